@@ -0,0 +1,86 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+)
+
+// AccountTransactions implements the /flow/account/transactions endpoint, which
+// is an extension to the Rosetta Data API. It returns the transactions that
+// affected an account over a range of blocks, in order of height, using the
+// account transaction history instead of walking through the blocks. Pages
+// are resumed with the same opaque cursor as transaction searches.
+func (d *Data) AccountTransactions(ctx echo.Context) error {
+
+	var req request.AccountTransactions
+	err := ctx.Bind(&req)
+	if err != nil {
+		return unpackError(err)
+	}
+
+	d = d.route(req.NetworkID)
+	err = d.validate.Request(req)
+	if err != nil {
+		return formatError(err)
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	// The cursor points to the first transaction of the next page, so that
+	// the listing resumes from there. Its match count is not used, as there
+	// is no offset to skip.
+	rosStart := req.StartBlockID
+	index := uint(0)
+	if req.Cursor != "" {
+		height, txIndex, _, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return httpError(invalidFormat(invalidCursor,
+				withDetail("cursor", req.Cursor),
+				withError(err),
+			))
+		}
+		rosStart = identifier.Block{Index: &height}
+		index = txIndex
+	}
+
+	transactions, next, nextIndex, err := d.retrieve.AccountTransactions(req.AccountID, rosStart, req.EndBlockID, index, limit)
+	if err != nil {
+		return apiError(historyRetrieval, err)
+	}
+
+	res := response.AccountTransactions{
+		Transactions: transactions,
+	}
+	if res.Transactions == nil {
+		res.Transactions = []object.BlockTransaction{}
+	}
+	if next != nil {
+		res.NextCursor = encodeCursor(*next.Index, nextIndex, 0)
+	}
+
+	return ctx.JSON(statusOK, res)
+}
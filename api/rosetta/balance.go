@@ -17,6 +17,7 @@ package rosetta
 import (
 	"github.com/labstack/echo/v4"
 
+	"github.com/optakt/flow-rosetta/rosetta/object"
 	"github.com/optakt/flow-rosetta/rosetta/request"
 	"github.com/optakt/flow-rosetta/rosetta/response"
 )
@@ -31,6 +32,7 @@ func (d *Data) Balance(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	d = d.route(req.NetworkID)
 	err = d.validate.Request(req)
 	if err != nil {
 		return formatError(err)
@@ -41,9 +43,19 @@ func (d *Data) Balance(ctx echo.Context) error {
 		return apiError(balancesRetrieval, err)
 	}
 
+	// We use the fully specified block identifier returned with the balances,
+	// so that the keys are guaranteed to be from the same block.
+	keys, err := d.retrieve.Keys(rosBlockID, req.AccountID)
+	if err != nil {
+		return apiError(keysRetrieval, err)
+	}
+
 	res := response.Balance{
 		BlockID:  rosBlockID,
 		Balances: balances,
+		Metadata: &object.AccountMetadata{
+			Keys: keys,
+		},
 	}
 
 	return ctx.JSON(statusOK, res)
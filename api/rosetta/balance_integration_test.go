@@ -213,7 +213,7 @@ func TestAPI_BalanceHandlesErrors(t *testing.T) {
 				Currencies: defaultCurrency(),
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidNetwork),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidNetwork),
 		},
 		{
 			name: "missing network name",
@@ -240,7 +240,7 @@ func TestAPI_BalanceHandlesErrors(t *testing.T) {
 				Currencies: defaultCurrency(),
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidNetwork),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidNetwork),
 		},
 		{
 			name: "invalid length of block id",
@@ -332,7 +332,7 @@ func TestAPI_BalanceHandlesErrors(t *testing.T) {
 				Currencies: defaultCurrency(),
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidBlock),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidBlock),
 		},
 		{
 			name: "unkown block requested",
@@ -343,7 +343,7 @@ func TestAPI_BalanceHandlesErrors(t *testing.T) {
 				Currencies: defaultCurrency(),
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorUnknownBlock),
+			checkError: checkRosettaError(http.StatusNotFound, configuration.ErrorUnknownBlock),
 		},
 		{
 			name: "mismatched block id and height",
@@ -354,7 +354,7 @@ func TestAPI_BalanceHandlesErrors(t *testing.T) {
 				Currencies: defaultCurrency(),
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidBlock),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidBlock),
 		},
 		{
 			name: "invalid account ID hex",
@@ -365,7 +365,7 @@ func TestAPI_BalanceHandlesErrors(t *testing.T) {
 				Currencies: defaultCurrency(),
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidAccount),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidAccount),
 		},
 		{
 			name: "invalid account ID",
@@ -376,7 +376,7 @@ func TestAPI_BalanceHandlesErrors(t *testing.T) {
 				Currencies: defaultCurrency(),
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidAccount),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidAccount),
 		},
 		{
 			name: "unknown currency requested",
@@ -387,7 +387,7 @@ func TestAPI_BalanceHandlesErrors(t *testing.T) {
 				Currencies: []identifier.Currency{{Symbol: invalidToken, Decimals: 8}},
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorUnknownCurrency),
+			checkError: checkRosettaError(http.StatusNotFound, configuration.ErrorUnknownCurrency),
 		},
 		{
 			name: "invalid currency decimal count",
@@ -398,7 +398,7 @@ func TestAPI_BalanceHandlesErrors(t *testing.T) {
 				Currencies: []identifier.Currency{{Symbol: dps.FlowSymbol, Decimals: 7}},
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidCurrency),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidCurrency),
 		},
 		{
 			name: "invalid currency decimal count in a list of currencies",
@@ -413,7 +413,7 @@ func TestAPI_BalanceHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidCurrency),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidCurrency),
 		},
 	}
 
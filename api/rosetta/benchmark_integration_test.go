@@ -0,0 +1,103 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+//go:build integration
+// +build integration
+
+package rosetta_test
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// The benchmarks below run against the bundled snapshot, so that their results
+// can be compared between commits to catch performance regressions. They can
+// be run with `make bench`. Balance retrieval is benchmarked with mocks in the
+// retriever package instead.
+
+func BenchmarkAPI_Block(b *testing.B) {
+
+	db := setupDB(b)
+	api := setupAPI(b, db)
+
+	benchmarks := []struct {
+		name   string
+		header flow.Header
+	}{
+		{name: "first block", header: knownHeader(0)},
+		{name: "block with transactions", header: knownHeader(47)},
+		{name: "block without transactions", header: knownHeader(60)},
+		{name: "last indexed block", header: knownHeader(173)},
+	}
+
+	for _, benchmark := range benchmarks {
+		benchmark := benchmark
+		b.Run(benchmark.name, func(b *testing.B) {
+			req := blockRequest(benchmark.header)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				serve(b, blockEndpoint, req, api.Block)
+			}
+		})
+	}
+}
+
+// BenchmarkAPI_Load replays a fixed sequence of block requests from concurrent
+// clients, similar to what a pool of indexer workers syncing the chain does.
+// Balance requests are left out until the snapshot's balance scripts can be
+// executed again; see the FIXME on the balance integration tests.
+func BenchmarkAPI_Load(b *testing.B) {
+
+	db := setupDB(b)
+	api := setupAPI(b, db)
+
+	// Only the known headers are used, so that each request is identical
+	// from one run to the next.
+	heights := []uint64{0, 1, 41, 47, 57, 60, 65, 116, 164, 173}
+	var counter uint64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			step := atomic.AddUint64(&counter, 1) - 1
+			header := knownHeader(heights[step%uint64(len(heights))])
+			serve(b, blockEndpoint, blockRequest(header), api.Block)
+		}
+	})
+}
+
+// serve executes a single request against the given handler, and fails the
+// benchmark if it does not succeed.
+func serve(b *testing.B, endpoint string, req interface{}, handler echo.HandlerFunc) {
+
+	rec, ctx, err := setupRecorder(endpoint, req)
+	if err != nil {
+		b.Fatal(err)
+	}
+	err = handler(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if rec.Result().StatusCode != http.StatusOK {
+		b.Fatalf("unexpected status code %d", rec.Result().StatusCode)
+	}
+}
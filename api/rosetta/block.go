@@ -31,6 +31,7 @@ func (d *Data) Block(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	d = d.route(req.NetworkID)
 	err = d.validate.Request(req)
 	if err != nil {
 		return formatError(err)
@@ -41,8 +42,11 @@ func (d *Data) Block(ctx echo.Context) error {
 		return apiError(blockRetrieval, err)
 	}
 
+	// Operations can be limited to a set of accounts through the request
+	// metadata, which keeps responses small for clients that only track
+	// their own accounts.
 	res := response.Block{
-		Block:             block,
+		Block:             filterBlock(block, accountFilter(req.Metadata)),
 		OtherTransactions: extraTxIDs,
 	}
 
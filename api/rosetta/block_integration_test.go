@@ -253,7 +253,7 @@ func TestAPI_BlockHandlesErrors(t *testing.T) {
 				BlockID: validBlockID,
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidNetwork),
+			checkErr: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidNetwork),
 		},
 		{
 			name: "missing network name",
@@ -277,7 +277,7 @@ func TestAPI_BlockHandlesErrors(t *testing.T) {
 				BlockID: validBlockID,
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidNetwork),
+			checkErr: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidNetwork),
 		},
 		{
 			name: "invalid length of block id",
@@ -289,7 +289,7 @@ func TestAPI_BlockHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkErr: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidFormat),
+			checkErr: checkRosettaErrorDetails(http.StatusBadRequest, configuration.ErrorInvalidFormat, "want_length", "have_length"),
 		},
 		{
 			name: "invalid block hash",
@@ -301,7 +301,7 @@ func TestAPI_BlockHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidBlock),
+			checkErr: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidBlock),
 		},
 		{
 			name: "unknown block",
@@ -312,7 +312,7 @@ func TestAPI_BlockHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorUnknownBlock),
+			checkErr: checkRosettaError(http.StatusNotFound, configuration.ErrorUnknownBlock),
 		},
 		{
 			name: "mismatched block height and hash",
@@ -324,7 +324,7 @@ func TestAPI_BlockHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidBlock),
+			checkErr: checkRosettaErrorDetails(http.StatusBadRequest, configuration.ErrorInvalidBlock, "block_index", "want_hash"),
 		},
 	}
 
@@ -0,0 +1,57 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+)
+
+// Blocks implements the /flow/blocks endpoint, which is an extension to the
+// Rosetta Data API. It returns all blocks of a bounded range of heights in
+// order, so that backfilling history does not take one request per block.
+func (d *Data) Blocks(ctx echo.Context) error {
+
+	var req request.Blocks
+	err := ctx.Bind(&req)
+	if err != nil {
+		return unpackError(err)
+	}
+
+	d = d.route(req.NetworkID)
+	err = d.validate.Request(req)
+	if err != nil {
+		return formatError(err)
+	}
+
+	blocks, extraTxIDs, err := d.retrieve.Blocks(req.StartBlockID, req.EndBlockID)
+	if err != nil {
+		return apiError(blockRetrieval, err)
+	}
+
+	res := response.Blocks{
+		Blocks: make([]response.Block, 0, len(blocks)),
+	}
+	for i, block := range blocks {
+		res.Blocks = append(res.Blocks, response.Block{
+			Block:             block,
+			OtherTransactions: extraTxIDs[i],
+		})
+	}
+
+	return ctx.JSON(statusOK, res)
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/sync/singleflight"
+)
+
+// Collapse returns a middleware that collapses concurrent identical requests
+// into a single execution of the handler. Requests are considered identical
+// when they target the same endpoint with the same canonicalized body, so
+// that differences in whitespace or field order do not matter. This prevents
+// thundering herds when many indexer workers request the same new block at
+// the same time.
+func Collapse() echo.MiddlewareFunc {
+
+	var group singleflight.Group
+
+	// The stream endpoint never ends, so it can not be shared, and submitting
+	// a transaction should always reach the access node.
+	skip := func(ctx echo.Context) bool {
+		switch ctx.Path() {
+		case "/flow/stream", "/construction/submit":
+			return true
+		default:
+			return ctx.Request().Method != http.MethodPost
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+
+			if skip(ctx) {
+				return next(ctx)
+			}
+
+			req := ctx.Request()
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			key := req.Method + " " + ctx.Path() + "\n" + string(canonicalize(body))
+			result, err, _ := group.Do(key, func() (interface{}, error) {
+				return record(ctx, next)
+			})
+			if err != nil {
+				return err
+			}
+
+			return result.(*recording).replay(ctx.Response())
+		}
+	}
+}
+
+// canonicalize returns the canonical encoding of a JSON body. As maps are
+// encoded with sorted keys, decoding and re-encoding the body removes any
+// differences in formatting and field order. Bodies that are not valid JSON
+// are returned as they are; the handler will reject them anyway.
+func canonicalize(body []byte) []byte {
+	var value interface{}
+	err := json.Unmarshal(body, &value)
+	if err != nil {
+		return body
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return canonical
+}
+
+// recording holds the response written by a handler, so that it can be
+// replayed to every request that was collapsed into the same execution.
+type recording struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// record executes the handler while capturing its response in a recording
+// instead of writing it to the client.
+func record(ctx echo.Context, next echo.HandlerFunc) (*recording, error) {
+
+	res := ctx.Response()
+	writer := res.Writer
+	rec := recording{
+		header: make(http.Header),
+		status: http.StatusOK,
+	}
+	res.Writer = &rec
+	defer func() {
+		res.Writer = writer
+		res.Committed = false
+		res.Size = 0
+	}()
+
+	err := next(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rec, nil
+}
+
+// Header implements http.ResponseWriter.
+func (r *recording) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (r *recording) WriteHeader(status int) {
+	r.status = status
+}
+
+// Write implements http.ResponseWriter.
+func (r *recording) Write(data []byte) (int, error) {
+	return r.body.Write(data)
+}
+
+// replay writes the recorded response to the given response.
+func (r *recording) replay(res *echo.Response) error {
+	header := res.Header()
+	for name, values := range r.header {
+		header[name] = append([]string(nil), values...)
+	}
+	res.WriteHeader(r.status)
+	_, err := res.Write(r.body.Bytes())
+	return err
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollapse(t *testing.T) {
+
+	// serve sends the given bodies concurrently to the given path, while the
+	// handler blocks until all of them have arrived at the middleware.
+	serve := func(t *testing.T, path string, bodies ...string) ([]*httptest.ResponseRecorder, uint32) {
+		t.Helper()
+
+		var calls uint32
+		release := make(chan struct{})
+		handler := func(ctx echo.Context) error {
+			atomic.AddUint32(&calls, 1)
+			<-release
+			ctx.Response().Header().Set("X-Test", "header")
+			return ctx.String(http.StatusAccepted, "response")
+		}
+
+		server := echo.New()
+		server.Use(Collapse())
+		server.POST(path, handler)
+
+		recs := make([]*httptest.ResponseRecorder, len(bodies))
+		var wg sync.WaitGroup
+		for i, body := range bodies {
+			recs[i] = httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+			wg.Add(1)
+			go func(rec *httptest.ResponseRecorder) {
+				defer wg.Done()
+				server.ServeHTTP(rec, req)
+			}(recs[i])
+		}
+
+		// Wait for the first handler call, then give all other requests a
+		// chance to join it before releasing the handler.
+		require.Eventually(t, func() bool { return atomic.LoadUint32(&calls) > 0 }, time.Second, time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		return recs, atomic.LoadUint32(&calls)
+	}
+
+	t.Run("collapses identical requests", func(t *testing.T) {
+		t.Parallel()
+
+		recs, calls := serve(t, "/block",
+			`{"block_identifier":{"index":1},"network_identifier":{"network":"flow-testnet"}}`,
+			`{ "network_identifier": {"network": "flow-testnet"}, "block_identifier": {"index": 1} }`,
+			`{"block_identifier":{"index":1},"network_identifier":{"network":"flow-testnet"}}`,
+		)
+
+		assert.Equal(t, uint32(1), calls)
+		for _, rec := range recs {
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			assert.Equal(t, "header", rec.Header().Get("X-Test"))
+			assert.Equal(t, "response", rec.Body.String())
+		}
+	})
+
+	t.Run("executes different requests separately", func(t *testing.T) {
+		t.Parallel()
+
+		recs, calls := serve(t, "/block",
+			`{"block_identifier":{"index":1}}`,
+			`{"block_identifier":{"index":2}}`,
+		)
+
+		assert.Equal(t, uint32(2), calls)
+		for _, rec := range recs {
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+			assert.Equal(t, "response", rec.Body.String())
+		}
+	})
+
+	t.Run("skips transaction submission", func(t *testing.T) {
+		t.Parallel()
+
+		recs, calls := serve(t, "/construction/submit",
+			`{"signed_transaction":"tx"}`,
+			`{"signed_transaction":"tx"}`,
+		)
+
+		assert.Equal(t, uint32(2), calls)
+		for _, rec := range recs {
+			assert.Equal(t, http.StatusAccepted, rec.Code)
+		}
+	})
+}
+
+func TestCanonicalize(t *testing.T) {
+	assert.Equal(t, `{"a":1,"b":[true,null]}`, string(canonicalize([]byte(` { "b": [true, null], "a": 1 } `))))
+	assert.Equal(t, `not json`, string(canonicalize([]byte(`not json`))))
+}
@@ -17,13 +17,16 @@ package rosetta
 import (
 	"github.com/labstack/echo/v4"
 
+	"github.com/optakt/flow-rosetta/rosetta/object"
 	"github.com/optakt/flow-rosetta/rosetta/request"
 	"github.com/optakt/flow-rosetta/rosetta/response"
+	"github.com/optakt/flow-rosetta/rosetta/transactor"
 )
 
 // Combine implements the /construction/combine endpoint of the Rosetta Construction API.
 // It creates a signed transaction by combining an unsigned transaction and
-// a list of signatures.
+// a list of signatures. Signatures can be produced from signing payloads in any of the
+// supported formats, and be returned by hardware wallets in their own encoding.
 // See https://www.rosetta-api.org/docs/ConstructionApi.html#constructioncombine
 func (c *Construction) Combine(ctx echo.Context) error {
 
@@ -33,6 +36,7 @@ func (c *Construction) Combine(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	c = c.route(req.NetworkID)
 	err = c.validate.Request(req)
 	if err != nil {
 		return formatError(err)
@@ -43,8 +47,32 @@ func (c *Construction) Combine(ctx echo.Context) error {
 		return apiError(txSigning, err)
 	}
 
+	// If the payer still needs to sign the transaction envelope, we return its
+	// signing payload, so that the transaction can be combined a second time. It
+	// uses the same format as the signing payloads of the signatures so far.
+	format := ""
+	for _, signature := range req.Signatures {
+		format = transactor.Format(signature.SigningPayload.HexBytes)
+		if format != "" {
+			break
+		}
+	}
+
+	var summary *object.Summary
+	var payloads []object.SigningPayload
+	if format != "" {
+		payloads, summary, err = c.transact.DetachedPayloads(signed, format)
+	} else {
+		payloads, err = c.transact.SigningPayloads(signed)
+	}
+	if err != nil {
+		return apiError(payloadHashing, err)
+	}
+
 	res := response.Combine{
 		SignedTransaction: signed,
+		Payloads:          payloads,
+		Summary:           summary,
 	}
 
 	return ctx.JSON(statusOK, res)
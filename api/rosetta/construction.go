@@ -14,6 +14,10 @@
 
 package rosetta
 
+import (
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
 // Construction implements the Rosetta Construction API specification.
 // See https://www.rosetta-api.org/docs/construction_api_introduction.html
 type Construction struct {
@@ -25,6 +29,10 @@ type Construction struct {
 	// transactions require a reference block ID, so that their validity
 	// or expiration can be determined.
 	retrieve Retriever
+
+	// Networks holds the Construction APIs of the other networks served by
+	// this instance.
+	networks []*Construction
 }
 
 // NewConstruction creates a new instance of the Construction API using the given configuration
@@ -40,3 +48,19 @@ func NewConstruction(config Configuration, transact Transactor, retrieve Retriev
 
 	return &c
 }
+
+// AddNetwork adds the stack of another network to the Construction API, which
+// works like the one of the Data API.
+func (c *Construction) AddNetwork(config Configuration, transact Transactor, retrieve Retriever, validate Validator) {
+	c.networks = append(c.networks, NewConstruction(config, transact, retrieve, validate))
+}
+
+// route returns the Construction API that serves the given network.
+func (c *Construction) route(network identifier.Network) *Construction {
+	for _, other := range c.networks {
+		if sameNetwork(other.config.Network(), network) {
+			return other
+		}
+	}
+	return c
+}
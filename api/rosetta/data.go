@@ -14,12 +14,20 @@
 
 package rosetta
 
+import (
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
 // Data implements the Rosetta Data API specification.
 // See https://www.rosetta-api.org/docs/data_api_introduction.html
 type Data struct {
 	config   Configuration
 	retrieve Retriever
 	validate Validator
+
+	// Networks holds the Data APIs of the other networks served by this
+	// instance, such as the next spork during a blue/green cutover.
+	networks []*Data
 }
 
 // NewData creates a new instance of the Data API using the given configuration to answer configuration queries
@@ -32,3 +40,38 @@ func NewData(config Configuration, retrieve Retriever, validate Validator) *Data
 	}
 	return &d
 }
+
+// AddNetwork adds the stack of another network to the Data API. Requests are
+// dispatched to the stack whose configured network is the requested one, and to
+// the stack given on creation when none is.
+func (d *Data) AddNetwork(config Configuration, retrieve Retriever, validate Validator) {
+	d.networks = append(d.networks, NewData(config, retrieve, validate))
+}
+
+// route returns the Data API that serves the given network. Handlers replace
+// their receiver with it as soon as the request is decoded. Requests for unknown
+// networks are served by the default stack, so that its validator rejects them.
+func (d *Data) route(network identifier.Network) *Data {
+	for _, other := range d.networks {
+		if sameNetwork(other.config.Network(), network) {
+			return other
+		}
+	}
+	return d
+}
+
+// sameNetwork returns whether two network identifiers designate the same
+// network, including their sub-network.
+func sameNetwork(n1 identifier.Network, n2 identifier.Network) bool {
+	if n1.Blockchain != n2.Blockchain || n1.Network != n2.Network {
+		return false
+	}
+	sub1, sub2 := "", ""
+	if n1.SubNetwork != nil {
+		sub1 = n1.SubNetwork.Network
+	}
+	if n2.SubNetwork != nil {
+		sub2 = n2.SubNetwork.Network
+	}
+	return sub1 == sub2
+}
@@ -50,6 +50,7 @@ import (
 	"github.com/optakt/flow-rosetta/rosetta/retriever"
 	"github.com/optakt/flow-rosetta/rosetta/scripts"
 	"github.com/optakt/flow-rosetta/rosetta/validator"
+	"github.com/optakt/flow-rosetta/testing/mocks"
 	"github.com/optakt/flow-rosetta/testing/snapshots"
 )
 
@@ -68,7 +69,7 @@ const (
 	invalidBlockHash = "f91704ce2fa9a1513500184ebfec884a1728438463c0104f8a17d5c66dd1af7z" // invalid hex value
 )
 
-func setupDB(t *testing.T) *badger.DB {
+func setupDB(t testing.TB) *badger.DB {
 	t.Helper()
 
 	opts := badger.DefaultOptions("").
@@ -89,7 +90,7 @@ func setupDB(t *testing.T) *badger.DB {
 	return db
 }
 
-func setupAPI(t *testing.T, db *badger.DB) *rosetta.Data {
+func setupAPI(t testing.TB, db *badger.DB) *rosetta.Data {
 	t.Helper()
 
 	rosetta.EnableSmartCodes()
@@ -100,11 +101,11 @@ func setupAPI(t *testing.T, db *badger.DB) *rosetta.Data {
 
 	params := dps.FlowParams[dps.FlowLocalnet]
 	config := configuration.New(params.ChainID)
-	validate := validator.New(params, index, config)
+	validate := validator.New(params, index, mocks.BaselineTracker(t), config)
 	generate := scripts.NewGenerator(params)
 	invoke, err := invoker.New(index)
 	require.NoError(t, err)
-	convert, err := converter.New(generate)
+	convert, err := converter.New(generate, params)
 	require.NoError(t, err)
 	retrieve := retriever.New(params, index, validate, generate, invoke, convert)
 	controller := rosetta.NewData(config, retrieve, validate)
@@ -162,6 +163,25 @@ func checkRosettaError(statusCode int, def meta.ErrorDefinition) func(t assert.T
 	}
 }
 
+// checkRosettaErrorDetails works like checkRosettaError, but additionally
+// verifies that the given keys are present in the error details.
+func checkRosettaErrorDetails(statusCode int, def meta.ErrorDefinition, keys ...string) func(t assert.TestingT, err error, v ...interface{}) bool {
+
+	return func(t assert.TestingT, err error, v ...interface{}) bool {
+		if !checkRosettaError(statusCode, def)(t, err, v...) {
+			return false
+		}
+
+		gotErr := err.(*echo.HTTPError).Message.(rosetta.Error)
+
+		success := true
+		for _, key := range keys {
+			success = success && assert.Contains(t, gotErr.Details, key)
+		}
+		return success
+	}
+}
+
 // defaultNetwork returns the Network identifier common for all requests.
 func defaultNetwork() identifier.Network {
 	return identifier.Network{
@@ -0,0 +1,99 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestData_Route(t *testing.T) {
+	active := configuration.New(flow.Mainnet, configuration.WithSubNetwork("mainnet-17"))
+	next := configuration.New(flow.Mainnet, configuration.WithSubNetwork("mainnet-18"))
+
+	serve := func(t *testing.T, handler echo.HandlerFunc, req interface{}) []byte {
+		t.Helper()
+
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(body))), rec)
+		ctx.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		require.NoError(t, handler(ctx))
+		require.Equal(t, statusOK, rec.Code)
+		return rec.Body.Bytes()
+	}
+
+	// Each stack returns a block with its own hash, so that we can tell which
+	// one served the request.
+	stack := func(hash string) *mocks.Retriever {
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.BlockFunc = func(identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			block := object.Block{ID: identifier.Block{Hash: hash}}
+			return &block, nil, nil
+		}
+		return retrieve
+	}
+
+	block := func(t *testing.T, d *Data, network identifier.Network) string {
+		t.Helper()
+
+		body := serve(t, d.Block, request.Block{NetworkID: network, BlockID: mocks.GenericRosBlockID})
+		var res response.Block
+		require.NoError(t, json.Unmarshal(body, &res))
+		require.NotNil(t, res.Block)
+		return res.Block.ID.Hash
+	}
+
+	d := NewData(active, stack("active"), mocks.BaselineValidator(t))
+	d.AddNetwork(next, stack("next"), mocks.BaselineValidator(t))
+
+	t.Run("nominal case", func(t *testing.T) {
+		assert.Equal(t, "next", block(t, d, next.Network()))
+		assert.Equal(t, "active", block(t, d, active.Network()))
+	})
+
+	t.Run("serves requests without sub-network from default stack", func(t *testing.T) {
+		network := identifier.Network{
+			Blockchain: active.Network().Blockchain,
+			Network:    active.Network().Network,
+		}
+		assert.Equal(t, "active", block(t, d, network))
+	})
+
+	t.Run("lists all networks", func(t *testing.T) {
+		body := serve(t, d.Networks, request.Networks{})
+		var res response.Networks
+		require.NoError(t, json.Unmarshal(body, &res))
+		assert.Equal(t, []identifier.Network{active.Network(), next.Network()}, res.NetworkIDs)
+	})
+}
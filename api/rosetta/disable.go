@@ -0,0 +1,58 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+// Disable returns a middleware that rejects requests to the given endpoints with
+// a Rosetta error, so that operators can shrink the surface of public deployments.
+// An endpoint that ends with a slash disables all of the endpoints below it, such
+// as `/construction/` for the whole Construction API.
+func Disable(endpoints []string) echo.MiddlewareFunc {
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+
+			if !Disabled(endpoints, ctx.Path()) {
+				return next(ctx)
+			}
+
+			return apiError(requestAdmission, failure.DisabledEndpoint{
+				Endpoint:    ctx.Path(),
+				Description: failure.NewDescription(endpointDisabled),
+			})
+		}
+	}
+}
+
+// Disabled returns whether the endpoint with the given path is disabled by any of
+// the given endpoints.
+func Disabled(endpoints []string, path string) bool {
+	for _, endpoint := range endpoints {
+		if path == endpoint {
+			return true
+		}
+		if strings.HasSuffix(endpoint, "/") && strings.HasPrefix(path, endpoint) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+)
+
+func TestDisable(t *testing.T) {
+
+	serve := func(endpoints []string, path string) (*httptest.ResponseRecorder, bool) {
+		called := false
+		handler := func(ctx echo.Context) error {
+			called = true
+			return ctx.NoContent(http.StatusOK)
+		}
+
+		server := echo.New()
+		server.Use(Disable(endpoints))
+		server.POST(path, handler)
+
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+
+		return rec, called
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		rec, called := serve([]string{"/search/transactions"}, "/block")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, called)
+	})
+
+	t.Run("rejects disabled endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		rec, called := serve([]string{"/search/transactions"}, "/search/transactions")
+
+		assert.False(t, called)
+		var rosErr Error
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rosErr))
+		assert.Equal(t, configuration.ErrorDisabledEndpoint.Code, rosErr.Code)
+		assert.False(t, rosErr.Retriable)
+		assert.Equal(t, "/search/transactions", rosErr.Details["endpoint"])
+	})
+
+	t.Run("rejects endpoints below disabled group", func(t *testing.T) {
+		t.Parallel()
+
+		_, called := serve([]string{"/construction/"}, "/construction/submit")
+		assert.False(t, called)
+		_, called = serve([]string{"/construction/"}, "/construction/payloads")
+		assert.False(t, called)
+	})
+
+	t.Run("does not match partial endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		_, called := serve([]string{"/account"}, "/account/balance")
+		assert.True(t, called)
+	})
+}
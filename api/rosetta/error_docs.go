@@ -0,0 +1,42 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/optakt/flow-rosetta/rosetta/meta"
+)
+
+// docsURL is the base URL of the documentation of the Rosetta errors, such as
+// an operator's runbooks. When it is set, every error links to the page for
+// its code, so that clients can look up failing responses programmatically.
+var docsURL = ""
+
+// EnableErrorDocs sets the base URL of the documentation of Rosetta errors.
+// Like smart status codes, this is a global switch of the Rosetta API.
+func EnableErrorDocs(base string) {
+	docsURL = strings.TrimSuffix(base, "/")
+}
+
+// documented returns the given error definition with its documentation URL.
+func documented(definition meta.ErrorDefinition) meta.ErrorDefinition {
+	if docsURL == "" {
+		return definition
+	}
+	definition.URL = fmt.Sprintf("%s/%d", docsURL, definition.Code)
+	return definition
+}
@@ -16,8 +16,10 @@ package rosetta
 
 import (
 	"errors"
+	"reflect"
 
 	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
 
 	"github.com/onflow/flow-go/model/flow"
 
@@ -29,10 +31,9 @@ import (
 const (
 	invalidJSON = "request does not contain valid JSON-encoded body"
 
-	txInvalidOps = "transaction operations are invalid"
-
 	blockRetrieval          = "unable to retrieve block"
 	balancesRetrieval       = "unable to retrieve balances"
+	keysRetrieval           = "unable to retrieve account keys"
 	oldestRetrieval         = "unable to retrieve oldest block"
 	currentRetrieval        = "unable to retrieve current block"
 	txSubmission            = "unable to submit transaction"
@@ -44,13 +45,34 @@ const (
 	txParsing               = "unable to parse transaction"
 	txSigning               = "unable to sign transaction"
 	payloadHashing          = "unable to hash signing payload"
+	feeSuggestion           = "unable to suggest transaction fee"
 	txIdentifier            = "unable to retrieve transaction identifier"
+	rewardsRetrieval        = "unable to retrieve rewards"
+	nodeRetrieval           = "unable to retrieve node"
+	txSimulation            = "unable to simulate transaction"
+	txSearch                = "unable to search transactions"
+	historyRetrieval        = "unable to retrieve account transactions"
+	requestAdmission        = "unable to admit request"
+
+	endpointDisabled = "endpoint is disabled on this server"
+
+	formatUnsupported = "signing payload format is not supported"
+
+	invalidCursor  = "search cursor is invalid"
+	cursorMismatch = "search cursor is beyond the requested offset"
+
+	backlogExceeded = "start index is too far behind the current block, use /flow/blocks to backfill"
 )
 
 // Error represents an error as defined by the Rosetta API specification. It
 // contains an error definition, which has an error code, error message and
 // retriable flag that never change, as well as a description and a list of
 // details to provide more granular error information.
+//
+// The details always contain the fields of the failure's description, using
+// the same snake_case keys, as well as the typed fields of the failure itself.
+// This allows automated clients to act on e.g. `want_hash` without having to
+// parse the description.
 // See: https://www.rosetta-api.org/docs/api_objects.html#error
 type Error struct {
 	meta.ErrorDefinition
@@ -84,7 +106,7 @@ func rosettaError(definition meta.ErrorDefinition, description string, details .
 		detail(dd)
 	}
 	e := Error{
-		ErrorDefinition: definition,
+		ErrorDefinition: documented(definition),
 		Description:     description,
 		Details:         dd,
 	}
@@ -140,6 +162,40 @@ func invalidBlockchain(fail failure.InvalidBlockchain) Error {
 	)
 }
 
+func invalidBlockHash(fail failure.InvalidBlockHash) Error {
+	return convertError(
+		configuration.ErrorInvalidFormat,
+		fail.Description,
+		withDetail("want_length", fail.WantLength),
+		withDetail("have_length", fail.HaveLength),
+	)
+}
+
+func invalidAccountAddress(fail failure.InvalidAccountAddress) Error {
+	return convertError(
+		configuration.ErrorInvalidFormat,
+		fail.Description,
+		withDetail("want_length", fail.WantLength),
+		withDetail("have_length", fail.HaveLength),
+	)
+}
+
+func invalidTransactionHash(fail failure.InvalidTransactionHash) Error {
+	return convertError(
+		configuration.ErrorInvalidFormat,
+		fail.Description,
+		withDetail("want_length", fail.WantLength),
+		withDetail("have_length", fail.HaveLength),
+	)
+}
+
+func incompleteBlock(fail failure.IncompleteBlock) Error {
+	return convertError(
+		configuration.ErrorInvalidFormat,
+		fail.Description,
+	)
+}
+
 func invalidAccount(fail failure.InvalidAccount) Error {
 	return convertError(
 		configuration.ErrorInvalidAccount,
@@ -172,6 +228,32 @@ func invalidTransaction(fail failure.InvalidTransaction) Error {
 	)
 }
 
+func unavailableBlock(fail failure.UnavailableBlock) Error {
+	return convertError(
+		configuration.ErrorUnavailableBlock,
+		fail.Description,
+		withDetail("index", fail.Index),
+		withDetail("hash", fail.Hash),
+	)
+}
+
+func unsealedBlock(fail failure.UnsealedBlock) Error {
+	return convertError(
+		configuration.ErrorUnsealedBlock,
+		fail.Description,
+		withDetail("index", fail.Index),
+		withDetail("hash", fail.Hash),
+	)
+}
+
+func uncoveredBlock(fail failure.UncoveredBlock) Error {
+	return convertError(
+		configuration.ErrorUncoveredBlock,
+		fail.Description,
+		withDetail("index", fail.Index),
+	)
+}
+
 func unknownCurrency(fail failure.UnknownCurrency) Error {
 	return convertError(
 		configuration.ErrorUnknownCurrency,
@@ -198,6 +280,23 @@ func unknownTransaction(fail failure.UnknownTransaction) Error {
 	)
 }
 
+func unknownNode(fail failure.UnknownNode) Error {
+	return convertError(
+		configuration.ErrorUnknownNode,
+		fail.Description,
+		withDetail("node_id", fail.NodeID),
+	)
+}
+
+func invalidOperations(fail failure.InvalidOperations) Error {
+	return convertError(
+		configuration.ErrorInvalidFormat,
+		fail.Description,
+		withDetail("have_operations", fail.Have),
+		withDetail("want_operations", fail.Want),
+	)
+}
+
 func invalidIntent(fail failure.InvalidIntent) Error {
 	return convertError(
 		configuration.ErrorInvalidIntent,
@@ -299,143 +398,260 @@ func invalidPayload(fail failure.InvalidPayload) Error {
 	)
 }
 
+func insufficientBalance(fail failure.InsufficientBalance) Error {
+	return convertError(
+		configuration.ErrorInsufficientBalance,
+		fail.Description,
+		withAddress("address", fail.Address),
+		withDetail("balance", fail.Balance),
+		withDetail("amount", fail.Amount),
+	)
+}
+
+func insufficientFee(fail failure.InsufficientFee) Error {
+	return convertError(
+		configuration.ErrorInsufficientFee,
+		fail.Description,
+		withDetail("max_fee", fail.MaxFee),
+		withDetail("fee", fail.Fee),
+	)
+}
+
+func expiredTransaction(fail failure.ExpiredTransaction) Error {
+	return convertError(
+		configuration.ErrorExpiredTransaction,
+		fail.Description,
+		withDetail("reference_height", fail.ReferenceHeight),
+		withDetail("expiry_height", fail.ExpiryHeight),
+		withDetail("current_height", fail.CurrentHeight),
+		withAddress("proposer", fail.Proposer),
+		withDetail("key_index", fail.KeyIndex),
+		withAddress("payer", fail.Payer),
+	)
+}
+
+func sequenceConflict(fail failure.SequenceConflict) Error {
+	return convertError(
+		configuration.ErrorSequenceConflict,
+		fail.Description,
+		withAddress("proposer", fail.Proposer),
+		withDetail("key_index", fail.KeyIndex),
+		withDetail("sequence_number", fail.Sequence),
+		withDetail("current_sequence_number", fail.Current),
+	)
+}
+
+func failedTransaction(fail failure.FailedTransaction) Error {
+	return convertError(
+		configuration.ErrorFailedTransaction,
+		fail.Description,
+		withDetail("error_code", fail.Code),
+	)
+}
+
+func overloaded(fail failure.Overloaded) Error {
+	return convertError(
+		configuration.ErrorOverloaded,
+		fail.Description,
+		withDetail("capacity", fail.Capacity),
+	)
+}
+
+func disabledEndpoint(fail failure.DisabledEndpoint) Error {
+	return convertError(
+		configuration.ErrorDisabledEndpoint,
+		fail.Description,
+		withDetail("endpoint", fail.Endpoint),
+	)
+}
+
+func limitExceeded(fail failure.LimitExceeded) Error {
+	return convertError(
+		configuration.ErrorLimitExceeded,
+		fail.Description,
+		withDetail("limit", fail.Limit),
+	)
+}
+
+func upstream(fail failure.Upstream) Error {
+	var definition meta.ErrorDefinition
+	switch fail.Code {
+	case codes.NotFound:
+		definition = configuration.ErrorUpstreamNotFound
+	case codes.OutOfRange:
+		definition = configuration.ErrorUpstreamOutOfRange
+	case codes.Unavailable:
+		definition = configuration.ErrorUpstreamUnavailable
+	case codes.DeadlineExceeded:
+		definition = configuration.ErrorUpstreamTimeout
+	default:
+		definition = configuration.ErrorInternal
+	}
+	return convertError(
+		definition,
+		fail.Description,
+		withDetail("service", fail.Service),
+		withDetail("code", fail.Code.String()),
+	)
+}
+
 // unpackError returns the HTTP status code and Rosetta Error for malformed JSON requests.
 func unpackError(err error) *echo.HTTPError {
-	return echo.NewHTTPError(statusBadRequest, invalidEncoding(invalidJSON, err))
+	return httpError(invalidEncoding(invalidJSON, err))
+}
+
+// conversions is the single table mapping each type of failure to the Rosetta
+// error that it is reported as, which is shared by all handlers.
+var conversions = map[reflect.Type]func(failure.Categorized) Error{
+	reflect.TypeOf(failure.InvalidBlockHash{}): func(fail failure.Categorized) Error {
+		return invalidBlockHash(fail.(failure.InvalidBlockHash))
+	},
+	reflect.TypeOf(failure.InvalidAccountAddress{}): func(fail failure.Categorized) Error {
+		return invalidAccountAddress(fail.(failure.InvalidAccountAddress))
+	},
+	reflect.TypeOf(failure.InvalidTransactionHash{}): func(fail failure.Categorized) Error {
+		return invalidTransactionHash(fail.(failure.InvalidTransactionHash))
+	},
+	reflect.TypeOf(failure.IncompleteBlock{}): func(fail failure.Categorized) Error {
+		return incompleteBlock(fail.(failure.IncompleteBlock))
+	},
+	reflect.TypeOf(failure.InvalidNetwork{}): func(fail failure.Categorized) Error {
+		return invalidNetwork(fail.(failure.InvalidNetwork))
+	},
+	reflect.TypeOf(failure.InvalidBlockchain{}): func(fail failure.Categorized) Error {
+		return invalidBlockchain(fail.(failure.InvalidBlockchain))
+	},
+	reflect.TypeOf(failure.InvalidBlock{}): func(fail failure.Categorized) Error {
+		return invalidBlock(fail.(failure.InvalidBlock))
+	},
+	reflect.TypeOf(failure.UnknownBlock{}): func(fail failure.Categorized) Error {
+		return unknownBlock(fail.(failure.UnknownBlock))
+	},
+	reflect.TypeOf(failure.UnavailableBlock{}): func(fail failure.Categorized) Error {
+		return unavailableBlock(fail.(failure.UnavailableBlock))
+	},
+	reflect.TypeOf(failure.UnsealedBlock{}): func(fail failure.Categorized) Error {
+		return unsealedBlock(fail.(failure.UnsealedBlock))
+	},
+	reflect.TypeOf(failure.UncoveredBlock{}): func(fail failure.Categorized) Error {
+		return uncoveredBlock(fail.(failure.UncoveredBlock))
+	},
+	reflect.TypeOf(failure.InvalidAccount{}): func(fail failure.Categorized) Error {
+		return invalidAccount(fail.(failure.InvalidAccount))
+	},
+	reflect.TypeOf(failure.InvalidCurrency{}): func(fail failure.Categorized) Error {
+		return invalidCurrency(fail.(failure.InvalidCurrency))
+	},
+	reflect.TypeOf(failure.UnknownCurrency{}): func(fail failure.Categorized) Error {
+		return unknownCurrency(fail.(failure.UnknownCurrency))
+	},
+	reflect.TypeOf(failure.InvalidTransaction{}): func(fail failure.Categorized) Error {
+		return invalidTransaction(fail.(failure.InvalidTransaction))
+	},
+	reflect.TypeOf(failure.UnknownTransaction{}): func(fail failure.Categorized) Error {
+		return unknownTransaction(fail.(failure.UnknownTransaction))
+	},
+	reflect.TypeOf(failure.UnknownNode{}): func(fail failure.Categorized) Error {
+		return unknownNode(fail.(failure.UnknownNode))
+	},
+	reflect.TypeOf(failure.InvalidAuthorizers{}): func(fail failure.Categorized) Error {
+		return invalidAuthorizers(fail.(failure.InvalidAuthorizers))
+	},
+	reflect.TypeOf(failure.InvalidPayer{}): func(fail failure.Categorized) Error {
+		return invalidPayer(fail.(failure.InvalidPayer))
+	},
+	reflect.TypeOf(failure.InvalidProposer{}): func(fail failure.Categorized) Error {
+		return invalidProposer(fail.(failure.InvalidProposer))
+	},
+	reflect.TypeOf(failure.InvalidSignature{}): func(fail failure.Categorized) Error {
+		return invalidSignature(fail.(failure.InvalidSignature))
+	},
+	reflect.TypeOf(failure.InvalidSignatures{}): func(fail failure.Categorized) Error {
+		return invalidSignatures(fail.(failure.InvalidSignatures))
+	},
+	reflect.TypeOf(failure.InvalidOperations{}): func(fail failure.Categorized) Error {
+		return invalidOperations(fail.(failure.InvalidOperations))
+	},
+	reflect.TypeOf(failure.InvalidIntent{}): func(fail failure.Categorized) Error {
+		return invalidIntent(fail.(failure.InvalidIntent))
+	},
+	reflect.TypeOf(failure.InvalidKey{}): func(fail failure.Categorized) Error {
+		return invalidKey(fail.(failure.InvalidKey))
+	},
+	reflect.TypeOf(failure.InvalidScript{}): func(fail failure.Categorized) Error {
+		return invalidScript(fail.(failure.InvalidScript))
+	},
+	reflect.TypeOf(failure.InvalidArguments{}): func(fail failure.Categorized) Error {
+		return invalidArguments(fail.(failure.InvalidArguments))
+	},
+	reflect.TypeOf(failure.InvalidAmount{}): func(fail failure.Categorized) Error {
+		return invalidAmount(fail.(failure.InvalidAmount))
+	},
+	reflect.TypeOf(failure.InvalidReceiver{}): func(fail failure.Categorized) Error {
+		return invalidReceiver(fail.(failure.InvalidReceiver))
+	},
+	reflect.TypeOf(failure.InvalidPayload{}): func(fail failure.Categorized) Error {
+		return invalidPayload(fail.(failure.InvalidPayload))
+	},
+	reflect.TypeOf(failure.InsufficientBalance{}): func(fail failure.Categorized) Error {
+		return insufficientBalance(fail.(failure.InsufficientBalance))
+	},
+	reflect.TypeOf(failure.InsufficientFee{}): func(fail failure.Categorized) Error {
+		return insufficientFee(fail.(failure.InsufficientFee))
+	},
+	reflect.TypeOf(failure.ExpiredTransaction{}): func(fail failure.Categorized) Error {
+		return expiredTransaction(fail.(failure.ExpiredTransaction))
+	},
+	reflect.TypeOf(failure.SequenceConflict{}): func(fail failure.Categorized) Error {
+		return sequenceConflict(fail.(failure.SequenceConflict))
+	},
+	reflect.TypeOf(failure.FailedTransaction{}): func(fail failure.Categorized) Error {
+		return failedTransaction(fail.(failure.FailedTransaction))
+	},
+	reflect.TypeOf(failure.Upstream{}): func(fail failure.Categorized) Error {
+		return upstream(fail.(failure.Upstream))
+	},
+	reflect.TypeOf(failure.Overloaded{}): func(fail failure.Categorized) Error {
+		return overloaded(fail.(failure.Overloaded))
+	},
+	reflect.TypeOf(failure.LimitExceeded{}): func(fail failure.Categorized) Error {
+		return limitExceeded(fail.(failure.LimitExceeded))
+	},
+	reflect.TypeOf(failure.DisabledEndpoint{}): func(fail failure.Categorized) Error {
+		return disabledEndpoint(fail.(failure.DisabledEndpoint))
+	},
+}
+
+// convertFailure returns the Rosetta error for the failure wrapped in the given
+// error, if there is one and it has a Rosetta error.
+func convertFailure(err error) (Error, bool) {
+	var fail failure.Categorized
+	if !errors.As(err, &fail) {
+		return Error{}, false
+	}
+	convert, ok := conversions[reflect.TypeOf(fail)]
+	if !ok {
+		return Error{}, false
+	}
+	return convert(fail), true
 }
 
 // formatError returns the HTTP status code and Rosetta Error for requests
 // that did not pass validation.
 func formatError(err error) *echo.HTTPError {
-
-	var ibErr failure.InvalidBlockHash
-	if errors.As(err, &ibErr) {
-		return echo.NewHTTPError(statusBadRequest, invalidFormat(ibErr.Description.Text,
-			withDetail("want_length", ibErr.WantLength),
-			withDetail("have_length", ibErr.HaveLength),
-		))
-	}
-	var iaErr failure.InvalidAccountAddress
-	if errors.As(err, &iaErr) {
-		return echo.NewHTTPError(statusBadRequest, invalidFormat(iaErr.Description.Text,
-			withDetail("want_length", iaErr.WantLength),
-			withDetail("have_length", iaErr.HaveLength),
-		))
-	}
-	var itErr failure.InvalidTransactionHash
-	if errors.As(err, &itErr) {
-		return echo.NewHTTPError(statusBadRequest, invalidFormat(itErr.Description.Text,
-			withDetail("want_length", itErr.WantLength),
-			withDetail("have_length", itErr.HaveLength),
-		))
-	}
-	var icErr failure.IncompleteBlock
-	if errors.As(err, &icErr) {
-		return echo.NewHTTPError(statusBadRequest, invalidFormat(icErr.Description.Text))
-	}
-	var inErr failure.InvalidNetwork
-	if errors.As(err, &inErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidNetwork(inErr))
+	rosErr, ok := convertFailure(err)
+	if ok {
+		return httpError(rosErr)
 	}
-	var iblErr failure.InvalidBlockchain
-	if errors.As(err, &iblErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidBlockchain(iblErr))
-	}
-
-	return echo.NewHTTPError(statusBadRequest, invalidFormat(err.Error()))
+	return httpError(invalidFormat(err.Error()))
 }
 
 // apiError returns the HTTP status code and Rosetta Error for various errors
 // occurred during request processing.
 func apiError(description string, err error) *echo.HTTPError {
-
-	// Common errors, found both in Data and Construction API.
-	var inErr failure.InvalidNetwork
-	if errors.As(err, &inErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidNetwork(inErr))
-	}
-	var ibErr failure.InvalidBlock
-	if errors.As(err, &ibErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidBlock(ibErr))
-	}
-	var ubErr failure.UnknownBlock
-	if errors.As(err, &ubErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, unknownBlock(ubErr))
-	}
-	var iaErr failure.InvalidAccount
-	if errors.As(err, &iaErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidAccount(iaErr))
-	}
-	var icErr failure.InvalidCurrency
-	if errors.As(err, &icErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidCurrency(icErr))
-	}
-	var ucErr failure.UnknownCurrency
-	if errors.As(err, &ucErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, unknownCurrency(ucErr))
+	rosErr, ok := convertFailure(err)
+	if ok {
+		return httpError(rosErr)
 	}
-	var itErr failure.InvalidTransaction
-	if errors.As(err, &itErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidTransaction(itErr))
-	}
-	var utErr failure.UnknownTransaction
-	if errors.As(err, &utErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, unknownTransaction(utErr))
-	}
-
-	// Construction API specific errors.
-	var iautErr failure.InvalidAuthorizers
-	if errors.As(err, &iaErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidAuthorizers(iautErr))
-	}
-	var ipyErr failure.InvalidPayer
-	if errors.As(err, &ipyErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidPayer(ipyErr))
-	}
-	var iprErr failure.InvalidProposer
-	if errors.As(err, &iprErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidProposer(iprErr))
-	}
-	var isgErr failure.InvalidSignature
-	if errors.As(err, &isgErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidSignature(isgErr))
-	}
-	var isgsErr failure.InvalidSignatures
-	if errors.As(err, &isgsErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidSignatures(isgsErr))
-	}
-	var opErr failure.InvalidOperations
-	if errors.As(err, &opErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidFormat(txInvalidOps))
-	}
-	var intErr failure.InvalidIntent
-	if errors.As(err, &intErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidIntent(intErr))
-	}
-	var ikErr failure.InvalidKey
-	if errors.As(err, &ipyErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidKey(ikErr))
-	}
-	var isErr failure.InvalidScript
-	if errors.As(err, &isErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidScript(isErr))
-	}
-	var iargErr failure.InvalidArguments
-	if errors.As(err, &iargErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidArguments(iargErr))
-	}
-	var imErr failure.InvalidAmount
-	if errors.As(err, &imErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidAmount(imErr))
-	}
-	var irErr failure.InvalidReceiver
-	if errors.As(err, &irErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidReceiver(irErr))
-	}
-	var iplErr failure.InvalidPayload
-	if errors.As(err, &iplErr) {
-		return echo.NewHTTPError(statusUnprocessableEntity, invalidPayload(iplErr))
-	}
-
-	return echo.NewHTTPError(statusInternalServerError, internal(description, err))
+	return httpError(internal(description, err))
 }
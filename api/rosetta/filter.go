@@ -0,0 +1,84 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"strings"
+
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+// accountFilter returns a function that tells whether an operation affects one
+// of the accounts of the given filter, or nil if no accounts are given.
+func accountFilter(filter *object.OperationFilter) func(*object.Operation) bool {
+
+	if filter == nil || len(filter.Accounts) == 0 {
+		return nil
+	}
+
+	addresses := make(map[string]struct{}, len(filter.Accounts))
+	for _, rosAccountID := range filter.Accounts {
+		addresses[strings.ToLower(rosAccountID.Address)] = struct{}{}
+	}
+
+	match := func(op *object.Operation) bool {
+		_, ok := addresses[strings.ToLower(op.AccountID.Address)]
+		return ok
+	}
+
+	return match
+}
+
+// filterBlock returns a copy of the given block which only holds the matching
+// operations, and omits the transactions left without any operations. Blocks
+// are cached by the retriever, so they are never modified in place.
+func filterBlock(block *object.Block, match func(*object.Operation) bool) *object.Block {
+
+	if match == nil {
+		return block
+	}
+
+	filtered := *block
+	filtered.Transactions = make([]*object.Transaction, 0, len(block.Transactions))
+	for _, transaction := range block.Transactions {
+		transaction = filterTransaction(transaction, match)
+		if len(transaction.Operations) == 0 {
+			continue
+		}
+		filtered.Transactions = append(filtered.Transactions, transaction)
+	}
+
+	return &filtered
+}
+
+// filterTransaction returns a copy of the given transaction which only holds
+// the matching operations. Operations keep their original indices, so that they
+// can still be correlated with unfiltered responses.
+func filterTransaction(transaction *object.Transaction, match func(*object.Operation) bool) *object.Transaction {
+
+	if match == nil {
+		return transaction
+	}
+
+	filtered := *transaction
+	filtered.Operations = make([]*object.Operation, 0, len(transaction.Operations))
+	for _, op := range transaction.Operations {
+		if match(op) {
+			filtered.Operations = append(filtered.Operations, op)
+		}
+	}
+
+	return &filtered
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+func TestFilterBlock(t *testing.T) {
+
+	op := func(index uint, address string) *object.Operation {
+		return &object.Operation{
+			ID:        identifier.Operation{Index: index},
+			AccountID: identifier.Account{Address: address},
+		}
+	}
+
+	hot := "631e88ae7f1d7c20"
+	cold := "754AED9DE6197641"
+	other := "e03daebed8ca0615"
+
+	block := &object.Block{
+		Transactions: []*object.Transaction{
+			{Operations: []*object.Operation{op(0, other), op(1, hot)}},
+			{Operations: []*object.Operation{op(0, other), op(1, other)}},
+			{Operations: []*object.Operation{op(0, "754aed9de6197641")}},
+		},
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		filter := object.OperationFilter{
+			Accounts: []identifier.Account{{Address: hot}, {Address: cold}},
+		}
+
+		got := filterBlock(block, accountFilter(&filter))
+
+		require.Len(t, got.Transactions, 2)
+		require.Len(t, got.Transactions[0].Operations, 1)
+		assert.Equal(t, uint(1), got.Transactions[0].Operations[0].ID.Index)
+		require.Len(t, got.Transactions[1].Operations, 1)
+		assert.Equal(t, uint(0), got.Transactions[1].Operations[0].ID.Index)
+
+		// The original block must be left untouched, since it is cached.
+		assert.Len(t, block.Transactions, 3)
+		assert.Len(t, block.Transactions[0].Operations, 2)
+	})
+
+	t.Run("no filter", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Same(t, block, filterBlock(block, accountFilter(nil)))
+		assert.Same(t, block, filterBlock(block, accountFilter(&object.OperationFilter{})))
+	})
+}
+
+func TestFilterTransaction(t *testing.T) {
+
+	transaction := &object.Transaction{
+		Operations: []*object.Operation{
+			{AccountID: identifier.Account{Address: "631e88ae7f1d7c20"}},
+			{AccountID: identifier.Account{Address: "e03daebed8ca0615"}},
+		},
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		filter := object.OperationFilter{
+			Accounts: []identifier.Account{{Address: "754aed9de6197641"}},
+		}
+
+		got := filterTransaction(transaction, accountFilter(&filter))
+
+		assert.Empty(t, got.Operations)
+		assert.Len(t, transaction.Operations, 2)
+	})
+
+	t.Run("no filter", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Same(t, transaction, filterTransaction(transaction, accountFilter(nil)))
+	})
+}
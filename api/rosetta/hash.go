@@ -32,6 +32,7 @@ func (c *Construction) Hash(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	c = c.route(req.NetworkID)
 	err = c.validate.Request(req)
 	if err != nil {
 		return formatError(err)
@@ -0,0 +1,53 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+)
+
+// Lookup implements the /flow/transaction endpoint, which is an extension to
+// the Rosetta Data API. It returns the given transaction along with the
+// identifier of the block that contains it, so that clients only need the hash
+// of a transaction to retrieve it.
+func (d *Data) Lookup(ctx echo.Context) error {
+
+	var req request.Lookup
+	err := ctx.Bind(&req)
+	if err != nil {
+		return unpackError(err)
+	}
+
+	d = d.route(req.NetworkID)
+	err = d.validate.Request(req)
+	if err != nil {
+		return formatError(err)
+	}
+
+	rosBlockID, transaction, err := d.retrieve.Lookup(req.TransactionID)
+	if err != nil {
+		return apiError(txRetrieval, err)
+	}
+
+	res := response.Lookup{
+		BlockID:     rosBlockID,
+		Transaction: filterTransaction(transaction, accountFilter(req.Metadata)),
+	}
+
+	return ctx.JSON(statusOK, res)
+}
@@ -17,6 +17,12 @@ package rosetta
 import (
 	"github.com/labstack/echo/v4"
 
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
 	"github.com/optakt/flow-rosetta/rosetta/object"
 	"github.com/optakt/flow-rosetta/rosetta/request"
 	"github.com/optakt/flow-rosetta/rosetta/response"
@@ -27,7 +33,9 @@ import (
 // For Flow, that information includes the reference block and sequence number. Reference block
 // is the last indexed block, and is used to track transaction expiration. Sequence number is
 // the proposer account's public key sequence number. Sequence number is incremented for each
-// transaction and is used to prevent replay attacks.
+// transaction and is used to prevent replay attacks. The gas limit is derived from the fee
+// options given to the preprocess endpoint, and the suggested fee is the transaction fee
+// that Flow charges at the reference block.
 // See https://www.rosetta-api.org/docs/ConstructionApi.html#constructionmetadata
 func (c *Construction) Metadata(ctx echo.Context) error {
 
@@ -37,6 +45,7 @@ func (c *Construction) Metadata(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	c = c.route(req.NetworkID)
 	err = c.validate.Request(req)
 	if err != nil {
 		return formatError(err)
@@ -47,18 +56,52 @@ func (c *Construction) Metadata(ctx echo.Context) error {
 		return apiError(referenceBlockRetrieval, err)
 	}
 
-	sequence, err := c.retrieve.Sequence(current, req.Options.AccountID, 0)
+	// The sequence number is the one of the proposal key, which is the first key
+	// of the proposer unless another one was requested.
+	var keyIndex int
+	if req.Options.Roles != nil {
+		keyIndex = req.Options.Roles.ProposerKeyIndex
+	}
+	sequence, err := c.retrieve.Sequence(current, req.Options.AccountID, keyIndex)
 	if err != nil {
 		return apiError(sequenceNumberRetrieval, err)
 	}
 
+	var maxFee uint64
+	if req.Options.MaxFee != "" {
+		maxFee, err = amount.Parse(req.Options.MaxFee)
+		if err != nil {
+			return apiError(feeSuggestion, failure.InvalidAmount{
+				Description: failure.NewDescription(err.Error()),
+				Amount:      req.Options.MaxFee,
+			})
+		}
+	}
+
+	gasLimit, fee, err := c.transact.SuggestFee(current, req.Options.Multiplier, maxFee)
+	if err != nil {
+		return apiError(feeSuggestion, err)
+	}
+
 	// In the `parse` endpoint, we parse a transaction to produce the original metadata (and operations).
-	// Since we can only deduce the block hash from the transaction, we will omit the block height from
-	// the identifier here, to keep the data identical.
+	// The current block is pinned as the reference block of the transaction, which expires once the
+	// network moves past the expiry height.
 	res := response.Metadata{
 		Metadata: object.Metadata{
 			CurrentBlockID: current,
 			SequenceNumber: sequence,
+			ExpiryHeight:   *current.Index + flow.DefaultTransactionExpiry,
+			Roles:          req.Options.Roles,
+			GasLimit:       gasLimit,
+		},
+		SuggestedFee: []object.Amount{
+			{
+				Value: amount.Format(fee),
+				Currency: identifier.Currency{
+					Symbol:   dps.FlowSymbol,
+					Decimals: dps.FlowDecimals,
+				},
+			},
 		},
 	}
 
@@ -33,9 +33,13 @@ func (d *Data) Networks(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
-	// Get the network we are running on from the configuration.
+	// Get the networks we are running on from their configurations.
+	networkIDs := []identifier.Network{d.config.Network()}
+	for _, other := range d.networks {
+		networkIDs = append(networkIDs, other.config.Network())
+	}
 	res := response.Networks{
-		NetworkIDs: []identifier.Network{d.config.Network()},
+		NetworkIDs: networkIDs,
 	}
 
 	return ctx.JSON(statusOK, res)
@@ -17,6 +17,7 @@ package rosetta
 import (
 	"github.com/labstack/echo/v4"
 
+	"github.com/optakt/flow-rosetta/rosetta/meta"
 	"github.com/optakt/flow-rosetta/rosetta/request"
 	"github.com/optakt/flow-rosetta/rosetta/response"
 )
@@ -32,16 +33,25 @@ func (d *Data) Options(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	d = d.route(req.NetworkID)
 	err = d.validate.Request(req)
 	if err != nil {
 		return formatError(err)
 	}
 
+	// Each advertised error links to its documentation, like the errors that
+	// are actually returned.
+	definitions := d.config.Errors()
+	advertised := make([]meta.ErrorDefinition, 0, len(definitions))
+	for _, definition := range definitions {
+		advertised = append(advertised, documented(definition))
+	}
+
 	// Create the allow object, which is native to the response.
 	allow := response.OptionsAllow{
 		OperationStatuses:       d.config.Statuses(),
 		OperationTypes:          d.config.Operations(),
-		Errors:                  d.config.Errors(),
+		Errors:                  advertised,
 		HistoricalBalanceLookup: true,
 		CallMethods:             []string{},
 		BalanceExemptions:       []struct{}{},
@@ -40,7 +40,10 @@ func TestAPI_Options(t *testing.T) {
 	db := setupDB(t)
 	api := setupAPI(t, db)
 
-	const wantErrorCount = 23
+	// Legacy error codes are sequential, while later ones are assigned in the
+	// namespace of their subsystem.
+	const wantLegacyCount = 35
+	const wantErrorCount = wantLegacyCount + 4
 
 	// verify version string is in the format of x.y.z
 	versionRe := regexp.MustCompile(`\d+\.\d+\.\d+`)
@@ -67,18 +70,48 @@ func TestAPI_Options(t *testing.T) {
 
 	assert.True(t, options.Allow.HistoricalBalanceLookup)
 
-	require.Len(t, options.Allow.OperationStatuses, 1)
+	require.Len(t, options.Allow.OperationStatuses, 2)
 
 	status := options.Allow.OperationStatuses[0]
 	assert.Equal(t, status.Status, dps.StatusCompleted)
 	assert.True(t, status.Successful)
 
-	require.Len(t, options.Allow.OperationTypes, 1)
+	status = options.Allow.OperationStatuses[1]
+	assert.Equal(t, status.Status, configuration.StatusFailed.Status)
+	assert.False(t, status.Successful)
+
+	require.Len(t, options.Allow.OperationTypes, 7)
 	assert.Equal(t, options.Allow.OperationTypes[0], dps.OperationTransfer)
+	assert.Equal(t, options.Allow.OperationTypes[1], configuration.OperationFeeCollection)
+	assert.Equal(t, options.Allow.OperationTypes[2], configuration.OperationMint)
+	assert.Equal(t, options.Allow.OperationTypes[3], configuration.OperationBurn)
+	assert.Equal(t, options.Allow.OperationTypes[4], configuration.OperationTemplate)
+	assert.Equal(t, options.Allow.OperationTypes[5], configuration.OperationKeyAdd)
+	assert.Equal(t, options.Allow.OperationTypes[6], configuration.OperationKeyRevoke)
 
 	require.Len(t, options.Allow.Errors, wantErrorCount)
 
-	for i := uint(0); i < wantErrorCount; i++ {
+	fee := options.Allow.Errors[wantLegacyCount]
+	assert.Equal(t, configuration.ErrorInsufficientFee.Code, fee.Code)
+	assert.Equal(t, configuration.ErrorInsufficientFee.Message, fee.Message)
+	assert.Equal(t, configuration.ErrorInsufficientFee.Retriable, fee.Retriable)
+
+	conflict := options.Allow.Errors[wantLegacyCount+1]
+	assert.Equal(t, configuration.ErrorSequenceConflict.Code, conflict.Code)
+	assert.Equal(t, configuration.ErrorSequenceConflict.Message, conflict.Message)
+	assert.Equal(t, configuration.ErrorSequenceConflict.Retriable, conflict.Retriable)
+
+	failed := options.Allow.Errors[wantLegacyCount+2]
+	assert.Equal(t, configuration.ErrorFailedTransaction.Code, failed.Code)
+	assert.Equal(t, configuration.ErrorFailedTransaction.Message, failed.Message)
+	assert.Equal(t, configuration.ErrorFailedTransaction.Retriable, failed.Retriable)
+
+	disabled := options.Allow.Errors[wantLegacyCount+3]
+	assert.Equal(t, configuration.ErrorDisabledEndpoint.Code, disabled.Code)
+	assert.Equal(t, configuration.ErrorDisabledEndpoint.Message, disabled.Message)
+	assert.Equal(t, configuration.ErrorDisabledEndpoint.Retriable, disabled.Retriable)
+
+	for i := uint(0); i < wantLegacyCount; i++ {
 		rosettaErr := options.Allow.Errors[i]
 
 		expectedCode := i + 1 // error codes start from 1
@@ -177,6 +210,54 @@ func TestAPI_Options(t *testing.T) {
 			assert.Equal(t, configuration.ErrorInvalidSignatures.Message, rosettaErr.Message)
 			assert.Equal(t, configuration.ErrorInvalidSignatures.Retriable, rosettaErr.Retriable)
 
+		case configuration.ErrorUpstreamNotFound.Code:
+			assert.Equal(t, configuration.ErrorUpstreamNotFound.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorUpstreamNotFound.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorUpstreamOutOfRange.Code:
+			assert.Equal(t, configuration.ErrorUpstreamOutOfRange.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorUpstreamOutOfRange.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorUpstreamUnavailable.Code:
+			assert.Equal(t, configuration.ErrorUpstreamUnavailable.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorUpstreamUnavailable.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorUpstreamTimeout.Code:
+			assert.Equal(t, configuration.ErrorUpstreamTimeout.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorUpstreamTimeout.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorUnavailableBlock.Code:
+			assert.Equal(t, configuration.ErrorUnavailableBlock.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorUnavailableBlock.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorUnknownNode.Code:
+			assert.Equal(t, configuration.ErrorUnknownNode.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorUnknownNode.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorInsufficientBalance.Code:
+			assert.Equal(t, configuration.ErrorInsufficientBalance.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorInsufficientBalance.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorExpiredTransaction.Code:
+			assert.Equal(t, configuration.ErrorExpiredTransaction.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorExpiredTransaction.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorUncoveredBlock.Code:
+			assert.Equal(t, configuration.ErrorUncoveredBlock.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorUncoveredBlock.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorOverloaded.Code:
+			assert.Equal(t, configuration.ErrorOverloaded.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorOverloaded.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorLimitExceeded.Code:
+			assert.Equal(t, configuration.ErrorLimitExceeded.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorLimitExceeded.Retriable, rosettaErr.Retriable)
+
+		case configuration.ErrorUnsealedBlock.Code:
+			assert.Equal(t, configuration.ErrorUnsealedBlock.Message, rosettaErr.Message)
+			assert.Equal(t, configuration.ErrorUnsealedBlock.Retriable, rosettaErr.Retriable)
+
 		default:
 			t.Errorf("unknown rosetta error received: (code: %v, message: '%v', retriable: %v", rosettaErr.Code, rosettaErr.Message, rosettaErr.Retriable)
 		}
@@ -215,7 +296,7 @@ func TestAPI_OptionsHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidNetwork),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidNetwork),
 		},
 		{
 			name: "missing network",
@@ -237,7 +318,7 @@ func TestAPI_OptionsHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidNetwork),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidNetwork),
 		},
 	}
 
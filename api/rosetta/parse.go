@@ -17,6 +17,8 @@ package rosetta
 import (
 	"github.com/labstack/echo/v4"
 
+	"github.com/onflow/flow-go/model/flow"
+
 	"github.com/optakt/flow-rosetta/rosetta/object"
 	"github.com/optakt/flow-rosetta/rosetta/request"
 	"github.com/optakt/flow-rosetta/rosetta/response"
@@ -35,6 +37,7 @@ func (c *Construction) Parse(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	c = c.route(req.NetworkID)
 	err = c.validate.Request(req)
 	if err != nil {
 		return formatError(err)
@@ -64,6 +67,9 @@ func (c *Construction) Parse(ctx echo.Context) error {
 	metadata := object.Metadata{
 		CurrentBlockID: refBlockID,
 		SequenceNumber: sequence,
+		ExpiryHeight:   *refBlockID.Index + flow.DefaultTransactionExpiry,
+		Roles:          parse.Roles(),
+		GasLimit:       parse.GasLimit(),
 	}
 
 	res := response.Parse{
@@ -17,17 +17,19 @@ package rosetta
 import (
 	"github.com/labstack/echo/v4"
 
-	"github.com/optakt/flow-rosetta/rosetta/identifier"
 	"github.com/optakt/flow-rosetta/rosetta/object"
 	"github.com/optakt/flow-rosetta/rosetta/request"
 	"github.com/optakt/flow-rosetta/rosetta/response"
+	"github.com/optakt/flow-rosetta/rosetta/transactor"
 )
 
 // Payloads implements the /construction/payloads endpoint of the Rosetta Construction API.
 // It receives an array of operations and all other relevant information required to construct
 // an unsigned transaction. Operations must deterministically describe the intent of the
 // transaction. Besides the unsigned transaction text, this endpoint also returns the list
-// of payloads that should be signed.
+// of payloads that should be signed. When the metadata sets the detached or Ledger payload
+// format, the payloads hold the full transaction messages instead of their hashes, and the
+// response includes a human-readable summary of the transaction for offline signers.
 // See https://www.rosetta-api.org/docs/ConstructionApi.html#constructionpayloads
 func (c *Construction) Payloads(ctx echo.Context) error {
 
@@ -37,11 +39,20 @@ func (c *Construction) Payloads(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	c = c.route(req.NetworkID)
 	err = c.validate.Request(req)
 	if err != nil {
 		return formatError(err)
 	}
 
+	format := req.Metadata.PayloadFormat
+	if format != "" && format != transactor.FormatDetached && format != transactor.FormatLedger {
+		return httpError(invalidFormat(formatUnsupported,
+			withDetail("have_format", format),
+			withDetail("want_formats", []string{transactor.FormatDetached, transactor.FormatLedger}),
+		))
+	}
+
 	// Metadata object is the response from our metadata endpoint. Thus, the object
 	// should be okay, but let's validate it anyway.
 	err = c.validate.CompleteBlockID(req.Metadata.CurrentBlockID)
@@ -49,34 +60,37 @@ func (c *Construction) Payloads(ctx echo.Context) error {
 		return formatError(err)
 	}
 
-	intent, err := c.transact.DeriveIntent(req.Operations)
+	intent, err := c.transact.DeriveIntent(req.Operations, req.Metadata.Roles)
 	if err != nil {
 		return apiError(intentDetermination, err)
 	}
 
+	// The gas limit is not described by the operations, so it is taken from the
+	// metadata, when it was suggested by the metadata endpoint.
+	intent.GasLimit = req.Metadata.GasLimit
+
 	unsigned, err := c.transact.CompileTransaction(req.Metadata.CurrentBlockID, intent, req.Metadata.SequenceNumber)
 	if err != nil {
 		return apiError(txConstruction, err)
 	}
 
-	sender := identifier.Account{
-		Address: intent.From.String(),
+	// When the sender is also the payer and the proposer, it only needs to sign the
+	// transaction envelope. Otherwise, the sender and proposer sign the payload first.
+	var summary *object.Summary
+	var payloads []object.SigningPayload
+	if format != "" {
+		payloads, summary, err = c.transact.DetachedPayloads(unsigned, format)
+	} else {
+		payloads, err = c.transact.SigningPayloads(unsigned)
 	}
-	algo, hash, err := c.transact.HashPayload(req.Metadata.CurrentBlockID, unsigned, sender)
 	if err != nil {
 		return apiError(payloadHashing, err)
 	}
 
-	// We only support a single signer at the moment, so the account only needs to sign the transaction envelope.
 	res := response.Payloads{
 		Transaction: unsigned,
-		Payloads: []object.SigningPayload{
-			{
-				AccountID:     identifier.Account{Address: intent.From.Hex()},
-				HexBytes:      hash,
-				SignatureType: algo,
-			},
-		},
+		Payloads:    payloads,
+		Summary:     summary,
 	}
 
 	return ctx.JSON(statusOK, res)
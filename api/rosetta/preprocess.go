@@ -27,7 +27,8 @@ import (
 // Preprocess receives a list of operations that should deterministically specify the
 // intent of the transaction. Preprocess endpoint returns the `options` object that
 // will be sent **unmodified** to /construction/metadata, effectively creating the metadata
-// request.
+// request. A maximum fee and a suggested fee multiplier can be given to trade the cost of
+// the transaction for execution headroom.
 // See https://www.rosetta-api.org/docs/ConstructionApi.html#constructionpreprocess
 func (c *Construction) Preprocess(ctx echo.Context) error {
 
@@ -37,21 +38,34 @@ func (c *Construction) Preprocess(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	c = c.route(req.NetworkID)
 	err = c.validate.Request(req)
 	if err != nil {
 		return formatError(err)
 	}
 
-	intent, err := c.transact.DeriveIntent(req.Operations)
+	intent, err := c.transact.DeriveIntent(req.Operations, req.Metadata)
 	if err != nil {
 		return apiError(intentDetermination, err)
 	}
 
+	// The maximum fee has been validated to be a single amount of FLOW.
+	maxFee := ""
+	if len(req.MaxFee) > 0 {
+		maxFee = req.MaxFee[0].Value
+	}
+
+	// The sequence number needs to be retrieved for the proposer, which is
+	// the sender unless requested otherwise. The fee options are forwarded so
+	// that the gas limit and suggested fee can be computed from them.
 	res := response.Preprocess{
 		Options: object.Options{
 			AccountID: identifier.Account{
-				Address: intent.From.Hex(),
+				Address: intent.Proposer.Hex(),
 			},
+			Roles:      req.Metadata,
+			MaxFee:     maxFee,
+			Multiplier: req.Multiplier,
 		},
 	}
 
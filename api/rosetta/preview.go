@@ -0,0 +1,66 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+)
+
+// Preview implements the /construction/preview endpoint, which is an extension
+// to the Rosetta Construction API. It executes the given transaction against
+// the indexed state at its reference block, and returns the operations it is
+// expected to produce along with the fee charged to its payer, or the error it
+// is expected to fail with. This lets wallets catch failures, such as an
+// insufficient balance, before broadcasting.
+func (c *Construction) Preview(ctx echo.Context) error {
+
+	var req request.Preview
+	err := ctx.Bind(&req)
+	if err != nil {
+		return unpackError(err)
+	}
+
+	c = c.route(req.NetworkID)
+	err = c.validate.Request(req)
+	if err != nil {
+		return formatError(err)
+	}
+
+	rosBlockID, operations, fee, err := c.transact.Simulate(req.Transaction)
+	if err != nil {
+		return apiError(txSimulation, err)
+	}
+
+	res := response.Preview{
+		BlockID:    rosBlockID,
+		Operations: operations,
+		Fee: object.Amount{
+			Value: amount.Format(fee),
+			Currency: identifier.Currency{
+				Symbol:   dps.FlowSymbol,
+				Decimals: dps.FlowDecimals,
+			},
+		},
+	}
+
+	return ctx.JSON(statusOK, res)
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/optakt/flow-rosetta/rosetta/response"
+)
+
+// Ready returns a handler for the readiness endpoint, which reports the service
+// as unavailable while the index lags too far behind the Flow network, so that
+// load balancers can take stale replicas out of rotation.
+func Ready(monitor Monitor) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+
+		status, err := monitor.Ready()
+
+		res := response.Ready{
+			Ready:         err == nil,
+			IndexedHeight: status.Indexed,
+			SealedHeight:  status.Sealed,
+			LagBlocks:     status.Blocks,
+			LagSeconds:    uint64(status.Delay.Seconds()),
+		}
+		if err != nil {
+			res.Reason = err.Error()
+			return ctx.JSON(http.StatusServiceUnavailable, res)
+		}
+
+		return ctx.JSON(http.StatusOK, res)
+	}
+}
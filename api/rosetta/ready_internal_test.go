@@ -0,0 +1,85 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/lag"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestReady(t *testing.T) {
+
+	serve := func(monitor Monitor) (*httptest.ResponseRecorder, response.Ready) {
+		server := echo.New()
+		server.GET("/health/ready", Ready(monitor))
+
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+		var res response.Ready
+		err := json.Unmarshal(rec.Body.Bytes(), &res)
+		require.NoError(t, err)
+
+		return rec, res
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		monitor := mocks.BaselineMonitor(t)
+		monitor.ReadyFunc = func() (lag.Status, error) {
+			return lag.Status{Indexed: 40, Sealed: 42, Blocks: 2, Delay: 3 * time.Second}, nil
+		}
+
+		rec, res := serve(monitor)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		want := response.Ready{
+			Ready:         true,
+			IndexedHeight: 40,
+			SealedHeight:  42,
+			LagBlocks:     2,
+			LagSeconds:    3,
+		}
+		assert.Equal(t, want, res)
+	})
+
+	t.Run("not ready while lagging", func(t *testing.T) {
+		t.Parallel()
+
+		monitor := mocks.BaselineMonitor(t)
+		monitor.ReadyFunc = func() (lag.Status, error) {
+			return lag.Status{Indexed: 40, Sealed: 142, Blocks: 102}, mocks.GenericError
+		}
+
+		rec, res := serve(monitor)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.False(t, res.Ready)
+		assert.Equal(t, uint64(102), res.LagBlocks)
+		assert.Equal(t, mocks.GenericError.Error(), res.Reason)
+	})
+}
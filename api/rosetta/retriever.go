@@ -24,8 +24,17 @@ import (
 type Retriever interface {
 	Oldest() (identifier.Block, time.Time, error)
 	Current() (identifier.Block, time.Time, error)
+	BlockID(rosBlockID identifier.Block) (identifier.Block, error)
+	Child(rosBlockID identifier.Block) (identifier.Block, identifier.Block, error)
 	Block(rosBlockID identifier.Block) (*object.Block, []identifier.Transaction, error)
+	Blocks(rosStart identifier.Block, rosEnd identifier.Block) ([]*object.Block, [][]identifier.Transaction, error)
 	Transaction(rosBlockID identifier.Block, rosTxID identifier.Transaction) (*object.Transaction, error)
+	Lookup(rosTxID identifier.Transaction) (identifier.Block, *object.Transaction, error)
 	Balances(rosBlockID identifier.Block, rosAccountID identifier.Account, rosCurrencies []identifier.Currency) (identifier.Block, []object.Amount, error)
+	Keys(rosBlockID identifier.Block, rosAccountID identifier.Account) ([]object.AccountKey, error)
 	Sequence(rosBlockID identifier.Block, rosAccountID identifier.Account, index int) (uint64, error)
+	Node(rosBlockID identifier.Block, nodeID string) (identifier.Block, *object.Node, error)
+	Rewards(nodeID string, delegatorID *uint32, rosStart identifier.Block, rosEnd identifier.Block) ([]object.Reward, *identifier.Block, error)
+	AccountTransactions(rosAccountID identifier.Account, rosStart identifier.Block, rosEnd identifier.Block, index uint, limit uint) ([]object.BlockTransaction, *identifier.Block, uint, error)
+	Search(rosBlockID identifier.Block, index uint, limit uint, rosAccountID *identifier.Account, match func(*object.Transaction) bool) ([]object.BlockTransaction, *identifier.Block, uint, error)
 }
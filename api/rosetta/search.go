@@ -0,0 +1,259 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+)
+
+// Search operators, as defined by the Rosetta API specification.
+const (
+	operatorAnd = "and"
+	operatorOr  = "or"
+)
+
+const (
+	// defaultSearchLimit is the number of transactions returned when the
+	// request does not specify a limit.
+	defaultSearchLimit = 25
+
+	// maxSearchLimit is the maximum number of transactions per page, whatever
+	// limit the request specifies.
+	maxSearchLimit = 100
+
+	// cursorSize is the size of a decoded search cursor, which contains the
+	// height of the block, the index of the transaction within the block, and
+	// the number of matches before that transaction.
+	cursorSize = 24
+)
+
+// SearchTransactions implements the /search/transactions endpoint of the Rosetta Data API.
+// Transactions are searched from the most recent block downwards. Next to the
+// offset from the specification, each page returns an opaque cursor, which lets
+// the client resume the search without walking through all previous matches.
+// See https://www.rosetta-api.org/docs/SearchApi.html#searchtransactions
+func (d *Data) SearchTransactions(ctx echo.Context) error {
+
+	var req request.SearchTransactions
+	err := ctx.Bind(&req)
+	if err != nil {
+		return unpackError(err)
+	}
+
+	d = d.route(req.NetworkID)
+	err = d.validate.Request(req)
+	if err != nil {
+		return formatError(err)
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	// Without a cursor, the search starts at the maximum block, or at the most
+	// recent one, and has to skip the requested offset of matches.
+	rosBlockID := identifier.Block{Index: req.MaxBlock}
+	index := uint(0)
+	seen := uint64(0)
+	if req.Cursor != "" {
+		height, txIndex, count, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return httpError(invalidFormat(invalidCursor,
+				withDetail("cursor", req.Cursor),
+				withError(err),
+			))
+		}
+		if count > req.Offset {
+			return httpError(invalidFormat(cursorMismatch,
+				withDetail("cursor_offset", count),
+				withDetail("offset", req.Offset),
+			))
+		}
+		rosBlockID = identifier.Block{Index: &height}
+		index = txIndex
+		seen = count
+	}
+	skip := req.Offset - seen
+
+	matches, next, nextIndex, err := d.retrieve.Search(rosBlockID, index, uint(skip)+limit, account(req), d.match(req))
+	if err != nil {
+		return apiError(txSearch, err)
+	}
+
+	// Matches up to the offset are only counted, so that the page starts at the
+	// requested offset, even if that takes several requests to reach.
+	skipped := uint64(len(matches))
+	if skipped > skip {
+		skipped = skip
+	}
+	seen += skipped
+	page := matches[skipped:]
+
+	res := response.SearchTransactions{
+		Transactions: page,
+		TotalCount:   seen + uint64(len(page)),
+	}
+	if res.Transactions == nil {
+		res.Transactions = []object.BlockTransaction{}
+	}
+	if next != nil {
+		nextOffset := res.TotalCount
+		if seen < req.Offset {
+			nextOffset = req.Offset
+		}
+		res.NextOffset = &nextOffset
+		res.NextCursor = encodeCursor(*next.Index, nextIndex, res.TotalCount)
+	}
+
+	return ctx.JSON(statusOK, res)
+}
+
+// account returns the account that all matching transactions have to affect,
+// if there is one. It lets the retriever skip blocks in which the account was
+// not active.
+func account(req request.SearchTransactions) *identifier.Account {
+	if req.Operator == operatorOr {
+		return nil
+	}
+	if req.AccountID != nil {
+		return req.AccountID
+	}
+	if req.Address != "" {
+		return &identifier.Account{Address: req.Address}
+	}
+	return nil
+}
+
+// match returns a function that checks whether a transaction matches the
+// conditions of the given search request. Without any conditions, every
+// transaction matches.
+func (d *Data) match(req request.SearchTransactions) func(*object.Transaction) bool {
+
+	var conditions []func(*object.Transaction) bool
+	if req.TransactionID != nil {
+		hash := req.TransactionID.Hash
+		conditions = append(conditions, func(rosTx *object.Transaction) bool {
+			return rosTx.ID.Hash == hash
+		})
+	}
+	if req.AccountID != nil {
+		address := req.AccountID.Address
+		conditions = append(conditions, anyOperation(func(op *object.Operation) bool {
+			return op.AccountID.Address == address
+		}))
+	}
+	if req.Address != "" {
+		address := req.Address
+		conditions = append(conditions, anyOperation(func(op *object.Operation) bool {
+			return op.AccountID.Address == address
+		}))
+	}
+	if req.Currency != nil {
+		currency := *req.Currency
+		conditions = append(conditions, anyOperation(func(op *object.Operation) bool {
+			return op.Amount.Currency.Symbol == currency.Symbol &&
+				(currency.Decimals == 0 || op.Amount.Currency.Decimals == currency.Decimals)
+		}))
+	}
+	if req.Status != "" {
+		status := req.Status
+		conditions = append(conditions, anyOperation(func(op *object.Operation) bool {
+			return op.Status == status
+		}))
+	}
+	if req.Type != "" {
+		opType := req.Type
+		conditions = append(conditions, anyOperation(func(op *object.Operation) bool {
+			return op.Type == opType
+		}))
+	}
+	if req.Success != nil {
+		successful := make(map[string]bool)
+		for _, status := range d.config.Statuses() {
+			successful[status.Status] = status.Successful
+		}
+		success := *req.Success
+		conditions = append(conditions, func(rosTx *object.Transaction) bool {
+			for _, op := range rosTx.Operations {
+				if !successful[op.Status] {
+					return !success
+				}
+			}
+			return success
+		})
+	}
+
+	return func(rosTx *object.Transaction) bool {
+		if len(conditions) == 0 {
+			return true
+		}
+		for _, condition := range conditions {
+			matched := condition(rosTx)
+			if req.Operator == operatorOr && matched {
+				return true
+			}
+			if req.Operator != operatorOr && !matched {
+				return false
+			}
+		}
+		return req.Operator != operatorOr
+	}
+}
+
+func anyOperation(match func(*object.Operation) bool) func(*object.Transaction) bool {
+	return func(rosTx *object.Transaction) bool {
+		for _, op := range rosTx.Operations {
+			if match(op) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func encodeCursor(height uint64, index uint, count uint64) string {
+	data := make([]byte, cursorSize)
+	binary.BigEndian.PutUint64(data[0:8], height)
+	binary.BigEndian.PutUint64(data[8:16], uint64(index))
+	binary.BigEndian.PutUint64(data[16:24], count)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string) (uint64, uint, uint64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("could not decode cursor: %w", err)
+	}
+	if len(data) != cursorSize {
+		return 0, 0, 0, fmt.Errorf("invalid cursor length (have: %d, want: %d)", len(data), cursorSize)
+	}
+	height := binary.BigEndian.Uint64(data[0:8])
+	index := binary.BigEndian.Uint64(data[8:16])
+	count := binary.BigEndian.Uint64(data[16:24])
+	return height, uint(index), count, nil
+}
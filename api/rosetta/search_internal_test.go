@@ -0,0 +1,274 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestData_SearchTransactions(t *testing.T) {
+	height := uint64(42)
+	matches := []object.BlockTransaction{
+		{BlockID: mocks.GenericRosBlockID, Transaction: mocks.GenericRosTransaction(0)},
+		{BlockID: mocks.GenericRosBlockID, Transaction: mocks.GenericRosTransaction(1)},
+		{BlockID: mocks.GenericRosBlockID, Transaction: mocks.GenericRosTransaction(2)},
+	}
+
+	search := func(t *testing.T, d *Data, req request.SearchTransactions) (int, []byte) {
+		t.Helper()
+
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/search/transactions", strings.NewReader(string(body))), rec)
+		ctx.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		err = d.SearchTransactions(ctx)
+		if err != nil {
+			herr, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			return herr.Code, nil
+		}
+		return rec.Code, rec.Body.Bytes()
+	}
+
+	t.Run("nominal case with next cursor", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.SearchFunc = func(rosBlockID identifier.Block, index uint, limit uint, _ *identifier.Account, _ func(*object.Transaction) bool) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+			assert.Nil(t, rosBlockID.Index)
+			assert.Zero(t, index)
+			assert.Equal(t, uint(2), limit)
+			return matches[:2], &identifier.Block{Index: &height}, 1, nil
+		}
+
+		d := NewData(configuration.New(flow.Testnet), retrieve, mocks.BaselineValidator(t))
+
+		status, body := search(t, d, request.SearchTransactions{Limit: 2})
+
+		require.Equal(t, statusOK, status)
+		var res response.SearchTransactions
+		require.NoError(t, json.Unmarshal(body, &res))
+		assert.Equal(t, matches[:2], res.Transactions)
+		assert.Equal(t, uint64(2), res.TotalCount)
+		require.NotNil(t, res.NextOffset)
+		assert.Equal(t, uint64(2), *res.NextOffset)
+		assert.Equal(t, encodeCursor(height, 1, 2), res.NextCursor)
+	})
+
+	t.Run("resumes from cursor", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.SearchFunc = func(rosBlockID identifier.Block, index uint, limit uint, _ *identifier.Account, _ func(*object.Transaction) bool) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+			require.NotNil(t, rosBlockID.Index)
+			assert.Equal(t, height, *rosBlockID.Index)
+			assert.Equal(t, uint(1), index)
+			assert.Equal(t, uint(2), limit)
+			return matches[2:], nil, 0, nil
+		}
+
+		d := NewData(configuration.New(flow.Testnet), retrieve, mocks.BaselineValidator(t))
+
+		status, body := search(t, d, request.SearchTransactions{Limit: 2, Offset: 2, Cursor: encodeCursor(height, 1, 2)})
+
+		require.Equal(t, statusOK, status)
+		var res response.SearchTransactions
+		require.NoError(t, json.Unmarshal(body, &res))
+		assert.Equal(t, matches[2:], res.Transactions)
+		assert.Equal(t, uint64(3), res.TotalCount)
+		assert.Nil(t, res.NextOffset)
+		assert.Empty(t, res.NextCursor)
+	})
+
+	t.Run("skips matches up to offset", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.SearchFunc = func(_ identifier.Block, _ uint, limit uint, _ *identifier.Account, _ func(*object.Transaction) bool) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+			assert.Equal(t, uint(4), limit)
+			return matches, nil, 0, nil
+		}
+
+		d := NewData(configuration.New(flow.Testnet), retrieve, mocks.BaselineValidator(t))
+
+		status, body := search(t, d, request.SearchTransactions{Limit: 2, Offset: 2})
+
+		require.Equal(t, statusOK, status)
+		var res response.SearchTransactions
+		require.NoError(t, json.Unmarshal(body, &res))
+		assert.Equal(t, matches[2:], res.Transactions)
+		assert.Equal(t, uint64(3), res.TotalCount)
+	})
+
+	t.Run("keeps offset when search stops before reaching it", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.SearchFunc = func(identifier.Block, uint, uint, *identifier.Account, func(*object.Transaction) bool) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+			return matches[:1], &identifier.Block{Index: &height}, 0, nil
+		}
+
+		d := NewData(configuration.New(flow.Testnet), retrieve, mocks.BaselineValidator(t))
+
+		status, body := search(t, d, request.SearchTransactions{Limit: 2, Offset: 5})
+
+		require.Equal(t, statusOK, status)
+		var res response.SearchTransactions
+		require.NoError(t, json.Unmarshal(body, &res))
+		assert.Empty(t, res.Transactions)
+		require.NotNil(t, res.NextOffset)
+		assert.Equal(t, uint64(5), *res.NextOffset)
+		assert.Equal(t, encodeCursor(height, 0, 1), res.NextCursor)
+	})
+
+	t.Run("handles invalid cursor", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewData(configuration.New(flow.Testnet), mocks.BaselineRetriever(t), mocks.BaselineValidator(t))
+
+		status, _ := search(t, d, request.SearchTransactions{Cursor: "not a cursor"})
+
+		assert.NotEqual(t, statusOK, status)
+	})
+
+	t.Run("handles cursor beyond offset", func(t *testing.T) {
+		t.Parallel()
+
+		d := NewData(configuration.New(flow.Testnet), mocks.BaselineRetriever(t), mocks.BaselineValidator(t))
+
+		status, _ := search(t, d, request.SearchTransactions{Cursor: encodeCursor(height, 0, 10), Offset: 2})
+
+		assert.NotEqual(t, statusOK, status)
+	})
+
+	t.Run("handles retriever failure", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.SearchFunc = func(identifier.Block, uint, uint, *identifier.Account, func(*object.Transaction) bool) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+			return nil, nil, 0, mocks.GenericError
+		}
+
+		d := NewData(configuration.New(flow.Testnet), retrieve, mocks.BaselineValidator(t))
+
+		status, _ := search(t, d, request.SearchTransactions{})
+
+		assert.NotEqual(t, statusOK, status)
+	})
+}
+
+func TestData_Match(t *testing.T) {
+	d := NewData(configuration.New(flow.Testnet), mocks.BaselineRetriever(t), mocks.BaselineValidator(t))
+	rosTx := mocks.GenericRosTransaction(0)
+	account := rosTx.Operations[0].AccountID
+	other := mocks.GenericAccountID(2)
+	failed := false
+
+	tests := []struct {
+		name string
+		req  request.SearchTransactions
+		want bool
+	}{
+		{
+			name: "no conditions",
+			req:  request.SearchTransactions{},
+			want: true,
+		},
+		{
+			name: "matching account",
+			req:  request.SearchTransactions{AccountID: &account},
+			want: true,
+		},
+		{
+			name: "other account",
+			req:  request.SearchTransactions{AccountID: &other},
+			want: false,
+		},
+		{
+			name: "matching account and other transaction",
+			req:  request.SearchTransactions{AccountID: &account, TransactionID: &identifier.Transaction{Hash: "other"}},
+			want: false,
+		},
+		{
+			name: "matching account or other transaction",
+			req:  request.SearchTransactions{Operator: operatorOr, AccountID: &account, TransactionID: &identifier.Transaction{Hash: "other"}},
+			want: true,
+		},
+		{
+			name: "other account or other address",
+			req:  request.SearchTransactions{Operator: operatorOr, AccountID: &other, Address: other.Address},
+			want: false,
+		},
+		{
+			name: "matching type and status",
+			req:  request.SearchTransactions{Type: rosTx.Operations[0].Type, Status: rosTx.Operations[0].Status},
+			want: true,
+		},
+		{
+			name: "matching currency",
+			req:  request.SearchTransactions{Currency: &mocks.GenericCurrency},
+			want: true,
+		},
+		{
+			name: "failed transaction",
+			req:  request.SearchTransactions{Success: &failed},
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			match := d.match(test.req)
+
+			assert.Equal(t, test.want, match(rosTx))
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	cursor := encodeCursor(42, 7, 1337)
+
+	height, index, count, err := decodeCursor(cursor)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), height)
+	assert.Equal(t, uint(7), index)
+	assert.Equal(t, uint64(1337), count)
+
+	_, _, _, err = decodeCursor(cursor[:10])
+	assert.Error(t, err)
+}
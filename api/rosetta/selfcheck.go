@@ -0,0 +1,320 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/rs/zerolog"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/interop"
+)
+
+// SelfCheck returns a middleware that runs every outgoing response of the
+// Rosetta API through the asserter of the Rosetta SDK, which clients such as
+// the Rosetta CLI use to validate the responses they receive. Violations do not
+// change the response; they are logged as warnings, so that drift from the
+// specification is caught in staging before it breaks any integration.
+// Responses are checked against the configuration of the network named in
+// their request, and against the first configuration when the request names
+// none of them.
+func SelfCheck(log zerolog.Logger, configs ...Configuration) (echo.MiddlewareFunc, error) {
+
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no network configuration to check responses against")
+	}
+
+	checks := make([]*checker, 0, len(configs))
+	for _, config := range configs {
+		check, err := newChecker(config)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize checker (network: %s): %w", config.Network().Network, err)
+		}
+		checks = append(checks, check)
+	}
+	route := func(req []byte) *checker {
+		var body struct {
+			NetworkID identifier.Network `json:"network_identifier"`
+		}
+		_ = json.Unmarshal(req, &body)
+		for _, check := range checks {
+			if sameNetwork(check.network, body.NetworkID) {
+				return check
+			}
+		}
+		return checks[0]
+	}
+
+	// The stream endpoint is skipped, as its response never ends and would
+	// otherwise be buffered in its entirety.
+	skip := func(ctx echo.Context) bool {
+		return ctx.Path() == "/flow/stream"
+	}
+
+	check := middleware.BodyDumpWithConfig(middleware.BodyDumpConfig{
+		Skipper: skip,
+		Handler: func(ctx echo.Context, req []byte, res []byte) {
+			err := route(req).Response(ctx.Path(), ctx.Response().Status, req, res)
+			if err != nil {
+				log.Warn().
+					Str("path", ctx.Path()).
+					Int("status", ctx.Response().Status).
+					Err(err).
+					Msg("response violates Rosetta specification")
+			}
+		},
+	})
+
+	return check, nil
+}
+
+// checker checks the responses for a single network with the asserter of the
+// Rosetta SDK. The asserter needs the genesis block of the network, which is
+// only known once a response of the /network/status endpoint has been checked,
+// so it is rebuilt whenever the advertised genesis block changes.
+type checker struct {
+	network    identifier.Network
+	sdkNetwork *types.NetworkIdentifier
+	operations []string
+	statuses   []*types.OperationStatus
+	errors     []*types.Error
+
+	mutex   sync.RWMutex
+	genesis *types.BlockIdentifier
+	assert  *asserter.Asserter
+}
+
+func newChecker(config Configuration) (*checker, error) {
+
+	c := checker{
+		network:    config.Network(),
+		operations: config.Operations(),
+	}
+	err := interop.Convert(config.Network(), &c.sdkNetwork)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert network identifier: %w", err)
+	}
+	err = interop.Convert(config.Statuses(), &c.statuses)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert status definitions: %w", err)
+	}
+	err = interop.Convert(config.Errors(), &c.errors)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert error definitions: %w", err)
+	}
+
+	// Until the genesis block is known, the asserter is given a placeholder at
+	// index zero, below any block that the index of a spork can hold.
+	c.assert, err = c.build(&types.BlockIdentifier{Index: 0, Hash: "genesis"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// Response checks the encoded response body for the given endpoint path, along
+// with the request it answers. Endpoints without a dedicated check are accepted
+// as they are.
+func (c *checker) Response(path string, status int, req []byte, res []byte) error {
+
+	if status != statusOK {
+		var rosErr types.Error
+		err := json.Unmarshal(res, &rosErr)
+		if err != nil {
+			return fmt.Errorf("could not decode error: %w", err)
+		}
+		return c.current().Error(&rosErr)
+	}
+
+	switch path {
+
+	case "/network/status":
+		var status types.NetworkStatusResponse
+		err := json.Unmarshal(res, &status)
+		if err != nil {
+			return fmt.Errorf("could not decode status: %w", err)
+		}
+		err = asserter.NetworkStatusResponse(&status)
+		if err != nil {
+			return err
+		}
+		return c.learn(status.GenesisBlockIdentifier)
+
+	case "/account/balance":
+		var request struct {
+			BlockID *types.PartialBlockIdentifier `json:"block_identifier"`
+		}
+		_ = json.Unmarshal(req, &request)
+		var balance types.AccountBalanceResponse
+		err := json.Unmarshal(res, &balance)
+		if err != nil {
+			return fmt.Errorf("could not decode balance: %w", err)
+		}
+		return asserter.AccountBalanceResponse(request.BlockID, &balance)
+
+	case "/block":
+		var block types.BlockResponse
+		err := json.Unmarshal(res, &block)
+		if err != nil {
+			return fmt.Errorf("could not decode block: %w", err)
+		}
+		return c.Block(block)
+
+	case "/flow/blocks":
+		var blocks struct {
+			Blocks []types.BlockResponse `json:"blocks"`
+		}
+		err := json.Unmarshal(res, &blocks)
+		if err != nil {
+			return fmt.Errorf("could not decode blocks: %w", err)
+		}
+		for _, block := range blocks.Blocks {
+			err = c.Block(block)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "/block/transaction":
+		var transaction types.BlockTransactionResponse
+		err := json.Unmarshal(res, &transaction)
+		if err != nil {
+			return fmt.Errorf("could not decode transaction: %w", err)
+		}
+		return c.current().Transaction(transaction.Transaction)
+
+	case "/search/transactions":
+		var search types.SearchTransactionsResponse
+		err := json.Unmarshal(res, &search)
+		if err != nil {
+			return fmt.Errorf("could not decode search: %w", err)
+		}
+		return c.current().SearchTransactionsResponse(&search)
+
+	default:
+		return nil
+	}
+}
+
+// Block checks the response of the /block endpoint. The SDK leaves the other
+// transactions of a block to the client that fetches them, so their
+// identifiers are checked here, along with the uniqueness of all the
+// transactions of the block.
+func (c *checker) Block(res types.BlockResponse) error {
+
+	assert := c.current()
+
+	// A block that is its own parent claims to be the genesis block, which
+	// cannot be verified before the genesis block is known.
+	block := res.Block
+	if !c.known() && block != nil && block.BlockIdentifier != nil && block.ParentBlockIdentifier != nil &&
+		block.BlockIdentifier.Hash == block.ParentBlockIdentifier.Hash {
+		var err error
+		assert, err = c.build(block.BlockIdentifier)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := assert.Block(block)
+	if err != nil {
+		return err
+	}
+
+	hashes := make(map[string]struct{}, len(block.Transactions)+len(res.OtherTransactions))
+	for _, transaction := range block.Transactions {
+		hashes[transaction.TransactionIdentifier.Hash] = struct{}{}
+	}
+	if len(hashes) != len(block.Transactions) {
+		return fmt.Errorf("block contains duplicate transactions")
+	}
+	for _, txID := range res.OtherTransactions {
+		err = asserter.TransactionIdentifier(txID)
+		if err != nil {
+			return fmt.Errorf("invalid other transaction: %w", err)
+		}
+		_, ok := hashes[txID.Hash]
+		if ok {
+			return fmt.Errorf("duplicate transaction hash %s", txID.Hash)
+		}
+		hashes[txID.Hash] = struct{}{}
+	}
+
+	return nil
+}
+
+// learn rebuilds the asserter when the network advertises a new genesis block.
+func (c *checker) learn(genesis *types.BlockIdentifier) error {
+
+	c.mutex.RLock()
+	same := c.genesis != nil && c.genesis.Index == genesis.Index && c.genesis.Hash == genesis.Hash
+	c.mutex.RUnlock()
+	if same {
+		return nil
+	}
+
+	assert, err := c.build(genesis)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.genesis = genesis
+	c.assert = assert
+
+	return nil
+}
+
+// known returns whether the genesis block of the network is known.
+func (c *checker) known() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.genesis != nil
+}
+
+// current returns the asserter for the latest known genesis block.
+func (c *checker) current() *asserter.Asserter {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.assert
+}
+
+// build builds an asserter of the Rosetta SDK for the given genesis block,
+// with the operation types, statuses and errors of the network.
+func (c *checker) build(genesis *types.BlockIdentifier) (*asserter.Asserter, error) {
+	assert, err := asserter.NewClientWithOptions(
+		c.sdkNetwork,
+		genesis,
+		c.operations,
+		c.statuses,
+		c.errors,
+		nil,
+		&asserter.Validations{Enabled: false},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize asserter: %w", err)
+	}
+	return assert, nil
+}
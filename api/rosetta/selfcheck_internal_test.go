@@ -0,0 +1,404 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/meta"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+)
+
+func TestChecker_Response(t *testing.T) {
+	check, err := newChecker(configuration.New(flow.Testnet))
+	require.NoError(t, err)
+
+	index := uint64(42)
+	parent := uint64(41)
+	validBlock := func() *object.Block {
+		return &object.Block{
+			ID:        identifier.Block{Index: &index, Hash: "block"},
+			ParentID:  identifier.Block{Index: &parent, Hash: "parent"},
+			Timestamp: 1_600_000_000_000,
+			Transactions: []*object.Transaction{
+				{
+					ID: identifier.Transaction{Hash: "tx"},
+					Operations: []*object.Operation{
+						{
+							ID:        identifier.Operation{Index: 0},
+							Type:      configuration.OperationTransfer,
+							Status:    configuration.StatusCompleted.Status,
+							AccountID: identifier.Account{Address: "0x1"},
+							Amount:    object.Amount{Value: "-100", Currency: identifier.Currency{Symbol: "FLOW", Decimals: 8}},
+						},
+						{
+							ID:         identifier.Operation{Index: 1},
+							RelatedIDs: []identifier.Operation{{Index: 0}},
+							Type:       configuration.OperationTransfer,
+							Status:     configuration.StatusCompleted.Status,
+							AccountID:  identifier.Account{Address: "0x2"},
+							Amount:     object.Amount{Value: "100", Currency: identifier.Currency{Symbol: "FLOW", Decimals: 8}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		status  int
+		res     interface{}
+		checkFn assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "valid block",
+			path:    "/block",
+			status:  http.StatusOK,
+			res:     response.Block{Block: validBlock()},
+			checkFn: assert.NoError,
+		},
+		{
+			name:   "block with missing hash",
+			path:   "/block",
+			status: http.StatusOK,
+			res: func() response.Block {
+				block := validBlock()
+				block.ID.Hash = ""
+				return response.Block{Block: block}
+			}(),
+			checkFn: assert.Error,
+		},
+		{
+			name:   "block with parent above block",
+			path:   "/block",
+			status: http.StatusOK,
+			res: func() response.Block {
+				block := validBlock()
+				block.ParentID.Index = &index
+				block.ID.Index = &parent
+				return response.Block{Block: block}
+			}(),
+			checkFn: assert.Error,
+		},
+		{
+			name:   "block with timestamp in seconds",
+			path:   "/block",
+			status: http.StatusOK,
+			res: func() response.Block {
+				block := validBlock()
+				block.Timestamp = 1_600_000_000
+				return response.Block{Block: block}
+			}(),
+			checkFn: assert.Error,
+		},
+		{
+			name:   "block with duplicate transaction",
+			path:   "/block",
+			status: http.StatusOK,
+			res: func() response.Block {
+				block := validBlock()
+				return response.Block{Block: block, OtherTransactions: []identifier.Transaction{block.Transactions[0].ID}}
+			}(),
+			checkFn: assert.Error,
+		},
+		{
+			name:   "operation with out of order index",
+			path:   "/block",
+			status: http.StatusOK,
+			res: func() response.Block {
+				block := validBlock()
+				block.Transactions[0].Operations[1].ID.Index = 2
+				return response.Block{Block: block}
+			}(),
+			checkFn: assert.Error,
+		},
+		{
+			name:   "operation with missing status",
+			path:   "/block/transaction",
+			status: http.StatusOK,
+			res: func() response.Transaction {
+				transaction := validBlock().Transactions[0]
+				transaction.Operations[0].Status = ""
+				return response.Transaction{Transaction: transaction}
+			}(),
+			checkFn: assert.Error,
+		},
+		{
+			name:   "operation with unknown type",
+			path:   "/block/transaction",
+			status: http.StatusOK,
+			res: func() response.Transaction {
+				transaction := validBlock().Transactions[0]
+				transaction.Operations[0].Type = "STAKE"
+				return response.Transaction{Transaction: transaction}
+			}(),
+			checkFn: assert.Error,
+		},
+		{
+			name:   "balance with non-integer value",
+			path:   "/account/balance",
+			status: http.StatusOK,
+			res: response.Balance{
+				BlockID:  identifier.Block{Index: &index, Hash: "block"},
+				Balances: []object.Amount{{Value: "1.5", Currency: identifier.Currency{Symbol: "FLOW", Decimals: 8}}},
+			},
+			checkFn: assert.Error,
+		},
+		{
+			name:   "status with missing genesis",
+			path:   "/network/status",
+			status: http.StatusOK,
+			res: response.Status{
+				CurrentBlockID:        identifier.Block{Index: &index, Hash: "block"},
+				CurrentBlockTimestamp: 1_600_000_000_000,
+				OldestBlockID:         identifier.Block{Index: &parent, Hash: "parent"},
+			},
+			checkFn: assert.Error,
+		},
+		{
+			name:    "advertised error",
+			path:    "/block",
+			status:  http.StatusInternalServerError,
+			res:     Error{ErrorDefinition: configuration.ErrorUnknownBlock, Description: "block not found"},
+			checkFn: assert.NoError,
+		},
+		{
+			name:   "error with mismatching message",
+			path:   "/block",
+			status: http.StatusInternalServerError,
+			res: Error{Description: "block not found", ErrorDefinition: func() meta.ErrorDefinition {
+				definition := configuration.ErrorUnknownBlock
+				definition.Message = "unknown"
+				return definition
+			}()},
+			checkFn: assert.Error,
+		},
+		{
+			name:   "unadvertised error",
+			path:   "/block",
+			status: http.StatusInternalServerError,
+			res: Error{Description: "block not found", ErrorDefinition: func() meta.ErrorDefinition {
+				definition := configuration.ErrorUnknownBlock
+				definition.Code = 999
+				return definition
+			}()},
+			checkFn: assert.Error,
+		},
+		{
+			name:    "unchecked endpoint",
+			path:    "/network/list",
+			status:  http.StatusOK,
+			res:     struct{}{},
+			checkFn: assert.NoError,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			body, err := json.Marshal(test.res)
+			require.NoError(t, err)
+
+			err = check.Response(test.path, test.status, nil, body)
+			test.checkFn(t, err)
+		})
+	}
+}
+
+func TestChecker_Genesis(t *testing.T) {
+	index := uint64(42)
+	other := uint64(43)
+	root := func() []byte {
+		block := object.Block{
+			ID:        identifier.Block{Index: &index, Hash: "root"},
+			ParentID:  identifier.Block{Index: &index, Hash: "root"},
+			Timestamp: 1_600_000_000_000,
+		}
+		body, err := json.Marshal(response.Block{Block: &block})
+		require.NoError(t, err)
+		return body
+	}
+	status := func(genesis identifier.Block) []byte {
+		body, err := json.Marshal(response.Status{
+			CurrentBlockID:        identifier.Block{Index: &other, Hash: "current"},
+			CurrentBlockTimestamp: 1_600_000_000_000,
+			OldestBlockID:         genesis,
+			GenesisBlockID:        genesis,
+		})
+		require.NoError(t, err)
+		return body
+	}
+
+	t.Run("accepts block that is its own parent before genesis is known", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := newChecker(configuration.New(flow.Testnet))
+		require.NoError(t, err)
+
+		err = check.Response("/block", http.StatusOK, nil, root())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("accepts genesis block that is its own parent", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := newChecker(configuration.New(flow.Testnet))
+		require.NoError(t, err)
+
+		err = check.Response("/network/status", http.StatusOK, nil, status(identifier.Block{Index: &index, Hash: "root"}))
+		require.NoError(t, err)
+
+		err = check.Response("/block", http.StatusOK, nil, root())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects other block that is its own parent", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := newChecker(configuration.New(flow.Testnet))
+		require.NoError(t, err)
+
+		err = check.Response("/network/status", http.StatusOK, nil, status(identifier.Block{Index: &other, Hash: "genesis"}))
+		require.NoError(t, err)
+
+		err = check.Response("/block", http.StatusOK, nil, root())
+
+		assert.Error(t, err)
+	})
+}
+
+func TestChecker_Balance(t *testing.T) {
+	index := uint64(42)
+	res, err := json.Marshal(response.Balance{
+		BlockID:  identifier.Block{Index: &index, Hash: "block"},
+		Balances: []object.Amount{{Value: "100", Currency: identifier.Currency{Symbol: "FLOW", Decimals: 8}}},
+	})
+	require.NoError(t, err)
+
+	check, err := newChecker(configuration.New(flow.Testnet))
+	require.NoError(t, err)
+
+	t.Run("accepts balance at requested block", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := json.Marshal(request.Balance{BlockID: identifier.Block{Index: &index}})
+		require.NoError(t, err)
+
+		err = check.Response("/account/balance", http.StatusOK, req, res)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects balance at other block", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := json.Marshal(request.Balance{BlockID: identifier.Block{Hash: "other"}})
+		require.NoError(t, err)
+
+		err = check.Response("/account/balance", http.StatusOK, req, res)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestSelfCheck(t *testing.T) {
+	current := configuration.New(flow.Testnet,
+		configuration.WithOperationTypes(configuration.OperationTransfer),
+	)
+	next := configuration.New(flow.Testnet,
+		configuration.WithSubNetwork("next"),
+	)
+
+	index := uint64(42)
+	res := response.Transaction{
+		Transaction: &object.Transaction{
+			ID: identifier.Transaction{Hash: "tx"},
+			Operations: []*object.Operation{
+				{
+					ID:        identifier.Operation{Index: 0},
+					Type:      configuration.OperationMint,
+					Status:    configuration.StatusCompleted.Status,
+					AccountID: identifier.Account{Address: "0x1"},
+					Amount:    object.Amount{Value: "100", Currency: identifier.Currency{Symbol: "FLOW", Decimals: 8}},
+				},
+			},
+		},
+	}
+
+	serve := func(t *testing.T, network identifier.Network) string {
+		t.Helper()
+
+		var buf bytes.Buffer
+		server := echo.New()
+		check, err := SelfCheck(zerolog.New(&buf), current, next)
+		require.NoError(t, err)
+		server.Use(check)
+		server.POST("/block/transaction", func(ctx echo.Context) error {
+			return ctx.JSON(http.StatusOK, res)
+		})
+
+		body, err := json.Marshal(request.Transaction{
+			NetworkID:     network,
+			BlockID:       identifier.Block{Index: &index, Hash: "block"},
+			TransactionID: identifier.Transaction{Hash: "tx"},
+		})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/block/transaction", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		return buf.String()
+	}
+
+	t.Run("checks response against configuration of requested network", func(t *testing.T) {
+		t.Parallel()
+
+		got := serve(t, next.Network())
+
+		assert.Empty(t, got)
+	})
+
+	t.Run("checks response against first configuration for other networks", func(t *testing.T) {
+		t.Parallel()
+
+		got := serve(t, current.Network())
+
+		assert.Contains(t, got, "response violates Rosetta specification")
+	})
+}
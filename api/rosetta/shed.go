@@ -0,0 +1,50 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Shed returns a middleware that rejects requests with a retriable Rosetta
+// error while the service is overloaded, so that excess load is shed early
+// instead of every request timing out.
+func Shed(shed Shedder) echo.MiddlewareFunc {
+
+	// The stream endpoint is long-lived, and its duration says nothing about
+	// the health of the service; other methods are only used for metrics.
+	skip := func(ctx echo.Context) bool {
+		return ctx.Path() == "/flow/stream" || ctx.Request().Method != http.MethodPost
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+
+			if skip(ctx) {
+				return next(ctx)
+			}
+
+			done, err := shed.Admit()
+			if err != nil {
+				return apiError(requestAdmission, err)
+			}
+			defer done()
+
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestShed(t *testing.T) {
+
+	serve := func(shed Shedder, method string, path string) (*httptest.ResponseRecorder, bool) {
+		called := false
+		handler := func(ctx echo.Context) error {
+			called = true
+			return ctx.NoContent(http.StatusOK)
+		}
+
+		server := echo.New()
+		server.Use(Shed(shed))
+		server.Add(method, path, handler)
+
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+
+		return rec, called
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		released := false
+		shed := mocks.BaselineShedder(t)
+		shed.AdmitFunc = func() (func(), error) {
+			return func() { released = true }, nil
+		}
+
+		rec, called := serve(shed, http.MethodPost, "/block")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, called)
+		assert.True(t, released)
+	})
+
+	t.Run("sheds request when overloaded", func(t *testing.T) {
+		t.Parallel()
+
+		shed := mocks.BaselineShedder(t)
+		shed.AdmitFunc = func() (func(), error) {
+			return nil, failure.Overloaded{Capacity: 1}
+		}
+
+		rec, called := serve(shed, http.MethodPost, "/block")
+
+		assert.False(t, called)
+		var rosErr Error
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rosErr))
+		assert.Equal(t, configuration.ErrorOverloaded.Code, rosErr.Code)
+		assert.True(t, rosErr.Retriable)
+	})
+
+	t.Run("skips metrics and stream endpoints", func(t *testing.T) {
+		t.Parallel()
+
+		shed := mocks.BaselineShedder(t)
+		shed.AdmitFunc = func() (func(), error) {
+			return nil, failure.Overloaded{Capacity: 1}
+		}
+
+		_, called := serve(shed, http.MethodGet, "/metrics")
+		assert.True(t, called)
+		_, called = serve(shed, http.MethodPost, "/flow/stream")
+		assert.True(t, called)
+	})
+}
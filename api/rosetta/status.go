@@ -31,6 +31,7 @@ func (d *Data) Status(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	d = d.route(req.NetworkID)
 	err = d.validate.Request(req)
 	if err != nil {
 		return formatError(err)
@@ -16,26 +16,39 @@ package rosetta
 
 import (
 	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/optakt/flow-rosetta/rosetta/meta"
 )
 
 // The Rosetta API specification expects every error returned from the Rosetta
 // API to be a HTTP status code 500 (internal server error). We optionally make
-// it possible to have a more expressive API by returning meaningful HTTP status
-// codes where appropriate.
+// it possible to have a more expressive API by returning the HTTP status code
+// of the failure category of each error definition instead.
 var (
-	statusOK                  = http.StatusOK
-	statusBadRequest          = http.StatusInternalServerError
-	statusUnprocessableEntity = http.StatusInternalServerError
-	statusInternalServerError = http.StatusInternalServerError
+	statusOK   = http.StatusOK
+	smartCodes = false
 )
 
-// EnableSmartCodes overwrites the global variables that determine which error
-// codes the Rosetta API returns. While we avoid global variables in general,
-// these function more as a proxy to the constants of the HTTP package, with the
-// ability to change their value.
+// EnableSmartCodes switches the Rosetta API to return the HTTP status code of
+// the failure category of each error definition. While we avoid global
+// variables in general, this functions more as a proxy to the constants of the
+// HTTP package, with the ability to change which ones are used.
 func EnableSmartCodes() {
-	statusOK = http.StatusOK
-	statusBadRequest = http.StatusBadRequest
-	statusUnprocessableEntity = http.StatusUnprocessableEntity
-	statusInternalServerError = http.StatusInternalServerError
+	smartCodes = true
+}
+
+// statusCode returns the HTTP status code to use for the given error definition.
+func statusCode(definition meta.ErrorDefinition) int {
+	if !smartCodes {
+		return http.StatusInternalServerError
+	}
+	return definition.Category.HTTP()
+}
+
+// httpError wraps the given Rosetta error into an HTTP error, with the status
+// code derived from its error definition.
+func httpError(rosErr Error) *echo.HTTPError {
+	return echo.NewHTTPError(statusCode(rosErr.ErrorDefinition), rosErr)
 }
@@ -110,7 +110,7 @@ func TestAPI_StatusHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidNetwork),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidNetwork),
 		},
 		{
 			name: "missing network",
@@ -132,7 +132,7 @@ func TestAPI_StatusHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkError: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidNetwork),
+			checkError: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidNetwork),
 		},
 	}
 
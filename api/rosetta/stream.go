@@ -0,0 +1,183 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+)
+
+// streamInterval is the interval at which the stream checks for newly sealed
+// blocks. It is in the order of the block time of the Flow network, so that
+// blocks are pushed shortly after they become available in the index.
+const streamInterval = time.Second
+
+// streamBacklog is the maximum number of blocks that a stream catches up on
+// before it follows the chain. The stream is not subject to load shedding, so
+// larger backfills have to go through the /flow/blocks endpoint.
+const streamBacklog = 1000
+
+// Server-sent event names used on the stream.
+const (
+	eventBlock = "block"
+	eventError = "error"
+)
+
+// Stream implements the /flow/stream endpoint, which is an extension to the
+// Rosetta Data API. It keeps the connection open and pushes a server-sent
+// event for every block as it becomes available, in order of height. By
+// default, only the block identifier is pushed; full blocks can be requested
+// instead. This allows indexers to follow the chain without polling the
+// /network/status endpoint.
+//
+// The start index has to be indexed, or be the one after the current block,
+// and at most the stream backlog behind it. Errors that happen before the
+// stream is established are returned like on any other endpoint. Afterwards, they are pushed as an error event containing
+// the Rosetta error, and the stream is closed.
+func (d *Data) Stream(ctx echo.Context) error {
+
+	var req request.Stream
+	err := ctx.Bind(&req)
+	if err != nil {
+		return unpackError(err)
+	}
+
+	d = d.route(req.NetworkID)
+	err = d.validate.Request(req)
+	if err != nil {
+		return formatError(err)
+	}
+
+	current, _, err := d.retrieve.Current()
+	if err != nil {
+		return apiError(currentRetrieval, err)
+	}
+
+	// Without a start index, we only push blocks that are sealed after the
+	// stream was opened. A client that is caught up resumes from the block
+	// after the current one, which does not exist yet.
+	next := *current.Index + 1
+	if req.StartIndex != nil && *req.StartIndex != next {
+		_, _, err = d.validate.Block(identifier.Block{Index: req.StartIndex})
+		if err != nil {
+			return apiError(blockRetrieval, err)
+		}
+		if *req.StartIndex <= *current.Index && *current.Index-*req.StartIndex >= streamBacklog {
+			return httpError(invalidFormat(backlogExceeded,
+				withDetail("start_index", *req.StartIndex),
+				withDetail("current_index", *current.Index),
+				withDetail("backlog_limit", streamBacklog),
+			))
+		}
+		next = *req.StartIndex
+	}
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ticker := time.NewTicker(streamInterval)
+	defer ticker.Stop()
+
+	done := ctx.Request().Context().Done()
+	for {
+
+		// Push all blocks up to the last indexed one before waiting for the
+		// next tick, so that clients catch up as fast as possible.
+		for ; next <= *current.Index; next++ {
+			select {
+			case <-done:
+				return nil
+			default:
+			}
+			height := next
+			event, herr := d.event(height, req.Full)
+			if herr != nil {
+				return writeEvent(res, eventError, height, herr.Message)
+			}
+			err = writeEvent(res, eventBlock, height, event)
+			if err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-done:
+			return nil
+		case <-ticker.C:
+		}
+
+		current, _, err = d.retrieve.Current()
+		if err != nil {
+			return writeEvent(res, eventError, next, apiError(currentRetrieval, err).Message)
+		}
+	}
+}
+
+// event retrieves the stream event for the block at the given height.
+func (d *Data) event(height uint64, full bool) (response.Stream, *echo.HTTPError) {
+
+	rosBlockID := identifier.Block{Index: &height}
+
+	if !full {
+		blockID, err := d.retrieve.BlockID(rosBlockID)
+		if err != nil {
+			return response.Stream{}, apiError(blockRetrieval, err)
+		}
+		return response.Stream{BlockID: blockID}, nil
+	}
+
+	block, extraTxIDs, err := d.retrieve.Block(rosBlockID)
+	if err != nil {
+		return response.Stream{}, apiError(blockRetrieval, err)
+	}
+
+	event := response.Stream{
+		BlockID:           block.ID,
+		Block:             block,
+		OtherTransactions: extraTxIDs,
+	}
+
+	return event, nil
+}
+
+// writeEvent writes the given payload as a server-sent event and flushes it
+// to the client right away. The block height is used as the event ID, so that
+// clients can resume the stream from the last block they received.
+func writeEvent(res *echo.Response, name string, height uint64, payload interface{}) error {
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not encode event: %w", err)
+	}
+
+	_, err = fmt.Fprintf(res, "event: %s\nid: %d\ndata: %s\n\n", name, height, data)
+	if err != nil {
+		return fmt.Errorf("could not write event: %w", err)
+	}
+	res.Flush()
+
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestData_Stream(t *testing.T) {
+	current := uint64(5000)
+
+	retrieve := func(t *testing.T) *mocks.Retriever {
+		t.Helper()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{Index: &current}, time.Time{}, nil
+		}
+		return retrieve
+	}
+
+	stream := func(t *testing.T, ctx context.Context, d *Data, req request.Stream) (*httptest.ResponseRecorder, error) {
+		t.Helper()
+
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		httpReq := httptest.NewRequest(http.MethodPost, "/flow/stream", strings.NewReader(string(body))).WithContext(ctx)
+		httpReq.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		err = d.Stream(echo.New().NewContext(httpReq, rec))
+		return rec, err
+	}
+
+	rosettaError := func(t *testing.T, err error) Error {
+		t.Helper()
+
+		require.Error(t, err)
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		rosErr, ok := httpErr.Message.(Error)
+		require.True(t, ok)
+		return rosErr
+	}
+
+	t.Run("catches up from start index until closed", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var heights []uint64
+		retrieve := retrieve(t)
+		retrieve.BlockIDFunc = func(rosBlockID identifier.Block) (identifier.Block, error) {
+			heights = append(heights, *rosBlockID.Index)
+			if len(heights) == 2 {
+				cancel()
+			}
+			return rosBlockID, nil
+		}
+
+		start := current - 10
+		d := NewData(configuration.New(flow.Testnet), retrieve, mocks.BaselineValidator(t))
+
+		rec, err := stream(t, ctx, d, request.Stream{StartIndex: &start})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []uint64{start, start + 1}, heights)
+		assert.Equal(t, 2, strings.Count(rec.Body.String(), "event: block\n"))
+	})
+
+	t.Run("resumes after current block without validation", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		validate := mocks.BaselineValidator(t)
+		validate.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		start := current + 1
+		d := NewData(configuration.New(flow.Testnet), retrieve(t), validate)
+
+		rec, err := stream(t, ctx, d, request.Stream{StartIndex: &start})
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("handles start index that is not indexed", func(t *testing.T) {
+		t.Parallel()
+
+		validate := mocks.BaselineValidator(t)
+		validate.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, failure.InvalidBlock{Description: failure.NewDescription("block too low")}
+		}
+
+		start := uint64(1)
+		d := NewData(configuration.New(flow.Testnet), retrieve(t), validate)
+
+		rec, err := stream(t, context.Background(), d, request.Stream{StartIndex: &start})
+
+		rosErr := rosettaError(t, err)
+		assert.Equal(t, configuration.ErrorInvalidBlock.Code, rosErr.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("handles start index too far behind", func(t *testing.T) {
+		t.Parallel()
+
+		start := current - streamBacklog
+		d := NewData(configuration.New(flow.Testnet), retrieve(t), mocks.BaselineValidator(t))
+
+		rec, err := stream(t, context.Background(), d, request.Stream{StartIndex: &start})
+
+		rosErr := rosettaError(t, err)
+		assert.Equal(t, configuration.ErrorInvalidFormat.Code, rosErr.Code)
+		assert.Equal(t, backlogExceeded, rosErr.Description)
+		assert.Empty(t, rec.Body.String())
+	})
+}
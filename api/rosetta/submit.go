@@ -15,8 +15,15 @@
 package rosetta
 
 import (
+	"errors"
+
 	"github.com/labstack/echo/v4"
 
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
 	"github.com/optakt/flow-rosetta/rosetta/request"
 	"github.com/optakt/flow-rosetta/rosetta/response"
 )
@@ -24,6 +31,9 @@ import (
 // Submit implements the /construction/submit endpoint of the Rosetta Construction API.
 // Submit endpoint receives the fully constructed, signed transaction and submits it
 // for execution to the Flow network using the SendTransaction API call of the Flow Access API.
+// Transactions whose reference block has expired compared to the last indexed block are
+// rejected before being submitted, along with fresh construction metadata for the
+// proposal key, so that clients can rebuild the payloads in a single round trip.
 // See https://www.rosetta-api.org/docs/ConstructionApi.html#constructionsubmit
 func (c *Construction) Submit(ctx echo.Context) error {
 
@@ -33,12 +43,22 @@ func (c *Construction) Submit(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	c = c.route(req.NetworkID)
 	err = c.validate.Request(req)
 	if err != nil {
 		return formatError(err)
 	}
 
-	rosTxID, err := c.transact.SubmitTransaction(req.SignedTransaction)
+	current, _, err := c.retrieve.Current()
+	if err != nil {
+		return apiError(currentRetrieval, err)
+	}
+
+	rosTxID, err := c.transact.SubmitTransaction(current, req.SignedTransaction)
+	var expired failure.ExpiredTransaction
+	if errors.As(err, &expired) {
+		return c.rebuild(current, expired)
+	}
 	if err != nil {
 		return apiError(txSubmission, err)
 	}
@@ -49,3 +69,39 @@ func (c *Construction) Submit(ctx echo.Context) error {
 
 	return ctx.JSON(statusOK, res)
 }
+
+// rebuild returns the error for an expired transaction, with the metadata that
+// the /construction/metadata endpoint would return for its roles and gas limit
+// at the current block attached in the `rebuild` detail, so that it can be given
+// to the /construction/payloads endpoint as it is.
+func (c *Construction) rebuild(current identifier.Block, expired failure.ExpiredTransaction) error {
+
+	rosErr := expiredTransaction(expired)
+
+	// The metadata is only guidance; if it can not be retrieved, the client
+	// still gets the expiry error and can go through the usual flow.
+	proposer := identifier.Account{Address: expired.Proposer.Hex()}
+	sequence, err := c.retrieve.Sequence(current, proposer, expired.KeyIndex)
+	if err != nil {
+		return httpError(rosErr)
+	}
+
+	// The roles are always given explicitly, since the expired transaction does
+	// not tell whether they were given to the metadata endpoint or defaulted to
+	// the sender. The gas limit is kept as it is, as it already includes any fee
+	// multiplier.
+	payer := identifier.Account{Address: expired.Payer.Hex()}
+	rosErr.Details["rebuild"] = object.Metadata{
+		CurrentBlockID: current,
+		SequenceNumber: sequence,
+		ExpiryHeight:   *current.Index + flow.DefaultTransactionExpiry,
+		Roles: &object.Roles{
+			PayerID:          &payer,
+			ProposerID:       &proposer,
+			ProposerKeyIndex: expired.KeyIndex,
+		},
+		GasLimit: expired.GasLimit,
+	}
+
+	return httpError(rosErr)
+}
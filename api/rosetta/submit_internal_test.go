@@ -0,0 +1,153 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rosetta
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/rosetta/response"
+	"github.com/optakt/flow-rosetta/rosetta/transactor"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestConstruction_Rebuild(t *testing.T) {
+	config := configuration.New(flow.Mainnet)
+
+	serve := func(t *testing.T, handler echo.HandlerFunc, req interface{}, res interface{}) error {
+		t.Helper()
+
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(body))), rec)
+		ctx.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		err = handler(ctx)
+		if err != nil {
+			return err
+		}
+		require.Equal(t, statusOK, rec.Code)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+		return nil
+	}
+
+	decode := func(t *testing.T, payload string) *sdk.Transaction {
+		t.Helper()
+
+		data, err := base64.StdEncoding.DecodeString(payload)
+		require.NoError(t, err)
+		var tx sdk.Transaction
+		require.NoError(t, json.Unmarshal(data, &tx))
+		return &tx
+	}
+
+	// The expired transaction references a block that is more than the expiry
+	// window behind the current block.
+	expired := identifier.Block{Index: &mocks.GenericHeight, Hash: mocks.GenericHeader.ParentID.String()}
+	currentHeight := mocks.GenericHeight + flow.DefaultTransactionExpiry + 1
+	current := identifier.Block{Index: &currentHeight, Hash: mocks.GenericHeader.ID().String()}
+
+	validate := mocks.BaselineValidator(t)
+	validate.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+		return flow.HexToAddress(rosAccountID.Address), nil
+	}
+	validate.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+		if rosBlockID.Hash == expired.Hash {
+			return *expired.Index, mocks.GenericHeader.ParentID, nil
+		}
+		return *current.Index, mocks.GenericHeader.ID(), nil
+	}
+
+	payer := mocks.GenericAccountID(2)
+	proposer := mocks.GenericAccountID(3)
+	retrieve := mocks.BaselineRetriever(t)
+	retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+		return current, time.Now(), nil
+	}
+	retrieve.SequenceFunc = func(_ identifier.Block, rosAccountID identifier.Account, index int) (uint64, error) {
+		assert.Equal(t, proposer, rosAccountID)
+		assert.Equal(t, 1, index)
+		return 7, nil
+	}
+
+	transact := transactor.New(validate, mocks.BaselineGenerator(t), mocks.BaselineInvoker(t), mocks.BaselineSubmitter(t))
+	c := NewConstruction(config, transact, retrieve, validate)
+
+	operations := mocks.GenericOperations(2)
+	metadata := object.Metadata{
+		CurrentBlockID: expired,
+		SequenceNumber: 3,
+		Roles: &object.Roles{
+			PayerID:          &payer,
+			ProposerID:       &proposer,
+			ProposerKeyIndex: 1,
+		},
+		GasLimit: 2500,
+	}
+
+	var payloads response.Payloads
+	err := serve(t, c.Payloads, request.Payloads{Operations: operations, Metadata: metadata}, &payloads)
+	require.NoError(t, err)
+	original := decode(t, payloads.Transaction)
+	require.Equal(t, sdk.HexToAddress(payer.Address), original.Payer)
+	require.Equal(t, uint64(2500), original.GasLimit)
+
+	err = serve(t, c.Submit, request.Submit{SignedTransaction: payloads.Transaction}, &response.Submit{})
+
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	rosErr, ok := httpErr.Message.(Error)
+	require.True(t, ok)
+	assert.Equal(t, configuration.ErrorExpiredTransaction.Code, rosErr.Code)
+
+	// The rebuild detail is given as it is to the payloads endpoint, just like
+	// the metadata returned by the metadata endpoint.
+	data, err := json.Marshal(rosErr.Details["rebuild"])
+	require.NoError(t, err)
+	var rebuild object.Metadata
+	require.NoError(t, json.Unmarshal(data, &rebuild))
+
+	payloads = response.Payloads{}
+	err = serve(t, c.Payloads, request.Payloads{Operations: operations, Metadata: rebuild}, &payloads)
+	require.NoError(t, err)
+	tx := decode(t, payloads.Transaction)
+
+	assert.Equal(t, sdk.HexToID(current.Hash), tx.ReferenceBlockID)
+	assert.Equal(t, uint64(7), tx.ProposalKey.SequenceNumber)
+	assert.Equal(t, original.ProposalKey.Address, tx.ProposalKey.Address)
+	assert.Equal(t, original.ProposalKey.KeyIndex, tx.ProposalKey.KeyIndex)
+	assert.Equal(t, original.Payer, tx.Payer)
+	assert.Equal(t, original.Authorizers, tx.Authorizers)
+	assert.Equal(t, original.GasLimit, tx.GasLimit)
+	assert.Equal(t, original.Script, tx.Script)
+	assert.Equal(t, original.Arguments, tx.Arguments)
+}
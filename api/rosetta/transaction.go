@@ -31,6 +31,7 @@ func (d *Data) Transaction(ctx echo.Context) error {
 		return unpackError(err)
 	}
 
+	d = d.route(req.NetworkID)
 	err = d.validate.Request(req)
 	if err != nil {
 		return formatError(err)
@@ -47,7 +48,7 @@ func (d *Data) Transaction(ctx echo.Context) error {
 	}
 
 	res := response.Transaction{
-		Transaction: transaction,
+		Transaction: filterTransaction(transaction, accountFilter(req.Metadata)),
 	}
 
 	return ctx.JSON(statusOK, res)
@@ -173,7 +173,7 @@ func TestAPI_TransactionHandlesErrors(t *testing.T) {
 				TransactionID: testTx,
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidNetwork),
+			checkErr: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidNetwork),
 		},
 		{
 			name: "missing network name",
@@ -199,7 +199,7 @@ func TestAPI_TransactionHandlesErrors(t *testing.T) {
 				TransactionID: testTx,
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidNetwork),
+			checkErr: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidNetwork),
 		},
 		{
 			name: "missing block height and hash",
@@ -249,7 +249,7 @@ func TestAPI_TransactionHandlesErrors(t *testing.T) {
 				TransactionID: testTx,
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidBlock),
+			checkErr: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidBlock),
 		},
 		{
 			name: "unknown block",
@@ -262,7 +262,7 @@ func TestAPI_TransactionHandlesErrors(t *testing.T) {
 				TransactionID: testTx,
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorUnknownBlock),
+			checkErr: checkRosettaError(http.StatusNotFound, configuration.ErrorUnknownBlock),
 		},
 		{
 			name: "mismatched block height and hash",
@@ -275,7 +275,7 @@ func TestAPI_TransactionHandlesErrors(t *testing.T) {
 				TransactionID: testTx,
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidBlock),
+			checkErr: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidBlock),
 		},
 		{
 			name: "missing transaction id",
@@ -311,7 +311,7 @@ func TestAPI_TransactionHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorInvalidTransaction),
+			checkErr: checkRosettaError(http.StatusBadRequest, configuration.ErrorInvalidTransaction),
 		},
 		// TODO: Add test case for transaction with no events/transfers.
 		//       See https://github.com/optakt/flow-dps/issues/452
@@ -325,7 +325,7 @@ func TestAPI_TransactionHandlesErrors(t *testing.T) {
 				},
 			},
 
-			checkErr: checkRosettaError(http.StatusUnprocessableEntity, configuration.ErrorUnknownTransaction),
+			checkErr: checkRosettaError(http.StatusNotFound, configuration.ErrorUnknownTransaction),
 		},
 	}
 
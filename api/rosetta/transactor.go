@@ -22,11 +22,15 @@ import (
 
 // Transactor is used by the Rosetta Construction API to handle transaction related operations.
 type Transactor interface {
-	DeriveIntent(operations []object.Operation) (intent *transactor.Intent, err error)
+	DeriveIntent(operations []object.Operation, roles *object.Roles) (intent *transactor.Intent, err error)
 	CompileTransaction(refBlockID identifier.Block, intent *transactor.Intent, sequence uint64) (unsigned string, err error)
 	HashPayload(rosBlockID identifier.Block, unsigned string, signer identifier.Account) (algo string, hash string, err error)
+	SigningPayloads(unsigned string) (payloads []object.SigningPayload, err error)
+	DetachedPayloads(unsigned string, format string) (payloads []object.SigningPayload, summary *object.Summary, err error)
+	SuggestFee(rosBlockID identifier.Block, multiplier float64, maxFee uint64) (gasLimit uint64, fee uint64, err error)
 	Parse(payload string) (transactor.Parser, error)
 	AttachSignatures(unsigned string, signatures []object.Signature) (signed string, err error)
 	TransactionIdentifier(signed string) (rosTxID identifier.Transaction, err error)
-	SubmitTransaction(signed string) (rosTxID identifier.Transaction, err error)
+	SubmitTransaction(current identifier.Block, signed string) (rosTxID identifier.Transaction, err error)
+	Simulate(payload string) (rosBlockID identifier.Block, operations []object.Operation, fee uint64, err error)
 }
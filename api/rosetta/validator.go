@@ -15,10 +15,13 @@
 package rosetta
 
 import (
+	"github.com/onflow/flow-go/model/flow"
+
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
 )
 
 type Validator interface {
 	Request(interface{}) error
+	Block(identifier.Block) (uint64, flow.Identifier, error)
 	CompleteBlockID(identifier.Block) error
 }
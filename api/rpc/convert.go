@@ -0,0 +1,315 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rpc
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+func rosettaNetworkID(networkID *NetworkIdentifier) identifier.Network {
+	return identifier.Network{
+		Blockchain: networkID.GetBlockchain(),
+		Network:    networkID.GetNetwork(),
+	}
+}
+
+func rosettaBlockID(blockID *BlockIdentifier) identifier.Block {
+	if blockID == nil {
+		return identifier.Block{}
+	}
+	return identifier.Block{
+		Index: blockID.Index,
+		Hash:  blockID.Hash,
+	}
+}
+
+func rosettaTxID(txID *TransactionIdentifier) identifier.Transaction {
+	return identifier.Transaction{
+		Hash: txID.GetHash(),
+	}
+}
+
+func rosettaAccountID(accountID *AccountIdentifier) identifier.Account {
+	return identifier.Account{
+		Address: accountID.GetAddress(),
+	}
+}
+
+func rosettaCurrencies(currencies []*Currency) []identifier.Currency {
+	rosCurrencies := make([]identifier.Currency, 0, len(currencies))
+	for _, currency := range currencies {
+		rosCurrency := identifier.Currency{
+			Symbol:   currency.GetSymbol(),
+			Decimals: uint(currency.GetDecimals()),
+		}
+		rosCurrencies = append(rosCurrencies, rosCurrency)
+	}
+	return rosCurrencies
+}
+
+func rpcBlockID(rosBlockID identifier.Block) *BlockIdentifier {
+	return &BlockIdentifier{
+		Index: rosBlockID.Index,
+		Hash:  rosBlockID.Hash,
+	}
+}
+
+func rpcTxIDs(rosTxIDs []identifier.Transaction) []*TransactionIdentifier {
+	txIDs := make([]*TransactionIdentifier, 0, len(rosTxIDs))
+	for _, rosTxID := range rosTxIDs {
+		txIDs = append(txIDs, &TransactionIdentifier{Hash: rosTxID.Hash})
+	}
+	return txIDs
+}
+
+func rpcBlock(block *object.Block) *Block {
+	transactions := make([]*Transaction, 0, len(block.Transactions))
+	for _, transaction := range block.Transactions {
+		transactions = append(transactions, rpcTransaction(transaction))
+	}
+	rpcBlock := Block{
+		BlockIdentifier:       rpcBlockID(block.ID),
+		ParentBlockIdentifier: rpcBlockID(block.ParentID),
+		Timestamp:             block.Timestamp,
+		Transactions:          transactions,
+	}
+	if block.Metadata != nil {
+		rpcBlock.Metadata = rpcBlockMetadata(*block.Metadata)
+	}
+	return &rpcBlock
+}
+
+func rpcBlockMetadata(metadata object.BlockMetadata) *BlockMetadata {
+	collections := make([]*CollectionMetadata, 0, len(metadata.Collections))
+	for _, collection := range metadata.Collections {
+		collections = append(collections, &CollectionMetadata{
+			CollectionId:     collection.ID,
+			ReferenceBlockId: collection.ReferenceBlock,
+			Guarantors:       collection.Guarantors,
+		})
+	}
+	seals := make([]*SealMetadata, 0, len(metadata.Seals))
+	for _, seal := range metadata.Seals {
+		seals = append(seals, &SealMetadata{
+			SealId:     seal.ID,
+			BlockId:    seal.BlockID,
+			ResultId:   seal.ResultID,
+			FinalState: seal.FinalState,
+		})
+	}
+	events := make([]*ServiceEvent, 0, len(metadata.ServiceEvents))
+	for _, event := range metadata.ServiceEvents {
+		events = append(events, rpcServiceEvent(event))
+	}
+	return &BlockMetadata{
+		Sealed:        metadata.Sealed,
+		Collections:   collections,
+		Chunks:        uint64(metadata.Chunks),
+		Seals:         seals,
+		ServiceEvents: events,
+	}
+}
+
+func rpcServiceEvent(event object.ServiceEvent) *ServiceEvent {
+	rpcEvent := ServiceEvent{
+		Type:    event.Type,
+		Counter: event.Counter,
+	}
+	if event.Setup != nil {
+		rpcEvent.EpochSetup = &EpochSetup{
+			FirstView:          event.Setup.FirstView,
+			FinalView:          event.Setup.FinalView,
+			DkgPhaseFinalViews: event.Setup.DKGPhaseFinalViews,
+			RandomSource:       event.Setup.RandomSource,
+			Participants:       uint64(event.Setup.Participants),
+			Clusters:           uint64(event.Setup.Clusters),
+		}
+	}
+	if event.Commit != nil {
+		rpcEvent.EpochCommit = &EpochCommit{
+			ClusterQcs:      uint64(event.Commit.ClusterQCs),
+			DkgGroupKey:     event.Commit.DKGGroupKey,
+			DkgParticipants: uint64(event.Commit.DKGParticipants),
+		}
+	}
+	return &rpcEvent
+}
+
+func rpcTransaction(transaction *object.Transaction) *Transaction {
+	operations := make([]*Operation, 0, len(transaction.Operations))
+	for _, operation := range transaction.Operations {
+		operations = append(operations, rpcOperation(operation))
+	}
+	rpcTransaction := Transaction{
+		TransactionIdentifier: &TransactionIdentifier{Hash: transaction.ID.Hash},
+		Operations:            operations,
+	}
+	if transaction.Metadata != nil {
+		rpcTransaction.Metadata = rpcTransactionMetadata(*transaction.Metadata)
+	}
+	return &rpcTransaction
+}
+
+func rpcTransactionMetadata(metadata object.TransactionMetadata) *TransactionMetadata {
+	accounts := make([]*CreatedAccount, 0, len(metadata.Accounts))
+	for _, account := range metadata.Accounts {
+		accounts = append(accounts, &CreatedAccount{
+			Address: account.Address,
+			Creator: account.Creator,
+			Keys:    uint64(account.Keys),
+		})
+	}
+	contracts := make([]*ContractChange, 0, len(metadata.Contracts))
+	for _, contract := range metadata.Contracts {
+		contracts = append(contracts, &ContractChange{
+			Address: contract.Address,
+			Name:    contract.Name,
+			Action:  contract.Action,
+		})
+	}
+	omitted := make([]*Operation, 0, len(metadata.Omitted))
+	for _, operation := range metadata.Omitted {
+		omitted = append(omitted, rpcOperation(operation))
+	}
+	rpcMetadata := TransactionMetadata{
+		ComputationUsed:   metadata.ComputationUsed,
+		GasLimit:          metadata.GasLimit,
+		CreatedAccounts:   accounts,
+		Contracts:         contracts,
+		OmittedOperations: omitted,
+	}
+	if metadata.Fees != nil {
+		rpcMetadata.Fees = &TransactionFees{
+			Amount:          rpcAmount(metadata.Fees.Amount),
+			InclusionEffort: metadata.Fees.InclusionEffort,
+			ExecutionEffort: metadata.Fees.ExecutionEffort,
+		}
+	}
+	if metadata.Error != nil {
+		rpcMetadata.Error = &ExecutionError{
+			Code:    uint64(metadata.Error.Code),
+			Message: metadata.Error.Message,
+		}
+	}
+	return &rpcMetadata
+}
+
+func rpcOperation(operation *object.Operation) *Operation {
+	relatedIDs := make([]*OperationIdentifier, 0, len(operation.RelatedIDs))
+	for _, relatedID := range operation.RelatedIDs {
+		relatedIDs = append(relatedIDs, &OperationIdentifier{Index: uint64(relatedID.Index)})
+	}
+	op := Operation{
+		OperationIdentifier: &OperationIdentifier{Index: uint64(operation.ID.Index)},
+		Type:                operation.Type,
+		Status:              operation.Status,
+		Account:             &AccountIdentifier{Address: operation.AccountID.Address},
+		Amount:              rpcAmount(operation.Amount),
+		RelatedOperations:   relatedIDs,
+	}
+	if operation.Metadata != nil {
+		op.Metadata = rpcOperationMetadata(*operation.Metadata)
+	}
+	return &op
+}
+
+func rpcOperationMetadata(metadata object.OperationMetadata) *OperationMetadata {
+	rpcMetadata := OperationMetadata{
+		Template:           metadata.Template,
+		Arguments:          metadata.Arguments,
+		PublicKey:          metadata.PublicKey,
+		SignatureAlgorithm: metadata.SignatureAlgorithm,
+		HashAlgorithm:      metadata.HashAlgorithm,
+		Weight:             int64(metadata.Weight),
+	}
+	if metadata.KeyIndex != nil {
+		index := int64(*metadata.KeyIndex)
+		rpcMetadata.KeyIndex = &index
+	}
+	if metadata.Staking != nil {
+		rpcMetadata.Staking = &StakingMetadata{
+			NodeId:      metadata.Staking.NodeID,
+			DelegatorId: metadata.Staking.DelegatorID,
+		}
+	}
+	if metadata.Fee != nil {
+		rpcMetadata.Fee = &FeeMetadata{
+			Payer: &AccountIdentifier{Address: metadata.Fee.Payer.Address},
+		}
+	}
+	if metadata.Reward != nil {
+		rpcMetadata.Reward = &RewardMetadata{
+			NodeId:      metadata.Reward.NodeID,
+			DelegatorId: metadata.Reward.DelegatorID,
+		}
+	}
+	return &rpcMetadata
+}
+
+func rpcAmounts(amounts []object.Amount) []*Amount {
+	rpcAmounts := make([]*Amount, 0, len(amounts))
+	for _, amount := range amounts {
+		rpcAmounts = append(rpcAmounts, rpcAmount(amount))
+	}
+	return rpcAmounts
+}
+
+func rpcAmount(amount object.Amount) *Amount {
+	rpcAmount := Amount{
+		Value:    amount.Value,
+		Currency: rpcCurrency(amount.Currency),
+	}
+	if amount.Metadata != nil {
+		vaults := make([]*VaultBalance, 0, len(amount.Metadata.Vaults))
+		for _, vault := range amount.Metadata.Vaults {
+			vaults = append(vaults, &VaultBalance{Path: vault.Path, Value: vault.Value})
+		}
+		rpcAmount.Metadata = &AmountMetadata{Vaults: vaults}
+	}
+	return &rpcAmount
+}
+
+func rpcCurrency(currency identifier.Currency) *Currency {
+	rpcCurrency := Currency{
+		Symbol:   currency.Symbol,
+		Decimals: uint32(currency.Decimals),
+	}
+	if currency.Metadata != nil {
+		rpcCurrency.Metadata = &CurrencyMetadata{
+			ContractAddress: currency.Metadata.ContractAddress,
+			ContractName:    currency.Metadata.ContractName,
+			VaultType:       currency.Metadata.VaultType,
+		}
+	}
+	return &rpcCurrency
+}
+
+func rpcKeys(keys []object.AccountKey) []*AccountKey {
+	rpcKeys := make([]*AccountKey, 0, len(keys))
+	for _, key := range keys {
+		rpcKey := AccountKey{
+			Index:              int64(key.Index),
+			PublicKey:          key.PublicKey,
+			SignatureAlgorithm: key.SignatureAlgorithm,
+			HashAlgorithm:      key.HashAlgorithm,
+			Weight:             int64(key.Weight),
+			Revoked:            key.Revoked,
+			SequenceNumber:     key.SequenceNumber,
+		}
+		rpcKeys = append(rpcKeys, &rpcKey)
+	}
+	return rpcKeys
+}
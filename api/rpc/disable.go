@@ -0,0 +1,51 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/optakt/flow-rosetta/api/rosetta"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+// endpoints maps the methods of the GRPC server to the paths of the Rosetta
+// Data API endpoints that they mirror.
+var endpoints = map[string]string{
+	"/API/GetStatus":      "/network/status",
+	"/API/GetBlock":       "/block",
+	"/API/GetTransaction": "/block/transaction",
+	"/API/GetBalance":     "/account/balance",
+}
+
+// Disable returns an interceptor that rejects calls to the methods that mirror
+// one of the given disabled endpoints of the Rosetta Data API, so that disabling
+// an endpoint can not be bypassed through the GRPC mirror.
+func Disable(disabled []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		path, ok := endpoints[info.FullMethod]
+		if !ok || !rosetta.Disabled(disabled, path) {
+			return handler(ctx, req)
+		}
+
+		return nil, statusError(requestAdmission, failure.DisabledEndpoint{
+			Endpoint:    path,
+			Description: failure.NewDescription(endpointDisabled),
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDisable(t *testing.T) {
+
+	call := func(disabled []string, method string) (bool, error) {
+		called := false
+		handler := func(context.Context, interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		}
+
+		info := grpc.UnaryServerInfo{FullMethod: method}
+		_, err := Disable(disabled)(context.Background(), nil, &info, handler)
+
+		return called, err
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		called, err := call([]string{"/search/transactions"}, "/API/GetBlock")
+
+		require.NoError(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("rejects method of disabled endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		called, err := call([]string{"/account/balance"}, "/API/GetBalance")
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.False(t, called)
+	})
+
+	t.Run("rejects methods below disabled group", func(t *testing.T) {
+		t.Parallel()
+
+		called, err := call([]string{"/block"}, "/API/GetTransaction")
+		require.NoError(t, err)
+		assert.True(t, called)
+
+		called, err = call([]string{"/block/"}, "/API/GetTransaction")
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.False(t, called)
+	})
+
+	t.Run("maps every method to an endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		for _, method := range API_ServiceDesc.Methods {
+			assert.Contains(t, endpoints, "/"+API_ServiceDesc.ServiceName+"/"+method.MethodName)
+		}
+	})
+}
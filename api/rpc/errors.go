@@ -0,0 +1,50 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rpc
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+const (
+	invalidRequest    = "invalid request"
+	blockRetrieval    = "unable to retrieve block"
+	balancesRetrieval = "unable to retrieve balances"
+	keysRetrieval     = "unable to retrieve account keys"
+	oldestRetrieval   = "unable to retrieve oldest block"
+	currentRetrieval  = "unable to retrieve current block"
+	txRetrieval       = "unable to retrieve transaction"
+	requestAdmission  = "unable to admit request"
+	endpointDisabled  = "endpoint is disabled on this server"
+)
+
+// requestError wraps the given validation error into a GRPC status error. The
+// validator only fails on invalid input, so the code is always the same.
+func requestError(err error) error {
+	return status.Error(codes.InvalidArgument, fmt.Sprintf("%s: %s", invalidRequest, err))
+}
+
+// statusError wraps the given error into a GRPC status error, with the status
+// code that corresponds to the failure category, so that typed clients can
+// react to e.g. unknown blocks without parsing the message.
+func statusError(description string, err error) error {
+	code := failure.Categorize(err).GRPC()
+	return status.Error(code, fmt.Sprintf("%s: %s", description, err))
+}
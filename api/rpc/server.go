@@ -0,0 +1,166 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rpc
+
+import (
+	"context"
+
+	"github.com/optakt/flow-rosetta/rosetta/request"
+)
+
+// Server implements the generated APIServer interface, which mirrors the
+// Rosetta Data API over GRPC. It uses the same retriever and validator as the
+// HTTP API, so that both always return the same data for the same queries.
+type Server struct {
+	retrieve Retriever
+	validate Validator
+}
+
+// NewServer creates a new server, using the provided retriever and validator
+// as a backend to answer and validate queries.
+func NewServer(retrieve Retriever, validate Validator) *Server {
+
+	s := Server{
+		retrieve: retrieve,
+		validate: validate,
+	}
+
+	return &s
+}
+
+// GetStatus implements the `GetStatus` method of the generated GRPC server.
+// It mirrors the /network/status endpoint of the Rosetta Data API.
+func (s *Server) GetStatus(_ context.Context, req *GetStatusRequest) (*GetStatusResponse, error) {
+
+	rosReq := request.Status{
+		NetworkID: rosettaNetworkID(req.GetNetworkIdentifier()),
+	}
+	err := s.validate.Request(rosReq)
+	if err != nil {
+		return nil, requestError(err)
+	}
+
+	oldest, _, err := s.retrieve.Oldest()
+	if err != nil {
+		return nil, statusError(oldestRetrieval, err)
+	}
+
+	current, timestamp, err := s.retrieve.Current()
+	if err != nil {
+		return nil, statusError(currentRetrieval, err)
+	}
+
+	res := GetStatusResponse{
+		CurrentBlockIdentifier: rpcBlockID(current),
+		CurrentBlockTimestamp:  timestamp.UnixNano() / 1_000_000,
+		OldestBlockIdentifier:  rpcBlockID(oldest),
+		GenesisBlockIdentifier: rpcBlockID(oldest),
+	}
+
+	return &res, nil
+}
+
+// GetBlock implements the `GetBlock` method of the generated GRPC server.
+// It mirrors the /block endpoint of the Rosetta Data API.
+func (s *Server) GetBlock(_ context.Context, req *GetBlockRequest) (*GetBlockResponse, error) {
+
+	rosReq := request.Block{
+		NetworkID: rosettaNetworkID(req.GetNetworkIdentifier()),
+		BlockID:   rosettaBlockID(req.GetBlockIdentifier()),
+	}
+	err := s.validate.Request(rosReq)
+	if err != nil {
+		return nil, requestError(err)
+	}
+
+	block, extraTxIDs, err := s.retrieve.Block(rosReq.BlockID)
+	if err != nil {
+		return nil, statusError(blockRetrieval, err)
+	}
+
+	res := GetBlockResponse{
+		Block:             rpcBlock(block),
+		OtherTransactions: rpcTxIDs(extraTxIDs),
+	}
+
+	return &res, nil
+}
+
+// GetTransaction implements the `GetTransaction` method of the generated GRPC
+// server. It mirrors the /block/transaction endpoint of the Rosetta Data API.
+func (s *Server) GetTransaction(_ context.Context, req *GetTransactionRequest) (*GetTransactionResponse, error) {
+
+	rosReq := request.Transaction{
+		NetworkID:     rosettaNetworkID(req.GetNetworkIdentifier()),
+		BlockID:       rosettaBlockID(req.GetBlockIdentifier()),
+		TransactionID: rosettaTxID(req.GetTransactionIdentifier()),
+	}
+	err := s.validate.Request(rosReq)
+	if err != nil {
+		return nil, requestError(err)
+	}
+	err = s.validate.CompleteBlockID(rosReq.BlockID)
+	if err != nil {
+		return nil, requestError(err)
+	}
+
+	transaction, err := s.retrieve.Transaction(rosReq.BlockID, rosReq.TransactionID)
+	if err != nil {
+		return nil, statusError(txRetrieval, err)
+	}
+
+	res := GetTransactionResponse{
+		Transaction: rpcTransaction(transaction),
+	}
+
+	return &res, nil
+}
+
+// GetBalance implements the `GetBalance` method of the generated GRPC server.
+// It mirrors the /account/balance endpoint of the Rosetta Data API, with the
+// account keys that are part of the balance metadata on the HTTP API.
+func (s *Server) GetBalance(_ context.Context, req *GetBalanceRequest) (*GetBalanceResponse, error) {
+
+	rosReq := request.Balance{
+		NetworkID:  rosettaNetworkID(req.GetNetworkIdentifier()),
+		BlockID:    rosettaBlockID(req.GetBlockIdentifier()),
+		AccountID:  rosettaAccountID(req.GetAccountIdentifier()),
+		Currencies: rosettaCurrencies(req.GetCurrencies()),
+	}
+	err := s.validate.Request(rosReq)
+	if err != nil {
+		return nil, requestError(err)
+	}
+
+	rosBlockID, balances, err := s.retrieve.Balances(rosReq.BlockID, rosReq.AccountID, rosReq.Currencies)
+	if err != nil {
+		return nil, statusError(balancesRetrieval, err)
+	}
+
+	// We use the fully specified block identifier returned with the balances,
+	// so that the keys are guaranteed to be from the same block.
+	keys, err := s.retrieve.Keys(rosBlockID, rosReq.AccountID)
+	if err != nil {
+		return nil, statusError(keysRetrieval, err)
+	}
+
+	res := GetBalanceResponse{
+		BlockIdentifier: rpcBlockID(rosBlockID),
+		Balances:        rpcAmounts(balances),
+		Keys:            rpcKeys(keys),
+	}
+
+	return &res, nil
+}
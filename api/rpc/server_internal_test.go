@@ -0,0 +1,492 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestNewServer(t *testing.T) {
+	retrieve := mocks.BaselineRetriever(t)
+	validate := mocks.BaselineValidator(t)
+
+	s := NewServer(retrieve, validate)
+
+	require.NotNil(t, s)
+	assert.Equal(t, retrieve, s.retrieve)
+	assert.Equal(t, validate, s.validate)
+}
+
+func TestServer_GetStatus(t *testing.T) {
+	networkID := &NetworkIdentifier{Blockchain: "flow", Network: "testnet"}
+	rosBlockID := mocks.GenericRosBlockID
+	timestamp := mocks.GenericHeader.Timestamp
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		validate := mocks.BaselineValidator(t)
+		validate.RequestFunc = func(req interface{}) error {
+			assert.Equal(t, request.Status{NetworkID: identifier.Network{Blockchain: "flow", Network: "testnet"}}, req)
+			return nil
+		}
+
+		s := BaselineServer(t, WithValidator(validate))
+
+		res, err := s.GetStatus(context.Background(), &GetStatusRequest{NetworkIdentifier: networkID})
+
+		require.NoError(t, err)
+		assert.Equal(t, *rosBlockID.Index, res.CurrentBlockIdentifier.GetIndex())
+		assert.Equal(t, rosBlockID.Hash, res.CurrentBlockIdentifier.GetHash())
+		assert.Equal(t, timestamp.UnixNano()/1_000_000, res.CurrentBlockTimestamp)
+		assert.Equal(t, rosBlockID.Hash, res.OldestBlockIdentifier.GetHash())
+		assert.Equal(t, rosBlockID.Hash, res.GenesisBlockIdentifier.GetHash())
+	})
+
+	t.Run("handles invalid request", func(t *testing.T) {
+		t.Parallel()
+
+		validate := mocks.BaselineValidator(t)
+		validate.RequestFunc = func(interface{}) error {
+			return mocks.GenericError
+		}
+
+		s := BaselineServer(t, WithValidator(validate))
+
+		_, err := s.GetStatus(context.Background(), &GetStatusRequest{NetworkIdentifier: networkID})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("handles oldest retrieval failure", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.OldestFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{}, time.Time{}, mocks.GenericError
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		_, err := s.GetStatus(context.Background(), &GetStatusRequest{NetworkIdentifier: networkID})
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("handles current retrieval failure", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{}, time.Time{}, mocks.GenericError
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		_, err := s.GetStatus(context.Background(), &GetStatusRequest{NetworkIdentifier: networkID})
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestServer_GetBlock(t *testing.T) {
+	rosBlockID := mocks.GenericRosBlockID
+	blockID := &BlockIdentifier{Index: rosBlockID.Index}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.BlockFunc = func(got identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			assert.Equal(t, identifier.Block{Index: rosBlockID.Index}, got)
+
+			block := object.Block{
+				ID:           rosBlockID,
+				Transactions: []*object.Transaction{mocks.GenericRosTransaction(0)},
+			}
+			return &block, []identifier.Transaction{mocks.GenericTransactionQualifier(1)}, nil
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		res, err := s.GetBlock(context.Background(), &GetBlockRequest{BlockIdentifier: blockID})
+
+		require.NoError(t, err)
+		assert.Equal(t, rosBlockID.Hash, res.Block.BlockIdentifier.GetHash())
+		require.Len(t, res.Block.Transactions, 1)
+		require.Len(t, res.Block.Transactions[0].Operations, 2)
+
+		op := mocks.GenericOperation(1)
+		got := res.Block.Transactions[0].Operations[1]
+		assert.Equal(t, uint64(op.ID.Index), got.OperationIdentifier.GetIndex())
+		assert.Equal(t, op.Type, got.Type)
+		assert.Equal(t, op.AccountID.Address, got.Account.GetAddress())
+		assert.Equal(t, op.Amount.Value, got.Amount.GetValue())
+		assert.Equal(t, op.Amount.Currency.Symbol, got.Amount.Currency.GetSymbol())
+
+		require.Len(t, res.OtherTransactions, 1)
+		assert.Equal(t, mocks.GenericTransactionQualifier(1).Hash, res.OtherTransactions[0].GetHash())
+	})
+
+	t.Run("includes block metadata", func(t *testing.T) {
+		t.Parallel()
+
+		delegatorID := uint32(7)
+		metadata := object.BlockMetadata{
+			Sealed: true,
+			Collections: []object.CollectionMetadata{
+				{ID: "collection", ReferenceBlock: "reference", Guarantors: []string{"guarantor"}},
+			},
+			Chunks: 2,
+			Seals: []object.SealMetadata{
+				{ID: "seal", BlockID: "block", ResultID: "result", FinalState: "state"},
+			},
+			ServiceEvents: []object.ServiceEvent{
+				{Type: "setup", Counter: 1, Setup: &object.EpochSetup{FirstView: 10, FinalView: 20, DKGPhaseFinalViews: []uint64{11, 12, 13}, Participants: 3, Clusters: 1}},
+				{Type: "commit", Counter: 1, Commit: &object.EpochCommit{ClusterQCs: 1, DKGGroupKey: "key", DKGParticipants: 2}},
+			},
+		}
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.BlockFunc = func(identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			transaction := mocks.GenericRosTransaction(0)
+			transaction.Operations[1].Metadata = &object.OperationMetadata{
+				Reward: &object.RewardMetadata{NodeID: "node", DelegatorID: &delegatorID},
+			}
+			block := object.Block{
+				ID:           rosBlockID,
+				Transactions: []*object.Transaction{transaction},
+				Metadata:     &metadata,
+			}
+			return &block, nil, nil
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		res, err := s.GetBlock(context.Background(), &GetBlockRequest{BlockIdentifier: blockID})
+
+		require.NoError(t, err)
+		got := res.Block.GetMetadata()
+		require.NotNil(t, got)
+		assert.True(t, got.Sealed)
+		assert.Equal(t, uint64(2), got.Chunks)
+		require.Len(t, got.Collections, 1)
+		assert.Equal(t, "reference", got.Collections[0].ReferenceBlockId)
+		assert.Equal(t, []string{"guarantor"}, got.Collections[0].Guarantors)
+		require.Len(t, got.Seals, 1)
+		assert.Equal(t, "result", got.Seals[0].ResultId)
+		assert.Equal(t, "state", got.Seals[0].FinalState)
+		require.Len(t, got.ServiceEvents, 2)
+		assert.Equal(t, []uint64{11, 12, 13}, got.ServiceEvents[0].EpochSetup.GetDkgPhaseFinalViews())
+		assert.Nil(t, got.ServiceEvents[0].EpochCommit)
+		assert.Equal(t, "key", got.ServiceEvents[1].EpochCommit.GetDkgGroupKey())
+		assert.Nil(t, got.ServiceEvents[1].EpochSetup)
+
+		reward := res.Block.Transactions[0].Operations[1].Metadata.GetReward()
+		require.NotNil(t, reward)
+		assert.Equal(t, "node", reward.NodeId)
+		assert.Equal(t, delegatorID, reward.GetDelegatorId())
+	})
+
+	t.Run("handles invalid request", func(t *testing.T) {
+		t.Parallel()
+
+		validate := mocks.BaselineValidator(t)
+		validate.RequestFunc = func(interface{}) error {
+			return mocks.GenericError
+		}
+
+		s := BaselineServer(t, WithValidator(validate))
+
+		_, err := s.GetBlock(context.Background(), &GetBlockRequest{BlockIdentifier: blockID})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("handles unknown block", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.BlockFunc = func(identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			return nil, nil, failure.UnknownBlock{Index: *rosBlockID.Index}
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		_, err := s.GetBlock(context.Background(), &GetBlockRequest{BlockIdentifier: blockID})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestServer_GetTransaction(t *testing.T) {
+	rosBlockID := mocks.GenericRosBlockID
+	blockID := &BlockIdentifier{Index: rosBlockID.Index, Hash: rosBlockID.Hash}
+	rosTxID := mocks.GenericTransactionQualifier(0)
+	txID := &TransactionIdentifier{Hash: rosTxID.Hash}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.TransactionFunc = func(gotBlockID identifier.Block, gotTxID identifier.Transaction) (*object.Transaction, error) {
+			assert.Equal(t, rosBlockID, gotBlockID)
+			assert.Equal(t, rosTxID, gotTxID)
+
+			return mocks.GenericRosTransaction(0), nil
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		res, err := s.GetTransaction(context.Background(), &GetTransactionRequest{
+			BlockIdentifier:       blockID,
+			TransactionIdentifier: txID,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, rosTxID.Hash, res.Transaction.TransactionIdentifier.GetHash())
+		assert.Len(t, res.Transaction.Operations, 2)
+	})
+
+	t.Run("includes transaction metadata and related operations", func(t *testing.T) {
+		t.Parallel()
+
+		omitted := mocks.GenericOperation(2)
+		metadata := object.TransactionMetadata{
+			ComputationUsed: 42,
+			GasLimit:        9999,
+			Fees: &object.TransactionFees{
+				Amount:          mocks.GenericOperation(0).Amount,
+				InclusionEffort: "1.00000000",
+				ExecutionEffort: "0.00000042",
+			},
+			Accounts:  []object.CreatedAccount{{Address: "address", Creator: "creator", Keys: 1}},
+			Contracts: []object.ContractChange{{Address: "address", Name: "Contract", Action: "added"}},
+			Omitted:   []*object.Operation{&omitted},
+			Error:     &object.ExecutionError{Code: 1101, Message: "failed"},
+		}
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.TransactionFunc = func(identifier.Block, identifier.Transaction) (*object.Transaction, error) {
+			transaction := mocks.GenericRosTransaction(0)
+			transaction.Operations[1].RelatedIDs = []identifier.Operation{transaction.Operations[0].ID}
+			transaction.Metadata = &metadata
+			return transaction, nil
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		res, err := s.GetTransaction(context.Background(), &GetTransactionRequest{
+			BlockIdentifier:       blockID,
+			TransactionIdentifier: txID,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, res.Transaction.Operations, 2)
+		assert.Empty(t, res.Transaction.Operations[0].RelatedOperations)
+		require.Len(t, res.Transaction.Operations[1].RelatedOperations, 1)
+		assert.Equal(t, res.Transaction.Operations[0].OperationIdentifier.GetIndex(), res.Transaction.Operations[1].RelatedOperations[0].GetIndex())
+
+		got := res.Transaction.GetMetadata()
+		require.NotNil(t, got)
+		assert.Equal(t, uint64(42), got.ComputationUsed)
+		assert.Equal(t, uint64(9999), got.GasLimit)
+		assert.Equal(t, metadata.Fees.Amount.Value, got.Fees.GetAmount().GetValue())
+		assert.Equal(t, "0.00000042", got.Fees.GetExecutionEffort())
+		require.Len(t, got.CreatedAccounts, 1)
+		assert.Equal(t, "creator", got.CreatedAccounts[0].Creator)
+		require.Len(t, got.Contracts, 1)
+		assert.Equal(t, "added", got.Contracts[0].Action)
+		require.Len(t, got.OmittedOperations, 1)
+		assert.Equal(t, omitted.Type, got.OmittedOperations[0].Type)
+		assert.Equal(t, uint64(1101), got.Error.GetCode())
+		assert.Equal(t, "failed", got.Error.GetMessage())
+	})
+
+	t.Run("handles incomplete block identifier", func(t *testing.T) {
+		t.Parallel()
+
+		validate := mocks.BaselineValidator(t)
+		validate.CompleteBlockIDFunc = func(identifier.Block) error {
+			return failure.IncompleteBlock{}
+		}
+
+		s := BaselineServer(t, WithValidator(validate))
+
+		_, err := s.GetTransaction(context.Background(), &GetTransactionRequest{
+			BlockIdentifier:       &BlockIdentifier{Hash: rosBlockID.Hash},
+			TransactionIdentifier: txID,
+		})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("handles unknown transaction", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.TransactionFunc = func(identifier.Block, identifier.Transaction) (*object.Transaction, error) {
+			return nil, failure.UnknownTransaction{Hash: rosTxID.Hash}
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		_, err := s.GetTransaction(context.Background(), &GetTransactionRequest{
+			BlockIdentifier:       blockID,
+			TransactionIdentifier: txID,
+		})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestServer_GetBalance(t *testing.T) {
+	rosBlockID := mocks.GenericRosBlockID
+	accountID := mocks.GenericAccountID(0)
+	currency := mocks.GenericCurrency
+
+	req := &GetBalanceRequest{
+		BlockIdentifier:   &BlockIdentifier{Index: rosBlockID.Index},
+		AccountIdentifier: &AccountIdentifier{Address: accountID.Address},
+		Currencies:        []*Currency{{Symbol: currency.Symbol, Decimals: uint32(currency.Decimals)}},
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		keyIndex := 3
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.BalancesFunc = func(gotBlockID identifier.Block, gotAccountID identifier.Account, gotCurrencies []identifier.Currency) (identifier.Block, []object.Amount, error) {
+			assert.Equal(t, identifier.Block{Index: rosBlockID.Index}, gotBlockID)
+			assert.Equal(t, accountID, gotAccountID)
+			assert.Equal(t, []identifier.Currency{currency}, gotCurrencies)
+
+			amount := object.Amount{
+				Value:    "42",
+				Currency: currency,
+				Metadata: &object.AmountMetadata{
+					Vaults: []object.VaultBalance{{Path: "/public/flowTokenBalance", Value: "42"}},
+				},
+			}
+			return rosBlockID, []object.Amount{amount}, nil
+		}
+		retrieve.KeysFunc = func(gotBlockID identifier.Block, gotAccountID identifier.Account) ([]object.AccountKey, error) {
+			assert.Equal(t, rosBlockID, gotBlockID)
+			assert.Equal(t, accountID, gotAccountID)
+
+			return []object.AccountKey{{Index: keyIndex, Weight: 1000, Revoked: true}}, nil
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		res, err := s.GetBalance(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, rosBlockID.Hash, res.BlockIdentifier.GetHash())
+		require.Len(t, res.Balances, 1)
+		assert.Equal(t, "42", res.Balances[0].Value)
+		require.Len(t, res.Balances[0].Metadata.GetVaults(), 1)
+		assert.Equal(t, "/public/flowTokenBalance", res.Balances[0].Metadata.Vaults[0].Path)
+		require.Len(t, res.Keys, 1)
+		assert.Equal(t, int64(keyIndex), res.Keys[0].Index)
+		assert.Equal(t, int64(1000), res.Keys[0].Weight)
+		assert.True(t, res.Keys[0].Revoked)
+	})
+
+	t.Run("handles invalid request", func(t *testing.T) {
+		t.Parallel()
+
+		validate := mocks.BaselineValidator(t)
+		validate.RequestFunc = func(interface{}) error {
+			return failure.InvalidAccountAddress{}
+		}
+
+		s := BaselineServer(t, WithValidator(validate))
+
+		_, err := s.GetBalance(context.Background(), req)
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("handles balances retrieval failure", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.BalancesFunc = func(identifier.Block, identifier.Account, []identifier.Currency) (identifier.Block, []object.Amount, error) {
+			return identifier.Block{}, nil, failure.UnavailableBlock{}
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		_, err := s.GetBalance(context.Background(), req)
+
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("handles keys retrieval failure", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.KeysFunc = func(identifier.Block, identifier.Account) ([]object.AccountKey, error) {
+			return nil, mocks.GenericError
+		}
+
+		s := BaselineServer(t, WithRetriever(retrieve))
+
+		_, err := s.GetBalance(context.Background(), req)
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func BaselineServer(t *testing.T, opts ...func(*Server)) *Server {
+	t.Helper()
+
+	s := Server{
+		retrieve: mocks.BaselineRetriever(t),
+		validate: mocks.BaselineValidator(t),
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return &s
+}
+
+func WithRetriever(retrieve Retriever) func(*Server) {
+	return func(s *Server) {
+		s.retrieve = retrieve
+	}
+}
+
+func WithValidator(validate Validator) func(*Server) {
+	return func(s *Server) {
+		s.validate = validate
+	}
+}
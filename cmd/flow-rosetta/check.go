@@ -0,0 +1,457 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/onflow/cadence/runtime/parser2"
+	"github.com/rs/zerolog"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/alert"
+	"github.com/optakt/flow-rosetta/rosetta/history"
+	"github.com/optakt/flow-rosetta/rosetta/integrity"
+	"github.com/optakt/flow-rosetta/rosetta/scripts"
+)
+
+// checkTimeout is how long the configuration checks wait for each Access API.
+const checkTimeout = 10 * time.Second
+
+// errSkipped is returned by checks that depend on a previous check which failed.
+var errSkipped = errors.New("skipped")
+
+// step is a single check of the configuration. It returns a short description of
+// what was checked when it succeeds.
+type step struct {
+	name string
+	run  func() (string, error)
+}
+
+// inspection holds the state that is shared between the checks of a configuration,
+// so that later checks can build on what earlier checks found.
+type inspection struct {
+	log        zerolog.Logger
+	f          *flags
+	disconnect func()
+	index      dps.Reader
+	params     *dps.Params
+}
+
+// check validates the given configuration without serving the API, and stops at the
+// first problem. It returns the exit code of the process, which is only successful
+// if the server could start with the configuration.
+func check(f *flags) int {
+
+	log, err := logger(f.Level)
+	if err != nil {
+		log.Error().Str("level", f.Level).Err(err).Msg("could not parse log level")
+		return failure
+	}
+
+	in := &inspection{log: log, f: f}
+	defer in.close()
+	for _, step := range in.steps() {
+		detail, err := step.run()
+		if err != nil {
+			log.Error().Str("check", step.name).Err(err).Msg("configuration check failed")
+			return failure
+		}
+		log.Info().Str("check", step.name).Str("detail", detail).Msg("configuration check passed")
+	}
+
+	log.Info().Msg("configuration valid")
+
+	return success
+}
+
+// preflight runs all checks of the given configuration, even after a problem was
+// found, and prints a report of what will and won't work to standard output. It
+// returns the exit code of the process, which is only successful if all checks pass.
+func preflight(f *flags) int {
+
+	// The logger is only used for the notices of the components that are
+	// initialized; the report itself is the output of the command.
+	log, err := logger(f.Level)
+	if err != nil {
+		log = log.Level(zerolog.WarnLevel)
+	}
+
+	in := &inspection{log: log, f: f}
+	defer in.close()
+
+	code := success
+	report := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(report, "STATUS\tCHECK\tDETAIL")
+	if err != nil {
+		fmt.Fprintf(report, "FAIL\tlog level\t%s\n", err)
+		code = failure
+	}
+	for _, step := range in.steps() {
+		detail, err := step.run()
+		switch {
+		case errors.Is(err, errSkipped):
+			fmt.Fprintf(report, "SKIP\t%s\t%s\n", step.name, err)
+			code = failure
+		case err != nil:
+			fmt.Fprintf(report, "FAIL\t%s\t%s\n", step.name, err)
+			code = failure
+		default:
+			fmt.Fprintf(report, "PASS\t%s\t%s\n", step.name, detail)
+		}
+	}
+	_ = report.Flush()
+
+	return code
+}
+
+// steps returns the checks of the configuration, in the order in which they run.
+func (in *inspection) steps() []step {
+
+	steps := []step{
+		{name: "cache weights", run: in.weights},
+		{name: "transaction templates", run: in.templates},
+		{name: "operation types", run: in.operations},
+		{name: "alert webhook", run: in.alerts},
+		{name: "dps api", run: in.connect},
+		{name: "token definitions", run: in.tokens},
+		{name: "scripts", run: in.scripts},
+		{name: "contract migrations", run: in.migrations},
+		{name: "block store", run: in.store},
+		{name: "history store", run: in.history},
+	}
+
+	// The Access API of the latest spork is only known once the DPS API was
+	// checked, so the addresses are resolved when the check runs.
+	steps = append(steps, step{name: "access api", run: func() (string, error) {
+		return in.access(in.f.Access)
+	}})
+	for _, address := range in.f.Hedge {
+		address := address
+		steps = append(steps, step{name: "access api (hedge)", run: func() (string, error) {
+			return in.access(address)
+		}})
+	}
+	if in.f.Integrity > 0 {
+		steps = append(steps, step{name: "index integrity", run: in.integrity})
+	}
+
+	return steps
+}
+
+func (in *inspection) weights() (string, error) {
+	weights, err := split(in.f.Weights)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d caches weighted", len(weights)), nil
+}
+
+func (in *inspection) templates() (string, error) {
+	if in.f.Templates == "" {
+		return "no templates configured", nil
+	}
+	_, err := allowlist(in.f)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s verified against %d trusted hashes", in.f.Templates, len(in.f.Trusted)), nil
+}
+
+func (in *inspection) operations() (string, error) {
+	operations, err := operationTypes(in.f.Operations)
+	if err != nil {
+		return "", err
+	}
+	if len(operations) == 0 {
+		return "all operation types included", nil
+	}
+	return strings.Join(operations, ", "), nil
+}
+
+// alerts only validates the configuration of the webhook, as posting a test
+// alert would page the operators. The URL is not reported, as webhook URLs
+// usually embed their credentials.
+func (in *inspection) alerts() (string, error) {
+	if in.f.Alert == "" {
+		return "no webhook configured", nil
+	}
+	_, err := alert.NewWebhook(in.f.Alert,
+		alert.WithFormat(in.f.AlertFormat),
+		alert.WithRoutingKey(in.f.AlertKey),
+	)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s format", in.f.AlertFormat), nil
+}
+
+// connect only uses read operations on the index, so that it can also be run
+// against the DPS API of a server that is live.
+func (in *inspection) connect() (string, error) {
+
+	index, _, disconnect, err := connect(in.log, in.f)
+	if err != nil {
+		return "", err
+	}
+	in.disconnect = disconnect
+	in.index = index
+
+	params, err := chain(index)
+	if err != nil {
+		return "", err
+	}
+	in.params = &params
+
+	first, err := index.First()
+	if err != nil {
+		return "", fmt.Errorf("could not get first height: %w", err)
+	}
+	last, err := index.Last()
+	if err != nil {
+		return "", fmt.Errorf("could not get last height: %w", err)
+	}
+
+	return fmt.Sprintf("chain %s, heights %d to %d", params.ChainID, first, last), nil
+}
+
+func (in *inspection) tokens() (string, error) {
+	if in.params == nil {
+		return "", fmt.Errorf("chain parameters unknown: %w", errSkipped)
+	}
+	for _, symbol := range in.params.Symbols() {
+		err := scripts.ValidateToken(in.params.Tokens[symbol])
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%d tokens valid", len(in.params.Tokens)), nil
+}
+
+func (in *inspection) scripts() (string, error) {
+	if in.params == nil {
+		return "", fmt.Errorf("chain parameters unknown: %w", errSkipped)
+	}
+	generate := scripts.NewGenerator(*in.params)
+	err := precompile(generate, in.f)
+	if err != nil {
+		return "", err
+	}
+	count, err := render(generate, *in.params, in.f)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d scripts rendered and parsed", count), nil
+}
+
+func (in *inspection) migrations() (string, error) {
+	if in.f.Migrations == "" {
+		return "no migrations configured", nil
+	}
+	if in.params == nil {
+		return "", fmt.Errorf("chain parameters unknown: %w", errSkipped)
+	}
+	migrations, _, err := migrate(*in.params, in.f.Migrations)
+	if err != nil {
+		return "", err
+	}
+	for _, migration := range migrations {
+		generate, ok := migration.Generate.(*scripts.Generator)
+		if !ok {
+			continue
+		}
+		_, err = render(generate, *in.params, in.f)
+		if err != nil {
+			return "", fmt.Errorf("could not render scripts for migration (height: %d): %w", migration.Height, err)
+		}
+	}
+	return fmt.Sprintf("%d migrations applied", len(migrations)), nil
+}
+
+// store opens the block store read-only, so that it is never modified by a check.
+// A missing or empty directory is fine, as the server creates the database on
+// startup.
+func (in *inspection) store() (string, error) {
+	if in.f.BlockStore == "" {
+		return "no block store configured", nil
+	}
+	empty, err := isEmpty(in.f.BlockStore)
+	if err != nil {
+		return "", fmt.Errorf("could not check block store directory: %w", err)
+	}
+	if empty {
+		return fmt.Sprintf("%s empty or missing, database will be created on startup", in.f.BlockStore), nil
+	}
+	db, err := badger.Open(dps.DefaultOptions(in.f.BlockStore).WithReadOnly(true))
+	if err != nil {
+		return "", fmt.Errorf("could not open block store database read-only: %w", err)
+	}
+	_ = db.Close()
+	return fmt.Sprintf("%s readable", in.f.BlockStore), nil
+}
+
+// history opens the history store read-only and reports the range of heights
+// that it covers. As for the block store, a missing or empty directory is fine.
+func (in *inspection) history() (string, error) {
+	if in.f.History == "" {
+		return "no history store configured", nil
+	}
+	empty, err := isEmpty(in.f.History)
+	if err != nil {
+		return "", fmt.Errorf("could not check history store directory: %w", err)
+	}
+	if empty {
+		return fmt.Sprintf("%s empty or missing, database will be created on startup", in.f.History), nil
+	}
+	db, err := badger.Open(dps.DefaultOptions(in.f.History).WithReadOnly(true))
+	if err != nil {
+		return "", fmt.Errorf("could not open history store database read-only: %w", err)
+	}
+	defer db.Close()
+	store, err := history.New(db)
+	if err != nil {
+		return "", fmt.Errorf("could not load history store: %w", err)
+	}
+	first, last, ok := store.Range()
+	if !ok {
+		return fmt.Sprintf("%s readable, no blocks indexed yet", in.f.History), nil
+	}
+	return fmt.Sprintf("%s readable, heights %d to %d indexed", in.f.History, first, last), nil
+}
+
+func (in *inspection) access(address string) (string, error) {
+
+	if address == "" {
+		return "", fmt.Errorf("access API endpoint is missing")
+	}
+
+	accessAPI, err := dial(address, in.f.Inflight)
+	if err != nil {
+		return "", fmt.Errorf("could not dial Flow Access API address (address: %s): %w", address, err)
+	}
+	defer accessAPI.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	header, err := accessAPI.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return "", fmt.Errorf("could not get latest sealed block header (address: %s): %w", address, err)
+	}
+
+	return fmt.Sprintf("%s sealed at height %d", address, header.Height), nil
+}
+
+// integrity compares a sample of indexed block headers to those of the Access API,
+// like the server does on startup.
+func (in *inspection) integrity() (string, error) {
+
+	if in.index == nil {
+		return "", fmt.Errorf("index unavailable: %w", errSkipped)
+	}
+
+	accessAPI, err := dial(in.f.Access, in.f.Inflight)
+	if err != nil {
+		return "", fmt.Errorf("could not dial Flow Access API address (address: %s): %w", in.f.Access, err)
+	}
+	defer accessAPI.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	report, err := integrity.New(in.index, accessAPI, integrity.WithSamples(in.f.Integrity)).Check(ctx)
+	if err != nil {
+		return "", err
+	}
+	err = report.Err()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d headers match, %d skipped", report.Checked, report.Skipped), nil
+}
+
+func (in *inspection) close() {
+	if in.disconnect != nil {
+		in.disconnect()
+	}
+}
+
+// render generates every script of the given generator for all tokens, and parses
+// the Cadence scripts and transactions, so that template mistakes are caught before
+// they are executed. It returns the number of scripts that were rendered.
+func render(generate *scripts.Generator, params dps.Params, f *flags) (int, error) {
+
+	var programs []func() ([]byte, error)
+	var events []func() (string, error)
+	for _, symbol := range params.Symbols() {
+		symbol := symbol
+		programs = append(programs,
+			func() ([]byte, error) { return generate.GetBalance(symbol) },
+			func() ([]byte, error) { return generate.GetVaultBalances(symbol, f.Vaults) },
+			func() ([]byte, error) { return generate.TransferTokens(symbol) },
+		)
+		events = append(events,
+			func() (string, error) { return generate.TokensDeposited(symbol) },
+			func() (string, error) { return generate.TokensWithdrawn(symbol) },
+		)
+	}
+	programs = append(programs,
+		generate.GetNodeInfo,
+		generate.GetLockedAccount,
+		generate.GetMachineAccounts,
+		generate.AddAccountKey,
+		generate.RevokeAccountKey,
+		generate.StakeNewTokens,
+		generate.RequestUnstaking,
+		generate.WithdrawUnstakedTokens,
+		generate.WithdrawRewardedTokens,
+		generate.GetTransactionFee,
+	)
+	if scripts.HasFUSD(params.ChainID) {
+		programs = append(programs, generate.TransferFUSD)
+	}
+	if f.Storage {
+		programs = append(programs, generate.GetStorageInfo)
+	}
+	events = append(events,
+		generate.RewardsPaid,
+		generate.DelegatorRewardsPaid,
+	)
+
+	for _, program := range programs {
+		script, err := program()
+		if err != nil {
+			return 0, fmt.Errorf("could not generate script: %w", err)
+		}
+		_, err = parser2.ParseProgram(string(script))
+		if err != nil {
+			return 0, fmt.Errorf("could not parse script: %w", err)
+		}
+	}
+	for _, event := range events {
+		_, err := event()
+		if err != nil {
+			return 0, fmt.Errorf("could not generate event type: %w", err)
+		}
+	}
+
+	return len(programs) + len(events), nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/optakt/flow-rosetta/rosetta/alert"
+	"github.com/optakt/flow-rosetta/rosetta/transactor"
+)
+
+// flags is the configuration of the Flow Rosetta Server, as given on the command line.
+type flags struct {
+	DPS          string
+	DPSTLS       bool
+	DPSCA        string
+	Access       string
+	Cache        uint64
+	Weights      map[string]int
+	Workers      uint
+	Computation  uint64
+	Interaction  uint64
+	Timeout      time.Duration
+	Queue        uint
+	Conversion   uint
+	Inflight     uint
+	Hedge        []string
+	HedgeDelay   time.Duration
+	ShedInflight uint
+	ShedLatency  time.Duration
+	ShedQueue    uint
+	ReadyBlocks  uint64
+	ReadyDelay   time.Duration
+	LagPoll      time.Duration
+	Level        string
+	Port         uint16
+	RPC          uint16
+	Transactions uint
+	Blocks       uint
+	Search       uint
+	Rewards      uint
+	Smart        bool
+	Wait         bool
+	Dump         bool
+	Check        bool
+	Dedup        time.Duration
+	Templates    string
+	Trusted      []string
+	GasLimit     uint64
+	Vaults       []string
+	Locked       bool
+	Machines     bool
+	Storage      bool
+	Sample       uint
+	Checkpoints  uint64
+	Poll         time.Duration
+	Alert        string
+	AlertFormat  string
+	AlertKey     string
+	AlertSource  string
+	Cooldown     time.Duration
+	Sporks       string
+	BlockStore   string
+	StoreSize    uint64
+	History      string
+	HistoryStart uint64
+	HistoryPoll  time.Duration
+	Collapse     bool
+	SealedOnly   bool
+	ErrorDocs    string
+	Prefetch     time.Duration
+	Migrations   string
+	Operations   []string
+	Disabled     []string
+	Replica      string
+	ReplicaDir   string
+	ReplicaPoll  time.Duration
+	Bootstrap    string
+	Checksum     string
+	Integrity    uint
+	Degraded     bool
+}
+
+// register adds the command line flags for the full server configuration to the
+// given flag set, with their default values.
+func (f *flags) register(set *pflag.FlagSet) {
+	set.StringVarP(&f.DPS, "dps-api", "a", "127.0.0.1:5005", "host address for GRPC API endpoint")
+	set.BoolVar(&f.DPSTLS, "dps-tls", false, "connect to the DPS API over TLS, verified against the system certificate pool unless a CA certificate is given")
+	set.StringVar(&f.DPSCA, "dps-tls-ca", "", "path to the PEM-encoded CA certificate to verify the DPS API with, which enables TLS (empty for the system certificate pool)")
+	set.StringVarP(&f.Access, "access-api", "c", "access.canary.nodes.onflow.org:9000", "host address for Flow network's Access API endpoint")
+	set.Uint64VarP(&f.Cache, "cache", "e", 1_500_000_000, "memory budget in bytes shared by all in-process caches")
+	set.StringToIntVar(&f.Weights, "cache-weights", map[string]int{cacheRegisters: 4, cacheScripts: 1, cacheBlocks: 1}, "relative shares of the memory budget for the register, script result and block caches (0 to disable a cache)")
+	set.UintVar(&f.Workers, "script-workers", uint(runtime.NumCPU()), "maximum amount of Cadence executions to run at the same time")
+	set.UintVar(&f.Queue, "script-queue", 100, "maximum amount of Cadence executions waiting for a worker before requests are rejected")
+	set.UintVar(&f.Conversion, "conversion-workers", uint(runtime.NumCPU()), "maximum amount of transactions of a block to convert in parallel")
+	set.UintVar(&f.ShedInflight, "shed-inflight", 0, "maximum amount of API requests in flight before new ones are rejected as overloaded (0 for no limit)")
+	set.DurationVar(&f.ShedLatency, "shed-latency", 0, "99th percentile API latency above which new requests are rejected as overloaded (0 to disable)")
+	set.UintVar(&f.ShedQueue, "shed-queue", 0, "amount of Cadence executions waiting for a worker above which new API requests are rejected as overloaded (0 to disable)")
+	set.Uint64Var(&f.ReadyBlocks, "ready-max-lag-blocks", 0, "maximum amount of sealed blocks the index can lag behind before the readiness endpoint reports it as not ready (0 to disable)")
+	set.DurationVar(&f.ReadyDelay, "ready-max-lag-duration", 0, "maximum age of the last indexed block before the readiness endpoint reports the index as not ready (0 to disable)")
+	set.UintVar(&f.Integrity, "integrity-samples", 0, "amount of indexed block headers to compare to those of the Access API on startup, spread over the indexed heights (0 to disable)")
+	set.BoolVar(&f.Degraded, "integrity-degraded", false, "serve an index whose block headers do not match the Access API, flagged as not ready, instead of refusing to start")
+	set.DurationVar(&f.LagPoll, "lag-poll", 10*time.Second, "how often to measure the index lag behind the sealed height for the metrics (0 to disable)")
+	set.UintVar(&f.Inflight, "access-inflight", 64, "maximum amount of requests in flight to the Flow Access API (0 for no limit)")
+	set.StringSliceVar(&f.Hedge, "access-hedge", nil, "host addresses of additional Flow Access API endpoints to hedge latency-sensitive requests against")
+	set.DurationVar(&f.HedgeDelay, "hedge-delay", 200*time.Millisecond, "how long to wait for an Access API response before hedging the request against the next endpoint")
+	set.Uint64Var(&f.Computation, "script-computation-limit", 100_000, "maximum amount of computation for a single Cadence execution")
+	set.Uint64Var(&f.Interaction, "script-interaction-limit", 20_000_000, "maximum amount of bytes of execution state that a single Cadence execution can read")
+	set.DurationVar(&f.Timeout, "script-timeout", 0, "maximum duration to wait for a single Cadence execution, which keeps its worker until it stops (0 to disable)")
+	set.StringVarP(&f.Level, "level", "l", "info", "log output level")
+	set.Uint16VarP(&f.Port, "port", "p", 8080, "port to host Rosetta API on")
+	set.Uint16Var(&f.RPC, "grpc-port", 0, "port to host the GRPC mirror of the Rosetta Data API on (0 to disable)")
+	set.UintVarP(&f.Transactions, "transaction-limit", "t", 200, "maximum amount of transactions to include in a block response")
+	set.UintVar(&f.Blocks, "block-limit", 100, "maximum amount of blocks to include in a block range response")
+	set.UintVar(&f.Search, "search-limit", 1000, "maximum amount of blocks to walk through for a single transaction search request")
+	set.UintVar(&f.Rewards, "reward-limit", 100_000, "maximum amount of blocks to walk through for a single rewards request")
+	set.StringVar(&f.BlockStore, "block-store", "", "path to a database directory to persist converted blocks across restarts (empty to disable)")
+	set.Uint64Var(&f.StoreSize, "block-store-size", 1<<30, "maximum size in bytes of the compressed blocks kept in the block store")
+	set.StringVar(&f.History, "history-store", "", "path to a database directory to index the transactions of each account in, which enables the account transactions endpoint (empty to disable)")
+	set.Uint64Var(&f.HistoryStart, "history-start", 0, "height from which to start indexing account transactions into an empty history store (0 for the oldest indexed block)")
+	set.DurationVar(&f.HistoryPoll, "history-poll", time.Second, "how often to check for new blocks to index into the history store")
+	set.DurationVar(&f.Prefetch, "prefetch-poll", 0, "how often to check for new blocks to convert ahead of requests into the block cache (0 to disable)")
+	set.StringSliceVar(&f.Disabled, "disabled-endpoints", nil, "paths of API endpoints to reject with an endpoint disabled error, along with their GRPC mirror, where a path ending with a slash disables all endpoints below it, such as /construction/")
+	set.BoolVar(&f.Collapse, "collapse-requests", true, "execute concurrent identical requests only once and share the response")
+	set.BoolVar(&f.SealedOnly, "sealed-only", false, "reject requests for blocks that are not sealed yet instead of serving them flagged as unsealed in their metadata")
+	set.BoolVar(&f.Smart, "smart-status-codes", false, "enable smart non-500 HTTP status codes for Rosetta API errors")
+	set.StringVar(&f.ErrorDocs, "error-docs", "", "base URL of the documentation of Rosetta API errors, to which the error code is appended to link each error (empty to disable)")
+	set.BoolVar(&f.Dump, "dump-requests", false, "print out full request and responses")
+	set.BoolVar(&f.Check, "self-check", false, "validate all responses against the Rosetta specification and log violations, useful in staging")
+	set.DurationVar(&f.Dedup, "dedup-window", 10*time.Minute, "duration for which submitted transactions are remembered to make resubmissions idempotent (0 to disable)")
+	set.StringVar(&f.Templates, "templates", "", "path to the JSON manifest of allowlisted transaction templates for the Construction API")
+	set.StringSliceVar(&f.Trusted, "template-hashes", nil, "hex-encoded SHA3-256 hashes of the trusted transaction template scripts, required for every template in the manifest")
+	set.Uint64Var(&f.GasLimit, "gas-limit", transactor.DefaultGasLimit, "default gas limit of constructed transactions, which the suggested fee multiplier scales up to the maximum")
+	set.StringSliceVar(&f.Vaults, "balance-paths", nil, "additional public paths of vault balance capabilities to aggregate into account balances")
+	set.BoolVar(&f.Locked, "locked-balances", false, "include the balance and unlock limit of locked accounts held through the LockedTokens contract in account balances")
+	set.BoolVar(&f.Machines, "machine-balances", false, "include the balances of the machine accounts of nodes operated through a staking collection in account balances")
+	set.BoolVar(&f.Storage, "storage-usage", false, "include the storage usage, storage capacity and minimum FLOW reservation of accounts in account balances")
+	set.UintVar(&f.Sample, "reconcile-sample", 0, "maximum amount of active accounts to reconcile for each range of new blocks (0 to disable)")
+	set.Uint64Var(&f.Checkpoints, "reconcile-interval", 100, "amount of blocks between two balance checkpoints of the reconciliation")
+	set.DurationVar(&f.Poll, "reconcile-poll", 30*time.Second, "how often to check for new blocks to reconcile")
+	set.StringVar(&f.Alert, "alert-webhook", "", "URL to post operational alerts to, such as reconciliation drift and index lag breaches of the readiness thresholds (empty to only log balance mismatches)")
+	set.StringVar(&f.AlertFormat, "alert-format", alert.FormatSlack, "payload format of operational alerts, either \"slack\" or \"pagerduty\"")
+	set.StringVar(&f.AlertKey, "alert-routing-key", "", "integration key of the PagerDuty service to route operational alerts to")
+	set.StringVar(&f.AlertSource, "alert-source", "flow-rosetta", "name of this instance in operational alerts")
+	set.DurationVar(&f.Cooldown, "alert-cooldown", 10*time.Minute, "duration during which repeated alerts for the same ongoing anomaly are suppressed")
+	set.StringVar(&f.Replica, "replica-snapshot", "", "path to the index snapshots exported while the indexer is stopped, which are loaded into a private copy of the index instead of using the DPS API (empty to disable)")
+	set.StringVar(&f.ReplicaDir, "replica-dir", "", "path to the directory in which to keep the private copy of the index loaded from its snapshots (empty for the temporary directory)")
+	set.DurationVar(&f.ReplicaPoll, "replica-poll", time.Minute, "how often to check for a newer index snapshot to load")
+	set.StringVar(&f.Bootstrap, "bootstrap-url", "", "URL of an index snapshot in object storage, such as a pre-signed S3 or GCS URL, to download as the replica snapshot on first boot (empty to disable)")
+	set.StringVar(&f.Checksum, "bootstrap-sha256", "", "hex-encoded SHA-256 checksum that the downloaded index snapshot has to match")
+	set.StringVar(&f.Sporks, "sporks", "", "path to the JSON configuration of sporks to serve, which replaces the DPS API and Access API addresses")
+	set.StringSliceVar(&f.Operations, "operation-types", nil, "allowlist of operation types to include in transactions of the Data API, which must contain TRANSFER and can contain TEMPLATE for failed staking transactions, with other operations moved into the transaction metadata (empty for all)")
+	set.StringVar(&f.Migrations, "contract-migrations", "", "path to the JSON configuration of historical core contract addresses and token types")
+	set.BoolVarP(&f.Wait, "wait-for-index", "w", false, "wait for index to be available instead of quitting right away, useful when DPS Live index bootstraps")
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+)
+
+const (
+	success = 0
+	failure = 1
+)
+
+// version is the version of the binary, which is set at build time.
+var version = "dev"
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+
+	// All subcommands that need the server configuration share the same flags,
+	// so that a configuration can be checked exactly as it would be served.
+	var f flags
+	code := success
+
+	root := &cobra.Command{
+		Use:           "flow-rosetta",
+		Short:         "Rosetta API implementation for the Flow network, backed by the Flow DPS index",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.CompletionOptions.DisableDefaultCmd = true
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the Rosetta Data and Construction APIs",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			code = serve(&f)
+		},
+	}
+	f.register(serveCmd.Flags())
+
+	checkCmd := &cobra.Command{
+		Use:   "check-config",
+		Short: "Validate the configuration and the connectivity to the DPS and Access APIs, then exit",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			code = check(&f)
+		},
+	}
+	f.register(checkCmd.Flags())
+
+	preflightCmd := &cobra.Command{
+		Use:   "preflight",
+		Short: "Run all configuration and connectivity checks and print a report of what will and won't work",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			code = preflight(&f)
+		},
+	}
+	f.register(preflightCmd.Flags())
+
+	// The statement command builds its retriever from the server configuration,
+	// so that it converts events exactly as the Data API does.
+	var (
+		flagAddress string
+		flagStart   uint64
+		flagEnd     uint64
+		flagFormat  string
+		flagPath    string
+	)
+	statementCmd := &cobra.Command{
+		Use:   "statement",
+		Short: "Export all operations affecting an account over a range of heights to a CSV or JSON file",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			code = statement(&f, flagAddress, flagStart, flagEnd, flagFormat, flagPath)
+		},
+	}
+	f.register(statementCmd.Flags())
+	statementCmd.Flags().StringVarP(&flagAddress, "address", "d", "", "address of the account to export the statement for")
+	statementCmd.Flags().Uint64VarP(&flagStart, "start", "s", 0, "first height of the statement (default first indexed height)")
+	statementCmd.Flags().Uint64VarP(&flagEnd, "end", "n", 0, "last height of the statement (default last indexed height)")
+	statementCmd.Flags().StringVarP(&flagFormat, "format", "f", formatCSV, "output format of the statement (csv or json)")
+	statementCmd.Flags().StringVarP(&flagPath, "output", "o", "", "path of the file to write the statement to (default standard output)")
+
+	// The snapshot commands work on a Badger database directory, such as a DPS
+	// index or a block store, so they do not need the server configuration.
+	var (
+		flagLevel  string
+		flagIndex  string
+		flagOutput string
+		flagInput  string
+	)
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Export or import compressed snapshots of an index database",
+	}
+	snapshotCmd.PersistentFlags().StringVarP(&flagLevel, "level", "l", "info", "log output level")
+	snapshotCmd.PersistentFlags().StringVarP(&flagIndex, "index", "i", "index", "database directory of the index")
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write a compressed snapshot of a stopped index to a file",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			code = exportSnapshot(flagLevel, flagIndex, flagOutput)
+		},
+	}
+	exportCmd.Flags().StringVarP(&flagOutput, "output", "o", "index.snapshot", "path of the snapshot file to create")
+
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Bootstrap an empty index from a compressed snapshot file",
+		Args:  cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			code = importSnapshot(flagLevel, flagInput, flagIndex)
+		},
+	}
+	importCmd.Flags().StringVar(&flagInput, "input", "index.snapshot", "path of the snapshot file to load")
+
+	snapshotCmd.AddCommand(exportCmd, importCmd)
+
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of the binary and of the implemented APIs",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "flow-rosetta %s\n", version)
+			fmt.Fprintf(out, "rosetta %s\n", configuration.RosettaVersion)
+			fmt.Fprintf(out, "middleware %s\n", configuration.MiddlewareVersion)
+			fmt.Fprintf(out, "node %s\n", configuration.NodeVersion)
+		},
+	}
+
+	root.AddCommand(serveCmd, checkCmd, preflightCmd, statementCmd, snapshotCmd, versionCmd)
+
+	err := root.Execute()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return failure
+	}
+
+	return code
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/converter"
+	"github.com/optakt/flow-rosetta/rosetta/invoker"
+	"github.com/optakt/flow-rosetta/rosetta/pool"
+	"github.com/optakt/flow-rosetta/rosetta/retriever"
+	"github.com/optakt/flow-rosetta/rosetta/scripts"
+	"github.com/optakt/flow-rosetta/rosetta/submitter"
+	"github.com/optakt/flow-rosetta/rosetta/templates"
+	"github.com/optakt/flow-rosetta/rosetta/tracker"
+	"github.com/optakt/flow-rosetta/rosetta/transactor"
+	"github.com/optakt/flow-rosetta/rosetta/validator"
+)
+
+// network is an additional network served next to the default one, such as the
+// next spork during a blue/green cutover, with its own index and Access API.
+type network struct {
+	name   string
+	index  dps.Reader
+	access string
+}
+
+// stack holds the components that serve the requests for an additional network.
+type stack struct {
+	config   *configuration.Configuration
+	validate *validator.Validator
+	retrieve *retriever.Retriever
+	transact *transactor.Transactor
+	close    func()
+}
+
+// build creates the stack of the given additional network, with the same settings
+// as the one of the default network. The block store, the account history and the
+// in-process caches are only used for the default network, so the stack converts
+// blocks on every request and has no account transactions. The statement command
+// builds the stack of the default network the same way, as it exports a statement
+// once and does not need them either. The returned stack has to be closed once it
+// is no longer used.
+func build(f *flags, additional network, operations []string, registry *templates.Registry) (*stack, error) {
+
+	params, err := chain(additional.index)
+	if err != nil {
+		return nil, fmt.Errorf("could not get chain parameters: %w", err)
+	}
+
+	accessAPI, err := dial(additional.access, f.Inflight)
+	if err != nil {
+		return nil, fmt.Errorf("could not dial Flow Access API address (address: %s): %w", additional.access, err)
+	}
+
+	config := configuration.New(params.ChainID,
+		configuration.WithOperationTypes(operations...),
+		configuration.WithSubNetwork(additional.name),
+	)
+	track := tracker.New(accessAPI)
+	validate := validator.New(params, additional.index, track, config,
+		validator.WithSealedOnly(f.SealedOnly),
+	)
+	generate := scripts.NewGenerator(params)
+	vm, err := invoker.New(additional.index,
+		invoker.WithComputationLimit(f.Computation),
+		invoker.WithInteractionLimit(f.Interaction),
+	)
+	if err != nil {
+		_ = accessAPI.Close()
+		return nil, fmt.Errorf("could not initialize invoker: %w", err)
+	}
+	executions := pool.New(vm,
+		pool.WithWorkers(f.Workers),
+		pool.WithQueue(f.Queue),
+		pool.WithTimeout(f.Timeout),
+	)
+
+	err = precompile(generate, f)
+	if err != nil {
+		_ = accessAPI.Close()
+		return nil, fmt.Errorf("could not precompile scripts: %w", err)
+	}
+	migrations, legacy, err := migrate(params, f.Migrations)
+	if err != nil {
+		_ = accessAPI.Close()
+		return nil, fmt.Errorf("could not load contract migrations: %w", err)
+	}
+	generators := make([]converter.Generator, 0, len(legacy))
+	for _, gen := range legacy {
+		generators = append(generators, gen)
+	}
+	convert, err := converter.New(generate, params, generators...)
+	if err != nil {
+		_ = accessAPI.Close()
+		return nil, fmt.Errorf("could not generate transaction event types: %w", err)
+	}
+	known, err := recognize(params, generate, legacy)
+	if err != nil {
+		_ = accessAPI.Close()
+		return nil, fmt.Errorf("could not generate known transaction scripts: %w", err)
+	}
+
+	var finality retriever.Tracker
+	if !f.SealedOnly {
+		finality = track
+	}
+	retrieve := retriever.New(params, additional.index, validate, generate, executions, convert,
+		retriever.WithTransactionLimit(f.Transactions),
+		retriever.WithBlockLimit(f.Blocks),
+		retriever.WithSearchLimit(f.Search),
+		retriever.WithRewardLimit(f.Rewards),
+		retriever.WithConversionWorkers(f.Conversion),
+		retriever.WithBalancePaths(f.Vaults...),
+		retriever.WithLockedTokens(f.Locked),
+		retriever.WithMachineAccounts(f.Machines),
+		retriever.WithStorageUsage(f.Storage),
+		retriever.WithMigrations(migrations...),
+		retriever.WithSoftFinality(finality),
+		retriever.WithOperationTypes(operations...),
+		retriever.WithIntents(known),
+	)
+
+	submit := submitter.New(accessAPI,
+		submitter.WithDeduplicationWindow(f.Dedup),
+	)
+	transact := transactor.New(validate, generate, executions, submit,
+		transactor.WithTemplates(registry),
+		transactor.WithIntents(known),
+		transactor.WithGasLimit(f.GasLimit),
+	)
+
+	s := stack{
+		config:   config,
+		validate: validate,
+		retrieve: retrieve,
+		transact: transact,
+		close:    func() { _ = accessAPI.Close() },
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,650 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/ziflex/lecho/v2"
+	"google.golang.org/grpc"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/api/rosetta"
+	"github.com/optakt/flow-rosetta/api/rpc"
+	"github.com/optakt/flow-rosetta/rosetta/alert"
+	"github.com/optakt/flow-rosetta/rosetta/cache"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/converter"
+	"github.com/optakt/flow-rosetta/rosetta/hedger"
+	"github.com/optakt/flow-rosetta/rosetta/history"
+	"github.com/optakt/flow-rosetta/rosetta/integrity"
+	"github.com/optakt/flow-rosetta/rosetta/invoker"
+	"github.com/optakt/flow-rosetta/rosetta/lag"
+	"github.com/optakt/flow-rosetta/rosetta/memory"
+	"github.com/optakt/flow-rosetta/rosetta/pool"
+	"github.com/optakt/flow-rosetta/rosetta/prefetcher"
+	"github.com/optakt/flow-rosetta/rosetta/reconciler"
+	"github.com/optakt/flow-rosetta/rosetta/retriever"
+	"github.com/optakt/flow-rosetta/rosetta/scripts"
+	"github.com/optakt/flow-rosetta/rosetta/shedder"
+	"github.com/optakt/flow-rosetta/rosetta/storage"
+	"github.com/optakt/flow-rosetta/rosetta/submitter"
+	"github.com/optakt/flow-rosetta/rosetta/tracker"
+	"github.com/optakt/flow-rosetta/rosetta/transactor"
+	"github.com/optakt/flow-rosetta/rosetta/validator"
+)
+
+// serve runs the Flow Rosetta Server with the given configuration until it is
+// interrupted, and returns the exit code of the process.
+func serve(f *flags) int {
+
+	// Signal catching for clean shutdown.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+
+	// Logger initialization.
+	log, err := logger(f.Level)
+	if err != nil {
+		log.Error().Str("level", f.Level).Err(err).Msg("could not parse log level")
+		return failure
+	}
+	elog := lecho.From(log)
+
+	// On first boot, a replica can download its index snapshot from object
+	// storage before it starts serving from it.
+	err = provision(log, f)
+	if err != nil {
+		log.Error().Err(err).Msg("could not bootstrap index snapshot")
+		return failure
+	}
+
+	// Initialize the DPS API client, or one client per spork if sporks are
+	// configured.
+	index, networks, disconnect, err := connect(log, f)
+	if err != nil {
+		log.Error().Err(err).Msg("could not connect to DPS API")
+		return failure
+	}
+	defer disconnect()
+
+wait:
+	// Deduce chain ID from DPS API to configure parameters for script exec.
+	params, err := chain(index)
+	if err != nil {
+		log.Error().Err(err).Msg("could not get chain parameters from DPS API")
+		if f.Wait {
+			time.Sleep(30 * time.Second)
+			goto wait
+		}
+		return failure
+	}
+
+	// Initialize the SDK client.
+	if f.Access == "" {
+		log.Error().Msg("Flow Access API endpoint is missing")
+		return failure
+	}
+	accessAPI, err := dial(f.Access, f.Inflight)
+	if err != nil {
+		log.Error().Str("address", f.Access).Err(err).Msg("could not dial Flow Access API address")
+		return failure
+	}
+	defer accessAPI.Close()
+
+	// A sample of the indexed blocks is compared to those of the Flow network,
+	// so that an index for another chain or fork, or a corrupted one, is not
+	// served. Heights that the Access API does not know are skipped.
+	var degraded error
+	if f.Integrity > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		report, err := integrity.New(index, accessAPI, integrity.WithSamples(f.Integrity)).Check(ctx)
+		cancel()
+		if err != nil {
+			log.Error().Err(err).Msg("could not check index integrity")
+			return failure
+		}
+		degraded = report.Err()
+		if degraded != nil && !f.Degraded {
+			log.Error().Err(degraded).Msg("index integrity check failed")
+			return failure
+		}
+		if degraded != nil {
+			log.Warn().Err(degraded).Msg("index integrity check failed, serving as degraded")
+		} else {
+			log.Info().Uint("checked", report.Checked).Uint("skipped", report.Skipped).Msg("index integrity verified")
+		}
+	}
+
+	// If additional access nodes are given, latency-sensitive requests for
+	// block headers are hedged against them, so that a single slow upstream
+	// replica does not stall the whole API.
+	var headers tracker.API = accessAPI
+	if len(f.Hedge) > 0 {
+		fallbacks := make([]hedger.API, 0, len(f.Hedge))
+		for _, address := range f.Hedge {
+			fallback, err := dial(address, f.Inflight)
+			if err != nil {
+				log.Error().Str("address", address).Err(err).Msg("could not dial hedging Flow Access API address")
+				return failure
+			}
+			defer fallback.Close()
+			fallbacks = append(fallbacks, fallback)
+		}
+		headers = hedger.New(accessAPI, fallbacks, hedger.WithDelay(f.HedgeDelay))
+	}
+
+	// If smart status codes are enabled for the Rosetta API, we use the HTTP
+	// status codes of the error definitions instead of always returning 500.
+	if f.Smart {
+		rosetta.EnableSmartCodes()
+	}
+
+	// If a documentation URL is given, each Rosetta API error links to the
+	// documentation page for its code, such as the operator's runbook.
+	if f.ErrorDocs != "" {
+		rosetta.EnableErrorDocs(f.ErrorDocs)
+	}
+
+	// All in-process caches share a single memory budget, split between them
+	// according to their weights, so that the total memory used for caching
+	// stays bounded.
+	weights, err := split(f.Weights)
+	if err != nil {
+		log.Error().Err(err).Msg("could not split memory budget")
+		return failure
+	}
+	budget := memory.NewBudget(f.Cache, weights)
+	cacheMetrics := memory.NewMetrics(prometheus.DefaultRegisterer)
+	log.Info().Str("budget", budget.String()).Msg("memory budget for caches split")
+
+	// Rosetta API initialization.
+	operations, err := operationTypes(f.Operations)
+	if err != nil {
+		log.Error().Strs("operation_types", f.Operations).Err(err).Msg("could not check operation types")
+		return failure
+	}
+	config := configuration.New(params.ChainID,
+		configuration.WithOperationTypes(operations...),
+	)
+	track := tracker.New(headers)
+	validate := validator.New(params, index, track, config,
+		validator.WithSealedOnly(f.SealedOnly),
+	)
+	generate := scripts.NewGenerator(params)
+	vm, err := invoker.New(index,
+		invoker.WithCacheSize(budget.Share(cacheRegisters)),
+		invoker.WithComputationLimit(f.Computation),
+		invoker.WithInteractionLimit(f.Interaction),
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("could not initialize invoker")
+		return failure
+	}
+
+	// Cadence executions go through a bounded pool, so that bursts of requests
+	// wait or get rejected instead of running the virtual machine without limit.
+	// The state at indexed heights never changes, so script results can be kept
+	// and reused for identical scripts and arguments at the same height; cached
+	// results are served without taking a worker of the pool.
+	executions := pool.New(vm,
+		pool.WithWorkers(f.Workers),
+		pool.WithQueue(f.Queue),
+		pool.WithTimeout(f.Timeout),
+	)
+	var invoke cache.Invoker = executions
+	if budget.Share(cacheScripts) > 0 {
+		invoke = cache.New(invoke, memory.NewLRU(cacheScripts, budget.Share(cacheScripts), cacheMetrics))
+	}
+
+	// We generate the scripts that depend on the configuration once, so that
+	// configuration mistakes are caught on startup rather than on every request.
+	err = precompile(generate, f)
+	if err != nil {
+		log.Error().Err(err).Msg("could not precompile scripts")
+		return failure
+	}
+
+	// If core contracts were migrated at some point, historical heights need
+	// their own generators so that event types and script imports resolve to
+	// the contracts that were in effect at the time.
+	migrations, legacy, err := migrate(params, f.Migrations)
+	if err != nil {
+		log.Error().Str("contract_migrations", f.Migrations).Err(err).Msg("could not load contract migrations")
+		return failure
+	}
+
+	generators := make([]converter.Generator, 0, len(legacy))
+	for _, gen := range legacy {
+		generators = append(generators, gen)
+	}
+	convert, err := converter.New(generate, params, generators...)
+	if err != nil {
+		log.Error().Err(err).Msg("could not generate transaction event types")
+		return failure
+	}
+
+	// Transactions without events, such as failed ones, are classified by their
+	// script instead, if it is one of the known transaction scripts.
+	known, err := recognize(params, generate, legacy)
+	if err != nil {
+		log.Error().Err(err).Msg("could not generate known transaction scripts")
+		return failure
+	}
+
+	// If a block store is configured, converted blocks are persisted in a
+	// local database, so that they survive restarts.
+	var store retriever.Store
+	if f.BlockStore != "" {
+		db, err := badger.Open(dps.DefaultOptions(f.BlockStore))
+		if err != nil {
+			log.Error().Str("block_store", f.BlockStore).Err(err).Msg("could not open block store database")
+			return failure
+		}
+		defer db.Close()
+		digest, err := fingerprint(params, f, operations)
+		if err != nil {
+			log.Error().Err(err).Msg("could not fingerprint block conversion configuration")
+			return failure
+		}
+		store, err = storage.New(log, db,
+			storage.WithCapacity(f.StoreSize),
+			storage.WithFingerprint(digest),
+		)
+		if err != nil {
+			log.Error().Err(err).Msg("could not initialize block store")
+			return failure
+		}
+	}
+
+	// If a history store is configured, the transactions affecting each account
+	// are indexed as new blocks are processed, so that they can be listed
+	// without walking through all blocks.
+	var accounts *history.Store
+	var histories retriever.History
+	if f.History != "" {
+		db, err := badger.Open(dps.DefaultOptions(f.History))
+		if err != nil {
+			log.Error().Str("history_store", f.History).Err(err).Msg("could not open history store database")
+			return failure
+		}
+		defer db.Close()
+		accounts, err = history.New(db)
+		if err != nil {
+			log.Error().Err(err).Msg("could not initialize history store")
+			return failure
+		}
+		histories = accounts
+	}
+	var blocks retriever.Cache
+	if budget.Share(cacheBlocks) > 0 {
+		blocks = memory.NewLRU(cacheBlocks, budget.Share(cacheBlocks), cacheMetrics)
+	}
+
+	// In soft-finality mode, blocks are served as soon as they are indexed, and
+	// those that are not sealed yet are flagged in their metadata. In strict
+	// mode, the validator rejects them, so there is nothing to flag.
+	var finality retriever.Tracker
+	if !f.SealedOnly {
+		finality = track
+	}
+	retrieve := retriever.New(params, index, validate, generate, invoke, convert,
+		retriever.WithTransactionLimit(f.Transactions),
+		retriever.WithBlockLimit(f.Blocks),
+		retriever.WithSearchLimit(f.Search),
+		retriever.WithRewardLimit(f.Rewards),
+		retriever.WithConversionWorkers(f.Conversion),
+		retriever.WithBalancePaths(f.Vaults...),
+		retriever.WithLockedTokens(f.Locked),
+		retriever.WithMachineAccounts(f.Machines),
+		retriever.WithStorageUsage(f.Storage),
+		retriever.WithMigrations(migrations...),
+		retriever.WithBlockCache(blocks),
+		retriever.WithBlockStore(store),
+		retriever.WithAccountHistory(histories),
+		retriever.WithSoftFinality(finality),
+		retriever.WithOperationTypes(operations...),
+		retriever.WithIntents(known),
+	)
+	dataCtrl := rosetta.NewData(config, retrieve, validate)
+
+	// Operational alerts are posted to a webhook, so that small operators are
+	// notified of anomalies without running a full monitoring stack.
+	var notify *alert.Webhook
+	if f.Alert != "" {
+		notify, err = alert.NewWebhook(f.Alert,
+			alert.WithFormat(f.AlertFormat),
+			alert.WithRoutingKey(f.AlertKey),
+			alert.WithSource(f.AlertSource),
+			alert.WithCooldown(f.Cooldown),
+		)
+		if err != nil {
+			log.Error().Str("alert_format", f.AlertFormat).Err(err).Msg("could not initialize alert webhook")
+			return failure
+		}
+	}
+
+	// The reconciliation worker follows new blocks and checks the balances of
+	// a sample of active accounts, so that conversion bugs are noticed early.
+	// Mismatches are posted to the alert webhook, or logged without one.
+	var alerter reconciler.Alerter = reconciler.NewLog(log)
+	if notify != nil {
+		alerter = reconciler.NewNotify(notify)
+	}
+	metrics := reconciler.NewMetrics(prometheus.DefaultRegisterer)
+	worker := reconciler.NewWorker(log, retrieve, alerter, metrics,
+		reconciler.WithInterval(f.Checkpoints),
+		reconciler.WithSampleSize(f.Sample),
+		reconciler.WithPoll(f.Poll),
+	)
+
+	// The prefetcher converts new blocks as soon as they are indexed, so that
+	// requests for the latest block are served from the block cache.
+	prefetch := prefetcher.New(log, retrieve,
+		prefetcher.WithPoll(f.Prefetch),
+	)
+
+	// The history follower indexes the transactions of new blocks into the
+	// history store, so that the account transactions endpoint can serve them.
+	var follow *history.Follower
+	if accounts != nil {
+		follow = history.NewFollower(log, accounts, retrieve,
+			history.WithStart(f.HistoryStart),
+			history.WithPoll(f.HistoryPoll),
+		)
+	}
+
+	submit := submitter.New(accessAPI,
+		submitter.WithDeduplicationWindow(f.Dedup),
+	)
+	registry, err := allowlist(f)
+	if err != nil {
+		log.Error().Str("templates", f.Templates).Strs("template_hashes", f.Trusted).Err(err).Msg("could not load transaction templates")
+		return failure
+	}
+	transact := transactor.New(validate, generate, invoke, submit,
+		transactor.WithTemplates(registry),
+		transactor.WithIntents(known),
+		transactor.WithGasLimit(f.GasLimit),
+	)
+	constructCtrl := rosetta.NewConstruction(config, transact, retrieve, validate)
+
+	// Sporks configured as their own network, such as the next spork during a
+	// blue/green cutover, are served by their own stack behind the same URL,
+	// and requests are dispatched to it by the sub-network that they name.
+	configs := []rosetta.Configuration{config}
+	for _, additional := range networks {
+		backend, err := build(f, additional, operations, registry)
+		if err != nil {
+			log.Error().Str("network", additional.name).Err(err).Msg("could not initialize network")
+			return failure
+		}
+		defer backend.close()
+		dataCtrl.AddNetwork(backend.config, backend.retrieve, backend.validate)
+		constructCtrl.AddNetwork(backend.config, backend.transact, backend.retrieve, backend.validate)
+		configs = append(configs, backend.config)
+	}
+
+	server := echo.New()
+	server.HideBanner = true
+	server.HidePort = true
+	server.Logger = elog
+
+	logger := lecho.Middleware(lecho.Config{Logger: elog})
+
+	if f.Dump {
+		logger = middleware.BodyDump(func(c echo.Context, request []byte, response []byte) {
+			fmt.Printf("<<<< %s %s\n%s>>>> %d\n%s\n",
+				c.Request().Method,
+				c.Request().RequestURI,
+				string(request),
+				c.Response().Status,
+				string(response),
+			)
+		})
+	}
+
+	server.Use(logger)
+
+	// Disabled endpoints are rejected before any of the other middleware, so
+	// that their requests take neither capacity nor shared executions.
+	if len(f.Disabled) > 0 {
+		server.Use(rosetta.Disable(f.Disabled))
+	}
+
+	// If self-check mode is enabled, every response is checked against the
+	// Rosetta specification, so that we notice drift before clients do.
+	if f.Check {
+		check, err := rosetta.SelfCheck(log, configs...)
+		if err != nil {
+			log.Error().Err(err).Msg("could not initialize self-check")
+			return failure
+		}
+		server.Use(check)
+	}
+
+	// When the service is overloaded, new requests are rejected right away with
+	// a retriable error, so that the requests in flight can still complete.
+	shedOptions := []func(*shedder.Config){
+		shedder.WithMaxInflight(f.ShedInflight),
+		shedder.WithMaxLatency(f.ShedLatency),
+	}
+	if f.ShedQueue > 0 {
+		shedOptions = append(shedOptions, shedder.WithQueue(executions, f.ShedQueue))
+	}
+	if f.ShedInflight > 0 || f.ShedLatency > 0 || f.ShedQueue > 0 {
+		server.Use(rosetta.Shed(shedder.New(shedOptions...)))
+	}
+
+	// If request collapsing is enabled, concurrent identical requests share a
+	// single execution, so that a burst of clients asking for the same new
+	// block only converts it once.
+	if f.Collapse {
+		server.Use(rosetta.Collapse())
+	}
+
+	// This group contains all of the Rosetta Data API endpoints.
+	server.POST("/network/list", dataCtrl.Networks)
+	server.POST("/network/options", dataCtrl.Options)
+	server.POST("/network/status", dataCtrl.Status)
+	server.POST("/account/balance", dataCtrl.Balance)
+	server.POST("/block", dataCtrl.Block)
+	server.POST("/block/transaction", dataCtrl.Transaction)
+	server.POST("/search/transactions", dataCtrl.SearchTransactions)
+
+	// This group contains extensions to the Rosetta Data API, which are not
+	// part of the specification.
+	server.POST("/flow/node", dataCtrl.Node)
+	server.POST("/flow/blocks", dataCtrl.Blocks)
+	server.POST("/flow/child", dataCtrl.Child)
+	server.POST("/flow/transaction", dataCtrl.Lookup)
+	server.POST("/flow/stream", dataCtrl.Stream)
+	server.POST("/flow/rewards", dataCtrl.Rewards)
+	if accounts != nil {
+		server.POST("/flow/account/transactions", dataCtrl.AccountTransactions)
+	}
+
+	// This endpoint exposes the metrics of the service, such as the drift
+	// detected by the reconciliation worker.
+	server.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+
+	// This endpoint tells load balancers whether the index is recent enough to
+	// be served, so that stale replicas are taken out of rotation.
+	monitor := lag.New(index, track,
+		lag.WithMaxBlocks(f.ReadyBlocks),
+		lag.WithMaxDelay(f.ReadyDelay),
+	)
+	if degraded != nil {
+		monitor.Degrade(degraded)
+	}
+	server.GET("/health/ready", rosetta.Ready(monitor))
+
+	// The lag sampler exposes the indexed and sealed heights as metrics, so
+	// that operators can alert on the sync health of the index. It also
+	// notifies breaches of the readiness thresholds to the alert webhook.
+	lagOptions := []func(*lag.Config){
+		lag.WithPoll(f.LagPoll),
+	}
+	if notify != nil {
+		lagOptions = append(lagOptions, lag.WithNotifier(notify))
+	}
+	sampler := lag.NewSampler(log, monitor, lag.NewMetrics(prometheus.DefaultRegisterer), lagOptions...)
+
+	// This group contains all of the Rosetta Construction API endpoints.
+	server.POST("/construction/preprocess", constructCtrl.Preprocess)
+	server.POST("/construction/metadata", constructCtrl.Metadata)
+	server.POST("/construction/payloads", constructCtrl.Payloads)
+	server.POST("/construction/parse", constructCtrl.Parse)
+	server.POST("/construction/combine", constructCtrl.Combine)
+	server.POST("/construction/hash", constructCtrl.Hash)
+	server.POST("/construction/submit", constructCtrl.Submit)
+
+	// This group contains extensions to the Rosetta Construction API, which
+	// are not part of the specification.
+	server.POST("/construction/preview", constructCtrl.Preview)
+
+	// Each disabled endpoint has to match at least one of the routes, so that
+	// a typo does not leave an endpoint enabled without anyone noticing.
+	err = routed(server.Routes(), f.Disabled)
+	if err != nil {
+		log.Error().Strs("disabled_endpoints", f.Disabled).Err(err).Msg("could not check disabled endpoints")
+		return failure
+	}
+
+	// The GRPC mirror of the Data API uses the same retriever and validator
+	// as the HTTP API, so both always return the same data. Its methods are
+	// disabled along with the endpoints they mirror.
+	gsvr := grpc.NewServer(grpc.UnaryInterceptor(rpc.Disable(f.Disabled)))
+	rpc.RegisterAPIServer(gsvr, rpc.NewServer(retrieve, validate))
+	var listener net.Listener
+	if f.RPC != 0 {
+		listener, err = net.Listen("tcp", fmt.Sprint(":", f.RPC))
+		if err != nil {
+			log.Error().Uint16("port", f.RPC).Err(err).Msg("could not listen on GRPC port")
+			return failure
+		}
+	}
+
+	// This section launches the main executing components in their own
+	// goroutine, so they can run concurrently. Afterwards, we wait for an
+	// interrupt signal in order to proceed with the next section.
+	done := make(chan struct{})
+	failed := make(chan struct{})
+	go func() {
+		log.Info().Msg("Flow Rosetta Server starting")
+		err := server.Start(fmt.Sprint(":", f.Port))
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Warn().Err(err).Msg("Flow Rosetta Server failed")
+			close(failed)
+		} else {
+			close(done)
+		}
+		log.Info().Msg("Flow Rosetta Server stopped")
+	}()
+	reconcileCtx, reconcileCancel := context.WithCancel(context.Background())
+	defer reconcileCancel()
+	if f.Sample != 0 {
+		go func() {
+			log.Info().Msg("Flow Rosetta Reconciler starting")
+			err := worker.Run(reconcileCtx)
+			if err != nil {
+				log.Warn().Err(err).Msg("Flow Rosetta Reconciler failed")
+			}
+			log.Info().Msg("Flow Rosetta Reconciler stopped")
+		}()
+	}
+	if f.Prefetch != 0 && blocks != nil {
+		go func() {
+			log.Info().Msg("Flow Rosetta Prefetcher starting")
+			err := prefetch.Run(reconcileCtx)
+			if err != nil {
+				log.Warn().Err(err).Msg("Flow Rosetta Prefetcher failed")
+			}
+			log.Info().Msg("Flow Rosetta Prefetcher stopped")
+		}()
+	}
+	if follow != nil {
+		go func() {
+			log.Info().Msg("Flow Rosetta History Follower starting")
+			err := follow.Run(reconcileCtx)
+			if err != nil {
+				log.Warn().Err(err).Msg("Flow Rosetta History Follower failed")
+			}
+			log.Info().Msg("Flow Rosetta History Follower stopped")
+		}()
+	}
+	if f.LagPoll != 0 {
+		go func() {
+			log.Info().Msg("Flow Rosetta Lag Sampler starting")
+			err := sampler.Run(reconcileCtx)
+			if err != nil {
+				log.Warn().Err(err).Msg("Flow Rosetta Lag Sampler failed")
+			}
+			log.Info().Msg("Flow Rosetta Lag Sampler stopped")
+		}()
+	}
+	if listener != nil {
+		go func() {
+			log.Info().Msg("Flow Rosetta GRPC Server starting")
+			err := gsvr.Serve(listener)
+			if err != nil {
+				log.Warn().Err(err).Msg("Flow Rosetta GRPC Server failed")
+			}
+			log.Info().Msg("Flow Rosetta GRPC Server stopped")
+		}()
+	}
+
+	select {
+	case <-sig:
+		log.Info().Msg("Flow Rosetta Server stopping")
+	case <-done:
+		log.Info().Msg("Flow Rosetta Server done")
+	case <-failed:
+		log.Warn().Msg("Flow Rosetta Server aborted")
+		return failure
+	}
+	go func() {
+		<-sig
+		log.Warn().Msg("forcing exit")
+		os.Exit(1)
+	}()
+
+	// The following code starts a shut down with a certain timeout and makes
+	// sure that the main executing components are shutting down within the
+	// allocated shutdown time. Otherwise, we will force the shutdown and log
+	// an error. We then wait for shutdown on each component to complete.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	reconcileCancel()
+	gsvr.GracefulStop()
+	err = server.Shutdown(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("could not shut down Rosetta API")
+		return failure
+	}
+
+	return success
+}
@@ -0,0 +1,469 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/onflow/flow-go-sdk/client"
+
+	api "github.com/optakt/flow-dps/api/dps"
+	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/index"
+	"github.com/optakt/flow-dps/service/storage"
+	"github.com/optakt/flow-rosetta/api/rosetta"
+	"github.com/optakt/flow-rosetta/rosetta/bootstrap"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/intents"
+	"github.com/optakt/flow-rosetta/rosetta/limiter"
+	"github.com/optakt/flow-rosetta/rosetta/replica"
+	"github.com/optakt/flow-rosetta/rosetta/retriever"
+	"github.com/optakt/flow-rosetta/rosetta/scripts"
+	"github.com/optakt/flow-rosetta/rosetta/sporks"
+	"github.com/optakt/flow-rosetta/rosetta/templates"
+)
+
+// Names of the in-process caches that share the memory budget.
+const (
+	cacheRegisters = "registers"
+	cacheScripts   = "scripts"
+	cacheBlocks    = "blocks"
+)
+
+// logger returns a logger writing to standard error at the given log level. If the
+// level is invalid, the logger is still usable to report the error.
+func logger(level string) (zerolog.Logger, error) {
+	zerolog.TimestampFunc = func() time.Time { return time.Now().UTC() }
+	log := zerolog.New(os.Stderr).With().Timestamp().Logger().Level(zerolog.DebugLevel)
+	lvl, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return log, err
+	}
+	return log.Level(lvl), nil
+}
+
+// connect initializes the DPS API client and wraps it for easy usage. If sporks are
+// configured, it initializes one client per spork instead, and routes each read to
+// the spork it belongs to. Sporks, or height ranges of a spork, can also be served
+// from a local index database, which is opened read-only, so that old heights can be
+// kept on cheap storage. The Access API of the most recent spork then replaces the
+// configured one, as it is used for tracking and submitting transactions. In replica
+// mode, it serves from a private copy of the index loaded from its snapshots instead.
+// Sporks with a network name are returned as additional networks, each with its own
+// spork registry and the Access API of its most recent spork. The returned function
+// closes all connections and databases.
+func connect(log zerolog.Logger, f *flags) (dps.Reader, []network, func(), error) {
+
+	codec := zbor.NewCodec()
+
+	// In replica mode, the index is loaded from the snapshots exported from the
+	// stopped indexer, and reloaded whenever a newer one is published.
+	if f.Replica != "" {
+		if f.Sporks != "" {
+			return nil, nil, nil, fmt.Errorf("replica snapshot and sporks are mutually exclusive")
+		}
+		mirror, err := replica.New(log, f.Replica, f.ReplicaDir, storage.New(codec),
+			replica.WithRefresh(f.ReplicaPoll),
+		)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("could not initialize index replica (snapshot: %s): %w", f.Replica, err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			_ = mirror.Run(ctx)
+			close(done)
+		}()
+		stop := func() {
+			cancel()
+			<-done
+			_ = mirror.Close()
+		}
+		return mirror, nil, stop, nil
+	}
+
+	// The DPS API usually runs next to the index, on another machine, so its
+	// connections can be secured with TLS.
+	creds, err := transport(f)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not load DPS API credentials: %w", err)
+	}
+
+	if f.Sporks == "" {
+		conn, err := grpc.Dial(f.DPS, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("could not dial API host (api: %s): %w", f.DPS, err)
+		}
+		dpsAPI := api.NewAPIClient(conn)
+		index := api.IndexFromAPI(dpsAPI, codec)
+		return index, nil, func() { _ = conn.Close() }, nil
+	}
+
+	list, err := sporks.Load(f.Sporks)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not load spork configuration: %w", err)
+	}
+	closers := make([]func() error, 0, len(list))
+	close := func() {
+		for _, closer := range closers {
+			_ = closer()
+		}
+	}
+	for i, spork := range list {
+		switch {
+
+		case spork.IndexDir != "" && spork.DPSAPI != "":
+			close()
+			return nil, nil, nil, fmt.Errorf("spork has both an index directory and a DPS API (spork: %s)", spork.Name)
+
+		case spork.IndexDir != "":
+			db, err := badger.Open(dps.DefaultOptions(spork.IndexDir).WithReadOnly(true))
+			if err != nil {
+				close()
+				return nil, nil, nil, fmt.Errorf("could not open index database (spork: %s, index: %s): %w", spork.Name, spork.IndexDir, err)
+			}
+			closers = append(closers, db.Close)
+			list[i].Index = index.NewReader(db, storage.New(codec))
+
+		default:
+			conn, err := grpc.Dial(spork.DPSAPI, grpc.WithTransportCredentials(creds))
+			if err != nil {
+				close()
+				return nil, nil, nil, fmt.Errorf("could not dial API host (spork: %s, api: %s): %w", spork.Name, spork.DPSAPI, err)
+			}
+			closers = append(closers, conn.Close)
+			dpsAPI := api.NewAPIClient(conn)
+			list[i].Index = api.IndexFromAPI(dpsAPI, codec)
+		}
+	}
+
+	// Sporks with a network name are grouped by network, in the order in which
+	// their networks first appear, while the others make up the history of the
+	// default network.
+	var current []sporks.Spork
+	var names []string
+	grouped := make(map[string][]sporks.Spork)
+	for _, spork := range list {
+		if spork.Network == "" {
+			current = append(current, spork)
+			continue
+		}
+		_, ok := grouped[spork.Network]
+		if !ok {
+			names = append(names, spork.Network)
+		}
+		grouped[spork.Network] = append(grouped[spork.Network], spork)
+	}
+
+	registry, err := sporks.New(current...)
+	if err != nil {
+		close()
+		return nil, nil, nil, fmt.Errorf("could not initialize spork registry: %w", err)
+	}
+	latest := registry.Latest()
+	if latest.AccessAPI != "" {
+		f.Access = latest.AccessAPI
+	}
+	log.Info().Int("sporks", len(current)).Str("latest", latest.Name).Msg("spork registry initialized")
+
+	networks := make([]network, 0, len(names))
+	for _, name := range names {
+		other, err := sporks.New(grouped[name]...)
+		if err != nil {
+			close()
+			return nil, nil, nil, fmt.Errorf("could not initialize spork registry (network: %s): %w", name, err)
+		}
+		latest := other.Latest()
+		if latest.AccessAPI == "" {
+			close()
+			return nil, nil, nil, fmt.Errorf("access API of network is missing (network: %s)", name)
+		}
+		additional := network{
+			name:   name,
+			index:  other,
+			access: latest.AccessAPI,
+		}
+		networks = append(networks, additional)
+		log.Info().Str("network", name).Int("sporks", len(grouped[name])).Str("latest", latest.Name).Msg("spork registry initialized")
+	}
+
+	return registry, networks, close, nil
+}
+
+// provision downloads the replica snapshot from object storage if it is missing,
+// so that a new replica can be provisioned from its configuration alone. Once the
+// snapshot exists, newer ones are published in its place by the indexer.
+func provision(log zerolog.Logger, f *flags) error {
+
+	if f.Bootstrap == "" {
+		return nil
+	}
+	if f.Replica == "" {
+		return fmt.Errorf("bootstrap requires a replica snapshot path")
+	}
+
+	_, err := os.Stat(f.Replica)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not check replica snapshot: %w", err)
+	}
+
+	log.Info().Str("url", f.Bootstrap).Str("snapshot", f.Replica).Msg("downloading index snapshot")
+	err = bootstrap.New(log).Download(context.Background(), f.Bootstrap, f.Replica, f.Checksum)
+	if err != nil {
+		return fmt.Errorf("could not download index snapshot: %w", err)
+	}
+	log.Info().Str("snapshot", f.Replica).Msg("index snapshot downloaded")
+
+	return nil
+}
+
+// transport returns the transport credentials for the connections to the DPS API,
+// which are only encrypted if TLS is enabled or a CA certificate is given.
+func transport(f *flags) (credentials.TransportCredentials, error) {
+	switch {
+	case f.DPSCA != "":
+		return credentials.NewClientTLSFromFile(f.DPSCA, "")
+	case f.DPSTLS:
+		return credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12}), nil
+	default:
+		return insecure.NewCredentials(), nil
+	}
+}
+
+// chain deduces the chain ID from the root block of the index, and returns the
+// parameters of that chain.
+func chain(index dps.Reader) (dps.Params, error) {
+
+	first, err := index.First()
+	if err != nil {
+		return dps.Params{}, fmt.Errorf("could not get first height from DPS API: %w", err)
+	}
+	root, err := index.Header(first)
+	if err != nil {
+		return dps.Params{}, fmt.Errorf("could not get root header from DPS API (first: %d): %w", first, err)
+	}
+	params, ok := dps.FlowParams[root.ChainID]
+	if !ok {
+		return dps.Params{}, fmt.Errorf("invalid chain ID for params (chain: %s)", root.ChainID)
+	}
+
+	return params, nil
+}
+
+// dial initializes an SDK client for the Flow Access API at the given address,
+// with at most the given amount of requests in flight.
+func dial(address string, inflight uint) (*client.Client, error) {
+	return client.New(address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(limiter.Interceptor(inflight)),
+	)
+}
+
+// split checks the relative weights of the in-process caches.
+func split(weights map[string]int) (map[string]uint, error) {
+
+	shares := make(map[string]uint, len(weights))
+	for name, weight := range weights {
+		switch name {
+		case cacheRegisters, cacheScripts, cacheBlocks:
+		default:
+			return nil, fmt.Errorf("unknown cache in cache weights (cache: %s)", name)
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("negative cache weight (cache: %s, weight: %d)", name, weight)
+		}
+		shares[name] = uint(weight)
+	}
+
+	return shares, nil
+}
+
+// operationTypes checks the allowlisted operation types, which are case-insensitive.
+// Transfers are needed by the Construction API, so they cannot be left out.
+func operationTypes(types []string) ([]string, error) {
+
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	// Template operations are not converted from events, but failed staking
+	// transactions get them as their intended operations.
+	known := make(map[string]struct{}, len(configuration.DataOperations)+1)
+	for _, typ := range configuration.DataOperations {
+		known[typ] = struct{}{}
+	}
+	known[configuration.OperationTemplate] = struct{}{}
+	var transfer bool
+	allowed := make([]string, 0, len(types))
+	for _, typ := range types {
+		typ = strings.ToUpper(typ)
+		_, ok := known[typ]
+		if !ok {
+			return nil, fmt.Errorf("unknown operation type (type: %s)", typ)
+		}
+		if typ == configuration.OperationTransfer {
+			transfer = true
+		}
+		allowed = append(allowed, typ)
+	}
+	if !transfer {
+		return nil, fmt.Errorf("missing required operation type (type: %s)", configuration.OperationTransfer)
+	}
+
+	return allowed, nil
+}
+
+// routed checks that each of the disabled endpoints matches at least one route.
+func routed(routes []*echo.Route, endpoints []string) error {
+	for _, endpoint := range endpoints {
+		var found bool
+		for _, route := range routes {
+			if rosetta.Disabled([]string{endpoint}, route.Path) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown endpoint (endpoint: %s)", endpoint)
+		}
+	}
+	return nil
+}
+
+// precompile generates the scripts that depend on the configuration once, so that
+// invalid balance paths or unsupported chains are caught on startup rather than on
+// every balance request.
+func precompile(generate *scripts.Generator, f *flags) error {
+
+	if len(f.Vaults) > 0 {
+		_, err := generate.GetVaultBalances(dps.FlowSymbol, f.Vaults)
+		if err != nil {
+			return fmt.Errorf("could not generate vault balances script: %w", err)
+		}
+	}
+	if f.Storage {
+		_, err := generate.GetStorageInfo()
+		if err != nil {
+			return fmt.Errorf("could not generate storage info script: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrate loads the configured core contract migrations, if any, and returns
+// the script generators for the historical heights before each of them.
+func migrate(params dps.Params, path string) ([]retriever.Migration, []*scripts.Generator, error) {
+
+	if path == "" {
+		return nil, nil, nil
+	}
+
+	list, err := scripts.LoadMigrations(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load contract migrations: %w", err)
+	}
+	var migrations []retriever.Migration
+	var legacy []*scripts.Generator
+	for _, migration := range list {
+		historical, err := migration.Params(params)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not apply contract migration (height: %d): %w", migration.Height, err)
+		}
+		gen := scripts.NewGenerator(historical)
+		migrations = append(migrations, retriever.Migration{Height: migration.Height, Generate: gen})
+		legacy = append(legacy, gen)
+	}
+
+	return migrations, legacy, nil
+}
+
+// recognize creates the registry of known transaction scripts, including the
+// variants of the scripts for the historical heights of contract migrations, so
+// that transactions are classified the same way before and after a migration.
+func recognize(params dps.Params, generate *scripts.Generator, legacy []*scripts.Generator) (*intents.Registry, error) {
+
+	generators := make([]intents.Generator, 0, len(legacy)+1)
+	generators = append(generators, generate)
+	for _, gen := range legacy {
+		generators = append(generators, gen)
+	}
+
+	return intents.New(generators,
+		intents.WithFUSD(scripts.HasFUSD(params.ChainID)),
+	)
+}
+
+// fingerprint returns a hash of the configuration that converted blocks depend
+// on, so that the block store drops its blocks when that configuration changes.
+func fingerprint(params dps.Params, f *flags, operations []string) ([]byte, error) {
+
+	types := make([]string, len(operations))
+	copy(types, operations)
+	sort.Strings(types)
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "chain=%s\n", params.ChainID)
+	_, _ = fmt.Fprintf(h, "transaction-limit=%d\n", f.Transactions)
+	_, _ = fmt.Fprintf(h, "operation-types=%s\n", strings.Join(types, ","))
+	if f.Migrations != "" {
+		data, err := os.ReadFile(f.Migrations)
+		if err != nil {
+			return nil, fmt.Errorf("could not read contract migrations: %w", err)
+		}
+		_, _ = h.Write(data)
+	}
+
+	return h.Sum(nil), nil
+}
+
+// allowlist loads the configured transaction templates, if any, and checks them
+// against the trusted script hashes.
+func allowlist(f *flags) (*templates.Registry, error) {
+
+	if f.Templates == "" {
+		return &templates.Registry{}, nil
+	}
+
+	registry, err := templates.FromFile(f.Templates)
+	if err != nil {
+		return nil, fmt.Errorf("could not load transaction templates: %w", err)
+	}
+	err = registry.Verify(f.Trusted...)
+	if err != nil {
+		return nil, fmt.Errorf("could not verify transaction templates: %w", err)
+	}
+
+	return registry, nil
+}
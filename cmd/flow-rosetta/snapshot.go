@@ -0,0 +1,171 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/snapshot"
+)
+
+// exportSnapshot writes a compressed snapshot of the Badger database in the given
+// directory to the file at the given path. The database is opened read-only, but
+// Badger still refuses to open a database that another process holds open, so the
+// indexer writing to it has to be stopped for the export.
+func exportSnapshot(level string, dir string, path string) int {
+
+	log, err := logger(level)
+	if err != nil {
+		log.Error().Str("level", level).Err(err).Msg("could not parse log level")
+		return failure
+	}
+
+	db, err := badger.Open(dps.DefaultOptions(dir).WithReadOnly(true))
+	if err != nil {
+		log.Error().Str("index", dir).Err(err).Msg("could not open index database read-only, make sure that its indexer is stopped")
+		return failure
+	}
+	defer db.Close()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		log.Error().Str("output", path).Err(err).Msg("could not create snapshot file")
+		return failure
+	}
+
+	// A partial snapshot cannot be told apart from a complete one when it is
+	// imported, so we remove the file if anything goes wrong.
+	version, err := snapshot.Export(db, file)
+	if err == nil {
+		err = file.Close()
+	} else {
+		_ = file.Close()
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		log.Error().Str("output", path).Err(err).Msg("could not export snapshot")
+		return failure
+	}
+
+	log.Info().Str("index", dir).Str("output", path).Uint64("version", version).Msg("snapshot exported")
+
+	return success
+}
+
+// importSnapshot bootstraps a new Badger database in the given directory from the
+// snapshot file at the given path. The directory has to be empty or missing, so
+// that an existing index is never mixed with the snapshot. If the import fails,
+// the partially loaded database is removed, so that it is never mistaken for a
+// complete index.
+func importSnapshot(level string, path string, dir string) int {
+
+	log, err := logger(level)
+	if err != nil {
+		log.Error().Str("level", level).Err(err).Msg("could not parse log level")
+		return failure
+	}
+
+	_, err = os.Stat(dir)
+	existed := err == nil
+
+	empty, err := isEmpty(dir)
+	if err != nil {
+		log.Error().Str("index", dir).Err(err).Msg("could not check index directory")
+		return failure
+	}
+	if !empty {
+		log.Error().Str("index", dir).Msg("index directory is not empty")
+		return failure
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Error().Str("input", path).Err(err).Msg("could not open snapshot file")
+		return failure
+	}
+	defer file.Close()
+
+	db, err := badger.Open(dps.DefaultOptions(dir))
+	if err != nil {
+		log.Error().Str("index", dir).Err(err).Msg("could not open index database")
+		return failure
+	}
+
+	err = snapshot.Import(db, file)
+	if err != nil {
+		_ = db.Close()
+		log.Error().Str("input", path).Err(err).Msg("could not import snapshot")
+		err = wipe(dir, existed)
+		if err != nil {
+			log.Error().Str("index", dir).Err(err).Msg("could not remove partially imported index")
+		}
+		return failure
+	}
+
+	err = db.Close()
+	if err != nil {
+		log.Error().Str("index", dir).Err(err).Msg("could not close index database")
+		return failure
+	}
+
+	log.Info().Str("input", path).Str("index", dir).Msg("snapshot imported")
+
+	return success
+}
+
+// wipe removes everything within the directory at the given path, and the
+// directory itself unless it existed before.
+func wipe(dir string, existed bool) error {
+
+	if !existed {
+		return os.RemoveAll(dir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		err = os.RemoveAll(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isEmpty returns whether the directory at the given path is empty or missing.
+func isEmpty(dir string) (bool, error) {
+	f, err := os.Open(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	_, err = f.Readdirnames(1)
+	if errors.Is(err, io.EOF) {
+		return true, nil
+	}
+	return false, err
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/templates"
+)
+
+const (
+	formatCSV  = "csv"
+	formatJSON = "json"
+)
+
+// statement writes all operations affecting the account with the given address
+// between the given start and end heights to the file at the given path, or to
+// standard output if no path is given. The retriever is built from the same flags
+// as the one of the server, so that the statement has the same operations as the
+// Data API, including those of heights before a contract migration.
+func statement(f *flags, address string, start uint64, end uint64, format string, path string) int {
+
+	log, err := logger(f.Level)
+	if err != nil {
+		log.Error().Str("level", f.Level).Err(err).Msg("could not parse log level")
+		return failure
+	}
+
+	// Check the statement parameters before connecting to anything.
+	if address == "" {
+		log.Error().Msg("account address is missing")
+		return failure
+	}
+	if format != formatCSV && format != formatJSON {
+		log.Error().Str("format", format).Msg("invalid output format")
+		return failure
+	}
+	operations, err := operationTypes(f.Operations)
+	if err != nil {
+		log.Error().Strs("operation_types", f.Operations).Err(err).Msg("could not check operation types")
+		return failure
+	}
+
+	// Additional networks are ignored, as statements are always exported for
+	// the default network.
+	index, _, disconnect, err := connect(log, f)
+	if err != nil {
+		log.Error().Err(err).Msg("could not connect to DPS API")
+		return failure
+	}
+	defer disconnect()
+
+	if f.Access == "" {
+		log.Error().Msg("Flow Access API endpoint is missing")
+		return failure
+	}
+
+	// The statement only needs the retriever, so the transactor of the stack is
+	// built without transaction templates.
+	current := network{
+		index:  index,
+		access: f.Access,
+	}
+	backend, err := build(f, current, operations, &templates.Registry{})
+	if err != nil {
+		log.Error().Err(err).Msg("could not initialize retriever")
+		return failure
+	}
+	defer backend.close()
+
+	// Heights which were not specified default to the full indexed range.
+	if start == 0 {
+		start, err = index.First()
+		if err != nil {
+			log.Error().Err(err).Msg("could not get first height from DPS API")
+			return failure
+		}
+	}
+	if end == 0 {
+		end, err = index.Last()
+		if err != nil {
+			log.Error().Err(err).Msg("could not get last height from DPS API")
+			return failure
+		}
+	}
+
+	rosAccountID := identifier.Account{Address: strings.TrimPrefix(address, "0x")}
+	rosStart := identifier.Block{Index: &start}
+	rosEnd := identifier.Block{Index: &end}
+	entries, err := backend.retrieve.Statement(rosAccountID, rosStart, rosEnd)
+	if err != nil {
+		log.Error().Str("address", address).Uint64("start", start).Uint64("end", end).Err(err).Msg("could not retrieve statement")
+		return failure
+	}
+
+	output := os.Stdout
+	if path != "" {
+		output, err = os.Create(path)
+		if err != nil {
+			log.Error().Str("output", path).Err(err).Msg("could not create output file")
+			return failure
+		}
+		defer output.Close()
+	}
+
+	switch format {
+	case formatJSON:
+		err = writeJSON(output, entries)
+	default:
+		err = writeCSV(output, entries)
+	}
+	if err != nil {
+		log.Error().Str("format", format).Err(err).Msg("could not write statement")
+		return failure
+	}
+
+	log.Info().Str("address", address).Uint64("start", start).Uint64("end", end).Int("entries", len(entries)).Msg("statement exported")
+
+	return success
+}
+
+func writeJSON(w io.Writer, entries []object.StatementEntry) error {
+
+	// We always write an array, so that an empty statement is still valid JSON
+	// that can be parsed as a list of entries.
+	if entries == nil {
+		entries = []object.StatementEntry{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(entries)
+	if err != nil {
+		return fmt.Errorf("could not encode entries: %w", err)
+	}
+
+	return nil
+}
+
+func writeCSV(w io.Writer, entries []object.StatementEntry) error {
+
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{
+		"block_index",
+		"block_hash",
+		"timestamp",
+		"transaction_hash",
+		"operation_index",
+		"type",
+		"status",
+		"address",
+		"value",
+		"symbol",
+		"decimals",
+	})
+	if err != nil {
+		return fmt.Errorf("could not write header: %w", err)
+	}
+
+	for _, entry := range entries {
+		var index string
+		if entry.BlockID.Index != nil {
+			index = strconv.FormatUint(*entry.BlockID.Index, 10)
+		}
+		op := entry.Operation
+		err = writer.Write([]string{
+			index,
+			entry.BlockID.Hash,
+			strconv.FormatInt(entry.Timestamp, 10),
+			entry.TransactionID.Hash,
+			strconv.FormatUint(uint64(op.ID.Index), 10),
+			op.Type,
+			op.Status,
+			op.AccountID.Address,
+			op.Amount.Value,
+			op.Amount.Currency.Symbol,
+			strconv.FormatUint(uint64(op.Amount.Currency.Decimals), 10),
+		})
+		if err != nil {
+			return fmt.Errorf("could not write entry: %w", err)
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return fmt.Errorf("could not flush entries: %w", err)
+	}
+
+	return nil
+}
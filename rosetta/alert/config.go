@@ -0,0 +1,64 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package alert
+
+import (
+	"time"
+)
+
+// Supported payload formats of the webhook.
+const (
+	FormatSlack     = "slack"
+	FormatPagerDuty = "pagerduty"
+)
+
+// Config contains the configuration options for the webhook.
+type Config struct {
+	Format     string
+	RoutingKey string
+	Source     string
+	Cooldown   time.Duration
+}
+
+// WithFormat sets the payload format of the webhook, which is either compatible
+// with Slack incoming webhooks or with the PagerDuty Events API v2.
+func WithFormat(format string) func(*Config) {
+	return func(c *Config) {
+		c.Format = format
+	}
+}
+
+// WithRoutingKey sets the integration key of the PagerDuty service that events
+// are routed to. It is only used with the PagerDuty format.
+func WithRoutingKey(key string) func(*Config) {
+	return func(c *Config) {
+		c.RoutingKey = key
+	}
+}
+
+// WithSource sets the name of the instance that events are reported from.
+func WithSource(source string) func(*Config) {
+	return func(c *Config) {
+		c.Source = source
+	}
+}
+
+// WithCooldown sets the duration during which repeated notifications of the
+// same ongoing anomaly are suppressed. Zero disables the suppression.
+func WithCooldown(cooldown time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.Cooldown = cooldown
+	}
+}
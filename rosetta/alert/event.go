@@ -0,0 +1,45 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package alert
+
+// Severity levels of events, which match the severities of PagerDuty events.
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// Event is an operational anomaly that operators should be notified of.
+type Event struct {
+	// Kind identifies the type of anomaly, such as `index_lag`.
+	Kind string
+	// Key identifies the affected resource within the kind, if any, so that
+	// anomalies for different resources are not deduplicated together.
+	Key      string
+	Severity string
+	Summary  string
+	Details  map[string]string
+	// Resolved is set when an anomaly that was reported before has ended.
+	Resolved bool
+}
+
+// dedup returns the key used to deduplicate repeated notifications of an event.
+func (e Event) dedup() string {
+	if e.Key == "" {
+		return e.Kind
+	}
+	return e.Kind + "/" + e.Key
+}
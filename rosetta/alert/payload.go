@@ -0,0 +1,95 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// slack is the payload of a Slack incoming webhook.
+// See https://api.slack.com/messaging/webhooks
+type slack struct {
+	Text string `json:"text"`
+}
+
+// pagerDuty is the payload of an event of the PagerDuty Events API v2.
+// See https://developer.pagerduty.com/docs/events-api-v2/trigger-events/
+type pagerDuty struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+func slackMessage(source string, event Event) slack {
+
+	status := strings.ToUpper(event.Severity)
+	if event.Resolved {
+		status = "RESOLVED"
+	}
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "[%s] %s: %s", status, source, event.Summary)
+
+	// Map iteration order is random, so details are sorted to keep messages
+	// stable and readable.
+	keys := make([]string, 0, len(event.Details))
+	for key := range event.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(&b, "\n• %s: %s", key, event.Details[key])
+	}
+
+	return slack{Text: b.String()}
+}
+
+func pagerDutyEvent(routingKey string, source string, event Event) pagerDuty {
+
+	// Resolve events only need the deduplication key of the incident.
+	if event.Resolved {
+		return pagerDuty{
+			RoutingKey:  routingKey,
+			EventAction: "resolve",
+			DedupKey:    source + "/" + event.dedup(),
+		}
+	}
+
+	p := pagerDuty{
+		RoutingKey:  routingKey,
+		EventAction: "trigger",
+		DedupKey:    source + "/" + event.dedup(),
+		Payload: &pagerDutyPayload{
+			Summary:       event.Summary,
+			Source:        source,
+			Severity:      event.Severity,
+			Component:     event.Kind,
+			CustomDetails: event.Details,
+		},
+	}
+
+	return p
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Webhook posts operational events as JSON to an HTTP endpoint, such as a Slack
+// incoming webhook or the PagerDuty Events API, so that small operators can be
+// alerted without running a full monitoring stack.
+type Webhook struct {
+	cfg    Config
+	url    string
+	client *http.Client
+	now    func() time.Time
+
+	// sent holds the time at which each ongoing anomaly was last notified,
+	// by deduplication key.
+	mutex *sync.Mutex
+	sent  map[string]time.Time
+}
+
+// NewWebhook creates a new webhook that posts events to the given URL.
+func NewWebhook(url string, options ...func(*Config)) (*Webhook, error) {
+
+	cfg := Config{
+		Format:     FormatSlack,
+		RoutingKey: "",
+		Source:     "flow-rosetta",
+		Cooldown:   10 * time.Minute,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	switch cfg.Format {
+	case FormatSlack:
+	case FormatPagerDuty:
+		if cfg.RoutingKey == "" {
+			return nil, fmt.Errorf("routing key is required for PagerDuty format")
+		}
+	default:
+		return nil, fmt.Errorf("unknown webhook format (format: %s)", cfg.Format)
+	}
+
+	w := Webhook{
+		cfg:    cfg,
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+		mutex:  &sync.Mutex{},
+		sent:   make(map[string]time.Time),
+	}
+
+	return &w, nil
+}
+
+// Notify posts the given event to the webhook, unless the same anomaly was
+// already notified within the cooldown. Resolutions are only posted for
+// anomalies that were notified before. Events are only recorded once the
+// webhook accepted them, so that an event that could not be delivered is
+// posted again on the next attempt.
+func (w *Webhook) Notify(event Event) error {
+
+	if !w.admit(event) {
+		return nil
+	}
+
+	var payload interface{}
+	switch w.cfg.Format {
+	case FormatPagerDuty:
+		payload = pagerDutyEvent(w.cfg.RoutingKey, w.cfg.Source, event)
+	default:
+		payload = slackMessage(w.cfg.Source, event)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not encode event: %w", err)
+	}
+
+	res, err := w.client.Post(w.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("could not post event: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected webhook response status (status: %d)", res.StatusCode)
+	}
+
+	w.record(event)
+
+	return nil
+}
+
+// admit decides whether the event should be posted.
+func (w *Webhook) admit(event Event) bool {
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	last, ongoing := w.sent[event.dedup()]
+
+	if event.Resolved {
+		return ongoing
+	}
+
+	return !ongoing || w.now().Sub(last) >= w.cfg.Cooldown
+}
+
+// record remembers that the event was posted, so that the anomaly is not
+// notified again within the cooldown, or forgets the anomaly once its
+// resolution was posted.
+func (w *Webhook) record(event Event) {
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	key := event.dedup()
+	if event.Resolved {
+		delete(w.sent, key)
+		return
+	}
+
+	w.sent[key] = w.now()
+}
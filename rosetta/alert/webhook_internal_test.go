@@ -0,0 +1,292 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package alert
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhook(t *testing.T) {
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		w, err := NewWebhook("http://localhost",
+			WithFormat(FormatPagerDuty),
+			WithRoutingKey("key"),
+			WithSource("source"),
+			WithCooldown(time.Minute),
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost", w.url)
+		assert.Equal(t, FormatPagerDuty, w.cfg.Format)
+		assert.Equal(t, "key", w.cfg.RoutingKey)
+		assert.Equal(t, "source", w.cfg.Source)
+		assert.Equal(t, time.Minute, w.cfg.Cooldown)
+	})
+
+	t.Run("handles missing routing key", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewWebhook("http://localhost", WithFormat(FormatPagerDuty))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles unknown format", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewWebhook("http://localhost", WithFormat("email"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestWebhook_Notify(t *testing.T) {
+
+	event := Event{
+		Kind:     "index_lag",
+		Severity: SeverityCritical,
+		Summary:  "index lags behind",
+		Details: map[string]string{
+			"lag_blocks":     "100",
+			"indexed_height": "42",
+		},
+	}
+
+	t.Run("posts slack message", func(t *testing.T) {
+		t.Parallel()
+
+		var got map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			err := json.NewDecoder(r.Body).Decode(&got)
+			assert.NoError(t, err)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		w := BaselineWebhook(t, server.URL)
+
+		err := w.Notify(event)
+
+		require.NoError(t, err)
+		want := map[string]interface{}{
+			"text": "[CRITICAL] test: index lags behind\n• indexed_height: 42\n• lag_blocks: 100",
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("posts pagerduty events", func(t *testing.T) {
+		t.Parallel()
+
+		var got []pagerDuty
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var p pagerDuty
+			err := json.NewDecoder(r.Body).Decode(&p)
+			assert.NoError(t, err)
+			got = append(got, p)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		w := BaselineWebhook(t, server.URL, WithPagerDuty("key"))
+
+		err := w.Notify(event)
+		require.NoError(t, err)
+
+		resolved := event
+		resolved.Resolved = true
+		err = w.Notify(resolved)
+		require.NoError(t, err)
+
+		want := []pagerDuty{
+			{
+				RoutingKey:  "key",
+				EventAction: "trigger",
+				DedupKey:    "test/index_lag",
+				Payload: &pagerDutyPayload{
+					Summary:       event.Summary,
+					Source:        "test",
+					Severity:      SeverityCritical,
+					Component:     "index_lag",
+					CustomDetails: event.Details,
+				},
+			},
+			{
+				RoutingKey:  "key",
+				EventAction: "resolve",
+				DedupKey:    "test/index_lag",
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("suppresses repeated events within cooldown", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		posted := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			posted++
+		}))
+		defer server.Close()
+
+		now := time.Now()
+		w := BaselineWebhook(t, server.URL, WithClock(func() time.Time { return now }))
+
+		err := w.Notify(event)
+		require.NoError(t, err)
+		err = w.Notify(event)
+		require.NoError(t, err)
+
+		other := event
+		other.Key = "other"
+		err = w.Notify(other)
+		require.NoError(t, err)
+
+		now = now.Add(time.Hour)
+		err = w.Notify(event)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, posted)
+	})
+
+	t.Run("skips resolution of events never notified", func(t *testing.T) {
+		t.Parallel()
+
+		posted := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			posted = true
+		}))
+		defer server.Close()
+
+		w := BaselineWebhook(t, server.URL)
+
+		resolved := event
+		resolved.Resolved = true
+		err := w.Notify(resolved)
+
+		require.NoError(t, err)
+		assert.False(t, posted)
+	})
+
+	t.Run("handles error status", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		w := BaselineWebhook(t, server.URL)
+
+		err := w.Notify(event)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("posts event again after failed delivery", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		statuses := []int{http.StatusInternalServerError, http.StatusOK, http.StatusBadGateway, http.StatusOK}
+		posted := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			w.WriteHeader(statuses[posted])
+			posted++
+		}))
+		defer server.Close()
+
+		w := BaselineWebhook(t, server.URL)
+
+		err := w.Notify(event)
+		require.Error(t, err)
+		err = w.Notify(event)
+		require.NoError(t, err)
+
+		resolved := event
+		resolved.Resolved = true
+		err = w.Notify(resolved)
+		require.Error(t, err)
+		err = w.Notify(resolved)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, posted)
+	})
+
+	t.Run("handles unreachable endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		server.Close()
+
+		w := BaselineWebhook(t, server.URL)
+
+		err := w.Notify(event)
+
+		assert.Error(t, err)
+	})
+}
+
+func BaselineWebhook(t *testing.T, url string, opts ...func(*Webhook)) *Webhook {
+	t.Helper()
+
+	w := Webhook{
+		cfg: Config{
+			Format:   FormatSlack,
+			Source:   "test",
+			Cooldown: time.Minute,
+		},
+		url:    url,
+		client: &http.Client{Timeout: time.Second},
+		now:    time.Now,
+		mutex:  &sync.Mutex{},
+		sent:   make(map[string]time.Time),
+	}
+
+	for _, opt := range opts {
+		opt(&w)
+	}
+
+	return &w
+}
+
+func WithPagerDuty(key string) func(*Webhook) {
+	return func(w *Webhook) {
+		w.cfg.Format = FormatPagerDuty
+		w.cfg.RoutingKey = key
+	}
+}
+
+func WithClock(now func() time.Time) func(*Webhook) {
+	return func(w *Webhook) {
+		w.now = now
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package amount
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/cadence"
+)
+
+// Parse parses an amount given in the smallest unit of a token, such as the
+// value of a Rosetta amount. It only accepts decimal digits, so that signs,
+// whitespace and values above the range of 64-bit unsigned integers are
+// rejected with an explicit error instead of being silently converted.
+// Fractional values are more precise than the smallest unit of the token, and
+// are rejected with ErrPrecision rather than truncated.
+func Parse(value string) (uint64, error) {
+
+	if strings.HasPrefix(value, "-") {
+		if digits(value[1:]) {
+			return 0, fmt.Errorf("%w (value: %s)", ErrNegative, value)
+		}
+		return 0, fmt.Errorf("%w (value: %s)", ErrInvalid, value)
+	}
+	dot := strings.IndexByte(value, '.')
+	if dot >= 0 && digits(value[:dot]) && digits(value[dot+1:]) {
+		return 0, fmt.Errorf("%w (value: %s)", ErrPrecision, value)
+	}
+	if !digits(value) {
+		return 0, fmt.Errorf("%w (value: %s)", ErrInvalid, value)
+	}
+
+	units, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w (value: %s)", ErrOverflow, value)
+	}
+
+	return units, nil
+}
+
+// ParseSigned parses a signed amount given in the smallest unit of a token, as
+// used by operations to distinguish withdrawals from deposits. It returns the
+// magnitude of the amount and whether it is negative, so that the full range
+// of 64-bit unsigned amounts can be represented.
+func ParseSigned(value string) (uint64, bool, error) {
+
+	negative := strings.HasPrefix(value, "-")
+	if negative {
+		value = value[1:]
+	}
+
+	units, err := Parse(value)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return units, negative && units != 0, nil
+}
+
+// Format formats an amount in the smallest unit of a token.
+func Format(units uint64) string {
+	return strconv.FormatUint(units, 10)
+}
+
+// FormatSigned formats an amount in the smallest unit of a token, with a minus
+// sign if it is negative.
+func FormatSigned(units uint64, negative bool) string {
+	if negative && units != 0 {
+		return "-" + Format(units)
+	}
+	return Format(units)
+}
+
+// ToDecimal formats an amount in the smallest unit of a token as a decimal
+// number with the given number of decimals, such as `12.34000000` for FLOW.
+func ToDecimal(units uint64, decimals uint) string {
+
+	value := Format(units)
+	if decimals == 0 {
+		return value
+	}
+
+	if uint(len(value)) <= decimals {
+		value = strings.Repeat("0", int(decimals)-len(value)+1) + value
+	}
+	split := len(value) - int(decimals)
+
+	return value[:split] + "." + value[split:]
+}
+
+// FromDecimal parses a decimal number with at most the given number of decimals
+// into an amount in the smallest unit of a token. Values with more decimals are
+// rejected rather than truncated.
+func FromDecimal(value string, decimals uint) (uint64, error) {
+
+	integer, fraction := value, ""
+	dot := strings.IndexByte(value, '.')
+	if dot >= 0 {
+		integer, fraction = value[:dot], value[dot+1:]
+		if integer == "" || fraction == "" {
+			return 0, fmt.Errorf("%w (value: %s)", ErrInvalid, value)
+		}
+	}
+	if !digits(fraction) && fraction != "" {
+		return 0, fmt.Errorf("%w (value: %s)", ErrInvalid, value)
+	}
+
+	// Trailing zeros do not add any precision, so they are always accepted.
+	trimmed := strings.TrimRight(fraction, "0")
+	if uint(len(trimmed)) > decimals {
+		return 0, fmt.Errorf("%w (value: %s, decimals: %d)", ErrPrecision, value, decimals)
+	}
+	padded := trimmed + strings.Repeat("0", int(decimals)-len(trimmed))
+
+	units, err := Parse(integer + padded)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse decimal: %w", err)
+	}
+
+	return units, nil
+}
+
+// ToUFix64 parses an amount in the smallest unit of a token into a Cadence
+// fixed-point value, whose smallest unit is the same as for all tokens on Flow.
+func ToUFix64(value string) (cadence.UFix64, error) {
+	units, err := Parse(value)
+	if err != nil {
+		return 0, err
+	}
+	return cadence.UFix64(units), nil
+}
+
+// FromUFix64 formats a Cadence fixed-point value as an amount in its smallest unit.
+func FromUFix64(value cadence.UFix64) string {
+	return Format(uint64(value))
+}
+
+// digits checks whether the given value is a non-empty string of decimal digits.
+func digits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
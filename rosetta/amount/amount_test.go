@@ -0,0 +1,167 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package amount_test
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+)
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		value   string
+		want    uint64
+		wantErr error
+	}{
+		{name: "zero", value: "0", want: 0},
+		{name: "nominal case", value: "4200000000", want: 4200000000},
+		{name: "maximum value", value: "18446744073709551615", want: 18446744073709551615},
+		{name: "overflow", value: "18446744073709551616", wantErr: amount.ErrOverflow},
+		{name: "negative value", value: "-42", wantErr: amount.ErrNegative},
+		{name: "explicit plus sign", value: "+42", wantErr: amount.ErrInvalid},
+		{name: "decimal value", value: "4.2", wantErr: amount.ErrPrecision},
+		{name: "malformed decimal value", value: "4.", wantErr: amount.ErrInvalid},
+		{name: "whitespace", value: " 42", wantErr: amount.ErrInvalid},
+		{name: "empty value", value: "", wantErr: amount.ErrInvalid},
+		{name: "sign only", value: "-", wantErr: amount.ErrInvalid},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := amount.Parse(test.value)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.value, amount.Format(got))
+		})
+	}
+}
+
+func TestParseSigned(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		value        string
+		want         uint64
+		wantNegative bool
+		wantErr      error
+	}{
+		{name: "positive value", value: "42", want: 42},
+		{name: "negative value", value: "-42", want: 42, wantNegative: true},
+		{name: "negative zero", value: "-0", want: 0},
+		{name: "negative maximum value", value: "-18446744073709551615", want: 18446744073709551615, wantNegative: true},
+		{name: "negative overflow", value: "-18446744073709551616", wantErr: amount.ErrOverflow},
+		{name: "double sign", value: "--42", wantErr: amount.ErrNegative},
+		{name: "invalid value", value: "-4x", wantErr: amount.ErrInvalid},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, negative, err := amount.ParseSigned(test.value)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.wantNegative, negative)
+		})
+	}
+}
+
+func TestFormatSigned(t *testing.T) {
+	assert.Equal(t, "42", amount.FormatSigned(42, false))
+	assert.Equal(t, "-42", amount.FormatSigned(42, true))
+	assert.Equal(t, "0", amount.FormatSigned(0, true))
+	assert.Equal(t, "-18446744073709551615", amount.FormatSigned(18446744073709551615, true))
+}
+
+func TestToDecimal(t *testing.T) {
+	assert.Equal(t, "12.34000000", amount.ToDecimal(1234000000, 8))
+	assert.Equal(t, "0.00000001", amount.ToDecimal(1, 8))
+	assert.Equal(t, "0.00000000", amount.ToDecimal(0, 8))
+	assert.Equal(t, "184467440737.09551615", amount.ToDecimal(18446744073709551615, 8))
+	assert.Equal(t, "42", amount.ToDecimal(42, 0))
+}
+
+func TestFromDecimal(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		value   string
+		want    uint64
+		wantErr error
+	}{
+		{name: "nominal case", value: "12.34", want: 1234000000},
+		{name: "full precision", value: "0.00000001", want: 1},
+		{name: "integer value", value: "42", want: 4200000000},
+		{name: "trailing zeros beyond precision", value: "1.0000000000", want: 100000000},
+		{name: "maximum value", value: "184467440737.09551615", want: 18446744073709551615},
+		{name: "overflow", value: "184467440737.09551616", wantErr: amount.ErrOverflow},
+		{name: "excess precision", value: "0.000000001", wantErr: amount.ErrPrecision},
+		{name: "negative value", value: "-1.5", wantErr: amount.ErrNegative},
+		{name: "missing integer part", value: ".5", wantErr: amount.ErrInvalid},
+		{name: "missing fraction part", value: "5.", wantErr: amount.ErrInvalid},
+		{name: "exponent", value: "1e8", wantErr: amount.ErrInvalid},
+		{name: "invalid fraction", value: "1.5x", wantErr: amount.ErrInvalid},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := amount.FromDecimal(test.value, 8)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestUFix64(t *testing.T) {
+	value, err := amount.ToUFix64("4200000000")
+
+	require.NoError(t, err)
+	assert.Equal(t, cadence.UFix64(4200000000), value)
+	assert.Equal(t, "42.00000000", value.String())
+	assert.Equal(t, "4200000000", amount.FromUFix64(value))
+
+	_, err = amount.ToUFix64("-1")
+
+	assert.ErrorIs(t, err, amount.ErrNegative)
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package amount
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Big parses a signed amount given in the smallest unit of a token into an
+// arbitrary-precision integer. Unlike Parse, it does not limit the magnitude to
+// the 64-bit unsigned range, so it can be used for aggregated values, such as the
+// sum of several vault balances or the net change of an account over many blocks.
+func Big(value string) (*big.Int, error) {
+
+	magnitude := strings.TrimPrefix(value, "-")
+	if !digits(magnitude) {
+		return nil, fmt.Errorf("%w (value: %s)", ErrInvalid, value)
+	}
+
+	number, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w (value: %s)", ErrInvalid, value)
+	}
+
+	return number, nil
+}
+
+// Sum returns the sum of the given signed amounts. It returns zero if no amounts
+// are given.
+func Sum(values ...string) (string, error) {
+
+	total := new(big.Int)
+	for _, value := range values {
+		number, err := Big(value)
+		if err != nil {
+			return "", err
+		}
+		total.Add(total, number)
+	}
+
+	return total.String(), nil
+}
+
+// Negate returns the given signed amount with its sign inverted.
+func Negate(value string) (string, error) {
+
+	number, err := Big(value)
+	if err != nil {
+		return "", err
+	}
+
+	return number.Neg(number).String(), nil
+}
+
+// Compare compares two signed amounts. It returns -1 if the first amount is
+// smaller than the second one, 0 if they are equal and +1 if it is larger.
+func Compare(first string, second string) (int, error) {
+
+	x, err := Big(first)
+	if err != nil {
+		return 0, err
+	}
+	y, err := Big(second)
+	if err != nil {
+		return 0, err
+	}
+
+	return x.Cmp(y), nil
+}
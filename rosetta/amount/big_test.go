@@ -0,0 +1,108 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package amount_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+)
+
+func TestBig(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "positive value", value: "42", want: "42"},
+		{name: "negative value", value: "-42", want: "-42"},
+		{name: "beyond 64-bit range", value: "-36893488147419103230", want: "-36893488147419103230"},
+		{name: "explicit plus sign", value: "+42", wantErr: amount.ErrInvalid},
+		{name: "decimal value", value: "4.2", wantErr: amount.ErrInvalid},
+		{name: "empty value", value: "", wantErr: amount.ErrInvalid},
+		{name: "sign only", value: "-", wantErr: amount.ErrInvalid},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := amount.Big(test.value)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got.String())
+		})
+	}
+}
+
+func TestSum(t *testing.T) {
+
+	got, err := amount.Sum("18446744073709551615", "18446744073709551615", "-10")
+	require.NoError(t, err)
+	assert.Equal(t, "36893488147419103220", got)
+
+	got, err = amount.Sum()
+	require.NoError(t, err)
+	assert.Equal(t, "0", got)
+
+	_, err = amount.Sum("42", "4x")
+	assert.ErrorIs(t, err, amount.ErrInvalid)
+}
+
+func TestNegate(t *testing.T) {
+
+	got, err := amount.Negate("18446744073709551615")
+	require.NoError(t, err)
+	assert.Equal(t, "-18446744073709551615", got)
+
+	got, err = amount.Negate("-42")
+	require.NoError(t, err)
+	assert.Equal(t, "42", got)
+
+	got, err = amount.Negate("0")
+	require.NoError(t, err)
+	assert.Equal(t, "0", got)
+
+	_, err = amount.Negate("--42")
+	assert.ErrorIs(t, err, amount.ErrInvalid)
+}
+
+func TestCompare(t *testing.T) {
+
+	got, err := amount.Compare("36893488147419103230", "18446744073709551615")
+	require.NoError(t, err)
+	assert.Equal(t, 1, got)
+
+	got, err = amount.Compare("-42", "42")
+	require.NoError(t, err)
+	assert.Equal(t, -1, got)
+
+	got, err = amount.Compare("42", "42")
+	require.NoError(t, err)
+	assert.Equal(t, 0, got)
+
+	_, err = amount.Compare("42", "")
+	assert.ErrorIs(t, err, amount.ErrInvalid)
+}
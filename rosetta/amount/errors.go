@@ -0,0 +1,28 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package amount
+
+import (
+	"errors"
+)
+
+// Sentinel errors for amounts that can not be converted. They are always wrapped
+// with the offending value.
+var (
+	ErrInvalid   = errors.New("invalid amount")
+	ErrNegative  = errors.New("negative amount")
+	ErrOverflow  = errors.New("amount overflows 64-bit unsigned integer")
+	ErrPrecision = errors.New("amount has too many decimals")
+)
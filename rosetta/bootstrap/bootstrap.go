@@ -0,0 +1,192 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Downloader downloads index snapshots from object storage, such as S3 or GCS,
+// through their HTTP endpoints or pre-signed URLs, so that new replicas can be
+// provisioned from configuration alone. Downloads are written to a partial file
+// next to their destination, so that an interrupted download is resumed where
+// it stopped, and they are only moved into place once their checksum matches.
+type Downloader struct {
+	log    zerolog.Logger
+	cfg    Config
+	client *http.Client
+}
+
+// New creates a new downloader.
+func New(log zerolog.Logger, options ...func(*Config)) *Downloader {
+
+	cfg := Config{
+		Attempts: 5,
+		Backoff:  5 * time.Second,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	// We always want to try at least once.
+	if cfg.Attempts == 0 {
+		cfg.Attempts = 1
+	}
+
+	d := Downloader{
+		log:    log.With().Str("component", "bootstrap").Logger(),
+		cfg:    cfg,
+		client: &http.Client{},
+	}
+
+	return &d
+}
+
+// Download downloads the file at the given URL to the given path, and verifies
+// that its SHA-256 checksum is the given hex-encoded one. If the download fails
+// on the way, it is resumed until it completes or the attempts are exhausted. A
+// file with a mismatching checksum is discarded.
+func (d *Downloader) Download(ctx context.Context, url string, path string, checksum string) error {
+
+	want, err := hex.DecodeString(strings.TrimSpace(checksum))
+	if err != nil || len(want) != sha256.Size {
+		return fmt.Errorf("invalid SHA-256 checksum (checksum: %s)", checksum)
+	}
+
+	partial := path + ".partial"
+	for attempt := uint(1); ; attempt++ {
+
+		err = d.resume(ctx, url, partial)
+		if err == nil {
+			break
+		}
+		if attempt >= d.cfg.Attempts || ctx.Err() != nil {
+			return fmt.Errorf("could not download snapshot (attempts: %d): %w", attempt, err)
+		}
+
+		d.log.Warn().Str("url", url).Uint("attempt", attempt).Err(err).Msg("snapshot download interrupted, resuming")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d.cfg.Backoff):
+		}
+	}
+
+	have, err := hash(partial)
+	if err != nil {
+		return fmt.Errorf("could not compute snapshot checksum: %w", err)
+	}
+	if !bytes.Equal(have, want) {
+		_ = os.Remove(partial)
+		return fmt.Errorf("snapshot checksum mismatch (have: %x, want: %x)", have, want)
+	}
+
+	err = os.Rename(partial, path)
+	if err != nil {
+		return fmt.Errorf("could not move snapshot into place: %w", err)
+	}
+
+	return nil
+}
+
+// resume downloads the part of the file at the given URL that is still missing
+// from the partial file at the given path.
+func (d *Downloader) resume(ctx context.Context, url string, partial string) error {
+
+	file, err := os.OpenFile(partial, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open partial file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat partial file: %w", err)
+	}
+	offset := info.Size()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Servers that do not support ranges send the whole file again, in which
+	// case we start over, and those that have nothing left to send tell us
+	// that the range starts past the end of the file.
+	switch res.StatusCode {
+	case http.StatusOK:
+		offset = 0
+		err = file.Truncate(0)
+		if err != nil {
+			return fmt.Errorf("could not truncate partial file: %w", err)
+		}
+	case http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		return nil
+	default:
+		return fmt.Errorf("unexpected response status (status: %s)", res.Status)
+	}
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("could not seek partial file: %w", err)
+	}
+	_, err = io.Copy(file, res.Body)
+	if err != nil {
+		return fmt.Errorf("could not write partial file: %w", err)
+	}
+
+	return file.Close()
+}
+
+// hash returns the SHA-256 checksum of the file at the given path.
+func hash(path string) ([]byte, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
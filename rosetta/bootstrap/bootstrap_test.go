@@ -0,0 +1,153 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package bootstrap_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/bootstrap"
+)
+
+func TestDownloader_Download(t *testing.T) {
+	content := bytes.Repeat([]byte("snapshot"), 1024)
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	serve := func(t *testing.T) (*httptest.Server, *[]string) {
+		t.Helper()
+
+		var mu sync.Mutex
+		var ranges []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+			http.ServeContent(w, r, "index.snap", time.Now(), bytes.NewReader(content))
+		}))
+		t.Cleanup(server.Close)
+
+		return server, &ranges
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		server, ranges := serve(t)
+		path := filepath.Join(t.TempDir(), "index.snap")
+
+		d := bootstrap.New(zerolog.Nop())
+		err := d.Download(context.Background(), server.URL, path, checksum)
+
+		require.NoError(t, err)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, content, data)
+		assert.Equal(t, []string{""}, *ranges)
+		assert.NoFileExists(t, path+".partial")
+	})
+
+	t.Run("resumes partial download", func(t *testing.T) {
+		t.Parallel()
+
+		server, ranges := serve(t)
+		path := filepath.Join(t.TempDir(), "index.snap")
+		require.NoError(t, os.WriteFile(path+".partial", content[:1000], 0644))
+
+		d := bootstrap.New(zerolog.Nop())
+		err := d.Download(context.Background(), server.URL, path, checksum)
+
+		require.NoError(t, err)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, content, data)
+		assert.Equal(t, []string{"bytes=1000-"}, *ranges)
+	})
+
+	t.Run("discards download with mismatching checksum", func(t *testing.T) {
+		t.Parallel()
+
+		server, _ := serve(t)
+		path := filepath.Join(t.TempDir(), "index.snap")
+		other := sha256.Sum256([]byte("other"))
+
+		d := bootstrap.New(zerolog.Nop())
+		err := d.Download(context.Background(), server.URL, path, hex.EncodeToString(other[:]))
+
+		assert.Error(t, err)
+		assert.NoFileExists(t, path)
+		assert.NoFileExists(t, path+".partial")
+	})
+
+	t.Run("retries failed requests", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			http.ServeContent(w, r, "index.snap", time.Now(), bytes.NewReader(content))
+		}))
+		t.Cleanup(server.Close)
+		path := filepath.Join(t.TempDir(), "index.snap")
+
+		d := bootstrap.New(zerolog.Nop(), bootstrap.WithBackoff(time.Millisecond))
+		err := d.Download(context.Background(), server.URL, path, checksum)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("gives up after last attempt", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		t.Cleanup(server.Close)
+		path := filepath.Join(t.TempDir(), "index.snap")
+
+		d := bootstrap.New(zerolog.Nop(), bootstrap.WithAttempts(2), bootstrap.WithBackoff(time.Millisecond))
+		err := d.Download(context.Background(), server.URL, path, checksum)
+
+		assert.Error(t, err)
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("handles invalid checksum", func(t *testing.T) {
+		t.Parallel()
+
+		d := bootstrap.New(zerolog.Nop())
+		err := d.Download(context.Background(), "http://localhost", filepath.Join(t.TempDir(), "index.snap"), "invalid")
+
+		assert.Error(t, err)
+	})
+}
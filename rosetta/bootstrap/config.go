@@ -0,0 +1,40 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package bootstrap
+
+import (
+	"time"
+)
+
+// Config contains the configuration options for the downloader.
+type Config struct {
+	Attempts uint
+	Backoff  time.Duration
+}
+
+// WithAttempts sets how many times the downloader tries to complete a download,
+// resuming it where the previous attempt stopped, before giving up.
+func WithAttempts(attempts uint) func(*Config) {
+	return func(c *Config) {
+		c.Attempts = attempts
+	}
+}
+
+// WithBackoff sets how long the downloader waits between two attempts.
+func WithBackoff(backoff time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.Backoff = backoff
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Cache wraps an invoker and keeps the results of the Cadence scripts it
+// executes. The state at an indexed height never changes, so executing the
+// same script with the same arguments at the same height always gives the
+// same result, and repeated balance queries can skip the execution.
+type Cache struct {
+	invoke  Invoker
+	results Store
+}
+
+// overhead is the estimated memory used by a cached result on top of its
+// encoded value, for its key and the bookkeeping of the store.
+const overhead = 128
+
+// key identifies a script execution by its height and by the hash of its
+// script and arguments.
+type key struct {
+	height uint64
+	digest [sha256.Size]byte
+}
+
+// New creates a new script result cache around the given invoker, which keeps
+// its results in the given store.
+func New(invoke Invoker, results Store) *Cache {
+
+	c := Cache{
+		invoke:  invoke,
+		results: results,
+	}
+
+	return &c
+}
+
+// Key returns the public key of the account with the given address at the given height.
+func (c *Cache) Key(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
+	return c.invoke.Key(height, address, index)
+}
+
+// Account returns the account with the given address at the given height.
+func (c *Cache) Account(height uint64, address flow.Address) (*flow.Account, error) {
+	return c.invoke.Account(height, address)
+}
+
+// Script executes the given Cadence script with the given arguments at the
+// given height, unless its result is already cached. Failed executions are not
+// cached, as they might be caused by transient problems.
+func (c *Cache) Script(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+
+	k, err := digest(height, script, parameters)
+	if err != nil {
+		return nil, fmt.Errorf("could not compute cache key: %w", err)
+	}
+
+	cached, ok := c.results.Get(k)
+	if ok {
+		return cached.(cadence.Value), nil
+	}
+
+	value, err := c.invoke.Script(height, script, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(k, value)
+
+	return value, nil
+}
+
+// Transaction executes the given transaction at the given height. Its outcome
+// is never cached, as it depends on more than its script and arguments.
+func (c *Cache) Transaction(height uint64, body *flow.TransactionBody) error {
+	return c.invoke.Transaction(height, body)
+}
+
+// store keeps the given result, weighted by the size of its encoding. The
+// size is only an estimate of the memory it uses, but it is proportional to it.
+func (c *Cache) store(k key, value cadence.Value) {
+	data, err := json.Encode(value)
+	if err != nil {
+		return
+	}
+	_ = c.results.Set(k, value, int64(len(data)+overhead))
+}
+
+// digest hashes the script and its encoded arguments together. Each part is
+// prefixed with its length, so that different splits of the same bytes between
+// script and arguments never result in the same key.
+func digest(height uint64, script []byte, parameters []cadence.Value) (key, error) {
+
+	hash := sha256.New()
+	write := func(data []byte) {
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(data)))
+		_, _ = hash.Write(size[:])
+		_, _ = hash.Write(data)
+	}
+
+	write(script)
+	for _, parameter := range parameters {
+		data, err := json.Encode(parameter)
+		if err != nil {
+			return key{}, fmt.Errorf("could not encode parameter: %w", err)
+		}
+		write(data)
+	}
+
+	k := key{height: height}
+	copy(k.digest[:], hash.Sum(nil))
+
+	return k, nil
+}
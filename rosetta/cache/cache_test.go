@@ -0,0 +1,176 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/cache"
+	"github.com/optakt/flow-rosetta/rosetta/memory"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestCache_Script(t *testing.T) {
+	script := mocks.GenericBytes
+	params := []cadence.Value{cadence.NewAddress(mocks.GenericAddress(0))}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(height uint64, got []byte, parameters []cadence.Value) (cadence.Value, error) {
+			calls++
+			assert.Equal(t, mocks.GenericHeight, height)
+			assert.Equal(t, script, got)
+			assert.Equal(t, params, parameters)
+
+			return mocks.GenericAmount(0), nil
+		}
+
+		c := cache.New(invoke, results(t))
+
+		for i := 0; i < 3; i++ {
+			value, err := c.Script(mocks.GenericHeight, script, params)
+
+			require.NoError(t, err)
+			assert.Equal(t, mocks.GenericAmount(0), value)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("keeps separate results per height, script and arguments", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			calls++
+			return mocks.GenericAmount(calls), nil
+		}
+
+		c := cache.New(invoke, results(t))
+
+		_, err := c.Script(mocks.GenericHeight, script, params)
+		require.NoError(t, err)
+		_, err = c.Script(mocks.GenericHeight+1, script, params)
+		require.NoError(t, err)
+		_, err = c.Script(mocks.GenericHeight, []byte("other"), params)
+		require.NoError(t, err)
+		_, err = c.Script(mocks.GenericHeight, script, []cadence.Value{cadence.NewAddress(mocks.GenericAddress(1))})
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("weighs results by their size", func(t *testing.T) {
+		t.Parallel()
+
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			return mocks.GenericAmount(0), nil
+		}
+
+		var cost int64
+		store := mocks.BaselineCache(t)
+		store.GetFunc = func(interface{}) (interface{}, bool) {
+			return nil, false
+		}
+		store.SetFunc = func(_ interface{}, value interface{}, got int64) bool {
+			assert.Equal(t, mocks.GenericAmount(0), value)
+			cost = got
+			return true
+		}
+
+		c := cache.New(invoke, store)
+
+		_, err := c.Script(mocks.GenericHeight, script, params)
+		require.NoError(t, err)
+
+		data, err := json.Encode(mocks.GenericAmount(0))
+		require.NoError(t, err)
+		assert.Greater(t, cost, int64(len(data)))
+	})
+
+	t.Run("does not cache failures", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			calls++
+			return nil, mocks.GenericError
+		}
+
+		c := cache.New(invoke, results(t))
+
+		_, err := c.Script(mocks.GenericHeight, script, params)
+		assert.Error(t, err)
+		_, err = c.Script(mocks.GenericHeight, script, params)
+		assert.Error(t, err)
+
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestCache_Account(t *testing.T) {
+	invoke := mocks.BaselineInvoker(t)
+	invoke.AccountFunc = func(height uint64, address flow.Address) (*flow.Account, error) {
+		assert.Equal(t, mocks.GenericHeight, height)
+		assert.Equal(t, mocks.GenericAddress(0), address)
+
+		return &mocks.GenericAccount, nil
+	}
+
+	account, err := cache.New(invoke, results(t)).Account(mocks.GenericHeight, mocks.GenericAddress(0))
+
+	require.NoError(t, err)
+	assert.Equal(t, &mocks.GenericAccount, account)
+}
+
+func TestCache_Transaction(t *testing.T) {
+	calls := 0
+	invoke := mocks.BaselineInvoker(t)
+	invoke.TransactionFunc = func(height uint64, body *flow.TransactionBody) error {
+		assert.Equal(t, mocks.GenericHeight, height)
+		assert.Equal(t, mocks.GenericTransaction(0), body)
+
+		calls++
+		return nil
+	}
+
+	c := cache.New(invoke, results(t))
+
+	err := c.Transaction(mocks.GenericHeight, mocks.GenericTransaction(0))
+	require.NoError(t, err)
+	err = c.Transaction(mocks.GenericHeight, mocks.GenericTransaction(0))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, calls)
+}
+
+func results(t *testing.T) *memory.LRU {
+	t.Helper()
+
+	return memory.NewLRU("scripts", 1<<20, memory.NewMetrics(prometheus.NewRegistry()))
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package configuration
+
+// Config is the configuration for the Rosetta API configuration component.
+type Config struct {
+	OperationTypes []string
+	SubNetwork     string
+}
+
+// WithOperationTypes sets the types of the operations that the Data API emits in
+// a Config. Only the allowlisted types of DataOperations are advertised, and all
+// of them are when none are given.
+func WithOperationTypes(types ...string) func(*Config) {
+	return func(c *Config) {
+		c.OperationTypes = types
+	}
+}
+
+// WithSubNetwork sets the sub-network that identifies the spork served with a
+// Config, so that several sporks of the same chain can be served side by side.
+func WithSubNetwork(name string) func(*Config) {
+	return func(c *Config) {
+		c.SubNetwork = name
+	}
+}
@@ -26,6 +26,7 @@ import (
 const (
 	blockchainUnknown = "network identifier has unknown blockchain field"
 	networkUnknown    = "network identifier has unknown network field"
+	subNetworkUnknown = "network identifier has unknown sub-network field"
 )
 
 type Configuration struct {
@@ -37,12 +38,20 @@ type Configuration struct {
 }
 
 // New returns the configuration for a given Flow chain.
-func New(chain flow.ChainID) *Configuration {
+func New(chain flow.ChainID, options ...func(*Config)) *Configuration {
+
+	var cfg Config
+	for _, option := range options {
+		option(&cfg)
+	}
 
 	network := identifier.Network{
 		Blockchain: dps.FlowBlockchain,
 		Network:    chain.String(),
 	}
+	if cfg.SubNetwork != "" {
+		network.SubNetwork = &identifier.SubNetwork{Network: cfg.SubNetwork}
+	}
 
 	version := meta.Version{
 		RosettaVersion:    RosettaVersion,
@@ -52,11 +61,28 @@ func New(chain flow.ChainID) *Configuration {
 
 	statuses := []meta.StatusDefinition{
 		StatusCompleted,
+		StatusFailed,
 	}
 
-	operations := []string{
-		OperationTransfer,
+	// The operation types of the Data API are only advertised if they are
+	// allowlisted, but the ones of the Construction API always are.
+	allowed := make(map[string]struct{}, len(cfg.OperationTypes))
+	for _, typ := range cfg.OperationTypes {
+		allowed[typ] = struct{}{}
 	}
+	var operations []string
+	for _, typ := range DataOperations {
+		_, ok := allowed[typ]
+		if len(allowed) > 0 && !ok {
+			continue
+		}
+		operations = append(operations, typ)
+	}
+	operations = append(operations,
+		OperationTemplate,
+		OperationKeyAdd,
+		OperationKeyRevoke,
+	)
 
 	errors := []meta.ErrorDefinition{
 		ErrorInternal,
@@ -83,6 +109,34 @@ func New(chain flow.ChainID) *Configuration {
 		ErrorInvalidKey,
 		ErrorInvalidPayload,
 		ErrorInvalidSignatures,
+
+		ErrorUpstreamNotFound,
+		ErrorUpstreamOutOfRange,
+		ErrorUpstreamUnavailable,
+		ErrorUpstreamTimeout,
+
+		ErrorUnavailableBlock,
+
+		ErrorUnknownNode,
+
+		ErrorInsufficientBalance,
+
+		ErrorExpiredTransaction,
+
+		ErrorUncoveredBlock,
+
+		ErrorOverloaded,
+		ErrorLimitExceeded,
+
+		ErrorUnsealedBlock,
+
+		ErrorInsufficientFee,
+
+		ErrorSequenceConflict,
+
+		ErrorFailedTransaction,
+
+		ErrorDisabledEndpoint,
 	}
 
 	c := Configuration{
@@ -137,5 +191,23 @@ func (c *Configuration) Check(network identifier.Network) error {
 			Description: failure.NewDescription(networkUnknown),
 		}
 	}
+
+	// Requests without a sub-network are served by whichever spork is
+	// configured, but those that name one have to name the configured one.
+	if network.SubNetwork == nil {
+		return nil
+	}
+	want := ""
+	if c.network.SubNetwork != nil {
+		want = c.network.SubNetwork.Network
+	}
+	if network.SubNetwork.Network != want {
+		return failure.InvalidNetwork{
+			HaveNetwork: network.SubNetwork.Network,
+			WantNetwork: want,
+			Description: failure.NewDescription(subNetworkUnknown),
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,103 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package configuration_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+func TestConfiguration_Operations(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		config := configuration.New(flow.Testnet)
+
+		want := append(configuration.DataOperations,
+			configuration.OperationTemplate,
+			configuration.OperationKeyAdd,
+			configuration.OperationKeyRevoke,
+		)
+		assert.Equal(t, want, config.Operations())
+	})
+
+	t.Run("only advertises allowlisted operation types", func(t *testing.T) {
+		config := configuration.New(flow.Testnet,
+			configuration.WithOperationTypes(configuration.OperationBurn, configuration.OperationTransfer),
+		)
+
+		want := []string{
+			configuration.OperationTransfer,
+			configuration.OperationBurn,
+			configuration.OperationTemplate,
+			configuration.OperationKeyAdd,
+			configuration.OperationKeyRevoke,
+		}
+		assert.Equal(t, want, config.Operations())
+	})
+}
+
+func TestConfiguration_Check(t *testing.T) {
+	network := func(sub string) identifier.Network {
+		n := identifier.Network{
+			Blockchain: dps.FlowBlockchain,
+			Network:    flow.Mainnet.String(),
+		}
+		if sub != "" {
+			n.SubNetwork = &identifier.SubNetwork{Network: sub}
+		}
+		return n
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		config := configuration.New(flow.Mainnet)
+
+		assert.NoError(t, config.Check(network("")))
+	})
+
+	t.Run("accepts configured sub-network", func(t *testing.T) {
+		config := configuration.New(flow.Mainnet, configuration.WithSubNetwork("mainnet-17"))
+
+		assert.Equal(t, network("mainnet-17"), config.Network())
+		assert.NoError(t, config.Check(network("mainnet-17")))
+		assert.NoError(t, config.Check(network("")))
+	})
+
+	t.Run("rejects other network", func(t *testing.T) {
+		config := configuration.New(flow.Mainnet)
+
+		other := network("")
+		other.Network = flow.Testnet.String()
+		assert.ErrorAs(t, config.Check(other), &failure.InvalidNetwork{})
+	})
+
+	t.Run("rejects other sub-network", func(t *testing.T) {
+		config := configuration.New(flow.Mainnet, configuration.WithSubNetwork("mainnet-17"))
+
+		assert.ErrorAs(t, config.Check(network("mainnet-16")), &failure.InvalidNetwork{})
+	})
+
+	t.Run("rejects sub-network when none is configured", func(t *testing.T) {
+		config := configuration.New(flow.Mainnet)
+
+		assert.ErrorAs(t, config.Check(network("mainnet-17")), &failure.InvalidNetwork{})
+	})
+}
@@ -15,34 +15,75 @@
 package configuration
 
 import (
+	"github.com/optakt/flow-rosetta/rosetta/failure"
 	"github.com/optakt/flow-rosetta/rosetta/meta"
 )
 
 var (
 	// Data API specific errors.
-	ErrorInternal           = meta.ErrorDefinition{Code: 1, Message: "internal error", Retriable: false}
-	ErrorInvalidEncoding    = meta.ErrorDefinition{Code: 2, Message: "invalid request encoding", Retriable: false}
-	ErrorInvalidFormat      = meta.ErrorDefinition{Code: 3, Message: "invalid request format", Retriable: false}
-	ErrorInvalidNetwork     = meta.ErrorDefinition{Code: 4, Message: "invalid network identifier", Retriable: false}
-	ErrorInvalidAccount     = meta.ErrorDefinition{Code: 5, Message: "invalid account identifier", Retriable: false}
-	ErrorInvalidCurrency    = meta.ErrorDefinition{Code: 6, Message: "invalid currency identifier", Retriable: false}
-	ErrorInvalidBlock       = meta.ErrorDefinition{Code: 7, Message: "invalid block identifier", Retriable: false}
-	ErrorInvalidTransaction = meta.ErrorDefinition{Code: 8, Message: "invalid transaction identifier", Retriable: false}
-	ErrorUnknownBlock       = meta.ErrorDefinition{Code: 9, Message: "unknown block identifier", Retriable: true}
-	ErrorUnknownCurrency    = meta.ErrorDefinition{Code: 10, Message: "unknown currency identifier", Retriable: false}
-	ErrorUnknownTransaction = meta.ErrorDefinition{Code: 11, Message: "unknown block transaction", Retriable: false}
+	ErrorInternal           = meta.ErrorDefinition{Code: 1, Message: "internal error", Retriable: false, Category: failure.CategoryInternal}
+	ErrorInvalidEncoding    = meta.ErrorDefinition{Code: 2, Message: "invalid request encoding", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidFormat      = meta.ErrorDefinition{Code: 3, Message: "invalid request format", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidNetwork     = meta.ErrorDefinition{Code: 4, Message: "invalid network identifier", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidAccount     = meta.ErrorDefinition{Code: 5, Message: "invalid account identifier", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidCurrency    = meta.ErrorDefinition{Code: 6, Message: "invalid currency identifier", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidBlock       = meta.ErrorDefinition{Code: 7, Message: "invalid block identifier", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidTransaction = meta.ErrorDefinition{Code: 8, Message: "invalid transaction identifier", Retriable: false, Category: failure.CategoryClient}
+	ErrorUnknownBlock       = meta.ErrorDefinition{Code: 9, Message: "unknown block identifier", Retriable: true, Category: failure.CategoryNotFound}
+	ErrorUnknownCurrency    = meta.ErrorDefinition{Code: 10, Message: "unknown currency identifier", Retriable: false, Category: failure.CategoryNotFound}
+	ErrorUnknownTransaction = meta.ErrorDefinition{Code: 11, Message: "unknown block transaction", Retriable: false, Category: failure.CategoryNotFound}
 
 	// Construction API specific errors.
-	ErrorInvalidIntent      = meta.ErrorDefinition{Code: 12, Message: "invalid transaction intent", Retriable: false}
-	ErrorInvalidAuthorizers = meta.ErrorDefinition{Code: 13, Message: "invalid transaction authorizers", Retriable: false}
-	ErrorInvalidPayer       = meta.ErrorDefinition{Code: 14, Message: "invalid transaction payer", Retriable: false}
-	ErrorInvalidProposer    = meta.ErrorDefinition{Code: 15, Message: "invalid transaction proposer", Retriable: false}
-	ErrorInvalidScript      = meta.ErrorDefinition{Code: 16, Message: "invalid transaction script", Retriable: false}
-	ErrorInvalidArguments   = meta.ErrorDefinition{Code: 17, Message: "invalid transaction arguments", Retriable: false}
-	ErrorInvalidAmount      = meta.ErrorDefinition{Code: 18, Message: "invalid transaction amount", Retriable: false}
-	ErrorInvalidReceiver    = meta.ErrorDefinition{Code: 19, Message: "invalid transaction recipient", Retriable: false}
-	ErrorInvalidSignature   = meta.ErrorDefinition{Code: 20, Message: "invalid transaction signature", Retriable: false}
-	ErrorInvalidKey         = meta.ErrorDefinition{Code: 21, Message: "invalid transaction signer key", Retriable: false}
-	ErrorInvalidPayload     = meta.ErrorDefinition{Code: 22, Message: "invalid transaction payload", Retriable: false}
-	ErrorInvalidSignatures  = meta.ErrorDefinition{Code: 23, Message: "invalid transaction signatures", Retriable: false}
+	ErrorInvalidIntent      = meta.ErrorDefinition{Code: 12, Message: "invalid transaction intent", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidAuthorizers = meta.ErrorDefinition{Code: 13, Message: "invalid transaction authorizers", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidPayer       = meta.ErrorDefinition{Code: 14, Message: "invalid transaction payer", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidProposer    = meta.ErrorDefinition{Code: 15, Message: "invalid transaction proposer", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidScript      = meta.ErrorDefinition{Code: 16, Message: "invalid transaction script", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidArguments   = meta.ErrorDefinition{Code: 17, Message: "invalid transaction arguments", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidAmount      = meta.ErrorDefinition{Code: 18, Message: "invalid transaction amount", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidReceiver    = meta.ErrorDefinition{Code: 19, Message: "invalid transaction recipient", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidSignature   = meta.ErrorDefinition{Code: 20, Message: "invalid transaction signature", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidKey         = meta.ErrorDefinition{Code: 21, Message: "invalid transaction signer key", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidPayload     = meta.ErrorDefinition{Code: 22, Message: "invalid transaction payload", Retriable: false, Category: failure.CategoryClient}
+	ErrorInvalidSignatures  = meta.ErrorDefinition{Code: 23, Message: "invalid transaction signatures", Retriable: false, Category: failure.CategoryClient}
+
+	// Upstream API specific errors.
+	ErrorUpstreamNotFound    = meta.ErrorDefinition{Code: 24, Message: "upstream resource not found", Retriable: false, Category: failure.CategoryNotFound}
+	ErrorUpstreamOutOfRange  = meta.ErrorDefinition{Code: 25, Message: "upstream request out of range", Retriable: false, Category: failure.CategoryClient}
+	ErrorUpstreamUnavailable = meta.ErrorDefinition{Code: 26, Message: "upstream service unavailable", Retriable: true, Category: failure.CategoryUnavailable}
+	ErrorUpstreamTimeout     = meta.ErrorDefinition{Code: 27, Message: "upstream request timed out", Retriable: true, Category: failure.CategoryUnavailable}
+
+	// Index specific errors.
+	ErrorUnavailableBlock = meta.ErrorDefinition{Code: 28, Message: "block not yet available", Retriable: true, Category: failure.CategoryUnavailable}
+
+	// Staking specific errors.
+	ErrorUnknownNode = meta.ErrorDefinition{Code: 29, Message: "unknown staking node", Retriable: false, Category: failure.CategoryNotFound}
+
+	// Simulation specific errors.
+	ErrorInsufficientBalance = meta.ErrorDefinition{Code: 30, Message: "insufficient account balance", Retriable: false, Category: failure.CategoryClient}
+
+	// Submission specific errors.
+	ErrorExpiredTransaction = meta.ErrorDefinition{Code: 31, Message: "transaction reference block expired", Retriable: false, Category: failure.CategoryClient}
+
+	// Spork specific errors.
+	ErrorUncoveredBlock = meta.ErrorDefinition{Code: 32, Message: "block not covered by any spork", Retriable: false, Category: failure.CategoryNotFound}
+
+	// Execution specific errors.
+	ErrorOverloaded    = meta.ErrorDefinition{Code: 33, Message: "server overloaded", Retriable: true, Category: failure.CategoryUnavailable}
+	ErrorLimitExceeded = meta.ErrorDefinition{Code: 34, Message: "script execution limit exceeded", Retriable: false, Category: failure.CategoryClient}
+
+	// Finality specific errors.
+	ErrorUnsealedBlock = meta.ErrorDefinition{Code: 35, Message: "block not yet sealed", Retriable: true, Category: failure.CategoryUnavailable}
+
+	// Fee specific errors.
+	ErrorInsufficientFee = meta.ErrorDefinition{Code: 200, Message: "maximum fee too low", Retriable: false, Category: failure.CategoryClient}
+
+	// Sequence number specific errors.
+	ErrorSequenceConflict = meta.ErrorDefinition{Code: 201, Message: "proposal key sequence number already used", Retriable: false, Category: failure.CategoryClient}
+
+	// Transaction execution specific errors.
+	ErrorFailedTransaction = meta.ErrorDefinition{Code: 500, Message: "transaction execution failed", Retriable: false, Category: failure.CategoryClient}
+
+	// Server specific errors.
+	ErrorDisabledEndpoint = meta.ErrorDefinition{Code: 600, Message: "endpoint disabled", Retriable: false, Category: failure.CategoryNotFound}
 )
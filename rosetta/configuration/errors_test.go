@@ -0,0 +1,51 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package configuration_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+func TestErrors(t *testing.T) {
+	config := configuration.New(flow.Testnet)
+
+	codes := make(map[uint]string)
+	for _, definition := range config.Errors() {
+		other, ok := codes[definition.Code]
+		assert.Falsef(t, ok, "error code %d of %q is already used by %q", definition.Code, definition.Message, other)
+		codes[definition.Code] = definition.Message
+
+		var matches int
+		for _, namespace := range configuration.Namespaces {
+			if namespace.Contains(definition.Code) {
+				matches++
+			}
+		}
+		assert.Equalf(t, 1, matches, "error code %d of %q should be in exactly one namespace", definition.Code, definition.Message)
+
+		// Clients have to change their request to get past a client error, so
+		// retrying it as it is can never succeed.
+		if definition.Category == failure.CategoryClient {
+			assert.Falsef(t, definition.Retriable, "client error code %d of %q should not be retriable", definition.Code, definition.Message)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package configuration
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/meta"
+)
+
+// The namespaces of Rosetta error codes. Codes below 100 were assigned before
+// namespaces were introduced and keep their values; new errors take the next
+// free code of the namespace of their subsystem.
+var (
+	NamespaceLegacy       = meta.Namespace{Name: "legacy", First: 1, Last: 99}
+	NamespaceData         = meta.Namespace{Name: "data", First: 100, Last: 199}
+	NamespaceConstruction = meta.Namespace{Name: "construction", First: 200, Last: 299}
+	NamespaceUpstream     = meta.Namespace{Name: "upstream", First: 300, Last: 399}
+	NamespaceIndex        = meta.Namespace{Name: "index", First: 400, Last: 499}
+	NamespaceExecution    = meta.Namespace{Name: "execution", First: 500, Last: 599}
+	NamespaceServer       = meta.Namespace{Name: "server", First: 600, Last: 699}
+)
+
+// Namespaces lists all namespaces of Rosetta error codes.
+var Namespaces = []meta.Namespace{
+	NamespaceLegacy,
+	NamespaceData,
+	NamespaceConstruction,
+	NamespaceUpstream,
+	NamespaceIndex,
+	NamespaceExecution,
+	NamespaceServer,
+}
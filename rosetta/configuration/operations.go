@@ -16,5 +16,20 @@ package configuration
 
 // Supported operations.
 const (
-	OperationTransfer = "TRANSFER"
+	OperationTransfer      = "TRANSFER"
+	OperationFeeCollection = "FEE_COLLECTION"
+	OperationMint          = "MINT"
+	OperationBurn          = "BURN"
+	OperationTemplate      = "TEMPLATE"
+	OperationKeyAdd        = "KEY_ADD"
+	OperationKeyRevoke     = "KEY_REVOKE"
 )
+
+// DataOperations are the types of the operations that the Data API converts
+// events into, as opposed to the ones that only the Construction API uses.
+var DataOperations = []string{
+	OperationTransfer,
+	OperationFeeCollection,
+	OperationMint,
+	OperationBurn,
+}
@@ -16,26 +16,57 @@ package converter
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/onflow/cadence"
 	"github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
 	"github.com/optakt/flow-rosetta/rosetta/object"
 	"github.com/optakt/flow-rosetta/rosetta/retriever"
 )
 
-// Converter converts Flow Events into Rosetta Operations.
+// contractActions are the actions on contracts of the events emitted by the Flow
+// virtual machine when contracts are deployed, updated or removed.
+var contractActions = map[flow.EventType]string{
+	"flow.AccountContractAdded":   "added",
+	"flow.AccountContractUpdated": "updated",
+	"flow.AccountContractRemoved": "removed",
+}
+
+// Converter converts Flow Events into Rosetta Operations, staking rewards and
+// transaction fees.
 type Converter struct {
 	deposit    flow.EventType
 	withdrawal flow.EventType
+	minted     flow.EventType
+	burned     flow.EventType
+
+	rewards          flow.EventType
+	delegatorRewards flow.EventType
+
+	fees flow.EventType
+
+	// collector is the address of the account holding the vault into which
+	// transaction fees are deposited.
+	collector flow.Address
+
+	epochSetup  flow.EventType
+	epochCommit flow.EventType
+
+	// legacy maps the event types of contracts before they were migrated to
+	// the event types of the contracts at their current addresses and names.
+	legacy map[flow.EventType]flow.EventType
 }
 
-// New instantiates and returns a new converter using the given Generator.
-func New(gen Generator) (*Converter, error) {
+// New instantiates and returns a new converter using the given Generator and the
+// parameters of the chain, which provide the address of the account that collects
+// transaction fees. The optional legacy generators are used to also convert the
+// events emitted by core contracts before they were migrated.
+func New(gen Generator, params dps.Params, legacy ...Generator) (*Converter, error) {
 	deposit, err := gen.TokensDeposited(dps.FlowSymbol)
 	if err != nil {
 		return nil, fmt.Errorf("could not generate deposit event type: %w", err)
@@ -44,10 +75,100 @@ func New(gen Generator) (*Converter, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not generate withdrawal event type: %w", err)
 	}
+	minted, err := gen.TokensMinted(dps.FlowSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate minted event type: %w", err)
+	}
+	burned, err := gen.TokensBurned(dps.FlowSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate burned event type: %w", err)
+	}
+	rewards, err := gen.RewardsPaid()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate rewards event type: %w", err)
+	}
+	delegatorRewards, err := gen.DelegatorRewardsPaid()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate delegator rewards event type: %w", err)
+	}
+	fees, err := gen.FeesDeducted()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate fees event type: %w", err)
+	}
+	epochSetup, err := gen.EpochSetup()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate epoch setup event type: %w", err)
+	}
+	epochCommit, err := gen.EpochCommit()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate epoch commit event type: %w", err)
+	}
 
 	c := Converter{
 		deposit:    flow.EventType(deposit),
 		withdrawal: flow.EventType(withdrawal),
+		minted:     flow.EventType(minted),
+		burned:     flow.EventType(burned),
+
+		rewards:          flow.EventType(rewards),
+		delegatorRewards: flow.EventType(delegatorRewards),
+
+		fees: flow.EventType(fees),
+
+		collector: params.FlowFees,
+
+		epochSetup:  flow.EventType(epochSetup),
+		epochCommit: flow.EventType(epochCommit),
+
+		legacy: make(map[flow.EventType]flow.EventType),
+	}
+
+	for _, gen := range legacy {
+		deposit, err := gen.TokensDeposited(dps.FlowSymbol)
+		if err != nil {
+			return nil, fmt.Errorf("could not generate legacy deposit event type: %w", err)
+		}
+		withdrawal, err := gen.TokensWithdrawn(dps.FlowSymbol)
+		if err != nil {
+			return nil, fmt.Errorf("could not generate legacy withdrawal event type: %w", err)
+		}
+		minted, err := gen.TokensMinted(dps.FlowSymbol)
+		if err != nil {
+			return nil, fmt.Errorf("could not generate legacy minted event type: %w", err)
+		}
+		burned, err := gen.TokensBurned(dps.FlowSymbol)
+		if err != nil {
+			return nil, fmt.Errorf("could not generate legacy burned event type: %w", err)
+		}
+		rewards, err := gen.RewardsPaid()
+		if err != nil {
+			return nil, fmt.Errorf("could not generate legacy rewards event type: %w", err)
+		}
+		delegatorRewards, err := gen.DelegatorRewardsPaid()
+		if err != nil {
+			return nil, fmt.Errorf("could not generate legacy delegator rewards event type: %w", err)
+		}
+		fees, err := gen.FeesDeducted()
+		if err != nil {
+			return nil, fmt.Errorf("could not generate legacy fees event type: %w", err)
+		}
+		epochSetup, err := gen.EpochSetup()
+		if err != nil {
+			return nil, fmt.Errorf("could not generate legacy epoch setup event type: %w", err)
+		}
+		epochCommit, err := gen.EpochCommit()
+		if err != nil {
+			return nil, fmt.Errorf("could not generate legacy epoch commit event type: %w", err)
+		}
+		c.legacy[flow.EventType(deposit)] = c.deposit
+		c.legacy[flow.EventType(withdrawal)] = c.withdrawal
+		c.legacy[flow.EventType(minted)] = c.minted
+		c.legacy[flow.EventType(burned)] = c.burned
+		c.legacy[flow.EventType(rewards)] = c.rewards
+		c.legacy[flow.EventType(delegatorRewards)] = c.delegatorRewards
+		c.legacy[flow.EventType(fees)] = c.fees
+		c.legacy[flow.EventType(epochSetup)] = c.epochSetup
+		c.legacy[flow.EventType(epochCommit)] = c.epochCommit
 	}
 
 	return &c, nil
@@ -66,21 +187,27 @@ func (c *Converter) EventToOperation(event flow.Event) (operation *object.Operat
 		return nil, fmt.Errorf("could not cast event: %w", err)
 	}
 
-	// Ensure that there are the correct amount of fields.
-	if len(e.Fields) != 2 {
-		return nil, fmt.Errorf("invalid number of fields (want: %d, have: %d)", 2, len(e.Fields))
+	// Minted and burned tokens change the total supply, and their events don't
+	// say which account they are minted for or burned from.
+	typ := c.current(event.Type)
+	if typ == c.minted || typ == c.burned {
+		return c.supplyOperation(event, e, typ == c.minted)
 	}
 
-	// The first field is always the amount and the second one the address.
-	// The types coming from Cadence are not native Flow types, so primitive types
-	// are needed before they can be converted into proper Flow types.
-	vAmount := e.Fields[0].ToGoValue()
-	uAmount, ok := vAmount.(uint64)
-	if !ok {
-		return nil, fmt.Errorf("could not cast amount (%T)", vAmount)
+	// Ensure that there are enough fields for an amount and an address. Vaults
+	// wrapped by other resources can emit additional fields of their own.
+	if len(e.Fields) < 2 {
+		return nil, fmt.Errorf("invalid number of fields (want: at least %d, have: %d)", 2, len(e.Fields))
 	}
 
-	vAddress := e.Fields[1].ToGoValue()
+	// The fields are identified by their types rather than by their positions,
+	// so that the account is always taken from the address in the event payload.
+	// That address is the owner of the vault that emitted the event, whatever the
+	// storage path of the vault or the resource wrapping it.
+	uAmount, vAddress, err := transferFields(e.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode event fields: %w", err)
+	}
 
 	// Sometimes an event is not associated with an account. Ignore these events
 	// as they refer to intermediary vaults.
@@ -93,8 +220,6 @@ func (c *Converter) EventToOperation(event flow.Event) (operation *object.Operat
 		return nil, fmt.Errorf("could not cast address (%T)", vAddress)
 	}
 
-	// Convert the amount to a signed integer that it can be inverted.
-	amount := int64(uAmount)
 	// Convert the address bytes into a native Flow address.
 	address := flow.Address(bAddress)
 
@@ -109,20 +234,26 @@ func (c *Converter) EventToOperation(event flow.Event) (operation *object.Operat
 		},
 	}
 
-	switch event.Type {
+	// In the case of a withdrawal, the amount is negative.
+	var negative bool
+	switch typ {
 	case c.deposit:
 		op.Type = dps.OperationTransfer
-
-	// In the case of a withdrawal, invert the amount value.
+		// Deposits into the vault of the fees account are the collection of
+		// the fees that the payers of transactions are charged, so they have
+		// their own operation type.
+		if address == c.collector {
+			op.Type = configuration.OperationFeeCollection
+		}
 	case c.withdrawal:
 		op.Type = dps.OperationTransfer
-		amount = -amount
+		negative = true
 	default:
 		return nil, retriever.ErrNotSupported
 	}
 
 	op.Amount = object.Amount{
-		Value: strconv.FormatInt(amount, 10),
+		Value: amount.FormatSigned(uAmount, negative),
 		Currency: identifier.Currency{
 			Symbol:   dps.FlowSymbol,
 			Decimals: dps.FlowDecimals,
@@ -131,3 +262,416 @@ func (c *Converter) EventToOperation(event flow.Event) (operation *object.Operat
 
 	return &op, nil
 }
+
+// supplyOperation converts an event for tokens being minted or burned into an
+// operation without an account, as the event does not name one. Minted tokens
+// have a positive amount and burned tokens a negative one, like the deposit of
+// the minted tokens and the withdrawal of the burned ones that they are folded
+// into by the retriever.
+func (c *Converter) supplyOperation(event flow.Event, e cadence.Event, minted bool) (*object.Operation, error) {
+
+	if len(e.Fields) != 1 {
+		return nil, fmt.Errorf("invalid number of fields (want: %d, have: %d)", 1, len(e.Fields))
+	}
+	vAmount := goValue(e.Fields[0])
+	uAmount, ok := vAmount.(uint64)
+	if !ok {
+		return nil, fmt.Errorf("could not cast amount (%T)", vAmount)
+	}
+
+	netIndex := uint(event.EventIndex)
+	op := object.Operation{
+		ID: identifier.Operation{
+			NetworkIndex: &netIndex,
+		},
+		Type:   configuration.OperationBurn,
+		Status: dps.StatusCompleted,
+		Amount: object.Amount{
+			Value: amount.FormatSigned(uAmount, !minted),
+			Currency: identifier.Currency{
+				Symbol:   dps.FlowSymbol,
+				Decimals: dps.FlowDecimals,
+			},
+		},
+	}
+	if minted {
+		op.Type = configuration.OperationMint
+	}
+
+	return &op, nil
+}
+
+// EventToReward converts a flow.Event for staking rewards being paid into a
+// Rosetta staking reward. The block identifier is left for the caller to fill.
+func (c *Converter) EventToReward(event flow.Event) (*object.Reward, error) {
+
+	value, err := json.Decode(event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode event: %w", err)
+	}
+	e, ok := value.(cadence.Event)
+	if !ok {
+		return nil, fmt.Errorf("could not cast event: %w", err)
+	}
+
+	// Node operator rewards have the node ID and amount as fields, while
+	// delegator rewards have the delegator ID in between.
+	var reward object.Reward
+	var vAmount interface{}
+	switch c.current(event.Type) {
+	case c.rewards:
+		if len(e.Fields) != 2 {
+			return nil, fmt.Errorf("invalid number of fields (want: %d, have: %d)", 2, len(e.Fields))
+		}
+		vAmount = goValue(e.Fields[1])
+
+	case c.delegatorRewards:
+		if len(e.Fields) != 3 {
+			return nil, fmt.Errorf("invalid number of fields (want: %d, have: %d)", 3, len(e.Fields))
+		}
+		vDelegatorID := goValue(e.Fields[1])
+		delegatorID, ok := vDelegatorID.(uint32)
+		if !ok {
+			return nil, fmt.Errorf("could not cast delegator ID (%T)", vDelegatorID)
+		}
+		reward.DelegatorID = &delegatorID
+		vAmount = goValue(e.Fields[2])
+
+	default:
+		return nil, retriever.ErrNotSupported
+	}
+
+	vNodeID := goValue(e.Fields[0])
+	nodeID, ok := vNodeID.(string)
+	if !ok {
+		return nil, fmt.Errorf("could not cast node ID (%T)", vNodeID)
+	}
+	units, ok := vAmount.(uint64)
+	if !ok {
+		return nil, fmt.Errorf("could not cast amount (%T)", vAmount)
+	}
+
+	reward.NodeID = nodeID
+	reward.Amount = object.Amount{
+		Value: amount.Format(units),
+		Currency: identifier.Currency{
+			Symbol:   dps.FlowSymbol,
+			Decimals: dps.FlowDecimals,
+		},
+	}
+
+	return &reward, nil
+}
+
+// EventToFees converts a flow.Event for deducted transaction fees into the fees
+// paid by the transaction, along with the inclusion and execution efforts that
+// the fees were computed from.
+func (c *Converter) EventToFees(event flow.Event) (*object.TransactionFees, error) {
+
+	if c.current(event.Type) != c.fees {
+		return nil, retriever.ErrNotSupported
+	}
+
+	value, err := json.Decode(event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode event: %w", err)
+	}
+	e, ok := value.(cadence.Event)
+	if !ok {
+		return nil, fmt.Errorf("could not cast event: %w", err)
+	}
+
+	// The fees event has the amount, the inclusion effort and the execution
+	// effort as fields, all of them fixed-point numbers.
+	if len(e.Fields) != 3 {
+		return nil, fmt.Errorf("invalid number of fields (want: %d, have: %d)", 3, len(e.Fields))
+	}
+	values := make([]cadence.UFix64, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		vNumber := goValue(field)
+		number, ok := vNumber.(uint64)
+		if !ok {
+			return nil, fmt.Errorf("could not cast fees field (%T)", vNumber)
+		}
+		values = append(values, cadence.UFix64(number))
+	}
+
+	fees := object.TransactionFees{
+		Amount: object.Amount{
+			Value: amount.FromUFix64(values[0]),
+			Currency: identifier.Currency{
+				Symbol:   dps.FlowSymbol,
+				Decimals: dps.FlowDecimals,
+			},
+		},
+		InclusionEffort: values[1].String(),
+		ExecutionEffort: values[2].String(),
+	}
+
+	return &fees, nil
+}
+
+// EventToServiceEvent converts a flow.Event for the setup or the commit of an
+// epoch into a Rosetta service event with its decoded payload. The fields are
+// read by position, in the order in which the epoch contract declares them.
+// Additional fields are ignored, so that new fields added to the events by an
+// upgrade of the contract don't prevent their conversion.
+func (c *Converter) EventToServiceEvent(event flow.Event) (*object.ServiceEvent, error) {
+
+	typ := c.current(event.Type)
+	if typ != c.epochSetup && typ != c.epochCommit {
+		return nil, retriever.ErrNotSupported
+	}
+
+	value, err := json.Decode(event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode event: %w", err)
+	}
+	e, ok := value.(cadence.Event)
+	if !ok {
+		return nil, fmt.Errorf("could not cast event: %w", err)
+	}
+
+	if typ == c.epochSetup {
+		return epochSetup(e.Fields)
+	}
+	return epochCommit(e.Fields)
+}
+
+// EventToAddress converts a flow.Event emitted by the Flow virtual machine for
+// an account, such as the creation of the account or a key being added to it,
+// into the address of the account, which is always the first field.
+func (c *Converter) EventToAddress(event flow.Event) (flow.Address, error) {
+
+	value, err := json.Decode(event.Payload)
+	if err != nil {
+		return flow.EmptyAddress, fmt.Errorf("could not decode event: %w", err)
+	}
+	e, ok := value.(cadence.Event)
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("could not cast event: %w", err)
+	}
+
+	if len(e.Fields) == 0 {
+		return flow.EmptyAddress, fmt.Errorf("missing address field")
+	}
+	address, ok := e.Fields[0].(cadence.Address)
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("could not cast address (%T)", e.Fields[0])
+	}
+
+	return flow.Address(address), nil
+}
+
+// EventToContract converts a flow.Event for a contract being deployed, updated
+// or removed into the change to the contract. The fields of these events are the
+// address of the account, the hash of the code and the name of the contract.
+func (c *Converter) EventToContract(event flow.Event) (*object.ContractChange, error) {
+
+	action, ok := contractActions[event.Type]
+	if !ok {
+		return nil, retriever.ErrNotSupported
+	}
+
+	value, err := json.Decode(event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode event: %w", err)
+	}
+	e, ok := value.(cadence.Event)
+	if !ok {
+		return nil, fmt.Errorf("could not cast event: %w", err)
+	}
+
+	if len(e.Fields) != 3 {
+		return nil, fmt.Errorf("invalid number of fields (want: %d, have: %d)", 3, len(e.Fields))
+	}
+	address, ok := e.Fields[0].(cadence.Address)
+	if !ok {
+		return nil, fmt.Errorf("could not cast address (%T)", e.Fields[0])
+	}
+	name, ok := e.Fields[2].(cadence.String)
+	if !ok {
+		return nil, fmt.Errorf("could not cast contract name (%T)", e.Fields[2])
+	}
+
+	contract := object.ContractChange{
+		Address: flow.Address(address).String(),
+		Name:    string(name),
+		Action:  action,
+	}
+
+	return &contract, nil
+}
+
+// epochSetup decodes the fields of an epoch setup event, which are the counter,
+// the participants, the first and final views, the collector clusters, the
+// random source and the final views of the three phases of the distributed key
+// generation.
+func epochSetup(fields []cadence.Value) (*object.ServiceEvent, error) {
+
+	if len(fields) < 9 {
+		return nil, fmt.Errorf("insufficient number of fields (want: %d, have: %d)", 9, len(fields))
+	}
+
+	var views []uint64
+	for _, index := range []int{0, 2, 3, 6, 7, 8} {
+		view, ok := fields[index].(cadence.UInt64)
+		if !ok {
+			return nil, fmt.Errorf("could not cast field %d (%T)", index, fields[index])
+		}
+		views = append(views, uint64(view))
+	}
+	participants, ok := fields[1].(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("could not cast participants (%T)", fields[1])
+	}
+	clusters, ok := fields[4].(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("could not cast clusters (%T)", fields[4])
+	}
+	source, ok := fields[5].(cadence.String)
+	if !ok {
+		return nil, fmt.Errorf("could not cast random source (%T)", fields[5])
+	}
+
+	service := object.ServiceEvent{
+		Type:    flow.ServiceEventSetup,
+		Counter: views[0],
+		Setup: &object.EpochSetup{
+			FirstView:          views[1],
+			FinalView:          views[2],
+			DKGPhaseFinalViews: views[3:],
+			RandomSource:       string(source),
+			Participants:       uint(len(participants.Values)),
+			Clusters:           uint(len(clusters.Values)),
+		},
+	}
+
+	return &service, nil
+}
+
+// epochCommit decodes the fields of an epoch commit event, which are the
+// counter, the quorum certificates of the collector clusters and the keys
+// resulting from the distributed key generation, with the group key first.
+func epochCommit(fields []cadence.Value) (*object.ServiceEvent, error) {
+
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("insufficient number of fields (want: %d, have: %d)", 3, len(fields))
+	}
+
+	counter, ok := fields[0].(cadence.UInt64)
+	if !ok {
+		return nil, fmt.Errorf("could not cast counter (%T)", fields[0])
+	}
+	qcs, ok := fields[1].(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("could not cast cluster QCs (%T)", fields[1])
+	}
+	keys, ok := fields[2].(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("could not cast DKG keys (%T)", fields[2])
+	}
+	if len(keys.Values) == 0 {
+		return nil, fmt.Errorf("missing DKG group key")
+	}
+	groupKey, ok := keys.Values[0].(cadence.String)
+	if !ok {
+		return nil, fmt.Errorf("could not cast DKG group key (%T)", keys.Values[0])
+	}
+
+	service := object.ServiceEvent{
+		Type:    flow.ServiceEventCommit,
+		Counter: uint64(counter),
+		Commit: &object.EpochCommit{
+			ClusterQCs:      uint(len(qcs.Values)),
+			DKGGroupKey:     string(groupKey),
+			DKGParticipants: uint(len(keys.Values) - 1),
+		},
+	}
+
+	return &service, nil
+}
+
+// transferFields returns the amount and the address of a deposit or withdrawal
+// event. The amount is its only numeric field, and the address its only field
+// which is an address or an optional address. Fields are identified by the types
+// of their values, unwrapping optionals, so that an optional field of another
+// type or a nested composite value is never mistaken for the address. Decoded
+// event payloads do not carry the declared types of their fields, so an optional
+// without a value is only taken as the address when no field holds an address,
+// in which case the vault is not owned by an account and the address is nil.
+func transferFields(fields []cadence.Value) (uint64, interface{}, error) {
+
+	var value, address, empty cadence.Value
+	for _, field := range fields {
+		switch unwrapType(field.Type()).(type) {
+		case cadence.UFix64Type, cadence.UInt64Type:
+			if value != nil {
+				return 0, nil, fmt.Errorf("duplicate amount field")
+			}
+			value = field
+		case cadence.AddressType:
+			if address != nil {
+				return 0, nil, fmt.Errorf("duplicate address field")
+			}
+			address = field
+		case cadence.NeverType:
+			empty = field
+		}
+	}
+	if value == nil {
+		return 0, nil, fmt.Errorf("missing amount field")
+	}
+	if address == nil {
+		address = empty
+	}
+	if address == nil {
+		return 0, nil, fmt.Errorf("missing address field")
+	}
+
+	// The types coming from Cadence are not native Flow types, so primitive types
+	// are needed before they can be converted into proper Flow types.
+	vAmount := goValue(value)
+	uAmount, ok := vAmount.(uint64)
+	if !ok {
+		return 0, nil, fmt.Errorf("could not cast amount (%T)", vAmount)
+	}
+
+	return uAmount, goValue(address), nil
+}
+
+// unwrapType returns the inner type of optional types, at any depth.
+func unwrapType(typ cadence.Type) cadence.Type {
+	for {
+		optional, ok := typ.(cadence.OptionalType)
+		if !ok {
+			return typ
+		}
+		typ = optional.Type
+	}
+}
+
+// goValue returns the Go value of the given Cadence value, unwrapping optionals
+// at any depth. It returns nil for optionals without a value.
+func goValue(value cadence.Value) interface{} {
+	for {
+		optional, ok := value.(cadence.Optional)
+		if !ok {
+			return value.ToGoValue()
+		}
+		if optional.Value == nil {
+			return nil
+		}
+		value = optional.Value
+	}
+}
+
+// current returns the event type of the current core contracts that corresponds
+// to the given event type, which might have been emitted before a migration.
+func (c *Converter) current(typ flow.EventType) flow.EventType {
+	current, ok := c.legacy[typ]
+	if ok {
+		return current
+	}
+	return typ
+}
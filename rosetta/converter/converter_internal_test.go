@@ -15,6 +15,8 @@
 package converter
 
 import (
+	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,6 +28,7 @@ import (
 	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
 	"github.com/optakt/flow-rosetta/rosetta/object"
 	"github.com/optakt/flow-rosetta/rosetta/retriever"
@@ -33,6 +36,13 @@ import (
 )
 
 func TestNew(t *testing.T) {
+	params := dps.Params{
+		FlowFees: mocks.GenericAddress(0),
+		Tokens: map[string]dps.Token{
+			dps.FlowSymbol: {Symbol: dps.FlowSymbol, Address: mocks.GenericAddress(1)},
+		},
+	}
+
 	t.Run("nominal case", func(t *testing.T) {
 		generator := mocks.BaselineGenerator(t)
 		generator.TokensDepositedFunc = func(symbol string) (string, error) {
@@ -44,11 +54,68 @@ func TestNew(t *testing.T) {
 			return string(mocks.GenericEventType(1)), nil
 		}
 
-		cvt, err := New(generator)
+		cvt, err := New(generator, params)
 
 		require.NoError(t, err)
 		assert.Equal(t, cvt.deposit, mocks.GenericEventType(0))
 		assert.Equal(t, cvt.withdrawal, mocks.GenericEventType(1))
+		assert.Equal(t, cvt.rewards, mocks.GenericEventType(2))
+		assert.Equal(t, cvt.delegatorRewards, mocks.GenericEventType(3))
+		assert.Equal(t, cvt.fees, mocks.GenericEventType(8))
+		assert.Equal(t, cvt.collector, mocks.GenericAddress(0))
+		assert.Equal(t, cvt.minted, mocks.GenericEventType(12))
+		assert.Equal(t, cvt.burned, mocks.GenericEventType(13))
+		assert.Equal(t, cvt.epochSetup, mocks.GenericEventType(10))
+		assert.Equal(t, cvt.epochCommit, mocks.GenericEventType(11))
+	})
+
+	t.Run("maps legacy event types", func(t *testing.T) {
+		legacy := mocks.BaselineGenerator(t)
+		legacy.TokensDepositedFunc = func(string) (string, error) {
+			return string(mocks.GenericEventType(4)), nil
+		}
+		legacy.TokensWithdrawnFunc = func(string) (string, error) {
+			return string(mocks.GenericEventType(5)), nil
+		}
+		legacy.RewardsPaidFunc = func() (string, error) {
+			return string(mocks.GenericEventType(6)), nil
+		}
+		legacy.DelegatorRewardsPaidFunc = func() (string, error) {
+			return string(mocks.GenericEventType(7)), nil
+		}
+		legacy.FeesDeductedFunc = func() (string, error) {
+			return string(mocks.GenericEventType(9)), nil
+		}
+		legacy.TokensMintedFunc = func(string) (string, error) {
+			return string(mocks.GenericEventType(14)), nil
+		}
+		legacy.TokensBurnedFunc = func(string) (string, error) {
+			return string(mocks.GenericEventType(15)), nil
+		}
+
+		cvt, err := New(mocks.BaselineGenerator(t), params, legacy)
+
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericEventType(0), cvt.current(mocks.GenericEventType(4)))
+		assert.Equal(t, mocks.GenericEventType(1), cvt.current(mocks.GenericEventType(5)))
+		assert.Equal(t, mocks.GenericEventType(2), cvt.current(mocks.GenericEventType(6)))
+		assert.Equal(t, mocks.GenericEventType(3), cvt.current(mocks.GenericEventType(7)))
+		assert.Equal(t, mocks.GenericEventType(8), cvt.current(mocks.GenericEventType(9)))
+		assert.Equal(t, mocks.GenericEventType(12), cvt.current(mocks.GenericEventType(14)))
+		assert.Equal(t, mocks.GenericEventType(13), cvt.current(mocks.GenericEventType(15)))
+		assert.Equal(t, mocks.GenericEventType(0), cvt.current(mocks.GenericEventType(0)))
+	})
+
+	t.Run("handles legacy generator failure", func(t *testing.T) {
+		legacy := mocks.BaselineGenerator(t)
+		legacy.TokensDepositedFunc = func(string) (string, error) {
+			return "", mocks.GenericError
+		}
+
+		cvt, err := New(mocks.BaselineGenerator(t), params, legacy)
+
+		assert.Error(t, err)
+		assert.Nil(t, cvt)
 	})
 
 	t.Run("handles generator failure for deposit event type", func(t *testing.T) {
@@ -57,7 +124,7 @@ func TestNew(t *testing.T) {
 			return "", mocks.GenericError
 		}
 
-		cvt, err := New(generator)
+		cvt, err := New(generator, params)
 
 		assert.Error(t, err)
 		assert.Nil(t, cvt)
@@ -69,7 +136,91 @@ func TestNew(t *testing.T) {
 			return "", mocks.GenericError
 		}
 
-		cvt, err := New(generator)
+		cvt, err := New(generator, params)
+
+		assert.Error(t, err)
+		assert.Nil(t, cvt)
+	})
+
+	t.Run("handles generator failure for minted event type", func(t *testing.T) {
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensMintedFunc = func(symbol string) (string, error) {
+			return "", mocks.GenericError
+		}
+
+		cvt, err := New(generator, params)
+
+		assert.Error(t, err)
+		assert.Nil(t, cvt)
+	})
+
+	t.Run("handles generator failure for burned event type", func(t *testing.T) {
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensBurnedFunc = func(symbol string) (string, error) {
+			return "", mocks.GenericError
+		}
+
+		cvt, err := New(generator, params)
+
+		assert.Error(t, err)
+		assert.Nil(t, cvt)
+	})
+
+	t.Run("handles generator failure for rewards event type", func(t *testing.T) {
+		generator := mocks.BaselineGenerator(t)
+		generator.RewardsPaidFunc = func() (string, error) {
+			return "", mocks.GenericError
+		}
+
+		cvt, err := New(generator, params)
+
+		assert.Error(t, err)
+		assert.Nil(t, cvt)
+	})
+
+	t.Run("handles generator failure for delegator rewards event type", func(t *testing.T) {
+		generator := mocks.BaselineGenerator(t)
+		generator.DelegatorRewardsPaidFunc = func() (string, error) {
+			return "", mocks.GenericError
+		}
+
+		cvt, err := New(generator, params)
+
+		assert.Error(t, err)
+		assert.Nil(t, cvt)
+	})
+
+	t.Run("handles generator failure for fees event type", func(t *testing.T) {
+		generator := mocks.BaselineGenerator(t)
+		generator.FeesDeductedFunc = func() (string, error) {
+			return "", mocks.GenericError
+		}
+
+		cvt, err := New(generator, params)
+
+		assert.Error(t, err)
+		assert.Nil(t, cvt)
+	})
+
+	t.Run("handles generator failure for epoch setup event type", func(t *testing.T) {
+		generator := mocks.BaselineGenerator(t)
+		generator.EpochSetupFunc = func() (string, error) {
+			return "", mocks.GenericError
+		}
+
+		cvt, err := New(generator, params)
+
+		assert.Error(t, err)
+		assert.Nil(t, cvt)
+	})
+
+	t.Run("handles generator failure for epoch commit event type", func(t *testing.T) {
+		generator := mocks.BaselineGenerator(t)
+		generator.EpochCommitFunc = func() (string, error) {
+			return "", mocks.GenericError
+		}
+
+		cvt, err := New(generator, params)
 
 		assert.Error(t, err)
 		assert.Nil(t, cvt)
@@ -121,6 +272,15 @@ func TestConverter_EventToOperation(t *testing.T) {
 	).WithType(withdrawalType)
 	withdrawalEventPayload := json.MustEncode(withdrawalEvent)
 
+	collector := flow.HexToAddress("0a0b0c0d0e0f1011")
+	feeEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.NewUInt64(42),
+			cadence.NewAddress(collector),
+		},
+	).WithType(depositType)
+	feeEventPayload := json.MustEncode(feeEvent)
+
 	depositNetIndex := uint(1)
 	testDepositOp := object.Operation{
 		ID: identifier.Operation{
@@ -158,6 +318,44 @@ func TestConverter_EventToOperation(t *testing.T) {
 		},
 	}
 
+	testFeeOp := testDepositOp
+	testFeeOp.Type = configuration.OperationFeeCollection
+	testFeeOp.AccountID = identifier.Account{Address: collector.String()}
+
+	// Minted and burned events only have the amount as field, so their
+	// operations have no account.
+	mintedType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(12)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "amount",
+				Type:       cadence.UFix64Type{},
+			},
+		},
+	}
+	mintedEvent := cadence.NewEvent([]cadence.Value{cadence.UFix64(42)}).WithType(mintedType)
+	mintedEventPayload := json.MustEncode(mintedEvent)
+	burnedType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(13)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "amount",
+				Type:       cadence.UFix64Type{},
+			},
+		},
+	}
+	burnedEvent := cadence.NewEvent([]cadence.Value{cadence.UFix64(42)}).WithType(burnedType)
+	burnedEventPayload := json.MustEncode(burnedEvent)
+
+	testBurnOp := testWithdrawalOp
+	testBurnOp.Type = configuration.OperationBurn
+	testBurnOp.AccountID = identifier.Account{}
+	testMintOp := testDepositOp
+	testMintOp.Type = configuration.OperationMint
+	testMintOp.AccountID = identifier.Account{}
+
 	id, err := flow.HexStringToIdentifier("a4c4194eae1a2dd0de4f4d51a884db4255bf265a40ddd98477a1d60ef45909ec")
 	require.NoError(t, err)
 
@@ -232,6 +430,146 @@ func TestConverter_EventToOperation(t *testing.T) {
 	).WithType(missingAddressEventType)
 	missingAddressEventPayload := json.MustEncode(missingAddressEvent)
 
+	// Wrapped vaults emit the same events, with the owner of the vault as an
+	// optional address, and the amount as a fixed-point value.
+	wrappedType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(0)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "to",
+				Type:       cadence.OptionalType{Type: cadence.AddressType{}},
+			},
+			{
+				Identifier: "amount",
+				Type:       cadence.UFix64Type{},
+			},
+		},
+	}
+	wrappedEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.NewOptional(cadence.NewAddress([8]byte{1, 2, 3, 4, 5, 6, 7, 8})),
+			cadence.UFix64(42),
+		},
+	).WithType(wrappedType)
+	wrappedEventPayload := json.MustEncode(wrappedEvent)
+
+	optionalAmountType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(0)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "amount",
+				Type:       cadence.OptionalType{Type: cadence.UFix64Type{}},
+			},
+			{
+				Identifier: "to",
+				Type:       cadence.OptionalType{Type: cadence.AddressType{}},
+			},
+		},
+	}
+	optionalAmountEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.NewOptional(cadence.UFix64(42)),
+			cadence.NewOptional(cadence.NewAddress([8]byte{1, 2, 3, 4, 5, 6, 7, 8})),
+		},
+	).WithType(optionalAmountType)
+	optionalAmountEventPayload := json.MustEncode(optionalAmountEvent)
+
+	// An optional field that holds another type of value must not be taken
+	// for the address of the vault.
+	optionalStringType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(0)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "amount",
+				Type:       cadence.UFix64Type{},
+			},
+			{
+				Identifier: "memo",
+				Type:       cadence.OptionalType{Type: cadence.StringType{}},
+			},
+		},
+	}
+	optionalStringEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.UFix64(42),
+			cadence.NewOptional(cadence.String("memo")),
+		},
+	).WithType(optionalStringType)
+	optionalStringEventPayload := json.MustEncode(optionalStringEvent)
+
+	// Wrapped vaults can emit additional optional fields of their own, which
+	// may or may not hold a value, next to the optional address of the owner.
+	extraOptionalType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(0)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "amount",
+				Type:       cadence.UFix64Type{},
+			},
+			{
+				Identifier: "to",
+				Type:       cadence.OptionalType{Type: cadence.AddressType{}},
+			},
+			{
+				Identifier: "memo",
+				Type:       cadence.OptionalType{Type: cadence.StringType{}},
+			},
+			{
+				Identifier: "tag",
+				Type:       cadence.OptionalType{Type: cadence.StringType{}},
+			},
+		},
+	}
+	extraOptionalEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.UFix64(42),
+			cadence.NewOptional(cadence.NewAddress([8]byte{1, 2, 3, 4, 5, 6, 7, 8})),
+			cadence.NewOptional(cadence.String("memo")),
+			cadence.NewOptional(nil),
+		},
+	).WithType(extraOptionalType)
+	extraOptionalEventPayload := json.MustEncode(extraOptionalEvent)
+
+	duplicateAddressEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.UFix64(42),
+			cadence.NewOptional(cadence.NewAddress([8]byte{1, 2, 3, 4, 5, 6, 7, 8})),
+			cadence.NewAddress([8]byte{2, 3, 4, 5, 6, 7, 8, 9}),
+		},
+	).WithType(threeFieldsType)
+	duplicateAddressEventPayload := json.MustEncode(duplicateAddressEvent)
+
+	singleFieldType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(0)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "amount",
+				Type:       cadence.UFix64Type{},
+			},
+		},
+	}
+	singleFieldEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.UFix64(42),
+		},
+	).WithType(singleFieldType)
+	singleFieldEventPayload := json.MustEncode(singleFieldEvent)
+
+	largeWithdrawalEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.NewUInt64(math.MaxUint64),
+			cadence.NewAddress([8]byte{2, 3, 4, 5, 6, 7, 8, 9}),
+		},
+	).WithType(withdrawalType)
+	largeWithdrawalEventPayload := json.MustEncode(largeWithdrawalEvent)
+	testLargeWithdrawalOp := testWithdrawalOp
+	testLargeWithdrawalOp.Amount.Value = "-18446744073709551615"
+
 	nilAddressEvent := cadence.NewEvent(
 		[]cadence.Value{
 			cadence.NewUInt64(42),
@@ -276,79 +614,1080 @@ func TestConverter_EventToOperation(t *testing.T) {
 			wantOperation: &testWithdrawalOp,
 		},
 		{
-			name: "unsupported event type",
+			name: "nominal case with deposit into fees vault",
 
 			event: flow.Event{
 				TransactionID: id,
-				Type:          flow.EventType("irrelevant"),
-				Payload:       withdrawalEventPayload,
+				Type:          mocks.GenericEventType(0),
+				Payload:       feeEventPayload,
+				EventIndex:    1,
 			},
 
-			wantErr:      assert.Error,
-			wantSentinel: retriever.ErrNotSupported,
+			wantErr:       assert.NoError,
+			wantOperation: &testFeeOp,
 		},
 		{
-			name: "wrong amount of fields",
+			name: "nominal case with withdrawal above signed integer range",
 
 			event: flow.Event{
-				Type:    mocks.GenericEventType(0),
-				Payload: threeFieldsEventPayload,
+				TransactionID: id,
+				Type:          mocks.GenericEventType(1),
+				Payload:       largeWithdrawalEventPayload,
+				EventIndex:    2,
 			},
 
-			wantErr: assert.Error,
+			wantErr:       assert.NoError,
+			wantOperation: &testLargeWithdrawalOp,
 		},
 		{
-			name: "missing amount field",
+			name: "nominal case with legacy deposit event",
 
 			event: flow.Event{
-				Type:    mocks.GenericEventType(0),
-				Payload: missingAmountEventPayload,
+				TransactionID: id,
+				Type:          mocks.GenericEventType(4),
+				Payload:       depositEventPayload,
+				EventIndex:    1,
 			},
 
-			wantErr: assert.Error,
+			wantErr:       assert.NoError,
+			wantOperation: &testDepositOp,
 		},
 		{
-			name: "missing address field",
+			name: "nominal case with optional address before amount",
 
 			event: flow.Event{
-				Type:    mocks.GenericEventType(0),
-				Payload: missingAddressEventPayload,
+				TransactionID: id,
+				Type:          mocks.GenericEventType(0),
+				Payload:       wrappedEventPayload,
+				EventIndex:    1,
 			},
 
-			wantErr: assert.Error,
+			wantErr:       assert.NoError,
+			wantOperation: &testDepositOp,
 		},
 		{
-			name: "nil address field",
+			name: "nominal case with optional amount",
 
 			event: flow.Event{
 				TransactionID: id,
 				Type:          mocks.GenericEventType(0),
-				Payload:       nilAddressPayload,
+				Payload:       optionalAmountEventPayload,
+				EventIndex:    1,
 			},
 
-			wantErr:      assert.Error,
-			wantSentinel: retriever.ErrNoAddress,
+			wantErr:       assert.NoError,
+			wantOperation: &testDepositOp,
 		},
-	}
+		{
+			name: "nominal case with extra optional fields",
 
-	for _, test := range tests {
-		test := test
-		t.Run(test.name, func(t *testing.T) {
-			t.Parallel()
+			event: flow.Event{
+				TransactionID: id,
+				Type:          mocks.GenericEventType(0),
+				Payload:       extraOptionalEventPayload,
+				EventIndex:    1,
+			},
 
-			cvt := &Converter{
-				deposit:    mocks.GenericEventType(0),
-				withdrawal: mocks.GenericEventType(1),
-			}
+			wantErr:       assert.NoError,
+			wantOperation: &testDepositOp,
+		},
+		{
+			name: "unsupported event type",
 
-			got, err := cvt.EventToOperation(test.event)
+			event: flow.Event{
+				TransactionID: id,
+				Type:          flow.EventType("irrelevant"),
+				Payload:       withdrawalEventPayload,
+			},
 
-			test.wantErr(t, err)
-			if test.wantSentinel != nil {
-				assert.ErrorIs(t, err, test.wantSentinel)
+			wantErr:      assert.Error,
+			wantSentinel: retriever.ErrNotSupported,
+		},
+		{
+			name: "wrong amount of fields",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(0),
+				Payload: threeFieldsEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "nominal case with minted event",
+
+			event: flow.Event{
+				TransactionID: id,
+				Type:          mocks.GenericEventType(12),
+				Payload:       mintedEventPayload,
+				EventIndex:    1,
+			},
+
+			wantErr:       assert.NoError,
+			wantOperation: &testMintOp,
+		},
+		{
+			name: "nominal case with burned event",
+
+			event: flow.Event{
+				TransactionID: id,
+				Type:          mocks.GenericEventType(13),
+				Payload:       burnedEventPayload,
+				EventIndex:    2,
+			},
+
+			wantErr:       assert.NoError,
+			wantOperation: &testBurnOp,
+		},
+		{
+			name: "burned event with invalid number of fields",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(13),
+				Payload: depositEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "missing amount field",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(0),
+				Payload: missingAmountEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "missing address field",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(0),
+				Payload: missingAddressEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "too few fields",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(0),
+				Payload: singleFieldEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "duplicate address field",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(0),
+				Payload: duplicateAddressEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "optional field of other type instead of address",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(0),
+				Payload: optionalStringEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "nil address field",
+
+			event: flow.Event{
+				TransactionID: id,
+				Type:          mocks.GenericEventType(0),
+				Payload:       nilAddressPayload,
+			},
+
+			wantErr:      assert.Error,
+			wantSentinel: retriever.ErrNoAddress,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cvt := &Converter{
+				deposit:    mocks.GenericEventType(0),
+				withdrawal: mocks.GenericEventType(1),
+				minted:     mocks.GenericEventType(12),
+				burned:     mocks.GenericEventType(13),
+				collector:  collector,
+				legacy: map[flow.EventType]flow.EventType{
+					mocks.GenericEventType(4): mocks.GenericEventType(0),
+				},
+			}
+
+			got, err := cvt.EventToOperation(test.event)
+
+			test.wantErr(t, err)
+			if test.wantSentinel != nil {
+				assert.ErrorIs(t, err, test.wantSentinel)
 			}
 
 			assert.Equal(t, test.wantOperation, got)
 		})
 	}
 }
+
+func TestConverter_EventToReward(t *testing.T) {
+	rewardsType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(2)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "nodeID",
+				Type:       cadence.StringType{},
+			},
+			{
+				Identifier: "amount",
+				Type:       cadence.UFix64Type{},
+			},
+		},
+	}
+	rewardsEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.String(mocks.GenericNodeID(0).String()),
+			cadence.UFix64(42),
+		},
+	).WithType(rewardsType)
+	rewardsEventPayload := json.MustEncode(rewardsEvent)
+
+	delegatorRewardsType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(3)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "nodeID",
+				Type:       cadence.StringType{},
+			},
+			{
+				Identifier: "delegatorID",
+				Type:       cadence.UInt32Type{},
+			},
+			{
+				Identifier: "amount",
+				Type:       cadence.UFix64Type{},
+			},
+		},
+	}
+	delegatorRewardsEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.String(mocks.GenericNodeID(0).String()),
+			cadence.NewUInt32(7),
+			cadence.UFix64(42),
+		},
+	).WithType(delegatorRewardsType)
+	delegatorRewardsEventPayload := json.MustEncode(delegatorRewardsEvent)
+
+	optionalDelegatorRewardsEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.String(mocks.GenericNodeID(0).String()),
+			cadence.NewOptional(cadence.NewUInt32(7)),
+			cadence.NewOptional(cadence.UFix64(42)),
+		},
+	).WithType(delegatorRewardsType)
+	optionalDelegatorRewardsEventPayload := json.MustEncode(optionalDelegatorRewardsEvent)
+
+	invalidNodeIDEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.NewUInt64(42),
+			cadence.UFix64(42),
+		},
+	).WithType(rewardsType)
+	invalidNodeIDEventPayload := json.MustEncode(invalidNodeIDEvent)
+
+	amount := object.Amount{
+		Value: "42",
+		Currency: identifier.Currency{
+			Symbol:   dps.FlowSymbol,
+			Decimals: dps.FlowDecimals,
+		},
+	}
+	delegatorID := uint32(7)
+
+	tests := []struct {
+		name string
+
+		event flow.Event
+
+		wantErr      assert.ErrorAssertionFunc
+		wantSentinel error
+		wantReward   *object.Reward
+	}{
+		{
+			name: "nominal case with node rewards event",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(2),
+				Payload: rewardsEventPayload,
+			},
+
+			wantErr: assert.NoError,
+			wantReward: &object.Reward{
+				NodeID: mocks.GenericNodeID(0).String(),
+				Amount: amount,
+			},
+		},
+		{
+			name: "nominal case with delegator rewards event",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(3),
+				Payload: delegatorRewardsEventPayload,
+			},
+
+			wantErr: assert.NoError,
+			wantReward: &object.Reward{
+				NodeID:      mocks.GenericNodeID(0).String(),
+				DelegatorID: &delegatorID,
+				Amount:      amount,
+			},
+		},
+		{
+			name: "nominal case with optional delegator rewards fields",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(3),
+				Payload: optionalDelegatorRewardsEventPayload,
+			},
+
+			wantErr: assert.NoError,
+			wantReward: &object.Reward{
+				NodeID:      mocks.GenericNodeID(0).String(),
+				DelegatorID: &delegatorID,
+				Amount:      amount,
+			},
+		},
+		{
+			name: "unsupported event type",
+
+			event: flow.Event{
+				Type:    flow.EventType("irrelevant"),
+				Payload: rewardsEventPayload,
+			},
+
+			wantErr:      assert.Error,
+			wantSentinel: retriever.ErrNotSupported,
+		},
+		{
+			name: "wrong amount of fields",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(3),
+				Payload: rewardsEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid node ID field",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(2),
+				Payload: invalidNodeIDEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid payload",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(2),
+				Payload: mocks.GenericBytes,
+			},
+
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cvt := &Converter{
+				rewards:          mocks.GenericEventType(2),
+				delegatorRewards: mocks.GenericEventType(3),
+			}
+
+			got, err := cvt.EventToReward(test.event)
+
+			test.wantErr(t, err)
+			if test.wantSentinel != nil {
+				assert.ErrorIs(t, err, test.wantSentinel)
+			}
+
+			assert.Equal(t, test.wantReward, got)
+		})
+	}
+}
+
+func TestConverter_EventToFees(t *testing.T) {
+	feesType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(8)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "amount",
+				Type:       cadence.UFix64Type{},
+			},
+			{
+				Identifier: "inclusionEffort",
+				Type:       cadence.UFix64Type{},
+			},
+			{
+				Identifier: "executionEffort",
+				Type:       cadence.UFix64Type{},
+			},
+		},
+	}
+	feesEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.UFix64(1000),
+			cadence.UFix64(100000000),
+			cadence.UFix64(5),
+		},
+	).WithType(feesType)
+	feesEventPayload := json.MustEncode(feesEvent)
+
+	invalidFeesEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.UFix64(1000),
+			cadence.String("invalid"),
+			cadence.UFix64(5),
+		},
+	).WithType(feesType)
+	invalidFeesEventPayload := json.MustEncode(invalidFeesEvent)
+
+	wantFees := mocks.GenericFees()
+
+	tests := []struct {
+		name string
+
+		event flow.Event
+
+		wantErr      assert.ErrorAssertionFunc
+		wantSentinel error
+		wantFees     *object.TransactionFees
+	}{
+		{
+			name: "nominal case",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(8),
+				Payload: feesEventPayload,
+			},
+
+			wantErr:  assert.NoError,
+			wantFees: &wantFees,
+		},
+		{
+			name: "nominal case with legacy fees event",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(9),
+				Payload: feesEventPayload,
+			},
+
+			wantErr:  assert.NoError,
+			wantFees: &wantFees,
+		},
+		{
+			name: "unsupported event type",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(2),
+				Payload: feesEventPayload,
+			},
+
+			wantErr:      assert.Error,
+			wantSentinel: retriever.ErrNotSupported,
+		},
+		{
+			name: "invalid fees field",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(8),
+				Payload: invalidFeesEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid payload",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(8),
+				Payload: mocks.GenericBytes,
+			},
+
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cvt := &Converter{
+				fees: mocks.GenericEventType(8),
+				legacy: map[flow.EventType]flow.EventType{
+					mocks.GenericEventType(9): mocks.GenericEventType(8),
+				},
+			}
+
+			got, err := cvt.EventToFees(test.event)
+
+			test.wantErr(t, err)
+			if test.wantSentinel != nil {
+				assert.ErrorIs(t, err, test.wantSentinel)
+			}
+
+			assert.Equal(t, test.wantFees, got)
+		})
+	}
+}
+
+func BenchmarkConverter_EventToOperation(b *testing.B) {
+	depositType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(mocks.GenericEventType(0)),
+		Fields: []cadence.Field{
+			{
+				Identifier: "amount",
+				Type:       cadence.UInt64Type{},
+			},
+			{
+				Identifier: "address",
+				Type:       cadence.AddressType{},
+			},
+		},
+	}
+	depositEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.NewUInt64(42),
+			cadence.NewAddress([8]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+		},
+	).WithType(depositType)
+	event := flow.Event{
+		Type:       mocks.GenericEventType(0),
+		Payload:    json.MustEncode(depositEvent),
+		EventIndex: 1,
+	}
+
+	cvt := &Converter{
+		deposit:    mocks.GenericEventType(0),
+		withdrawal: mocks.GenericEventType(1),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := cvt.EventToOperation(event)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestConverter_EventToAddress(t *testing.T) {
+	address := flow.HexToAddress("0102030405060708")
+
+	createdType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: string(flow.EventAccountCreated),
+		Fields: []cadence.Field{
+			{
+				Identifier: "address",
+				Type:       cadence.AddressType{},
+			},
+		},
+	}
+	createdEvent := cadence.NewEvent([]cadence.Value{cadence.NewAddress(address)}).WithType(createdType)
+	createdEventPayload := json.MustEncode(createdEvent)
+
+	keyAddedType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "flow.AccountKeyAdded",
+		Fields: []cadence.Field{
+			{
+				Identifier: "address",
+				Type:       cadence.AddressType{},
+			},
+			{
+				Identifier: "publicKey",
+				Type:       cadence.StringType{},
+			},
+		},
+	}
+	keyAddedEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.NewAddress(address),
+			cadence.String("key"),
+		},
+	).WithType(keyAddedType)
+	keyAddedEventPayload := json.MustEncode(keyAddedEvent)
+
+	noFieldsType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "test",
+		Fields:              []cadence.Field{},
+	}
+	noFieldsEventPayload := json.MustEncode(cadence.NewEvent([]cadence.Value{}).WithType(noFieldsType))
+
+	invalidType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "test",
+		Fields: []cadence.Field{
+			{
+				Identifier: "address",
+				Type:       cadence.UInt64Type{},
+			},
+		},
+	}
+	invalidEventPayload := json.MustEncode(cadence.NewEvent([]cadence.Value{cadence.NewUInt64(42)}).WithType(invalidType))
+
+	tests := []struct {
+		name string
+
+		event flow.Event
+
+		wantErr     assert.ErrorAssertionFunc
+		wantAddress flow.Address
+	}{
+		{
+			name: "nominal case with account created event",
+
+			event: flow.Event{
+				Type:    flow.EventAccountCreated,
+				Payload: createdEventPayload,
+			},
+
+			wantErr:     assert.NoError,
+			wantAddress: address,
+		},
+		{
+			name: "nominal case with account key added event",
+
+			event: flow.Event{
+				Type:    "flow.AccountKeyAdded",
+				Payload: keyAddedEventPayload,
+			},
+
+			wantErr:     assert.NoError,
+			wantAddress: address,
+		},
+		{
+			name: "missing address field",
+
+			event: flow.Event{
+				Type:    flow.EventAccountCreated,
+				Payload: noFieldsEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid address field",
+
+			event: flow.Event{
+				Type:    flow.EventAccountCreated,
+				Payload: invalidEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid payload",
+
+			event: flow.Event{
+				Type:    flow.EventAccountCreated,
+				Payload: mocks.GenericBytes,
+			},
+
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cvt := &Converter{}
+
+			got, err := cvt.EventToAddress(test.event)
+
+			test.wantErr(t, err)
+			assert.Equal(t, test.wantAddress, got)
+		})
+	}
+}
+
+func TestConverter_EventToContract(t *testing.T) {
+	address := flow.HexToAddress("0102030405060708")
+
+	contractType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "flow.AccountContractUpdated",
+		Fields: []cadence.Field{
+			{
+				Identifier: "address",
+				Type:       cadence.AddressType{},
+			},
+			{
+				Identifier: "codeHash",
+				Type:       cadence.StringType{},
+			},
+			{
+				Identifier: "contract",
+				Type:       cadence.StringType{},
+			},
+		},
+	}
+	contractEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.NewAddress(address),
+			cadence.String("hash"),
+			cadence.String("FungibleToken"),
+		},
+	).WithType(contractType)
+	contractEventPayload := json.MustEncode(contractEvent)
+
+	invalidNameEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.NewAddress(address),
+			cadence.String("hash"),
+			cadence.NewUInt64(42),
+		},
+	).WithType(contractType)
+	invalidNameEventPayload := json.MustEncode(invalidNameEvent)
+
+	invalidAddressEvent := cadence.NewEvent(
+		[]cadence.Value{
+			cadence.NewUInt64(42),
+			cadence.String("hash"),
+			cadence.String("FungibleToken"),
+		},
+	).WithType(contractType)
+	invalidAddressEventPayload := json.MustEncode(invalidAddressEvent)
+
+	wantContract := func(action string) *object.ContractChange {
+		return &object.ContractChange{
+			Address: address.String(),
+			Name:    "FungibleToken",
+			Action:  action,
+		}
+	}
+
+	tests := []struct {
+		name string
+
+		event flow.Event
+
+		wantErr      assert.ErrorAssertionFunc
+		wantSentinel error
+		wantContract *object.ContractChange
+	}{
+		{
+			name: "nominal case with added contract",
+
+			event: flow.Event{
+				Type:    "flow.AccountContractAdded",
+				Payload: contractEventPayload,
+			},
+
+			wantErr:      assert.NoError,
+			wantContract: wantContract("added"),
+		},
+		{
+			name: "nominal case with updated contract",
+
+			event: flow.Event{
+				Type:    "flow.AccountContractUpdated",
+				Payload: contractEventPayload,
+			},
+
+			wantErr:      assert.NoError,
+			wantContract: wantContract("updated"),
+		},
+		{
+			name: "nominal case with removed contract",
+
+			event: flow.Event{
+				Type:    "flow.AccountContractRemoved",
+				Payload: contractEventPayload,
+			},
+
+			wantErr:      assert.NoError,
+			wantContract: wantContract("removed"),
+		},
+		{
+			name: "unsupported event type",
+
+			event: flow.Event{
+				Type:    flow.EventAccountCreated,
+				Payload: contractEventPayload,
+			},
+
+			wantErr:      assert.Error,
+			wantSentinel: retriever.ErrNotSupported,
+		},
+		{
+			name: "invalid number of fields",
+
+			event: flow.Event{
+				Type:    "flow.AccountContractAdded",
+				Payload: mocks.GenericEvent(0).Payload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid address field",
+
+			event: flow.Event{
+				Type:    "flow.AccountContractAdded",
+				Payload: invalidAddressEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid contract name field",
+
+			event: flow.Event{
+				Type:    "flow.AccountContractAdded",
+				Payload: invalidNameEventPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid payload",
+
+			event: flow.Event{
+				Type:    "flow.AccountContractAdded",
+				Payload: mocks.GenericBytes,
+			},
+
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cvt := &Converter{}
+
+			got, err := cvt.EventToContract(test.event)
+
+			test.wantErr(t, err)
+			if test.wantSentinel != nil {
+				assert.ErrorIs(t, err, test.wantSentinel)
+			}
+
+			assert.Equal(t, test.wantContract, got)
+		})
+	}
+}
+
+func TestConverter_EventToServiceEvent(t *testing.T) {
+	// The declared fields of the events are derived from their values, as only
+	// their number and order matter for the conversion.
+	payload := func(typ flow.EventType, values ...cadence.Value) []byte {
+		fields := make([]cadence.Field, 0, len(values))
+		for i, value := range values {
+			fields = append(fields, cadence.Field{
+				Identifier: fmt.Sprintf("field%d", i),
+				Type:       value.Type(),
+			})
+		}
+		eventType := &cadence.EventType{
+			Location:            utils.TestLocation,
+			QualifiedIdentifier: string(typ),
+			Fields:              fields,
+		}
+		return json.MustEncode(cadence.NewEvent(values).WithType(eventType))
+	}
+	list := func(values ...string) cadence.Array {
+		array := make([]cadence.Value, 0, len(values))
+		for _, value := range values {
+			array = append(array, cadence.String(value))
+		}
+		return cadence.NewArray(array).WithType(cadence.VariableSizedArrayType{ElementType: cadence.StringType{}})
+	}
+	setupType := mocks.GenericEventType(10)
+	commitType := mocks.GenericEventType(11)
+
+	setupFields := []cadence.Value{
+		cadence.UInt64(42),
+		list("node1", "node2", "node3", "node4"),
+		cadence.UInt64(1000),
+		cadence.UInt64(1999),
+		list("cluster"),
+		cadence.String("01020304"),
+		cadence.UInt64(1100),
+		cadence.UInt64(1200),
+		cadence.UInt64(1300),
+	}
+	setupPayload := payload(setupType, setupFields...)
+
+	commitFields := []cadence.Value{
+		cadence.UInt64(43),
+		list("qc1", "qc2"),
+		list("group", "key1", "key2", "key3"),
+	}
+	commitPayload := payload(commitType, commitFields...)
+
+	invalidSetupFields := make([]cadence.Value, len(setupFields))
+	copy(invalidSetupFields, setupFields)
+	invalidSetupFields[2] = cadence.String("invalid")
+	invalidSetupPayload := payload(setupType, invalidSetupFields...)
+	shortSetupPayload := payload(setupType, setupFields[:8]...)
+	extendedSetupPayload := payload(setupType, append(setupFields, cadence.UInt64(0))...)
+	missingKeysPayload := payload(commitType, cadence.UInt64(43), list(), list())
+
+	wantSetup := mocks.GenericServiceEvent()
+	wantCommit := object.ServiceEvent{
+		Type:    flow.ServiceEventCommit,
+		Counter: 43,
+		Commit: &object.EpochCommit{
+			ClusterQCs:      2,
+			DKGGroupKey:     "group",
+			DKGParticipants: 3,
+		},
+	}
+
+	tests := []struct {
+		name string
+
+		event flow.Event
+
+		wantErr      assert.ErrorAssertionFunc
+		wantSentinel error
+		wantService  *object.ServiceEvent
+	}{
+		{
+			name: "nominal case for epoch setup",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(10),
+				Payload: setupPayload,
+			},
+
+			wantErr:     assert.NoError,
+			wantService: &wantSetup,
+		},
+		{
+			name: "nominal case for epoch commit",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(11),
+				Payload: commitPayload,
+			},
+
+			wantErr:     assert.NoError,
+			wantService: &wantCommit,
+		},
+		{
+			name: "nominal case with additional fields",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(10),
+				Payload: extendedSetupPayload,
+			},
+
+			wantErr:     assert.NoError,
+			wantService: &wantSetup,
+		},
+		{
+			name: "unsupported event type",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(8),
+				Payload: setupPayload,
+			},
+
+			wantErr:      assert.Error,
+			wantSentinel: retriever.ErrNotSupported,
+		},
+		{
+			name: "insufficient number of fields",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(10),
+				Payload: shortSetupPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid view field",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(10),
+				Payload: invalidSetupPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "missing DKG group key",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(11),
+				Payload: missingKeysPayload,
+			},
+
+			wantErr: assert.Error,
+		},
+		{
+			name: "invalid payload",
+
+			event: flow.Event{
+				Type:    mocks.GenericEventType(10),
+				Payload: mocks.GenericBytes,
+			},
+
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cvt := &Converter{
+				epochSetup:  mocks.GenericEventType(10),
+				epochCommit: mocks.GenericEventType(11),
+			}
+
+			got, err := cvt.EventToServiceEvent(test.event)
+
+			test.wantErr(t, err)
+			if test.wantSentinel != nil {
+				assert.ErrorIs(t, err, test.wantSentinel)
+			}
+
+			assert.Equal(t, test.wantService, got)
+		})
+	}
+}
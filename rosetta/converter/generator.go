@@ -15,8 +15,15 @@
 package converter
 
 // Generator represents something that can generate scripts for retrieving the amounts
-// deposited and withdrawn for a given token.
+// deposited and withdrawn for a given token, as well as the staking rewards paid out.
 type Generator interface {
 	TokensDeposited(symbol string) (string, error)
 	TokensWithdrawn(symbol string) (string, error)
+	TokensMinted(symbol string) (string, error)
+	TokensBurned(symbol string) (string, error)
+	RewardsPaid() (string, error)
+	DelegatorRewardsPaid() (string, error)
+	FeesDeducted() (string, error)
+	EpochSetup() (string, error)
+	EpochCommit() (string, error)
 }
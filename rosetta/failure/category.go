@@ -0,0 +1,90 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Category is the broad class of a failure. It determines how the failure is
+// reported to clients, regardless of which API they use.
+type Category uint8
+
+// The categories of failures. The zero value is the internal category, so that
+// errors which are not categorized are never mistaken for client errors.
+const (
+	CategoryInternal Category = iota
+	CategoryClient
+	CategoryNotFound
+	CategoryUnavailable
+)
+
+// Categorized is implemented by all failures, so that the category of a
+// failure can be determined without knowing its type.
+type Categorized interface {
+	Category() Category
+}
+
+// status is the way a category of failures is reported on each API.
+type status struct {
+	name string
+	http int
+	grpc codes.Code
+}
+
+// statuses is the single table mapping failure categories to the status codes
+// of the HTTP and GRPC APIs.
+var statuses = map[Category]status{
+	CategoryInternal:    {name: "internal", http: http.StatusInternalServerError, grpc: codes.Internal},
+	CategoryClient:      {name: "client", http: http.StatusBadRequest, grpc: codes.InvalidArgument},
+	CategoryNotFound:    {name: "not found", http: http.StatusNotFound, grpc: codes.NotFound},
+	CategoryUnavailable: {name: "unavailable", http: http.StatusServiceUnavailable, grpc: codes.Unavailable},
+}
+
+// String implements the fmt.Stringer interface.
+func (c Category) String() string {
+	return statuses[c.known()].name
+}
+
+// HTTP returns the HTTP status code for failures of the category.
+func (c Category) HTTP() int {
+	return statuses[c.known()].http
+}
+
+// GRPC returns the GRPC status code for failures of the category.
+func (c Category) GRPC() codes.Code {
+	return statuses[c.known()].grpc
+}
+
+func (c Category) known() Category {
+	_, ok := statuses[c]
+	if !ok {
+		return CategoryInternal
+	}
+	return c
+}
+
+// Categorize returns the category of the failure wrapped in the given error.
+// Errors that do not wrap a failure are internal.
+func Categorize(err error) Category {
+	var fail Categorized
+	if errors.As(err, &fail) {
+		return fail.Category()
+	}
+	return CategoryInternal
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestCategorize(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want failure.Category
+	}{
+		{name: "client failure", err: failure.InvalidBlock{}, want: failure.CategoryClient},
+		{name: "not found failure", err: failure.UnknownBlock{}, want: failure.CategoryNotFound},
+		{name: "unavailable failure", err: failure.UnavailableBlock{}, want: failure.CategoryUnavailable},
+		{name: "wrapped failure", err: fmt.Errorf("could not validate block: %w", failure.UnknownTransaction{}), want: failure.CategoryNotFound},
+		{name: "upstream not found", err: failure.Upstream{Code: codes.NotFound}, want: failure.CategoryNotFound},
+		{name: "upstream out of range", err: failure.Upstream{Code: codes.OutOfRange}, want: failure.CategoryClient},
+		{name: "upstream timeout", err: failure.Upstream{Code: codes.DeadlineExceeded}, want: failure.CategoryUnavailable},
+		{name: "upstream internal", err: failure.Upstream{Code: codes.Internal}, want: failure.CategoryInternal},
+		{name: "uncategorized error", err: mocks.GenericError, want: failure.CategoryInternal},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, failure.Categorize(test.err))
+		})
+	}
+}
+
+func TestCategory(t *testing.T) {
+	tests := []struct {
+		category failure.Category
+		name     string
+		http     int
+		grpc     codes.Code
+	}{
+		{category: failure.CategoryInternal, name: "internal", http: http.StatusInternalServerError, grpc: codes.Internal},
+		{category: failure.CategoryClient, name: "client", http: http.StatusBadRequest, grpc: codes.InvalidArgument},
+		{category: failure.CategoryNotFound, name: "not found", http: http.StatusNotFound, grpc: codes.NotFound},
+		{category: failure.CategoryUnavailable, name: "unavailable", http: http.StatusServiceUnavailable, grpc: codes.Unavailable},
+		{category: failure.Category(255), name: "internal", http: http.StatusInternalServerError, grpc: codes.Internal},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.name, test.category.String())
+			assert.Equal(t, test.http, test.category.HTTP())
+			assert.Equal(t, test.grpc, test.category.GRPC())
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"fmt"
+)
+
+// DisabledEndpoint is the error for a request to an endpoint that the operator
+// disabled in the configuration of the server.
+type DisabledEndpoint struct {
+	Description Description
+	Endpoint    string
+}
+
+// Error implements the error interface.
+func (d DisabledEndpoint) Error() string {
+	return fmt.Sprintf("endpoint disabled (endpoint: %s): %s", d.Endpoint, d.Description)
+}
+
+// Category implements the Categorized interface.
+func (d DisabledEndpoint) Category() Category {
+	return CategoryNotFound
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// ExpiredTransaction is the error for a transaction whose reference block is too
+// old for the transaction to be accepted by the network. Such a transaction has
+// to be constructed again, starting from a fresh reference block. The proposal
+// key identifies the key whose sequence number the rebuilt transaction needs,
+// while the payer and gas limit let it be rebuilt with the same roles and fees.
+type ExpiredTransaction struct {
+	Description     Description
+	ReferenceHeight uint64
+	ExpiryHeight    uint64
+	CurrentHeight   uint64
+	Proposer        flow.Address
+	KeyIndex        int
+	Payer           flow.Address
+	GasLimit        uint64
+}
+
+// Error implements the error interface.
+func (e ExpiredTransaction) Error() string {
+	return fmt.Sprintf("expired transaction (reference_height: %d, expiry_height: %d, current_height: %d): %s", e.ReferenceHeight, e.ExpiryHeight, e.CurrentHeight, e.Description)
+}
+
+// Category implements the Categorized interface.
+func (e ExpiredTransaction) Category() Category {
+	return CategoryClient
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"fmt"
+)
+
+// FailedTransaction is the error for a transaction that fails when it is
+// executed against the state of the network, which means that it would fail
+// the same way once it is included in a block.
+type FailedTransaction struct {
+	Description Description
+	Code        uint
+}
+
+// Error implements the error interface.
+func (f FailedTransaction) Error() string {
+	return fmt.Sprintf("failed transaction (code: %d): %s", f.Code, f.Description)
+}
+
+// Category implements the Categorized interface.
+func (f FailedTransaction) Category() Category {
+	return CategoryClient
+}
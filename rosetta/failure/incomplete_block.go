@@ -28,3 +28,8 @@ type IncompleteBlock struct {
 func (i IncompleteBlock) Error() string {
 	return fmt.Sprintf("incomplete block: %s", i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i IncompleteBlock) Category() Category {
+	return CategoryClient
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"fmt"
+)
+
+// InsufficientFee is the error for a transaction whose maximum fee, as given by
+// the client, does not cover the fee of even the smallest possible gas limit.
+type InsufficientFee struct {
+	Description Description
+	MaxFee      string
+	Fee         string
+}
+
+// Error implements the error interface.
+func (i InsufficientFee) Error() string {
+	return fmt.Sprintf("insufficient fee (max_fee: %s, fee: %s): %s", i.MaxFee, i.Fee, i.Description)
+}
+
+// Category implements the Categorized interface.
+func (i InsufficientFee) Category() Category {
+	return CategoryClient
+}
@@ -28,3 +28,8 @@ type InvalidAccount struct {
 func (i InvalidAccount) Error() string {
 	return fmt.Sprintf("invalid account (address: %s): %s", i.Address, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidAccount) Category() Category {
+	return CategoryClient
+}
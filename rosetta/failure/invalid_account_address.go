@@ -29,3 +29,8 @@ type InvalidAccountAddress struct {
 func (i InvalidAccountAddress) Error() string {
 	return fmt.Sprintf("invalid account address length (want: %d, have: %d): %s", i.WantLength, i.HaveLength, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidAccountAddress) Category() Category {
+	return CategoryClient
+}
@@ -28,3 +28,8 @@ type InvalidAmount struct {
 func (i InvalidAmount) Error() string {
 	return fmt.Sprintf("invalid transaction amount (amount: %s): %s", i.Amount, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidAmount) Category() Category {
+	return CategoryClient
+}
@@ -29,3 +29,8 @@ type InvalidArguments struct {
 func (i InvalidArguments) Error() string {
 	return fmt.Sprintf("invalid transaction arguments (have: %d, want: %d): %s", i.Have, i.Want, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidArguments) Category() Category {
+	return CategoryClient
+}
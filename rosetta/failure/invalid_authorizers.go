@@ -29,3 +29,8 @@ type InvalidAuthorizers struct {
 func (i InvalidAuthorizers) Error() string {
 	return fmt.Sprintf("invalid number of authorizers (have: %d, want: %d): %s", i.Have, i.Want, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidAuthorizers) Category() Category {
+	return CategoryClient
+}
@@ -27,3 +27,8 @@ type InvalidBlock struct {
 func (i InvalidBlock) Error() string {
 	return fmt.Sprintf("invalid block: %s", i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidBlock) Category() Category {
+	return CategoryClient
+}
@@ -29,3 +29,8 @@ type InvalidBlockHash struct {
 func (i InvalidBlockHash) Error() string {
 	return fmt.Sprintf("invalid block hash length (want: %d, have: %d): %s", i.WantLength, i.HaveLength, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidBlockHash) Category() Category {
+	return CategoryClient
+}
@@ -27,3 +27,8 @@ type InvalidBlockchain struct {
 func (i InvalidBlockchain) Error() string {
 	return fmt.Sprintf("invalid blockchain (have: %s, want: %s): %s", i.HaveBlockchain, i.WantBlockchain, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidBlockchain) Category() Category {
+	return CategoryClient
+}
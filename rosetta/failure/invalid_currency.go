@@ -29,3 +29,8 @@ type InvalidCurrency struct {
 func (i InvalidCurrency) Error() string {
 	return fmt.Sprintf("invalid currency (symbol: %s, decimals: %d): %s", i.Symbol, i.Decimals, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidCurrency) Category() Category {
+	return CategoryClient
+}
@@ -27,3 +27,8 @@ type InvalidIntent struct {
 func (i InvalidIntent) Error() string {
 	return fmt.Sprintf("invalid transaction intent: %s", i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidIntent) Category() Category {
+	return CategoryClient
+}
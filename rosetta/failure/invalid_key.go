@@ -32,3 +32,8 @@ type InvalidKey struct {
 func (i InvalidKey) Error() string {
 	return fmt.Sprintf("invalid signer key (height: %d, address: %s, key index: %d): %s", i.Height, i.Address.Hex(), i.Index, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidKey) Category() Category {
+	return CategoryClient
+}
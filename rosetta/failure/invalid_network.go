@@ -29,3 +29,8 @@ type InvalidNetwork struct {
 func (i InvalidNetwork) Error() string {
 	return fmt.Sprintf("invalid network (have: %s, want: %s): %s", i.HaveNetwork, i.WantNetwork, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidNetwork) Category() Category {
+	return CategoryClient
+}
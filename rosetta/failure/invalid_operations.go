@@ -29,3 +29,8 @@ type InvalidOperations struct {
 func (i InvalidOperations) Error() string {
 	return fmt.Sprintf("invalid operations (want: %d, have: %d): %s", i.Want, i.Have, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidOperations) Category() Category {
+	return CategoryClient
+}
@@ -31,3 +31,8 @@ type InvalidPayer struct {
 func (i InvalidPayer) Error() string {
 	return fmt.Sprintf("invalid transaction payer (have: %s, want: %s): %s", i.Have.Hex(), i.Want.Hex(), i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidPayer) Category() Category {
+	return CategoryClient
+}
@@ -28,3 +28,8 @@ type InvalidPayload struct {
 func (i InvalidPayload) Error() string {
 	return fmt.Sprintf("invalid transaction payload (encoding: %s): %s", i.Encoding, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidPayload) Category() Category {
+	return CategoryClient
+}
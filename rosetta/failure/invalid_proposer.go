@@ -31,3 +31,8 @@ type InvalidProposer struct {
 func (i InvalidProposer) Error() string {
 	return fmt.Sprintf("invalid transaction proposer (have: %s, want: %s): %s", i.Have.Hex(), i.Want.Hex(), i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidProposer) Category() Category {
+	return CategoryClient
+}
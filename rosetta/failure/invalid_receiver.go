@@ -28,3 +28,8 @@ type InvalidReceiver struct {
 func (i InvalidReceiver) Error() string {
 	return fmt.Sprintf("invalid transaction receiver (receiver: %s): %s", i.Receiver, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidReceiver) Category() Category {
+	return CategoryClient
+}
@@ -29,3 +29,8 @@ func (i InvalidScript) Error() string {
 	// We don't want to print the entire script, that would be gigantic.
 	return fmt.Sprintf("invalid transaction script: %s", i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidScript) Category() Category {
+	return CategoryClient
+}
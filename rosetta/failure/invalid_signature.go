@@ -27,3 +27,8 @@ type InvalidSignature struct {
 func (i InvalidSignature) Error() string {
 	return fmt.Sprintf("invalid transaction signature: %s", i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidSignature) Category() Category {
+	return CategoryClient
+}
@@ -29,3 +29,8 @@ type InvalidSignatures struct {
 func (i InvalidSignatures) Error() string {
 	return fmt.Sprintf("invalid signatures (want: %d, have: %d): %s", i.Want, i.Have, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidSignatures) Category() Category {
+	return CategoryClient
+}
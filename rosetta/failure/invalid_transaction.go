@@ -28,3 +28,8 @@ type InvalidTransaction struct {
 func (i InvalidTransaction) Error() string {
 	return fmt.Sprintf("invalid transaction (transaction: %s): %s", i.Hash, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidTransaction) Category() Category {
+	return CategoryClient
+}
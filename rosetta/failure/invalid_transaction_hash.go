@@ -29,3 +29,8 @@ type InvalidTransactionHash struct {
 func (i InvalidTransactionHash) Error() string {
 	return fmt.Sprintf("invalid transaction hash length (want: %d, have: %d): %s", i.WantLength, i.HaveLength, i.Description)
 }
+
+// Category implements the Categorized interface.
+func (i InvalidTransactionHash) Category() Category {
+	return CategoryClient
+}
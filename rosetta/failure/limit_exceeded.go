@@ -0,0 +1,36 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"fmt"
+)
+
+// LimitExceeded is the error for a Cadence execution that was aborted because
+// it exceeded one of the configured execution limits.
+type LimitExceeded struct {
+	Description Description
+	Limit       string
+}
+
+// Error implements the error interface.
+func (l LimitExceeded) Error() string {
+	return fmt.Sprintf("limit exceeded (limit: %s): %s", l.Limit, l.Description)
+}
+
+// Category implements the Categorized interface.
+func (l LimitExceeded) Category() Category {
+	return CategoryClient
+}
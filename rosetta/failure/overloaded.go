@@ -0,0 +1,36 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"fmt"
+)
+
+// Overloaded is the error for a request that was rejected because too many
+// executions are already running or waiting, so that it can be retried later.
+type Overloaded struct {
+	Description Description
+	Capacity    uint
+}
+
+// Error implements the error interface.
+func (o Overloaded) Error() string {
+	return fmt.Sprintf("overloaded (capacity: %d): %s", o.Capacity, o.Description)
+}
+
+// Category implements the Categorized interface.
+func (o Overloaded) Category() Category {
+	return CategoryUnavailable
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// SequenceConflict is the error for a transaction whose proposal key sequence
+// number was already used by another transaction, which means the network would
+// reject it. Such a transaction has to be constructed again, with the current
+// sequence number of the proposal key.
+type SequenceConflict struct {
+	Description Description
+	Proposer    flow.Address
+	KeyIndex    int
+	Sequence    uint64
+	Current     uint64
+}
+
+// Error implements the error interface.
+func (e SequenceConflict) Error() string {
+	return fmt.Sprintf("sequence conflict (proposer: %s, key_index: %d, sequence: %d, current: %d): %s", e.Proposer.Hex(), e.KeyIndex, e.Sequence, e.Current, e.Description)
+}
+
+// Category implements the Categorized interface.
+func (e SequenceConflict) Category() Category {
+	return CategoryClient
+}
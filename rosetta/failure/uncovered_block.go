@@ -0,0 +1,37 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"fmt"
+)
+
+// UncoveredBlock is the error for a block height that is not covered by any of
+// the sporks served by the API, because it lies before the first spork or in a
+// gap between two of them.
+type UncoveredBlock struct {
+	Description Description
+	Index       uint64
+}
+
+// Error implements the error interface.
+func (u UncoveredBlock) Error() string {
+	return fmt.Sprintf("uncovered block (index: %d): %s", u.Index, u.Description)
+}
+
+// Category implements the Categorized interface.
+func (u UncoveredBlock) Category() Category {
+	return CategoryNotFound
+}
@@ -29,3 +29,8 @@ type UnknownBlock struct {
 func (u UnknownBlock) Error() string {
 	return fmt.Sprintf("unknown block (index: %d, hash: %s): %s", u.Index, u.Hash, u.Description)
 }
+
+// Category implements the Categorized interface.
+func (u UnknownBlock) Category() Category {
+	return CategoryNotFound
+}
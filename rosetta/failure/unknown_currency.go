@@ -29,3 +29,8 @@ type UnknownCurrency struct {
 func (u UnknownCurrency) Error() string {
 	return fmt.Sprintf("unknown currency (symbol: %s, decimals: %d): %s", u.Symbol, u.Decimals, u.Description)
 }
+
+// Category implements the Categorized interface.
+func (u UnknownCurrency) Category() Category {
+	return CategoryNotFound
+}
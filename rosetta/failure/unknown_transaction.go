@@ -28,3 +28,8 @@ type UnknownTransaction struct {
 func (u UnknownTransaction) Error() string {
 	return fmt.Sprintf("unknown transaction (hash: %s): %s", u.Hash, u.Description)
 }
+
+// Category implements the Categorized interface.
+func (u UnknownTransaction) Category() Category {
+	return CategoryNotFound
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"fmt"
+)
+
+// UnsealedBlock is the error for a block that is finalized and indexed, but
+// that has not been sealed on the Flow network yet, when the API is configured
+// to only serve sealed blocks.
+type UnsealedBlock struct {
+	Description Description
+	Index       uint64
+	Hash        string
+}
+
+// Error implements the error interface.
+func (u UnsealedBlock) Error() string {
+	return fmt.Sprintf("unsealed block (index: %d, hash: %s): %s", u.Index, u.Hash, u.Description)
+}
+
+// Category implements the Categorized interface.
+func (u UnsealedBlock) Category() Category {
+	return CategoryUnavailable
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package failure
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Upstream is the error for a failed request to an upstream gRPC API, such as
+// the Flow Access API. It keeps the gRPC status code, so that it can be
+// translated into a specific Rosetta error.
+type Upstream struct {
+	Description Description
+	Service     string
+	Code        codes.Code
+}
+
+// Error implements the error interface.
+func (u Upstream) Error() string {
+	return fmt.Sprintf("upstream failure (service: %s, code: %s): %s", u.Service, u.Code, u.Description)
+}
+
+// Category implements the Categorized interface. The category depends on the
+// GRPC status code returned by the upstream API.
+func (u Upstream) Category() Category {
+	switch u.Code {
+	case codes.NotFound:
+		return CategoryNotFound
+	case codes.OutOfRange, codes.InvalidArgument, codes.FailedPrecondition:
+		return CategoryClient
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return CategoryUnavailable
+	default:
+		return CategoryInternal
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hedger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+
+	sdk "github.com/onflow/flow-go-sdk"
+)
+
+// Hedger sends latency-sensitive requests to several access nodes. A request
+// first goes to the primary access node; if it did not respond after the
+// configured delay, or if it failed, the same request is sent to the next one,
+// and so on. The first successful response wins, and the remaining requests
+// are canceled. This smooths over slow upstream replicas.
+type Hedger struct {
+	cfg  Config
+	apis []API
+}
+
+// result is the response of a single access node.
+type result struct {
+	header *sdk.BlockHeader
+	err    error
+}
+
+// New creates a new hedger for the given primary access node and fallbacks.
+func New(primary API, fallbacks []API, options ...func(*Config)) *Hedger {
+
+	cfg := Config{
+		Delay: 200 * time.Millisecond,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	apis := make([]API, 0, len(fallbacks)+1)
+	apis = append(apis, primary)
+	apis = append(apis, fallbacks...)
+
+	h := Hedger{
+		cfg:  cfg,
+		apis: apis,
+	}
+
+	return &h
+}
+
+// GetLatestBlockHeader returns the latest block header from the first access
+// node to respond successfully.
+func (h *Hedger) GetLatestBlockHeader(ctx context.Context, isSealed bool, opts ...grpc.CallOption) (*sdk.BlockHeader, error) {
+	return h.hedge(ctx, func(ctx context.Context, api API) (*sdk.BlockHeader, error) {
+		return api.GetLatestBlockHeader(ctx, isSealed, opts...)
+	})
+}
+
+// GetBlockHeaderByHeight returns the block header at the given height from the
+// first access node to respond successfully.
+func (h *Hedger) GetBlockHeaderByHeight(ctx context.Context, height uint64, opts ...grpc.CallOption) (*sdk.BlockHeader, error) {
+	return h.hedge(ctx, func(ctx context.Context, api API) (*sdk.BlockHeader, error) {
+		return api.GetBlockHeaderByHeight(ctx, height, opts...)
+	})
+}
+
+func (h *Hedger) hedge(ctx context.Context, call func(context.Context, API) (*sdk.BlockHeader, error)) (*sdk.BlockHeader, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The channel is buffered for all access nodes, so that requests that
+	// complete after we returned do not block forever.
+	results := make(chan result, len(h.apis))
+	launched := 0
+	launch := func() <-chan time.Time {
+		api := h.apis[launched]
+		launched++
+		go func() {
+			header, err := call(ctx, api)
+			results <- result{header: header, err: err}
+		}()
+		if launched == len(h.apis) {
+			return nil
+		}
+		return time.After(h.cfg.Delay)
+	}
+
+	hedge := launch()
+	var errs []error
+	for len(errs) < launched {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-hedge:
+			hedge = launch()
+		case res := <-results:
+			if res.err == nil {
+				return res.header, nil
+			}
+			errs = append(errs, res.err)
+			if launched < len(h.apis) {
+				hedge = launch()
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("all %d access nodes failed: %w", len(errs), errs[0])
+}
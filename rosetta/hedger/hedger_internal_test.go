@@ -0,0 +1,178 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package hedger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	sdk "github.com/onflow/flow-go-sdk"
+
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestNew(t *testing.T) {
+	primary := mocks.BaselineAccessAPI(t)
+	fallback := mocks.BaselineAccessAPI(t)
+
+	h := New(primary, []API{fallback}, WithDelay(time.Second))
+
+	require.NotNil(t, h)
+	assert.Equal(t, time.Second, h.cfg.Delay)
+	assert.Equal(t, []API{primary, fallback}, h.apis)
+}
+
+func TestHedger_GetLatestBlockHeader(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		primary := mocks.BaselineAccessAPI(t)
+		primary.GetLatestBlockHeaderFunc = func(_ context.Context, isSealed bool, _ ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			assert.True(t, isSealed)
+			return &sdk.BlockHeader{Height: mocks.GenericHeight}, nil
+		}
+		fallback := mocks.BaselineAccessAPI(t)
+		fallback.GetLatestBlockHeaderFunc = func(context.Context, bool, ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			t.Error("fallback should not be called")
+			return nil, mocks.GenericError
+		}
+
+		h := New(primary, []API{fallback}, WithDelay(time.Hour))
+
+		header, err := h.GetLatestBlockHeader(context.Background(), true)
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight, header.Height)
+	})
+
+	t.Run("hedges slow primary", func(t *testing.T) {
+		t.Parallel()
+
+		canceled := make(chan struct{})
+		primary := mocks.BaselineAccessAPI(t)
+		primary.GetLatestBlockHeaderFunc = func(ctx context.Context, _ bool, _ ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			<-ctx.Done()
+			close(canceled)
+			return nil, ctx.Err()
+		}
+		fallback := mocks.BaselineAccessAPI(t)
+		fallback.GetLatestBlockHeaderFunc = func(context.Context, bool, ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			return &sdk.BlockHeader{Height: mocks.GenericHeight + 1}, nil
+		}
+
+		h := New(primary, []API{fallback}, WithDelay(time.Millisecond))
+
+		header, err := h.GetLatestBlockHeader(context.Background(), true)
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight+1, header.Height)
+
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Error("slow request was not canceled")
+		}
+	})
+
+	t.Run("falls back immediately on failure", func(t *testing.T) {
+		t.Parallel()
+
+		primary := mocks.BaselineAccessAPI(t)
+		primary.GetLatestBlockHeaderFunc = func(context.Context, bool, ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			return nil, mocks.GenericError
+		}
+		fallback := mocks.BaselineAccessAPI(t)
+		fallback.GetLatestBlockHeaderFunc = func(context.Context, bool, ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			return &sdk.BlockHeader{Height: mocks.GenericHeight + 1}, nil
+		}
+
+		h := New(primary, []API{fallback}, WithDelay(time.Hour))
+
+		header, err := h.GetLatestBlockHeader(context.Background(), true)
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight+1, header.Height)
+	})
+
+	t.Run("handles failure of all access nodes", func(t *testing.T) {
+		t.Parallel()
+
+		primary := mocks.BaselineAccessAPI(t)
+		primary.GetLatestBlockHeaderFunc = func(context.Context, bool, ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			return nil, mocks.GenericError
+		}
+		fallback := mocks.BaselineAccessAPI(t)
+		fallback.GetLatestBlockHeaderFunc = func(context.Context, bool, ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			return nil, mocks.GenericError
+		}
+
+		h := New(primary, []API{fallback}, WithDelay(time.Hour))
+
+		_, err := h.GetLatestBlockHeader(context.Background(), true)
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+
+	t.Run("handles canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		primary := mocks.BaselineAccessAPI(t)
+		primary.GetLatestBlockHeaderFunc = func(ctx context.Context, _ bool, _ ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		h := New(primary, nil, WithDelay(time.Hour))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := h.GetLatestBlockHeader(ctx, true)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestHedger_GetBlockHeaderByHeight(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		primary := mocks.BaselineAccessAPI(t)
+		primary.GetBlockHeaderByHeightFunc = func(_ context.Context, height uint64, _ ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			assert.Equal(t, mocks.GenericHeight, height)
+			return &sdk.BlockHeader{Height: height}, nil
+		}
+
+		h := New(primary, nil)
+
+		header, err := h.GetBlockHeaderByHeight(context.Background(), mocks.GenericHeight)
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight, header.Height)
+	})
+
+	t.Run("handles access API failure", func(t *testing.T) {
+		t.Parallel()
+
+		primary := mocks.BaselineAccessAPI(t)
+		primary.GetBlockHeaderByHeightFunc = func(context.Context, uint64, ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			return nil, mocks.GenericError
+		}
+
+		h := New(primary, nil)
+
+		_, err := h.GetBlockHeaderByHeight(context.Background(), mocks.GenericHeight)
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package history
+
+import (
+	"time"
+)
+
+// Config contains the configuration options for the history follower.
+type Config struct {
+	Poll  time.Duration
+	Start uint64
+}
+
+// WithPoll sets how often the follower checks for new blocks to index.
+func WithPoll(poll time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.Poll = poll
+	}
+}
+
+// WithStart sets the height from which the follower starts indexing when the
+// store is still empty. By default, it starts at the oldest indexed block.
+func WithStart(start uint64) func(*Config) {
+	return func(c *Config) {
+		c.Start = start
+	}
+}
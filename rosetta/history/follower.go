@@ -0,0 +1,155 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+// Follower follows newly indexed blocks and adds each of them to the history
+// store, so that the transactions of an account can be listed without walking
+// through all blocks.
+type Follower struct {
+	log      zerolog.Logger
+	cfg      Config
+	store    *Store
+	retrieve Retriever
+}
+
+// NewFollower creates a new follower that converts blocks using the given
+// retriever and adds them to the given store.
+func NewFollower(log zerolog.Logger, store *Store, retrieve Retriever, options ...func(*Config)) *Follower {
+
+	cfg := Config{
+		Poll:  time.Second,
+		Start: 0,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	f := Follower{
+		log:      log.With().Str("component", "history").Logger(),
+		cfg:      cfg,
+		store:    store,
+		retrieve: retrieve,
+	}
+
+	return &f
+}
+
+// Run indexes new blocks as they arrive until the given context is canceled.
+func (f *Follower) Run(ctx context.Context) error {
+
+	ticker := time.NewTicker(f.cfg.Poll)
+	defer ticker.Stop()
+	for {
+
+		// Failures are logged rather than returned, so that a temporary
+		// problem with the index does not stop the indexing.
+		err := f.catchUp(ctx)
+		if err != nil {
+			f.log.Warn().Err(err).Msg("could not index new blocks")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// catchUp indexes the blocks after the last indexed height, up to the current
+// block. When the store is empty, it starts at the configured height, or at
+// the oldest indexed block if that one is higher.
+func (f *Follower) catchUp(ctx context.Context) error {
+
+	var next uint64
+	_, last, ok := f.store.Range()
+	if ok {
+		next = last + 1
+	} else {
+		rosBlockID, _, err := f.retrieve.Oldest()
+		if err != nil {
+			return fmt.Errorf("could not get oldest block: %w", err)
+		}
+		next = *rosBlockID.Index
+		if f.cfg.Start > next {
+			next = f.cfg.Start
+		}
+	}
+
+	rosBlockID, _, err := f.retrieve.Current()
+	if err != nil {
+		return fmt.Errorf("could not get current block: %w", err)
+	}
+	current := *rosBlockID.Index
+
+	for height := next; height <= current; height++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		err := f.index(height)
+		if err != nil {
+			return fmt.Errorf("could not index block (height: %d): %w", height, err)
+		}
+	}
+
+	return nil
+}
+
+// index adds the block at the given height to the store, including the
+// transactions that did not fit into the block response.
+func (f *Follower) index(height uint64) error {
+
+	block, extraTxIDs, err := f.retrieve.Block(identifier.Block{Index: &height})
+	if err != nil {
+		return fmt.Errorf("could not retrieve block: %w", err)
+	}
+
+	transactions := make([]*object.Transaction, 0, len(block.Transactions)+len(extraTxIDs))
+	transactions = append(transactions, block.Transactions...)
+	for _, rosTxID := range extraTxIDs {
+		transaction, err := f.retrieve.Transaction(block.ID, rosTxID)
+		if err != nil {
+			return fmt.Errorf("could not retrieve transaction (hash: %s): %w", rosTxID.Hash, err)
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	blockID, err := flow.HexStringToIdentifier(block.ID.Hash)
+	if err != nil {
+		return fmt.Errorf("could not decode block ID (hash: %s): %w", block.ID.Hash, err)
+	}
+
+	err = f.store.Index(height, blockID, transactions)
+	if err != nil {
+		return fmt.Errorf("could not add block to store: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package history_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/history"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestFollower_Run(t *testing.T) {
+	header := mocks.GenericHeader
+	address := mocks.GenericAddress(0)
+	txIDs := mocks.GenericTransactionIDs(2)
+
+	transaction := func(index int) *object.Transaction {
+		return &object.Transaction{
+			ID: identifier.Transaction{Hash: txIDs[index].String()},
+			Operations: []*object.Operation{
+				{Type: dps.OperationTransfer, AccountID: identifier.Account{Address: address.Hex()}},
+			},
+		}
+	}
+
+	// following returns a retriever serving blocks from the given oldest to
+	// the given current height, which cancels the given context once the
+	// current block is retrieved, so that the follower stops after one pass.
+	following := func(t *testing.T, cancel context.CancelFunc, oldest uint64, current uint64, heights *[]uint64) *mocks.Retriever {
+		t.Helper()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.OldestFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{Index: &oldest}, time.Time{}, nil
+		}
+		retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{Index: &current}, time.Time{}, nil
+		}
+		retrieve.BlockFunc = func(rosBlockID identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			height := *rosBlockID.Index
+			*heights = append(*heights, height)
+			if height == current {
+				cancel()
+			}
+			block := object.Block{
+				ID:           identifier.Block{Index: &height, Hash: header.ID().String()},
+				Transactions: []*object.Transaction{transaction(0)},
+			}
+			return &block, []identifier.Transaction{transaction(1).ID}, nil
+		}
+		retrieve.TransactionFunc = func(_ identifier.Block, rosTxID identifier.Transaction) (*object.Transaction, error) {
+			assert.Equal(t, transaction(1).ID, rosTxID)
+
+			return transaction(1), nil
+		}
+
+		return retrieve
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := history.New(inMemoryDB(t))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var heights []uint64
+		retrieve := following(t, cancel, 10, 12, &heights)
+
+		follow := history.NewFollower(zerolog.Nop(), store, retrieve, history.WithPoll(time.Hour))
+		err = follow.Run(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint64{10, 11, 12}, heights)
+
+		first, last, ok := store.Range()
+		require.True(t, ok)
+		assert.Equal(t, uint64(10), first)
+		assert.Equal(t, uint64(12), last)
+
+		// The transactions that did not fit into the block response are
+		// indexed too, after the ones of the block.
+		refs, err := store.Transactions(address, 10, 0, 10, 10)
+		require.NoError(t, err)
+		require.Len(t, refs, 2)
+		assert.Equal(t, txIDs[0], refs[0].TxID)
+		assert.Equal(t, txIDs[1], refs[1].TxID)
+		assert.Equal(t, uint32(1), refs[1].Index)
+	})
+
+	t.Run("starts at configured height", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := history.New(inMemoryDB(t))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var heights []uint64
+		retrieve := following(t, cancel, 10, 12, &heights)
+
+		follow := history.NewFollower(zerolog.Nop(), store, retrieve, history.WithPoll(time.Hour), history.WithStart(11))
+		err = follow.Run(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint64{11, 12}, heights)
+	})
+
+	t.Run("resumes after last indexed height", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := history.New(inMemoryDB(t))
+		require.NoError(t, err)
+		err = store.Index(10, header.ID(), nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var heights []uint64
+		retrieve := following(t, cancel, 5, 12, &heights)
+
+		follow := history.NewFollower(zerolog.Nop(), store, retrieve, history.WithPoll(time.Hour), history.WithStart(8))
+		err = follow.Run(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint64{11, 12}, heights)
+	})
+
+	t.Run("keeps indexed blocks on retriever failure", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := history.New(inMemoryDB(t))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var heights []uint64
+		retrieve := following(t, cancel, 10, 12, &heights)
+		retrieve.TransactionFunc = func(rosBlockID identifier.Block, _ identifier.Transaction) (*object.Transaction, error) {
+			if *rosBlockID.Index == 11 {
+				cancel()
+				return nil, mocks.GenericError
+			}
+			return transaction(1), nil
+		}
+
+		follow := history.NewFollower(zerolog.Nop(), store, retrieve, history.WithPoll(time.Hour))
+		err = follow.Run(ctx)
+		require.NoError(t, err)
+
+		_, last, ok := store.Range()
+		require.True(t, ok)
+		assert.Equal(t, uint64(10), last)
+	})
+}
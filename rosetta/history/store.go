@@ -0,0 +1,300 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+// Column prefixes of the history database.
+const (
+	prefixEntry   = byte(1)
+	prefixRange   = byte(2)
+	prefixVersion = byte(3)
+)
+
+// Version is the version of the indexing of account histories. It has to be
+// increased whenever a change would index other transactions for an account,
+// so that histories indexed by a previous version are indexed again.
+const Version = uint32(2)
+
+// Reference identifies a transaction that affected an account, through the
+// height and ID of its block and its index within the block.
+type Reference struct {
+	Height  uint64
+	Index   uint32
+	BlockID flow.Identifier
+	TxID    flow.Identifier
+}
+
+// Store persists, for each account, references to the transactions with
+// operations of any type affecting it. Blocks are added in order of height without
+// gaps, so that the store covers a single range of heights, which allows it to
+// tell whether the history of an account is complete for a given range.
+type Store struct {
+	db *badger.DB
+
+	mutex *sync.RWMutex
+	first uint64
+	last  uint64
+	empty bool
+}
+
+// New returns a new history store on top of the given database. The range of
+// heights that is already indexed is loaded on startup, unless it was indexed
+// by another version, in which case the store starts over empty.
+func New(db *badger.DB) (*Store, error) {
+
+	s := Store{
+		db:    db,
+		mutex: &sync.RWMutex{},
+		empty: true,
+	}
+
+	err := s.migrate()
+	if err != nil {
+		return nil, fmt.Errorf("could not check history version: %w", err)
+	}
+
+	err = db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get([]byte{prefixRange})
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			if len(val) != 16 {
+				return fmt.Errorf("invalid range length (have: %d, want: 16)", len(val))
+			}
+			s.first = binary.BigEndian.Uint64(val[0:8])
+			s.last = binary.BigEndian.Uint64(val[8:16])
+			s.empty = false
+			return nil
+		})
+	})
+	if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, fmt.Errorf("could not load indexed range: %w", err)
+	}
+
+	return &s, nil
+}
+
+// migrate drops all indexed histories if they were indexed by another version,
+// and records the current version.
+func (s *Store) migrate() error {
+
+	version := make([]byte, 4)
+	binary.BigEndian.PutUint32(version, Version)
+
+	var stored []byte
+	err := s.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get([]byte{prefixVersion})
+		if err != nil {
+			return err
+		}
+		stored, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+		return fmt.Errorf("could not read history version: %w", err)
+	}
+	if bytes.Equal(stored, version) {
+		return nil
+	}
+
+	err = s.db.DropPrefix([]byte{prefixEntry}, []byte{prefixRange})
+	if err != nil {
+		return fmt.Errorf("could not drop indexed histories: %w", err)
+	}
+	err = s.db.Update(func(tx *badger.Txn) error {
+		return tx.Set([]byte{prefixVersion}, version)
+	})
+	if err != nil {
+		return fmt.Errorf("could not write history version: %w", err)
+	}
+
+	return nil
+}
+
+// Range returns the first and last heights that are indexed. The boolean is
+// false if no block was indexed yet.
+func (s *Store) Range() (uint64, uint64, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.first, s.last, !s.empty
+}
+
+// Index adds the given transactions of the block with the given height and ID
+// to the history of the accounts that their operations affect. The height has
+// to follow the last indexed height, unless the store is empty.
+func (s *Store) Index(height uint64, blockID flow.Identifier, transactions []*object.Transaction) error {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.empty && height != s.last+1 {
+		return fmt.Errorf("height does not follow last indexed height (height: %d, last: %d)", height, s.last)
+	}
+
+	first := s.first
+	if s.empty {
+		first = height
+	}
+
+	err := s.db.Update(func(tx *badger.Txn) error {
+		for index, transaction := range transactions {
+			txID, err := flow.HexStringToIdentifier(transaction.ID.Hash)
+			if err != nil {
+				return fmt.Errorf("could not decode transaction ID (hash: %s): %w", transaction.ID.Hash, err)
+			}
+
+			// A transaction only appears once in the history of an account,
+			// even when it has several operations affecting it.
+			value := make([]byte, 0, 2*len(flow.ZeroID))
+			value = append(value, blockID[:]...)
+			value = append(value, txID[:]...)
+			seen := make(map[flow.Address]struct{})
+			for _, op := range transaction.Operations {
+				address := flow.HexToAddress(op.AccountID.Address)
+				_, ok := seen[address]
+				if ok {
+					continue
+				}
+				seen[address] = struct{}{}
+				err = tx.Set(entryKey(address, height, uint32(index)), value)
+				if err != nil {
+					return fmt.Errorf("could not set entry: %w", err)
+				}
+			}
+		}
+
+		val := make([]byte, 16)
+		binary.BigEndian.PutUint64(val[0:8], first)
+		binary.BigEndian.PutUint64(val[8:16], height)
+		return tx.Set([]byte{prefixRange}, val)
+	})
+	if err != nil {
+		return fmt.Errorf("could not index block (height: %d): %w", height, err)
+	}
+
+	s.first = first
+	s.last = height
+	s.empty = false
+
+	return nil
+}
+
+// Transactions returns up to the given limit of references to transactions
+// that affected the given account between the given heights, inclusive. They
+// are ordered by height, then by index within the block, and start at the
+// transaction with the given index within the start height.
+func (s *Store) Transactions(address flow.Address, start uint64, index uint32, end uint64, limit uint) ([]Reference, error) {
+
+	var refs []Reference
+	err := s.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = accountPrefix(address)
+		it := tx.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(entryKey(address, start, index)); it.Valid() && uint(len(refs)) < limit; it.Next() {
+			item := it.Item()
+			key := item.Key()
+			ref := Reference{
+				Height: binary.BigEndian.Uint64(key[1+flow.AddressLength:]),
+				Index:  binary.BigEndian.Uint32(key[1+flow.AddressLength+8:]),
+			}
+			if ref.Height > end {
+				break
+			}
+			err := item.Value(func(val []byte) error {
+				if len(val) != 2*len(flow.ZeroID) {
+					return fmt.Errorf("invalid entry length (have: %d, want: %d)", len(val), 2*len(flow.ZeroID))
+				}
+				copy(ref.BlockID[:], val[:len(flow.ZeroID)])
+				copy(ref.TxID[:], val[len(flow.ZeroID):])
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("could not read entry: %w", err)
+			}
+			refs = append(refs, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not iterate entries: %w", err)
+	}
+
+	return refs, nil
+}
+
+// Latest returns the highest height, up to the given height, at which the given
+// account was affected by a transaction. The boolean is false if the account
+// was not affected by any transaction at or below the given height.
+func (s *Store) Latest(address flow.Address, height uint64) (uint64, bool, error) {
+
+	var latest uint64
+	var found bool
+	err := s.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Reverse = true
+		opts.Prefix = accountPrefix(address)
+		it := tx.NewIterator(opts)
+		defer it.Close()
+		it.Seek(entryKey(address, height, math.MaxUint32))
+		if !it.Valid() {
+			return nil
+		}
+		latest = binary.BigEndian.Uint64(it.Item().Key()[1+flow.AddressLength:])
+		found = true
+		return nil
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("could not iterate entries: %w", err)
+	}
+
+	return latest, found, nil
+}
+
+func accountPrefix(address flow.Address) []byte {
+	prefix := make([]byte, 1+flow.AddressLength)
+	prefix[0] = prefixEntry
+	copy(prefix[1:], address[:])
+	return prefix
+}
+
+// entryKey returns the database key for the transaction with the given index
+// within the block at the given height, in the history of the given account.
+// Heights and indices are encoded in big endian, so that the entries of an
+// account are sorted by height, then by index.
+func entryKey(address flow.Address, height uint64, index uint32) []byte {
+	key := make([]byte, 1+flow.AddressLength+8+4)
+	copy(key, accountPrefix(address))
+	binary.BigEndian.PutUint64(key[1+flow.AddressLength:], height)
+	binary.BigEndian.PutUint32(key[1+flow.AddressLength+8:], index)
+	return key
+}
@@ -0,0 +1,272 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package history_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/history"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestStore(t *testing.T) {
+	addresses := mocks.GenericAddresses(3)
+	blockIDs := mocks.GenericBlockIDs(3)
+	txIDs := mocks.GenericTransactionIDs(3)
+
+	transfer := func(address flow.Address) *object.Operation {
+		return &object.Operation{
+			Type:      dps.OperationTransfer,
+			AccountID: identifier.Account{Address: address.Hex()},
+		}
+	}
+	transaction := func(txID flow.Identifier, ops ...*object.Operation) *object.Transaction {
+		return &object.Transaction{
+			ID:         identifier.Transaction{Hash: txID.String()},
+			Operations: ops,
+		}
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := history.New(inMemoryDB(t))
+		require.NoError(t, err)
+
+		err = store.Index(10, blockIDs[0], []*object.Transaction{
+			transaction(txIDs[0], transfer(addresses[0]), transfer(addresses[1])),
+			transaction(txIDs[1], transfer(addresses[1])),
+		})
+		require.NoError(t, err)
+		err = store.Index(11, blockIDs[1], []*object.Transaction{
+			transaction(txIDs[2], transfer(addresses[0]), transfer(addresses[0])),
+		})
+		require.NoError(t, err)
+
+		first, last, ok := store.Range()
+		require.True(t, ok)
+		assert.Equal(t, uint64(10), first)
+		assert.Equal(t, uint64(11), last)
+
+		refs, err := store.Transactions(addresses[0], 10, 0, 11, 10)
+		require.NoError(t, err)
+		want := []history.Reference{
+			{Height: 10, Index: 0, BlockID: blockIDs[0], TxID: txIDs[0]},
+			{Height: 11, Index: 0, BlockID: blockIDs[1], TxID: txIDs[2]},
+		}
+		assert.Equal(t, want, refs)
+
+		refs, err = store.Transactions(addresses[1], 10, 0, 11, 10)
+		require.NoError(t, err)
+		want = []history.Reference{
+			{Height: 10, Index: 0, BlockID: blockIDs[0], TxID: txIDs[0]},
+			{Height: 10, Index: 1, BlockID: blockIDs[0], TxID: txIDs[1]},
+		}
+		assert.Equal(t, want, refs)
+
+		refs, err = store.Transactions(addresses[2], 10, 0, 11, 10)
+		require.NoError(t, err)
+		assert.Empty(t, refs)
+	})
+
+	t.Run("respects bounds and limit", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := history.New(inMemoryDB(t))
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			err = store.Index(uint64(10+i), blockIDs[i], []*object.Transaction{
+				transaction(txIDs[0], transfer(addresses[0])),
+				transaction(txIDs[1], transfer(addresses[0])),
+			})
+			require.NoError(t, err)
+		}
+
+		refs, err := store.Transactions(addresses[0], 10, 1, 11, 10)
+		require.NoError(t, err)
+		require.Len(t, refs, 3)
+		assert.Equal(t, uint64(10), refs[0].Height)
+		assert.Equal(t, uint32(1), refs[0].Index)
+		assert.Equal(t, uint64(11), refs[2].Height)
+
+		refs, err = store.Transactions(addresses[0], 11, 0, 12, 3)
+		require.NoError(t, err)
+		require.Len(t, refs, 3)
+		assert.Equal(t, uint64(12), refs[2].Height)
+		assert.Equal(t, uint32(0), refs[2].Index)
+	})
+
+	t.Run("includes operations of every type", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := history.New(inMemoryDB(t))
+		require.NoError(t, err)
+
+		fees := transfer(addresses[0])
+		fees.Type = configuration.OperationFeeCollection
+		mint := transfer(addresses[1])
+		mint.Type = configuration.OperationMint
+		template := transfer(addresses[2])
+		template.Type = configuration.OperationTemplate
+		err = store.Index(10, blockIDs[0], []*object.Transaction{
+			transaction(txIDs[0], fees),
+			transaction(txIDs[1], mint),
+			transaction(txIDs[2], template),
+		})
+		require.NoError(t, err)
+
+		for _, address := range addresses {
+			refs, err := store.Transactions(address, 10, 0, 10, 10)
+			require.NoError(t, err)
+			assert.Len(t, refs, 1)
+		}
+	})
+
+	t.Run("drops histories of another version", func(t *testing.T) {
+		t.Parallel()
+
+		db := inMemoryDB(t)
+		store, err := history.New(db)
+		require.NoError(t, err)
+		err = store.Index(10, blockIDs[0], []*object.Transaction{transaction(txIDs[0], transfer(addresses[0]))})
+		require.NoError(t, err)
+
+		err = db.Update(func(tx *badger.Txn) error {
+			return tx.Set([]byte{3}, []byte{0, 0, 0, 1})
+		})
+		require.NoError(t, err)
+
+		store, err = history.New(db)
+		require.NoError(t, err)
+		_, _, ok := store.Range()
+		assert.False(t, ok)
+		refs, err := store.Transactions(addresses[0], 10, 0, 10, 10)
+		require.NoError(t, err)
+		assert.Empty(t, refs)
+	})
+
+	t.Run("finds latest activity", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := history.New(inMemoryDB(t))
+		require.NoError(t, err)
+
+		err = store.Index(10, blockIDs[0], []*object.Transaction{
+			transaction(txIDs[0], transfer(addresses[1])),
+			transaction(txIDs[1], transfer(addresses[0])),
+		})
+		require.NoError(t, err)
+		err = store.Index(11, blockIDs[1], []*object.Transaction{transaction(txIDs[2], transfer(addresses[1]))})
+		require.NoError(t, err)
+		err = store.Index(12, blockIDs[2], nil)
+		require.NoError(t, err)
+
+		latest, found, err := store.Latest(addresses[0], 12)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, uint64(10), latest)
+
+		latest, found, err = store.Latest(addresses[1], 12)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, uint64(11), latest)
+
+		latest, found, err = store.Latest(addresses[1], 10)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, uint64(10), latest)
+
+		_, found, err = store.Latest(addresses[0], 9)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		_, found, err = store.Latest(addresses[2], 12)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("rejects gaps in heights", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := history.New(inMemoryDB(t))
+		require.NoError(t, err)
+
+		err = store.Index(10, blockIDs[0], nil)
+		require.NoError(t, err)
+		err = store.Index(12, blockIDs[2], nil)
+		assert.Error(t, err)
+		err = store.Index(10, blockIDs[0], nil)
+		assert.Error(t, err)
+
+		_, last, _ := store.Range()
+		assert.Equal(t, uint64(10), last)
+	})
+
+	t.Run("handles empty store", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := history.New(inMemoryDB(t))
+		require.NoError(t, err)
+
+		_, _, ok := store.Range()
+		assert.False(t, ok)
+	})
+
+	t.Run("keeps range across restarts", func(t *testing.T) {
+		t.Parallel()
+
+		db := inMemoryDB(t)
+		store, err := history.New(db)
+		require.NoError(t, err)
+		err = store.Index(10, blockIDs[0], []*object.Transaction{transaction(txIDs[0], transfer(addresses[0]))})
+		require.NoError(t, err)
+		err = store.Index(11, blockIDs[1], nil)
+		require.NoError(t, err)
+
+		store, err = history.New(db)
+		require.NoError(t, err)
+
+		first, last, ok := store.Range()
+		require.True(t, ok)
+		assert.Equal(t, uint64(10), first)
+		assert.Equal(t, uint64(11), last)
+
+		refs, err := store.Transactions(addresses[0], 10, 0, 11, 10)
+		require.NoError(t, err)
+		assert.Len(t, refs, 1)
+	})
+}
+
+func inMemoryDB(t *testing.T) *badger.DB {
+	t.Helper()
+
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := badger.Open(opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
@@ -14,9 +14,53 @@
 
 package identifier
 
+import (
+	"encoding/hex"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
 // Account uniquely identifies an account within a network. No sub-accounts are used
-// in this implementation for now, though they will probably have to be added to support
-// staking on Coinbase in the future.
+// in this implementation; they are only decoded so that requests for them can be
+// rejected instead of being served the balance of the parent account.
 type Account struct {
+	Address    string      `json:"address"`
+	SubAccount *SubAccount `json:"sub_account,omitempty"`
+}
+
+// SubAccount identifies a sub-account of an account.
+type SubAccount struct {
 	Address string `json:"address"`
 }
+
+// Validate checks that the account identifier is syntactically valid, which
+// means it holds a hex-encoded Flow address. Whether the address is valid for
+// a given chain is not checked.
+func (a Account) Validate() error {
+
+	if a.Address == "" {
+		return failure.InvalidAccountAddress{
+			Description: failure.NewDescription(addressEmpty),
+			WantLength:  hexAddressSize,
+			HaveLength:  0,
+		}
+	}
+
+	if len(a.Address) != hexAddressSize {
+		return failure.InvalidAccountAddress{
+			Description: failure.NewDescription(addressLength),
+			WantLength:  hexAddressSize,
+			HaveLength:  len(a.Address),
+		}
+	}
+
+	_, err := hex.DecodeString(a.Address)
+	if err != nil {
+		return failure.InvalidAccount{
+			Address:     a.Address,
+			Description: failure.NewDescription(addressInvalid),
+		}
+	}
+
+	return nil
+}
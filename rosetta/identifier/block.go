@@ -14,9 +14,43 @@
 
 package identifier
 
+import (
+	"encoding/hex"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
 // Block uniquely identifies a block in a particular network. As the view is not
 // unique between sporks, index refers to the block height.
 type Block struct {
 	Index *uint64 `json:"index,omitempty"`
 	Hash  string  `json:"hash,omitempty"`
 }
+
+// Validate checks that the block identifier is syntactically valid. Both fields
+// are optional, but a given hash has to be a hex-encoded Flow identifier.
+func (b Block) Validate() error {
+
+	if b.Hash == "" {
+		return nil
+	}
+
+	if len(b.Hash) != hexHashSize {
+		return failure.InvalidBlockHash{
+			Description: failure.NewDescription(blockLength),
+			WantLength:  hexHashSize,
+			HaveLength:  len(b.Hash),
+		}
+	}
+
+	_, err := hex.DecodeString(b.Hash)
+	if err != nil {
+		return failure.InvalidBlock{
+			Description: failure.NewDescription(blockInvalid,
+				failure.WithString("block_hash", b.Hash),
+			),
+		}
+	}
+
+	return nil
+}
@@ -14,14 +14,57 @@
 
 package identifier
 
+import (
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
 // Currency is composed of a canonical symbol and decimals. The `decimals` value
 // is used to convert an amount value from atomic units (such as satoshis) to
 // standard units (such as bitcoins). As monetary values in Flow are provided as
 // an unsigned fixed point value with 8 decimals, simply use the full integer
-// with 8 decimals in the currency struct. The symbol is always `FLOW`.
+// with 8 decimals in the currency struct.
 //
-// An example of metadata given in the Rosetta API documentation is `Issuer`.
+// For any token other than FLOW, the metadata identifies the fungible token
+// contract on-chain, so that integrators can map the symbol unambiguously.
 type Currency struct {
-	Symbol   string `json:"symbol"`
-	Decimals uint   `json:"decimals,omitempty"`
+	Symbol   string            `json:"symbol"`
+	Decimals uint              `json:"decimals,omitempty"`
+	Metadata *CurrencyMetadata `json:"metadata,omitempty"`
+}
+
+// CurrencyMetadata contains the address and name of the contract that defines
+// a token, as well as the fully qualified Cadence type of its vault, such as
+// `A.1654653399040a61.FlowToken.Vault`.
+type CurrencyMetadata struct {
+	ContractAddress string `json:"contract_address"`
+	ContractName    string `json:"contract_name"`
+	VaultType       string `json:"vault_type"`
+}
+
+// Validate checks that the currency identifier is syntactically valid. The
+// symbol is required, and as all tokens on Flow use `UFix64` amounts, the
+// decimals are either omitted or equal to the Flow decimals. Whether the
+// symbol is known is not checked.
+func (c Currency) Validate() error {
+
+	if c.Symbol == "" {
+		return failure.InvalidCurrency{
+			Symbol:      c.Symbol,
+			Decimals:    c.Decimals,
+			Description: failure.NewDescription(symbolEmpty),
+		}
+	}
+
+	if c.Decimals != 0 && c.Decimals != dps.FlowDecimals {
+		return failure.InvalidCurrency{
+			Symbol:   c.Symbol,
+			Decimals: c.Decimals,
+			Description: failure.NewDescription(decimalsMismatch,
+				failure.WithInt("want_decimals", dps.FlowDecimals),
+			),
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,42 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package identifier
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Identifiers use hex encoding, which takes two characters for every byte.
+const (
+	hexHashSize    = 2 * len(flow.ZeroID)
+	hexAddressSize = 2 * flow.AddressLength
+)
+
+// Error descriptions for syntactically invalid identifiers.
+const (
+	blockLength  = "block identifier has invalid hash field length"
+	blockInvalid = "block hash is not a valid hex-encoded string"
+
+	addressEmpty   = "account identifier has empty address field"
+	addressLength  = "account identifier has invalid address field length"
+	addressInvalid = "account address is not a valid hex-encoded string"
+
+	txHashEmpty   = "transaction identifier has empty hash field"
+	txLength      = "transaction identifier has invalid hash field length"
+	txHashInvalid = "transaction hash is not a valid hex-encoded string"
+
+	symbolEmpty      = "currency identifier has empty symbol field"
+	decimalsMismatch = "currency decimals mismatch with authoritative decimals for symbol"
+)
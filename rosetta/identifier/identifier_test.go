@@ -0,0 +1,138 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package identifier_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestBlock_Validate(t *testing.T) {
+	height := mocks.GenericHeight
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, identifier.Block{}.Validate())
+		assert.NoError(t, identifier.Block{Index: &height}.Validate())
+		assert.NoError(t, identifier.Block{Hash: mocks.GenericHeader.ID().String()}.Validate())
+	})
+
+	t.Run("handles invalid hash length", func(t *testing.T) {
+		t.Parallel()
+
+		err := identifier.Block{Hash: "abcd"}.Validate()
+		assert.True(t, errors.As(err, &failure.InvalidBlockHash{}))
+	})
+
+	t.Run("handles invalid hash encoding", func(t *testing.T) {
+		t.Parallel()
+
+		hash := "zz" + mocks.GenericHeader.ID().String()[2:]
+		err := identifier.Block{Hash: hash}.Validate()
+		assert.True(t, errors.As(err, &failure.InvalidBlock{}))
+	})
+}
+
+func TestAccount_Validate(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		err := identifier.Account{Address: mocks.GenericAddress(0).String()}.Validate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("handles empty address", func(t *testing.T) {
+		t.Parallel()
+
+		err := identifier.Account{}.Validate()
+		assert.True(t, errors.As(err, &failure.InvalidAccountAddress{}))
+	})
+
+	t.Run("handles invalid address length", func(t *testing.T) {
+		t.Parallel()
+
+		err := identifier.Account{Address: "abcd"}.Validate()
+		assert.True(t, errors.As(err, &failure.InvalidAccountAddress{}))
+	})
+
+	t.Run("handles invalid address encoding", func(t *testing.T) {
+		t.Parallel()
+
+		err := identifier.Account{Address: "zz" + mocks.GenericAddress(0).String()[2:]}.Validate()
+		assert.True(t, errors.As(err, &failure.InvalidAccount{}))
+	})
+}
+
+func TestTransaction_Validate(t *testing.T) {
+	txID := mocks.GenericTransaction(0).ID().String()
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, identifier.Transaction{Hash: txID}.Validate())
+	})
+
+	t.Run("handles empty hash", func(t *testing.T) {
+		t.Parallel()
+
+		err := identifier.Transaction{}.Validate()
+		assert.True(t, errors.As(err, &failure.InvalidTransactionHash{}))
+	})
+
+	t.Run("handles invalid hash length", func(t *testing.T) {
+		t.Parallel()
+
+		err := identifier.Transaction{Hash: "abcd"}.Validate()
+		assert.True(t, errors.As(err, &failure.InvalidTransactionHash{}))
+	})
+
+	t.Run("handles invalid hash encoding", func(t *testing.T) {
+		t.Parallel()
+
+		err := identifier.Transaction{Hash: "zz" + txID[2:]}.Validate()
+		assert.True(t, errors.As(err, &failure.InvalidTransaction{}))
+	})
+}
+
+func TestCurrency_Validate(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, identifier.Currency{Symbol: dps.FlowSymbol}.Validate())
+		assert.NoError(t, identifier.Currency{Symbol: dps.FlowSymbol, Decimals: dps.FlowDecimals}.Validate())
+	})
+
+	t.Run("handles empty symbol", func(t *testing.T) {
+		t.Parallel()
+
+		err := identifier.Currency{Decimals: dps.FlowDecimals}.Validate()
+		assert.True(t, errors.As(err, &failure.InvalidCurrency{}))
+	})
+
+	t.Run("handles invalid decimals", func(t *testing.T) {
+		t.Parallel()
+
+		err := identifier.Currency{Symbol: dps.FlowSymbol, Decimals: 7}.Validate()
+		assert.True(t, errors.As(err, &failure.InvalidCurrency{}))
+	})
+}
@@ -15,13 +15,19 @@
 package identifier
 
 // Network specifies which network a particular object is associated with. The
-// blockchain field is always set to `flow` and the network is always set to
-// `mainnet`.
+// blockchain field is always set to `flow` and the network to the Flow chain ID,
+// such as `flow-mainnet`.
 //
-// We are omitting the `SubNetwork` field for now, but we could use it in the
-// future to distinguish between the networks of different sporks (i.e.
-// `candidate4` or `mainnet-5`).
+// The sub-network is optional, and distinguishes between the networks of
+// different sporks (i.e. `mainnet-16` or `mainnet-17`) when a single server
+// serves several of them.
 type Network struct {
-	Blockchain string `json:"blockchain"`
-	Network    string `json:"network"`
+	Blockchain string      `json:"blockchain"`
+	Network    string      `json:"network"`
+	SubNetwork *SubNetwork `json:"sub_network_identifier,omitempty"`
+}
+
+// SubNetwork identifies a spork within a network.
+type SubNetwork struct {
+	Network string `json:"network"`
 }
@@ -14,8 +14,45 @@
 
 package identifier
 
+import (
+	"encoding/hex"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
 // Transaction uniquely identifies a transaction in a particular network and
 // block.
 type Transaction struct {
 	Hash string `json:"hash"`
 }
+
+// Validate checks that the transaction identifier is syntactically valid, which
+// means it holds a hex-encoded Flow identifier.
+func (t Transaction) Validate() error {
+
+	if t.Hash == "" {
+		return failure.InvalidTransactionHash{
+			Description: failure.NewDescription(txHashEmpty),
+			WantLength:  hexHashSize,
+			HaveLength:  0,
+		}
+	}
+
+	if len(t.Hash) != hexHashSize {
+		return failure.InvalidTransactionHash{
+			Description: failure.NewDescription(txLength),
+			WantLength:  hexHashSize,
+			HaveLength:  len(t.Hash),
+		}
+	}
+
+	_, err := hex.DecodeString(t.Hash)
+	if err != nil {
+		return failure.InvalidTransaction{
+			Hash:        t.Hash,
+			Description: failure.NewDescription(txHashInvalid),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package integrity
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	sdk "github.com/onflow/flow-go-sdk"
+)
+
+// API represents something that can be used to retrieve the block headers of
+// the Flow network by height, such as the client of an Access API.
+type API interface {
+	GetBlockHeaderByHeight(ctx context.Context, height uint64, opts ...grpc.CallOption) (*sdk.BlockHeader, error)
+}
@@ -0,0 +1,28 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package integrity
+
+// Config contains the configuration options for the integrity checker.
+type Config struct {
+	Samples uint
+}
+
+// WithSamples sets how many heights the checker verifies, spread evenly over the
+// range of indexed heights, including the first and the last one.
+func WithSamples(samples uint) func(*Config) {
+	return func(c *Config) {
+		c.Samples = samples
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package integrity
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Checker verifies that the indexed blocks are the ones of the Flow network, by
+// comparing the IDs of a sample of indexed block headers to those that the Access
+// API returns for the same heights. This catches indexes that were built for
+// another chain or fork, or that were corrupted, before they serve wrong data.
+type Checker struct {
+	cfg   Config
+	index Index
+	api   API
+}
+
+// Report is the result of an integrity check.
+type Report struct {
+	First      uint64
+	Last       uint64
+	Checked    uint
+	Skipped    uint
+	Mismatches []Mismatch
+}
+
+// Mismatch is an indexed block header whose ID differs from the one of the Flow
+// network at the same height.
+type Mismatch struct {
+	Height   uint64
+	Indexed  flow.Identifier
+	Upstream flow.Identifier
+}
+
+// New creates a new integrity checker for the given index, using the given API
+// to get the block headers of the Flow network.
+func New(index Index, api API, options ...func(*Config)) *Checker {
+
+	cfg := Config{
+		Samples: 16,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	c := Checker{
+		cfg:   cfg,
+		index: index,
+		api:   api,
+	}
+
+	return &c
+}
+
+// Check verifies the sampled heights and reports the mismatches it finds. The
+// Access API only serves the heights of its own spork, so heights that it does
+// not know are skipped rather than reported. It only fails if the check itself
+// can not be completed.
+func (c *Checker) Check(ctx context.Context) (Report, error) {
+
+	first, err := c.index.First()
+	if err != nil {
+		return Report{}, fmt.Errorf("could not get first indexed height: %w", err)
+	}
+	last, err := c.index.Last()
+	if err != nil {
+		return Report{}, fmt.Errorf("could not get last indexed height: %w", err)
+	}
+
+	report := Report{
+		First: first,
+		Last:  last,
+	}
+	for _, height := range sample(first, last, c.cfg.Samples) {
+
+		header, err := c.index.Header(height)
+		if err != nil {
+			return Report{}, fmt.Errorf("could not get indexed header (height: %d): %w", height, err)
+		}
+
+		upstream, err := c.api.GetBlockHeaderByHeight(ctx, height)
+		code := status.Code(err)
+		if code == codes.NotFound || code == codes.OutOfRange {
+			report.Skipped++
+			continue
+		}
+		if err != nil {
+			return Report{}, fmt.Errorf("could not get upstream header (height: %d): %w", height, err)
+		}
+
+		report.Checked++
+		indexed := header.ID()
+		if flow.Identifier(upstream.ID) == indexed {
+			continue
+		}
+		mismatch := Mismatch{
+			Height:   height,
+			Indexed:  indexed,
+			Upstream: flow.Identifier(upstream.ID),
+		}
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+
+	return report, nil
+}
+
+// Err returns an error describing the mismatches of the report, if it has any.
+func (r Report) Err() error {
+	if len(r.Mismatches) == 0 {
+		return nil
+	}
+	m := r.Mismatches[0]
+	return fmt.Errorf("indexed blocks do not match the Flow network (mismatches: %d, checked: %d, height: %d, indexed: %x, upstream: %x)",
+		len(r.Mismatches), r.Checked, m.Height, m.Indexed, m.Upstream)
+}
+
+// sample returns the given amount of heights, spread evenly between the first
+// and the last height, both included. If the range holds fewer heights, all of
+// them are returned.
+func sample(first uint64, last uint64, samples uint) []uint64 {
+
+	if samples == 0 || last < first {
+		return nil
+	}
+
+	span := last - first
+	if uint64(samples) > span {
+		heights := make([]uint64, 0, span+1)
+		for height := first; height <= last; height++ {
+			heights = append(heights, height)
+		}
+		return heights
+	}
+	if samples == 1 {
+		return []uint64{last}
+	}
+
+	heights := make([]uint64, 0, samples)
+	for i := uint64(0); i < uint64(samples); i++ {
+		heights = append(heights, first+span*i/uint64(samples-1))
+	}
+
+	return heights
+}
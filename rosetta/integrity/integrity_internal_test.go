@@ -0,0 +1,154 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	sdk "github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestChecker_Check(t *testing.T) {
+
+	// Each height has a header with its own ID, which the upstream API returns
+	// unless told otherwise.
+	headers := make(map[uint64]*flow.Header)
+	for height := uint64(100); height <= 200; height++ {
+		headers[height] = &flow.Header{ChainID: flow.Mainnet, Height: height}
+	}
+	index := func() *mocks.Reader {
+		index := mocks.BaselineReader(t)
+		index.FirstFunc = func() (uint64, error) {
+			return 100, nil
+		}
+		index.LastFunc = func() (uint64, error) {
+			return 200, nil
+		}
+		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			return headers[height], nil
+		}
+		return index
+	}
+	upstream := func(height uint64) *sdk.BlockHeader {
+		return &sdk.BlockHeader{ID: sdk.Identifier(headers[height].ID()), Height: height}
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var heights []uint64
+		api := mocks.BaselineAccessAPI(t)
+		api.GetBlockHeaderByHeightFunc = func(_ context.Context, height uint64, _ ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			heights = append(heights, height)
+			return upstream(height), nil
+		}
+
+		c := New(index(), api, WithSamples(5))
+		report, err := c.Check(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{100, 125, 150, 175, 200}, heights)
+		assert.Equal(t, uint(5), report.Checked)
+		assert.Empty(t, report.Mismatches)
+		assert.NoError(t, report.Err())
+	})
+
+	t.Run("reports mismatching headers", func(t *testing.T) {
+		t.Parallel()
+
+		api := mocks.BaselineAccessAPI(t)
+		api.GetBlockHeaderByHeightFunc = func(_ context.Context, height uint64, _ ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			if height == 150 {
+				return &sdk.BlockHeader{ID: sdk.Identifier(mocks.GenericHeader.ID()), Height: height}, nil
+			}
+			return upstream(height), nil
+		}
+
+		c := New(index(), api, WithSamples(3))
+		report, err := c.Check(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(3), report.Checked)
+		want := []Mismatch{{Height: 150, Indexed: headers[150].ID(), Upstream: mocks.GenericHeader.ID()}}
+		assert.Equal(t, want, report.Mismatches)
+		assert.Error(t, report.Err())
+	})
+
+	t.Run("skips heights unknown upstream", func(t *testing.T) {
+		t.Parallel()
+
+		api := mocks.BaselineAccessAPI(t)
+		api.GetBlockHeaderByHeightFunc = func(_ context.Context, height uint64, _ ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			if height < 150 {
+				return nil, status.Error(codes.NotFound, "not found")
+			}
+			return upstream(height), nil
+		}
+
+		c := New(index(), api, WithSamples(3))
+		report, err := c.Check(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), report.Checked)
+		assert.Equal(t, uint(1), report.Skipped)
+		assert.Empty(t, report.Mismatches)
+	})
+
+	t.Run("handles upstream failure", func(t *testing.T) {
+		t.Parallel()
+
+		api := mocks.BaselineAccessAPI(t)
+		api.GetBlockHeaderByHeightFunc = func(context.Context, uint64, ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		}
+
+		c := New(index(), api)
+		_, err := c.Check(context.Background())
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles index failure", func(t *testing.T) {
+		t.Parallel()
+
+		failing := index()
+		failing.HeaderFunc = func(uint64) (*flow.Header, error) {
+			return nil, mocks.GenericError
+		}
+
+		c := New(failing, mocks.BaselineAccessAPI(t))
+		_, err := c.Check(context.Background())
+
+		assert.Error(t, err)
+	})
+}
+
+func TestSample(t *testing.T) {
+	assert.Equal(t, []uint64{10, 15, 20}, sample(10, 20, 3))
+	assert.Equal(t, []uint64{10, 11, 12}, sample(10, 12, 5))
+	assert.Equal(t, []uint64{20}, sample(10, 20, 1))
+	assert.Equal(t, []uint64{7}, sample(7, 7, 4))
+	assert.Empty(t, sample(10, 20, 0))
+}
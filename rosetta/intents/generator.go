@@ -0,0 +1,26 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package intents
+
+// Generator represents something that can generate the Cadence scripts of the
+// transactions whose intents are known.
+type Generator interface {
+	TransferTokens(symbol string) ([]byte, error)
+	TransferFUSD() ([]byte, error)
+	StakeNewTokens() ([]byte, error)
+	RequestUnstaking() ([]byte, error)
+	WithdrawUnstakedTokens() ([]byte, error)
+	WithdrawRewardedTokens() ([]byte, error)
+}
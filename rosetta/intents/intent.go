@@ -0,0 +1,220 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package intents
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/onflow/cadence"
+	cjson "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+// Names of the known transaction scripts.
+const (
+	NameTransferTokens         = "transfer_tokens"
+	NameTransferFUSD           = "transfer_fusd"
+	NameStakeNewTokens         = "stake_new_tokens"
+	NameRequestUnstaking       = "request_unstaking"
+	NameWithdrawUnstakedTokens = "withdraw_unstaked_tokens"
+	NameWithdrawRewardedTokens = "withdraw_rewarded_tokens"
+)
+
+// Kinds of intents. Transfers move an amount of tokens from their authorizer to
+// the recipient given in their arguments, while staking actions are taken by
+// their authorizer on a node or delegator of its staking collection.
+const (
+	KindTransfer = "transfer"
+	KindStaking  = "staking"
+)
+
+// Intent describes what the transactions of a known script intend to do, which
+// is enough to derive their operations from their arguments.
+type Intent struct {
+	Name   string
+	Kind   string
+	Symbol string
+}
+
+// Operations derives the operations that a transaction with the given authorizer
+// and arguments intends to execute. Their status is left empty, and their indices
+// start at zero, for the caller to fill in. Transfers have a withdrawal from the
+// authorizer followed by the related deposit to the recipient. Staking actions
+// don't move tokens between accounts by themselves, so they have a single template
+// operation of the authorizer, with a zero amount and the decoded arguments in
+// its metadata.
+func (i Intent) Operations(authorizer flow.Address, args [][]byte) ([]object.Operation, error) {
+	switch i.Kind {
+	case KindTransfer:
+		return i.transfer(authorizer, args)
+	case KindStaking:
+		return i.staking(authorizer, args)
+	default:
+		return nil, fmt.Errorf("unknown intent kind (kind: %s)", i.Kind)
+	}
+}
+
+func (i Intent) transfer(authorizer flow.Address, args [][]byte) ([]object.Operation, error) {
+
+	values, err := decode(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	units, ok := values[0].(cadence.UFix64)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount argument (type: %T)", values[0])
+	}
+	receiver, ok := values[1].(cadence.Address)
+	if !ok {
+		return nil, fmt.Errorf("invalid receiver argument (type: %T)", values[1])
+	}
+
+	withdrawal := object.Operation{
+		ID: identifier.Operation{
+			Index: 0,
+		},
+		Type: dps.OperationTransfer,
+		AccountID: identifier.Account{
+			Address: authorizer.String(),
+		},
+		Amount: object.Amount{
+			Value:    amount.FormatSigned(uint64(units), true),
+			Currency: i.currency(),
+		},
+	}
+	deposit := object.Operation{
+		ID: identifier.Operation{
+			Index: 1,
+		},
+		RelatedIDs: []identifier.Operation{
+			{Index: 0},
+		},
+		Type: dps.OperationTransfer,
+		AccountID: identifier.Account{
+			Address: flow.Address(receiver).String(),
+		},
+		Amount: object.Amount{
+			Value:    amount.FormatSigned(uint64(units), false),
+			Currency: i.currency(),
+		},
+	}
+
+	return []object.Operation{withdrawal, deposit}, nil
+}
+
+func (i Intent) staking(authorizer flow.Address, args [][]byte) ([]object.Operation, error) {
+
+	values, err := decode(args, 3)
+	if err != nil {
+		return nil, err
+	}
+	nodeID, ok := values[0].(cadence.String)
+	if !ok {
+		return nil, fmt.Errorf("invalid node ID argument (type: %T)", values[0])
+	}
+	optional, ok := values[1].(cadence.Optional)
+	if !ok {
+		return nil, fmt.Errorf("invalid delegator ID argument (type: %T)", values[1])
+	}
+	units, ok := values[2].(cadence.UFix64)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount argument (type: %T)", values[2])
+	}
+
+	arguments := map[string]string{
+		"node_id": string(nodeID),
+		"amount":  amount.FromUFix64(units),
+	}
+	var delegatorID *uint32
+	if optional.Value != nil {
+		value, ok := optional.Value.(cadence.UInt32)
+		if !ok {
+			return nil, fmt.Errorf("invalid delegator ID argument (type: %T)", optional.Value)
+		}
+		arguments["delegator_id"] = strconv.FormatUint(uint64(value), 10)
+		id := uint32(value)
+		delegatorID = &id
+	}
+
+	// Withdrawals of rewarded tokens pay out the rewards of the node or delegator,
+	// while the other actions move tokens into or out of the staking table.
+	metadata := object.OperationMetadata{
+		Template:  i.Name,
+		Arguments: arguments,
+	}
+	switch i.Name {
+	case NameWithdrawRewardedTokens:
+		metadata.Reward = &object.RewardMetadata{
+			NodeID:      string(nodeID),
+			DelegatorID: delegatorID,
+		}
+	default:
+		metadata.Staking = &object.StakingMetadata{
+			NodeID:      string(nodeID),
+			DelegatorID: delegatorID,
+		}
+	}
+
+	op := object.Operation{
+		ID: identifier.Operation{
+			Index: 0,
+		},
+		Type: configuration.OperationTemplate,
+		AccountID: identifier.Account{
+			Address: authorizer.String(),
+		},
+		Amount: object.Amount{
+			Value:    "0",
+			Currency: i.currency(),
+		},
+		Metadata: &metadata,
+	}
+
+	return []object.Operation{op}, nil
+}
+
+// currency returns the currency of the intent. All known tokens are Cadence
+// fungible tokens, whose amounts are UFix64 values with the same decimals.
+func (i Intent) currency() identifier.Currency {
+	return identifier.Currency{
+		Symbol:   i.Symbol,
+		Decimals: dps.FlowDecimals,
+	}
+}
+
+func decode(args [][]byte, want int) ([]cadence.Value, error) {
+
+	if len(args) != want {
+		return nil, fmt.Errorf("invalid number of arguments (have: %d, want: %d)", len(args), want)
+	}
+
+	values := make([]cadence.Value, 0, len(args))
+	for index, arg := range args {
+		value, err := cjson.Decode(arg)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode argument (index: %d): %w", index, err)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
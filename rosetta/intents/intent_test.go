@@ -0,0 +1,173 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package intents_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	cjson "github.com/onflow/cadence/encoding/json"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/intents"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestIntent_Operations(t *testing.T) {
+	sender := mocks.GenericAddress(0)
+	receiver := mocks.GenericAddress(1)
+
+	encode := func(values ...cadence.Value) [][]byte {
+		var args [][]byte
+		for _, value := range values {
+			arg, err := cjson.Encode(value)
+			require.NoError(t, err)
+			args = append(args, arg)
+		}
+		return args
+	}
+
+	transfer := intents.Intent{
+		Name:   intents.NameTransferFUSD,
+		Kind:   intents.KindTransfer,
+		Symbol: "FUSD",
+	}
+	staking := intents.Intent{
+		Name:   intents.NameWithdrawRewardedTokens,
+		Kind:   intents.KindStaking,
+		Symbol: dps.FlowSymbol,
+	}
+	stake := intents.Intent{
+		Name:   intents.NameStakeNewTokens,
+		Kind:   intents.KindStaking,
+		Symbol: dps.FlowSymbol,
+	}
+
+	t.Run("nominal case with transfer", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := transfer.Operations(sender, encode(cadence.UFix64(100_000_000), cadence.NewAddress(receiver)))
+
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+
+		assert.Equal(t, uint(0), got[0].ID.Index)
+		assert.Equal(t, dps.OperationTransfer, got[0].Type)
+		assert.Equal(t, sender.String(), got[0].AccountID.Address)
+		assert.Equal(t, "-100000000", got[0].Amount.Value)
+		assert.Equal(t, identifier.Currency{Symbol: "FUSD", Decimals: dps.FlowDecimals}, got[0].Amount.Currency)
+		assert.Empty(t, got[0].Status)
+
+		assert.Equal(t, uint(1), got[1].ID.Index)
+		assert.Equal(t, []identifier.Operation{{Index: 0}}, got[1].RelatedIDs)
+		assert.Equal(t, dps.OperationTransfer, got[1].Type)
+		assert.Equal(t, receiver.String(), got[1].AccountID.Address)
+		assert.Equal(t, "100000000", got[1].Amount.Value)
+		assert.Empty(t, got[1].Status)
+	})
+
+	t.Run("nominal case with staking action of node", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := staking.Operations(sender, encode(cadence.String("node"), cadence.NewOptional(nil), cadence.UFix64(100_000_000)))
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, configuration.OperationTemplate, got[0].Type)
+		assert.Equal(t, sender.String(), got[0].AccountID.Address)
+		assert.Equal(t, "0", got[0].Amount.Value)
+		require.NotNil(t, got[0].Metadata)
+		assert.Equal(t, intents.NameWithdrawRewardedTokens, got[0].Metadata.Template)
+		assert.Equal(t, map[string]string{"node_id": "node", "amount": "100000000"}, got[0].Metadata.Arguments)
+		assert.Equal(t, &object.RewardMetadata{NodeID: "node"}, got[0].Metadata.Reward)
+		assert.Nil(t, got[0].Metadata.Staking)
+	})
+
+	t.Run("nominal case with staking action of delegator", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := staking.Operations(sender, encode(cadence.String("node"), cadence.NewOptional(cadence.NewUInt32(3)), cadence.UFix64(100_000_000)))
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		require.NotNil(t, got[0].Metadata)
+		assert.Equal(t, "3", got[0].Metadata.Arguments["delegator_id"])
+		delegatorID := uint32(3)
+		assert.Equal(t, &object.RewardMetadata{NodeID: "node", DelegatorID: &delegatorID}, got[0].Metadata.Reward)
+	})
+
+	t.Run("nominal case with staking action moving tokens", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := stake.Operations(sender, encode(cadence.String("node"), cadence.NewOptional(cadence.NewUInt32(3)), cadence.UFix64(100_000_000)))
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		require.NotNil(t, got[0].Metadata)
+		assert.Equal(t, intents.NameStakeNewTokens, got[0].Metadata.Template)
+		delegatorID := uint32(3)
+		assert.Equal(t, &object.StakingMetadata{NodeID: "node", DelegatorID: &delegatorID}, got[0].Metadata.Staking)
+		assert.Nil(t, got[0].Metadata.Reward)
+	})
+
+	t.Run("handles invalid number of arguments", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := transfer.Operations(sender, encode(cadence.UFix64(100_000_000)))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles undecodable argument", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := transfer.Operations(sender, [][]byte{mocks.GenericBytes, mocks.GenericBytes})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid transfer arguments", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := transfer.Operations(sender, encode(cadence.NewAddress(receiver), cadence.UFix64(100_000_000)))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid staking arguments", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := staking.Operations(sender, encode(cadence.String("node"), cadence.NewOptional(cadence.String("3")), cadence.UFix64(100_000_000)))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles unknown kind", func(t *testing.T) {
+		t.Parallel()
+
+		intent := transfer
+		intent.Kind = "unknown"
+
+		_, err := intent.Operations(sender, encode(cadence.UFix64(100_000_000), cadence.NewAddress(receiver)))
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package intents
+
+import (
+	"fmt"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/scripts"
+	"github.com/optakt/flow-rosetta/rosetta/templates"
+)
+
+// Registry maps the hashes of known Cadence transaction scripts to what they
+// intend to do, so that transactions can be classified from their script and
+// arguments when there are no events to convert, like for failed transactions.
+// The zero value is an empty registry.
+type Registry struct {
+	hashes map[string]Intent
+}
+
+// script is a known script, along with the intent of its transactions.
+type script struct {
+	intent   Intent
+	generate func() ([]byte, error)
+}
+
+// New creates a new registry with the intents of the scripts of the given
+// generators. Each generator can produce different variants of the same
+// scripts, such as the ones for the contract addresses before a migration,
+// and all of them are recognized.
+func New(generators []Generator, options ...func(*Config)) (*Registry, error) {
+
+	var cfg Config
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	r := Registry{
+		hashes: make(map[string]Intent),
+	}
+
+	for _, generate := range generators {
+		known := []script{
+			{
+				intent:   Intent{Name: NameTransferTokens, Kind: KindTransfer, Symbol: dps.FlowSymbol},
+				generate: func() ([]byte, error) { return generate.TransferTokens(dps.FlowSymbol) },
+			},
+			{
+				intent:   Intent{Name: NameStakeNewTokens, Kind: KindStaking, Symbol: dps.FlowSymbol},
+				generate: generate.StakeNewTokens,
+			},
+			{
+				intent:   Intent{Name: NameRequestUnstaking, Kind: KindStaking, Symbol: dps.FlowSymbol},
+				generate: generate.RequestUnstaking,
+			},
+			{
+				intent:   Intent{Name: NameWithdrawUnstakedTokens, Kind: KindStaking, Symbol: dps.FlowSymbol},
+				generate: generate.WithdrawUnstakedTokens,
+			},
+			{
+				intent:   Intent{Name: NameWithdrawRewardedTokens, Kind: KindStaking, Symbol: dps.FlowSymbol},
+				generate: generate.WithdrawRewardedTokens,
+			},
+		}
+		if cfg.FUSD {
+			known = append(known, script{
+				intent:   Intent{Name: NameTransferFUSD, Kind: KindTransfer, Symbol: scripts.FUSDSymbol},
+				generate: generate.TransferFUSD,
+			})
+		}
+
+		for _, entry := range known {
+			code, err := entry.generate()
+			if err != nil {
+				return nil, fmt.Errorf("could not generate script (intent: %s): %w", entry.intent.Name, err)
+			}
+			digest := templates.Hash(code)
+			existing, ok := r.hashes[digest]
+			if ok && existing != entry.intent {
+				return nil, fmt.Errorf("duplicate intent script (intent: %s, existing: %s)", entry.intent.Name, existing.Name)
+			}
+			r.hashes[digest] = entry.intent
+		}
+	}
+
+	return &r, nil
+}
+
+// Match returns the intent of the given script, if it is known.
+func (r *Registry) Match(script []byte) (Intent, bool) {
+	intent, ok := r.hashes[templates.Hash(script)]
+	return intent, ok
+}
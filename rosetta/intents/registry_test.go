@@ -0,0 +1,138 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package intents_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/intents"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		registry, err := intents.New([]intents.Generator{mocks.BaselineGenerator(t)})
+
+		require.NoError(t, err)
+
+		got, ok := registry.Match(mocks.GenericBytes)
+		assert.True(t, ok)
+		assert.Equal(t, intents.Intent{Name: intents.NameTransferTokens, Kind: intents.KindTransfer, Symbol: dps.FlowSymbol}, got)
+
+		got, ok = registry.Match(mocks.GenericStakeScript)
+		assert.True(t, ok)
+		assert.Equal(t, intents.Intent{Name: intents.NameStakeNewTokens, Kind: intents.KindStaking, Symbol: dps.FlowSymbol}, got)
+
+		got, ok = registry.Match(mocks.GenericUnstakeScript)
+		assert.True(t, ok)
+		assert.Equal(t, intents.NameRequestUnstaking, got.Name)
+
+		got, ok = registry.Match(mocks.GenericWithdrawUnstakedScript)
+		assert.True(t, ok)
+		assert.Equal(t, intents.NameWithdrawUnstakedTokens, got.Name)
+
+		got, ok = registry.Match(mocks.GenericWithdrawRewardedScript)
+		assert.True(t, ok)
+		assert.Equal(t, intents.NameWithdrawRewardedTokens, got.Name)
+
+		_, ok = registry.Match(mocks.GenericFUSDScript)
+		assert.False(t, ok)
+	})
+
+	t.Run("nominal case with FUSD", func(t *testing.T) {
+		t.Parallel()
+
+		registry, err := intents.New([]intents.Generator{mocks.BaselineGenerator(t)}, intents.WithFUSD(true))
+
+		require.NoError(t, err)
+
+		got, ok := registry.Match(mocks.GenericFUSDScript)
+		assert.True(t, ok)
+		assert.Equal(t, intents.Intent{Name: intents.NameTransferFUSD, Kind: intents.KindTransfer, Symbol: "FUSD"}, got)
+	})
+
+	t.Run("nominal case with script variants", func(t *testing.T) {
+		t.Parallel()
+
+		legacy := mocks.BaselineGenerator(t)
+		legacy.TransferTokensFunc = func(string) ([]byte, error) {
+			return []byte(`legacy_transfer_tokens`), nil
+		}
+
+		registry, err := intents.New([]intents.Generator{mocks.BaselineGenerator(t), legacy})
+
+		require.NoError(t, err)
+
+		got, ok := registry.Match(mocks.GenericBytes)
+		assert.True(t, ok)
+		assert.Equal(t, intents.NameTransferTokens, got.Name)
+
+		got, ok = registry.Match([]byte(`legacy_transfer_tokens`))
+		assert.True(t, ok)
+		assert.Equal(t, intents.NameTransferTokens, got.Name)
+	})
+
+	t.Run("handles unknown script", func(t *testing.T) {
+		t.Parallel()
+
+		registry, err := intents.New([]intents.Generator{mocks.BaselineGenerator(t)})
+
+		require.NoError(t, err)
+
+		_, ok := registry.Match(mocks.GenericTemplateScript)
+		assert.False(t, ok)
+	})
+
+	t.Run("handles duplicate script", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.RequestUnstakingFunc = func() ([]byte, error) {
+			return mocks.GenericStakeScript, nil
+		}
+
+		_, err := intents.New([]intents.Generator{generator})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles generator failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TransferFUSDFunc = func() ([]byte, error) {
+			return nil, mocks.GenericError
+		}
+
+		_, err := intents.New([]intents.Generator{generator}, intents.WithFUSD(true))
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+
+	t.Run("empty registry", func(t *testing.T) {
+		t.Parallel()
+
+		var registry intents.Registry
+
+		_, ok := registry.Match(mocks.GenericBytes)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package interop
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Convert converts between the types of the `object`, `identifier`, `request`
+// and `response` packages and the equivalent types of any other implementation
+// of the Rosetta specification, such as the canonical types of the Rosetta SDK
+// (`github.com/coinbase/rosetta-sdk-go/types`). Both sides encode to the JSON
+// schema of the specification, so the conversion goes through that encoding
+// instead of requiring hand-written adapters, or a dependency on the SDK.
+//
+// The target must be a pointer. Fields that only exist on one side, such as
+// the metadata keys of the SDK types that this repository does not know, are
+// dropped.
+func Convert(from interface{}, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return fmt.Errorf("could not encode source: %w", err)
+	}
+	err = json.Unmarshal(data, to)
+	if err != nil {
+		return fmt.Errorf("could not decode target: %w", err)
+	}
+	return nil
+}
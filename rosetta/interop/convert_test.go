@@ -0,0 +1,203 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package interop_test
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/interop"
+	"github.com/optakt/flow-rosetta/rosetta/meta"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/request"
+)
+
+func TestConvert(t *testing.T) {
+	index := uint64(42)
+	parent := uint64(41)
+	block := object.Block{
+		ID:        identifier.Block{Index: &index, Hash: "block"},
+		ParentID:  identifier.Block{Index: &parent, Hash: "parent"},
+		Timestamp: 1_600_000_000_000,
+		Transactions: []*object.Transaction{
+			{
+				ID: identifier.Transaction{Hash: "tx"},
+				Operations: []*object.Operation{
+					{
+						ID:        identifier.Operation{Index: 0},
+						Type:      "TRANSFER",
+						Status:    "COMPLETED",
+						AccountID: identifier.Account{Address: "0x1"},
+						Amount:    object.Amount{Value: "-100", Currency: identifier.Currency{Symbol: "FLOW", Decimals: 8}},
+					},
+					{
+						ID:         identifier.Operation{Index: 1},
+						RelatedIDs: []identifier.Operation{{Index: 0}},
+						Type:       "TRANSFER",
+						Status:     "COMPLETED",
+						AccountID:  identifier.Account{Address: "0x2"},
+						Amount:     object.Amount{Value: "100", Currency: identifier.Currency{Symbol: "FLOW", Decimals: 8}},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("to SDK types", func(t *testing.T) {
+		t.Parallel()
+
+		var got types.Block
+		err := interop.Convert(block, &got)
+
+		require.NoError(t, err)
+		require.NotNil(t, got.BlockIdentifier)
+		assert.Equal(t, int64(index), got.BlockIdentifier.Index)
+		assert.Equal(t, "block", got.BlockIdentifier.Hash)
+		require.NotNil(t, got.ParentBlockIdentifier)
+		assert.Equal(t, int64(parent), got.ParentBlockIdentifier.Index)
+		assert.Equal(t, "parent", got.ParentBlockIdentifier.Hash)
+		assert.Equal(t, block.Timestamp, got.Timestamp)
+		require.Len(t, got.Transactions, 1)
+		assert.Equal(t, "tx", got.Transactions[0].TransactionIdentifier.Hash)
+		require.Len(t, got.Transactions[0].Operations, 2)
+		op := got.Transactions[0].Operations[1]
+		assert.Equal(t, int64(1), op.OperationIdentifier.Index)
+		assert.Equal(t, []*types.OperationIdentifier{{Index: 0}}, op.RelatedOperations)
+		assert.Equal(t, "TRANSFER", op.Type)
+		require.NotNil(t, op.Status)
+		assert.Equal(t, "COMPLETED", *op.Status)
+		require.NotNil(t, op.Account)
+		assert.Equal(t, "0x2", op.Account.Address)
+		require.NotNil(t, op.Amount)
+		assert.Equal(t, "100", op.Amount.Value)
+		require.NotNil(t, op.Amount.Currency)
+		assert.Equal(t, "FLOW", op.Amount.Currency.Symbol)
+		assert.Equal(t, int32(8), op.Amount.Currency.Decimals)
+	})
+
+	t.Run("from SDK types", func(t *testing.T) {
+		t.Parallel()
+
+		status := "COMPLETED"
+		sdk := types.Block{
+			BlockIdentifier:       &types.BlockIdentifier{Index: int64(index), Hash: "block"},
+			ParentBlockIdentifier: &types.BlockIdentifier{Index: int64(parent), Hash: "parent"},
+			Timestamp:             1_600_000_000_000,
+			Transactions: []*types.Transaction{
+				{
+					TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx"},
+					Operations: []*types.Operation{
+						{
+							OperationIdentifier: &types.OperationIdentifier{Index: 0},
+							Type:                "TRANSFER",
+							Status:              &status,
+							Account:             &types.AccountIdentifier{Address: "0x1"},
+							Amount:              &types.Amount{Value: "-100", Currency: &types.Currency{Symbol: "FLOW", Decimals: 8}},
+						},
+						{
+							OperationIdentifier: &types.OperationIdentifier{Index: 1},
+							RelatedOperations:   []*types.OperationIdentifier{{Index: 0}},
+							Type:                "TRANSFER",
+							Status:              &status,
+							Account:             &types.AccountIdentifier{Address: "0x2"},
+							Amount:              &types.Amount{Value: "100", Currency: &types.Currency{Symbol: "FLOW", Decimals: 8}},
+						},
+					},
+				},
+			},
+			Metadata: map[string]interface{}{"sealed": true, "unknown": "dropped"},
+		}
+
+		var got object.Block
+		err := interop.Convert(sdk, &got)
+
+		require.NoError(t, err)
+		want := block
+		want.Metadata = &object.BlockMetadata{Sealed: true}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		var sdk types.Block
+		err := interop.Convert(block, &sdk)
+		require.NoError(t, err)
+
+		var got object.Block
+		err = interop.Convert(sdk, &got)
+		require.NoError(t, err)
+
+		assert.Equal(t, block, got)
+	})
+
+	t.Run("converts requests to SDK types", func(t *testing.T) {
+		t.Parallel()
+
+		sub := identifier.Network{
+			Blockchain: "flow",
+			Network:    "testnet",
+			SubNetwork: &identifier.SubNetwork{Network: "next"},
+		}
+		req := request.Balance{
+			NetworkID:  sub,
+			BlockID:    identifier.Block{Index: &index},
+			AccountID:  identifier.Account{Address: "0x1"},
+			Currencies: []identifier.Currency{{Symbol: "FLOW", Decimals: 8}},
+		}
+
+		var got types.AccountBalanceRequest
+		err := interop.Convert(req, &got)
+
+		require.NoError(t, err)
+		want := types.AccountBalanceRequest{
+			NetworkIdentifier: &types.NetworkIdentifier{
+				Blockchain:           "flow",
+				Network:              "testnet",
+				SubNetworkIdentifier: &types.SubNetworkIdentifier{Network: "next"},
+			},
+			BlockIdentifier:   &types.PartialBlockIdentifier{Index: func(i int64) *int64 { return &i }(int64(index))},
+			AccountIdentifier: &types.AccountIdentifier{Address: "0x1"},
+			Currencies:        []*types.Currency{{Symbol: "FLOW", Decimals: 8}},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("converts definitions to SDK types", func(t *testing.T) {
+		t.Parallel()
+
+		definitions := []meta.ErrorDefinition{
+			{Code: 1, Message: "unknown block", Retriable: true},
+		}
+
+		var got []*types.Error
+		err := interop.Convert(definitions, &got)
+
+		require.NoError(t, err)
+		assert.Equal(t, []*types.Error{{Code: 1, Message: "unknown block", Retriable: true}}, got)
+	})
+
+	t.Run("handles non-pointer target", func(t *testing.T) {
+		t.Parallel()
+
+		err := interop.Convert(block, types.Block{})
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package invoker
+
+// Config contains the configuration options for the invoker.
+type Config struct {
+	CacheSize        uint64
+	ComputationLimit uint64
+	InteractionLimit uint64
+}
+
+// WithCacheSize sets the maximum size of the register cache in bytes.
+func WithCacheSize(size uint64) func(*Config) {
+	return func(c *Config) {
+		c.CacheSize = size
+	}
+}
+
+// WithComputationLimit sets the maximum amount of computation that a single
+// Cadence execution can use before it is aborted.
+func WithComputationLimit(limit uint64) func(*Config) {
+	return func(c *Config) {
+		c.ComputationLimit = limit
+	}
+}
+
+// WithInteractionLimit sets the maximum amount of bytes that a single Cadence
+// execution can read from the execution state, which bounds the memory that
+// it uses to load registers.
+func WithInteractionLimit(limit uint64) func(*Config) {
+	return func(c *Config) {
+		c.InteractionLimit = limit
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package invoker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+// Error messages of the Flow virtual machine and the Cadence runtime for
+// executions that exceed their limits.
+const (
+	computationExceeded = "computation limited exceeded"
+	interactionExceeded = "max interaction with storage has exceeded the limit"
+)
+
+// Failure descriptions for aborted executions.
+const (
+	limitComputation = "script execution exceeded computation limit"
+	limitInteraction = "script execution exceeded state interaction limit"
+	txFailed         = "transaction execution failed"
+)
+
+// Invoker retrieves account information from and executes Cadence scripts
+// against the Flow virtual machine, within the configured execution limits.
+type Invoker struct {
+	cfg   Config
+	index dps.Reader
+	vm    VirtualMachine
+	cache Cache
+}
+
+// New returns a new Invoker with the given configuration.
+func New(index dps.Reader, options ...func(*Config)) (*Invoker, error) {
+
+	// Initialize the invoker configuration with the default values of the
+	// Flow virtual machine.
+	cfg := Config{
+		CacheSize:        uint64(100_000_000), // ~100 MB default size
+		ComputationLimit: fvm.DefaultGasLimit,
+		InteractionLimit: state.DefaultMaxInteractionSize,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	rt := fvm.NewInterpreterRuntime()
+	vm := fvm.NewVirtualMachine(rt)
+
+	// Initialize the Ristretto cache with the size limit. Ristretto recommends
+	// keeping ten times as many counters as items in the cache when full.
+	// Assuming an average item size of 1 kilobyte, this is what we get.
+	cache, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: int64(cfg.CacheSize) / 1000 * 10,
+		MaxCost:     int64(cfg.CacheSize),
+		BufferItems: 64,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize cache: %w", err)
+	}
+
+	i := Invoker{
+		cfg:   cfg,
+		index: index,
+		vm:    vm,
+		cache: cache,
+	}
+
+	return &i, nil
+}
+
+// Key returns the public key of the account with the given address.
+func (i *Invoker) Key(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
+
+	account, err := i.Account(height, address)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve account: %w", err)
+	}
+
+	keys := make(map[int]flow.AccountPublicKey)
+	for _, key := range account.Keys {
+		keys[key.Index] = key
+	}
+	key, ok := keys[index]
+	if !ok {
+		return nil, fmt.Errorf("account key with given index not found")
+	}
+
+	if key.Revoked {
+		return nil, fmt.Errorf("account key with given index has been revoked")
+	}
+
+	return &key, nil
+}
+
+// Account returns the account with the given address.
+func (i *Invoker) Account(height uint64, address flow.Address) (*flow.Account, error) {
+
+	header, err := i.index.Header(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get header: %w", err)
+	}
+
+	ctx := i.context(header)
+	read := readRegister(i.index, i.cache, header.Height)
+	view := delta.NewView(read)
+
+	var account *flow.Account
+	err = i.run(func() error {
+		var err error
+		account, err = i.vm.GetAccount(ctx, address, view, programs.NewEmptyPrograms())
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get account at height %d: %w", header.Height, err)
+	}
+
+	return account, nil
+}
+
+// Script executes the given Cadence script and returns its result.
+func (i *Invoker) Script(height uint64, script []byte, arguments []cadence.Value) (cadence.Value, error) {
+
+	var args [][]byte
+	for _, argument := range arguments {
+		arg, err := json.Encode(argument)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode value: %w", err)
+		}
+		args = append(args, arg)
+	}
+
+	header, err := i.index.Header(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get header: %w", err)
+	}
+
+	ctx := i.context(header)
+	read := readRegister(i.index, i.cache, height)
+	view := delta.NewView(read)
+	proc := fvm.Script(script).WithArguments(args...)
+
+	err = i.run(func() error {
+		err := i.vm.Run(ctx, proc, view, programs.NewEmptyPrograms())
+		if err != nil {
+			return fmt.Errorf("could not run script: %w", err)
+		}
+		if proc.Err != nil {
+			return fmt.Errorf("script execution encountered error: %w", proc.Err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return proc.Value, nil
+}
+
+// Transaction executes the given transaction body against the state at the
+// given height, as the execution nodes of its chain would, and discards its
+// changes. Signatures and sequence numbers are not checked, so that
+// transactions can be executed before they are signed, but fees are deducted
+// from the payer and storage limits are enforced. Transactions that fail are
+// returned as a failed transaction.
+func (i *Invoker) Transaction(height uint64, body *flow.TransactionBody) error {
+
+	header, err := i.index.Header(height)
+	if err != nil {
+		return fmt.Errorf("could not get header: %w", err)
+	}
+
+	// Transaction fees are only deducted on the networks whose execution nodes
+	// charge them.
+	fees := header.ChainID == flow.Mainnet || header.ChainID == flow.Testnet || header.ChainID == flow.Canary
+
+	ctx := fvm.NewContextFromParent(i.context(header),
+		fvm.WithChain(header.ChainID.Chain()),
+		fvm.WithTransactionProcessors(fvm.NewTransactionInvoker(zerolog.Nop())),
+		fvm.WithTransactionFeesEnabled(fees),
+		fvm.WithAccountStorageLimit(true),
+	)
+	read := readRegister(i.index, i.cache, height)
+	view := delta.NewView(read)
+	proc := fvm.Transaction(body, 0)
+
+	err = i.run(func() error {
+		err := i.vm.Run(ctx, proc, view, programs.NewEmptyPrograms())
+		if err != nil {
+			return fmt.Errorf("could not run transaction: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if proc.Err != nil {
+		return failure.FailedTransaction{
+			Description: failure.NewDescription(txFailed,
+				failure.WithErr(proc.Err),
+			),
+			Code: uint(proc.Err.Code()),
+		}
+	}
+
+	return nil
+}
+
+// context returns the virtual machine context for executions at the given
+// block, with the configured limits.
+func (i *Invoker) context(header *flow.Header) fvm.Context {
+	return fvm.NewContext(zerolog.Nop(),
+		fvm.WithBlockHeader(header),
+		fvm.WithGasLimit(i.cfg.ComputationLimit),
+		fvm.WithMaxStateInteractionSize(i.cfg.InteractionLimit),
+	)
+}
+
+// run runs the given execution and converts errors for exceeded limits into
+// limit failures.
+func (i *Invoker) run(execute func() error) error {
+
+	err := execute()
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case strings.Contains(err.Error(), computationExceeded):
+		return failure.LimitExceeded{
+			Limit: "computation",
+			Description: failure.NewDescription(limitComputation,
+				failure.WithUint64("computation_limit", i.cfg.ComputationLimit),
+				failure.WithErr(err),
+			),
+		}
+	case strings.Contains(err.Error(), interactionExceeded):
+		return failure.LimitExceeded{
+			Limit: "interaction",
+			Description: failure.NewDescription(limitInteraction,
+				failure.WithUint64("interaction_limit", i.cfg.InteractionLimit),
+				failure.WithErr(err),
+			),
+		}
+	default:
+		return err
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package invoker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go/fvm"
+	fvmerrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestInvoker_Script(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.RunFunc = func(ctx fvm.Context, proc fvm.Procedure, _ state.View, _ *programs.Programs) error {
+			assert.Equal(t, uint64(1000), ctx.GasLimit)
+			assert.Equal(t, uint64(2000), ctx.MaxStateInteractionSize)
+			assert.Equal(t, mocks.GenericHeader, ctx.BlockHeader)
+
+			script, ok := proc.(*fvm.ScriptProcedure)
+			require.True(t, ok)
+			script.Value = mocks.GenericAmount(0)
+
+			return nil
+		}
+
+		invoke := BaselineInvoker(t, WithVM(vm), WithLimits(1000, 2000))
+
+		value, err := invoke.Script(mocks.GenericHeight, mocks.GenericBytes, []cadence.Value{mocks.GenericAmount(1)})
+
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericAmount(0), value)
+	})
+
+	t.Run("handles computation limit exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.RunFunc = func(fvm.Context, fvm.Procedure, state.View, *programs.Programs) error {
+			return fmt.Errorf("could not execute: %s: 1000", computationExceeded)
+		}
+
+		invoke := BaselineInvoker(t, WithVM(vm))
+
+		_, err := invoke.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+
+		var leErr failure.LimitExceeded
+		require.True(t, errors.As(err, &leErr))
+		assert.Equal(t, "computation", leErr.Limit)
+	})
+
+	t.Run("handles virtual machine failure", func(t *testing.T) {
+		t.Parallel()
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.RunFunc = func(fvm.Context, fvm.Procedure, state.View, *programs.Programs) error {
+			return mocks.GenericError
+		}
+
+		invoke := BaselineInvoker(t, WithVM(vm))
+
+		_, err := invoke.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+		var leErr failure.LimitExceeded
+		assert.False(t, errors.As(err, &leErr))
+	})
+
+	t.Run("handles index failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeaderFunc = func(uint64) (*flow.Header, error) {
+			return nil, mocks.GenericError
+		}
+
+		invoke := BaselineInvoker(t, WithIndex(index))
+
+		_, err := invoke.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestInvoker_Transaction(t *testing.T) {
+	body := flow.NewTransactionBody().
+		SetScript(mocks.GenericBytes).
+		SetPayer(mocks.GenericAddress(0))
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.RunFunc = func(ctx fvm.Context, proc fvm.Procedure, _ state.View, _ *programs.Programs) error {
+			assert.Equal(t, mocks.GenericHeader, ctx.BlockHeader)
+			assert.Equal(t, flow.Testnet, ctx.Chain.ChainID())
+			assert.True(t, ctx.TransactionFeesEnabled)
+			assert.True(t, ctx.LimitAccountStorage)
+			require.Len(t, ctx.TransactionProcessors, 1)
+			assert.IsType(t, &fvm.TransactionInvoker{}, ctx.TransactionProcessors[0])
+
+			tx, ok := proc.(*fvm.TransactionProcedure)
+			require.True(t, ok)
+			assert.Equal(t, body, tx.Transaction)
+
+			return nil
+		}
+
+		invoke := BaselineInvoker(t, WithVM(vm))
+
+		err := invoke.Transaction(mocks.GenericHeight, body)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("handles failed transaction", func(t *testing.T) {
+		t.Parallel()
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.RunFunc = func(_ fvm.Context, proc fvm.Procedure, _ state.View, _ *programs.Programs) error {
+			tx, ok := proc.(*fvm.TransactionProcedure)
+			require.True(t, ok)
+			tx.Err = fvmerrors.NewStorageCapacityExceededError(mocks.GenericAddress(0), 200, 100)
+
+			return nil
+		}
+
+		invoke := BaselineInvoker(t, WithVM(vm))
+
+		err := invoke.Transaction(mocks.GenericHeight, body)
+
+		var ftErr failure.FailedTransaction
+		require.True(t, errors.As(err, &ftErr))
+		assert.Equal(t, uint(fvmerrors.ErrCodeStorageCapacityExceeded), ftErr.Code)
+	})
+
+	t.Run("handles virtual machine failure", func(t *testing.T) {
+		t.Parallel()
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.RunFunc = func(fvm.Context, fvm.Procedure, state.View, *programs.Programs) error {
+			return mocks.GenericError
+		}
+
+		invoke := BaselineInvoker(t, WithVM(vm))
+
+		err := invoke.Transaction(mocks.GenericHeight, body)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+		var ftErr failure.FailedTransaction
+		assert.False(t, errors.As(err, &ftErr))
+	})
+
+	t.Run("handles index failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeaderFunc = func(uint64) (*flow.Header, error) {
+			return nil, mocks.GenericError
+		}
+
+		invoke := BaselineInvoker(t, WithIndex(index))
+
+		err := invoke.Transaction(mocks.GenericHeight, body)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestInvoker_Account(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.GetAccountFunc = func(_ fvm.Context, address flow.Address, _ state.View, _ *programs.Programs) (*flow.Account, error) {
+			assert.Equal(t, mocks.GenericAddress(0), address)
+
+			return &mocks.GenericAccount, nil
+		}
+
+		invoke := BaselineInvoker(t, WithVM(vm))
+
+		account, err := invoke.Account(mocks.GenericHeight, mocks.GenericAddress(0))
+
+		require.NoError(t, err)
+		assert.Equal(t, &mocks.GenericAccount, account)
+	})
+
+	t.Run("handles interaction limit exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.GetAccountFunc = func(fvm.Context, flow.Address, state.View, *programs.Programs) (*flow.Account, error) {
+			return nil, fmt.Errorf("[Error Code: 1106] %s (used: 2001 bytes, limit 2000 bytes)", interactionExceeded)
+		}
+
+		invoke := BaselineInvoker(t, WithVM(vm))
+
+		_, err := invoke.Account(mocks.GenericHeight, mocks.GenericAddress(0))
+
+		var leErr failure.LimitExceeded
+		require.True(t, errors.As(err, &leErr))
+		assert.Equal(t, "interaction", leErr.Limit)
+	})
+}
+
+func BaselineInvoker(t *testing.T, opts ...func(*Invoker)) *Invoker {
+	t.Helper()
+
+	i := Invoker{
+		cfg: Config{
+			ComputationLimit: fvm.DefaultGasLimit,
+			InteractionLimit: state.DefaultMaxInteractionSize,
+		},
+		index: mocks.BaselineReader(t),
+		vm:    mocks.BaselineVirtualMachine(t),
+		cache: mocks.BaselineCache(t),
+	}
+
+	for _, opt := range opts {
+		opt(&i)
+	}
+
+	return &i
+}
+
+func WithIndex(index dps.Reader) func(*Invoker) {
+	return func(invoker *Invoker) {
+		invoker.index = index
+	}
+}
+
+func WithVM(vm VirtualMachine) func(*Invoker) {
+	return func(invoker *Invoker) {
+		invoker.vm = vm
+	}
+}
+
+func WithLimits(computation uint64, interaction uint64) func(*Invoker) {
+	return func(invoker *Invoker) {
+		invoker.cfg.ComputationLimit = computation
+		invoker.cfg.InteractionLimit = interaction
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package lag
+
+import (
+	"time"
+)
+
+// Config contains the configuration options for the lag monitor.
+type Config struct {
+	MaxBlocks uint64
+	MaxDelay  time.Duration
+	Poll      time.Duration
+	Notifier  Notifier
+}
+
+// WithMaxBlocks sets the maximum number of sealed blocks that the index can lag
+// behind the Flow network before it is considered stale. Zero disables the check.
+func WithMaxBlocks(max uint64) func(*Config) {
+	return func(c *Config) {
+		c.MaxBlocks = max
+	}
+}
+
+// WithMaxDelay sets the maximum age of the latest indexed block before the index
+// is considered stale. Zero disables the check.
+func WithMaxDelay(max time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.MaxDelay = max
+	}
+}
+
+// WithPoll sets how often the sampler measures the index lag for its metrics.
+func WithPoll(poll time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.Poll = poll
+	}
+}
+
+// WithNotifier sets the notifier that the sampler reports threshold breaches to,
+// along with their resolution. No notifications are sent if it is not set.
+func WithNotifier(notify Notifier) func(*Config) {
+	return func(c *Config) {
+		c.Notifier = notify
+	}
+}
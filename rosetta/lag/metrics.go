@@ -0,0 +1,71 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package lag
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics contains the metrics that the sampler exposes about how far the index
+// lags behind the Flow network.
+type Metrics struct {
+	indexed prometheus.Gauge
+	sealed  prometheus.Gauge
+	blocks  prometheus.Gauge
+	delay   prometheus.Gauge
+	rate    prometheus.Gauge
+}
+
+// NewMetrics creates the index lag metrics and registers them with the given
+// registerer.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+
+	factory := promauto.With(reg)
+	m := Metrics{
+		indexed: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "index",
+			Name:      "indexed_height",
+			Help:      "latest block height available in the index",
+		}),
+		sealed: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "index",
+			Name:      "sealed_height",
+			Help:      "latest sealed block height according to the Access API",
+		}),
+		blocks: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "index",
+			Name:      "lag_blocks",
+			Help:      "number of sealed blocks that are not indexed yet",
+		}),
+		delay: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "index",
+			Name:      "lag_seconds",
+			Help:      "age of the latest indexed block",
+		}),
+		rate: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "index",
+			Name:      "blocks_per_second",
+			Help:      "number of blocks indexed per second since the previous sample",
+		}),
+	}
+
+	return &m
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package lag
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status is a snapshot of how far the index lags behind the Flow network.
+type Status struct {
+	Indexed uint64
+	Sealed  uint64
+	Blocks  uint64
+	Delay   time.Duration
+}
+
+// Monitor compares the latest indexed height to the latest sealed height of
+// the Flow network, so that stale replicas can be taken out of rotation.
+type Monitor struct {
+	cfg   Config
+	index Index
+	track Tracker
+	now   func() time.Time
+
+	// Degraded is the reason for which the index was flagged as degraded, if
+	// it was, which it stays until restart.
+	mutex    *sync.Mutex
+	degraded error
+}
+
+// New returns a new lag monitor for the given index, using the given tracker
+// to get the latest sealed height of the Flow network.
+func New(index Index, track Tracker, options ...func(*Config)) *Monitor {
+
+	cfg := Config{
+		MaxBlocks: 0,
+		MaxDelay:  0,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	m := Monitor{
+		cfg:   cfg,
+		index: index,
+		track: track,
+		now:   time.Now,
+		mutex: &sync.Mutex{},
+	}
+
+	return &m
+}
+
+// Status returns how far the index currently lags behind the Flow network, both
+// in sealed blocks that are not indexed yet and in age of the latest indexed block.
+func (m *Monitor) Status() (Status, error) {
+
+	indexed, err := m.index.Last()
+	if err != nil {
+		return Status{}, fmt.Errorf("could not get last indexed height: %w", err)
+	}
+	header, err := m.index.Header(indexed)
+	if err != nil {
+		return Status{}, fmt.Errorf("could not get last indexed header: %w", err)
+	}
+	sealed, err := m.track.Sealed()
+	if err != nil {
+		return Status{}, fmt.Errorf("could not get last sealed height: %w", err)
+	}
+
+	// The index can be ahead of the tracker, as the sealed height is cached
+	// for a short while, in which case it does not lag at all.
+	status := Status{
+		Indexed: indexed,
+		Sealed:  sealed,
+	}
+	if sealed > indexed {
+		status.Blocks = sealed - indexed
+	}
+	delay := m.now().Sub(header.Timestamp)
+	if delay > 0 {
+		status.Delay = delay
+	}
+
+	return status, nil
+}
+
+// Ready returns the current lag status, along with an error if the index lags
+// behind the Flow network by more than the configured thresholds, or if the lag
+// can not be determined.
+func (m *Monitor) Ready() (Status, error) {
+
+	status, err := m.Status()
+	if err != nil {
+		return Status{}, err
+	}
+
+	m.mutex.Lock()
+	degraded := m.degraded
+	m.mutex.Unlock()
+	if degraded != nil {
+		return status, fmt.Errorf("index is degraded: %w", degraded)
+	}
+
+	err = m.check(status)
+	if err != nil {
+		return status, err
+	}
+
+	return status, nil
+}
+
+// Degrade flags the index as degraded for the given reason, so that it is reported
+// as not ready regardless of its lag, for example when its blocks do not match the
+// ones of the Flow network.
+func (m *Monitor) Degrade(reason error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.degraded = reason
+}
+
+// check returns an error if the given lag status breaches one of the configured
+// thresholds.
+func (m *Monitor) check(status Status) error {
+	if m.cfg.MaxBlocks > 0 && status.Blocks > m.cfg.MaxBlocks {
+		return fmt.Errorf("index lags too many blocks behind (blocks: %d, max: %d)", status.Blocks, m.cfg.MaxBlocks)
+	}
+	if m.cfg.MaxDelay > 0 && status.Delay > m.cfg.MaxDelay {
+		return fmt.Errorf("last indexed block is too old (delay: %s, max: %s)", status.Delay.Round(time.Millisecond), m.cfg.MaxDelay)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package lag
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+var (
+	genericError  = errors.New("dummy error")
+	genericHeight = uint64(42)
+	genericTime   = time.Date(1972, 11, 12, 13, 14, 15, 16, time.UTC)
+)
+
+func TestNew(t *testing.T) {
+	index := baselineIndex()
+	track := baselineTracker()
+
+	m := New(index, track,
+		WithMaxBlocks(100),
+		WithMaxDelay(time.Minute),
+	)
+
+	assert.Equal(t, index, m.index)
+	assert.Equal(t, track, m.track)
+	assert.Equal(t, uint64(100), m.cfg.MaxBlocks)
+	assert.Equal(t, time.Minute, m.cfg.MaxDelay)
+}
+
+func TestMonitor_Status(t *testing.T) {
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		track := baselineTracker()
+		track.sealed = func() (uint64, error) {
+			return genericHeight + 10, nil
+		}
+
+		m := BaselineMonitor(t, WithSealed(track), WithNow(genericTime.Add(time.Minute)))
+
+		status, err := m.Status()
+
+		require.NoError(t, err)
+		assert.Equal(t, genericHeight, status.Indexed)
+		assert.Equal(t, genericHeight+10, status.Sealed)
+		assert.Equal(t, uint64(10), status.Blocks)
+		assert.Equal(t, time.Minute, status.Delay)
+	})
+
+	t.Run("index ahead of sealed height", func(t *testing.T) {
+		t.Parallel()
+
+		track := baselineTracker()
+		track.sealed = func() (uint64, error) {
+			return genericHeight - 1, nil
+		}
+
+		m := BaselineMonitor(t, WithSealed(track), WithNow(genericTime.Add(-time.Second)))
+
+		status, err := m.Status()
+
+		require.NoError(t, err)
+		assert.Zero(t, status.Blocks)
+		assert.Zero(t, status.Delay)
+	})
+
+	t.Run("handles index failure on last height", func(t *testing.T) {
+		t.Parallel()
+
+		index := baselineIndex()
+		index.last = func() (uint64, error) {
+			return 0, genericError
+		}
+
+		m := BaselineMonitor(t, WithIndex(index))
+
+		_, err := m.Status()
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles index failure on header", func(t *testing.T) {
+		t.Parallel()
+
+		index := baselineIndex()
+		index.header = func(uint64) (*flow.Header, error) {
+			return nil, genericError
+		}
+
+		m := BaselineMonitor(t, WithIndex(index))
+
+		_, err := m.Status()
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles tracker failure", func(t *testing.T) {
+		t.Parallel()
+
+		track := baselineTracker()
+		track.sealed = func() (uint64, error) {
+			return 0, genericError
+		}
+
+		m := BaselineMonitor(t, WithSealed(track))
+
+		_, err := m.Status()
+
+		assert.Error(t, err)
+	})
+}
+
+func TestMonitor_Ready(t *testing.T) {
+
+	track := baselineTracker()
+	track.sealed = func() (uint64, error) {
+		return genericHeight + 10, nil
+	}
+	now := genericTime.Add(time.Minute)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		m := BaselineMonitor(t,
+			WithSealed(track),
+			WithNow(now),
+			WithBlocks(10),
+			WithDelay(time.Minute),
+		)
+
+		status, err := m.Status()
+		require.NoError(t, err)
+
+		got, err := m.Ready()
+
+		require.NoError(t, err)
+		assert.Equal(t, status, got)
+	})
+
+	t.Run("ready without thresholds", func(t *testing.T) {
+		t.Parallel()
+
+		m := BaselineMonitor(t, WithSealed(track), WithNow(now))
+
+		_, err := m.Ready()
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("not ready above maximum blocks", func(t *testing.T) {
+		t.Parallel()
+
+		m := BaselineMonitor(t, WithSealed(track), WithNow(now), WithBlocks(9))
+
+		status, err := m.Ready()
+
+		assert.Error(t, err)
+		assert.Equal(t, uint64(10), status.Blocks)
+	})
+
+	t.Run("not ready above maximum delay", func(t *testing.T) {
+		t.Parallel()
+
+		m := BaselineMonitor(t, WithSealed(track), WithNow(now), WithDelay(time.Second))
+
+		status, err := m.Ready()
+
+		assert.Error(t, err)
+		assert.Equal(t, time.Minute, status.Delay)
+	})
+
+	t.Run("not ready when degraded", func(t *testing.T) {
+		t.Parallel()
+
+		m := BaselineMonitor(t, WithSealed(track), WithNow(now))
+		m.Degrade(genericError)
+
+		status, err := m.Ready()
+
+		assert.ErrorIs(t, err, genericError)
+		assert.Equal(t, uint64(10), status.Blocks)
+	})
+
+	t.Run("handles status failure", func(t *testing.T) {
+		t.Parallel()
+
+		track := baselineTracker()
+		track.sealed = func() (uint64, error) {
+			return 0, genericError
+		}
+
+		m := BaselineMonitor(t, WithSealed(track))
+
+		_, err := m.Ready()
+
+		assert.Error(t, err)
+	})
+}
+
+func BaselineMonitor(t *testing.T, opts ...func(*Monitor)) *Monitor {
+	t.Helper()
+
+	m := Monitor{
+		cfg:   Config{},
+		index: baselineIndex(),
+		track: baselineTracker(),
+		now:   time.Now,
+		mutex: &sync.Mutex{},
+	}
+
+	for _, opt := range opts {
+		opt(&m)
+	}
+
+	return &m
+}
+
+func WithIndex(index Index) func(*Monitor) {
+	return func(m *Monitor) {
+		m.index = index
+	}
+}
+
+func WithSealed(track Tracker) func(*Monitor) {
+	return func(m *Monitor) {
+		m.track = track
+	}
+}
+
+func WithNow(now time.Time) func(*Monitor) {
+	return func(m *Monitor) {
+		m.now = func() time.Time { return now }
+	}
+}
+
+func WithBlocks(max uint64) func(*Monitor) {
+	return func(m *Monitor) {
+		m.cfg.MaxBlocks = max
+	}
+}
+
+func WithDelay(max time.Duration) func(*Monitor) {
+	return func(m *Monitor) {
+		m.cfg.MaxDelay = max
+	}
+}
+
+type index struct {
+	last   func() (uint64, error)
+	header func(height uint64) (*flow.Header, error)
+}
+
+func baselineIndex() *index {
+	i := index{
+		last: func() (uint64, error) {
+			return genericHeight, nil
+		},
+		header: func(height uint64) (*flow.Header, error) {
+			return &flow.Header{Height: height, Timestamp: genericTime}, nil
+		},
+	}
+	return &i
+}
+
+func (i *index) Last() (uint64, error) {
+	return i.last()
+}
+
+func (i *index) Header(height uint64) (*flow.Header, error) {
+	return i.header(height)
+}
+
+type tracker struct {
+	sealed func() (uint64, error)
+}
+
+func baselineTracker() *tracker {
+	t := tracker{
+		sealed: func() (uint64, error) {
+			return genericHeight, nil
+		},
+	}
+	return &t
+}
+
+func (t *tracker) Sealed() (uint64, error) {
+	return t.sealed()
+}
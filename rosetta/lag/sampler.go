@@ -0,0 +1,146 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package lag
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/optakt/flow-rosetta/rosetta/alert"
+)
+
+// Sampler periodically measures how far the index lags behind the Flow network
+// and exposes the result as metrics, so that operators can alert on sync health.
+type Sampler struct {
+	log     zerolog.Logger
+	cfg     Config
+	monitor *Monitor
+	metrics *Metrics
+	now     func() time.Time
+
+	// last is the indexed height at the previous sample, and at the time at
+	// which it was taken, used to compute the block processing rate.
+	last   uint64
+	sample time.Time
+
+	// breached is set while the index lag breaches the thresholds of the
+	// monitor, so that only changes are notified.
+	breached bool
+}
+
+// NewSampler creates a new lag sampler, which records the lag status of the
+// given monitor into the given metrics.
+func NewSampler(log zerolog.Logger, monitor *Monitor, metrics *Metrics, options ...func(*Config)) *Sampler {
+
+	cfg := Config{
+		Poll: 10 * time.Second,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	s := Sampler{
+		log:     log.With().Str("component", "lag_sampler").Logger(),
+		cfg:     cfg,
+		monitor: monitor,
+		metrics: metrics,
+		now:     time.Now,
+	}
+
+	return &s
+}
+
+// Run samples the index lag until the given context is canceled.
+func (s *Sampler) Run(ctx context.Context) error {
+
+	ticker := time.NewTicker(s.cfg.Poll)
+	defer ticker.Stop()
+	for {
+
+		// Failures are logged rather than returned, so that a temporary
+		// problem with the index or the Access API does not stop the sampling.
+		err := s.Sample()
+		if err != nil {
+			s.log.Warn().Err(err).Msg("could not sample index lag")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sample measures the current index lag and updates the metrics.
+func (s *Sampler) Sample() error {
+
+	status, err := s.monitor.Status()
+	if err != nil {
+		return err
+	}
+	now := s.now()
+
+	s.metrics.indexed.Set(float64(status.Indexed))
+	s.metrics.sealed.Set(float64(status.Sealed))
+	s.metrics.blocks.Set(float64(status.Blocks))
+	s.metrics.delay.Set(status.Delay.Seconds())
+
+	// The rate needs two samples, and the index never goes backwards, unless
+	// it was replaced, in which case we simply start over.
+	elapsed := now.Sub(s.sample)
+	if !s.sample.IsZero() && elapsed > 0 && status.Indexed >= s.last {
+		s.metrics.rate.Set(float64(status.Indexed-s.last) / elapsed.Seconds())
+	}
+	s.last = status.Indexed
+	s.sample = now
+
+	if s.cfg.Notifier == nil {
+		return nil
+	}
+
+	err = s.monitor.check(status)
+	if (err != nil) == s.breached {
+		return nil
+	}
+	event := alert.Event{
+		Kind:     "index_lag",
+		Severity: alert.SeverityCritical,
+		Summary:  "index lag is back within thresholds",
+		Details: map[string]string{
+			"indexed_height": strconv.FormatUint(status.Indexed, 10),
+			"sealed_height":  strconv.FormatUint(status.Sealed, 10),
+			"lag_blocks":     strconv.FormatUint(status.Blocks, 10),
+			"lag_duration":   status.Delay.Round(time.Second).String(),
+		},
+		Resolved: err == nil,
+	}
+	if err != nil {
+		event.Summary = err.Error()
+	}
+	s.breached = err != nil
+
+	err = s.cfg.Notifier.Notify(event)
+	if err != nil {
+		return fmt.Errorf("could not notify index lag: %w", err)
+	}
+
+	return nil
+}
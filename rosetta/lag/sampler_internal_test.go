@@ -0,0 +1,170 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package lag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/alert"
+)
+
+func TestNewSampler(t *testing.T) {
+	monitor := BaselineMonitor(t)
+	metrics := NewMetrics(prometheus.NewRegistry())
+
+	s := NewSampler(zerolog.Nop(), monitor, metrics, WithPoll(time.Minute))
+
+	assert.Equal(t, monitor, s.monitor)
+	assert.Equal(t, metrics, s.metrics)
+	assert.Equal(t, time.Minute, s.cfg.Poll)
+}
+
+func TestSampler_Sample(t *testing.T) {
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		track := baselineTracker()
+		track.sealed = func() (uint64, error) {
+			return genericHeight + 10, nil
+		}
+		now := genericTime.Add(time.Minute)
+		s := BaselineSampler(t, BaselineMonitor(t, WithSealed(track), WithNow(now)), now)
+
+		err := s.Sample()
+
+		require.NoError(t, err)
+		assert.Equal(t, float64(genericHeight), testutil.ToFloat64(s.metrics.indexed))
+		assert.Equal(t, float64(genericHeight+10), testutil.ToFloat64(s.metrics.sealed))
+		assert.Equal(t, float64(10), testutil.ToFloat64(s.metrics.blocks))
+		assert.Equal(t, float64(60), testutil.ToFloat64(s.metrics.delay))
+		assert.Zero(t, testutil.ToFloat64(s.metrics.rate))
+	})
+
+	t.Run("computes rate between samples", func(t *testing.T) {
+		t.Parallel()
+
+		height := genericHeight
+		index := baselineIndex()
+		index.last = func() (uint64, error) {
+			return height, nil
+		}
+		now := genericTime
+		s := BaselineSampler(t, BaselineMonitor(t, WithIndex(index)), now)
+
+		err := s.Sample()
+		require.NoError(t, err)
+
+		height += 20
+		s.now = func() time.Time { return now.Add(10 * time.Second) }
+
+		err = s.Sample()
+		require.NoError(t, err)
+		assert.Equal(t, float64(2), testutil.ToFloat64(s.metrics.rate))
+	})
+
+	t.Run("notifies threshold breach and resolution", func(t *testing.T) {
+		t.Parallel()
+
+		sealed := genericHeight + 10
+		track := baselineTracker()
+		track.sealed = func() (uint64, error) {
+			return sealed, nil
+		}
+		notify := &notifier{}
+		monitor := BaselineMonitor(t, WithSealed(track), WithBlocks(5))
+		s := BaselineSampler(t, monitor, genericTime)
+		s.cfg.Notifier = notify
+
+		err := s.Sample()
+		require.NoError(t, err)
+		err = s.Sample()
+		require.NoError(t, err)
+
+		sealed = genericHeight
+		err = s.Sample()
+		require.NoError(t, err)
+
+		require.Len(t, notify.events, 2)
+		assert.Equal(t, "index_lag", notify.events[0].Kind)
+		assert.Equal(t, alert.SeverityCritical, notify.events[0].Severity)
+		assert.Equal(t, "10", notify.events[0].Details["lag_blocks"])
+		assert.False(t, notify.events[0].Resolved)
+		assert.Equal(t, "index_lag", notify.events[1].Kind)
+		assert.True(t, notify.events[1].Resolved)
+	})
+
+	t.Run("handles notifier failure", func(t *testing.T) {
+		t.Parallel()
+
+		track := baselineTracker()
+		track.sealed = func() (uint64, error) {
+			return genericHeight + 10, nil
+		}
+		monitor := BaselineMonitor(t, WithSealed(track), WithBlocks(5))
+		s := BaselineSampler(t, monitor, genericTime)
+		s.cfg.Notifier = &notifier{err: genericError}
+
+		err := s.Sample()
+
+		assert.ErrorIs(t, err, genericError)
+	})
+
+	t.Run("handles status failure", func(t *testing.T) {
+		t.Parallel()
+
+		track := baselineTracker()
+		track.sealed = func() (uint64, error) {
+			return 0, genericError
+		}
+		s := BaselineSampler(t, BaselineMonitor(t, WithSealed(track)), genericTime)
+
+		err := s.Sample()
+
+		assert.Error(t, err)
+		assert.Zero(t, testutil.ToFloat64(s.metrics.indexed))
+	})
+}
+
+func BaselineSampler(t *testing.T, monitor *Monitor, now time.Time) *Sampler {
+	t.Helper()
+
+	s := Sampler{
+		log:     zerolog.Nop(),
+		cfg:     Config{Poll: time.Second},
+		monitor: monitor,
+		metrics: NewMetrics(prometheus.NewRegistry()),
+		now:     func() time.Time { return now },
+	}
+
+	return &s
+}
+
+type notifier struct {
+	events []alert.Event
+	err    error
+}
+
+func (n *notifier) Notify(event alert.Event) error {
+	n.events = append(n.events, event)
+	return n.err
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package limiter_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/optakt/flow-rosetta/rosetta/limiter"
+)
+
+func TestInterceptor(t *testing.T) {
+
+	// invoker returns a GRPC invoker that blocks until released, and keeps
+	// track of the highest amount of concurrent calls.
+	invoker := func(release <-chan struct{}, current *int32, highest *int32) grpc.UnaryInvoker {
+		return func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+			now := atomic.AddInt32(current, 1)
+			for {
+				seen := atomic.LoadInt32(highest)
+				if now <= seen || atomic.CompareAndSwapInt32(highest, seen, now) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(current, -1)
+			return nil
+		}
+	}
+
+	t.Run("bounds calls in flight", func(t *testing.T) {
+		t.Parallel()
+
+		var current, highest int32
+		release := make(chan struct{})
+		call := invoker(release, &current, &highest)
+		intercept := limiter.Interceptor(2)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := intercept(context.Background(), "method", nil, nil, nil, call)
+				assert.NoError(t, err)
+			}()
+		}
+
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&current) == 2 }, time.Second, time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(2), highest)
+	})
+
+	t.Run("stops waiting on context cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		var current, highest int32
+		release := make(chan struct{})
+		defer close(release)
+		call := invoker(release, &current, &highest)
+		intercept := limiter.Interceptor(1)
+
+		go func() {
+			_ = intercept(context.Background(), "method", nil, nil, nil, call)
+		}()
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&current) == 1 }, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := intercept(ctx, "method", nil, nil, nil, call)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("handles no limit", func(t *testing.T) {
+		t.Parallel()
+
+		var current, highest int32
+		release := make(chan struct{})
+		call := invoker(release, &current, &highest)
+		intercept := limiter.Interceptor(0)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = intercept(context.Background(), "method", nil, nil, nil, call)
+			}()
+		}
+
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&current) == 5 }, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+	})
+}
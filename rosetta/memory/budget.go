@@ -0,0 +1,69 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Budget splits a global memory budget between the in-process caches of the
+// service, proportionally to their weights. This bounds the total memory used
+// by caching, so that the service can run on modest hardware.
+type Budget struct {
+	total   uint64
+	weights map[string]uint
+	sum     uint
+}
+
+// NewBudget creates a budget of the given total amount of bytes, to be split
+// between the caches with the given weights. A cache with a weight of zero is
+// disabled.
+func NewBudget(total uint64, weights map[string]uint) *Budget {
+
+	b := Budget{
+		total:   total,
+		weights: make(map[string]uint, len(weights)),
+	}
+	for name, weight := range weights {
+		b.weights[name] = weight
+		b.sum += weight
+	}
+
+	return &b
+}
+
+// Share returns the amount of bytes of the budget that the cache with the
+// given name can use.
+func (b *Budget) Share(name string) uint64 {
+	if b.sum == 0 {
+		return 0
+	}
+	return b.total / uint64(b.sum) * uint64(b.weights[name])
+}
+
+// String returns a readable representation of how the budget is split.
+func (b *Budget) String() string {
+	names := make([]string, 0, len(b.weights))
+	for name := range b.weights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	description := fmt.Sprintf("%d bytes", b.total)
+	for _, name := range names {
+		description += fmt.Sprintf(", %s: %d", name, b.Share(name))
+	}
+	return description
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package memory
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LRU is a cache bounded by the total cost of its entries rather than by their
+// number. Each entry is weighted by its estimated size in bytes, and when the
+// total exceeds the capacity, the least recently used entries are evicted
+// until the cache is back within its capacity.
+type LRU struct {
+	capacity uint64
+
+	mu      *sync.Mutex
+	size    uint64
+	order   *list.List
+	entries map[interface{}]*list.Element
+
+	hits      prometheus.Counter
+	misses    prometheus.Counter
+	evictions prometheus.Counter
+	bytes     prometheus.Gauge
+	items     prometheus.Gauge
+}
+
+// entry is a cached value, along with its key so that it can be removed from
+// the lookup map on eviction, and its cost.
+type entry struct {
+	key   interface{}
+	value interface{}
+	cost  uint64
+}
+
+// NewLRU creates a new weighted LRU cache with the given name and capacity in
+// bytes. The name is used to label the cache's metrics. A capacity of zero
+// disables the cache.
+func NewLRU(name string, capacity uint64, metrics *Metrics) *LRU {
+
+	metrics.capacity.WithLabelValues(name).Set(float64(capacity))
+
+	l := LRU{
+		capacity: capacity,
+
+		mu:      &sync.Mutex{},
+		order:   list.New(),
+		entries: make(map[interface{}]*list.Element),
+
+		hits:      metrics.hits.WithLabelValues(name),
+		misses:    metrics.misses.WithLabelValues(name),
+		evictions: metrics.evictions.WithLabelValues(name),
+		bytes:     metrics.bytes.WithLabelValues(name),
+		items:     metrics.items.WithLabelValues(name),
+	}
+
+	return &l
+}
+
+// Get returns the value cached for the given key, and marks it as the most
+// recently used entry.
+func (l *LRU) Get(key interface{}) (interface{}, bool) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		l.misses.Inc()
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	l.hits.Inc()
+
+	return element.Value.(*entry).value, true
+}
+
+// Set caches the given value with the given cost and reports whether it was
+// accepted. Values that cost more than the whole capacity are rejected, as
+// they would evict everything else.
+func (l *LRU) Set(key interface{}, value interface{}, cost int64) bool {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if cost < 0 || uint64(cost) > l.capacity {
+		return false
+	}
+
+	element, ok := l.entries[key]
+	if ok {
+		existing := element.Value.(*entry)
+		l.size = l.size - existing.cost + uint64(cost)
+		existing.value = value
+		existing.cost = uint64(cost)
+		l.order.MoveToFront(element)
+	} else {
+		l.entries[key] = l.order.PushFront(&entry{key: key, value: value, cost: uint64(cost)})
+		l.size += uint64(cost)
+	}
+
+	for l.size > l.capacity {
+		oldest := l.order.Back()
+		evicted := oldest.Value.(*entry)
+		l.order.Remove(oldest)
+		delete(l.entries, evicted.key)
+		l.size -= evicted.cost
+		l.evictions.Inc()
+	}
+
+	l.bytes.Set(float64(l.size))
+	l.items.Set(float64(len(l.entries)))
+
+	return true
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/optakt/flow-rosetta/rosetta/memory"
+)
+
+func TestLRU(t *testing.T) {
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		lru := memory.NewLRU("test", 100, memory.NewMetrics(prometheus.NewRegistry()))
+		lru.Set("key", "value", 10)
+
+		value, ok := lru.Get("key")
+		assert.True(t, ok)
+		assert.Equal(t, "value", value)
+
+		_, ok = lru.Get("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("evicts least recently used entries by cost", func(t *testing.T) {
+		t.Parallel()
+
+		lru := memory.NewLRU("test", 100, memory.NewMetrics(prometheus.NewRegistry()))
+		lru.Set(1, "first", 40)
+		lru.Set(2, "second", 40)
+
+		// Using the first entry makes the second one the least recently used.
+		_, ok := lru.Get(1)
+		assert.True(t, ok)
+
+		lru.Set(3, "third", 40)
+
+		_, ok = lru.Get(1)
+		assert.True(t, ok)
+		_, ok = lru.Get(2)
+		assert.False(t, ok)
+		_, ok = lru.Get(3)
+		assert.True(t, ok)
+	})
+
+	t.Run("updates cost of existing entries", func(t *testing.T) {
+		t.Parallel()
+
+		lru := memory.NewLRU("test", 100, memory.NewMetrics(prometheus.NewRegistry()))
+		lru.Set(1, "first", 40)
+		lru.Set(2, "second", 40)
+		lru.Set(1, "updated", 70)
+
+		value, ok := lru.Get(1)
+		assert.True(t, ok)
+		assert.Equal(t, "updated", value)
+		_, ok = lru.Get(2)
+		assert.False(t, ok)
+	})
+
+	t.Run("skips entries above capacity", func(t *testing.T) {
+		t.Parallel()
+
+		lru := memory.NewLRU("test", 100, memory.NewMetrics(prometheus.NewRegistry()))
+		lru.Set(1, "first", 40)
+		ok := lru.Set(2, "second", 101)
+		assert.False(t, ok)
+
+		_, ok = lru.Get(1)
+		assert.True(t, ok)
+		_, ok = lru.Get(2)
+		assert.False(t, ok)
+	})
+
+	t.Run("handles zero capacity", func(t *testing.T) {
+		t.Parallel()
+
+		lru := memory.NewLRU("test", 0, memory.NewMetrics(prometheus.NewRegistry()))
+		lru.Set(1, "first", 1)
+
+		_, ok := lru.Get(1)
+		assert.False(t, ok)
+	})
+}
+
+func TestBudget_Share(t *testing.T) {
+
+	budget := memory.NewBudget(1000, map[string]uint{
+		"registers": 3,
+		"blocks":    1,
+		"scripts":   1,
+		"disabled":  0,
+	})
+
+	assert.Equal(t, uint64(600), budget.Share("registers"))
+	assert.Equal(t, uint64(200), budget.Share("blocks"))
+	assert.Equal(t, uint64(200), budget.Share("scripts"))
+	assert.Equal(t, uint64(0), budget.Share("disabled"))
+	assert.Equal(t, uint64(0), budget.Share("unknown"))
+
+	empty := memory.NewBudget(1000, nil)
+	assert.Equal(t, uint64(0), empty.Share("registers"))
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package memory
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics contains the metrics that the caches expose about their memory usage
+// and efficiency, labelled by cache name.
+type Metrics struct {
+	capacity  *prometheus.GaugeVec
+	bytes     *prometheus.GaugeVec
+	items     *prometheus.GaugeVec
+	hits      *prometheus.CounterVec
+	misses    *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+}
+
+// NewMetrics creates the cache metrics and registers them with the given
+// registerer.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+
+	factory := promauto.With(reg)
+	labels := []string{"cache"}
+	m := Metrics{
+		capacity: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "cache",
+			Name:      "capacity_bytes",
+			Help:      "share of the memory budget allocated to the cache",
+		}, labels),
+		bytes: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "cache",
+			Name:      "size_bytes",
+			Help:      "estimated size of the entries currently in the cache",
+		}, labels),
+		items: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "cache",
+			Name:      "entries",
+			Help:      "number of entries currently in the cache",
+		}, labels),
+		hits: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "cache",
+			Name:      "hits_total",
+			Help:      "number of lookups that found an entry in the cache",
+		}, labels),
+		misses: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "cache",
+			Name:      "misses_total",
+			Help:      "number of lookups that did not find an entry in the cache",
+		}, labels),
+		evictions: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "cache",
+			Name:      "evictions_total",
+			Help:      "number of entries evicted to stay within the memory budget",
+		}, labels),
+	}
+
+	return &m
+}
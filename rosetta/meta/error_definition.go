@@ -14,9 +14,23 @@
 
 package meta
 
-// ErrorDefinition is a Rosetta error's definition.
+import (
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+// ErrorDefinition is a Rosetta error's definition. The retriable flag tells
+// clients whether the same request might succeed later, for example once the
+// index has caught up, while the category determines the HTTP status code used
+// when smart status codes are enabled. The category is not part of the Rosetta
+// specification and is thus not serialized. The URL optionally links to the
+// documentation of the error, such as an operator's runbook.
+//
+// Codes are stable: once assigned, the code of an error never changes, and new
+// errors take their code from the namespace of their subsystem.
 type ErrorDefinition struct {
-	Code      uint   `json:"code"`
-	Message   string `json:"message"`
-	Retriable bool   `json:"retriable"`
+	Code      uint             `json:"code"`
+	Message   string           `json:"message"`
+	Retriable bool             `json:"retriable"`
+	URL       string           `json:"url,omitempty"`
+	Category  failure.Category `json:"-"`
 }
@@ -0,0 +1,28 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package object
+
+// AccountKey is a public key of a Flow account, along with the information that
+// construction clients need to pick signing keys, such as its weight, whether
+// it was revoked and its current sequence number.
+type AccountKey struct {
+	Index              int    `json:"index"`
+	PublicKey          string `json:"public_key"`
+	SignatureAlgorithm string `json:"signature_algorithm"`
+	HashAlgorithm      string `json:"hash_algorithm"`
+	Weight             int    `json:"weight"`
+	Revoked            bool   `json:"revoked"`
+	SequenceNumber     uint64 `json:"sequence_number"`
+}
@@ -22,4 +22,5 @@ import (
 type Amount struct {
 	Value    string              `json:"value"`
 	Currency identifier.Currency `json:"currency"`
+	Metadata *AmountMetadata     `json:"metadata,omitempty"`
 }
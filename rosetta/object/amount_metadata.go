@@ -0,0 +1,66 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package object
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+// AmountMetadata is the metadata attached to an account balance. For balances
+// that were aggregated from several vaults, it contains the balance of each vault.
+// For accounts that hold a locked account, it contains the locked balance, and
+// for node operators, the balances of the machine accounts of their nodes. For
+// FLOW balances, it can also contain the storage usage of the account.
+type AmountMetadata struct {
+	Vaults   []VaultBalance   `json:"vaults,omitempty"`
+	Locked   *LockedBalance   `json:"locked,omitempty"`
+	Machines []MachineBalance `json:"machines,omitempty"`
+	Storage  *StorageUsage    `json:"storage,omitempty"`
+}
+
+// VaultBalance is the balance of a single vault, identified by the public path
+// of the capability through which it was read.
+type VaultBalance struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// LockedBalance is the balance of the locked account that an account holds through
+// the LockedTokens contract, which is not part of the account's own balance. The
+// unlock limit is the amount of those tokens that can currently be withdrawn.
+type LockedBalance struct {
+	Account     identifier.Account `json:"account"`
+	Value       string             `json:"value"`
+	UnlockLimit string             `json:"unlock_limit"`
+}
+
+// MachineBalance is the balance of the machine account that a node operator set up
+// for one of its collection or consensus nodes through its staking collection. The
+// node uses it to pay for the transactions of its epoch protocol duties.
+type MachineBalance struct {
+	Account identifier.Account `json:"account"`
+	NodeID  string             `json:"node_id"`
+	Role    string             `json:"role"`
+	Value   string             `json:"value"`
+}
+
+// StorageUsage is the storage used by an account and its storage capacity, in bytes.
+// The reservation is the amount of FLOW tokens that the account has to keep in its
+// vault to pay for its storage, which can therefore not be transferred.
+type StorageUsage struct {
+	Used        uint64 `json:"storage_used"`
+	Capacity    uint64 `json:"storage_capacity"`
+	Reservation string `json:"minimum_reservation"`
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package object
+
+// BlockMetadata is the metadata attached to a block, which flags blocks that
+// are finalized but not yet sealed on the Flow network. It also lists the
+// guarantees of the collections included in the block, and the number of
+// chunks that the block is executed in, which is one per collection, plus the
+// system chunk. Finally, it lists the seals included in the block, which
+// allow the execution state of previous blocks to be verified, and the service
+// events emitted in the block, if there are any.
+type BlockMetadata struct {
+	Sealed        bool                 `json:"sealed"`
+	Collections   []CollectionMetadata `json:"collections"`
+	Chunks        uint                 `json:"chunks"`
+	Seals         []SealMetadata       `json:"seals"`
+	ServiceEvents []ServiceEvent       `json:"service_events,omitempty"`
+}
+
+// CollectionMetadata is the guarantee of a collection included in a block. The
+// guarantors are the collection nodes of the cluster that built the collection.
+type CollectionMetadata struct {
+	ID             string   `json:"collection_id"`
+	ReferenceBlock string   `json:"reference_block_id"`
+	Guarantors     []string `json:"guarantors"`
+}
+
+// SealMetadata is a seal included in a block. It identifies the sealed block,
+// the execution result that was sealed for it and the state commitment that
+// the execution of the sealed block resulted in. Only the seal ID is known for
+// seals that could not be retrieved from the index.
+type SealMetadata struct {
+	ID         string `json:"seal_id"`
+	BlockID    string `json:"block_id,omitempty"`
+	ResultID   string `json:"result_id,omitempty"`
+	FinalState string `json:"final_state,omitempty"`
+}
@@ -19,7 +19,15 @@ import (
 )
 
 // Metadata is the information required to construct a transaction for a specific network.
+// The expiry height is the last height at which a transaction using the current block as
+// its reference block can still be accepted by the network. The gas limit is the one
+// the suggested fee was computed for. The payload format can be
+// set by the client to request signing payloads in a format other than the default.
 type Metadata struct {
 	CurrentBlockID identifier.Block `json:"current_block"`
 	SequenceNumber uint64           `json:"sequence_number"`
+	ExpiryHeight   uint64           `json:"expiry_height,omitempty"`
+	Roles          *Roles           `json:"roles,omitempty"`
+	GasLimit       uint64           `json:"gas_limit,omitempty"`
+	PayloadFormat  string           `json:"payload_format,omitempty"`
 }
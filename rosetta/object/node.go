@@ -0,0 +1,31 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package object
+
+// Node is the staking record of a node operator, as kept by the staking table
+// contract. The token buckets are amounts of FLOW tokens.
+type Node struct {
+	ID                       string `json:"node_id"`
+	Role                     string `json:"role"`
+	NetworkingAddress        string `json:"networking_address"`
+	InitialWeight            uint64 `json:"initial_weight"`
+	DelegatorCount           uint   `json:"delegator_count"`
+	TokensStaked             Amount `json:"tokens_staked"`
+	TokensCommitted          Amount `json:"tokens_committed"`
+	TokensUnstaking          Amount `json:"tokens_unstaking"`
+	TokensUnstaked           Amount `json:"tokens_unstaked"`
+	TokensRewarded           Amount `json:"tokens_rewarded"`
+	TokensRequestedToUnstake Amount `json:"tokens_requested_to_unstake"`
+}
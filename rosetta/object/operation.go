@@ -26,14 +26,21 @@ import (
 // blockchains.
 //
 // Examples of metadata given in the Rosetta API documentation are
-// "asm" and "hex".
+// "asm" and "hex". For Flow, metadata is used by template and key operations,
+// as well as by operations related to staking, fees and rewards.
+//
+// The withdrawal and deposit of a transfer are linked through the related
+// operations of the deposit, which reference operations with a lower index only,
+// as required by the Rosetta API specification.
 //
 // The `coin_change` field is omitted, as the Flow blockchain is an
 // account-based blockchain without utxo set.
 type Operation struct {
-	ID        identifier.Operation `json:"operation_identifier"`
-	Type      string               `json:"type"`
-	Status    string               `json:"status,omitempty"`
-	AccountID identifier.Account   `json:"account"`
-	Amount    Amount               `json:"amount"`
+	ID         identifier.Operation   `json:"operation_identifier"`
+	RelatedIDs []identifier.Operation `json:"related_operations,omitempty"`
+	Type       string                 `json:"type"`
+	Status     string                 `json:"status,omitempty"`
+	AccountID  identifier.Account     `json:"account"`
+	Amount     Amount                 `json:"amount"`
+	Metadata   *OperationMetadata     `json:"metadata,omitempty"`
 }
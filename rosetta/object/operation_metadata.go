@@ -0,0 +1,64 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package object
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+// OperationMetadata is the metadata of an operation. Template operations
+// reference one of the allowlisted transaction templates, along with the named
+// argument values for its script. Key operations describe the account key to
+// add, or the index of the account key to revoke.
+//
+// Operations related to staking, fees or rewards carry the typed metadata of
+// their kind, so that consumers can decode it without guessing at free-form
+// fields. The JSON field names of all metadata types are stable.
+type OperationMetadata struct {
+	Template  string            `json:"template,omitempty"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+
+	PublicKey          string `json:"public_key,omitempty"`
+	SignatureAlgorithm string `json:"signature_algorithm,omitempty"`
+	HashAlgorithm      string `json:"hash_algorithm,omitempty"`
+	Weight             int    `json:"weight,omitempty"`
+	KeyIndex           *int   `json:"key_index,omitempty"`
+
+	Staking *StakingMetadata `json:"staking,omitempty"`
+	Fee     *FeeMetadata     `json:"fee,omitempty"`
+	Reward  *RewardMetadata  `json:"reward,omitempty"`
+}
+
+// StakingMetadata is the metadata of an operation that moves tokens into or out
+// of the staking table. It identifies the node operator and, for delegated
+// tokens, the delegator of the node.
+type StakingMetadata struct {
+	NodeID      string  `json:"node_id"`
+	DelegatorID *uint32 `json:"delegator_id,omitempty"`
+}
+
+// FeeMetadata is the metadata of an operation that pays transaction fees. It
+// identifies the account that paid the fees for the transaction.
+type FeeMetadata struct {
+	Payer identifier.Account `json:"payer"`
+}
+
+// RewardMetadata is the metadata of an operation that pays out staking rewards.
+// It identifies the node operator and, for delegator rewards, the delegator of
+// the node that earned the reward.
+type RewardMetadata struct {
+	NodeID      string  `json:"node_id"`
+	DelegatorID *uint32 `json:"delegator_id,omitempty"`
+}
@@ -25,7 +25,12 @@ import (
 // Specifically for Flow DPS, this object contains the account identifier
 // that is the proposer of the transaction (by default, this is the sender).
 // Account identifier is required so that we can return the sequence number
-// of the proposer's key, required for the Flow transaction.
+// of the proposer's key, required for the Flow transaction. The designated
+// roles are forwarded as well, so that they end up in the metadata, and so
+// are the maximum fee and fee multiplier, which determine the gas limit.
 type Options struct {
-	AccountID identifier.Account `json:"account_identifier"`
+	AccountID  identifier.Account `json:"account_identifier"`
+	Roles      *Roles             `json:"roles,omitempty"`
+	MaxFee     string             `json:"max_fee,omitempty"`
+	Multiplier float64            `json:"fee_multiplier,omitempty"`
 }
@@ -0,0 +1,30 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package object
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+// Roles designates the accounts that pay for and propose a transaction, when
+// they are distinct from the account that authorizes it. This allows e.g. an
+// operator to pay the fees for transfers made by custodied accounts. Any role
+// that is omitted is taken by the authorizer. The proposer proposes and signs
+// with its first key, unless the index of another one of its keys is given.
+type Roles struct {
+	PayerID          *identifier.Account `json:"payer,omitempty"`
+	ProposerID       *identifier.Account `json:"proposer,omitempty"`
+	ProposerKeyIndex int                 `json:"proposer_key_index,omitempty"`
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package object
+
+// ServiceEvent is a service event emitted in a block, which the Flow protocol
+// acts upon. Its type is either the setup or the commit of an epoch, and only
+// the matching payload is set. The counter is the one of the epoch that is set
+// up or committed.
+type ServiceEvent struct {
+	Type    string       `json:"type"`
+	Counter uint64       `json:"counter"`
+	Setup   *EpochSetup  `json:"epoch_setup,omitempty"`
+	Commit  *EpochCommit `json:"epoch_commit,omitempty"`
+}
+
+// EpochSetup is the payload of the service event that sets up the next epoch.
+// It gives the range of views of the epoch, the final views of the phases of
+// its distributed key generation, its random source, as well as the number of
+// nodes that participate in it and the number of collection clusters.
+type EpochSetup struct {
+	FirstView          uint64   `json:"first_view"`
+	FinalView          uint64   `json:"final_view"`
+	DKGPhaseFinalViews []uint64 `json:"dkg_phase_final_views"`
+	RandomSource       string   `json:"random_source"`
+	Participants       uint     `json:"participants"`
+	Clusters           uint     `json:"clusters"`
+}
+
+// EpochCommit is the payload of the service event that commits the next epoch,
+// once its setup is complete. It gives the number of quorum certificates of the
+// collection clusters, and the group key resulting from the distributed key
+// generation, along with the number of participant keys.
+type EpochCommit struct {
+	ClusterQCs      uint   `json:"cluster_qcs"`
+	DKGGroupKey     string `json:"dkg_group_key"`
+	DKGParticipants uint   `json:"dkg_participants"`
+}
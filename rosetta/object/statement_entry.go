@@ -0,0 +1,29 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package object
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+// StatementEntry is a single operation affecting an account, along with the
+// block and transaction it was part of. An account statement is the ordered
+// list of these entries over a range of blocks.
+type StatementEntry struct {
+	BlockID       identifier.Block       `json:"block_identifier"`
+	Timestamp     int64                  `json:"timestamp"`
+	TransactionID identifier.Transaction `json:"transaction_identifier"`
+	Operation     *Operation             `json:"operation"`
+}
@@ -0,0 +1,28 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package object
+
+// Summary is a human-readable description of an unsigned transaction. It accompanies
+// signing payloads in the detached format, so that they can be reviewed on an offline
+// signer before they are signed.
+type Summary struct {
+	ReferenceBlock string   `json:"reference_block"`
+	SequenceNumber uint64   `json:"sequence_number"`
+	GasLimit       uint64   `json:"gas_limit"`
+	Proposer       string   `json:"proposer"`
+	Payer          string   `json:"payer"`
+	Authorizers    []string `json:"authorizers"`
+	Operations     []string `json:"operations"`
+}
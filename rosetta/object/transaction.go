@@ -22,8 +22,9 @@ import (
 // transaction identifier.
 //
 // Examples of metadata given in the Rosetta API documentation are "size" and
-// "lockTime".
+// "lockTime". For Flow, metadata holds the execution details of the transaction.
 type Transaction struct {
 	ID         identifier.Transaction `json:"transaction_identifier"`
 	Operations []*Operation           `json:"operations"`
+	Metadata   *TransactionMetadata   `json:"metadata,omitempty"`
 }
@@ -0,0 +1,65 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package object
+
+// TransactionMetadata is the metadata attached to a transaction. It holds the
+// computation used by the transaction and its gas limit, the fees deducted for
+// it if the fees event was emitted, the accounts it created, the contracts it
+// deployed, updated or removed, the operations of types that are not allowlisted,
+// and the execution error of transactions that failed on the Flow network.
+type TransactionMetadata struct {
+	ComputationUsed uint64           `json:"computation_used"`
+	GasLimit        uint64           `json:"gas_limit"`
+	Fees            *TransactionFees `json:"fees,omitempty"`
+	Accounts        []CreatedAccount `json:"created_accounts,omitempty"`
+	Contracts       []ContractChange `json:"contracts,omitempty"`
+	Omitted         []*Operation     `json:"omitted_operations,omitempty"`
+	Error           *ExecutionError  `json:"error,omitempty"`
+}
+
+// CreatedAccount is an account created by a transaction. The creator is the
+// payer of the transaction, and the keys are the number of public keys that the
+// transaction added to the new account.
+type CreatedAccount struct {
+	Address string `json:"address"`
+	Creator string `json:"creator"`
+	Keys    uint   `json:"keys"`
+}
+
+// TransactionFees are the fees deducted for a transaction. The total amount is
+// computed from the inclusion effort, which covers the transaction being
+// included in a block, and the execution effort, which covers its execution.
+type TransactionFees struct {
+	Amount          Amount `json:"amount"`
+	InclusionEffort string `json:"inclusion_effort"`
+	ExecutionEffort string `json:"execution_effort"`
+}
+
+// ContractChange is a change to a contract made by a transaction. The action is
+// either `added`, `updated` or `removed`, and the address is the one of the
+// account that the contract is deployed to.
+type ContractChange struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+	Action  string `json:"action"`
+}
+
+// ExecutionError is the error returned by the Flow virtual machine for a failed
+// transaction. The code is the FVM error code, which is zero when the message
+// does not include one.
+type ExecutionError struct {
+	Code    uint   `json:"code,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package pool
+
+import (
+	"time"
+)
+
+// Config contains the configuration options for the invocation pool.
+type Config struct {
+	Workers uint
+	Queue   uint
+	Timeout time.Duration
+}
+
+// WithWorkers sets the maximum number of Cadence executions that can run at
+// the same time.
+func WithWorkers(workers uint) func(*Config) {
+	return func(c *Config) {
+		c.Workers = workers
+	}
+}
+
+// WithQueue sets the maximum number of Cadence executions that can wait for
+// a worker, after which further executions are rejected until the queue drains.
+func WithQueue(queue uint) func(*Config) {
+	return func(c *Config) {
+		c.Queue = queue
+	}
+}
+
+// WithTimeout sets how long a caller waits for a single Cadence execution
+// before its result is abandoned. A timeout of zero disables it.
+func WithTimeout(timeout time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.Timeout = timeout
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package pool
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+const (
+	poolOverloaded = "too many concurrent script executions, retry later"
+	limitTimeout   = "script execution exceeded timeout"
+)
+
+// Pool wraps an invoker and bounds the number of Cadence executions that run
+// at the same time. Executions beyond that limit wait in a bounded queue, and
+// are rejected once the queue is full, so that bursts of balance requests do
+// not translate into unbounded memory usage of the virtual machine.
+type Pool struct {
+	cfg    Config
+	invoke Invoker
+
+	// workers holds a token for each running execution, while admitted holds
+	// a token for each running or waiting execution.
+	workers  chan struct{}
+	admitted chan struct{}
+}
+
+// New creates a new invocation pool around the given invoker.
+func New(invoke Invoker, options ...func(*Config)) *Pool {
+
+	cfg := Config{
+		Workers: uint(runtime.NumCPU()),
+		Queue:   100,
+		Timeout: 0,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	// Without any workers, no execution could ever run.
+	if cfg.Workers == 0 {
+		cfg.Workers = 1
+	}
+
+	p := Pool{
+		cfg:      cfg,
+		invoke:   invoke,
+		workers:  make(chan struct{}, cfg.Workers),
+		admitted: make(chan struct{}, cfg.Workers+cfg.Queue),
+	}
+
+	return &p
+}
+
+// Key returns the public key of the account with the given address at the given height.
+func (p *Pool) Key(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
+	release, err := p.acquire()
+	if err != nil {
+		return nil, err
+	}
+	var key *flow.AccountPublicKey
+	err = p.run(release, func() error {
+		var err error
+		key, err = p.invoke.Key(height, address, index)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Account returns the account with the given address at the given height.
+func (p *Pool) Account(height uint64, address flow.Address) (*flow.Account, error) {
+	release, err := p.acquire()
+	if err != nil {
+		return nil, err
+	}
+	var account *flow.Account
+	err = p.run(release, func() error {
+		var err error
+		account, err = p.invoke.Account(height, address)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Script executes the given Cadence script with the given arguments at the given height.
+func (p *Pool) Script(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+	release, err := p.acquire()
+	if err != nil {
+		return nil, err
+	}
+	var value cadence.Value
+	err = p.run(release, func() error {
+		var err error
+		value, err = p.invoke.Script(height, script, parameters)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Transaction executes the given transaction at the given height.
+func (p *Pool) Transaction(height uint64, body *flow.TransactionBody) error {
+	release, err := p.acquire()
+	if err != nil {
+		return err
+	}
+	return p.run(release, func() error {
+		return p.invoke.Transaction(height, body)
+	})
+}
+
+// Waiting returns the number of executions that are waiting for a worker.
+func (p *Pool) Waiting() uint {
+	waiting := len(p.admitted) - len(p.workers)
+	if waiting < 0 {
+		return 0
+	}
+	return uint(waiting)
+}
+
+// acquire admits an execution into the pool and waits for a free worker. It
+// fails right away if the queue is full, and otherwise returns the function
+// that releases the worker once the execution is done.
+func (p *Pool) acquire() (func(), error) {
+
+	select {
+	case p.admitted <- struct{}{}:
+	default:
+		return nil, failure.Overloaded{
+			Capacity: p.cfg.Workers + p.cfg.Queue,
+			Description: failure.NewDescription(poolOverloaded,
+				failure.WithInt("workers", int(p.cfg.Workers)),
+				failure.WithInt("queue", int(p.cfg.Queue)),
+			),
+		}
+	}
+
+	p.workers <- struct{}{}
+
+	release := func() {
+		<-p.workers
+		<-p.admitted
+	}
+
+	return release, nil
+}
+
+// run runs the given execution and releases its worker once it returns. If a
+// timeout is configured and the execution takes longer, its result is abandoned.
+// The virtual machine can not be interrupted, so the abandoned execution keeps
+// its worker until it stops on its own, at the latest when it reaches the
+// computation limit; this way, executions that time out still count against
+// the number of workers.
+func (p *Pool) run(release func(), execute func() error) error {
+
+	if p.cfg.Timeout == 0 {
+		defer release()
+		return execute()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer release()
+		done <- execute()
+	}()
+
+	timer := time.NewTimer(p.cfg.Timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return failure.LimitExceeded{
+			Limit: "timeout",
+			Description: failure.NewDescription(limitTimeout,
+				failure.WithString("timeout", p.cfg.Timeout.String()),
+			),
+		}
+	}
+}
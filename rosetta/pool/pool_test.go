@@ -0,0 +1,287 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package pool_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/pool"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestPool_Script(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+			assert.Equal(t, mocks.GenericHeight, height)
+			assert.Equal(t, mocks.GenericBytes, script)
+
+			return mocks.GenericAmount(0), nil
+		}
+
+		value, err := pool.New(invoke).Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericAmount(0), value)
+	})
+
+	t.Run("limits concurrent executions", func(t *testing.T) {
+		t.Parallel()
+
+		var mu sync.Mutex
+		running, peak := 0, 0
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+
+			return mocks.GenericAmount(0), nil
+		}
+
+		p := pool.New(invoke, pool.WithWorkers(2), pool.WithQueue(20))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.LessOrEqual(t, peak, 2)
+	})
+
+	t.Run("rejects executions when queue is full", func(t *testing.T) {
+		t.Parallel()
+
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			close(started)
+			<-unblock
+			return mocks.GenericAmount(0), nil
+		}
+
+		p := pool.New(invoke, pool.WithWorkers(1), pool.WithQueue(0))
+
+		done := make(chan error)
+		go func() {
+			_, err := p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+			done <- err
+		}()
+		<-started
+
+		_, err := p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+
+		var olErr failure.Overloaded
+		require.True(t, errors.As(err, &olErr))
+		assert.Equal(t, uint(1), olErr.Capacity)
+
+		close(unblock)
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("handles invoker failure", func(t *testing.T) {
+		t.Parallel()
+
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			return nil, mocks.GenericError
+		}
+
+		p := pool.New(invoke, pool.WithWorkers(1), pool.WithQueue(0))
+
+		_, err := p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+		assert.Error(t, err)
+
+		// The worker is released after a failure, so the next execution runs.
+		_, err = p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+}
+
+func TestPool_Transaction(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		invoke := mocks.BaselineInvoker(t)
+		invoke.TransactionFunc = func(height uint64, body *flow.TransactionBody) error {
+			assert.Equal(t, mocks.GenericHeight, height)
+			assert.Equal(t, mocks.GenericTransaction(0), body)
+
+			return nil
+		}
+
+		err := pool.New(invoke).Transaction(mocks.GenericHeight, mocks.GenericTransaction(0))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects executions when queue is full", func(t *testing.T) {
+		t.Parallel()
+
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			close(started)
+			<-unblock
+			return mocks.GenericAmount(0), nil
+		}
+
+		p := pool.New(invoke, pool.WithWorkers(1), pool.WithQueue(0))
+
+		done := make(chan error)
+		go func() {
+			_, err := p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+			done <- err
+		}()
+		<-started
+
+		err := p.Transaction(mocks.GenericHeight, mocks.GenericTransaction(0))
+
+		var olErr failure.Overloaded
+		assert.True(t, errors.As(err, &olErr))
+
+		close(unblock)
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("handles invoker failure", func(t *testing.T) {
+		t.Parallel()
+
+		invoke := mocks.BaselineInvoker(t)
+		invoke.TransactionFunc = func(uint64, *flow.TransactionBody) error {
+			return mocks.GenericError
+		}
+
+		err := pool.New(invoke).Transaction(mocks.GenericHeight, mocks.GenericTransaction(0))
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+}
+
+func TestPool_Timeout(t *testing.T) {
+	t.Run("handles timeout", func(t *testing.T) {
+		t.Parallel()
+
+		unblock := make(chan struct{})
+		defer close(unblock)
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			<-unblock
+			return mocks.GenericAmount(0), nil
+		}
+
+		p := pool.New(invoke, pool.WithTimeout(time.Millisecond))
+
+		_, err := p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+
+		var leErr failure.LimitExceeded
+		require.True(t, errors.As(err, &leErr))
+		assert.Equal(t, "timeout", leErr.Limit)
+	})
+
+	t.Run("keeps worker of abandoned execution until it returns", func(t *testing.T) {
+		t.Parallel()
+
+		unblock := make(chan struct{})
+		invoke := mocks.BaselineInvoker(t)
+		invoke.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			<-unblock
+			return mocks.GenericAmount(0), nil
+		}
+
+		p := pool.New(invoke,
+			pool.WithWorkers(1),
+			pool.WithQueue(0),
+			pool.WithTimeout(time.Millisecond),
+		)
+
+		_, err := p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+		require.True(t, errors.As(err, &failure.LimitExceeded{}))
+
+		// The abandoned execution still runs, so there is no worker for another one.
+		_, err = p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+		require.True(t, errors.As(err, &failure.Overloaded{}))
+
+		// Once it returns, the worker is released and later executions run again.
+		close(unblock)
+		assert.Eventually(t, func() bool {
+			_, err := p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+			return err == nil
+		}, time.Second, time.Millisecond)
+	})
+}
+
+func TestPool_Waiting(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	invoke := mocks.BaselineInvoker(t)
+	invoke.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+		started <- struct{}{}
+		<-unblock
+		return mocks.GenericAmount(0), nil
+	}
+
+	p := pool.New(invoke, pool.WithWorkers(1), pool.WithQueue(2))
+	assert.Equal(t, uint(0), p.Waiting())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = p.Script(mocks.GenericHeight, mocks.GenericBytes, nil)
+		}()
+	}
+	<-started
+
+	// One execution is running, so the two others are waiting for the worker.
+	assert.Eventually(t, func() bool { return p.Waiting() == 2 }, time.Second, time.Millisecond)
+
+	close(unblock)
+	<-started
+	<-started
+	wg.Wait()
+
+	assert.Equal(t, uint(0), p.Waiting())
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package prefetcher
+
+import (
+	"time"
+)
+
+// Config contains the configuration options for the prefetcher.
+type Config struct {
+	Poll  time.Duration
+	Depth uint64
+}
+
+// WithPoll sets how often the prefetcher checks for new blocks.
+func WithPoll(poll time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.Poll = poll
+	}
+}
+
+// WithDepth sets the maximum number of blocks below the current one that the
+// prefetcher converts after each check, so that a large backlog of new blocks,
+// for example while the index catches up, does not flood the block cache.
+func WithDepth(depth uint64) func(*Config) {
+	return func(c *Config) {
+		c.Depth = depth
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package prefetcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+// Prefetcher follows newly indexed blocks and converts each of them as soon as
+// it is available, so that requests for the latest block, which is what syncing
+// clients ask for the most, are served from the block cache of the retriever.
+type Prefetcher struct {
+	log      zerolog.Logger
+	cfg      Config
+	retrieve Retriever
+
+	// last is the height up to which blocks were prefetched.
+	last uint64
+}
+
+// New creates a new prefetcher using the given retriever.
+func New(log zerolog.Logger, retrieve Retriever, options ...func(*Config)) *Prefetcher {
+
+	cfg := Config{
+		Poll:  time.Second,
+		Depth: 10,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	// We always want to prefetch at least the current block.
+	if cfg.Depth == 0 {
+		cfg.Depth = 1
+	}
+
+	p := Prefetcher{
+		log:      log.With().Str("component", "prefetcher").Logger(),
+		cfg:      cfg,
+		retrieve: retrieve,
+	}
+
+	return &p
+}
+
+// Run prefetches new blocks as they arrive until the given context is canceled.
+func (p *Prefetcher) Run(ctx context.Context) error {
+
+	ticker := time.NewTicker(p.cfg.Poll)
+	defer ticker.Stop()
+	for {
+
+		// Failures are logged rather than returned, so that a temporary
+		// problem with the index does not stop the prefetching.
+		err := p.catchUp(ctx)
+		if err != nil {
+			p.log.Warn().Uint64("last", p.last).Err(err).Msg("could not prefetch new blocks")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// catchUp converts the blocks that were indexed since the last check, up to
+// the configured depth below the current block.
+func (p *Prefetcher) catchUp(ctx context.Context) error {
+
+	rosBlockID, _, err := p.retrieve.Current()
+	if err != nil {
+		return fmt.Errorf("could not get current block: %w", err)
+	}
+	current := *rosBlockID.Index
+
+	next := p.last + 1
+	if current >= p.cfg.Depth && next < current-p.cfg.Depth+1 {
+		next = current - p.cfg.Depth + 1
+	}
+
+	for height := next; height <= current; height++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		index := height
+		_, _, err := p.retrieve.Block(identifier.Block{Index: &index})
+		if err != nil {
+			return fmt.Errorf("could not prefetch block (height: %d): %w", height, err)
+		}
+		p.last = height
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package prefetcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestNew(t *testing.T) {
+	retrieve := mocks.BaselineRetriever(t)
+
+	p := New(zerolog.Nop(), retrieve,
+		WithPoll(time.Minute),
+		WithDepth(5),
+	)
+
+	require.NotNil(t, p)
+	assert.Equal(t, retrieve, p.retrieve)
+	assert.Equal(t, time.Minute, p.cfg.Poll)
+	assert.Equal(t, uint64(5), p.cfg.Depth)
+}
+
+func TestPrefetcher_Run(t *testing.T) {
+	t.Run("stops on context cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := currentAt(t, 100)
+		retrieve.BlockFunc = func(identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			t.Error("block prefetched after cancellation")
+			return &object.Block{}, nil, nil
+		}
+
+		p := BaselinePrefetcher(t, WithRetriever(retrieve))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := p.Run(ctx)
+
+		require.NoError(t, err)
+	})
+}
+
+func TestPrefetcher_CatchUp(t *testing.T) {
+	t.Run("prefetches up to depth below current block", func(t *testing.T) {
+		t.Parallel()
+
+		var heights []uint64
+		retrieve := currentAt(t, 100)
+		retrieve.BlockFunc = func(rosBlockID identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			heights = append(heights, *rosBlockID.Index)
+			return &object.Block{}, nil, nil
+		}
+
+		p := BaselinePrefetcher(t, WithRetriever(retrieve), WithBlocks(3))
+
+		err := p.catchUp(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{98, 99, 100}, heights)
+		assert.Equal(t, uint64(100), p.last)
+	})
+
+	t.Run("only prefetches new blocks", func(t *testing.T) {
+		t.Parallel()
+
+		var heights []uint64
+		retrieve := currentAt(t, 100)
+		retrieve.BlockFunc = func(rosBlockID identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			heights = append(heights, *rosBlockID.Index)
+			return &object.Block{}, nil, nil
+		}
+
+		p := BaselinePrefetcher(t, WithRetriever(retrieve), WithBlocks(10))
+		p.last = 98
+
+		err := p.catchUp(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{99, 100}, heights)
+	})
+
+	t.Run("handles retriever failure for current block", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{}, time.Time{}, mocks.GenericError
+		}
+
+		p := BaselinePrefetcher(t, WithRetriever(retrieve))
+
+		err := p.catchUp(context.Background())
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles retriever failure for block and resumes later", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := currentAt(t, 100)
+		retrieve.BlockFunc = func(rosBlockID identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			if *rosBlockID.Index == 100 {
+				return nil, nil, mocks.GenericError
+			}
+			return &object.Block{}, nil, nil
+		}
+
+		p := BaselinePrefetcher(t, WithRetriever(retrieve), WithBlocks(3))
+
+		err := p.catchUp(context.Background())
+
+		assert.Error(t, err)
+		assert.Equal(t, uint64(99), p.last)
+	})
+}
+
+func currentAt(t *testing.T, height uint64) *mocks.Retriever {
+	t.Helper()
+
+	retrieve := mocks.BaselineRetriever(t)
+	retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+		return identifier.Block{Index: &height}, time.Time{}, nil
+	}
+
+	return retrieve
+}
+
+func BaselinePrefetcher(t *testing.T, opts ...func(*Prefetcher)) *Prefetcher {
+	t.Helper()
+
+	p := Prefetcher{
+		log:      zerolog.Nop(),
+		cfg:      Config{Poll: time.Minute, Depth: 10},
+		retrieve: mocks.BaselineRetriever(t),
+	}
+
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	return &p
+}
+
+func WithRetriever(retrieve Retriever) func(*Prefetcher) {
+	return func(prefetcher *Prefetcher) {
+		prefetcher.retrieve = retrieve
+	}
+}
+
+func WithBlocks(depth uint64) func(*Prefetcher) {
+	return func(prefetcher *Prefetcher) {
+		prefetcher.cfg.Depth = depth
+	}
+}
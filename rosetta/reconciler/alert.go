@@ -0,0 +1,102 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package reconciler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rs/zerolog"
+
+	"github.com/optakt/flow-rosetta/rosetta/alert"
+)
+
+// Alerter represents something that can notify operators of a mismatch.
+type Alerter interface {
+	Alert(mismatch Mismatch) error
+}
+
+// Notifier represents something that can notify operators of an operational event.
+type Notifier interface {
+	Notify(event alert.Event) error
+}
+
+// Log is an alerter that writes mismatches to the log.
+type Log struct {
+	log zerolog.Logger
+}
+
+// NewLog creates a new alerter that writes mismatches to the given logger.
+func NewLog(log zerolog.Logger) *Log {
+
+	l := Log{
+		log: log,
+	}
+
+	return &l
+}
+
+// Alert logs the given mismatch as an error.
+func (l *Log) Alert(mismatch Mismatch) error {
+	l.log.Error().
+		Str("address", mismatch.AccountID.Address).
+		Uint64("start", mismatch.StartIndex).
+		Uint64("end", mismatch.EndIndex).
+		Str("computed", mismatch.Computed).
+		Str("actual", mismatch.Actual).
+		Msg("account balance mismatch detected")
+	return nil
+}
+
+// Notify is an alerter that reports mismatches as reconciliation drift events
+// to a notifier, such as an operational alerting webhook.
+type Notify struct {
+	notify Notifier
+}
+
+// NewNotify creates a new alerter that reports mismatches to the given notifier.
+func NewNotify(notify Notifier) *Notify {
+
+	n := Notify{
+		notify: notify,
+	}
+
+	return &n
+}
+
+// Alert reports the given mismatch as a reconciliation drift event.
+func (n *Notify) Alert(mismatch Mismatch) error {
+
+	event := alert.Event{
+		Kind:     "reconciliation_drift",
+		Key:      mismatch.AccountID.Address,
+		Severity: alert.SeverityError,
+		Summary:  fmt.Sprintf("account balance mismatch detected for %s", mismatch.AccountID.Address),
+		Details: map[string]string{
+			"address":     mismatch.AccountID.Address,
+			"start_index": strconv.FormatUint(mismatch.StartIndex, 10),
+			"end_index":   strconv.FormatUint(mismatch.EndIndex, 10),
+			"computed":    mismatch.Computed,
+			"actual":      mismatch.Actual,
+		},
+	}
+
+	err := n.notify.Notify(event)
+	if err != nil {
+		return fmt.Errorf("could not notify mismatch: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package reconciler
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/alert"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestLog_Alert(t *testing.T) {
+	mismatch := Mismatch{
+		AccountID:  mocks.GenericAccountID(0),
+		StartIndex: 6,
+		EndIndex:   15,
+		Computed:   "75",
+		Actual:     "100",
+	}
+
+	var buf bytes.Buffer
+	l := NewLog(zerolog.New(&buf))
+
+	err := l.Alert(mismatch)
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), mismatch.AccountID.Address)
+	assert.Contains(t, buf.String(), `"computed":"75"`)
+	assert.Contains(t, buf.String(), `"actual":"100"`)
+}
+
+func TestNotify_Alert(t *testing.T) {
+	mismatch := Mismatch{
+		AccountID:  mocks.GenericAccountID(0),
+		StartIndex: 6,
+		EndIndex:   15,
+		Computed:   "75",
+		Actual:     "100",
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var got alert.Event
+		notify := mocks.BaselineNotifier(t)
+		notify.NotifyFunc = func(event alert.Event) error {
+			got = event
+			return nil
+		}
+
+		n := NewNotify(notify)
+
+		err := n.Alert(mismatch)
+
+		require.NoError(t, err)
+		assert.Equal(t, "reconciliation_drift", got.Kind)
+		assert.Equal(t, mismatch.AccountID.Address, got.Key)
+		assert.Equal(t, alert.SeverityError, got.Severity)
+		assert.Equal(t, "6", got.Details["start_index"])
+		assert.Equal(t, "15", got.Details["end_index"])
+		assert.Equal(t, "75", got.Details["computed"])
+		assert.Equal(t, "100", got.Details["actual"])
+	})
+
+	t.Run("handles notifier failure", func(t *testing.T) {
+		t.Parallel()
+
+		notify := mocks.BaselineNotifier(t)
+		notify.NotifyFunc = func(alert.Event) error {
+			return mocks.GenericError
+		}
+
+		n := NewNotify(notify)
+
+		err := n.Alert(mismatch)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package reconciler
+
+import (
+	"time"
+)
+
+// Config contains the configuration options for the reconciler and its worker.
+type Config struct {
+	Interval   uint64
+	SampleSize uint
+	Poll       time.Duration
+}
+
+// WithInterval sets the number of blocks between two balance checkpoints. A
+// smaller interval narrows down the block range reported for a mismatch, at the
+// cost of executing more balance scripts.
+func WithInterval(interval uint64) func(*Config) {
+	return func(c *Config) {
+		c.Interval = interval
+	}
+}
+
+// WithSampleSize sets the maximum number of active accounts that the worker
+// reconciles for each range of new blocks.
+func WithSampleSize(size uint) func(*Config) {
+	return func(c *Config) {
+		c.SampleSize = size
+	}
+}
+
+// WithPoll sets how often the worker checks for new blocks to reconcile.
+func WithPoll(poll time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.Poll = poll
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package reconciler
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics contains the metrics that the worker exposes about reconciliation.
+type Metrics struct {
+	height     prometheus.Gauge
+	accounts   prometheus.Counter
+	mismatches prometheus.Counter
+	drift      prometheus.Counter
+}
+
+// NewMetrics creates the reconciliation metrics and registers them with the
+// given registerer.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+
+	factory := promauto.With(reg)
+	m := Metrics{
+		height: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "reconciler",
+			Name:      "height",
+			Help:      "last block height up to which balances were reconciled",
+		}),
+		accounts: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "reconciler",
+			Name:      "accounts_total",
+			Help:      "number of account balances reconciled",
+		}),
+		mismatches: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "reconciler",
+			Name:      "mismatches_total",
+			Help:      "number of balance mismatches detected",
+		}),
+		drift: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: "flow_rosetta",
+			Subsystem: "reconciler",
+			Name:      "drift_total",
+			Help:      "absolute difference between computed and actual balances, in the smallest unit of the currency",
+		}),
+	}
+
+	return &m
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package reconciler
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+// Mismatch is a difference between the balance computed by replaying the
+// operations of an account and the balance returned by the balance script. The
+// offending operations are within the range of blocks between the start and end
+// indices, both included.
+type Mismatch struct {
+	AccountID  identifier.Account `json:"account_identifier"`
+	StartIndex uint64             `json:"start_index"`
+	EndIndex   uint64             `json:"end_index"`
+	Computed   string             `json:"computed"`
+	Actual     string             `json:"actual"`
+}
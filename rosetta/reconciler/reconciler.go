@@ -0,0 +1,155 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package reconciler
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+// Reconciler replays the operations affecting accounts and compares the balances
+// they add up to with the balances returned by the balance scripts at regular
+// checkpoints, similar to the balance reconciliation of the Rosetta CLI.
+type Reconciler struct {
+	cfg      Config
+	retrieve Retriever
+}
+
+// New creates a new Reconciler that uses the given retriever.
+func New(retrieve Retriever, options ...func(*Config)) *Reconciler {
+
+	cfg := Config{
+		Interval: 100,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	// An interval of zero would never advance to the next checkpoint, so we
+	// check the balance after every block instead.
+	if cfg.Interval == 0 {
+		cfg.Interval = 1
+	}
+
+	r := Reconciler{
+		cfg:      cfg,
+		retrieve: retrieve,
+	}
+
+	return &r
+}
+
+// Reconcile checks the balances of the given accounts between the given start and
+// end heights, both included. The balance at the start height is used as the
+// baseline, so only the operations of the following blocks are replayed.
+func (r *Reconciler) Reconcile(rosAccountIDs []identifier.Account, start uint64, end uint64) ([]Mismatch, error) {
+
+	if start > end {
+		return nil, fmt.Errorf("invalid range (start: %d, end: %d)", start, end)
+	}
+
+	var mismatches []Mismatch
+	for _, rosAccountID := range rosAccountIDs {
+		found, err := r.account(rosAccountID, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("could not reconcile account (address: %s): %w", rosAccountID.Address, err)
+		}
+		mismatches = append(mismatches, found...)
+	}
+
+	return mismatches, nil
+}
+
+func (r *Reconciler) account(rosAccountID identifier.Account, start uint64, end uint64) ([]Mismatch, error) {
+
+	computed, err := r.balance(rosAccountID, start)
+	if err != nil {
+		return nil, fmt.Errorf("could not get baseline balance (height: %d): %w", start, err)
+	}
+
+	var mismatches []Mismatch
+	for from := start + 1; from <= end; from += r.cfg.Interval {
+
+		to := from + r.cfg.Interval - 1
+		if to > end {
+			to = end
+		}
+
+		// Replay all of the operations between the two checkpoints on the
+		// balance computed so far.
+		first, last := from, to
+		entries, err := r.retrieve.Statement(rosAccountID, identifier.Block{Index: &first}, identifier.Block{Index: &last})
+		if err != nil {
+			return nil, fmt.Errorf("could not get statement (start: %d, end: %d): %w", from, to, err)
+		}
+		for _, entry := range entries {
+			value, err := amount.Big(entry.Operation.Amount.Value)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse operation amount (tx: %s): %w", entry.TransactionID.Hash, err)
+			}
+			computed.Add(computed, value)
+		}
+
+		actual, err := r.balance(rosAccountID, to)
+		if err != nil {
+			return nil, fmt.Errorf("could not get checkpoint balance (height: %d): %w", to, err)
+		}
+		if computed.Cmp(actual) != 0 {
+			mismatch := Mismatch{
+				AccountID:  rosAccountID,
+				StartIndex: from,
+				EndIndex:   to,
+				Computed:   computed.String(),
+				Actual:     actual.String(),
+			}
+			mismatches = append(mismatches, mismatch)
+		}
+
+		// We continue from the actual balance, so that each mismatch is only
+		// reported for the range of blocks in which it happened.
+		computed = actual
+
+		// Avoid overflowing the height when the end is at the very top.
+		if to == end {
+			break
+		}
+	}
+
+	return mismatches, nil
+}
+
+func (r *Reconciler) balance(rosAccountID identifier.Account, height uint64) (*big.Int, error) {
+
+	currencies := []identifier.Currency{{Symbol: dps.FlowSymbol, Decimals: dps.FlowDecimals}}
+	_, amounts, err := r.retrieve.Balances(identifier.Block{Index: &height}, rosAccountID, currencies)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve balances: %w", err)
+	}
+	if len(amounts) != 1 {
+		return nil, fmt.Errorf("invalid number of balances (have: %d, want: 1)", len(amounts))
+	}
+
+	balance, err := amount.Big(amounts[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse balance: %w", err)
+	}
+
+	return balance, nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package reconciler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestNew(t *testing.T) {
+	retrieve := mocks.BaselineRetriever(t)
+
+	r := New(retrieve, WithInterval(10))
+
+	require.NotNil(t, r)
+	assert.Equal(t, retrieve, r.retrieve)
+	assert.Equal(t, uint64(10), r.cfg.Interval)
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	rosAccountID := mocks.GenericAccountID(0)
+	otherAccountID := mocks.GenericAccountID(1)
+
+	// The balances at each height, which the statement entries are built from.
+	balances := []int64{100, 100, 150, 120, 120, 200}
+	balance := func(height uint64) string {
+		return fmt.Sprint(balances[height])
+	}
+	statement := func(start uint64, end uint64) []object.StatementEntry {
+		var entries []object.StatementEntry
+		for height := start; height <= end; height++ {
+			delta := balances[height] - balances[height-1]
+			if delta == 0 {
+				continue
+			}
+			entry := object.StatementEntry{
+				Operation: &object.Operation{
+					Amount: object.Amount{Value: fmt.Sprint(delta)},
+				},
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var ranges [][2]uint64
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.StatementFunc = func(_ identifier.Account, rosStart identifier.Block, rosEnd identifier.Block) ([]object.StatementEntry, error) {
+			ranges = append(ranges, [2]uint64{*rosStart.Index, *rosEnd.Index})
+			return statement(*rosStart.Index, *rosEnd.Index), nil
+		}
+		retrieve.BalancesFunc = func(rosBlockID identifier.Block, _ identifier.Account, rosCurrencies []identifier.Currency) (identifier.Block, []object.Amount, error) {
+			assert.Equal(t, []identifier.Currency{{Symbol: dps.FlowSymbol, Decimals: dps.FlowDecimals}}, rosCurrencies)
+			return rosBlockID, []object.Amount{{Value: balance(*rosBlockID.Index)}}, nil
+		}
+
+		r := BaselineReconciler(t, WithRetriever(retrieve), WithCheckpoints(2))
+
+		mismatches, err := r.Reconcile([]identifier.Account{rosAccountID}, 0, 5)
+
+		require.NoError(t, err)
+		assert.Empty(t, mismatches)
+		assert.Equal(t, [][2]uint64{{1, 2}, {3, 4}, {5, 5}}, ranges)
+	})
+
+	t.Run("reports offending range", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.StatementFunc = func(rosAccountID identifier.Account, rosStart identifier.Block, rosEnd identifier.Block) ([]object.StatementEntry, error) {
+			entries := statement(*rosStart.Index, *rosEnd.Index)
+
+			// Drop the operations of block 3 for the other account only.
+			if rosAccountID == otherAccountID && *rosStart.Index == 3 {
+				entries = entries[:0]
+			}
+			return entries, nil
+		}
+		retrieve.BalancesFunc = func(rosBlockID identifier.Block, _ identifier.Account, _ []identifier.Currency) (identifier.Block, []object.Amount, error) {
+			return rosBlockID, []object.Amount{{Value: balance(*rosBlockID.Index)}}, nil
+		}
+
+		r := BaselineReconciler(t, WithRetriever(retrieve), WithCheckpoints(2))
+
+		mismatches, err := r.Reconcile([]identifier.Account{rosAccountID, otherAccountID}, 0, 5)
+
+		require.NoError(t, err)
+		want := []Mismatch{
+			{
+				AccountID:  otherAccountID,
+				StartIndex: 3,
+				EndIndex:   4,
+				Computed:   "150",
+				Actual:     "120",
+			},
+		}
+		assert.Equal(t, want, mismatches)
+	})
+
+	t.Run("handles inverted range", func(t *testing.T) {
+		t.Parallel()
+
+		r := BaselineReconciler(t)
+
+		_, err := r.Reconcile([]identifier.Account{rosAccountID}, 5, 0)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles statement failure", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.StatementFunc = func(identifier.Account, identifier.Block, identifier.Block) ([]object.StatementEntry, error) {
+			return nil, mocks.GenericError
+		}
+
+		r := BaselineReconciler(t, WithRetriever(retrieve))
+
+		_, err := r.Reconcile([]identifier.Account{rosAccountID}, 0, 5)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles balances failure", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.BalancesFunc = func(identifier.Block, identifier.Account, []identifier.Currency) (identifier.Block, []object.Amount, error) {
+			return identifier.Block{}, nil, mocks.GenericError
+		}
+
+		r := BaselineReconciler(t, WithRetriever(retrieve))
+
+		_, err := r.Reconcile([]identifier.Account{rosAccountID}, 0, 5)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid operation amount", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.StatementFunc = func(identifier.Account, identifier.Block, identifier.Block) ([]object.StatementEntry, error) {
+			entry := object.StatementEntry{
+				Operation: &object.Operation{
+					Amount: object.Amount{Value: "invalid"},
+				},
+			}
+			return []object.StatementEntry{entry}, nil
+		}
+
+		r := BaselineReconciler(t, WithRetriever(retrieve))
+
+		_, err := r.Reconcile([]identifier.Account{rosAccountID}, 0, 5)
+
+		assert.Error(t, err)
+	})
+}
+
+func BaselineReconciler(t *testing.T, opts ...func(*Reconciler)) *Reconciler {
+	t.Helper()
+
+	r := Reconciler{
+		cfg: Config{
+			Interval: 100,
+		},
+		retrieve: mocks.BaselineRetriever(t),
+	}
+
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	return &r
+}
+
+func WithRetriever(retrieve Retriever) func(*Reconciler) {
+	return func(r *Reconciler) {
+		r.retrieve = retrieve
+	}
+}
+
+func WithCheckpoints(interval uint64) func(*Reconciler) {
+	return func(r *Reconciler) {
+		r.cfg.Interval = interval
+	}
+}
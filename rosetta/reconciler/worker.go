@@ -0,0 +1,225 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+// Worker continuously reconciles the balances of a sample of the accounts that
+// are active in new blocks, and raises an alert for every mismatch it detects.
+type Worker struct {
+	log       zerolog.Logger
+	cfg       Config
+	retrieve  Retriever
+	reconcile *Reconciler
+	alert     Alerter
+	metrics   *Metrics
+	random    *rand.Rand
+
+	// last is the height up to which balances were reconciled.
+	last uint64
+}
+
+// NewWorker creates a new reconciliation worker, which uses the given retriever
+// to follow new blocks and the given alerter to report mismatches.
+func NewWorker(log zerolog.Logger, retrieve Retriever, alert Alerter, metrics *Metrics, options ...func(*Config)) *Worker {
+
+	cfg := Config{
+		Interval:   100,
+		SampleSize: 10,
+		Poll:       30 * time.Second,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	// As for the reconciler, we need a positive interval to make progress.
+	if cfg.Interval == 0 {
+		cfg.Interval = 1
+	}
+
+	w := Worker{
+		log:       log.With().Str("component", "reconciler").Logger(),
+		cfg:       cfg,
+		retrieve:  retrieve,
+		reconcile: New(retrieve, options...),
+		alert:     alert,
+		metrics:   metrics,
+		random:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	return &w
+}
+
+// Run reconciles new blocks as they arrive until the given context is canceled.
+// Reconciliation starts at the block that is current when the worker starts.
+func (w *Worker) Run(ctx context.Context) error {
+
+	rosBlockID, _, err := w.retrieve.Current()
+	if err != nil {
+		return fmt.Errorf("could not get current block: %w", err)
+	}
+	w.last = *rosBlockID.Index
+
+	ticker := time.NewTicker(w.cfg.Poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Failures are logged rather than returned, so that a temporary
+		// problem with the index does not stop the reconciliation.
+		err := w.catchUp(ctx)
+		if err != nil {
+			w.log.Warn().Uint64("last", w.last).Err(err).Msg("could not reconcile new blocks")
+		}
+	}
+}
+
+// catchUp reconciles all blocks up to the current one, one checkpoint interval
+// at a time, so that a worker that fell behind does not sample from the whole
+// backlog at once.
+func (w *Worker) catchUp(ctx context.Context) error {
+
+	rosBlockID, _, err := w.retrieve.Current()
+	if err != nil {
+		return fmt.Errorf("could not get current block: %w", err)
+	}
+	current := *rosBlockID.Index
+
+	for w.last < current {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		end := w.last + w.cfg.Interval
+		if end > current {
+			end = current
+		}
+		err = w.step(w.last, end)
+		if err != nil {
+			return fmt.Errorf("could not reconcile range (start: %d, end: %d): %w", w.last+1, end, err)
+		}
+		w.last = end
+		w.metrics.height.Set(float64(end))
+	}
+
+	return nil
+}
+
+// step reconciles a sample of the accounts that were active after the given
+// start height, up to and including the given end height.
+func (w *Worker) step(start uint64, end uint64) error {
+
+	rosAccountIDs, err := w.sample(start+1, end)
+	if err != nil {
+		return fmt.Errorf("could not sample active accounts: %w", err)
+	}
+	if len(rosAccountIDs) == 0 {
+		return nil
+	}
+
+	mismatches, err := w.reconcile.Reconcile(rosAccountIDs, start, end)
+	if err != nil {
+		return fmt.Errorf("could not reconcile accounts: %w", err)
+	}
+	w.metrics.accounts.Add(float64(len(rosAccountIDs)))
+
+	for _, mismatch := range mismatches {
+		w.metrics.mismatches.Inc()
+		w.metrics.drift.Add(drift(mismatch))
+
+		err = w.alert.Alert(mismatch)
+		if err != nil {
+			w.log.Warn().Str("address", mismatch.AccountID.Address).Err(err).Msg("could not send mismatch alert")
+		}
+	}
+
+	w.log.Debug().
+		Uint64("start", start+1).
+		Uint64("end", end).
+		Int("accounts", len(rosAccountIDs)).
+		Int("mismatches", len(mismatches)).
+		Msg("reconciled active accounts")
+
+	return nil
+}
+
+// sample returns a random subset of the accounts affected by operations
+// between the given start and end heights, both included.
+func (w *Worker) sample(start uint64, end uint64) ([]identifier.Account, error) {
+
+	seen := make(map[string]struct{})
+	var rosAccountIDs []identifier.Account
+	for height := start; height <= end; height++ {
+		index := height
+		block, _, err := w.retrieve.Block(identifier.Block{Index: &index})
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve block (height: %d): %w", height, err)
+		}
+		for _, transaction := range block.Transactions {
+			for _, op := range transaction.Operations {
+				_, ok := seen[op.AccountID.Address]
+				if ok {
+					continue
+				}
+				seen[op.AccountID.Address] = struct{}{}
+				rosAccountIDs = append(rosAccountIDs, op.AccountID)
+			}
+		}
+	}
+
+	if uint(len(rosAccountIDs)) <= w.cfg.SampleSize {
+		return rosAccountIDs, nil
+	}
+
+	w.random.Shuffle(len(rosAccountIDs), func(i int, j int) {
+		rosAccountIDs[i], rosAccountIDs[j] = rosAccountIDs[j], rosAccountIDs[i]
+	})
+
+	return rosAccountIDs[:w.cfg.SampleSize], nil
+}
+
+// drift returns the absolute difference between the computed and the actual
+// balance of a mismatch.
+func drift(mismatch Mismatch) float64 {
+
+	computed, err := amount.Big(mismatch.Computed)
+	if err != nil {
+		return 0
+	}
+	actual, err := amount.Big(mismatch.Actual)
+	if err != nil {
+		return 0
+	}
+
+	difference, _ := new(big.Float).SetInt(computed.Sub(computed, actual).Abs(computed)).Float64()
+
+	return difference
+}
@@ -0,0 +1,269 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestNewWorker(t *testing.T) {
+	retrieve := mocks.BaselineRetriever(t)
+	alert := NewLog(zerolog.Nop())
+	metrics := NewMetrics(prometheus.NewRegistry())
+
+	w := NewWorker(zerolog.Nop(), retrieve, alert, metrics,
+		WithInterval(10),
+		WithSampleSize(5),
+		WithPoll(time.Minute),
+	)
+
+	require.NotNil(t, w)
+	assert.Equal(t, retrieve, w.retrieve)
+	assert.Equal(t, alert, w.alert)
+	assert.Equal(t, metrics, w.metrics)
+	assert.Equal(t, uint64(10), w.cfg.Interval)
+	assert.Equal(t, uint(5), w.cfg.SampleSize)
+	assert.Equal(t, time.Minute, w.cfg.Poll)
+	require.NotNil(t, w.reconcile)
+	assert.Equal(t, uint64(10), w.reconcile.cfg.Interval)
+}
+
+func TestWorker_Run(t *testing.T) {
+	t.Run("stops on context cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		w := BaselineWorker(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := w.Run(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, *mocks.GenericRosBlockID.Index, w.last)
+	})
+
+	t.Run("handles retriever failure", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{}, time.Time{}, mocks.GenericError
+		}
+
+		w := BaselineWorker(t, WithWorkerRetriever(retrieve))
+
+		err := w.Run(context.Background())
+
+		assert.Error(t, err)
+	})
+}
+
+func TestWorker_CatchUp(t *testing.T) {
+	current := uint64(25)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var ranges [][2]uint64
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{Index: &current}, time.Time{}, nil
+		}
+		retrieve.StatementFunc = func(_ identifier.Account, rosStart identifier.Block, rosEnd identifier.Block) ([]object.StatementEntry, error) {
+			ranges = append(ranges, [2]uint64{*rosStart.Index, *rosEnd.Index})
+			return nil, nil
+		}
+		retrieve.BalancesFunc = func(rosBlockID identifier.Block, _ identifier.Account, _ []identifier.Currency) (identifier.Block, []object.Amount, error) {
+			return rosBlockID, []object.Amount{{Value: "100"}}, nil
+		}
+
+		w := BaselineWorker(t, WithWorkerRetriever(retrieve))
+		w.last = 5
+
+		err := w.catchUp(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, current, w.last)
+		assert.Equal(t, float64(current), testutil.ToFloat64(w.metrics.height))
+		assert.Equal(t, float64(4), testutil.ToFloat64(w.metrics.accounts))
+		assert.Zero(t, testutil.ToFloat64(w.metrics.mismatches))
+
+		// Each of the two sampled accounts is reconciled for each of the two intervals.
+		assert.Equal(t, [][2]uint64{{6, 15}, {6, 15}, {16, 25}, {16, 25}}, ranges)
+	})
+
+	t.Run("alerts on mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{Index: &current}, time.Time{}, nil
+		}
+		retrieve.StatementFunc = func(identifier.Account, identifier.Block, identifier.Block) ([]object.StatementEntry, error) {
+			entry := object.StatementEntry{
+				Operation: &object.Operation{
+					Amount: object.Amount{Value: "-25"},
+				},
+			}
+			return []object.StatementEntry{entry}, nil
+		}
+		retrieve.BalancesFunc = func(rosBlockID identifier.Block, _ identifier.Account, _ []identifier.Currency) (identifier.Block, []object.Amount, error) {
+			return rosBlockID, []object.Amount{{Value: "100"}}, nil
+		}
+
+		var buf bytes.Buffer
+		w := BaselineWorker(t,
+			WithWorkerRetriever(retrieve),
+			WithAlerter(NewLog(zerolog.New(&buf))),
+			WithSample(1),
+		)
+		w.last = 15
+
+		err := w.catchUp(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, float64(1), testutil.ToFloat64(w.metrics.mismatches))
+		assert.Equal(t, float64(25), testutil.ToFloat64(w.metrics.drift))
+		assert.Equal(t, 1, strings.Count(buf.String(), "account balance mismatch detected"))
+	})
+
+	t.Run("handles block failure", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{Index: &current}, time.Time{}, nil
+		}
+		retrieve.BlockFunc = func(identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			return nil, nil, mocks.GenericError
+		}
+
+		w := BaselineWorker(t, WithWorkerRetriever(retrieve))
+		w.last = 5
+
+		err := w.catchUp(context.Background())
+
+		assert.Error(t, err)
+		assert.Equal(t, uint64(5), w.last)
+	})
+
+	t.Run("handles reconciler failure", func(t *testing.T) {
+		t.Parallel()
+
+		retrieve := mocks.BaselineRetriever(t)
+		retrieve.CurrentFunc = func() (identifier.Block, time.Time, error) {
+			return identifier.Block{Index: &current}, time.Time{}, nil
+		}
+		retrieve.StatementFunc = func(identifier.Account, identifier.Block, identifier.Block) ([]object.StatementEntry, error) {
+			return nil, mocks.GenericError
+		}
+
+		w := BaselineWorker(t, WithWorkerRetriever(retrieve))
+		w.last = 5
+
+		err := w.catchUp(context.Background())
+
+		assert.Error(t, err)
+		assert.Equal(t, uint64(5), w.last)
+	})
+}
+
+func TestWorker_Sample(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		w := BaselineWorker(t)
+
+		rosAccountIDs, err := w.sample(1, 3)
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []identifier.Account{mocks.GenericAccountID(0), mocks.GenericAccountID(1)}, rosAccountIDs)
+	})
+
+	t.Run("limits sample size", func(t *testing.T) {
+		t.Parallel()
+
+		w := BaselineWorker(t, WithSample(1))
+
+		rosAccountIDs, err := w.sample(1, 3)
+
+		require.NoError(t, err)
+		assert.Len(t, rosAccountIDs, 1)
+	})
+}
+
+func BaselineWorker(t *testing.T, opts ...func(*Worker)) *Worker {
+	t.Helper()
+
+	cfg := Config{
+		Interval:   10,
+		SampleSize: 10,
+		Poll:       time.Millisecond,
+	}
+	retrieve := mocks.BaselineRetriever(t)
+
+	w := Worker{
+		log:      zerolog.Nop(),
+		cfg:      cfg,
+		retrieve: retrieve,
+		reconcile: &Reconciler{
+			cfg:      cfg,
+			retrieve: retrieve,
+		},
+		alert:   NewLog(zerolog.Nop()),
+		metrics: NewMetrics(prometheus.NewRegistry()),
+		random:  rand.New(rand.NewSource(0)),
+	}
+
+	for _, opt := range opts {
+		opt(&w)
+	}
+
+	return &w
+}
+
+func WithWorkerRetriever(retrieve Retriever) func(*Worker) {
+	return func(w *Worker) {
+		w.retrieve = retrieve
+		w.reconcile.retrieve = retrieve
+	}
+}
+
+func WithAlerter(alert Alerter) func(*Worker) {
+	return func(w *Worker) {
+		w.alert = alert
+	}
+}
+
+func WithSample(size uint) func(*Worker) {
+	return func(w *Worker) {
+		w.cfg.SampleSize = size
+	}
+}
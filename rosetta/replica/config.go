@@ -0,0 +1,32 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package replica
+
+import (
+	"time"
+)
+
+// Config contains the configuration options for the replica.
+type Config struct {
+	Refresh time.Duration
+}
+
+// WithRefresh sets how often the replica checks whether a newer snapshot was
+// published, on top of how often snapshots are exported.
+func WithRefresh(refresh time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.Refresh = refresh
+	}
+}
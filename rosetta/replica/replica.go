@@ -0,0 +1,303 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package replica
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/index"
+
+	"github.com/optakt/flow-rosetta/rosetta/snapshot"
+)
+
+// Replica is a DPS index reader over a private copy of an index database, which
+// it loads from the snapshots that are periodically exported from the index of
+// the indexer. As a replica never opens the database of the indexer, any number
+// of them can share its snapshots without any risk of corrupting it. Badger does
+// not allow exporting a database that another process holds open, however, so
+// the snapshots can only be exported while the indexer is stopped, and a replica
+// only sees the heights that were indexed before the last export.
+//
+// Whenever a newer snapshot is published at the configured path, the replica
+// loads it into a new full copy of the index, and swaps it with the previous one
+// once no more reads are in flight on the latter, so that the disk space of two
+// copies of the index is needed while refreshing. Snapshots should be published
+// by renaming them into place, so that a replica never loads a partial one.
+type Replica struct {
+	log  zerolog.Logger
+	cfg  Config
+	path string
+	dir  string
+	lib  dps.ReadLibrary
+
+	mutex    sync.RWMutex
+	db       *badger.DB
+	index    dps.Reader
+	current  string
+	modified time.Time
+}
+
+// New creates a replica of the index in the snapshot at the given path, keeping
+// its copies of the index in the given directory. It fails if the snapshot can
+// not be loaded right away.
+func New(log zerolog.Logger, path string, dir string, lib dps.ReadLibrary, options ...func(*Config)) (*Replica, error) {
+
+	cfg := Config{
+		Refresh: time.Minute,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	r := Replica{
+		log:  log.With().Str("component", "replica").Logger(),
+		cfg:  cfg,
+		path: path,
+		dir:  dir,
+		lib:  lib,
+	}
+
+	err := r.Refresh()
+	if err != nil {
+		return nil, fmt.Errorf("could not load initial snapshot: %w", err)
+	}
+
+	return &r, nil
+}
+
+// Run checks for new snapshots at the configured interval until the given
+// context is canceled.
+func (r *Replica) Run(ctx context.Context) error {
+
+	ticker := time.NewTicker(r.cfg.Refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// If a snapshot can not be loaded, we keep serving from the previous
+		// copy and try again later.
+		err := r.Refresh()
+		if err != nil {
+			r.log.Warn().Str("snapshot", r.path).Err(err).Msg("could not refresh index replica")
+		}
+	}
+}
+
+// Refresh loads the snapshot into a new copy of the index if it was modified
+// since it was last loaded, so that the heights indexed since become visible.
+func (r *Replica) Refresh() error {
+
+	file, err := os.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("could not open snapshot: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not stat snapshot: %w", err)
+	}
+	if !info.ModTime().After(r.modified) {
+		return nil
+	}
+
+	target, err := os.MkdirTemp(r.dir, "replica-")
+	if err != nil {
+		return fmt.Errorf("could not create index directory: %w", err)
+	}
+	db, err := badger.Open(dps.DefaultOptions(target))
+	if err != nil {
+		_ = os.RemoveAll(target)
+		return fmt.Errorf("could not open index database: %w", err)
+	}
+	err = snapshot.Import(db, file)
+	if err != nil {
+		_ = db.Close()
+		_ = os.RemoveAll(target)
+		return fmt.Errorf("could not import snapshot: %w", err)
+	}
+
+	// Taking the write lock waits for the reads on the previous copy to
+	// complete, so it can be removed as soon as the new one is in place.
+	r.mutex.Lock()
+	staleDB, staleCopy := r.db, r.current
+	r.db = db
+	r.index = index.NewReader(db, r.lib)
+	r.current = target
+	r.modified = info.ModTime()
+	r.mutex.Unlock()
+
+	r.log.Info().Str("snapshot", r.path).Time("modified", info.ModTime()).Msg("index replica refreshed")
+
+	if staleDB == nil {
+		return nil
+	}
+	err = staleDB.Close()
+	if err != nil {
+		return fmt.Errorf("could not close previous index database: %w", err)
+	}
+	err = os.RemoveAll(staleCopy)
+	if err != nil {
+		return fmt.Errorf("could not remove previous index directory: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the current copy of the index and removes it.
+func (r *Replica) Close() error {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	err := r.db.Close()
+	if err != nil {
+		return fmt.Errorf("could not close index database: %w", err)
+	}
+	err = os.RemoveAll(r.current)
+	if err != nil {
+		return fmt.Errorf("could not remove index directory: %w", err)
+	}
+
+	return nil
+}
+
+// First returns the height of the first indexed block.
+func (r *Replica) First() (uint64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.First()
+}
+
+// Last returns the height of the last indexed block.
+func (r *Replica) Last() (uint64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.Last()
+}
+
+// HeightForBlock returns the height of the block with the given ID.
+func (r *Replica) HeightForBlock(blockID flow.Identifier) (uint64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.HeightForBlock(blockID)
+}
+
+// HeightForTransaction returns the height of the block that contains the transaction with the given ID.
+func (r *Replica) HeightForTransaction(txID flow.Identifier) (uint64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.HeightForTransaction(txID)
+}
+
+// Commit returns the state commitment at the given height.
+func (r *Replica) Commit(height uint64) (flow.StateCommitment, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.Commit(height)
+}
+
+// Header returns the header at the given height.
+func (r *Replica) Header(height uint64) (*flow.Header, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.Header(height)
+}
+
+// Events returns the events of the given types at the given height.
+func (r *Replica) Events(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.Events(height, types...)
+}
+
+// Values returns the register values for the given paths at the given height.
+func (r *Replica) Values(height uint64, paths []ledger.Path) ([]ledger.Value, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.Values(height, paths)
+}
+
+// Collection returns the collection with the given ID.
+func (r *Replica) Collection(collID flow.Identifier) (*flow.LightCollection, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.Collection(collID)
+}
+
+// Guarantee returns the guarantee for the collection with the given ID.
+func (r *Replica) Guarantee(collID flow.Identifier) (*flow.CollectionGuarantee, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.Guarantee(collID)
+}
+
+// Transaction returns the transaction with the given ID.
+func (r *Replica) Transaction(txID flow.Identifier) (*flow.TransactionBody, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.Transaction(txID)
+}
+
+// Seal returns the seal with the given ID.
+func (r *Replica) Seal(sealID flow.Identifier) (*flow.Seal, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.Seal(sealID)
+}
+
+// Result returns the result of the transaction with the given ID.
+func (r *Replica) Result(txID flow.Identifier) (*flow.TransactionResult, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.Result(txID)
+}
+
+// CollectionsByHeight returns the IDs of the collections at the given height.
+func (r *Replica) CollectionsByHeight(height uint64) ([]flow.Identifier, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.CollectionsByHeight(height)
+}
+
+// TransactionsByHeight returns the IDs of the transactions at the given height.
+func (r *Replica) TransactionsByHeight(height uint64) ([]flow.Identifier, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.TransactionsByHeight(height)
+}
+
+// SealsByHeight returns the IDs of the seals at the given height.
+func (r *Replica) SealsByHeight(height uint64) ([]flow.Identifier, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.index.SealsByHeight(height)
+}
@@ -0,0 +1,172 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package replica_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/service/storage"
+
+	"github.com/optakt/flow-rosetta/rosetta/replica"
+	"github.com/optakt/flow-rosetta/rosetta/snapshot"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestReplica(t *testing.T) {
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		primary := inMemoryDB(t)
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, primary.Update(lib.SaveFirst(10)))
+		require.NoError(t, primary.Update(lib.SaveLast(20)))
+		path := filepath.Join(t.TempDir(), "index.snap")
+		publish(t, primary, path, time.Now())
+
+		r, err := replica.New(zerolog.Nop(), path, t.TempDir(), lib)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = r.Close() })
+
+		first, err := r.First()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), first)
+		last, err := r.Last()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(20), last)
+	})
+
+	t.Run("loads newer snapshot on refresh", func(t *testing.T) {
+		t.Parallel()
+
+		primary := inMemoryDB(t)
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, primary.Update(lib.SaveLast(20)))
+		path := filepath.Join(t.TempDir(), "index.snap")
+		published := time.Now()
+		publish(t, primary, path, published)
+
+		dir := t.TempDir()
+		r, err := replica.New(zerolog.Nop(), path, dir, lib)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = r.Close() })
+
+		require.NoError(t, primary.Update(lib.SaveLast(21)))
+		publish(t, primary, path, published.Add(time.Minute))
+		require.NoError(t, r.Refresh())
+
+		last, err := r.Last()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(21), last)
+
+		// The copy of the previous snapshot is removed once replaced.
+		copies, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, copies, 1)
+	})
+
+	t.Run("keeps current copy when snapshot is unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		primary := inMemoryDB(t)
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, primary.Update(lib.SaveLast(20)))
+		path := filepath.Join(t.TempDir(), "index.snap")
+		published := time.Now()
+		publish(t, primary, path, published)
+
+		r, err := replica.New(zerolog.Nop(), path, t.TempDir(), lib)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = r.Close() })
+
+		require.NoError(t, primary.Update(lib.SaveLast(21)))
+		publish(t, primary, path, published)
+		require.NoError(t, r.Refresh())
+
+		last, err := r.Last()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(20), last)
+	})
+
+	t.Run("keeps current copy when snapshot is invalid", func(t *testing.T) {
+		t.Parallel()
+
+		primary := inMemoryDB(t)
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, primary.Update(lib.SaveLast(20)))
+		path := filepath.Join(t.TempDir(), "index.snap")
+		published := time.Now()
+		publish(t, primary, path, published)
+
+		dir := t.TempDir()
+		r, err := replica.New(zerolog.Nop(), path, dir, lib)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = r.Close() })
+
+		require.NoError(t, os.WriteFile(path, mocks.GenericBytes, 0600))
+		require.NoError(t, os.Chtimes(path, published.Add(time.Minute), published.Add(time.Minute)))
+		assert.Error(t, r.Refresh())
+
+		last, err := r.Last()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(20), last)
+		copies, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, copies, 1)
+	})
+
+	t.Run("handles missing snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := replica.New(zerolog.Nop(), filepath.Join(t.TempDir(), "missing.snap"), t.TempDir(), storage.New(zbor.NewCodec()))
+
+		assert.Error(t, err)
+	})
+}
+
+// publish exports a snapshot of the given database and renames it into place at
+// the given path, with the given modification time.
+func publish(t *testing.T, db *badger.DB, path string, modified time.Time) {
+	t.Helper()
+
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	require.NoError(t, err)
+	_, err = snapshot.Export(db, file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.NoError(t, os.Chtimes(tmp, modified, modified))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func inMemoryDB(t *testing.T) *badger.DB {
+	t.Helper()
+
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := badger.Open(opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package request
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+// AccountTransactions implements the request schema for /flow/account/transactions,
+// which is an extension to the Rosetta Data API. The start and end blocks are
+// optional and default to the range covered by the account history. When a
+// cursor is given, the listing resumes where the previous page ended.
+type AccountTransactions struct {
+	NetworkID    identifier.Network `json:"network_identifier"`
+	AccountID    identifier.Account `json:"account_identifier"`
+	StartBlockID identifier.Block   `json:"start_block_identifier"`
+	EndBlockID   identifier.Block   `json:"end_block_identifier"`
+	Limit        uint               `json:"limit,omitempty"`
+	Cursor       string             `json:"cursor,omitempty"`
+}
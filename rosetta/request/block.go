@@ -16,11 +16,13 @@ package request
 
 import (
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
 )
 
 // Block implements the request schema for /block.
 // See https://www.rosetta-api.org/docs/BlockApi.html#request
 type Block struct {
-	NetworkID identifier.Network `json:"network_identifier"`
-	BlockID   identifier.Block   `json:"block_identifier"`
+	NetworkID identifier.Network      `json:"network_identifier"`
+	BlockID   identifier.Block        `json:"block_identifier"`
+	Metadata  *object.OperationFilter `json:"metadata,omitempty"`
 }
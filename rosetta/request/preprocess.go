@@ -24,4 +24,7 @@ import (
 type Preprocess struct {
 	NetworkID  identifier.Network `json:"network_identifier"`
 	Operations []object.Operation `json:"operations"`
+	Metadata   *object.Roles      `json:"metadata,omitempty"`
+	MaxFee     []object.Amount    `json:"max_fee,omitempty"`
+	Multiplier float64            `json:"suggested_fee_multiplier,omitempty"`
 }
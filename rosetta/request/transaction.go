@@ -16,12 +16,14 @@ package request
 
 import (
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
 )
 
 // Transaction implements the request schema for /block/transaction.
 // See https://www.rosetta-api.org/docs/BlockApi.html#request-1
 type Transaction struct {
-	NetworkID     identifier.Network     `json:"network_identifier"`
-	BlockID       identifier.Block       `json:"block_identifier"`
-	TransactionID identifier.Transaction `json:"transaction_identifier"`
+	NetworkID     identifier.Network      `json:"network_identifier"`
+	BlockID       identifier.Block        `json:"block_identifier"`
+	TransactionID identifier.Transaction  `json:"transaction_identifier"`
+	Metadata      *object.OperationFilter `json:"metadata,omitempty"`
 }
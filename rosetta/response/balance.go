@@ -22,6 +22,7 @@ import (
 // Balance implements the successful response schema for /account/balance.
 // See https://www.rosetta-api.org/docs/AccountApi.html#200---ok
 type Balance struct {
-	BlockID  identifier.Block `json:"block_identifier"`
-	Balances []object.Amount  `json:"balances"`
+	BlockID  identifier.Block        `json:"block_identifier"`
+	Balances []object.Amount         `json:"balances"`
+	Metadata *object.AccountMetadata `json:"metadata,omitempty"`
 }
@@ -14,8 +14,20 @@
 
 package response
 
+import (
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
 // Combine implements the response schema for /construction/combine.
+// When the payer of the transaction is distinct from its other signers, it can
+// only sign the envelope once their payload signatures are attached. In that
+// case, the transaction is only partially signed, and the payloads contain the
+// envelope signing payload for the payer, as an extension to the specification.
+// If the payload signatures were produced from detached signing payloads, the
+// envelope signing payload is detached as well, and comes with a summary.
 // See https://www.rosetta-api.org/docs/ConstructionApi.html#response
 type Combine struct {
-	SignedTransaction string `json:"signed_transaction"`
+	SignedTransaction string                  `json:"signed_transaction"`
+	Payloads          []object.SigningPayload `json:"payloads,omitempty"`
+	Summary           *object.Summary         `json:"summary,omitempty"`
 }
@@ -21,5 +21,6 @@ import (
 // Metadata implements the response schema for /construction/metadata.
 // See https://www.rosetta-api.org/docs/ConstructionApi.html#response-3
 type Metadata struct {
-	Metadata object.Metadata `json:"metadata"`
+	Metadata     object.Metadata `json:"metadata"`
+	SuggestedFee []object.Amount `json:"suggested_fee,omitempty"`
 }
@@ -19,8 +19,10 @@ import (
 )
 
 // Payloads implements the response schema for /construction/payloads.
+// The summary is only included for signing payloads in the detached format.
 // See https://www.rosetta-api.org/docs/ConstructionApi.html#response-5
 type Payloads struct {
 	Transaction string                  `json:"unsigned_transaction"`
 	Payloads    []object.SigningPayload `json:"payloads"`
+	Summary     *object.Summary         `json:"summary,omitempty"`
 }
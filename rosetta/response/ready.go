@@ -0,0 +1,27 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package response
+
+// Ready is the response schema for /health/ready, which is an extension to the
+// Rosetta API that tells load balancers whether the index is recent enough to
+// be served.
+type Ready struct {
+	Ready         bool   `json:"ready"`
+	IndexedHeight uint64 `json:"indexed_height"`
+	SealedHeight  uint64 `json:"sealed_height"`
+	LagBlocks     uint64 `json:"lag_blocks"`
+	LagSeconds    uint64 `json:"lag_seconds"`
+	Reason        string `json:"reason,omitempty"`
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package response
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+// SearchTransactions implements the response schema for /search/transactions.
+// As the total number of matches is only known once the whole index has been
+// searched, the total count is the number of matches up to the end of the page.
+// The next cursor is an extension to the specification, which allows resuming
+// the search without walking through the previous matches again.
+// See https://www.rosetta-api.org/docs/SearchApi.html#200---ok
+type SearchTransactions struct {
+	Transactions []object.BlockTransaction `json:"transactions"`
+	TotalCount   uint64                    `json:"total_count"`
+	NextOffset   *uint64                   `json:"next_offset,omitempty"`
+	NextCursor   string                    `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,28 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package response
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+// Stream implements the schema of each event pushed on the /flow/stream extension endpoint.
+// The block and its other transactions are only included when full blocks were requested.
+type Stream struct {
+	BlockID           identifier.Block         `json:"block_identifier"`
+	Block             *object.Block            `json:"block,omitempty"`
+	OtherTransactions []identifier.Transaction `json:"other_transactions,omitempty"`
+}
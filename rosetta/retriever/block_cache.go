@@ -0,0 +1,72 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package retriever
+
+import (
+	"encoding/json"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+// blockOverhead is the estimated memory used by a cached block on top of its
+// encoded size, for its key and the bookkeeping of the cache.
+const blockOverhead = 256
+
+// cachedBlock is a converted block along with the identifiers of the
+// transactions that did not fit into it.
+type cachedBlock struct {
+	block *object.Block
+	extra []identifier.Transaction
+}
+
+// cached returns the converted block at the given height from the block cache.
+func (r *Retriever) cached(height uint64) (*object.Block, []identifier.Transaction, bool) {
+
+	if r.cfg.BlockCache == nil {
+		return nil, nil, false
+	}
+
+	value, ok := r.cfg.BlockCache.Get(height)
+	if !ok {
+		return nil, nil, false
+	}
+	cached := value.(cachedBlock)
+
+	return cached.block, cached.extra, true
+}
+
+// cache puts the converted block at the given height into the block cache. The
+// block is weighted by the size of its JSON encoding, which is what it costs to
+// keep it around to within a constant factor.
+func (r *Retriever) cache(height uint64, block *object.Block, extra []identifier.Transaction) {
+
+	if r.cfg.BlockCache == nil {
+		return
+	}
+
+	data, err := json.Marshal(cachedEntry{Block: block, Extra: extra})
+	if err != nil {
+		return
+	}
+
+	_ = r.cfg.BlockCache.Set(height, cachedBlock{block: block, extra: extra}, int64(len(data)+blockOverhead))
+}
+
+// cachedEntry is only used to estimate the size of a cached block.
+type cachedEntry struct {
+	Block *object.Block            `json:"block"`
+	Extra []identifier.Transaction `json:"extra"`
+}
@@ -17,6 +17,21 @@ package retriever
 // Config is the configuration for the Rosetta retriever component.
 type Config struct {
 	TransactionLimit uint
+	BlockLimit       uint
+	SearchLimit      uint
+	RewardLimit      uint
+	Workers          uint
+	BlockCache       Cache
+	BlockStore       Store
+	History          History
+	Tracker          Tracker
+	BalancePaths     []string
+	LockedTokens     bool
+	MachineAccounts  bool
+	StorageUsage     bool
+	Migrations       []Migration
+	OperationTypes   []string
+	Intents          Intents
 }
 
 // WithTransactionLimit sets a transaction limit in a Config.
@@ -25,3 +40,124 @@ func WithTransactionLimit(limit uint) func(*Config) {
 		c.TransactionLimit = limit
 	}
 }
+
+// WithBlockLimit sets the maximum number of blocks that can be retrieved for a block range in a Config.
+func WithBlockLimit(limit uint) func(*Config) {
+	return func(c *Config) {
+		c.BlockLimit = limit
+	}
+}
+
+// WithSearchLimit sets the maximum number of blocks that a single transaction search
+// walks through in a Config, after which the search has to be resumed by a new request.
+func WithSearchLimit(limit uint) func(*Config) {
+	return func(c *Config) {
+		c.SearchLimit = limit
+	}
+}
+
+// WithRewardLimit sets the maximum number of blocks that a single reward lookup
+// walks through in a Config, after which the lookup has to be resumed by a new request.
+func WithRewardLimit(limit uint) func(*Config) {
+	return func(c *Config) {
+		c.RewardLimit = limit
+	}
+}
+
+// WithConversionWorkers sets the maximum number of transactions of a block that
+// are converted in parallel in a Config.
+func WithConversionWorkers(workers uint) func(*Config) {
+	return func(c *Config) {
+		c.Workers = workers
+	}
+}
+
+// WithBlockCache sets the cache in which to keep converted blocks in memory in a
+// Config, so that repeated requests for recent blocks skip the conversion.
+func WithBlockCache(cache Cache) func(*Config) {
+	return func(c *Config) {
+		c.BlockCache = cache
+	}
+}
+
+// WithBlockStore sets a persistent store for converted blocks in a Config, which
+// is checked after the in-memory block cache and survives restarts.
+func WithBlockStore(store Store) func(*Config) {
+	return func(c *Config) {
+		c.BlockStore = store
+	}
+}
+
+// WithAccountHistory sets the store of account transaction histories in a Config,
+// which is required to list the transactions of an account.
+func WithAccountHistory(history History) func(*Config) {
+	return func(c *Config) {
+		c.History = history
+	}
+}
+
+// WithSoftFinality sets the tracker used to check whether blocks are sealed in
+// a Config. Blocks that are served before they are sealed on the Flow network
+// are then flagged as such in their metadata.
+func WithSoftFinality(track Tracker) func(*Config) {
+	return func(c *Config) {
+		c.Tracker = track
+	}
+}
+
+// WithBalancePaths sets additional public paths in a Config, on which accounts
+// can expose further vaults that are aggregated into their balances.
+func WithBalancePaths(paths ...string) func(*Config) {
+	return func(c *Config) {
+		c.BalancePaths = paths
+	}
+}
+
+// WithLockedTokens sets whether account balances in a Config include the balance
+// and unlock limit of the locked account that an account holds, if any.
+func WithLockedTokens(enabled bool) func(*Config) {
+	return func(c *Config) {
+		c.LockedTokens = enabled
+	}
+}
+
+// WithMachineAccounts sets whether account balances in a Config include the balances
+// of the machine accounts of the nodes that an account operates, if any.
+func WithMachineAccounts(enabled bool) func(*Config) {
+	return func(c *Config) {
+		c.MachineAccounts = enabled
+	}
+}
+
+// WithStorageUsage sets whether FLOW balances in a Config include the storage usage and
+// capacity of the account, as well as the minimum balance reserved to pay for its storage.
+func WithStorageUsage(enabled bool) func(*Config) {
+	return func(c *Config) {
+		c.StorageUsage = enabled
+	}
+}
+
+// WithOperationTypes sets the types of the operations to include in transactions
+// in a Config. Operations of other types are moved into the transaction metadata
+// instead, and all types are included when none are given.
+func WithOperationTypes(types ...string) func(*Config) {
+	return func(c *Config) {
+		c.OperationTypes = types
+	}
+}
+
+// WithMigrations sets the script generators to use for historical heights in a
+// Config, for when core contracts lived at different addresses or under different names.
+func WithMigrations(migrations ...Migration) func(*Config) {
+	return func(c *Config) {
+		c.Migrations = migrations
+	}
+}
+
+// WithIntents sets the registry of known transaction scripts in a Config, which
+// is used to derive the operations of failed transactions from their arguments.
+func WithIntents(intents Intents) func(*Config) {
+	return func(c *Config) {
+		c.Intents = intents
+	}
+}
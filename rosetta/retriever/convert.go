@@ -15,9 +15,16 @@
 package retriever
 
 import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go/model/flow"
 
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
 )
 
 func rosettaTxID(txID flow.Identifier) identifier.Transaction {
@@ -26,6 +33,54 @@ func rosettaTxID(txID flow.Identifier) identifier.Transaction {
 	}
 }
 
+// rosettaTxMetadata returns the metadata of a transaction with the given result
+// and body. The execution error is only set if the transaction failed. FVM error
+// messages start with their error code, which is extracted so that it can be used
+// without parsing.
+func rosettaTxMetadata(result *flow.TransactionResult, body *flow.TransactionBody) *object.TransactionMetadata {
+
+	metadata := object.TransactionMetadata{
+		ComputationUsed: result.ComputationUsed,
+		GasLimit:        body.GasLimit,
+	}
+
+	if result.ErrorMessage != "" {
+		var code uint
+		_, _ = fmt.Sscanf(result.ErrorMessage, "[Error Code: %d]", &code)
+		metadata.Error = &object.ExecutionError{
+			Code:    code,
+			Message: result.ErrorMessage,
+		}
+	}
+
+	return &metadata
+}
+
+func rosettaCollection(guarantee *flow.CollectionGuarantee) object.CollectionMetadata {
+
+	guarantors := make([]string, 0, len(guarantee.SignerIDs))
+	for _, signerID := range guarantee.SignerIDs {
+		guarantors = append(guarantors, signerID.String())
+	}
+
+	collection := object.CollectionMetadata{
+		ID:             guarantee.CollectionID.String(),
+		ReferenceBlock: guarantee.ReferenceBlockID.String(),
+		Guarantors:     guarantors,
+	}
+
+	return collection
+}
+
+func rosettaSeal(sealID flow.Identifier, seal *flow.Seal) object.SealMetadata {
+	return object.SealMetadata{
+		ID:         sealID.String(),
+		BlockID:    seal.BlockID.String(),
+		ResultID:   seal.ResultID.String(),
+		FinalState: hex.EncodeToString(seal.FinalState[:]),
+	}
+}
+
 func rosettaBlockID(height uint64, blockID flow.Identifier) identifier.Block {
 	return identifier.Block{
 		Index: &height,
@@ -33,9 +88,241 @@ func rosettaBlockID(height uint64, blockID flow.Identifier) identifier.Block {
 	}
 }
 
-func rosettaCurrency(symbol string, decimals uint) identifier.Currency {
-	return identifier.Currency{
+func rosettaCurrency(symbol string, decimals uint, tokens map[string]dps.Token) identifier.Currency {
+	currency := identifier.Currency{
 		Symbol:   symbol,
 		Decimals: decimals,
 	}
+	token, ok := tokens[symbol]
+	if !ok || symbol == dps.FlowSymbol {
+		return currency
+	}
+	currency.Metadata = &identifier.CurrencyMetadata{
+		ContractAddress: token.Address.Hex(),
+		ContractName:    token.Type,
+		VaultType:       fmt.Sprintf("A.%s.%s.Vault", token.Address.Hex(), token.Type),
+	}
+	return currency
+}
+
+func rosettaKey(key flow.AccountPublicKey) object.AccountKey {
+	return object.AccountKey{
+		Index:              key.Index,
+		PublicKey:          key.PublicKey.String(),
+		SignatureAlgorithm: key.SignAlgo.String(),
+		HashAlgorithm:      key.HashAlgo.String(),
+		Weight:             key.Weight,
+		Revoked:            key.Revoked,
+		SequenceNumber:     key.SeqNumber,
+	}
+}
+
+// rosettaNode converts the `FlowIDTableStaking.NodeInfo` structure returned by
+// the staking table contract into a Rosetta node.
+func rosettaNode(value cadence.Value) (*object.Node, error) {
+
+	info, ok := value.(cadence.Struct)
+	if !ok || info.StructType == nil {
+		return nil, fmt.Errorf("unexpected node info type (%T)", value)
+	}
+	if len(info.StructType.Fields) != len(info.Fields) {
+		return nil, fmt.Errorf("mismatching node info fields (type: %d, value: %d)", len(info.StructType.Fields), len(info.Fields))
+	}
+	fields := make(map[string]interface{}, len(info.Fields))
+	for i, field := range info.StructType.Fields {
+		fields[field.Identifier] = info.Fields[i].ToGoValue()
+	}
+
+	var node object.Node
+	node.ID, ok = fields["id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid node info ID (%T)", fields["id"])
+	}
+	node.NetworkingAddress, ok = fields["networkingAddress"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid node info networking address (%T)", fields["networkingAddress"])
+	}
+	node.InitialWeight, ok = fields["initialWeight"].(uint64)
+	if !ok {
+		return nil, fmt.Errorf("invalid node info initial weight (%T)", fields["initialWeight"])
+	}
+
+	role, ok := fields["role"].(uint8)
+	if !ok {
+		return nil, fmt.Errorf("invalid node info role (%T)", fields["role"])
+	}
+	if !flow.Role(role).Valid() {
+		return nil, fmt.Errorf("unknown node info role (%d)", role)
+	}
+	node.Role = flow.Role(role).String()
+
+	delegators, ok := fields["delegators"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid node info delegators (%T)", fields["delegators"])
+	}
+	node.DelegatorCount = uint(len(delegators))
+
+	buckets := []struct {
+		field  string
+		amount *object.Amount
+	}{
+		{field: "tokensStaked", amount: &node.TokensStaked},
+		{field: "tokensCommitted", amount: &node.TokensCommitted},
+		{field: "tokensUnstaking", amount: &node.TokensUnstaking},
+		{field: "tokensUnstaked", amount: &node.TokensUnstaked},
+		{field: "tokensRewarded", amount: &node.TokensRewarded},
+		{field: "tokensRequestedToUnstake", amount: &node.TokensRequestedToUnstake},
+	}
+	for _, bucket := range buckets {
+		tokens, ok := fields[bucket.field].(uint64)
+		if !ok {
+			return nil, fmt.Errorf("invalid node info token bucket (field: %s, type: %T)", bucket.field, fields[bucket.field])
+		}
+		*bucket.amount = object.Amount{
+			Value:    amount.Format(tokens),
+			Currency: rosettaCurrency(dps.FlowSymbol, dps.FlowDecimals, nil),
+		}
+	}
+
+	return &node, nil
+}
+
+func rosettaLocked(value cadence.Value) (*object.LockedBalance, error) {
+
+	// The script returns an optional, which is nil for accounts without a
+	// locked account.
+	optional, ok := value.(cadence.Optional)
+	if !ok {
+		return nil, fmt.Errorf("unexpected locked account type (%T)", value)
+	}
+	if optional.Value == nil {
+		return nil, nil
+	}
+
+	info, ok := optional.Value.(cadence.Struct)
+	if !ok || info.StructType == nil {
+		return nil, fmt.Errorf("unexpected locked account info type (%T)", optional.Value)
+	}
+	if len(info.StructType.Fields) != len(info.Fields) {
+		return nil, fmt.Errorf("mismatching locked account fields (type: %d, value: %d)", len(info.StructType.Fields), len(info.Fields))
+	}
+	fields := make(map[string]cadence.Value, len(info.Fields))
+	for i, field := range info.StructType.Fields {
+		fields[field.Identifier] = info.Fields[i]
+	}
+
+	address, ok := fields["address"].(cadence.Address)
+	if !ok {
+		return nil, fmt.Errorf("invalid locked account address (%T)", fields["address"])
+	}
+	balance, ok := fields["balance"].(cadence.UFix64)
+	if !ok {
+		return nil, fmt.Errorf("invalid locked account balance (%T)", fields["balance"])
+	}
+	limit, ok := fields["unlockLimit"].(cadence.UFix64)
+	if !ok {
+		return nil, fmt.Errorf("invalid locked account unlock limit (%T)", fields["unlockLimit"])
+	}
+
+	locked := object.LockedBalance{
+		Account: identifier.Account{
+			Address: flow.Address(address).String(),
+		},
+		Value:       amount.FromUFix64(balance),
+		UnlockLimit: amount.FromUFix64(limit),
+	}
+
+	return &locked, nil
+}
+
+func rosettaMachines(value cadence.Value) ([]object.MachineBalance, error) {
+
+	infos, ok := value.(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("unexpected machine accounts type (%T)", value)
+	}
+
+	machines := make([]object.MachineBalance, 0, len(infos.Values))
+	for _, value := range infos.Values {
+
+		info, ok := value.(cadence.Struct)
+		if !ok || info.StructType == nil {
+			return nil, fmt.Errorf("unexpected machine account info type (%T)", value)
+		}
+		if len(info.StructType.Fields) != len(info.Fields) {
+			return nil, fmt.Errorf("mismatching machine account fields (type: %d, value: %d)", len(info.StructType.Fields), len(info.Fields))
+		}
+		fields := make(map[string]cadence.Value, len(info.Fields))
+		for i, field := range info.StructType.Fields {
+			fields[field.Identifier] = info.Fields[i]
+		}
+
+		nodeID, ok := fields["nodeID"].(cadence.String)
+		if !ok {
+			return nil, fmt.Errorf("invalid machine account node ID (%T)", fields["nodeID"])
+		}
+		role, ok := fields["role"].(cadence.UInt8)
+		if !ok {
+			return nil, fmt.Errorf("invalid machine account role (%T)", fields["role"])
+		}
+		if !flow.Role(role).Valid() {
+			return nil, fmt.Errorf("unknown machine account role (%d)", role)
+		}
+		address, ok := fields["address"].(cadence.Address)
+		if !ok {
+			return nil, fmt.Errorf("invalid machine account address (%T)", fields["address"])
+		}
+		balance, ok := fields["balance"].(cadence.UFix64)
+		if !ok {
+			return nil, fmt.Errorf("invalid machine account balance (%T)", fields["balance"])
+		}
+
+		machine := object.MachineBalance{
+			Account: identifier.Account{
+				Address: flow.Address(address).String(),
+			},
+			NodeID: string(nodeID),
+			Role:   flow.Role(role).String(),
+			Value:  amount.FromUFix64(balance),
+		}
+		machines = append(machines, machine)
+	}
+
+	return machines, nil
+}
+
+func rosettaStorage(value cadence.Value) (*object.StorageUsage, error) {
+
+	info, ok := value.(cadence.Struct)
+	if !ok || info.StructType == nil {
+		return nil, fmt.Errorf("unexpected storage info type (%T)", value)
+	}
+	if len(info.StructType.Fields) != len(info.Fields) {
+		return nil, fmt.Errorf("mismatching storage info fields (type: %d, value: %d)", len(info.StructType.Fields), len(info.Fields))
+	}
+	fields := make(map[string]cadence.Value, len(info.Fields))
+	for i, field := range info.StructType.Fields {
+		fields[field.Identifier] = info.Fields[i]
+	}
+
+	used, ok := fields["used"].(cadence.UInt64)
+	if !ok {
+		return nil, fmt.Errorf("invalid storage info used storage (%T)", fields["used"])
+	}
+	capacity, ok := fields["capacity"].(cadence.UInt64)
+	if !ok {
+		return nil, fmt.Errorf("invalid storage info storage capacity (%T)", fields["capacity"])
+	}
+	reserved, ok := fields["reserved"].(cadence.UFix64)
+	if !ok {
+		return nil, fmt.Errorf("invalid storage info reservation (%T)", fields["reserved"])
+	}
+
+	storage := object.StorageUsage{
+		Used:        uint64(used),
+		Capacity:    uint64(capacity),
+		Reservation: amount.FromUFix64(reserved),
+	}
+
+	return &storage, nil
 }
@@ -20,7 +20,13 @@ import (
 	"github.com/optakt/flow-rosetta/rosetta/object"
 )
 
-// Converter represents something that can convert Flow events into Rosetta operations.
+// Converter represents something that can convert Flow events into Rosetta operations
+// and staking rewards.
 type Converter interface {
 	EventToOperation(event flow.Event) (operation *object.Operation, err error)
+	EventToReward(event flow.Event) (reward *object.Reward, err error)
+	EventToFees(event flow.Event) (fees *object.TransactionFees, err error)
+	EventToServiceEvent(event flow.Event) (service *object.ServiceEvent, err error)
+	EventToAddress(event flow.Event) (address flow.Address, err error)
+	EventToContract(event flow.Event) (contract *object.ContractChange, err error)
 }
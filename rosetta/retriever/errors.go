@@ -15,6 +15,27 @@ const (
 	// This can happen if the account does not exist at the given height.
 	missingVault = "Could not borrow Balance reference to the Vault"
 
+	// FVM error returned when the account does not exist at the given height.
+	missingAccount = "account not found for address"
+
+	// Cadence error returned when the node ID is not part of the staking table.
+	missingNode = "Specified node ID does not exist in the record"
+
 	// Error description for failure to find a transaction.
 	txMissing = "transaction not found in given block"
+
+	// Error description for failure to find a node.
+	nodeMissing = "node not found in staking table at given block"
+
+	// Error description for an inverted block range.
+	rangeInverted = "start block index is above end block index"
+
+	// Error description for a block range that has more blocks than allowed.
+	rangeExceeded = "block range exceeds block limit"
+
+	// Error descriptions for block ranges that are not covered by the account
+	// transaction history.
+	historyEmpty   = "account history does not contain any block yet"
+	historyTooLow  = "start block index is below first block index of account history"
+	historyTooHigh = "end block index is above last block index of account history"
 )
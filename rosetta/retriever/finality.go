@@ -0,0 +1,49 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package retriever
+
+import (
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+// flag returns the given block with metadata flagging it as unsealed, if soft
+// finality is enabled and the block is not sealed yet. Cached and stored blocks
+// are never modified, as the flag goes away once the block is sealed.
+func (r *Retriever) flag(block *object.Block) *object.Block {
+
+	if r.cfg.Tracker == nil {
+		return block
+	}
+
+	// If the latest sealed height is unknown, we can not confirm that the block
+	// is sealed, so we flag it.
+	sealed, err := r.cfg.Tracker.Sealed()
+	if err == nil && *block.ID.Index <= sealed {
+		return block
+	}
+
+	// The metadata is copied, so that the block shared with the cache and the
+	// block store is left untouched.
+	var metadata object.BlockMetadata
+	if block.Metadata != nil {
+		metadata = *block.Metadata
+	}
+	metadata.Sealed = false
+
+	flagged := *block
+	flagged.Metadata = &metadata
+
+	return &flagged
+}
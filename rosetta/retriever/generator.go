@@ -14,10 +14,30 @@
 
 package retriever
 
+import (
+	"github.com/optakt/flow-rosetta/rosetta/intents"
+)
+
 // Generator represents something that can generate scripts for retrieving
-// balances as well as the amounts deposited and withdrawn for a given token.
+// balances as well as the amounts deposited and withdrawn for a given token,
+// the staking records and rewards of node operators, locked token and machine account balances, and storage usage.
+// It also generates the known transaction scripts, so that failed transactions
+// can be recognized without a separately configured registry.
 type Generator interface {
+	intents.Generator
 	GetBalance(symbol string) ([]byte, error)
+	GetVaultBalances(symbol string, paths []string) ([]byte, error)
+	GetNodeInfo() ([]byte, error)
+	GetLockedAccount() ([]byte, error)
+	GetMachineAccounts() ([]byte, error)
+	GetStorageInfo() ([]byte, error)
 	TokensDeposited(symbol string) (string, error)
 	TokensWithdrawn(symbol string) (string, error)
+	TokensMinted(symbol string) (string, error)
+	TokensBurned(symbol string) (string, error)
+	RewardsPaid() (string, error)
+	DelegatorRewardsPaid() (string, error)
+	FeesDeducted() (string, error)
+	EpochSetup() (string, error)
+	EpochCommit() (string, error)
 }
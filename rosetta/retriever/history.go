@@ -0,0 +1,30 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package retriever
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/history"
+)
+
+// History represents something that can list the transactions that affected
+// an account over the range of heights that it covers, and tell the latest
+// height at which an account was active.
+type History interface {
+	Range() (uint64, uint64, bool)
+	Transactions(address flow.Address, start uint64, index uint32, end uint64, limit uint) ([]history.Reference, error)
+	Latest(address flow.Address, height uint64) (uint64, bool, error)
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package retriever
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+// intended returns the operations that the given transaction body intended to
+// execute, based on its script and arguments rather than on its events. It is
+// used for failed transactions, which do not emit any token events, so that
+// their attempted transfers and staking actions are still visible with a failed
+// status. Operations of types that are not allowlisted are returned separately,
+// without index or relations, like those converted from events. The indices of
+// the others start at the given index, so that they follow the event operations.
+// Transactions that do not use a known script, whose token is not served, or
+// whose arguments cannot be decoded, have no intended operations.
+func (r *Retriever) intended(body *flow.TransactionBody, index uint) ([]*object.Operation, []*object.Operation) {
+
+	if r.cfg.Intents == nil || len(body.Authorizers) != 1 {
+		return nil, nil
+	}
+	intent, ok := r.cfg.Intents.Match(body.Script)
+	if !ok {
+		return nil, nil
+	}
+
+	// Operations can only use the currencies that the Data API serves, as the
+	// balances of their accounts could not be looked up otherwise.
+	_, served := r.params.Tokens[intent.Symbol]
+	if !served {
+		return nil, nil
+	}
+
+	// The arguments come from whoever submitted the transaction, so we can't
+	// treat arguments that don't decode as an error of our own.
+	ops, err := intent.Operations(body.Authorizers[0], body.Arguments)
+	if err != nil {
+		return nil, nil
+	}
+
+	// The operations are filtered before they get their indices, so that the
+	// indices leave no gaps, and each kept operation is mapped from its index
+	// within the intent to its index within the transaction.
+	indices := make(map[uint]uint, len(ops))
+	intended := make([]*object.Operation, 0, len(ops))
+	var omitted []*object.Operation
+	for i := range ops {
+		op := ops[i]
+		op.Status = configuration.StatusFailed.Status
+		if !r.allowed(op.Type) {
+			op.ID = identifier.Operation{}
+			op.RelatedIDs = nil
+			omitted = append(omitted, &op)
+			continue
+		}
+		indices[op.ID.Index] = index + uint(len(intended))
+		op.ID.Index = indices[op.ID.Index]
+		intended = append(intended, &op)
+	}
+
+	// Relations to omitted operations are dropped, as they have no index to
+	// refer to.
+	for _, op := range intended {
+		var related []identifier.Operation
+		for _, relatedID := range op.RelatedIDs {
+			mapped, ok := indices[relatedID.Index]
+			if !ok {
+				continue
+			}
+			related = append(related, identifier.Operation{Index: mapped})
+		}
+		op.RelatedIDs = related
+	}
+
+	return intended, omitted
+}
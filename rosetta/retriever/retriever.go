@@ -18,16 +18,22 @@ import (
 	"errors"
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/fxamacker/cbor/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
 	"github.com/optakt/flow-rosetta/rosetta/failure"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/intents"
 	"github.com/optakt/flow-rosetta/rosetta/object"
 )
 
@@ -49,12 +55,46 @@ func New(params dps.Params, index dps.Reader, validate Validator, generator Gene
 
 	cfg := Config{
 		TransactionLimit: 200,
+		BlockLimit:       100,
+		SearchLimit:      1000,
+		RewardLimit:      100_000,
+		Workers:          1,
 	}
 
 	for _, opt := range options {
 		opt(&cfg)
 	}
 
+	// At least one worker is needed to convert any transactions at all.
+	if cfg.Workers == 0 {
+		cfg.Workers = 1
+	}
+
+	// Migrations are checked in order of height, so that each height uses the
+	// earliest migration that it comes before.
+	migrations := make([]Migration, len(cfg.Migrations))
+	copy(migrations, cfg.Migrations)
+	sort.Slice(migrations, func(i int, j int) bool {
+		return migrations[i].Height < migrations[j].Height
+	})
+	cfg.Migrations = migrations
+
+	// Without a configured registry, the known transaction scripts of the
+	// generators are recognized, so that failed FLOW transfers keep their
+	// intended operations. If the scripts can't be generated, failed
+	// transactions simply have none.
+	if cfg.Intents == nil {
+		generators := make([]intents.Generator, 0, len(migrations)+1)
+		generators = append(generators, generator)
+		for _, migration := range migrations {
+			generators = append(generators, migration.Generate)
+		}
+		registry, err := intents.New(generators)
+		if err == nil {
+			cfg.Intents = registry
+		}
+	}
+
 	r := Retriever{
 		cfg:      cfg,
 		params:   params,
@@ -110,6 +150,66 @@ func (r *Retriever) Current() (identifier.Block, time.Time, error) {
 	return block, header.Timestamp, nil
 }
 
+// pin resolves the latest block once for all of the given block identifiers
+// that reference it, by filling in their index. This makes sure that all
+// lookups of a request happen at the same height, even if a new block is
+// indexed while it is being processed. The latest block is resolved by the
+// validator, so that it is the latest sealed one when only sealed blocks are
+// served.
+func (r *Retriever) pin(rosBlockIDs ...*identifier.Block) error {
+
+	var last *uint64
+	for _, rosBlockID := range rosBlockIDs {
+		if rosBlockID.Index != nil || rosBlockID.Hash != "" {
+			continue
+		}
+		if last == nil {
+			height, _, err := r.validate.Block(identifier.Block{})
+			if err != nil {
+				return fmt.Errorf("could not resolve latest block: %w", err)
+			}
+			last = &height
+		}
+		rosBlockID.Index = last
+	}
+
+	return nil
+}
+
+// BlockID completes the given Rosetta block identifier, so that it contains both the height and the
+// hash of the block, without retrieving the block's transactions.
+func (r *Retriever) BlockID(rosBlockID identifier.Block) (identifier.Block, error) {
+
+	height, blockID, err := r.validate.Block(rosBlockID)
+	if err != nil {
+		return identifier.Block{}, fmt.Errorf("could not validate block: %w", err)
+	}
+
+	return rosettaBlockID(height, blockID), nil
+}
+
+// Child retrieves the complete identifiers of the given block and of its child,
+// which is the block at the next height, so that clients can walk the chain
+// forward.
+func (r *Retriever) Child(rosBlockID identifier.Block) (identifier.Block, identifier.Block, error) {
+
+	height, blockID, err := r.validate.Block(rosBlockID)
+	if err != nil {
+		return identifier.Block{}, identifier.Block{}, fmt.Errorf("could not validate block: %w", err)
+	}
+
+	// The child is validated like any other block, so that a child that is not
+	// indexed yet, or not sealed yet when only sealed blocks are served, is
+	// reported the same way as when it is requested directly.
+	child := height + 1
+	_, childID, err := r.validate.Block(identifier.Block{Index: &child})
+	if err != nil {
+		return identifier.Block{}, identifier.Block{}, fmt.Errorf("could not validate child block: %w", err)
+	}
+
+	return rosettaBlockID(height, blockID), rosettaBlockID(child, childID), nil
+}
+
 // Balances retrieves the balances for the given currencies of the given account ID at the given block.
 func (r *Retriever) Balances(rosBlockID identifier.Block, rosAccountID identifier.Account, rosCurrencies []identifier.Currency) (identifier.Block, []object.Amount, error) {
 
@@ -144,43 +244,224 @@ func (r *Retriever) Balances(rosBlockID identifier.Block, rosAccountID identifie
 	amounts := make([]object.Amount, 0, len(symbols))
 	for _, symbol := range symbols {
 
-		// We generate the script to get the vault balance and execute it.
-		script, err := r.generate.GetBalance(symbol)
-		if err != nil {
-			return identifier.Block{}, nil, fmt.Errorf("could not generate script: %w", err)
+		entry := object.Amount{
+			Currency: rosettaCurrency(symbol, decimals[symbol], r.params.Tokens),
+		}
+		var metadata object.AmountMetadata
+
+		// When additional balance paths are configured, the balance is the sum
+		// of all vaults exposed on them, and we attach a breakdown per vault.
+		if len(r.cfg.BalancePaths) > 0 {
+			balance, vaults, err := r.vaults(height, address, symbol)
+			if err != nil {
+				return identifier.Block{}, nil, fmt.Errorf("could not get vault balances: %w", err)
+			}
+
+			entry.Value = balance
+			metadata.Vaults = vaults
+		} else {
+			balance, err := r.balance(height, address, symbol)
+			if err != nil {
+				return identifier.Block{}, nil, err
+			}
+
+			entry.Value = amount.Format(balance)
 		}
-		params := []cadence.Value{cadence.NewAddress(address)}
-		result, err := r.invoke.Script(height, script, params)
-		if err != nil && !strings.Contains(err.Error(), missingVault) {
-			return identifier.Block{}, nil, fmt.Errorf("could not invoke script: %w", err)
+
+		// Tokens held in a locked account are not part of the account's own
+		// balance, so we report them separately in the metadata.
+		if r.cfg.LockedTokens && symbol == dps.FlowSymbol {
+			metadata.Locked, err = r.locked(height, address)
+			if err != nil {
+				return identifier.Block{}, nil, fmt.Errorf("could not get locked balance: %w", err)
+			}
 		}
 
-		// In the previous error check, we exclude errors that are about getting
-		// the vault reference in Cadence. In those cases, we keep the default
-		// balance here, which is zero.
-		balance := uint64(0)
-		if err == nil {
-			var ok bool
-			balance, ok = result.ToGoValue().(uint64)
-			if !ok {
-				return identifier.Block{}, nil, fmt.Errorf("unexpected script result type (got: %s, want uint64)", result.String())
+		// The same goes for the machine accounts of the nodes that the account
+		// operates through its staking collection.
+		if r.cfg.MachineAccounts && symbol == dps.FlowSymbol {
+			metadata.Machines, err = r.machines(height, address)
+			if err != nil {
+				return identifier.Block{}, nil, fmt.Errorf("could not get machine account balances: %w", err)
+			}
+		}
+
+		// Part of the FLOW balance is reserved to pay for the storage of the
+		// account, so we include its storage usage to show what can be spent.
+		if r.cfg.StorageUsage && symbol == dps.FlowSymbol {
+			metadata.Storage, err = r.storage(height, address)
+			if err != nil {
+				return identifier.Block{}, nil, fmt.Errorf("could not get storage usage: %w", err)
 			}
 		}
 
-		amount := object.Amount{
-			Currency: rosettaCurrency(symbol, decimals[symbol]),
-			Value:    strconv.FormatUint(balance, 10),
+		if metadata.Vaults != nil || metadata.Locked != nil || len(metadata.Machines) > 0 || metadata.Storage != nil {
+			entry.Metadata = &metadata
 		}
 
-		amounts = append(amounts, amount)
+		amounts = append(amounts, entry)
 	}
 
 	return rosettaBlockID(height, blockID), amounts, nil
 }
 
+// balance retrieves the balance of the given token in the default vault of the account.
+func (r *Retriever) balance(height uint64, address flow.Address, symbol string) (uint64, error) {
+
+	// We generate the script to get the vault balance and execute it.
+	script, err := r.generator(height).GetBalance(symbol)
+	if err != nil {
+		return 0, fmt.Errorf("could not generate script: %w", err)
+	}
+	params := []cadence.Value{cadence.NewAddress(address)}
+	result, err := r.invoke.Script(height, script, params)
+	if err != nil && !strings.Contains(err.Error(), missingVault) {
+		return 0, fmt.Errorf("could not invoke script: %w", err)
+	}
+
+	// In the previous error check, we exclude errors that are about getting
+	// the vault reference in Cadence. In those cases, we keep the default
+	// balance here, which is zero.
+	balance := uint64(0)
+	if err == nil {
+		var ok bool
+		balance, ok = result.ToGoValue().(uint64)
+		if !ok {
+			return 0, fmt.Errorf("unexpected script result type (got: %s, want uint64)", result.String())
+		}
+	}
+
+	return balance, nil
+}
+
+// locked retrieves the balance and unlock limit of the locked account that the
+// account holds through the LockedTokens contract. It returns nil if the account
+// does not hold a locked account.
+func (r *Retriever) locked(height uint64, address flow.Address) (*object.LockedBalance, error) {
+
+	script, err := r.generator(height).GetLockedAccount()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate script: %w", err)
+	}
+	params := []cadence.Value{cadence.NewAddress(address)}
+	result, err := r.invoke.Script(height, script, params)
+	if err != nil {
+		return nil, fmt.Errorf("could not invoke script: %w", err)
+	}
+
+	return rosettaLocked(result)
+}
+
+// machines retrieves the balances of the machine accounts of all nodes that the account
+// operates through its staking collection, sorted by node ID.
+func (r *Retriever) machines(height uint64, address flow.Address) ([]object.MachineBalance, error) {
+
+	script, err := r.generator(height).GetMachineAccounts()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate script: %w", err)
+	}
+	params := []cadence.Value{cadence.NewAddress(address)}
+	result, err := r.invoke.Script(height, script, params)
+	if err != nil {
+		return nil, fmt.Errorf("could not invoke script: %w", err)
+	}
+
+	machines, err := rosettaMachines(result)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert machine accounts: %w", err)
+	}
+
+	sort.Slice(machines, func(i int, j int) bool {
+		return machines[i].NodeID < machines[j].NodeID
+	})
+
+	return machines, nil
+}
+
+// storage retrieves the storage usage and capacity of the account, as well as the
+// minimum FLOW balance that it has to keep to pay for its storage.
+func (r *Retriever) storage(height uint64, address flow.Address) (*object.StorageUsage, error) {
+
+	script, err := r.generator(height).GetStorageInfo()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate script: %w", err)
+	}
+	params := []cadence.Value{cadence.NewAddress(address)}
+	result, err := r.invoke.Script(height, script, params)
+	if err != nil {
+		return nil, fmt.Errorf("could not invoke script: %w", err)
+	}
+
+	return rosettaStorage(result)
+}
+
+// vaults retrieves the balances of all vaults of the given token that the account exposes on the
+// default balance path or one of the configured balance paths, as well as their sum.
+func (r *Retriever) vaults(height uint64, address flow.Address, symbol string) (string, []object.VaultBalance, error) {
+
+	script, err := r.generator(height).GetVaultBalances(symbol, r.cfg.BalancePaths)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not generate script: %w", err)
+	}
+	params := []cadence.Value{cadence.NewAddress(address)}
+	result, err := r.invoke.Script(height, script, params)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not invoke script: %w", err)
+	}
+	dict, ok := result.(cadence.Dictionary)
+	if !ok {
+		return "", nil, fmt.Errorf("unexpected script result type (got: %s, want dictionary)", result.String())
+	}
+
+	values := make([]string, 0, len(dict.Pairs))
+	vaults := make([]object.VaultBalance, 0, len(dict.Pairs))
+	for _, pair := range dict.Pairs {
+		path, ok := pair.Key.ToGoValue().(string)
+		if !ok {
+			return "", nil, fmt.Errorf("unexpected vault path type (got: %s, want string)", pair.Key.String())
+		}
+		balance, ok := pair.Value.ToGoValue().(uint64)
+		if !ok {
+			return "", nil, fmt.Errorf("unexpected vault balance type (got: %s, want uint64)", pair.Value.String())
+		}
+
+		vault := object.VaultBalance{
+			Path:  path,
+			Value: amount.Format(balance),
+		}
+		values = append(values, vault.Value)
+		vaults = append(vaults, vault)
+	}
+
+	// The sum of several vaults can exceed the range of a single vault balance,
+	// so it is computed with arbitrary precision.
+	total, err := amount.Sum(values...)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not sum vault balances: %w", err)
+	}
+
+	// Dictionaries have no defined order in Cadence, so we sort the vaults
+	// by path to make the breakdown deterministic.
+	sort.Slice(vaults, func(i int, j int) bool {
+		return vaults[i].Path < vaults[j].Path
+	})
+
+	return total, vaults, nil
+}
+
 // Block retrieves a block and its transactions given its identifier.
 func (r *Retriever) Block(rosBlockID identifier.Block) (*object.Block, []identifier.Transaction, error) {
 
+	block, extra, err := r.block(rosBlockID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r.flag(block), extra, nil
+}
+
+func (r *Retriever) block(rosBlockID identifier.Block) (*object.Block, []identifier.Transaction, error) {
+
 	// Run validation on the Rosetta block identifier. If it is valid, this will
 	// return the associated Flow block height and block ID.
 	height, blockID, err := r.validate.Block(rosBlockID)
@@ -188,14 +469,27 @@ func (r *Retriever) Block(rosBlockID identifier.Block) (*object.Block, []identif
 		return nil, nil, fmt.Errorf("could not validate block: %w", err)
 	}
 
-	// Retrieve the Flow token default withdrawal and deposit events.
-	deposit, err := r.generate.TokensDeposited(dps.FlowSymbol)
-	if err != nil {
-		return nil, nil, fmt.Errorf("could not generate deposit event type: %w", err)
+	// Blocks never change once indexed, so we can serve recently converted
+	// blocks from memory.
+	cached, extra, ok := r.cached(height)
+	if ok {
+		return cached, extra, nil
 	}
-	withdrawal, err := r.generate.TokensWithdrawn(dps.FlowSymbol)
+
+	// Blocks that were converted before a restart can be read back from the
+	// block store, which is much cheaper than converting them again.
+	if r.cfg.BlockStore != nil {
+		stored, extra, ok := r.cfg.BlockStore.Block(height)
+		if ok {
+			r.cache(height, stored, extra)
+			return stored, extra, nil
+		}
+	}
+
+	// Get all the events for the block to extract deposits, withdrawals and fees.
+	events, err := r.events(height)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not generate withdrawal event type: %w", err)
+		return nil, nil, fmt.Errorf("could not get block events: %w", err)
 	}
 
 	// Then, get the header; it contains the block ID, parent ID and timestamp.
@@ -204,12 +498,6 @@ func (r *Retriever) Block(rosBlockID identifier.Block) (*object.Block, []identif
 		return nil, nil, fmt.Errorf("could not get header: %w", err)
 	}
 
-	// Next, we get all the events for the block to extract deposit and withdrawal events.
-	events, err := r.index.Events(height, flow.EventType(deposit), flow.EventType(withdrawal))
-	if err != nil {
-		return nil, nil, fmt.Errorf("could not get events: %w", err)
-	}
-
 	// Get all transaction IDs for this height.
 	txIDs, err := r.index.TransactionsByHeight(height)
 	if err != nil {
@@ -218,22 +506,16 @@ func (r *Retriever) Block(rosBlockID identifier.Block) (*object.Block, []identif
 
 	// Go over all the transaction IDs and create the related Rosetta transaction
 	// until we hit the limit, at which point we just add the identifier.
-	var blockTransactions []*object.Transaction
 	var extraTransactions []identifier.Transaction
-	for index, txID := range txIDs {
-		if index >= int(r.cfg.TransactionLimit) {
+	if uint(len(txIDs)) > r.cfg.TransactionLimit {
+		for _, txID := range txIDs[r.cfg.TransactionLimit:] {
 			extraTransactions = append(extraTransactions, rosettaTxID(txID))
-			continue
 		}
-		ops, err := r.operations(txID, events)
-		if err != nil {
-			return nil, nil, fmt.Errorf("could not get operations: %w", err)
-		}
-		rosTx := object.Transaction{
-			ID:         rosettaTxID(txID),
-			Operations: ops,
-		}
-		blockTransactions = append(blockTransactions, &rosTx)
+		txIDs = txIDs[:r.cfg.TransactionLimit]
+	}
+	blockTransactions, err := r.transactions(height, txIDs, events)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get operations: %w", err)
 	}
 
 	// Rosetta spec notes that for genesis block, it is recommended to use the
@@ -252,174 +534,1327 @@ func (r *Retriever) Block(rosBlockID identifier.Block) (*object.Block, []identif
 		parent = rosettaBlockID(height-1, header.ParentID)
 	}
 
-	// Now we just need to build the block.
+	// The collection guarantees allow consumers to correlate the block with the
+	// collection-level data of the Flow network.
+	collections, err := r.collections(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get collections: %w", err)
+	}
+
+	// The seals allow consumers to verify the execution state of the blocks
+	// that were sealed by this block.
+	seals, err := r.seals(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get seals: %w", err)
+	}
+
+	// The service events let operators anticipate epoch transitions.
+	services, err := r.services(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get service events: %w", err)
+	}
+
+	// Now we just need to build the block. Every collection is executed in its
+	// own chunk, followed by the system chunk.
 	block := object.Block{
 		ID:           rosettaBlockID(height, blockID),
 		ParentID:     parent,
 		Timestamp:    header.Timestamp.UnixNano() / 1_000_000,
 		Transactions: blockTransactions,
+		Metadata: &object.BlockMetadata{
+			Sealed:        true,
+			Collections:   collections,
+			Chunks:        uint(len(collections)) + 1,
+			Seals:         seals,
+			ServiceEvents: services,
+		},
+	}
+
+	r.cache(height, &block, extraTransactions)
+	if r.cfg.BlockStore != nil {
+		r.cfg.BlockStore.Save(height, &block, extraTransactions)
 	}
 
 	return &block, extraTransactions, nil
 }
 
-// Transaction retrieves a transaction given its identifier and the identifier of the block it is a part of.
-func (r *Retriever) Transaction(rosBlockID identifier.Block, rosTxID identifier.Transaction) (*object.Transaction, error) {
-
-	// Run validation on the Rosetta block identifier. If it is valid, this will
-	// return the associated Flow block height and block ID.
-	height, blockID, err := r.validate.Block(rosBlockID)
-	if err != nil {
-		return nil, fmt.Errorf("could not validate block: %w", err)
-	}
+// collections returns the guarantees of the collections included in the block
+// at the given height, in the order in which they are included.
+func (r *Retriever) collections(height uint64) ([]object.CollectionMetadata, error) {
 
-	// Run validation on the transaction qualifier. If it is valid, this will return
-	// the associated Flow transaction ID.
-	txID, err := r.validate.Transaction(rosTxID)
+	collIDs, err := r.index.CollectionsByHeight(height)
 	if err != nil {
-		return nil, fmt.Errorf("could not validate transaction: %w", err)
+		return nil, fmt.Errorf("could not get collections by height: %w", err)
 	}
 
-	// We retrieve all transaction IDs for the given block height to check that
-	// our transaction is part of it.
-	txIDs, err := r.index.TransactionsByHeight(height)
-	if err != nil {
-		return nil, fmt.Errorf("could not list block transactions: %w", err)
-	}
-	lookup := make(map[flow.Identifier]struct{})
-	for _, txID := range txIDs {
-		lookup[txID] = struct{}{}
-	}
-	_, ok := lookup[txID]
-	if !ok {
-		return nil, failure.UnknownTransaction{
-			Hash: rosTxID.Hash,
-			Description: failure.NewDescription(txMissing,
-				failure.WithUint64("block_index", height),
-				failure.WithID("block_hash", blockID),
-			),
+	collections := make([]object.CollectionMetadata, 0, len(collIDs))
+	for _, collID := range collIDs {
+		guarantee, err := r.index.Guarantee(collID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get guarantee (collection: %s): %w", collID, err)
 		}
+		collections = append(collections, rosettaCollection(guarantee))
 	}
 
-	// Retrieve the Flow token default withdrawal and deposit events.
-	deposit, err := r.generate.TokensDeposited(dps.FlowSymbol)
-	if err != nil {
-		return nil, fmt.Errorf("could not generate deposit event type: %w", err)
-	}
-	withdrawal, err := r.generate.TokensWithdrawn(dps.FlowSymbol)
-	if err != nil {
-		return nil, fmt.Errorf("could not generate withdrawal event type: %w", err)
-	}
-	// TODO Retrieve A.8624b52f9ddcd04a.FlowIDTableStaking DelegatorRewardsPaid
+	return collections, nil
+}
 
-	// Retrieve the deposit and withdrawal events for the block (yes, all of them).
-	events, err := r.index.Events(height, flow.EventType(deposit), flow.EventType(withdrawal))
-	if err != nil {
-		return nil, fmt.Errorf("could not get events: %w", err)
-	}
+// seals returns the metadata of the seals included in the block at the given
+// height.
+func (r *Retriever) seals(height uint64) ([]object.SealMetadata, error) {
 
-	// Convert events to operations.
-	ops, err := r.operations(txID, events)
+	sealIDs, err := r.index.SealsByHeight(height)
 	if err != nil {
-		return nil, fmt.Errorf("could not convert events to operations: %w", err)
+		return nil, fmt.Errorf("could not get seals by height: %w", err)
 	}
 
-	transaction := object.Transaction{
-		ID:         rosettaTxID(txID),
-		Operations: ops,
+	// Indexes built with earlier versions of Flow Go store seals that still
+	// have their service events, which can no longer be decoded into the
+	// current seal type. We don't want that to make the whole block
+	// unavailable, so we fall back to only providing the seal ID. Any other
+	// failure to get a seal is still an error.
+	seals := make([]object.SealMetadata, 0, len(sealIDs))
+	for _, sealID := range sealIDs {
+		seal, err := r.index.Seal(sealID)
+		if err != nil && undecodable(err) {
+			seals = append(seals, object.SealMetadata{ID: sealID.String()})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not get seal (seal: %x): %w", sealID, err)
+		}
+		seals = append(seals, rosettaSeal(sealID, seal))
 	}
 
-	return &transaction, nil
+	return seals, nil
 }
 
-// Sequence retrieves the sequence number of an account's public key.
-func (r *Retriever) Sequence(rosBlockID identifier.Block, rosAccountID identifier.Account, index int) (uint64, error) {
-
-	// Run validation on the Rosetta block identifier. This will infer any
-	// missing data and return the height and block ID.
-	height, _, err := r.validate.Block(rosBlockID)
-	if err != nil {
-		return 0, fmt.Errorf("could not validate block: %w", err)
-	}
-
-	// Run validation on the Rosetta account identifier. This will return the
-	// native Flow address.
-	address, err := r.validate.Account(rosAccountID)
-	if err != nil {
-		return 0, fmt.Errorf("could not validate account: %w", err)
+// undecodable returns whether the given error was caused by data that was read
+// from the index, but that could not be decoded into the requested type. When
+// the index is served by the DPS API, the server decodes the data itself and
+// only returns the text of its error, with an unknown status code, so the CBOR
+// error is recognized by its message instead.
+func undecodable(err error) bool {
+	var fieldErr *cbor.UnknownFieldError
+	var typeErr *cbor.UnmarshalTypeError
+	var syntaxErr *cbor.SyntaxError
+	var semanticErr *cbor.SemanticError
+	if errors.As(err, &fieldErr) ||
+		errors.As(err, &typeErr) ||
+		errors.As(err, &syntaxErr) ||
+		errors.As(err, &semanticErr) {
+		return true
 	}
 
-	// Retrieve the key at the height of the given block and for the given
-	// address at the given index.
-	key, err := r.invoke.Key(height, address, index)
-	if err != nil {
-		return 0, fmt.Errorf("could not retrieve account: %w", err)
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &grpcErr) {
+		return false
 	}
-
-	return key.SeqNumber, nil
+	remote := grpcErr.GRPCStatus()
+	return remote.Code() == codes.Unknown && strings.Contains(remote.Message(), "cbor: ")
 }
 
-// operations allows us to extract the operations for a transaction ID by using the given list of
-// events. In general, we retrieve all events for the block in question, so those should be passed in order to avoid
-// querying events for each transaction in a block.
-func (r *Retriever) operations(txID flow.Identifier, events []flow.Event) ([]*object.Operation, error) {
+// services returns the service events emitted in the block at the given height,
+// in the order in which they were emitted.
+func (r *Retriever) services(height uint64) ([]object.ServiceEvent, error) {
 
-	// These are the currently supported event types. The order here has to be kept the same so that we can keep
-	// deterministic operation indices, which is a requirement of the Rosetta API specification.
-	deposit, err := r.generate.TokensDeposited(dps.FlowSymbol)
+	setup, err := r.generator(height).EpochSetup()
 	if err != nil {
-		return nil, fmt.Errorf("could not generate deposit event type: %w", err)
+		return nil, fmt.Errorf("could not generate epoch setup event type: %w", err)
 	}
-	withdrawal, err := r.generate.TokensWithdrawn(dps.FlowSymbol)
+	commit, err := r.generator(height).EpochCommit()
 	if err != nil {
-		return nil, fmt.Errorf("could not generate withdrawal event type: %w", err)
+		return nil, fmt.Errorf("could not generate epoch commit event type: %w", err)
 	}
-	priorities := map[string]uint{
-		deposit:    1,
-		withdrawal: 2,
+
+	events, err := r.index.Events(height, flow.EventType(setup), flow.EventType(commit))
+	if err != nil {
+		return nil, fmt.Errorf("could not get events: %w", err)
 	}
 
-	// We then start by filtering out all events that don't have the right transaction
-	// ID or which are not a supported type. Afterwards, we sort them by priority,
-	// which  will make sure that we keep a deterministic index order for operations.
 	filtered := make([]flow.Event, 0, len(events))
 	for _, event := range events {
-		if event.TransactionID != txID {
-			continue
-		}
-		_, ok := priorities[string(event.Type)]
-		if !ok {
+		if event.Type != flow.EventType(setup) && event.Type != flow.EventType(commit) {
 			continue
 		}
 		filtered = append(filtered, event)
 	}
-	sort.Slice(filtered, func(i int, j int) bool {
-		return priorities[string(events[i].Type)] < priorities[string(events[j].Type)]
+	sort.SliceStable(filtered, func(i int, j int) bool {
+		if filtered[i].TransactionIndex != filtered[j].TransactionIndex {
+			return filtered[i].TransactionIndex < filtered[j].TransactionIndex
+		}
+		return filtered[i].EventIndex < filtered[j].EventIndex
 	})
 
-	// Now we can convert each event to an operation, as they are both filtered for
-	// only supported ones and properly ordered.
-	ops := make([]*object.Operation, 0, len(filtered))
+	var services []object.ServiceEvent
 	for _, event := range filtered {
-		op, err := r.convert.EventToOperation(event)
-		if errors.Is(err, ErrNoAddress) {
-			// this will happen when an event is not related to an account
-			continue
-		}
-		if errors.Is(err, ErrNotSupported) {
-			// this should never happen, but it's good defensive programming
-			continue
+		service, err := r.convert.EventToServiceEvent(event)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert service event (type: %s): %w", event.Type, err)
 		}
+		services = append(services, *service)
+	}
+
+	return services, nil
+}
+
+// transactions converts the transactions with the given IDs into Rosetta
+// transactions, using up to the configured number of workers. The resulting
+// transactions keep the order of the given IDs.
+func (r *Retriever) transactions(height uint64, txIDs []flow.Identifier, events []flow.Event) ([]*object.Transaction, error) {
+
+	if len(txIDs) == 0 {
+		return nil, nil
+	}
+
+	transactions := make([]*object.Transaction, len(txIDs))
+	convert := func(index int) error {
+		txID := txIDs[index]
+		transaction, err := r.transaction(height, txID, events)
 		if err != nil {
-			return nil, fmt.Errorf("could not convert event to operation (tx: %s, type: %s): %w", event.TransactionID, event.Type, err)
+			return fmt.Errorf("could not convert transaction %s: %w", txID, err)
 		}
-		ops = append(ops, op)
+		transactions[index] = transaction
+		return nil
 	}
 
-	// Finally, we can assign the indices.
-	for index, op := range ops {
-		op.ID.Index = uint(index)
+	// Most blocks only have a handful of transactions, so we avoid the overhead
+	// of spinning up workers when there would only be one anyway.
+	workers := int(r.cfg.Workers)
+	if workers > len(txIDs) {
+		workers = len(txIDs)
+	}
+	if workers == 1 {
+		for index := range txIDs {
+			err := convert(index)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return transactions, nil
+	}
+
+	indices := make(chan int, len(txIDs))
+	for index := range txIDs {
+		indices <- index
+	}
+	close(indices)
+
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for index := range indices {
+				err := convert(index)
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+
+	// A failed worker stops taking new transactions, but we still wait for all
+	// of them, so that no worker keeps writing to the result after we return.
+	var convErr error
+	for i := 0; i < workers; i++ {
+		err := <-errs
+		if err != nil && convErr == nil {
+			convErr = err
+		}
+	}
+	if convErr != nil {
+		return nil, convErr
+	}
+
+	return transactions, nil
+}
+
+// Blocks retrieves all blocks between the given start and end blocks, both included, in order of height.
+// For each block, it also returns the identifiers of the transactions that exceeded the transaction limit.
+func (r *Retriever) Blocks(rosStart identifier.Block, rosEnd identifier.Block) ([]*object.Block, [][]identifier.Transaction, error) {
+
+	err := r.pin(&rosStart, &rosEnd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not pin latest block: %w", err)
+	}
+	start, _, err := r.validate.Block(rosStart)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not validate start block: %w", err)
+	}
+	end, _, err := r.validate.Block(rosEnd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not validate end block: %w", err)
+	}
+	if start > end {
+		return nil, nil, failure.InvalidBlock{
+			Description: failure.NewDescription(rangeInverted,
+				failure.WithUint64("start_index", start),
+				failure.WithUint64("end_index", end),
+			),
+		}
+	}
+	if end-start >= uint64(r.cfg.BlockLimit) {
+		return nil, nil, failure.InvalidBlock{
+			Description: failure.NewDescription(rangeExceeded,
+				failure.WithUint64("start_index", start),
+				failure.WithUint64("end_index", end),
+				failure.WithUint64("block_limit", uint64(r.cfg.BlockLimit)),
+			),
+		}
+	}
+
+	blocks := make([]*object.Block, 0, end-start+1)
+	extraTxIDs := make([][]identifier.Transaction, 0, end-start+1)
+	for height := start; height <= end; height++ {
+		index := height
+		block, extra, err := r.Block(identifier.Block{Index: &index})
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not retrieve block (height: %d): %w", height, err)
+		}
+		blocks = append(blocks, block)
+		extraTxIDs = append(extraTxIDs, extra)
+	}
+
+	return blocks, extraTxIDs, nil
+}
+
+// Search walks down the blocks starting from the given block, and from the transaction with the given
+// index within it, and retrieves the transactions for which the given match function returns true, up
+// to the given limit. It returns the block and transaction index at which a subsequent search should
+// resume, or a nil block if the search reached the oldest indexed block. When the search is limited to
+// transactions affecting a given account, and the account transaction history covers the searched
+// blocks, the blocks in which the account was not active are skipped without being converted.
+func (r *Retriever) Search(rosBlockID identifier.Block, index uint, limit uint, rosAccountID *identifier.Account, match func(*object.Transaction) bool) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+
+	height, _, err := r.validate.Block(rosBlockID)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("could not validate block: %w", err)
+	}
+
+	first, err := r.index.First()
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("could not get first block index: %w", err)
+	}
+
+	// The account is only used to skip blocks, so an invalid address simply
+	// means that every block has to be searched.
+	var address *flow.Address
+	if rosAccountID != nil && r.cfg.History != nil {
+		valid, err := r.validate.Account(*rosAccountID)
+		if err == nil {
+			address = &valid
+		}
+	}
+
+	var matches []object.BlockTransaction
+	for scanned := uint(0); scanned < r.cfg.SearchLimit; scanned++ {
+
+		if address != nil {
+			active, ok, err := r.active(*address, height, first)
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf("could not look up account activity (height: %d): %w", height, err)
+			}
+			if !ok {
+				return matches, nil, 0, nil
+			}
+			if active != height {
+				height = active
+				index = 0
+			}
+		}
+
+		txIDs, err := r.index.TransactionsByHeight(height)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("could not get transactions by height (height: %d): %w", height, err)
+		}
+
+		// Only the block we resume from is partially searched already.
+		var events []flow.Event
+		if index < uint(len(txIDs)) {
+			events, err = r.events(height)
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf("could not get block events (height: %d): %w", height, err)
+			}
+		}
+
+		var header *flow.Header
+		for ; index < uint(len(txIDs)); index++ {
+
+			// Once we have enough matches, the next search resumes at the
+			// first transaction that we did not look at.
+			if uint(len(matches)) >= limit {
+				next := identifier.Block{Index: &height}
+				return matches, &next, index, nil
+			}
+
+			txID := txIDs[index]
+			rosTx, err := r.transaction(height, txID, events)
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf("could not convert transaction (height: %d, tx: %s): %w", height, txID, err)
+			}
+			if !match(rosTx) {
+				continue
+			}
+
+			// We only need the header once there is a match in the block.
+			if header == nil {
+				header, err = r.index.Header(height)
+				if err != nil {
+					return nil, nil, 0, fmt.Errorf("could not get header (height: %d): %w", height, err)
+				}
+			}
+			matches = append(matches, object.BlockTransaction{
+				BlockID:     rosettaBlockID(height, header.ID()),
+				Transaction: rosTx,
+			})
+		}
+
+		if height == first {
+			return matches, nil, 0, nil
+		}
+		height--
+		index = 0
+
+		if uint(len(matches)) >= limit {
+			break
+		}
+	}
+
+	next := identifier.Block{Index: &height}
+
+	return matches, &next, 0, nil
+}
+
+// active returns the height at which a search for transactions affecting the given account should
+// continue, starting from the given height. If the account transaction history covers the height,
+// this is the most recent height at which the account was active, or the height right below the
+// history if it was not active within it. Otherwise, the given height is returned as is. The boolean
+// is false if the account was not active anymore down to the given first height.
+func (r *Retriever) active(address flow.Address, height uint64, first uint64) (uint64, bool, error) {
+
+	start, end, ok := r.cfg.History.Range()
+	if !ok || height < start || height > end {
+		return height, true, nil
+	}
+
+	latest, found, err := r.cfg.History.Latest(address, height)
+	if err != nil {
+		return 0, false, fmt.Errorf("could not get latest activity: %w", err)
+	}
+	if found && latest >= start {
+		return latest, true, nil
+	}
+
+	if start <= first {
+		return 0, false, nil
+	}
+
+	return start - 1, true, nil
+}
+
+// AccountTransactions retrieves the transactions that affected the given account between the given
+// blocks, inclusive, in order of height, from the account transaction history. When the start block
+// is omitted, the transactions start at the first block of the history, and when the end block is
+// omitted, they end at its last block. The transactions start at the given index within the start
+// block, and when there are more than the given limit, the block and index at which the next page
+// starts are returned.
+func (r *Retriever) AccountTransactions(rosAccountID identifier.Account, rosStart identifier.Block, rosEnd identifier.Block, index uint, limit uint) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+
+	if r.cfg.History == nil {
+		return nil, nil, 0, fmt.Errorf("account transaction history is not enabled")
+	}
+
+	address, err := r.validate.Account(rosAccountID)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("could not validate account: %w", err)
+	}
+
+	first, last, ok := r.cfg.History.Range()
+	if !ok {
+		return nil, nil, 0, failure.UnavailableBlock{
+			Description: failure.NewDescription(historyEmpty),
+		}
+	}
+
+	start := first
+	if rosStart.Index != nil || rosStart.Hash != "" {
+		start, _, err = r.validate.Block(rosStart)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("could not validate start block: %w", err)
+		}
+	}
+	end := last
+	if rosEnd.Index != nil || rosEnd.Hash != "" {
+		end, _, err = r.validate.Block(rosEnd)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("could not validate end block: %w", err)
+		}
+	}
+	if start > end {
+		return nil, nil, 0, failure.InvalidBlock{
+			Description: failure.NewDescription(rangeInverted,
+				failure.WithUint64("start_index", start),
+				failure.WithUint64("end_index", end),
+			),
+		}
+	}
+	if start < first {
+		return nil, nil, 0, failure.InvalidBlock{
+			Description: failure.NewDescription(historyTooLow,
+				failure.WithUint64("start_index", start),
+				failure.WithUint64("first_index", first),
+			),
+		}
+	}
+	if end > last {
+		return nil, nil, 0, failure.UnavailableBlock{
+			Index: end,
+			Description: failure.NewDescription(historyTooHigh,
+				failure.WithUint64("end_index", end),
+				failure.WithUint64("last_index", last),
+			),
+		}
+	}
+
+	// We ask for one more reference than the limit, so that we know where the
+	// next page starts, if there is one.
+	refs, err := r.cfg.History.Transactions(address, start, uint32(index), end, limit+1)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("could not get account transactions: %w", err)
+	}
+	var next *identifier.Block
+	var nextIndex uint
+	if uint(len(refs)) > limit {
+		ref := refs[limit]
+		next = &identifier.Block{Index: &ref.Height}
+		nextIndex = uint(ref.Index)
+		refs = refs[:limit]
+	}
+
+	// Events are only retrieved once for each block in which several of the
+	// transactions were executed.
+	var height uint64
+	var events []flow.Event
+	transactions := make([]object.BlockTransaction, 0, len(refs))
+	for i, ref := range refs {
+		if i == 0 || ref.Height != height {
+			height = ref.Height
+			events, err = r.events(height)
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf("could not get block events (height: %d): %w", height, err)
+			}
+		}
+		rosTx, err := r.transaction(ref.Height, ref.TxID, events)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("could not convert transaction (height: %d, tx: %s): %w", ref.Height, ref.TxID, err)
+		}
+		transactions = append(transactions, object.BlockTransaction{
+			BlockID:     rosettaBlockID(ref.Height, ref.BlockID),
+			Transaction: rosTx,
+		})
+	}
+
+	return transactions, next, nextIndex, nil
+}
+
+// Transaction retrieves a transaction given its identifier and the identifier of the block it is a part of.
+func (r *Retriever) Transaction(rosBlockID identifier.Block, rosTxID identifier.Transaction) (*object.Transaction, error) {
+
+	// Run validation on the Rosetta block identifier. If it is valid, this will
+	// return the associated Flow block height and block ID.
+	height, blockID, err := r.validate.Block(rosBlockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate block: %w", err)
+	}
+
+	// Run validation on the transaction qualifier. If it is valid, this will return
+	// the associated Flow transaction ID.
+	txID, err := r.validate.Transaction(rosTxID)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate transaction: %w", err)
+	}
+
+	// We retrieve all transaction IDs for the given block height to check that
+	// our transaction is part of it.
+	txIDs, err := r.index.TransactionsByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not list block transactions: %w", err)
+	}
+	lookup := make(map[flow.Identifier]struct{})
+	for _, txID := range txIDs {
+		lookup[txID] = struct{}{}
+	}
+	_, ok := lookup[txID]
+	if !ok {
+		return nil, failure.UnknownTransaction{
+			Hash: rosTxID.Hash,
+			Description: failure.NewDescription(txMissing,
+				failure.WithUint64("block_index", height),
+				failure.WithID("block_hash", blockID),
+			),
+		}
+	}
+
+	// TODO Retrieve A.8624b52f9ddcd04a.FlowIDTableStaking DelegatorRewardsPaid
+
+	// Retrieve the deposit, withdrawal and fees events for the block (yes, all of them).
+	events, err := r.events(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get block events: %w", err)
+	}
+
+	// Convert events to operations.
+	transaction, err := r.transaction(height, txID, events)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// Lookup retrieves a transaction given only its identifier, along with the complete identifier of the
+// block it is a part of, which is found through the index of transaction heights.
+func (r *Retriever) Lookup(rosTxID identifier.Transaction) (identifier.Block, *object.Transaction, error) {
+
+	txID, err := r.validate.Transaction(rosTxID)
+	if err != nil {
+		return identifier.Block{}, nil, fmt.Errorf("could not validate transaction: %w", err)
+	}
+
+	height, err := r.index.HeightForTransaction(txID)
+	if err != nil {
+		return identifier.Block{}, nil, fmt.Errorf("could not get height for transaction: %w", err)
+	}
+
+	// The block is validated like any other block, so that a transaction in a
+	// block that is not sealed yet, when only sealed blocks are served, is
+	// reported the same way as when its block is requested directly.
+	rosBlockID, err := r.BlockID(identifier.Block{Index: &height})
+	if err != nil {
+		return identifier.Block{}, nil, fmt.Errorf("could not complete block identifier: %w", err)
+	}
+
+	transaction, err := r.Transaction(rosBlockID, rosTxID)
+	if err != nil {
+		return identifier.Block{}, nil, fmt.Errorf("could not retrieve transaction: %w", err)
+	}
+
+	return rosBlockID, transaction, nil
+}
+
+// transaction converts the transaction with the given ID into a Rosetta
+// transaction, using the given events of its block. Its metadata holds the
+// computation it used, its gas limit, the fees deducted for it, the accounts it
+// created and the contracts it changed, as well as its execution error if it
+// failed.
+func (r *Retriever) transaction(height uint64, txID flow.Identifier, events []flow.Event) (*object.Transaction, error) {
+
+	ops, omitted, err := r.operations(height, txID, events)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert events to operations: %w", err)
+	}
+
+	result, err := r.index.Result(txID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get transaction result: %w", err)
+	}
+	body, err := r.index.Transaction(txID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get transaction body: %w", err)
+	}
+
+	// The collection of fees does not say who paid them, so the payer of the
+	// transaction is given in the fee metadata of the operation.
+	for _, list := range [][]*object.Operation{ops, omitted} {
+		for _, op := range list {
+			if op.Type != configuration.OperationFeeCollection {
+				continue
+			}
+			op.Metadata = &object.OperationMetadata{
+				Fee: &object.FeeMetadata{
+					Payer: identifier.Account{Address: body.Payer.String()},
+				},
+			}
+		}
+	}
+
+	// Failed transactions don't emit token events, so their operations are
+	// derived from what their script and arguments intended to do instead.
+	// Like for events, intended operations of types that are not allowlisted
+	// are folded into the metadata.
+	if result.ErrorMessage != "" {
+		intended, skipped := r.intended(body, uint(len(ops)))
+		ops = append(ops, intended...)
+		omitted = append(omitted, skipped...)
+	}
+
+	metadata := rosettaTxMetadata(result, body)
+	metadata.Omitted = omitted
+	metadata.Fees, err = r.fees(height, txID, events)
+	if err != nil {
+		return nil, fmt.Errorf("could not get transaction fees: %w", err)
+	}
+	metadata.Accounts, err = r.accounts(txID, body.Payer, events)
+	if err != nil {
+		return nil, fmt.Errorf("could not get created accounts: %w", err)
+	}
+	metadata.Contracts, err = r.contracts(txID, events)
+	if err != nil {
+		return nil, fmt.Errorf("could not get contract changes: %w", err)
+	}
+
+	transaction := object.Transaction{
+		ID:         rosettaTxID(txID),
+		Operations: ops,
+		Metadata:   metadata,
+	}
+
+	return &transaction, nil
+}
+
+// These are the types of the events that the Flow virtual machine emits when a
+// public key is added to an account, and when a contract is deployed, updated or
+// removed. Unlike the one for created accounts, flow-go has no constants for them.
+const (
+	eventAccountKeyAdded        flow.EventType = "flow.AccountKeyAdded"
+	eventAccountContractAdded   flow.EventType = "flow.AccountContractAdded"
+	eventAccountContractUpdated flow.EventType = "flow.AccountContractUpdated"
+	eventAccountContractRemoved flow.EventType = "flow.AccountContractRemoved"
+)
+
+// events returns the events of the block at the given height that are needed to
+// convert its transactions, which are the Flow token deposits, withdrawals, mints
+// and burns, the deducted transaction fees, as well as the created accounts, the
+// keys added to accounts and the changes to contracts.
+func (r *Retriever) events(height uint64) ([]flow.Event, error) {
+
+	types, err := r.transfers(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get transfer event types: %w", err)
+	}
+	fees, err := r.generator(height).FeesDeducted()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate fees event type: %w", err)
+	}
+
+	types = append(types,
+		flow.EventType(fees),
+		flow.EventAccountCreated,
+		eventAccountKeyAdded,
+		eventAccountContractAdded,
+		eventAccountContractUpdated,
+		eventAccountContractRemoved,
+	)
+	events, err := r.index.Events(height, types...)
+	if err != nil {
+		return nil, fmt.Errorf("could not get events: %w", err)
+	}
+
+	return events, nil
+}
+
+// fees returns the fees deducted for the transaction with the given ID, using
+// the given events of its block. Transactions executed before the fees event
+// was introduced have no fees event, in which case the fees are nil.
+func (r *Retriever) fees(height uint64, txID flow.Identifier, events []flow.Event) (*object.TransactionFees, error) {
+
+	typ, err := r.generator(height).FeesDeducted()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate fees event type: %w", err)
+	}
+
+	for _, event := range events {
+		if event.TransactionID != txID || event.Type != flow.EventType(typ) {
+			continue
+		}
+		fees, err := r.convert.EventToFees(event)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert fees event: %w", err)
+		}
+		return fees, nil
+	}
+
+	return nil, nil
+}
+
+// accounts returns the accounts created by the transaction with the given ID,
+// using the given events of its block, in the order in which they were created.
+// The payer of the transaction is given as their creator, and the keys added to
+// each of them by the same transaction are counted. Keys added to accounts that
+// already existed are ignored.
+func (r *Retriever) accounts(txID flow.Identifier, payer flow.Address, events []flow.Event) ([]object.CreatedAccount, error) {
+
+	var created, added []flow.Event
+	for _, event := range events {
+		if event.TransactionID != txID {
+			continue
+		}
+		switch event.Type {
+		case flow.EventAccountCreated:
+			created = append(created, event)
+		case eventAccountKeyAdded:
+			added = append(added, event)
+		}
+	}
+	if len(created) == 0 {
+		return nil, nil
+	}
+	sort.Slice(created, func(i int, j int) bool {
+		return created[i].EventIndex < created[j].EventIndex
+	})
+
+	accounts := make([]object.CreatedAccount, 0, len(created))
+	lookup := make(map[flow.Address]int, len(created))
+	for _, event := range created {
+		address, err := r.convert.EventToAddress(event)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert account created event: %w", err)
+		}
+		lookup[address] = len(accounts)
+		account := object.CreatedAccount{
+			Address: address.String(),
+			Creator: payer.String(),
+		}
+		accounts = append(accounts, account)
+	}
+
+	for _, event := range added {
+		address, err := r.convert.EventToAddress(event)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert account key added event: %w", err)
+		}
+		index, ok := lookup[address]
+		if !ok {
+			continue
+		}
+		accounts[index].Keys++
+	}
+
+	return accounts, nil
+}
+
+// contracts returns the changes to contracts made by the transaction with the
+// given ID, using the given events of its block, in the order in which they were
+// made. Deployments and updates of contracts can change how tokens behave, and
+// thus how their events are converted, from that height on.
+func (r *Retriever) contracts(txID flow.Identifier, events []flow.Event) ([]object.ContractChange, error) {
+
+	var filtered []flow.Event
+	for _, event := range events {
+		if event.TransactionID != txID {
+			continue
+		}
+		switch event.Type {
+		case eventAccountContractAdded, eventAccountContractUpdated, eventAccountContractRemoved:
+			filtered = append(filtered, event)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, nil
+	}
+	sort.Slice(filtered, func(i int, j int) bool {
+		return filtered[i].EventIndex < filtered[j].EventIndex
+	})
+
+	contracts := make([]object.ContractChange, 0, len(filtered))
+	for _, event := range filtered {
+		contract, err := r.convert.EventToContract(event)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert contract event (type: %s): %w", event.Type, err)
+		}
+		contracts = append(contracts, *contract)
+	}
+
+	return contracts, nil
+}
+
+// Sequence retrieves the sequence number of an account's public key.
+func (r *Retriever) Sequence(rosBlockID identifier.Block, rosAccountID identifier.Account, index int) (uint64, error) {
+
+	// Run validation on the Rosetta block identifier. This will infer any
+	// missing data and return the height and block ID.
+	height, _, err := r.validate.Block(rosBlockID)
+	if err != nil {
+		return 0, fmt.Errorf("could not validate block: %w", err)
+	}
+
+	// Run validation on the Rosetta account identifier. This will return the
+	// native Flow address.
+	address, err := r.validate.Account(rosAccountID)
+	if err != nil {
+		return 0, fmt.Errorf("could not validate account: %w", err)
+	}
+
+	// Retrieve the key at the height of the given block and for the given
+	// address at the given index.
+	key, err := r.invoke.Key(height, address, index)
+	if err != nil {
+		return 0, fmt.Errorf("could not retrieve account: %w", err)
+	}
+
+	return key.SeqNumber, nil
+}
+
+// Keys retrieves all public keys of an account, including revoked ones, along
+// with their sequence numbers. Accounts that do not exist at the given block
+// have no keys.
+func (r *Retriever) Keys(rosBlockID identifier.Block, rosAccountID identifier.Account) ([]object.AccountKey, error) {
+
+	height, _, err := r.validate.Block(rosBlockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate block: %w", err)
+	}
+
+	address, err := r.validate.Account(rosAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate account: %w", err)
+	}
+
+	account, err := r.invoke.Account(height, address)
+	if err != nil && strings.Contains(err.Error(), missingAccount) {
+		return []object.AccountKey{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve account: %w", err)
+	}
+
+	keys := make([]object.AccountKey, 0, len(account.Keys))
+	for _, key := range account.Keys {
+		keys = append(keys, rosettaKey(key))
+	}
+
+	return keys, nil
+}
+
+// generator returns the script generator for the core contracts that were in
+// effect at the given height.
+func (r *Retriever) generator(height uint64) Generator {
+	for _, migration := range r.cfg.Migrations {
+		if height < migration.Height {
+			return migration.Generate
+		}
+	}
+	return r.generate
+}
+
+// transfers returns the Flow token deposit, withdrawal, minted and burned event
+// types at the given height, in the order that their operations are indexed in.
+func (r *Retriever) transfers(height uint64) ([]flow.EventType, error) {
+	generate := r.generator(height)
+	deposit, err := generate.TokensDeposited(dps.FlowSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate deposit event type: %w", err)
+	}
+	withdrawal, err := generate.TokensWithdrawn(dps.FlowSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate withdrawal event type: %w", err)
+	}
+	minted, err := generate.TokensMinted(dps.FlowSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate minted event type: %w", err)
+	}
+	burned, err := generate.TokensBurned(dps.FlowSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate burned event type: %w", err)
+	}
+	types := []flow.EventType{
+		flow.EventType(deposit),
+		flow.EventType(withdrawal),
+		flow.EventType(minted),
+		flow.EventType(burned),
+	}
+	return types, nil
+}
+
+// operations allows us to extract the operations for a transaction ID by using the given list of
+// events. In general, we retrieve all events for the block in question, so those should be passed in order to avoid
+// querying events for each transaction in a block. Operations are ordered by event index, then by event type, with
+// deposits before withdrawals, and their indices only depend on the events of the transaction. Operations of types
+// that are not allowlisted are returned separately, without an index, and are not related to other operations.
+func (r *Retriever) operations(height uint64, txID flow.Identifier, events []flow.Event) ([]*object.Operation, []*object.Operation, error) {
+
+	// These are the currently supported event types. Their order is used to break ties between events with the same
+	// index, so it has to be kept the same to keep deterministic operation indices.
+	types, err := r.transfers(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get transfer event types: %w", err)
+	}
+	priorities := make(map[string]uint, len(types))
+	for index, typ := range types {
+		priorities[string(typ)] = uint(index + 1)
+	}
+
+	// We then start by filtering out all events that don't have the right transaction
+	// ID or which are not a supported type. Afterwards, we sort them by event index,
+	// and then by priority, so that operations are in the order in which their events
+	// were emitted, regardless of the order in which the index returns the events.
+	filtered := make([]flow.Event, 0, len(events))
+	for _, event := range events {
+		if event.TransactionID != txID {
+			continue
+		}
+		_, ok := priorities[string(event.Type)]
+		if !ok {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+	sort.SliceStable(filtered, func(i int, j int) bool {
+		if filtered[i].EventIndex != filtered[j].EventIndex {
+			return filtered[i].EventIndex < filtered[j].EventIndex
+		}
+		return priorities[string(filtered[i].Type)] < priorities[string(filtered[j].Type)]
+	})
+
+	// Now we can convert each event to an operation, as they are both filtered for
+	// only supported ones and properly ordered.
+	converted := make([]*object.Operation, 0, len(filtered))
+	for _, event := range filtered {
+		op, err := r.convert.EventToOperation(event)
+		if errors.Is(err, ErrNoAddress) {
+			// this will happen when an event is not related to an account
+			continue
+		}
+		if errors.Is(err, ErrNotSupported) {
+			// this should never happen, but it's good defensive programming
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not convert event to operation (tx: %s, type: %s): %w", event.TransactionID, event.Type, err)
+		}
+		converted = append(converted, op)
+	}
+
+	// Minted and burned tokens have no account of their own, so they are folded
+	// into the deposit or withdrawal of the tokens.
+	converted = r.supply(converted)
+
+	// Operations of types that are not allowlisted are folded into the metadata,
+	// so that they neither take an index nor count as the withdrawal of a deposit.
+	ops := make([]*object.Operation, 0, len(converted))
+	var omitted []*object.Operation
+	for _, op := range converted {
+		if !r.allowed(op.Type) {
+			omitted = append(omitted, op)
+			continue
+		}
+		ops = append(ops, op)
+	}
+
+	// Finally, we can assign the indices. Events that are skipped during conversion
+	// do not leave gaps, so indices always go from zero to the number of operations.
+	for index, op := range ops {
+		op.ID.Index = uint(index)
+	}
+
+	// Once indices are assigned, the deposits can reference their withdrawals.
+	relate(ops)
+
+	return ops, omitted, nil
+}
+
+// supply folds the operations of minted and burned tokens into the operations of
+// the accounts that the tokens are minted for or burned from, following the Rosetta
+// convention of single-sided mints and burns without counterparty. The deposit of
+// the minted tokens that follows a mint becomes the mint, while the withdrawal of
+// the burned tokens that precedes a burn becomes the burn. Mints and burns without
+// a matching deposit or withdrawal are dropped, as there is no account to give
+// them, and so are mints and burns whose type is not allowlisted, in which case
+// the deposit or withdrawal stays a transfer.
+func (r *Retriever) supply(ops []*object.Operation) []*object.Operation {
+
+	folded := make([]*object.Operation, 0, len(ops))
+	var mints []*object.Operation
+	for _, op := range ops {
+		switch op.Type {
+
+		case configuration.OperationMint:
+			mints = append(mints, op)
+
+		case configuration.OperationBurn:
+			for i := len(folded) - 1; i >= 0; i-- {
+				withdrawal := folded[i]
+				if withdrawal.Type != dps.OperationTransfer || !equal(withdrawal.Amount, op.Amount) {
+					continue
+				}
+				if r.allowed(configuration.OperationBurn) {
+					withdrawal.Type = configuration.OperationBurn
+				}
+				break
+			}
+
+		default:
+			for i, mint := range mints {
+				if op.Type != dps.OperationTransfer || !equal(mint.Amount, op.Amount) {
+					continue
+				}
+				if r.allowed(configuration.OperationMint) {
+					op.Type = configuration.OperationMint
+				}
+				mints = append(mints[:i], mints[i+1:]...)
+				break
+			}
+			folded = append(folded, op)
+		}
+	}
+
+	return folded
+}
+
+// equal returns whether the given amounts have the same value and currency.
+func equal(a object.Amount, b object.Amount) bool {
+	return a.Value == b.Value && a.Currency.Symbol == b.Currency.Symbol
+}
+
+// allowed returns whether operations of the given type are included in transactions.
+func (r *Retriever) allowed(typ string) bool {
+	if len(r.cfg.OperationTypes) == 0 {
+		return true
+	}
+	for _, allowed := range r.cfg.OperationTypes {
+		if typ == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// relate links the deposit of each transfer to the withdrawal it received the
+// tokens from, which is the earliest unrelated withdrawal of the same amount that
+// precedes it. Withdrawals and deposits without a counterpart, such as those of
+// vaults that are not stored in an account, are left without related operations,
+// and so are mints and burns, which have no counterparty.
+func relate(ops []*object.Operation) {
+	var pending []*object.Operation
+	for _, op := range ops {
+		if op.Type == configuration.OperationMint || op.Type == configuration.OperationBurn {
+			continue
+		}
+		if strings.HasPrefix(op.Amount.Value, "-") {
+			pending = append(pending, op)
+			continue
+		}
+		for j, withdrawal := range pending {
+			if withdrawal.Amount.Currency.Symbol != op.Amount.Currency.Symbol {
+				continue
+			}
+			if strings.TrimPrefix(withdrawal.Amount.Value, "-") != op.Amount.Value {
+				continue
+			}
+			op.RelatedIDs = []identifier.Operation{{Index: withdrawal.ID.Index}}
+			pending = append(pending[:j], pending[j+1:]...)
+			break
+		}
+	}
+}
+
+// Rewards retrieves the staking rewards paid out to the given node operator
+// between the given start and end blocks, both included. If a delegator ID is
+// given, the rewards of that delegator of the node are retrieved instead.
+// At most the configured reward limit of blocks is looked through; if the range
+// is larger than that, the block to resume the lookup from is returned as well.
+func (r *Retriever) Rewards(nodeID string, delegatorID *uint32, rosStart identifier.Block, rosEnd identifier.Block) ([]object.Reward, *identifier.Block, error) {
+
+	err := r.pin(&rosStart, &rosEnd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not pin latest block: %w", err)
+	}
+	start, _, err := r.validate.Block(rosStart)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not validate start block: %w", err)
+	}
+	end, _, err := r.validate.Block(rosEnd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not validate end block: %w", err)
+	}
+	if start > end {
+		return nil, nil, failure.InvalidBlock{
+			Description: failure.NewDescription(rangeInverted,
+				failure.WithUint64("start_index", start),
+				failure.WithUint64("end_index", end),
+			),
+		}
+	}
+
+	var next *identifier.Block
+	if end-start >= uint64(r.cfg.RewardLimit) {
+		resume := start + uint64(r.cfg.RewardLimit)
+		next = &identifier.Block{Index: &resume}
+		end = resume - 1
+	}
+
+	var rewards []object.Reward
+	for height := start; height <= end; height++ {
+
+		// Node operators and delegators each have their own event type, so we only
+		// need to look for the one that matches the request.
+		var eventType string
+		if delegatorID == nil {
+			eventType, err = r.generator(height).RewardsPaid()
+		} else {
+			eventType, err = r.generator(height).DelegatorRewardsPaid()
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not generate rewards event type (height: %d): %w", height, err)
+		}
+
+		events, err := r.index.Events(height, flow.EventType(eventType))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get events (height: %d): %w", height, err)
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		header, err := r.index.Header(height)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get header (height: %d): %w", height, err)
+		}
+
+		for _, event := range events {
+			reward, err := r.convert.EventToReward(event)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not convert event: %w", err)
+			}
+			if reward.NodeID != nodeID {
+				continue
+			}
+			if delegatorID != nil && (reward.DelegatorID == nil || *reward.DelegatorID != *delegatorID) {
+				continue
+			}
+			reward.BlockID = rosettaBlockID(height, header.ID())
+			rewards = append(rewards, *reward)
+		}
+	}
+
+	return rewards, next, nil
+}
+
+// Statement retrieves all operations affecting the given account between the given
+// start and end blocks, both included, in the order in which they were executed.
+func (r *Retriever) Statement(rosAccountID identifier.Account, rosStart identifier.Block, rosEnd identifier.Block) ([]object.StatementEntry, error) {
+
+	address, err := r.validate.Account(rosAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate account: %w", err)
+	}
+	err = r.pin(&rosStart, &rosEnd)
+	if err != nil {
+		return nil, fmt.Errorf("could not pin latest block: %w", err)
+	}
+	start, _, err := r.validate.Block(rosStart)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate start block: %w", err)
+	}
+	end, _, err := r.validate.Block(rosEnd)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate end block: %w", err)
+	}
+	if start > end {
+		return nil, failure.InvalidBlock{
+			Description: failure.NewDescription(rangeInverted,
+				failure.WithUint64("start_index", start),
+				failure.WithUint64("end_index", end),
+			),
+		}
+	}
+
+	var entries []object.StatementEntry
+	for height := start; height <= end; height++ {
+
+		types, err := r.transfers(height)
+		if err != nil {
+			return nil, fmt.Errorf("could not get transfer event types (height: %d): %w", height, err)
+		}
+		events, err := r.index.Events(height, types...)
+		if err != nil {
+			return nil, fmt.Errorf("could not get events (height: %d): %w", height, err)
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		header, err := r.index.Header(height)
+		if err != nil {
+			return nil, fmt.Errorf("could not get header (height: %d): %w", height, err)
+		}
+
+		// We go through the transactions of the block so that entries are in
+		// execution order, but only convert those which emitted transfer events.
+		txIDs, err := r.index.TransactionsByHeight(height)
+		if err != nil {
+			return nil, fmt.Errorf("could not get transactions by height (height: %d): %w", height, err)
+		}
+		lookup := make(map[flow.Identifier]struct{}, len(events))
+		for _, event := range events {
+			lookup[event.TransactionID] = struct{}{}
+		}
+
+		for _, txID := range txIDs {
+			_, ok := lookup[txID]
+			if !ok {
+				continue
+			}
+
+			// The operations are converted for the whole transaction before
+			// filtering, so that operation indices match the ones of the Data API.
+			ops, _, err := r.operations(height, txID, events)
+			if err != nil {
+				return nil, fmt.Errorf("could not get operations (height: %d, tx: %s): %w", height, txID, err)
+			}
+			for _, op := range ops {
+				if op.AccountID.Address != address.String() {
+					continue
+				}
+				entry := object.StatementEntry{
+					BlockID:       rosettaBlockID(height, header.ID()),
+					Timestamp:     header.Timestamp.UnixNano() / 1_000_000,
+					TransactionID: rosettaTxID(txID),
+					Operation:     op,
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// Node retrieves the staking record of the given node operator at the given block.
+func (r *Retriever) Node(rosBlockID identifier.Block, nodeID string) (identifier.Block, *object.Node, error) {
+
+	height, blockID, err := r.validate.Block(rosBlockID)
+	if err != nil {
+		return identifier.Block{}, nil, fmt.Errorf("could not validate block: %w", err)
+	}
+
+	script, err := r.generator(height).GetNodeInfo()
+	if err != nil {
+		return identifier.Block{}, nil, fmt.Errorf("could not generate script: %w", err)
+	}
+	id, err := cadence.NewString(nodeID)
+	if err != nil {
+		return identifier.Block{}, nil, fmt.Errorf("could not convert node ID: %w", err)
+	}
+	result, err := r.invoke.Script(height, script, []cadence.Value{id})
+	if err != nil && strings.Contains(err.Error(), missingNode) {
+		return identifier.Block{}, nil, failure.UnknownNode{
+			NodeID: nodeID,
+			Description: failure.NewDescription(nodeMissing,
+				failure.WithUint64("block_index", height),
+				failure.WithID("block_hash", blockID),
+			),
+		}
+	}
+	if err != nil {
+		return identifier.Block{}, nil, fmt.Errorf("could not invoke script: %w", err)
+	}
+
+	node, err := rosettaNode(result)
+	if err != nil {
+		return identifier.Block{}, nil, fmt.Errorf("could not convert node info: %w", err)
 	}
 
-	return ops, nil
+	return rosettaBlockID(height, blockID), node, nil
 }
@@ -43,11 +43,11 @@ func TestNew(t *testing.T) {
 	assert.Equal(t, convert, r.convert)
 }
 
-func BaselineRetriever(t *testing.T, opts ...func(*Retriever)) *Retriever {
+func BaselineRetriever(t testing.TB, opts ...func(*Retriever)) *Retriever {
 	t.Helper()
 
 	r := Retriever{
-		cfg:      Config{TransactionLimit: 999},
+		cfg:      Config{TransactionLimit: 999, BlockLimit: 999, SearchLimit: 999, RewardLimit: 999, Workers: 1},
 		params:   mocks.GenericParams,
 		index:    mocks.BaselineReader(t),
 		validate: mocks.BaselineValidator(t),
@@ -104,3 +104,93 @@ func WithLimit(limit uint) func(*Retriever) {
 		retriever.cfg.TransactionLimit = limit
 	}
 }
+
+func WithMaxBlocks(limit uint) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.BlockLimit = limit
+	}
+}
+
+func WithMaxScan(limit uint) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.SearchLimit = limit
+	}
+}
+
+func WithMaxRewards(limit uint) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.RewardLimit = limit
+	}
+}
+
+func WithPaths(paths ...string) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.BalancePaths = paths
+	}
+}
+
+func WithHistory(migrations ...Migration) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.Migrations = migrations
+	}
+}
+
+func WithCachedBlocks(cache Cache) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.BlockCache = cache
+	}
+}
+
+func WithWorkers(workers uint) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.Workers = workers
+	}
+}
+
+func WithStoredBlocks(store Store) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.BlockStore = store
+	}
+}
+
+func WithTracker(track Tracker) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.Tracker = track
+	}
+}
+
+func WithLocked(enabled bool) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.LockedTokens = enabled
+	}
+}
+
+func WithMachines(enabled bool) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.MachineAccounts = enabled
+	}
+}
+
+func WithStorage(enabled bool) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.StorageUsage = enabled
+	}
+}
+
+func WithAccounts(history History) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.History = history
+	}
+}
+
+func WithAllowed(types ...string) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.OperationTypes = types
+	}
+}
+
+func WithRegistry(intents Intents) func(*Retriever) {
+	return func(retriever *Retriever) {
+		retriever.cfg.Intents = intents
+	}
+}
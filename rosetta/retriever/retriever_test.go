@@ -15,18 +15,39 @@
 package retriever_test
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/onflow/cadence"
+	cjson "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/flow-go/model/flow"
 
+	api "github.com/optakt/flow-dps/api/dps"
+	"github.com/optakt/flow-dps/codec/zbor"
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/history"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/intents"
+	"github.com/optakt/flow-rosetta/rosetta/memory"
 	"github.com/optakt/flow-rosetta/rosetta/object"
 	"github.com/optakt/flow-rosetta/rosetta/retriever"
+	"github.com/optakt/flow-rosetta/rosetta/scripts"
+	validatorpkg "github.com/optakt/flow-rosetta/rosetta/validator"
 	"github.com/optakt/flow-rosetta/testing/mocks"
 )
 
@@ -139,6 +160,106 @@ func TestRetriever_Current(t *testing.T) {
 	})
 }
 
+func TestRetriever_BlockID(t *testing.T) {
+	header := mocks.GenericHeader
+	rosBlockID := mocks.GenericRosBlockID
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(got identifier.Block) (uint64, flow.Identifier, error) {
+			assert.Equal(t, identifier.Block{Index: &header.Height}, got)
+
+			return header.Height, header.ID(), nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		blockID, err := ret.BlockID(identifier.Block{Index: &header.Height})
+
+		require.NoError(t, err)
+		assert.Equal(t, rosBlockID, blockID)
+	})
+
+	t.Run("handles invalid block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, err := ret.BlockID(rosBlockID)
+		assert.Error(t, err)
+	})
+}
+
+func TestRetriever_Child(t *testing.T) {
+	header := mocks.GenericHeader
+	rosBlockID := mocks.GenericRosBlockID
+	childHeight := header.Height + 1
+	childID := mocks.GenericBlockIDs(2)[1]
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(got identifier.Block) (uint64, flow.Identifier, error) {
+			if got.Index != nil && *got.Index == childHeight {
+				return childHeight, childID, nil
+			}
+
+			assert.Equal(t, rosBlockID, got)
+
+			return header.Height, header.ID(), nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		blockID, child, err := ret.Child(rosBlockID)
+
+		require.NoError(t, err)
+		assert.Equal(t, rosBlockID, blockID)
+		require.NotNil(t, child.Index)
+		assert.Equal(t, childHeight, *child.Index)
+		assert.Equal(t, childID.String(), child.Hash)
+	})
+
+	t.Run("handles invalid block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, _, err := ret.Child(rosBlockID)
+		assert.Error(t, err)
+	})
+
+	t.Run("handles child that is not indexed yet", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(got identifier.Block) (uint64, flow.Identifier, error) {
+			if got.Index != nil && *got.Index == childHeight {
+				return 0, flow.ZeroID, failure.UnknownBlock{Index: childHeight}
+			}
+			return header.Height, header.ID(), nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, _, err := ret.Child(rosBlockID)
+		assert.ErrorAs(t, err, &failure.UnknownBlock{})
+	})
+}
+
 func TestRetriever_Balances(t *testing.T) {
 	header := mocks.GenericHeader
 	account := mocks.GenericAccount
@@ -208,6 +329,49 @@ func TestRetriever_Balances(t *testing.T) {
 		assert.Equal(t, wantAmounts, amounts)
 	})
 
+	t.Run("includes contract metadata for non-FLOW tokens", func(t *testing.T) {
+		t.Parallel()
+
+		token := dps.Token{
+			Symbol:  "FUSD",
+			Address: flow.HexToAddress("3c5959b568896393"),
+			Type:    "FUSD",
+		}
+		params := mocks.GenericParams
+		params.Tokens = map[string]dps.Token{
+			dps.FlowSymbol: {Symbol: dps.FlowSymbol, Type: "FlowToken"},
+			token.Symbol:   token,
+		}
+
+		flowCurrency := identifier.Currency{Symbol: dps.FlowSymbol, Decimals: dps.FlowDecimals}
+		fusdCurrency := identifier.Currency{Symbol: token.Symbol, Decimals: dps.FlowDecimals}
+
+		validator := mocks.BaselineValidator(t)
+		validator.CurrencyFunc = func(currency identifier.Currency) (string, uint, error) {
+			return currency.Symbol, currency.Decimals, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithValidator(validator),
+			retriever.WithParams(params),
+		)
+
+		_, amounts, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{flowCurrency, fusdCurrency},
+		)
+
+		require.NoError(t, err)
+		require.Len(t, amounts, 2)
+		assert.Nil(t, amounts[0].Currency.Metadata)
+		require.NotNil(t, amounts[1].Currency.Metadata)
+		assert.Equal(t, "3c5959b568896393", amounts[1].Currency.Metadata.ContractAddress)
+		assert.Equal(t, "FUSD", amounts[1].Currency.Metadata.ContractName)
+		assert.Equal(t, "A.3c5959b568896393.FUSD.Vault", amounts[1].Currency.Metadata.VaultType)
+	})
+
 	t.Run("handles invalid block", func(t *testing.T) {
 		t.Parallel()
 
@@ -297,336 +461,3746 @@ func TestRetriever_Balances(t *testing.T) {
 		)
 		assert.Error(t, err)
 	})
-}
-
-func TestRetriever_Block(t *testing.T) {
-	header := mocks.GenericHeader
-	rosBlockID := mocks.GenericRosBlockID
-
-	transactions := mocks.GenericTransactionIDs(5)
-	withdrawalType := mocks.GenericEventType(0)
-	depositType := mocks.GenericEventType(1)
-	withdrawals := mocks.GenericEvents(2, withdrawalType)
-	deposits := mocks.GenericEvents(2, depositType)
-	events := append(withdrawals, deposits...)
 
-	t.Run("nominal case with limit not reached", func(t *testing.T) {
+	t.Run("aggregates vaults on configured balance paths", func(t *testing.T) {
 		t.Parallel()
 
-		index := mocks.BaselineReader(t)
-		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
-			assert.Equal(t, header.Height, height)
-
-			return header, nil
-		}
-		index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
-			assert.Equal(t, header.Height, height)
-
-			return transactions, nil
-		}
-		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
-			assert.Equal(t, header.Height, height)
-
-			return events, nil
-		}
-
-		validator := mocks.BaselineValidator(t)
-		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
-			assert.Equal(t, rosBlockID, rosBlockID)
-
-			return header.Height, header.ID(), nil
-		}
+		paths := []string{"/public/extraBalance"}
 
 		generator := mocks.BaselineGenerator(t)
-		generator.TokensDepositedFunc = func(symbol string) (string, error) {
-			assert.Equal(t, symbol, dps.FlowSymbol)
-
-			return string(withdrawalType), nil
+		generator.GetBalanceFunc = func(string) ([]byte, error) {
+			t.Error("default balance script should not be generated")
+			return nil, mocks.GenericError
 		}
-		generator.TokensWithdrawnFunc = func(symbol string) (string, error) {
-			assert.Equal(t, symbol, dps.FlowSymbol)
+		generator.GetVaultBalancesFunc = func(symbol string, got []string) ([]byte, error) {
+			assert.Equal(t, currency.Symbol, symbol)
+			assert.Equal(t, paths, got)
 
-			return string(depositType), nil
+			return []byte(`test`), nil
 		}
 
-		convert := mocks.BaselineConverter(t)
-		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
-			var op object.Operation
-			switch event.Type {
-			case withdrawalType:
-				assert.Contains(t, withdrawals, event)
-				op = mocks.GenericOperation(0)
-			case depositType:
-				assert.Contains(t, deposits, event)
-				op = mocks.GenericOperation(1)
-			}
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+			assert.Equal(t, []byte(`test`), script)
+			require.Len(t, parameters, 1)
+			assert.Equal(t, address, parameters[0])
 
-			return &op, nil
+			vaults := cadence.NewDictionary([]cadence.KeyValuePair{
+				{Key: cadence.String("/public/flowTokenBalance"), Value: cadence.UFix64(300)},
+				{Key: cadence.String("/public/extraBalance"), Value: cadence.UFix64(42)},
+			})
+
+			return vaults, nil
 		}
 
 		ret := retriever.BaselineRetriever(
 			t,
 			retriever.WithGenerator(generator),
-			retriever.WithIndex(index),
-			retriever.WithValidator(validator),
-			retriever.WithConverter(convert),
-			retriever.WithLimit(6),
+			retriever.WithInvoker(invoker),
+			retriever.WithPaths(paths...),
 		)
 
-		block, extra, err := ret.Block(rosBlockID)
+		_, amounts, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
 
 		require.NoError(t, err)
-		assert.Equal(t, rosBlockID, block.ID)
-		assert.Len(t, block.Transactions, 5)
-
-		assert.Empty(t, extra)
+		require.Len(t, amounts, 1)
+		assert.Equal(t, "342", amounts[0].Value)
+		require.NotNil(t, amounts[0].Metadata)
+		wantVaults := []object.VaultBalance{
+			{Path: "/public/extraBalance", Value: "42"},
+			{Path: "/public/flowTokenBalance", Value: "300"},
+		}
+		assert.Equal(t, wantVaults, amounts[0].Metadata.Vaults)
 	})
 
-	t.Run("nominal case with limit reached exactly", func(t *testing.T) {
+	t.Run("aggregates vaults beyond the range of a single balance", func(t *testing.T) {
 		t.Parallel()
 
-		index := mocks.BaselineReader(t)
-		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
-			assert.Equal(t, header.Height, height)
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			vaults := cadence.NewDictionary([]cadence.KeyValuePair{
+				{Key: cadence.String("/public/flowTokenBalance"), Value: cadence.UFix64(math.MaxUint64)},
+				{Key: cadence.String("/public/extraBalance"), Value: cadence.UFix64(math.MaxUint64)},
+			})
 
-			return mocks.GenericHeader, nil
+			return vaults, nil
 		}
-		index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
-			assert.Equal(t, header.Height, height)
 
-			return mocks.GenericTransactionIDs(5), nil
-		}
-		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
-			assert.Equal(t, header.Height, height)
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithInvoker(invoker),
+			retriever.WithPaths("/public/extraBalance"),
+		)
 
-			return events, nil
-		}
+		_, amounts, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
 
-		validator := mocks.BaselineValidator(t)
-		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
-			assert.Equal(t, rosBlockID, rosBlockID)
+		require.NoError(t, err)
+		require.Len(t, amounts, 1)
+		assert.Equal(t, "36893488147419103230", amounts[0].Value)
+	})
 
-			return header.Height, header.ID(), nil
-		}
+	t.Run("handles vault balances generate failure", func(t *testing.T) {
+		t.Parallel()
 
 		generator := mocks.BaselineGenerator(t)
-		generator.TokensDepositedFunc = func(symbol string) (string, error) {
-			assert.Equal(t, symbol, dps.FlowSymbol)
-
-			return string(depositType), nil
-		}
-		generator.TokensWithdrawnFunc = func(symbol string) (string, error) {
-			assert.Equal(t, symbol, dps.FlowSymbol)
-
-			return string(withdrawalType), nil
-		}
-
-		convert := mocks.BaselineConverter(t)
-		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
-
-			var op object.Operation
-			switch event.Type {
-			case withdrawalType:
-				assert.Contains(t, withdrawals, event)
-				op = mocks.GenericOperation(0)
-			case depositType:
-				assert.Contains(t, deposits, event)
-				op = mocks.GenericOperation(1)
-			}
-
-			return &op, nil
+		generator.GetVaultBalancesFunc = func(string, []string) ([]byte, error) {
+			return nil, mocks.GenericError
 		}
 
 		ret := retriever.BaselineRetriever(
 			t,
 			retriever.WithGenerator(generator),
-			retriever.WithIndex(index),
-			retriever.WithValidator(validator),
-			retriever.WithConverter(convert),
-			retriever.WithLimit(5),
+			retriever.WithPaths("/public/extraBalance"),
 		)
 
-		block, extra, err := ret.Block(rosBlockID)
-
-		require.NoError(t, err)
-		assert.Len(t, block.Transactions, 5)
-		assert.Equal(t, rosBlockID, block.ID)
-
-		assert.Empty(t, extra)
+		_, _, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
+		assert.Error(t, err)
 	})
 
-	t.Run("nominal case with more transactions than limit", func(t *testing.T) {
+	t.Run("handles vault balances invoker failure", func(t *testing.T) {
 		t.Parallel()
 
-		index := mocks.BaselineReader(t)
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithInvoker(invoker),
+			retriever.WithPaths("/public/extraBalance"),
+		)
+
+		_, _, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("handles unexpected vault balances result", func(t *testing.T) {
+		t.Parallel()
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithPaths("/public/extraBalance"),
+		)
+
+		_, _, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("reports locked account balance", func(t *testing.T) {
+		t.Parallel()
+
+		locked := mocks.GenericAccountID(1)
+
+		generator := mocks.BaselineGenerator(t)
+		generator.GetBalanceFunc = func(string) ([]byte, error) {
+			return []byte(`balance`), nil
+		}
+		generator.GetLockedAccountFunc = func() ([]byte, error) {
+			return []byte(`locked`), nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+			require.Len(t, parameters, 1)
+			assert.Equal(t, address, parameters[0])
+
+			if string(script) == `balance` {
+				return mocks.GenericAmount(0), nil
+			}
+
+			info := cadence.NewStruct([]cadence.Value{
+				cadence.NewAddress(flow.HexToAddress(locked.Address)),
+				cadence.UFix64(500),
+				cadence.UFix64(100),
+			}).WithType(&cadence.StructType{
+				Fields: []cadence.Field{
+					{Identifier: "address", Type: cadence.AddressType{}},
+					{Identifier: "balance", Type: cadence.UFix64Type{}},
+					{Identifier: "unlockLimit", Type: cadence.UFix64Type{}},
+				},
+			})
+
+			return cadence.NewOptional(info), nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithInvoker(invoker),
+			retriever.WithLocked(true),
+		)
+
+		_, amounts, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
+
+		require.NoError(t, err)
+		require.Len(t, amounts, 1)
+		assert.Equal(t, mocks.GenericAmount(0).String(), amounts[0].Value)
+		require.NotNil(t, amounts[0].Metadata)
+		wantLocked := &object.LockedBalance{
+			Account:     locked,
+			Value:       "500",
+			UnlockLimit: "100",
+		}
+		assert.Equal(t, wantLocked, amounts[0].Metadata.Locked)
+	})
+
+	t.Run("omits locked balance for accounts without locked account", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.GetBalanceFunc = func(string) ([]byte, error) {
+			return []byte(`balance`), nil
+		}
+		generator.GetLockedAccountFunc = func() ([]byte, error) {
+			return []byte(`locked`), nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(_ uint64, script []byte, _ []cadence.Value) (cadence.Value, error) {
+			if string(script) == `balance` {
+				return mocks.GenericAmount(0), nil
+			}
+			return cadence.NewOptional(nil), nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithInvoker(invoker),
+			retriever.WithLocked(true),
+		)
+
+		_, amounts, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
+
+		require.NoError(t, err)
+		require.Len(t, amounts, 1)
+		assert.Nil(t, amounts[0].Metadata)
+	})
+
+	t.Run("handles locked account invoker failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.GetBalanceFunc = func(string) ([]byte, error) {
+			return []byte(`balance`), nil
+		}
+		generator.GetLockedAccountFunc = func() ([]byte, error) {
+			return []byte(`locked`), nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(_ uint64, script []byte, _ []cadence.Value) (cadence.Value, error) {
+			if string(script) == `balance` {
+				return mocks.GenericAmount(0), nil
+			}
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithInvoker(invoker),
+			retriever.WithLocked(true),
+		)
+
+		_, _, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("reports machine account balances", func(t *testing.T) {
+		t.Parallel()
+
+		machineType := &cadence.StructType{
+			Fields: []cadence.Field{
+				{Identifier: "nodeID", Type: cadence.StringType{}},
+				{Identifier: "role", Type: cadence.UInt8Type{}},
+				{Identifier: "address", Type: cadence.AddressType{}},
+				{Identifier: "balance", Type: cadence.UFix64Type{}},
+			},
+		}
+		consensus := mocks.GenericAccountID(1)
+		collection := mocks.GenericAccountID(2)
+
+		generator := mocks.BaselineGenerator(t)
+		generator.GetBalanceFunc = func(string) ([]byte, error) {
+			return []byte(`balance`), nil
+		}
+		generator.GetMachineAccountsFunc = func() ([]byte, error) {
+			return []byte(`machines`), nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+			require.Len(t, parameters, 1)
+			assert.Equal(t, address, parameters[0])
+
+			if string(script) == `balance` {
+				return mocks.GenericAmount(0), nil
+			}
+
+			machines := cadence.NewArray([]cadence.Value{
+				cadence.NewStruct([]cadence.Value{
+					cadence.String("node2"),
+					cadence.UInt8(flow.RoleConsensus),
+					cadence.NewAddress(flow.HexToAddress(consensus.Address)),
+					cadence.UFix64(20),
+				}).WithType(machineType),
+				cadence.NewStruct([]cadence.Value{
+					cadence.String("node1"),
+					cadence.UInt8(flow.RoleCollection),
+					cadence.NewAddress(flow.HexToAddress(collection.Address)),
+					cadence.UFix64(10),
+				}).WithType(machineType),
+			})
+
+			return machines, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithInvoker(invoker),
+			retriever.WithMachines(true),
+		)
+
+		_, amounts, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
+
+		require.NoError(t, err)
+		require.Len(t, amounts, 1)
+		assert.Equal(t, mocks.GenericAmount(0).String(), amounts[0].Value)
+		require.NotNil(t, amounts[0].Metadata)
+		wantMachines := []object.MachineBalance{
+			{Account: collection, NodeID: "node1", Role: flow.RoleCollection.String(), Value: "10"},
+			{Account: consensus, NodeID: "node2", Role: flow.RoleConsensus.String(), Value: "20"},
+		}
+		assert.Equal(t, wantMachines, amounts[0].Metadata.Machines)
+	})
+
+	t.Run("handles unknown machine account role", func(t *testing.T) {
+		t.Parallel()
+
+		machineType := &cadence.StructType{
+			Fields: []cadence.Field{
+				{Identifier: "nodeID", Type: cadence.StringType{}},
+				{Identifier: "role", Type: cadence.UInt8Type{}},
+				{Identifier: "address", Type: cadence.AddressType{}},
+				{Identifier: "balance", Type: cadence.UFix64Type{}},
+			},
+		}
+
+		generator := mocks.BaselineGenerator(t)
+		generator.GetBalanceFunc = func(string) ([]byte, error) {
+			return []byte(`balance`), nil
+		}
+		generator.GetMachineAccountsFunc = func() ([]byte, error) {
+			return []byte(`machines`), nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(_ uint64, script []byte, _ []cadence.Value) (cadence.Value, error) {
+			if string(script) == `balance` {
+				return mocks.GenericAmount(0), nil
+			}
+
+			machines := cadence.NewArray([]cadence.Value{
+				cadence.NewStruct([]cadence.Value{
+					cadence.String("node1"),
+					cadence.UInt8(0),
+					cadence.NewAddress(flow.HexToAddress(mocks.GenericAccountID(1).Address)),
+					cadence.UFix64(10),
+				}).WithType(machineType),
+			})
+
+			return machines, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithInvoker(invoker),
+			retriever.WithMachines(true),
+		)
+
+		assert.NotPanics(t, func() {
+			_, _, err := ret.Balances(
+				rosBlockID,
+				accountID,
+				[]identifier.Currency{currency},
+			)
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("handles machine accounts invoker failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.GetBalanceFunc = func(string) ([]byte, error) {
+			return []byte(`balance`), nil
+		}
+		generator.GetMachineAccountsFunc = func() ([]byte, error) {
+			return []byte(`machines`), nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(_ uint64, script []byte, _ []cadence.Value) (cadence.Value, error) {
+			if string(script) == `balance` {
+				return mocks.GenericAmount(0), nil
+			}
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithInvoker(invoker),
+			retriever.WithMachines(true),
+		)
+
+		_, _, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("reports storage usage", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.GetBalanceFunc = func(string) ([]byte, error) {
+			return []byte(`balance`), nil
+		}
+		generator.GetStorageInfoFunc = func() ([]byte, error) {
+			return []byte(`storage`), nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+			require.Len(t, parameters, 1)
+			assert.Equal(t, address, parameters[0])
+
+			if string(script) == `balance` {
+				return mocks.GenericAmount(0), nil
+			}
+
+			info := cadence.NewStruct([]cadence.Value{
+				cadence.UInt64(4096),
+				cadence.UInt64(100000),
+				cadence.UFix64(100000),
+			}).WithType(&cadence.StructType{
+				Fields: []cadence.Field{
+					{Identifier: "used", Type: cadence.UInt64Type{}},
+					{Identifier: "capacity", Type: cadence.UInt64Type{}},
+					{Identifier: "reserved", Type: cadence.UFix64Type{}},
+				},
+			})
+
+			return info, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithInvoker(invoker),
+			retriever.WithStorage(true),
+		)
+
+		_, amounts, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
+
+		require.NoError(t, err)
+		require.Len(t, amounts, 1)
+		require.NotNil(t, amounts[0].Metadata)
+		wantStorage := &object.StorageUsage{
+			Used:        4096,
+			Capacity:    100000,
+			Reservation: "100000",
+		}
+		assert.Equal(t, wantStorage, amounts[0].Metadata.Storage)
+	})
+
+	t.Run("handles storage info invoker failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.GetBalanceFunc = func(string) ([]byte, error) {
+			return []byte(`balance`), nil
+		}
+		generator.GetStorageInfoFunc = func() ([]byte, error) {
+			return []byte(`storage`), nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(_ uint64, script []byte, _ []cadence.Value) (cadence.Value, error) {
+			if string(script) == `balance` {
+				return mocks.GenericAmount(0), nil
+			}
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithInvoker(invoker),
+			retriever.WithStorage(true),
+		)
+
+		_, _, err := ret.Balances(
+			rosBlockID,
+			accountID,
+			[]identifier.Currency{currency},
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestRetriever_Block(t *testing.T) {
+	header := mocks.GenericHeader
+	rosBlockID := mocks.GenericRosBlockID
+
+	transactions := mocks.GenericTransactionIDs(5)
+	withdrawalType := mocks.GenericEventType(0)
+	depositType := mocks.GenericEventType(1)
+	withdrawals := mocks.GenericEvents(2, withdrawalType)
+	deposits := mocks.GenericEvents(2, depositType)
+	events := append(withdrawals, deposits...)
+
+	t.Run("nominal case with limit not reached", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			assert.Equal(t, header.Height, height)
+
+			return header, nil
+		}
+		index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+			assert.Equal(t, header.Height, height)
+
+			return transactions, nil
+		}
+		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+			assert.Equal(t, header.Height, height)
+
+			return events, nil
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+			assert.Equal(t, rosBlockID, rosBlockID)
+
+			return header.Height, header.ID(), nil
+		}
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensDepositedFunc = func(symbol string) (string, error) {
+			assert.Equal(t, symbol, dps.FlowSymbol)
+
+			return string(withdrawalType), nil
+		}
+		generator.TokensWithdrawnFunc = func(symbol string) (string, error) {
+			assert.Equal(t, symbol, dps.FlowSymbol)
+
+			return string(depositType), nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+			var op object.Operation
+			switch event.Type {
+			case withdrawalType:
+				assert.Contains(t, withdrawals, event)
+				op = mocks.GenericOperation(0)
+			case depositType:
+				assert.Contains(t, deposits, event)
+				op = mocks.GenericOperation(1)
+			}
+
+			return &op, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithLimit(6),
+		)
+
+		block, extra, err := ret.Block(rosBlockID)
+
+		require.NoError(t, err)
+		assert.Equal(t, rosBlockID, block.ID)
+		assert.Len(t, block.Transactions, 5)
+
+		assert.Empty(t, extra)
+	})
+
+	t.Run("nominal case with migrated contracts", func(t *testing.T) {
+		t.Parallel()
+
+		legacyType := mocks.GenericEventType(4)
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+			// Service events are looked up separately from transfers.
+			if types[0] == mocks.GenericEventType(10) {
+				return nil, nil
+			}
+			assert.Contains(t, types, legacyType)
+
+			return mocks.GenericEvents(1, legacyType), nil
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return header.Height, header.ID(), nil
+		}
+
+		legacy := mocks.BaselineGenerator(t)
+		legacy.TokensDepositedFunc = func(string) (string, error) {
+			return string(legacyType), nil
+		}
+
+		current := mocks.BaselineGenerator(t)
+		current.TokensDepositedFunc = func(string) (string, error) {
+			t.Error("current generator used for historical height")
+			return string(depositType), nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(current),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithHistory(
+				retriever.Migration{Height: header.Height - 1, Generate: current},
+				retriever.Migration{Height: header.Height + 1, Generate: legacy},
+			),
+		)
+
+		block, _, err := ret.Block(rosBlockID)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, block.Transactions)
+	})
+
+	t.Run("nominal case with cached block", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		index := mocks.BaselineReader(t)
+		index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			calls++
+			return transactions, nil
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return header.Height, header.ID(), nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithCachedBlocks(memory.NewLRU("blocks", 1<<20, memory.NewMetrics(prometheus.NewRegistry()))),
+		)
+
+		first, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+		second, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		assert.Same(t, first, second)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("nominal case with stored block", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			t.Fail()
+			return nil, nil
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return header.Height, header.ID(), nil
+		}
+
+		stored := &object.Block{ID: rosBlockID}
+		other := []identifier.Transaction{{Hash: "other"}}
+		store := mocks.BaselineBlockStore(t)
+		store.BlockFunc = func(height uint64) (*object.Block, []identifier.Transaction, bool) {
+			assert.Equal(t, header.Height, height)
+			return stored, other, true
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithStoredBlocks(store),
+		)
+
+		block, extra, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		assert.Same(t, stored, block)
+		assert.Equal(t, other, extra)
+	})
+
+	t.Run("nominal case with block saved to store", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return header.Height, header.ID(), nil
+		}
+
+		var saved *object.Block
+		store := mocks.BaselineBlockStore(t)
+		store.SaveFunc = func(height uint64, block *object.Block, _ []identifier.Transaction) {
+			assert.Equal(t, header.Height, height)
+			saved = block
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithValidator(validator),
+			retriever.WithStoredBlocks(store),
+		)
+
+		block, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		assert.Same(t, block, saved)
+	})
+
+	t.Run("nominal case with unsealed block and soft finality", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return header.Height, header.ID(), nil
+		}
+
+		var saved *object.Block
+		store := mocks.BaselineBlockStore(t)
+		store.SaveFunc = func(_ uint64, block *object.Block, _ []identifier.Transaction) {
+			saved = block
+		}
+
+		tracker := mocks.BaselineTracker(t)
+		tracker.SealedFunc = func() (uint64, error) {
+			return header.Height - 1, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithValidator(validator),
+			retriever.WithStoredBlocks(store),
+			retriever.WithTracker(tracker),
+		)
+
+		block, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		require.NotNil(t, block.Metadata)
+		assert.False(t, block.Metadata.Sealed)
+		require.NotNil(t, saved)
+		require.NotNil(t, saved.Metadata)
+		assert.True(t, saved.Metadata.Sealed)
+	})
+
+	t.Run("nominal case with sealed block and soft finality", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return header.Height, header.ID(), nil
+		}
+
+		tracker := mocks.BaselineTracker(t)
+		tracker.SealedFunc = func() (uint64, error) {
+			return header.Height, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithValidator(validator),
+			retriever.WithTracker(tracker),
+		)
+
+		block, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		require.NotNil(t, block.Metadata)
+		assert.True(t, block.Metadata.Sealed)
+	})
+
+	t.Run("includes collection guarantees and chunks", func(t *testing.T) {
+		t.Parallel()
+
+		collIDs := mocks.GenericCollectionIDs(2)
+		signerIDs := mocks.GenericNodeIDs(2)
+
+		index := mocks.BaselineReader(t)
+		index.CollectionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+			assert.Equal(t, header.Height, height)
+
+			return collIDs, nil
+		}
+		index.GuaranteeFunc = func(collID flow.Identifier) (*flow.CollectionGuarantee, error) {
+			guarantee := flow.CollectionGuarantee{
+				CollectionID:     collID,
+				ReferenceBlockID: header.ID(),
+				SignerIDs:        signerIDs,
+			}
+			return &guarantee, nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		block, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		require.NotNil(t, block.Metadata)
+		require.Len(t, block.Metadata.Collections, 2)
+		for i, collection := range block.Metadata.Collections {
+			assert.Equal(t, collIDs[i].String(), collection.ID)
+			assert.Equal(t, header.ID().String(), collection.ReferenceBlock)
+			assert.Equal(t, []string{signerIDs[0].String(), signerIDs[1].String()}, collection.Guarantors)
+		}
+		assert.Equal(t, uint(3), block.Metadata.Chunks)
+	})
+
+	t.Run("handles index.CollectionsByHeight failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.CollectionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, _, err := ret.Block(rosBlockID)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles index.Guarantee failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.GuaranteeFunc = func(flow.Identifier) (*flow.CollectionGuarantee, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, _, err := ret.Block(rosBlockID)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("includes seals", func(t *testing.T) {
+		t.Parallel()
+
+		seals := mocks.GenericSeals(2)
+		sealIDs := mocks.GenericSealIDs(2)
+
+		index := mocks.BaselineReader(t)
+		index.SealsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+			assert.Equal(t, header.Height, height)
+
+			return sealIDs, nil
+		}
+		index.SealFunc = func(sealID flow.Identifier) (*flow.Seal, error) {
+			for i, id := range sealIDs {
+				if id == sealID {
+					return seals[i], nil
+				}
+			}
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		block, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		require.NotNil(t, block.Metadata)
+		require.Len(t, block.Metadata.Seals, 2)
+		for i, seal := range block.Metadata.Seals {
+			assert.Equal(t, sealIDs[i].String(), seal.ID)
+			assert.Equal(t, seals[i].BlockID.String(), seal.BlockID)
+			assert.Equal(t, seals[i].ResultID.String(), seal.ResultID)
+			assert.Equal(t, hex.EncodeToString(seals[i].FinalState[:]), seal.FinalState)
+		}
+	})
+
+	t.Run("includes service events", func(t *testing.T) {
+		t.Parallel()
+
+		setupType := mocks.GenericEventType(10)
+		commitType := mocks.GenericEventType(11)
+		events := []flow.Event{
+			{Type: commitType, TransactionIndex: 1, EventIndex: 0},
+			{Type: setupType, TransactionIndex: 0, EventIndex: 1},
+			{Type: mocks.GenericEventType(0), TransactionIndex: 0, EventIndex: 0},
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+			if types[0] != setupType {
+				return nil, nil
+			}
+			assert.Equal(t, header.Height, height)
+			assert.Equal(t, []flow.EventType{setupType, commitType}, types)
+
+			return events, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToServiceEventFunc = func(event flow.Event) (*object.ServiceEvent, error) {
+			service := object.ServiceEvent{Type: string(event.Type)}
+			return &service, nil
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithConverter(convert),
+		)
+
+		block, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		require.NotNil(t, block.Metadata)
+		want := []object.ServiceEvent{
+			{Type: string(setupType)},
+			{Type: string(commitType)},
+		}
+		assert.Equal(t, want, block.Metadata.ServiceEvents)
+	})
+
+	t.Run("handles service event conversion failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(_ uint64, types ...flow.EventType) ([]flow.Event, error) {
+			return mocks.GenericEvents(1, types[0]), nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToServiceEventFunc = func(flow.Event) (*object.ServiceEvent, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithConverter(convert),
+		)
+
+		_, _, err := ret.Block(rosBlockID)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles index.SealsByHeight failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.SealsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, _, err := ret.Block(rosBlockID)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("falls back to seal IDs of seals that can not be decoded", func(t *testing.T) {
+		t.Parallel()
+
+		sealIDs := mocks.GenericSealIDs(2)
+
+		index := mocks.BaselineReader(t)
+		index.SealsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return sealIDs, nil
+		}
+		index.SealFunc = func(flow.Identifier) (*flow.Seal, error) {
+			return nil, fmt.Errorf("could not decode seal: %w", &cbor.UnknownFieldError{Index: 3})
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		block, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		require.NotNil(t, block.Metadata)
+		want := []object.SealMetadata{
+			{ID: sealIDs[0].String()},
+			{ID: sealIDs[1].String()},
+		}
+		assert.Equal(t, want, block.Metadata.Seals)
+	})
+
+	t.Run("falls back to seal IDs of seals that the DPS API can not decode", func(t *testing.T) {
+		t.Parallel()
+
+		sealIDs := mocks.GenericSealIDs(2)
+
+		// The DPS server decodes the seals itself, so the CBOR error only
+		// reaches the API-backed index as the message of the gRPC status.
+		remote := mocks.BaselineReader(t)
+		remote.SealsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return sealIDs, nil
+		}
+		remote.SealFunc = func(flow.Identifier) (*flow.Seal, error) {
+			return nil, fmt.Errorf("could not decode value: %w", &cbor.UnknownFieldError{Index: 3})
+		}
+		server := grpc.NewServer()
+		api.RegisterAPIServer(server, api.NewServer(remote, zbor.NewCodec()))
+		listener := bufconn.Listen(1 << 20)
+		go func() { _ = server.Serve(listener) }()
+		defer server.Stop()
+
+		conn, err := grpc.Dial("bufconn",
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		require.NoError(t, err)
+		defer conn.Close()
+		client := api.IndexFromAPI(api.NewAPIClient(conn), zbor.NewCodec())
+
+		index := mocks.BaselineReader(t)
+		index.SealsByHeightFunc = client.SealsByHeight
+		index.SealFunc = client.Seal
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		block, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		require.NotNil(t, block.Metadata)
+		want := []object.SealMetadata{
+			{ID: sealIDs[0].String()},
+			{ID: sealIDs[1].String()},
+		}
+		assert.Equal(t, want, block.Metadata.Seals)
+	})
+
+	t.Run("handles index.Seal failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.SealsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return mocks.GenericSealIDs(2), nil
+		}
+		index.SealFunc = func(flow.Identifier) (*flow.Seal, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, _, err := ret.Block(rosBlockID)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+
+	t.Run("flags block when sealed height is unknown with soft finality", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return header.Height, header.ID(), nil
+		}
+
+		tracker := mocks.BaselineTracker(t)
+		tracker.SealedFunc = func() (uint64, error) {
+			return 0, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithValidator(validator),
+			retriever.WithTracker(tracker),
+		)
+
+		block, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+
+		require.NotNil(t, block.Metadata)
+		assert.False(t, block.Metadata.Sealed)
+	})
+
+	t.Run("nominal case with limit reached exactly", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			assert.Equal(t, header.Height, height)
+
+			return mocks.GenericHeader, nil
+		}
+		index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+			assert.Equal(t, header.Height, height)
+
+			return mocks.GenericTransactionIDs(5), nil
+		}
+		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+			assert.Equal(t, header.Height, height)
+
+			return events, nil
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+			assert.Equal(t, rosBlockID, rosBlockID)
+
+			return header.Height, header.ID(), nil
+		}
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensDepositedFunc = func(symbol string) (string, error) {
+			assert.Equal(t, symbol, dps.FlowSymbol)
+
+			return string(depositType), nil
+		}
+		generator.TokensWithdrawnFunc = func(symbol string) (string, error) {
+			assert.Equal(t, symbol, dps.FlowSymbol)
+
+			return string(withdrawalType), nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+
+			var op object.Operation
+			switch event.Type {
+			case withdrawalType:
+				assert.Contains(t, withdrawals, event)
+				op = mocks.GenericOperation(0)
+			case depositType:
+				assert.Contains(t, deposits, event)
+				op = mocks.GenericOperation(1)
+			}
+
+			return &op, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithLimit(5),
+		)
+
+		block, extra, err := ret.Block(rosBlockID)
+
+		require.NoError(t, err)
+		assert.Len(t, block.Transactions, 5)
+		assert.Equal(t, rosBlockID, block.ID)
+
+		assert.Empty(t, extra)
+	})
+
+	t.Run("nominal case with more transactions than limit", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			assert.Equal(t, header.Height, height)
+
+			return mocks.GenericHeader, nil
+		}
+		index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+			assert.Equal(t, header.Height, height)
+
+			return mocks.GenericTransactionIDs(6), nil
+		}
+		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+			assert.Equal(t, mocks.GenericHeight, height)
+
+			return events, nil
+		}
+		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+			assert.Equal(t, header.Height, height)
+
+			return events, nil
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+			assert.Equal(t, rosBlockID, rosBlockID)
+
+			return header.Height, header.ID(), nil
+		}
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensDepositedFunc = func(symbol string) (string, error) {
+			assert.Equal(t, symbol, dps.FlowSymbol)
+
+			return string(depositType), nil
+		}
+		generator.TokensWithdrawnFunc = func(symbol string) (string, error) {
+			assert.Equal(t, symbol, dps.FlowSymbol)
+
+			return string(withdrawalType), nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+
+			var op object.Operation
+			switch event.Type {
+			case withdrawalType:
+				assert.Contains(t, withdrawals, event)
+				op = mocks.GenericOperation(0)
+			case depositType:
+				assert.Contains(t, deposits, event)
+				op = mocks.GenericOperation(1)
+			}
+
+			return &op, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithLimit(5),
+		)
+
+		block, extra, err := ret.Block(rosBlockID)
+
+		require.NoError(t, err)
+		assert.Equal(t, rosBlockID, block.ID)
+		assert.Len(t, block.Transactions, 5)
+
+		assert.Len(t, extra, 1)
+	})
+
+	t.Run("handles block without transactions", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return []flow.Identifier{}, nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		got, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Transactions)
+	})
+
+	t.Run("nominal case with parallel conversion", func(t *testing.T) {
+		t.Parallel()
+
+		sequential := retriever.BaselineRetriever(t)
+		parallel := retriever.BaselineRetriever(t, retriever.WithWorkers(4))
+
+		want, wantExtra, err := sequential.Block(rosBlockID)
+		require.NoError(t, err)
+		got, gotExtra, err := parallel.Block(rosBlockID)
+		require.NoError(t, err)
+
+		require.NotEmpty(t, got.Transactions)
+		assert.Equal(t, want, got)
+		assert.Equal(t, wantExtra, gotExtra)
+	})
+
+	t.Run("handles converter failure with parallel conversion", func(t *testing.T) {
+		t.Parallel()
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithConverter(convert),
+			retriever.WithWorkers(4),
+		)
+
+		_, _, err := ret.Block(rosBlockID)
+		assert.Error(t, err)
+	})
+
+	t.Run("handles block without relevant events", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return []flow.Event{}, nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		got, _, err := ret.Block(rosBlockID)
+		require.NoError(t, err)
+		require.NotEmpty(t, got.Transactions)
+		for _, tx := range got.Transactions {
+			assert.Empty(t, tx.Operations)
+		}
+	})
+
+	t.Run("handles invalid block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, _, err := ret.Block(rosBlockID)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles deposit script generate failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensDepositedFunc = func(string) (string, error) {
+			return "", mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithGenerator(generator))
+
+		_, _, err := ret.Block(rosBlockID)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles withdrawal script generate failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensWithdrawnFunc = func(string) (string, error) {
+			return "", mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithGenerator(generator))
+
+		_, _, err := ret.Block(rosBlockID)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles index header retrieval failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeaderFunc = func(uint64) (*flow.Header, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, _, err := ret.Block(rosBlockID)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles index event retrieval failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, _, err := ret.Block(rosBlockID)
+		assert.Error(t, err)
+	})
+
+	t.Run("handles event converter failure", func(t *testing.T) {
+		t.Parallel()
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithConverter(convert))
+
+		_, _, err := ret.Block(rosBlockID)
+		assert.Error(t, err)
+	})
+}
+
+func TestRetriever_AccountTransactions(t *testing.T) {
+	header := mocks.GenericHeader
+	accountID := mocks.GenericAccountID(0)
+	address := mocks.GenericAddress(0)
+	txIDs := mocks.GenericTransactionIDs(2)
+	rosBlockID := mocks.GenericRosBlockID
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := mocks.BaselineHistory(t)
+		accounts.RangeFunc = func() (uint64, uint64, bool) {
+			return header.Height - 10, header.Height + 10, true
+		}
+		accounts.TransactionsFunc = func(gotAddress flow.Address, start uint64, index uint32, end uint64, limit uint) ([]history.Reference, error) {
+			assert.Equal(t, address, gotAddress)
+			assert.Equal(t, header.Height-10, start)
+			assert.Equal(t, uint32(3), index)
+			assert.Equal(t, header.Height+10, end)
+			assert.Equal(t, uint(2), limit)
+
+			refs := []history.Reference{
+				{Height: header.Height, Index: 3, BlockID: header.ID(), TxID: txIDs[0]},
+				{Height: header.Height + 1, Index: 0, BlockID: header.ID(), TxID: txIDs[1]},
+			}
+			return refs, nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithAccounts(accounts))
+
+		transactions, next, nextIndex, err := ret.AccountTransactions(accountID, identifier.Block{}, identifier.Block{}, 3, 1)
+
+		require.NoError(t, err)
+		require.Len(t, transactions, 1)
+		assert.Equal(t, rosBlockID, transactions[0].BlockID)
+		require.NotNil(t, next)
+		assert.Equal(t, header.Height+1, *next.Index)
+		assert.Equal(t, uint(0), nextIndex)
+	})
+
+	t.Run("nominal case with last page", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := mocks.BaselineHistory(t)
+
+		ret := retriever.BaselineRetriever(t, retriever.WithAccounts(accounts))
+
+		transactions, next, _, err := ret.AccountTransactions(accountID, rosBlockID, rosBlockID, 0, 10)
+
+		require.NoError(t, err)
+		assert.Len(t, transactions, 1)
+		assert.Nil(t, next)
+	})
+
+	t.Run("handles missing history", func(t *testing.T) {
+		t.Parallel()
+
+		ret := retriever.BaselineRetriever(t)
+
+		_, _, _, err := ret.AccountTransactions(accountID, rosBlockID, rosBlockID, 0, 10)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles empty history", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := mocks.BaselineHistory(t)
+		accounts.RangeFunc = func() (uint64, uint64, bool) {
+			return 0, 0, false
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithAccounts(accounts))
+
+		_, _, _, err := ret.AccountTransactions(accountID, rosBlockID, rosBlockID, 0, 10)
+
+		assert.ErrorAs(t, err, &failure.UnavailableBlock{})
+	})
+
+	t.Run("handles start below history", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := mocks.BaselineHistory(t)
+		accounts.RangeFunc = func() (uint64, uint64, bool) {
+			return header.Height + 1, header.Height + 10, true
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithAccounts(accounts))
+
+		_, _, _, err := ret.AccountTransactions(accountID, rosBlockID, identifier.Block{}, 0, 10)
+
+		assert.ErrorAs(t, err, &failure.InvalidBlock{})
+	})
+
+	t.Run("handles end above history", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := mocks.BaselineHistory(t)
+		accounts.RangeFunc = func() (uint64, uint64, bool) {
+			return header.Height - 10, header.Height - 1, true
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithAccounts(accounts))
+
+		_, _, _, err := ret.AccountTransactions(accountID, identifier.Block{}, rosBlockID, 0, 10)
+
+		assert.ErrorAs(t, err, &failure.UnavailableBlock{})
+	})
+
+	t.Run("handles inverted range", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := mocks.BaselineHistory(t)
+		accounts.RangeFunc = func() (uint64, uint64, bool) {
+			return header.Height - 10, header.Height + 10, true
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+			return *rosBlockID.Index, header.ID(), nil
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithAccounts(accounts),
+			retriever.WithValidator(validator),
+		)
+
+		end := header.Height - 1
+		_, _, _, err := ret.AccountTransactions(accountID, rosBlockID, identifier.Block{Index: &end}, 0, 10)
+
+		assert.ErrorAs(t, err, &failure.InvalidBlock{})
+	})
+
+	t.Run("handles invalid account", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(identifier.Account) (flow.Address, error) {
+			return flow.EmptyAddress, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithAccounts(mocks.BaselineHistory(t)),
+			retriever.WithValidator(validator),
+		)
+
+		_, _, _, err := ret.AccountTransactions(accountID, rosBlockID, rosBlockID, 0, 10)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles history failure", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := mocks.BaselineHistory(t)
+		accounts.TransactionsFunc = func(flow.Address, uint64, uint32, uint64, uint) ([]history.Reference, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithAccounts(accounts))
+
+		_, _, _, err := ret.AccountTransactions(accountID, rosBlockID, rosBlockID, 0, 10)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRetriever_Transaction(t *testing.T) {
+	header := mocks.GenericHeader
+	rosBlockID := mocks.GenericRosBlockID
+
+	withdrawalType := mocks.GenericEventType(0)
+	depositType := mocks.GenericEventType(1)
+	withdrawals := mocks.GenericEvents(2, withdrawalType)
+	deposits := mocks.GenericEvents(2, depositType)
+	events := append(withdrawals, deposits...)
+
+	txQual := mocks.GenericTransactionQualifier(0)
+	txIDs := mocks.GenericTransactionIDs(5)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+			assert.Equal(t, rosBlockID, rosBlockID)
+
+			return header.Height, header.ID(), nil
+		}
+		validator.TransactionFunc = func(transaction identifier.Transaction) (flow.Identifier, error) {
+			assert.Equal(t, txQual, transaction)
+
+			return txIDs[0], nil
+		}
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensDepositedFunc = func(symbol string) (string, error) {
+			assert.Equal(t, dps.FlowSymbol, symbol)
+
+			return string(withdrawalType), nil
+		}
+		generator.TokensWithdrawnFunc = func(symbol string) (string, error) {
+			assert.Equal(t, dps.FlowSymbol, symbol)
+
+			return string(depositType), nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+			assert.Equal(t, header.Height, height)
+			require.Len(t, types, 10)
+			assert.Equal(t, withdrawalType, types[0])
+			assert.Equal(t, depositType, types[1])
+			assert.Equal(t, mocks.GenericEventType(12), types[2])
+			assert.Equal(t, mocks.GenericEventType(13), types[3])
+			assert.Equal(t, mocks.GenericEventType(8), types[4])
+			assert.Equal(t, flow.EventAccountCreated, types[5])
+			assert.Equal(t, flow.EventType("flow.AccountKeyAdded"), types[6])
+			assert.Equal(t, flow.EventType("flow.AccountContractAdded"), types[7])
+			assert.Equal(t, flow.EventType("flow.AccountContractUpdated"), types[8])
+			assert.Equal(t, flow.EventType("flow.AccountContractRemoved"), types[9])
+
+			return events, nil
+		}
+		index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+			assert.Equal(t, header.Height, height)
+
+			return txIDs, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+
+			var op object.Operation
+			switch event.Type {
+			case withdrawalType:
+				assert.Contains(t, withdrawals, event)
+				op = mocks.GenericOperation(0)
+			case depositType:
+				assert.Contains(t, deposits, event)
+				op = mocks.GenericOperation(1)
+			}
+
+			return &op, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		assert.Equal(t, txQual, got.ID)
+		assert.Len(t, got.Operations, 2)
+	})
+
+	t.Run("orders operations by event index and type", func(t *testing.T) {
+		t.Parallel()
+
+		// The events are returned out of order, with two events sharing an index
+		// so that the event type has to break the tie.
+		unordered := []flow.Event{
+			{TransactionID: txIDs[0], EventIndex: 3, Type: depositType},
+			{TransactionID: txIDs[0], EventIndex: 1, Type: depositType},
+			{TransactionID: txIDs[1], EventIndex: 2, Type: withdrawalType},
+			{TransactionID: txIDs[0], EventIndex: 1, Type: withdrawalType},
+			{TransactionID: txIDs[0], EventIndex: 0, Type: depositType},
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensDepositedFunc = func(string) (string, error) {
+			return string(withdrawalType), nil
+		}
+		generator.TokensWithdrawnFunc = func(string) (string, error) {
+			return string(depositType), nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return unordered, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+			netIndex := uint(event.EventIndex)
+			op := object.Operation{
+				ID:   identifier.Operation{NetworkIndex: &netIndex},
+				Type: string(event.Type),
+			}
+			return &op, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.Len(t, got.Operations, 4)
+
+		wantNetIndices := []uint{0, 1, 1, 3}
+		wantTypes := []flow.EventType{depositType, withdrawalType, depositType, depositType}
+		for i, op := range got.Operations {
+			assert.Equal(t, uint(i), op.ID.Index)
+			require.NotNil(t, op.ID.NetworkIndex)
+			assert.Equal(t, wantNetIndices[i], *op.ID.NetworkIndex)
+			assert.Equal(t, string(wantTypes[i]), op.Type)
+		}
+	})
+
+	t.Run("relates deposits to their withdrawals", func(t *testing.T) {
+		t.Parallel()
+
+		deposited := mocks.GenericEventType(2)
+		withdrawn := mocks.GenericEventType(3)
+
+		// Two transfers, followed by a deposit which has no matching withdrawal.
+		transfers := []flow.Event{
+			{TransactionID: txIDs[0], EventIndex: 0, Type: withdrawn, Payload: []byte("-10")},
+			{TransactionID: txIDs[0], EventIndex: 1, Type: withdrawn, Payload: []byte("-5")},
+			{TransactionID: txIDs[0], EventIndex: 2, Type: deposited, Payload: []byte("5")},
+			{TransactionID: txIDs[0], EventIndex: 3, Type: deposited, Payload: []byte("10")},
+			{TransactionID: txIDs[0], EventIndex: 4, Type: deposited, Payload: []byte("10")},
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensDepositedFunc = func(string) (string, error) {
+			return string(deposited), nil
+		}
+		generator.TokensWithdrawnFunc = func(string) (string, error) {
+			return string(withdrawn), nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return transfers, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+			op := object.Operation{
+				Type: dps.OperationTransfer,
+				Amount: object.Amount{
+					Value:    string(event.Payload),
+					Currency: identifier.Currency{Symbol: dps.FlowSymbol, Decimals: dps.FlowDecimals},
+				},
+			}
+			return &op, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithGenerator(generator),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.Len(t, got.Operations, 5)
+		assert.Empty(t, got.Operations[0].RelatedIDs)
+		assert.Empty(t, got.Operations[1].RelatedIDs)
+		assert.Equal(t, []identifier.Operation{{Index: 1}}, got.Operations[2].RelatedIDs)
+		assert.Equal(t, []identifier.Operation{{Index: 0}}, got.Operations[3].RelatedIDs)
+		assert.Empty(t, got.Operations[4].RelatedIDs)
+	})
+
+	t.Run("folds minted and burned tokens into their deposits and withdrawals", func(t *testing.T) {
+		t.Parallel()
+
+		deposited := mocks.GenericEventType(0)
+		withdrawn := mocks.GenericEventType(1)
+		minted := mocks.GenericEventType(12)
+		burned := mocks.GenericEventType(13)
+
+		// Minted tokens are deposited into a vault, while burned tokens are
+		// withdrawn from one first. The last mint has no deposit.
+		events := []flow.Event{
+			{TransactionID: txIDs[0], EventIndex: 0, Type: minted, Payload: []byte("10")},
+			{TransactionID: txIDs[0], EventIndex: 1, Type: deposited, Payload: []byte("10")},
+			{TransactionID: txIDs[0], EventIndex: 2, Type: withdrawn, Payload: []byte("-5")},
+			{TransactionID: txIDs[0], EventIndex: 3, Type: burned, Payload: []byte("-5")},
+			{TransactionID: txIDs[0], EventIndex: 4, Type: withdrawn, Payload: []byte("-3")},
+			{TransactionID: txIDs[0], EventIndex: 5, Type: deposited, Payload: []byte("3")},
+			{TransactionID: txIDs[0], EventIndex: 6, Type: minted, Payload: []byte("7")},
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return events, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+			op := object.Operation{
+				Type: dps.OperationTransfer,
+				Amount: object.Amount{
+					Value:    string(event.Payload),
+					Currency: identifier.Currency{Symbol: dps.FlowSymbol, Decimals: dps.FlowDecimals},
+				},
+			}
+			switch event.Type {
+			case minted:
+				op.Type = configuration.OperationMint
+			case burned:
+				op.Type = configuration.OperationBurn
+			default:
+				op.AccountID = mocks.GenericAccountID(int(event.EventIndex))
+			}
+			return &op, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.Len(t, got.Operations, 4)
+		assert.Equal(t, configuration.OperationMint, got.Operations[0].Type)
+		assert.Equal(t, mocks.GenericAccountID(1), got.Operations[0].AccountID)
+		assert.Equal(t, "10", got.Operations[0].Amount.Value)
+		assert.Empty(t, got.Operations[0].RelatedIDs)
+		assert.Equal(t, configuration.OperationBurn, got.Operations[1].Type)
+		assert.Equal(t, mocks.GenericAccountID(2), got.Operations[1].AccountID)
+		assert.Equal(t, "-5", got.Operations[1].Amount.Value)
+		assert.Empty(t, got.Operations[1].RelatedIDs)
+		assert.Equal(t, dps.OperationTransfer, got.Operations[2].Type)
+		assert.Equal(t, dps.OperationTransfer, got.Operations[3].Type)
+		assert.Equal(t, []identifier.Operation{{Index: 2}}, got.Operations[3].RelatedIDs)
+	})
+
+	t.Run("keeps transfers of minted tokens when mints are not allowlisted", func(t *testing.T) {
+		t.Parallel()
+
+		deposited := mocks.GenericEventType(0)
+		minted := mocks.GenericEventType(12)
+
+		events := []flow.Event{
+			{TransactionID: txIDs[0], EventIndex: 0, Type: minted, Payload: []byte("10")},
+			{TransactionID: txIDs[0], EventIndex: 1, Type: deposited, Payload: []byte("10")},
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return events, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+			op := object.Operation{
+				Type: dps.OperationTransfer,
+				Amount: object.Amount{
+					Value:    string(event.Payload),
+					Currency: identifier.Currency{Symbol: dps.FlowSymbol, Decimals: dps.FlowDecimals},
+				},
+			}
+			if event.Type == minted {
+				op.Type = configuration.OperationMint
+			}
+			return &op, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithAllowed(configuration.OperationTransfer),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.Len(t, got.Operations, 1)
+		assert.Equal(t, dps.OperationTransfer, got.Operations[0].Type)
+		assert.Equal(t, uint(0), got.Operations[0].ID.Index)
+		assert.Empty(t, got.Operations[0].RelatedIDs)
+		require.NotNil(t, got.Metadata)
+		assert.Empty(t, got.Metadata.Omitted)
+	})
+
+	t.Run("moves operations of types that are not allowlisted into metadata", func(t *testing.T) {
+		t.Parallel()
+
+		deposited := mocks.GenericEventType(0)
+
+		events := []flow.Event{
+			{TransactionID: txIDs[0], EventIndex: 0, Type: deposited, Payload: []byte("10")},
+			{TransactionID: txIDs[0], EventIndex: 1, Type: deposited, Payload: []byte("5")},
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return events, nil
+		}
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			return &flow.TransactionBody{Payer: mocks.GenericAddress(1)}, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+			op := object.Operation{
+				Type: dps.OperationTransfer,
+				Amount: object.Amount{
+					Value:    string(event.Payload),
+					Currency: identifier.Currency{Symbol: dps.FlowSymbol, Decimals: dps.FlowDecimals},
+				},
+			}
+			if event.EventIndex == 0 {
+				op.Type = configuration.OperationFeeCollection
+			}
+			return &op, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithAllowed(configuration.OperationTransfer),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.Len(t, got.Operations, 1)
+		assert.Equal(t, dps.OperationTransfer, got.Operations[0].Type)
+		assert.Equal(t, uint(0), got.Operations[0].ID.Index)
+		require.NotNil(t, got.Metadata)
+		require.Len(t, got.Metadata.Omitted, 1)
+		assert.Equal(t, configuration.OperationFeeCollection, got.Metadata.Omitted[0].Type)
+		require.NotNil(t, got.Metadata.Omitted[0].Metadata)
+		assert.Equal(t, &object.FeeMetadata{Payer: identifier.Account{Address: mocks.GenericAddress(1).String()}}, got.Metadata.Omitted[0].Metadata.Fee)
+	})
+
+	t.Run("includes execution error of failed transaction", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.ResultFunc = func(txID flow.Identifier) (*flow.TransactionResult, error) {
+			assert.Equal(t, txIDs[0], txID)
+
+			result := flow.TransactionResult{
+				TransactionID: txID,
+				ErrorMessage:  "[Error Code: 1101] cadence runtime error",
+			}
+			return &result, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.NotNil(t, got.Metadata)
+		require.NotNil(t, got.Metadata.Error)
+		assert.Equal(t, uint(1101), got.Metadata.Error.Code)
+		assert.Equal(t, "[Error Code: 1101] cadence runtime error", got.Metadata.Error.Message)
+	})
+
+	t.Run("includes intended transfer of failed transaction", func(t *testing.T) {
+		t.Parallel()
+
+		sender := mocks.GenericAddress(0)
+		receiver := mocks.GenericAddress(1)
+		amountArg, err := cjson.Encode(cadence.UFix64(100_000_000))
+		require.NoError(t, err)
+		receiverArg, err := cjson.Encode(cadence.NewAddress(receiver))
+		require.NoError(t, err)
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.ResultFunc = func(txID flow.Identifier) (*flow.TransactionResult, error) {
+			result := flow.TransactionResult{
+				TransactionID: txID,
+				ErrorMessage:  "[Error Code: 1101] cadence runtime error",
+			}
+			return &result, nil
+		}
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			body := flow.TransactionBody{
+				Script:      mocks.GenericBytes,
+				Arguments:   [][]byte{amountArg, receiverArg},
+				Authorizers: []flow.Address{sender},
+			}
+			return &body, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, retriever.ErrNotSupported
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithParams(dps.FlowParams[dps.FlowTestnet]),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithRegistry(mocks.BaselineIntents(t)),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.Len(t, got.Operations, 2)
+
+		withdrawal := got.Operations[0]
+		assert.Equal(t, uint(0), withdrawal.ID.Index)
+		assert.Equal(t, dps.OperationTransfer, withdrawal.Type)
+		assert.Equal(t, configuration.StatusFailed.Status, withdrawal.Status)
+		assert.Equal(t, sender.String(), withdrawal.AccountID.Address)
+		assert.Equal(t, "-100000000", withdrawal.Amount.Value)
+
+		deposit := got.Operations[1]
+		assert.Equal(t, uint(1), deposit.ID.Index)
+		assert.Equal(t, dps.OperationTransfer, deposit.Type)
+		assert.Equal(t, configuration.StatusFailed.Status, deposit.Status)
+		assert.Equal(t, receiver.String(), deposit.AccountID.Address)
+		assert.Equal(t, "100000000", deposit.Amount.Value)
+		assert.Equal(t, []identifier.Operation{{Index: 0}}, deposit.RelatedIDs)
+	})
+
+	t.Run("recognizes known scripts of failed transaction without configured registry", func(t *testing.T) {
+		t.Parallel()
+
+		amountArg, err := cjson.Encode(cadence.UFix64(100_000_000))
+		require.NoError(t, err)
+		receiverArg, err := cjson.Encode(cadence.NewAddress(mocks.GenericAddress(1)))
+		require.NoError(t, err)
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.ResultFunc = func(txID flow.Identifier) (*flow.TransactionResult, error) {
+			result := flow.TransactionResult{
+				TransactionID: txID,
+				ErrorMessage:  "[Error Code: 1101] cadence runtime error",
+			}
+			return &result, nil
+		}
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			body := flow.TransactionBody{
+				Script:      mocks.GenericBytes,
+				Arguments:   [][]byte{amountArg, receiverArg},
+				Authorizers: []flow.Address{mocks.GenericAddress(0)},
+			}
+			return &body, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, retriever.ErrNotSupported
+		}
+
+		ret := retriever.New(
+			dps.FlowParams[dps.FlowTestnet],
+			index,
+			validator,
+			mocks.BaselineGenerator(t),
+			mocks.BaselineInvoker(t),
+			convert,
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.Len(t, got.Operations, 2)
+		assert.Equal(t, configuration.StatusFailed.Status, got.Operations[0].Status)
+		assert.Equal(t, configuration.StatusFailed.Status, got.Operations[1].Status)
+	})
+
+	t.Run("skips intended operations of failed transaction with unknown script", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.ResultFunc = func(txID flow.Identifier) (*flow.TransactionResult, error) {
+			result := flow.TransactionResult{
+				TransactionID: txID,
+				ErrorMessage:  "[Error Code: 1101] cadence runtime error",
+			}
+			return &result, nil
+		}
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			body := flow.TransactionBody{
+				Script:      []byte("transaction {}"),
+				Authorizers: []flow.Address{mocks.GenericAddress(0)},
+			}
+			return &body, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, retriever.ErrNotSupported
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithRegistry(mocks.BaselineIntents(t)),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		assert.Empty(t, got.Operations)
+	})
+
+	t.Run("skips intended operations of failed transaction with invalid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.ResultFunc = func(txID flow.Identifier) (*flow.TransactionResult, error) {
+			result := flow.TransactionResult{
+				TransactionID: txID,
+				ErrorMessage:  "[Error Code: 1101] cadence runtime error",
+			}
+			return &result, nil
+		}
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			body := flow.TransactionBody{
+				Script:      mocks.GenericBytes,
+				Arguments:   [][]byte{mocks.GenericBytes, mocks.GenericBytes},
+				Authorizers: []flow.Address{mocks.GenericAddress(0)},
+			}
+			return &body, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, retriever.ErrNotSupported
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithRegistry(mocks.BaselineIntents(t)),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		assert.Empty(t, got.Operations)
+	})
+
+	t.Run("skips intended operations of failed transaction with token that is not served", func(t *testing.T) {
+		t.Parallel()
+
+		amountArg, err := cjson.Encode(cadence.UFix64(100_000_000))
+		require.NoError(t, err)
+		receiverArg, err := cjson.Encode(cadence.NewAddress(mocks.GenericAddress(1)))
+		require.NoError(t, err)
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.ResultFunc = func(txID flow.Identifier) (*flow.TransactionResult, error) {
+			result := flow.TransactionResult{
+				TransactionID: txID,
+				ErrorMessage:  "[Error Code: 1101] cadence runtime error",
+			}
+			return &result, nil
+		}
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			body := flow.TransactionBody{
+				Script:      mocks.GenericFUSDScript,
+				Arguments:   [][]byte{amountArg, receiverArg},
+				Authorizers: []flow.Address{mocks.GenericAddress(0)},
+			}
+			return &body, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, retriever.ErrNotSupported
+		}
+
+		registry := mocks.BaselineIntents(t)
+		registry.MatchFunc = func([]byte) (intents.Intent, bool) {
+			intent := intents.Intent{
+				Name:   intents.NameTransferFUSD,
+				Kind:   intents.KindTransfer,
+				Symbol: scripts.FUSDSymbol,
+			}
+			return intent, true
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithParams(dps.FlowParams[dps.FlowTestnet]),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithRegistry(registry),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		assert.Empty(t, got.Operations)
+		require.NotNil(t, got.Metadata)
+		assert.Empty(t, got.Metadata.Omitted)
+	})
+
+	t.Run("omits intended operations of failed transaction with types not allowlisted", func(t *testing.T) {
+		t.Parallel()
+
+		nodeArg, err := cjson.Encode(cadence.String("node"))
+		require.NoError(t, err)
+		delegatorArg, err := cjson.Encode(cadence.NewOptional(nil))
+		require.NoError(t, err)
+		amountArg, err := cjson.Encode(cadence.UFix64(100_000_000))
+		require.NoError(t, err)
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.ResultFunc = func(txID flow.Identifier) (*flow.TransactionResult, error) {
+			result := flow.TransactionResult{
+				TransactionID: txID,
+				ErrorMessage:  "[Error Code: 1101] cadence runtime error",
+			}
+			return &result, nil
+		}
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			body := flow.TransactionBody{
+				Script:      mocks.GenericStakeScript,
+				Arguments:   [][]byte{nodeArg, delegatorArg, amountArg},
+				Authorizers: []flow.Address{mocks.GenericAddress(0)},
+			}
+			return &body, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, retriever.ErrNotSupported
+		}
+
+		registry := mocks.BaselineIntents(t)
+		registry.MatchFunc = func([]byte) (intents.Intent, bool) {
+			intent := intents.Intent{
+				Name:   intents.NameStakeNewTokens,
+				Kind:   intents.KindStaking,
+				Symbol: dps.FlowSymbol,
+			}
+			return intent, true
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithParams(dps.FlowParams[dps.FlowTestnet]),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithRegistry(registry),
+			retriever.WithAllowed(dps.OperationTransfer),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		assert.Empty(t, got.Operations)
+		require.NotNil(t, got.Metadata)
+		require.Len(t, got.Metadata.Omitted, 1)
+		assert.Equal(t, configuration.OperationTemplate, got.Metadata.Omitted[0].Type)
+		assert.Equal(t, configuration.StatusFailed.Status, got.Metadata.Omitted[0].Status)
+	})
+
+	t.Run("omits intended transfer of failed transaction without indices or relations", func(t *testing.T) {
+		t.Parallel()
+
+		amountArg, err := cjson.Encode(cadence.UFix64(100_000_000))
+		require.NoError(t, err)
+		receiverArg, err := cjson.Encode(cadence.NewAddress(mocks.GenericAddress(1)))
+		require.NoError(t, err)
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.ResultFunc = func(txID flow.Identifier) (*flow.TransactionResult, error) {
+			result := flow.TransactionResult{
+				TransactionID: txID,
+				ErrorMessage:  "[Error Code: 1101] cadence runtime error",
+			}
+			return &result, nil
+		}
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			body := flow.TransactionBody{
+				Script:      mocks.GenericBytes,
+				Arguments:   [][]byte{amountArg, receiverArg},
+				Authorizers: []flow.Address{mocks.GenericAddress(0)},
+			}
+			return &body, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, retriever.ErrNotSupported
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithParams(dps.FlowParams[dps.FlowTestnet]),
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+			retriever.WithRegistry(mocks.BaselineIntents(t)),
+			retriever.WithAllowed(configuration.OperationTemplate),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		assert.Empty(t, got.Operations)
+		require.NotNil(t, got.Metadata)
+		require.Len(t, got.Metadata.Omitted, 2)
+		for _, op := range got.Metadata.Omitted {
+			assert.Equal(t, dps.OperationTransfer, op.Type)
+			assert.Equal(t, configuration.StatusFailed.Status, op.Status)
+			assert.Zero(t, op.ID.Index)
+			assert.Empty(t, op.RelatedIDs)
+		}
+	})
+
+	t.Run("includes computation, gas limit and fees", func(t *testing.T) {
+		t.Parallel()
+
+		feesType := mocks.GenericEventType(8)
+		feesEvents := []flow.Event{
+			{TransactionID: txIDs[1], EventIndex: 0, Type: feesType},
+			{TransactionID: txIDs[0], EventIndex: 1, Type: feesType},
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return feesEvents, nil
+		}
+		index.ResultFunc = func(txID flow.Identifier) (*flow.TransactionResult, error) {
+			result := flow.TransactionResult{
+				TransactionID:   txID,
+				ComputationUsed: 42,
+			}
+			return &result, nil
+		}
+		index.TransactionFunc = func(txID flow.Identifier) (*flow.TransactionBody, error) {
+			assert.Equal(t, txIDs[0], txID)
+
+			body := flow.TransactionBody{GasLimit: 9999}
+			return &body, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToFeesFunc = func(event flow.Event) (*object.TransactionFees, error) {
+			assert.Equal(t, feesEvents[1], event)
+
+			fees := mocks.GenericFees()
+			return &fees, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.NotNil(t, got.Metadata)
+		assert.Equal(t, uint64(42), got.Metadata.ComputationUsed)
+		assert.Equal(t, uint64(9999), got.Metadata.GasLimit)
+		wantFees := mocks.GenericFees()
+		assert.Equal(t, &wantFees, got.Metadata.Fees)
+		assert.Nil(t, got.Metadata.Error)
+	})
+
+	t.Run("includes created accounts with their keys", func(t *testing.T) {
+		t.Parallel()
+
+		// The second transaction creates an account as well, and the existing
+		// account gets a key, neither of which should be included.
+		payer := mocks.GenericAddress(2)
+		events := []flow.Event{
+			{TransactionID: txIDs[0], EventIndex: 3, Type: flow.EventAccountCreated, Payload: mocks.GenericAddress(1).Bytes()},
+			{TransactionID: txIDs[0], EventIndex: 0, Type: flow.EventAccountCreated, Payload: mocks.GenericAddress(0).Bytes()},
+			{TransactionID: txIDs[0], EventIndex: 1, Type: "flow.AccountKeyAdded", Payload: mocks.GenericAddress(0).Bytes()},
+			{TransactionID: txIDs[0], EventIndex: 2, Type: "flow.AccountKeyAdded", Payload: mocks.GenericAddress(0).Bytes()},
+			{TransactionID: txIDs[0], EventIndex: 4, Type: "flow.AccountKeyAdded", Payload: mocks.GenericAddress(1).Bytes()},
+			{TransactionID: txIDs[0], EventIndex: 5, Type: "flow.AccountKeyAdded", Payload: mocks.GenericAddress(3).Bytes()},
+			{TransactionID: txIDs[1], EventIndex: 0, Type: flow.EventAccountCreated, Payload: mocks.GenericAddress(4).Bytes()},
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return events, nil
+		}
+		index.TransactionFunc = func(txID flow.Identifier) (*flow.TransactionBody, error) {
+			body := flow.TransactionBody{Payer: payer}
+			return &body, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToAddressFunc = func(event flow.Event) (flow.Address, error) {
+			return flow.BytesToAddress(event.Payload), nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.NotNil(t, got.Metadata)
+		want := []object.CreatedAccount{
+			{Address: mocks.GenericAddress(0).String(), Creator: payer.String(), Keys: 2},
+			{Address: mocks.GenericAddress(1).String(), Creator: payer.String(), Keys: 1},
+		}
+		assert.Equal(t, want, got.Metadata.Accounts)
+	})
+
+	t.Run("includes contract changes in order", func(t *testing.T) {
+		t.Parallel()
+
+		events := []flow.Event{
+			{TransactionID: txIDs[0], EventIndex: 2, Type: "flow.AccountContractUpdated", Payload: []byte("Second")},
+			{TransactionID: txIDs[0], EventIndex: 1, Type: "flow.AccountContractAdded", Payload: []byte("First")},
+			{TransactionID: txIDs[1], EventIndex: 0, Type: "flow.AccountContractRemoved", Payload: []byte("Other")},
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return events, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToContractFunc = func(event flow.Event) (*object.ContractChange, error) {
+			contract := mocks.GenericContractChange()
+			contract.Name = string(event.Payload)
+			return &contract, nil
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.NotNil(t, got.Metadata)
+		require.Len(t, got.Metadata.Contracts, 2)
+		assert.Equal(t, "First", got.Metadata.Contracts[0].Name)
+		assert.Equal(t, "Second", got.Metadata.Contracts[1].Name)
+	})
+
+	t.Run("handles converter failure for contract change", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return []flow.Event{{TransactionID: txIDs[0], Type: "flow.AccountContractAdded"}}, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToContractFunc = func(flow.Event) (*object.ContractChange, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		_, err := ret.Transaction(rosBlockID, txQual)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles converter failure for created account", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return []flow.Event{{TransactionID: txIDs[0], Type: flow.EventAccountCreated}}, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToAddressFunc = func(flow.Event) (flow.Address, error) {
+			return flow.EmptyAddress, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		_, err := ret.Transaction(rosBlockID, txQual)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("omits fees of transaction without fees event", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		require.NotNil(t, got.Metadata)
+		assert.Nil(t, got.Metadata.Fees)
+		assert.Nil(t, got.Metadata.Error)
+	})
+
+	t.Run("handles index.Transaction failure", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+		)
+
+		_, err := ret.Transaction(rosBlockID, txQual)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles fees conversion failure", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return []flow.Event{{TransactionID: txIDs[0], Type: mocks.GenericEventType(8)}}, nil
+		}
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToFeesFunc = func(flow.Event) (*object.TransactionFees, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		_, err := ret.Transaction(rosBlockID, txQual)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles index.Result failure", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return txIDs[0], nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.ResultFunc = func(flow.Identifier) (*flow.TransactionResult, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(
+			t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+		)
+
+		_, err := ret.Transaction(rosBlockID, txQual)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles transaction with no relevant operations", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return []flow.Event{
+				{
+					Type: mocks.GenericEventType(0),
+					// Here we use the wrong resource ID on purpose so that it does not match any of transaction ID.
+					TransactionID: mocks.GenericSeal(0).ID(),
+				},
+			}, nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		got, err := ret.Transaction(rosBlockID, txQual)
+
+		require.NoError(t, err)
+		assert.Empty(t, got.Operations)
+	})
+
+	t.Run("handles invalid block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid transaction", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return flow.ZeroID, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("block does not contain transaction", func(t *testing.T) {
+		index := mocks.BaselineReader(t)
+		index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return []flow.Identifier{}, nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles transactions index failure", func(t *testing.T) {
+		index := mocks.BaselineReader(t)
+		index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles deposit script generate failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensDepositedFunc = func(string) (string, error) {
+			return "", mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithGenerator(generator))
+
+		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles withdrawal script generate failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensWithdrawnFunc = func(string) (string, error) {
+			return "", mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithGenerator(generator))
+
+		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles index event retrieval failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles converter failure", func(t *testing.T) {
+		t.Parallel()
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithConverter(convert))
+
+		_, err := ret.Transaction(mocks.GenericRosBlockID, mocks.GenericTransactionQualifier(0))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRetriever_Lookup(t *testing.T) {
+	header := mocks.GenericHeader
+	rosBlockID := mocks.GenericRosBlockID
+	txQual := mocks.GenericTransactionQualifier(0)
+	txID := mocks.GenericTransaction(0).ID()
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(transaction identifier.Transaction) (flow.Identifier, error) {
+			assert.Equal(t, txQual, transaction)
+
+			return txID, nil
+		}
+		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+			require.NotNil(t, rosBlockID.Index)
+			assert.Equal(t, header.Height, *rosBlockID.Index)
+
+			return header.Height, header.ID(), nil
+		}
+
+		index := mocks.BaselineReader(t)
+		index.HeightForTransactionFunc = func(gotTxID flow.Identifier) (uint64, error) {
+			assert.Equal(t, txID, gotTxID)
+
+			return header.Height, nil
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+		)
+
+		gotBlockID, got, err := ret.Lookup(txQual)
+
+		require.NoError(t, err)
+		assert.Equal(t, rosBlockID, gotBlockID)
+		assert.Equal(t, txQual, got.ID)
+	})
+
+	t.Run("handles invalid transaction", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
+			return flow.ZeroID, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, _, err := ret.Lookup(txQual)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles unknown transaction", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeightForTransactionFunc = func(flow.Identifier) (uint64, error) {
+			return 0, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, _, err := ret.Lookup(txQual)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, _, err := ret.Lookup(txQual)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles transaction retrieval failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.ResultFunc = func(flow.Identifier) (*flow.TransactionResult, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+
+		_, _, err := ret.Lookup(txQual)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRetriever_Search(t *testing.T) {
+	header := mocks.GenericHeader
+	first := uint64(10)
+	last := uint64(12)
+	rosBlockID := identifier.Block{Index: &last}
+	txIDs := mocks.GenericTransactionIDs(2)
+	all := func(*object.Transaction) bool { return true }
+
+	validator := mocks.BaselineValidator(t)
+	validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+		return *rosBlockID.Index, flow.ZeroID, nil
+	}
+
+	index := mocks.BaselineReader(t)
+	index.FirstFunc = func() (uint64, error) {
+		return first, nil
+	}
+	index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+		return txIDs, nil
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+		)
+
+		matches, next, nextIndex, err := ret.Search(rosBlockID, 0, 3, nil, all)
+
+		require.NoError(t, err)
+		require.Len(t, matches, 3)
+		assert.Equal(t, last, *matches[0].BlockID.Index)
+		assert.Equal(t, header.ID().String(), matches[0].BlockID.Hash)
+		assert.Equal(t, txIDs[0].String(), matches[0].Transaction.ID.Hash)
+		assert.Equal(t, txIDs[1].String(), matches[1].Transaction.ID.Hash)
+		assert.Equal(t, last-1, *matches[2].BlockID.Index)
+		assert.Equal(t, txIDs[0].String(), matches[2].Transaction.ID.Hash)
+		require.NotNil(t, next)
+		assert.Equal(t, last-1, *next.Index)
+		assert.Equal(t, uint(1), nextIndex)
+	})
+
+	t.Run("resumes at given transaction until oldest block", func(t *testing.T) {
+		t.Parallel()
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+		)
+
+		resume := last - 1
+		matches, next, _, err := ret.Search(identifier.Block{Index: &resume}, 1, 10, nil, all)
+
+		require.NoError(t, err)
+		require.Len(t, matches, 3)
+		assert.Equal(t, txIDs[1].String(), matches[0].Transaction.ID.Hash)
+		assert.Equal(t, first, *matches[2].BlockID.Index)
+		assert.Nil(t, next)
+	})
+
+	t.Run("only returns matching transactions", func(t *testing.T) {
+		t.Parallel()
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+		)
+
+		match := func(rosTx *object.Transaction) bool {
+			return rosTx.ID.Hash == txIDs[1].String()
+		}
+		matches, _, _, err := ret.Search(rosBlockID, 0, 10, nil, match)
+
+		require.NoError(t, err)
+		assert.Len(t, matches, 3)
+		for _, match := range matches {
+			assert.Equal(t, txIDs[1].String(), match.Transaction.ID.Hash)
+		}
+	})
+
+	t.Run("stops at search limit", func(t *testing.T) {
+		t.Parallel()
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithMaxScan(2),
+		)
+
+		none := func(*object.Transaction) bool { return false }
+		matches, next, nextIndex, err := ret.Search(rosBlockID, 0, 10, nil, none)
+
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+		require.NotNil(t, next)
+		assert.Equal(t, first, *next.Index)
+		assert.Zero(t, nextIndex)
+	})
+
+	t.Run("skips blocks without account activity", func(t *testing.T) {
+		t.Parallel()
+
+		var heights []uint64
+		index := mocks.BaselineReader(t)
+		index.FirstFunc = func() (uint64, error) {
+			return first, nil
+		}
+		index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+			heights = append(heights, height)
+			return txIDs, nil
+		}
+
+		accounts := mocks.BaselineHistory(t)
+		accounts.RangeFunc = func() (uint64, uint64, bool) {
+			return first, last, true
+		}
+		accounts.LatestFunc = func(gotAddress flow.Address, height uint64) (uint64, bool, error) {
+			assert.Equal(t, mocks.GenericAddress(0), gotAddress)
+			assert.Equal(t, last, height)
+			return first, true, nil
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithAccounts(accounts),
+		)
+
+		accountID := mocks.GenericAccountID(0)
+		matches, next, _, err := ret.Search(rosBlockID, 1, 10, &accountID, all)
+
+		require.NoError(t, err)
+		require.Len(t, matches, 2)
+		assert.Equal(t, first, *matches[0].BlockID.Index)
+		assert.Equal(t, txIDs[0].String(), matches[0].Transaction.ID.Hash)
+		assert.Nil(t, next)
+		assert.Equal(t, []uint64{first}, heights)
+	})
+
+	t.Run("stops when account is not active in remaining blocks", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := mocks.BaselineHistory(t)
+		accounts.RangeFunc = func() (uint64, uint64, bool) {
+			return first, last, true
+		}
+		accounts.LatestFunc = func(flow.Address, uint64) (uint64, bool, error) {
+			return 0, false, nil
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithAccounts(accounts),
+		)
+
+		accountID := mocks.GenericAccountID(0)
+		matches, next, _, err := ret.Search(rosBlockID, 0, 10, &accountID, all)
+
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+		assert.Nil(t, next)
+	})
+
+	t.Run("searches blocks that the history does not cover", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := mocks.BaselineHistory(t)
+		accounts.RangeFunc = func() (uint64, uint64, bool) {
+			return first + 1, last, true
+		}
+		accounts.LatestFunc = func(flow.Address, uint64) (uint64, bool, error) {
+			return 0, false, nil
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithAccounts(accounts),
+		)
+
+		accountID := mocks.GenericAccountID(0)
+		matches, next, _, err := ret.Search(rosBlockID, 0, 10, &accountID, all)
+
+		require.NoError(t, err)
+		require.Len(t, matches, 2)
+		assert.Equal(t, first, *matches[0].BlockID.Index)
+		assert.Nil(t, next)
+	})
+
+	t.Run("handles history failure", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := mocks.BaselineHistory(t)
+		accounts.RangeFunc = func() (uint64, uint64, bool) {
+			return first, last, true
+		}
+		accounts.LatestFunc = func(flow.Address, uint64) (uint64, bool, error) {
+			return 0, false, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithAccounts(accounts),
+		)
+
+		accountID := mocks.GenericAccountID(0)
+		_, _, _, err := ret.Search(rosBlockID, 0, 10, &accountID, all)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, _, _, err := ret.Search(rosBlockID, 0, 10, nil, all)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles index failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+		)
+
+		_, _, _, err := ret.Search(rosBlockID, 0, 10, nil, all)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles converter failure", func(t *testing.T) {
+		t.Parallel()
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		_, _, _, err := ret.Search(rosBlockID, 0, 10, nil, all)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRetriever_Sequence(t *testing.T) {
+	rosBlockID := mocks.GenericRosBlockID
+	accountID := mocks.GenericAccountID(0)
+	address := mocks.GenericAddress(0)
+	key := mocks.GenericAccount.Keys[0]
+	header := mocks.GenericHeader
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(blockID identifier.Block) (uint64, flow.Identifier, error) {
+			assert.Equal(t, rosBlockID, blockID)
+
+			return header.Height, header.ID(), nil
+		}
+		validator.AccountFunc = func(gotAccountID identifier.Account) (flow.Address, error) {
+			assert.Equal(t, accountID, gotAccountID)
+
+			return address, nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(height uint64, gotAddress flow.Address, index int) (*flow.AccountPublicKey, error) {
+			assert.Equal(t, header.Height, height)
+			assert.Equal(t, address, gotAddress)
+			assert.Equal(t, 0, index)
+
+			return &key, nil
+		}
+
+		ret := retriever.New(
+			mocks.GenericParams,
+			mocks.BaselineReader(t),
+			validator,
+			mocks.BaselineGenerator(t),
+			invoker,
+			mocks.BaselineConverter(t),
+		)
+
+		seqNum, err := ret.Sequence(rosBlockID, accountID, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, key.SeqNumber, seqNum)
+	})
+
+	t.Run("handles validator failure on block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		ret := retriever.New(
+			mocks.GenericParams,
+			mocks.BaselineReader(t),
+			validator,
+			mocks.BaselineGenerator(t),
+			mocks.BaselineInvoker(t),
+			mocks.BaselineConverter(t),
+		)
+
+		_, err := ret.Sequence(rosBlockID, accountID, 0)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles validator failure on account", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(identifier.Account) (flow.Address, error) {
+			return flow.EmptyAddress, mocks.GenericError
+		}
+
+		ret := retriever.New(
+			mocks.GenericParams,
+			mocks.BaselineReader(t),
+			validator,
+			mocks.BaselineGenerator(t),
+			mocks.BaselineInvoker(t),
+			mocks.BaselineConverter(t),
+		)
+
+		_, err := ret.Sequence(rosBlockID, accountID, 0)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invoker failure on key", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return nil, mocks.GenericError
+		}
+
+		ret := retriever.New(
+			mocks.GenericParams,
+			mocks.BaselineReader(t),
+			mocks.BaselineValidator(t),
+			mocks.BaselineGenerator(t),
+			invoker,
+			mocks.BaselineConverter(t),
+		)
+
+		_, err := ret.Sequence(rosBlockID, accountID, 0)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRetriever_Blocks(t *testing.T) {
+	header := mocks.GenericHeader
+	start := header.Height
+	end := header.Height + 2
+	rosStart := identifier.Block{Index: &start}
+	rosEnd := identifier.Block{Index: &end}
+
+	validator := mocks.BaselineValidator(t)
+	validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+		return *rosBlockID.Index, flow.ZeroID, nil
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var heights []uint64
+		index := mocks.BaselineReader(t)
+		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			heights = append(heights, height)
+			return header, nil
+		}
+		index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return mocks.GenericTransactionIDs(3), nil
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithLimit(2),
+		)
+
+		blocks, extraTxIDs, err := ret.Blocks(rosStart, rosEnd)
+
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{start, start + 1, start + 2}, heights)
+		require.Len(t, blocks, 3)
+		require.Len(t, extraTxIDs, 3)
+		for i, block := range blocks {
+			require.NotNil(t, block.ID.Index)
+			assert.Equal(t, start+uint64(i), *block.ID.Index)
+			assert.Len(t, block.Transactions, 2)
+			assert.Len(t, extraTxIDs[i], 1)
+		}
+	})
+
+	t.Run("pins latest block for whole range", func(t *testing.T) {
+		t.Parallel()
+
+		// Every resolution of the latest block advances its height, as if a
+		// new block was indexed while the request is processed.
+		last := header.Height
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+			if rosBlockID.Index == nil {
+				last++
+				return last, flow.ZeroID, nil
+			}
+			return *rosBlockID.Index, flow.ZeroID, nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		blocks, _, err := ret.Blocks(identifier.Block{}, identifier.Block{})
+
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, header.Height+1, *blocks[0].ID.Index)
+	})
+
+	t.Run("pins latest sealed block when only sealed blocks are served", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.FirstFunc = func() (uint64, error) {
+			return header.Height, nil
+		}
+		index.LastFunc = func() (uint64, error) {
+			return header.Height + 5, nil
+		}
 		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
-			assert.Equal(t, header.Height, height)
-
-			return mocks.GenericHeader, nil
+			header := *header
+			header.Height = height
+			return &header, nil
 		}
-		index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
-			assert.Equal(t, header.Height, height)
 
-			return mocks.GenericTransactionIDs(6), nil
+		tracker := mocks.BaselineTracker(t)
+		tracker.SealedFunc = func() (uint64, error) {
+			return header.Height + 2, nil
 		}
-		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
-			assert.Equal(t, mocks.GenericHeight, height)
 
-			return events, nil
-		}
-		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
-			assert.Equal(t, header.Height, height)
+		// The real validator is used, so that the pinned end block goes through
+		// the same checks as any block that is requested by its height.
+		params := dps.Params{ChainID: flow.Mainnet}
+		validate := validatorpkg.New(params, index, tracker, configuration.New(flow.Mainnet), validatorpkg.WithSealedOnly(true))
 
-			return events, nil
-		}
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validate),
+		)
+
+		start := header.Height + 1
+		blocks, _, err := ret.Blocks(identifier.Block{Index: &start}, identifier.Block{})
+
+		require.NoError(t, err)
+		require.Len(t, blocks, 2)
+		assert.Equal(t, header.Height+2, *blocks[1].ID.Index)
+	})
+
+	t.Run("handles latest block resolution failure", func(t *testing.T) {
+		t.Parallel()
 
 		validator := mocks.BaselineValidator(t)
 		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
-			assert.Equal(t, rosBlockID, rosBlockID)
-
-			return header.Height, header.ID(), nil
+			if rosBlockID.Index == nil {
+				return 0, flow.ZeroID, mocks.GenericError
+			}
+			return *rosBlockID.Index, flow.ZeroID, nil
 		}
 
-		generator := mocks.BaselineGenerator(t)
-		generator.TokensDepositedFunc = func(symbol string) (string, error) {
-			assert.Equal(t, symbol, dps.FlowSymbol)
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
 
-			return string(depositType), nil
-		}
-		generator.TokensWithdrawnFunc = func(symbol string) (string, error) {
-			assert.Equal(t, symbol, dps.FlowSymbol)
+		_, _, err := ret.Blocks(identifier.Block{}, rosEnd)
 
-			return string(withdrawalType), nil
-		}
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
 
-		convert := mocks.BaselineConverter(t)
-		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+	t.Run("handles single block range", func(t *testing.T) {
+		t.Parallel()
 
-			var op object.Operation
-			switch event.Type {
-			case withdrawalType:
-				assert.Contains(t, withdrawals, event)
-				op = mocks.GenericOperation(0)
-			case depositType:
-				assert.Contains(t, deposits, event)
-				op = mocks.GenericOperation(1)
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithValidator(validator),
+			retriever.WithMaxBlocks(1),
+		)
+
+		blocks, _, err := ret.Blocks(rosStart, rosStart)
+
+		require.NoError(t, err)
+		assert.Len(t, blocks, 1)
+	})
+
+	t.Run("handles inverted range", func(t *testing.T) {
+		t.Parallel()
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, _, err := ret.Blocks(rosEnd, rosStart)
+
+		require.Error(t, err)
+		assert.True(t, errors.As(err, &failure.InvalidBlock{}))
+	})
+
+	t.Run("handles range exceeding block limit", func(t *testing.T) {
+		t.Parallel()
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithValidator(validator),
+			retriever.WithMaxBlocks(2),
+		)
+
+		_, _, err := ret.Blocks(rosStart, rosEnd)
+
+		require.Error(t, err)
+		assert.True(t, errors.As(err, &failure.InvalidBlock{}))
+	})
+
+	t.Run("handles invalid end block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+			if *rosBlockID.Index == end {
+				return 0, flow.ZeroID, mocks.GenericError
 			}
+			return *rosBlockID.Index, flow.ZeroID, nil
+		}
 
-			return &op, nil
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, _, err := ret.Blocks(rosStart, rosEnd)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles block retrieval failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			if height == start+1 {
+				return nil, mocks.GenericError
+			}
+			return header, nil
 		}
 
-		ret := retriever.BaselineRetriever(
-			t,
-			retriever.WithGenerator(generator),
+		ret := retriever.BaselineRetriever(t,
 			retriever.WithIndex(index),
 			retriever.WithValidator(validator),
-			retriever.WithConverter(convert),
-			retriever.WithLimit(5),
 		)
 
-		block, extra, err := ret.Block(rosBlockID)
-
-		require.NoError(t, err)
-		assert.Equal(t, rosBlockID, block.ID)
-		assert.Len(t, block.Transactions, 5)
+		_, _, err := ret.Blocks(rosStart, rosEnd)
 
-		assert.Len(t, extra, 1)
+		assert.Error(t, err)
 	})
+}
 
-	t.Run("handles block without transactions", func(t *testing.T) {
+func TestRetriever_Rewards(t *testing.T) {
+	nodeID := mocks.GenericNodeID(0).String()
+	otherNodeID := mocks.GenericNodeID(1).String()
+	header := mocks.GenericHeader
+	start := header.Height
+	end := header.Height + 2
+	rosStart := identifier.Block{Index: &start}
+	rosEnd := identifier.Block{Index: &end}
+	delegatorID := uint32(7)
+	otherDelegatorID := uint32(8)
+
+	validator := mocks.BaselineValidator(t)
+	validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+		return *rosBlockID.Index, flow.ZeroID, nil
+	}
+
+	t.Run("nominal case for node operator", func(t *testing.T) {
 		t.Parallel()
 
 		index := mocks.BaselineReader(t)
-		index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
-			return []flow.Identifier{}, nil
+		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+			assert.Equal(t, []flow.EventType{mocks.GenericEventType(2)}, types)
+			if height != start+1 {
+				return nil, nil
+			}
+			return mocks.GenericEvents(2), nil
+		}
+		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			assert.Equal(t, start+1, height)
+			return header, nil
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+		var calls int
+		convert := mocks.BaselineConverter(t)
+		convert.EventToRewardFunc = func(flow.Event) (*object.Reward, error) {
+			calls++
+			reward := mocks.GenericReward(0)
+			if calls == 2 {
+				reward.NodeID = otherNodeID
+			}
+			return &reward, nil
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		rewards, next, err := ret.Rewards(nodeID, nil, rosStart, rosEnd)
 
-		got, _, err := ret.Block(rosBlockID)
 		require.NoError(t, err)
-		assert.Empty(t, got.Transactions)
+		assert.Nil(t, next)
+		require.Len(t, rewards, 1)
+		assert.Equal(t, nodeID, rewards[0].NodeID)
+		require.NotNil(t, rewards[0].BlockID.Index)
+		assert.Equal(t, start+1, *rewards[0].BlockID.Index)
+		assert.Equal(t, header.ID().String(), rewards[0].BlockID.Hash)
+		assert.Equal(t, mocks.GenericReward(0).Amount, rewards[0].Amount)
 	})
 
-	t.Run("handles block without relevant events", func(t *testing.T) {
+	t.Run("nominal case for delegator", func(t *testing.T) {
 		t.Parallel()
 
 		index := mocks.BaselineReader(t)
-		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
-			return []flow.Event{}, nil
+		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+			assert.Equal(t, []flow.EventType{mocks.GenericEventType(3)}, types)
+			return mocks.GenericEvents(2), nil
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+		var calls int
+		convert := mocks.BaselineConverter(t)
+		convert.EventToRewardFunc = func(flow.Event) (*object.Reward, error) {
+			calls++
+			reward := mocks.GenericReward(0)
+			reward.DelegatorID = &delegatorID
+			if calls%2 == 0 {
+				reward.DelegatorID = &otherDelegatorID
+			}
+			return &reward, nil
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		rewards, next, err := ret.Rewards(nodeID, &delegatorID, rosStart, rosEnd)
 
-		got, _, err := ret.Block(rosBlockID)
 		require.NoError(t, err)
-		require.NotEmpty(t, got.Transactions)
-		for _, tx := range got.Transactions {
-			assert.Empty(t, tx.Operations)
+		assert.Nil(t, next)
+		assert.Len(t, rewards, 3)
+		for _, reward := range rewards {
+			require.NotNil(t, reward.DelegatorID)
+			assert.Equal(t, delegatorID, *reward.DelegatorID)
 		}
 	})
 
-	t.Run("handles invalid block", func(t *testing.T) {
+	t.Run("handles inverted range", func(t *testing.T) {
 		t.Parallel()
 
-		validator := mocks.BaselineValidator(t)
-		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
-			return 0, flow.ZeroID, mocks.GenericError
-		}
-
 		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
 
-		_, _, err := ret.Block(rosBlockID)
+		_, _, err := ret.Rewards(nodeID, nil, rosEnd, rosStart)
 
-		assert.Error(t, err)
+		require.Error(t, err)
+		assert.True(t, errors.As(err, &failure.InvalidBlock{}))
 	})
 
-	t.Run("handles deposit script generate failure", func(t *testing.T) {
+	t.Run("resumes range exceeding reward limit", func(t *testing.T) {
 		t.Parallel()
 
-		generator := mocks.BaselineGenerator(t)
-		generator.TokensDepositedFunc = func(string) (string, error) {
-			return "", mocks.GenericError
+		var heights []uint64
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(height uint64, _ ...flow.EventType) ([]flow.Event, error) {
+			heights = append(heights, height)
+			return mocks.GenericEvents(1), nil
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithGenerator(generator))
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+			retriever.WithMaxBlocks(1),
+			retriever.WithMaxRewards(2),
+		)
 
-		_, _, err := ret.Block(rosBlockID)
+		rewards, next, err := ret.Rewards(nodeID, nil, rosStart, rosEnd)
 
-		assert.Error(t, err)
+		require.NoError(t, err)
+		assert.Len(t, rewards, 2)
+		assert.Equal(t, []uint64{start, start + 1}, heights)
+		require.NotNil(t, next)
+		require.NotNil(t, next.Index)
+		assert.Equal(t, start+2, *next.Index)
 	})
 
-	t.Run("handles withdrawal script generate failure", func(t *testing.T) {
+	t.Run("handles invalid start block", func(t *testing.T) {
 		t.Parallel()
 
-		generator := mocks.BaselineGenerator(t)
-		generator.TokensWithdrawnFunc = func(string) (string, error) {
-			return "", mocks.GenericError
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithGenerator(generator))
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
 
-		_, _, err := ret.Block(rosBlockID)
+		_, _, err := ret.Rewards(nodeID, nil, rosStart, rosEnd)
 
 		assert.Error(t, err)
 	})
 
-	t.Run("handles index header retrieval failure", func(t *testing.T) {
+	t.Run("handles generator failure", func(t *testing.T) {
 		t.Parallel()
 
-		index := mocks.BaselineReader(t)
-		index.HeaderFunc = func(uint64) (*flow.Header, error) {
-			return nil, mocks.GenericError
+		generator := mocks.BaselineGenerator(t)
+		generator.DelegatorRewardsPaidFunc = func() (string, error) {
+			return "", mocks.GenericError
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithValidator(validator),
+			retriever.WithGenerator(generator),
+		)
 
-		_, _, err := ret.Block(rosBlockID)
+		_, _, err := ret.Rewards(nodeID, &delegatorID, rosStart, rosEnd)
 
 		assert.Error(t, err)
 	})
 
-	t.Run("handles index event retrieval failure", func(t *testing.T) {
+	t.Run("handles index failure", func(t *testing.T) {
 		t.Parallel()
 
 		index := mocks.BaselineReader(t)
@@ -634,135 +4208,124 @@ func TestRetriever_Block(t *testing.T) {
 			return nil, mocks.GenericError
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+		)
+
+		_, _, err := ret.Rewards(nodeID, nil, rosStart, rosEnd)
 
-		_, _, err := ret.Block(rosBlockID)
 		assert.Error(t, err)
 	})
 
-	t.Run("handles event converter failure", func(t *testing.T) {
+	t.Run("handles converter failure", func(t *testing.T) {
 		t.Parallel()
+
 		convert := mocks.BaselineConverter(t)
-		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
+		convert.EventToRewardFunc = func(flow.Event) (*object.Reward, error) {
 			return nil, mocks.GenericError
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithConverter(convert))
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
+
+		_, _, err := ret.Rewards(nodeID, nil, rosStart, rosEnd)
 
-		_, _, err := ret.Block(rosBlockID)
 		assert.Error(t, err)
 	})
 }
 
-func TestRetriever_Transaction(t *testing.T) {
+func TestRetriever_Statement(t *testing.T) {
 	header := mocks.GenericHeader
-	rosBlockID := mocks.GenericRosBlockID
+	start := header.Height
+	end := header.Height + 2
+	rosStart := identifier.Block{Index: &start}
+	rosEnd := identifier.Block{Index: &end}
+	rosAccountID := mocks.GenericAccountID(0)
 
-	withdrawalType := mocks.GenericEventType(0)
-	depositType := mocks.GenericEventType(1)
-	withdrawals := mocks.GenericEvents(2, withdrawalType)
-	deposits := mocks.GenericEvents(2, depositType)
-	events := append(withdrawals, deposits...)
-
-	txQual := mocks.GenericTransactionQualifier(0)
-	txIDs := mocks.GenericTransactionIDs(5)
+	validator := mocks.BaselineValidator(t)
+	validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+		return *rosBlockID.Index, flow.ZeroID, nil
+	}
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
-		validator := mocks.BaselineValidator(t)
-		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
-			assert.Equal(t, rosBlockID, rosBlockID)
-
-			return header.Height, header.ID(), nil
-		}
-		validator.TransactionFunc = func(transaction identifier.Transaction) (flow.Identifier, error) {
-			assert.Equal(t, txQual, transaction)
-
-			return txIDs[0], nil
-		}
-
-		generator := mocks.BaselineGenerator(t)
-		generator.TokensDepositedFunc = func(symbol string) (string, error) {
-			assert.Equal(t, dps.FlowSymbol, symbol)
-
-			return string(withdrawalType), nil
-		}
-		generator.TokensWithdrawnFunc = func(symbol string) (string, error) {
-			assert.Equal(t, dps.FlowSymbol, symbol)
-
-			return string(depositType), nil
-		}
+		txIDs := mocks.GenericTransactionIDs(4)
+		events := mocks.GenericEvents(4, mocks.GenericEventTypes(2)...)
 
 		index := mocks.BaselineReader(t)
 		index.EventsFunc = func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
-			assert.Equal(t, header.Height, height)
-			require.Len(t, types, 2)
-			assert.Equal(t, withdrawalType, types[0])
-			assert.Equal(t, depositType, types[1])
-
-			return events, nil
-		}
-		index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
-			assert.Equal(t, header.Height, height)
-
-			return txIDs, nil
-		}
-
-		convert := mocks.BaselineConverter(t)
-		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
-
-			var op object.Operation
-			switch event.Type {
-			case withdrawalType:
-				assert.Contains(t, withdrawals, event)
-				op = mocks.GenericOperation(0)
-			case depositType:
-				assert.Contains(t, deposits, event)
-				op = mocks.GenericOperation(1)
+			want := append(mocks.GenericEventTypes(2), mocks.GenericEventType(12), mocks.GenericEventType(13))
+			assert.Equal(t, want, types)
+			if height != start+1 {
+				return nil, nil
 			}
+			return events[2:], nil
+		}
+		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			assert.Equal(t, start+1, height)
+			return header, nil
+		}
+		index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+			assert.Equal(t, start+1, height)
+			return txIDs, nil
+		}
 
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(event flow.Event) (*object.Operation, error) {
+			op := mocks.GenericOperation(int(event.EventIndex))
 			return &op, nil
 		}
 
-		ret := retriever.BaselineRetriever(
-			t,
-			retriever.WithGenerator(generator),
+		ret := retriever.BaselineRetriever(t,
 			retriever.WithIndex(index),
 			retriever.WithValidator(validator),
 			retriever.WithConverter(convert),
 		)
 
-		got, err := ret.Transaction(rosBlockID, txQual)
+		entries, err := ret.Statement(rosAccountID, rosStart, rosEnd)
 
 		require.NoError(t, err)
-		assert.Equal(t, txQual, got.ID)
-		assert.Len(t, got.Operations, 2)
+		require.Len(t, entries, 1)
+		require.NotNil(t, entries[0].BlockID.Index)
+		assert.Equal(t, start+1, *entries[0].BlockID.Index)
+		assert.Equal(t, header.ID().String(), entries[0].BlockID.Hash)
+		assert.Equal(t, header.Timestamp.UnixNano()/1_000_000, entries[0].Timestamp)
+		assert.Equal(t, txIDs[2].String(), entries[0].TransactionID.Hash)
+		require.NotNil(t, entries[0].Operation)
+		assert.Equal(t, rosAccountID, entries[0].Operation.AccountID)
 	})
 
-	t.Run("handles transaction with no relevant operations", func(t *testing.T) {
+	t.Run("handles inverted range", func(t *testing.T) {
 		t.Parallel()
 
-		index := mocks.BaselineReader(t)
-		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
-			return []flow.Event{
-				{
-					Type: mocks.GenericEventType(0),
-					// Here we use the wrong resource ID on purpose so that it does not match any of transaction ID.
-					TransactionID: mocks.GenericSeal(0).ID(),
-				},
-			}, nil
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, err := ret.Statement(rosAccountID, rosEnd, rosStart)
+
+		require.Error(t, err)
+		assert.True(t, errors.As(err, &failure.InvalidBlock{}))
+	})
+
+	t.Run("handles invalid account", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(identifier.Account) (flow.Address, error) {
+			return flow.EmptyAddress, mocks.GenericError
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
 
-		got, err := ret.Transaction(rosBlockID, txQual)
+		_, err := ret.Statement(rosAccountID, rosStart, rosEnd)
 
-		require.NoError(t, err)
-		assert.Empty(t, got.Operations)
+		assert.Error(t, err)
 	})
 
-	t.Run("handles invalid block", func(t *testing.T) {
+	t.Run("handles invalid start block", func(t *testing.T) {
 		t.Parallel()
 
 		validator := mocks.BaselineValidator(t)
@@ -772,160 +4335,309 @@ func TestRetriever_Transaction(t *testing.T) {
 
 		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
 
-		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+		_, err := ret.Statement(rosAccountID, rosStart, rosEnd)
 
 		assert.Error(t, err)
 	})
 
-	t.Run("handles invalid transaction", func(t *testing.T) {
+	t.Run("handles generator failure", func(t *testing.T) {
 		t.Parallel()
 
-		validator := mocks.BaselineValidator(t)
-		validator.TransactionFunc = func(identifier.Transaction) (flow.Identifier, error) {
-			return flow.ZeroID, mocks.GenericError
+		generator := mocks.BaselineGenerator(t)
+		generator.TokensWithdrawnFunc = func(string) (string, error) {
+			return "", mocks.GenericError
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithValidator(validator),
+			retriever.WithGenerator(generator),
+		)
 
-		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+		_, err := ret.Statement(rosAccountID, rosStart, rosEnd)
 
 		assert.Error(t, err)
 	})
 
-	t.Run("block does not contain transaction", func(t *testing.T) {
+	t.Run("handles index failure", func(t *testing.T) {
+		t.Parallel()
+
 		index := mocks.BaselineReader(t)
 		index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
-			return []flow.Identifier{}, nil
+			return nil, mocks.GenericError
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithIndex(index),
+			retriever.WithValidator(validator),
+		)
 
-		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+		_, err := ret.Statement(rosAccountID, rosStart, rosEnd)
 
 		assert.Error(t, err)
 	})
 
-	t.Run("handles transactions index failure", func(t *testing.T) {
-		index := mocks.BaselineReader(t)
-		index.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+	t.Run("handles converter failure", func(t *testing.T) {
+		t.Parallel()
+
+		convert := mocks.BaselineConverter(t)
+		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
 			return nil, mocks.GenericError
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithValidator(validator),
+			retriever.WithConverter(convert),
+		)
 
-		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+		_, err := ret.Statement(rosAccountID, rosStart, rosEnd)
 
 		assert.Error(t, err)
 	})
+}
 
-	t.Run("handles deposit script generate failure", func(t *testing.T) {
+func TestRetriever_Node(t *testing.T) {
+	header := mocks.GenericHeader
+	rosBlockID := mocks.GenericRosBlockID
+	nodeID := mocks.GenericNodeID(0).String()
+
+	nodeInfo := func(role cadence.Value) cadence.Value {
+		names := []string{
+			"id", "role", "networkingAddress", "networkingKey", "stakingKey",
+			"tokensStaked", "tokensCommitted", "tokensUnstaking", "tokensUnstaked",
+			"tokensRewarded", "delegators", "delegatorIDCounter",
+			"tokensRequestedToUnstake", "initialWeight",
+		}
+		fields := make([]cadence.Field, 0, len(names))
+		for _, name := range names {
+			fields = append(fields, cadence.Field{Identifier: name})
+		}
+		values := []cadence.Value{
+			cadence.String(nodeID),
+			role,
+			cadence.String("node.example.com:3569"),
+			cadence.String("networking"),
+			cadence.String("staking"),
+			cadence.UFix64(100),
+			cadence.UFix64(200),
+			cadence.UFix64(300),
+			cadence.UFix64(400),
+			cadence.UFix64(500),
+			cadence.NewArray([]cadence.Value{cadence.NewUInt32(1), cadence.NewUInt32(2)}),
+			cadence.NewUInt32(2),
+			cadence.UFix64(600),
+			cadence.NewUInt64(1000),
+		}
+		return cadence.NewStruct(values).WithType(&cadence.StructType{
+			QualifiedIdentifier: "FlowIDTableStaking.NodeInfo",
+			Fields:              fields,
+		})
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
 		generator := mocks.BaselineGenerator(t)
-		generator.TokensDepositedFunc = func(string) (string, error) {
-			return "", mocks.GenericError
+		generator.GetNodeInfoFunc = func() ([]byte, error) {
+			return []byte(`test`), nil
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithGenerator(generator))
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+			assert.Equal(t, header.Height, height)
+			assert.Equal(t, []byte(`test`), script)
+			require.Len(t, parameters, 1)
+			assert.Equal(t, cadence.String(nodeID), parameters[0])
 
-		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+			return nodeInfo(cadence.NewUInt8(uint8(flow.RoleExecution))), nil
+		}
+
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithGenerator(generator),
+			retriever.WithInvoker(invoker),
+		)
+
+		blockID, node, err := ret.Node(rosBlockID, nodeID)
+
+		require.NoError(t, err)
+		assert.Equal(t, rosBlockID, blockID)
+		require.NotNil(t, node)
+		assert.Equal(t, nodeID, node.ID)
+		assert.Equal(t, flow.RoleExecution.String(), node.Role)
+		assert.Equal(t, "node.example.com:3569", node.NetworkingAddress)
+		assert.Equal(t, uint64(1000), node.InitialWeight)
+		assert.Equal(t, uint(2), node.DelegatorCount)
+		assert.Equal(t, "100", node.TokensStaked.Value)
+		assert.Equal(t, dps.FlowSymbol, node.TokensStaked.Currency.Symbol)
+		assert.Equal(t, "200", node.TokensCommitted.Value)
+		assert.Equal(t, "300", node.TokensUnstaking.Value)
+		assert.Equal(t, "400", node.TokensUnstaked.Value)
+		assert.Equal(t, "500", node.TokensRewarded.Value)
+		assert.Equal(t, "600", node.TokensRequestedToUnstake.Value)
+	})
+
+	t.Run("handles unknown node", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			return nil, errors.New("panic: Specified node ID does not exist in the record")
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithInvoker(invoker))
+
+		_, _, err := ret.Node(rosBlockID, nodeID)
+
+		require.Error(t, err)
+		assert.True(t, errors.As(err, &failure.UnknownNode{}))
+	})
+
+	t.Run("handles invalid block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
+
+		_, _, err := ret.Node(rosBlockID, nodeID)
 
 		assert.Error(t, err)
 	})
 
-	t.Run("handles withdrawal script generate failure", func(t *testing.T) {
+	t.Run("handles generator failure", func(t *testing.T) {
 		t.Parallel()
 
 		generator := mocks.BaselineGenerator(t)
-		generator.TokensWithdrawnFunc = func(string) (string, error) {
-			return "", mocks.GenericError
+		generator.GetNodeInfoFunc = func() ([]byte, error) {
+			return nil, mocks.GenericError
 		}
 
 		ret := retriever.BaselineRetriever(t, retriever.WithGenerator(generator))
 
-		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+		_, _, err := ret.Node(rosBlockID, nodeID)
 
 		assert.Error(t, err)
 	})
 
-	t.Run("handles index event retrieval failure", func(t *testing.T) {
+	t.Run("handles invoker failure", func(t *testing.T) {
 		t.Parallel()
 
-		index := mocks.BaselineReader(t)
-		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
 			return nil, mocks.GenericError
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithIndex(index))
+		ret := retriever.BaselineRetriever(t, retriever.WithInvoker(invoker))
 
-		_, err := ret.Transaction(rosBlockID, mocks.GenericTransactionQualifier(0))
+		_, _, err := ret.Node(rosBlockID, nodeID)
 
-		assert.Error(t, err)
+		require.Error(t, err)
+		assert.False(t, errors.As(err, &failure.UnknownNode{}))
 	})
 
-	t.Run("handles converter failure", func(t *testing.T) {
+	t.Run("handles invalid script result", func(t *testing.T) {
 		t.Parallel()
 
-		convert := mocks.BaselineConverter(t)
-		convert.EventToOperationFunc = func(flow.Event) (*object.Operation, error) {
-			return nil, mocks.GenericError
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			return nodeInfo(cadence.String("execution")), nil
 		}
 
-		ret := retriever.BaselineRetriever(t, retriever.WithConverter(convert))
+		ret := retriever.BaselineRetriever(t, retriever.WithInvoker(invoker))
 
-		_, err := ret.Transaction(mocks.GenericRosBlockID, mocks.GenericTransactionQualifier(0))
+		_, _, err := ret.Node(rosBlockID, nodeID)
 
 		assert.Error(t, err)
 	})
+
+	t.Run("handles unknown role", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			return nodeInfo(cadence.NewUInt8(6)), nil
+		}
+
+		ret := retriever.BaselineRetriever(t, retriever.WithInvoker(invoker))
+
+		assert.NotPanics(t, func() {
+			_, _, err := ret.Node(rosBlockID, nodeID)
+			assert.Error(t, err)
+		})
+	})
 }
 
-func TestRetriever_Sequence(t *testing.T) {
+func TestRetriever_Keys(t *testing.T) {
+	header := mocks.GenericHeader
+	account := mocks.GenericAccount
 	rosBlockID := mocks.GenericRosBlockID
 	accountID := mocks.GenericAccountID(0)
-	address := mocks.GenericAddress(0)
-	key := mocks.GenericAccount.Keys[0]
-	header := mocks.GenericHeader
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
-		validator := mocks.BaselineValidator(t)
-		validator.BlockFunc = func(blockID identifier.Block) (uint64, flow.Identifier, error) {
-			assert.Equal(t, rosBlockID, blockID)
-
-			return header.Height, header.ID(), nil
+		invoker := mocks.BaselineInvoker(t)
+		invoker.AccountFunc = func(height uint64, address flow.Address) (*flow.Account, error) {
+			assert.Equal(t, header.Height, height)
+			assert.Equal(t, account.Address, address)
+
+			revoked := account
+			revoked.Keys = append([]flow.AccountPublicKey{}, account.Keys...)
+			revoked.Keys = append(revoked.Keys, flow.AccountPublicKey{
+				Index:     1,
+				SeqNumber: 3,
+				PublicKey: account.Keys[0].PublicKey,
+				Weight:    1000,
+				Revoked:   true,
+			})
+			return &revoked, nil
 		}
-		validator.AccountFunc = func(gotAccountID identifier.Account) (flow.Address, error) {
-			assert.Equal(t, accountID, gotAccountID)
 
-			return address, nil
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+			assert.Equal(t, accountID, rosAccountID)
+			return account.Address, nil
 		}
 
-		invoker := mocks.BaselineInvoker(t)
-		invoker.KeyFunc = func(height uint64, gotAddress flow.Address, index int) (*flow.AccountPublicKey, error) {
-			assert.Equal(t, header.Height, height)
-			assert.Equal(t, address, gotAddress)
-			assert.Equal(t, 0, index)
+		ret := retriever.BaselineRetriever(t,
+			retriever.WithInvoker(invoker),
+			retriever.WithValidator(validator),
+		)
 
-			return &key, nil
+		keys, err := ret.Keys(rosBlockID, accountID)
+
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, 0, keys[0].Index)
+		assert.Equal(t, account.Keys[0].SeqNumber, keys[0].SequenceNumber)
+		assert.Equal(t, account.Keys[0].PublicKey.String(), keys[0].PublicKey)
+		assert.Equal(t, account.Keys[0].HashAlgo.String(), keys[0].HashAlgorithm)
+		assert.False(t, keys[0].Revoked)
+		assert.Equal(t, 1, keys[1].Index)
+		assert.Equal(t, uint64(3), keys[1].SequenceNumber)
+		assert.Equal(t, 1000, keys[1].Weight)
+		assert.True(t, keys[1].Revoked)
+	})
+
+	t.Run("handles missing account", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.AccountFunc = func(uint64, flow.Address) (*flow.Account, error) {
+			return nil, errors.New("could not get account: [Error Code: 1201] account not found for address 0102030405060708")
 		}
 
-		ret := retriever.New(
-			mocks.GenericParams,
-			mocks.BaselineReader(t),
-			validator,
-			mocks.BaselineGenerator(t),
-			invoker,
-			mocks.BaselineConverter(t),
-		)
+		ret := retriever.BaselineRetriever(t, retriever.WithInvoker(invoker))
 
-		seqNum, err := ret.Sequence(rosBlockID, accountID, 0)
+		keys, err := ret.Keys(rosBlockID, accountID)
 
 		require.NoError(t, err)
-		assert.Equal(t, key.SeqNumber, seqNum)
+		assert.Empty(t, keys)
 	})
 
-	t.Run("handles validator failure on block", func(t *testing.T) {
+	t.Run("handles invalid block", func(t *testing.T) {
 		t.Parallel()
 
 		validator := mocks.BaselineValidator(t)
@@ -933,21 +4645,14 @@ func TestRetriever_Sequence(t *testing.T) {
 			return 0, flow.ZeroID, mocks.GenericError
 		}
 
-		ret := retriever.New(
-			mocks.GenericParams,
-			mocks.BaselineReader(t),
-			validator,
-			mocks.BaselineGenerator(t),
-			mocks.BaselineInvoker(t),
-			mocks.BaselineConverter(t),
-		)
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
 
-		_, err := ret.Sequence(rosBlockID, accountID, 0)
+		_, err := ret.Keys(rosBlockID, accountID)
 
 		assert.Error(t, err)
 	})
 
-	t.Run("handles validator failure on account", func(t *testing.T) {
+	t.Run("handles invalid account", func(t *testing.T) {
 		t.Parallel()
 
 		validator := mocks.BaselineValidator(t)
@@ -955,39 +4660,58 @@ func TestRetriever_Sequence(t *testing.T) {
 			return flow.EmptyAddress, mocks.GenericError
 		}
 
-		ret := retriever.New(
-			mocks.GenericParams,
-			mocks.BaselineReader(t),
-			validator,
-			mocks.BaselineGenerator(t),
-			mocks.BaselineInvoker(t),
-			mocks.BaselineConverter(t),
-		)
+		ret := retriever.BaselineRetriever(t, retriever.WithValidator(validator))
 
-		_, err := ret.Sequence(rosBlockID, accountID, 0)
+		_, err := ret.Keys(rosBlockID, accountID)
 
 		assert.Error(t, err)
 	})
 
-	t.Run("handles invoker failure on key", func(t *testing.T) {
+	t.Run("handles invoker failure", func(t *testing.T) {
 		t.Parallel()
 
 		invoker := mocks.BaselineInvoker(t)
-		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+		invoker.AccountFunc = func(uint64, flow.Address) (*flow.Account, error) {
 			return nil, mocks.GenericError
 		}
 
-		ret := retriever.New(
-			mocks.GenericParams,
-			mocks.BaselineReader(t),
-			mocks.BaselineValidator(t),
-			mocks.BaselineGenerator(t),
-			invoker,
-			mocks.BaselineConverter(t),
-		)
+		ret := retriever.BaselineRetriever(t, retriever.WithInvoker(invoker))
 
-		_, err := ret.Sequence(rosBlockID, accountID, 0)
+		_, err := ret.Keys(rosBlockID, accountID)
 
 		assert.Error(t, err)
 	})
 }
+
+func BenchmarkRetriever_Block(b *testing.B) {
+	rosBlockID := mocks.GenericRosBlockID
+
+	for _, workers := range []uint{1, 4} {
+		ret := retriever.BaselineRetriever(b, retriever.WithWorkers(workers))
+		b.Run(fmt.Sprintf("%d workers", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _, err := ret.Block(rosBlockID)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRetriever_Balances(b *testing.B) {
+	rosBlockID := mocks.GenericRosBlockID
+	accountID := mocks.GenericAccountID(0)
+	currencies := []identifier.Currency{mocks.GenericCurrency}
+
+	ret := retriever.BaselineRetriever(b)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, err := ret.Balances(rosBlockID, accountID, currencies)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package scripts
+
+// Adopted from:
+// https://github.com/onflow/flow-core-contracts/blob/master/transactions/flowServiceAccount/add_account_key.cdc
+
+const addAccountKey = `// This transaction adds a public key to the signer account. The
+// signature and hashing algorithms are given by their raw values.
+
+transaction(publicKey: String, signatureAlgorithm: UInt8, hashAlgorithm: UInt8, weight: UFix64) {
+
+    prepare(signer: AuthAccount) {
+
+        let key = PublicKey(
+            publicKey: publicKey.decodeHex(),
+            signatureAlgorithm: SignatureAlgorithm(rawValue: signatureAlgorithm)
+                ?? panic("Invalid signature algorithm")
+        )
+
+        signer.keys.add(
+            publicKey: key,
+            hashAlgorithm: HashAlgorithm(rawValue: hashAlgorithm)
+                ?? panic("Invalid hashing algorithm"),
+            weight: weight
+        )
+    }
+}
+`
@@ -0,0 +1,49 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package scripts
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// FUSDSymbol is the symbol of the FUSD stablecoin.
+const FUSDSymbol = "FUSD"
+
+// fusd contains the FUSD token for each Flow chain it is deployed on, which is
+// not part of the DPS chain parameters, see:
+// https://github.com/onflow/fusd
+var fusd = map[flow.ChainID]dps.Token{
+	dps.FlowMainnet: fusdToken(flow.HexToAddress("3c5959b568896393")),
+	dps.FlowTestnet: fusdToken(flow.HexToAddress("e223d8a629e49c68")),
+}
+
+func fusdToken(address flow.Address) dps.Token {
+	return dps.Token{
+		Symbol:   FUSDSymbol,
+		Address:  address,
+		Type:     "FUSD",
+		Vault:    "/storage/fusdVault",
+		Receiver: "/public/fusdReceiver",
+		Balance:  "/public/fusdBalance",
+	}
+}
+
+// HasFUSD returns whether the FUSD contract is deployed on the given chain.
+func HasFUSD(chain flow.ChainID) bool {
+	_, ok := fusd[chain]
+	return ok
+}
@@ -17,18 +17,48 @@ package scripts
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"text/template"
 
+	"github.com/onflow/flow-go/model/flow"
+
 	"github.com/optakt/flow-dps/models/dps"
 )
 
+// publicPath matches the literal of a Cadence public path, which is the only
+// kind of path that can be used to borrow capabilities of other accounts.
+var publicPath = regexp.MustCompile(`^/public/[A-Za-z_][A-Za-z0-9_]*$`)
+
 // Generator dynamically generates Cadence scripts from templates.
 type Generator struct {
 	params          dps.Params
 	getBalance      *template.Template
+	getVaults       *template.Template
 	transferTokens  *template.Template
+	transferFUSD    *template.Template
 	tokensDeposited *template.Template
 	tokensWithdrawn *template.Template
+	tokensMinted    *template.Template
+	tokensBurned    *template.Template
+
+	rewardsPaid          *template.Template
+	delegatorRewardsPaid *template.Template
+	feesDeducted         *template.Template
+	epochSetup           *template.Template
+	epochCommit          *template.Template
+	getNodeInfo          *template.Template
+	getLockedAccount     *template.Template
+	getMachineAccounts   *template.Template
+	getStorageInfo       *template.Template
+	getTransactionFee    *template.Template
+
+	addAccountKey    *template.Template
+	revokeAccountKey *template.Template
+
+	stakeNewTokens         *template.Template
+	requestUnstaking       *template.Template
+	withdrawUnstakedTokens *template.Template
+	withdrawRewardedTokens *template.Template
 }
 
 // NewGenerator returns a Generator using the given parameters.
@@ -36,9 +66,32 @@ func NewGenerator(params dps.Params) *Generator {
 	g := Generator{
 		params:          params,
 		getBalance:      template.Must(template.New("get_balance").Parse(getBalance)),
+		getVaults:       template.Must(template.New("get_vault_balances").Parse(getVaultBalances)),
 		transferTokens:  template.Must(template.New("transfer_tokens").Parse(transferTokens)),
+		transferFUSD:    template.Must(template.New("transfer_fusd").Parse(transferFUSD)),
 		tokensDeposited: template.Must(template.New("tokensDeposited").Parse(tokensDeposited)),
 		tokensWithdrawn: template.Must(template.New("withdrawal").Parse(tokensWithdrawn)),
+		tokensMinted:    template.Must(template.New("tokensMinted").Parse(tokensMinted)),
+		tokensBurned:    template.Must(template.New("tokensBurned").Parse(tokensBurned)),
+
+		rewardsPaid:          template.Must(template.New("rewardsPaid").Parse(rewardsPaid)),
+		delegatorRewardsPaid: template.Must(template.New("delegatorRewardsPaid").Parse(delegatorRewardsPaid)),
+		feesDeducted:         template.Must(template.New("feesDeducted").Parse(feesDeducted)),
+		epochSetup:           template.Must(template.New("epochSetup").Parse(epochSetup)),
+		epochCommit:          template.Must(template.New("epochCommit").Parse(epochCommit)),
+		getNodeInfo:          template.Must(template.New("get_node_info").Parse(getNodeInfo)),
+		getLockedAccount:     template.Must(template.New("get_locked_account").Parse(getLockedAccount)),
+		getMachineAccounts:   template.Must(template.New("get_machine_accounts").Parse(getMachineAccounts)),
+		getStorageInfo:       template.Must(template.New("get_storage_info").Parse(getStorageInfo)),
+		getTransactionFee:    template.Must(template.New("get_transaction_fee").Parse(getTransactionFee)),
+
+		addAccountKey:    template.Must(template.New("add_account_key").Parse(addAccountKey)),
+		revokeAccountKey: template.Must(template.New("revoke_account_key").Parse(revokeAccountKey)),
+
+		stakeNewTokens:         template.Must(template.New("stake_new_tokens").Parse(stakeNewTokens)),
+		requestUnstaking:       template.Must(template.New("request_unstaking").Parse(requestUnstaking)),
+		withdrawUnstakedTokens: template.Must(template.New("withdraw_unstaked_tokens").Parse(withdrawUnstakedTokens)),
+		withdrawRewardedTokens: template.Must(template.New("withdraw_rewarded_tokens").Parse(withdrawRewardedTokens)),
 	}
 	return &g
 }
@@ -48,11 +101,49 @@ func (g *Generator) GetBalance(symbol string) ([]byte, error) {
 	return g.bytes(g.getBalance, symbol)
 }
 
+// GetVaultBalances generates a Cadence script to retrieve the balances of all
+// vaults of an account that are exposed on the default balance path of the token,
+// or on one of the given additional public paths.
+func (g *Generator) GetVaultBalances(symbol string, paths []string) ([]byte, error) {
+	// Paths that are given more than once are only included a single time. The
+	// script itself skips vaults that it already read through another path, so
+	// that the same vault is never counted twice.
+	unique := make([]string, 0, len(paths))
+	seen := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		if !publicPath.MatchString(path) {
+			return nil, fmt.Errorf("invalid public path (%s)", path)
+		}
+		_, ok := seen[path]
+		if ok {
+			continue
+		}
+		seen[path] = struct{}{}
+		unique = append(unique, path)
+	}
+	return g.bytes(g.getVaults, symbol, unique...)
+}
+
 // TransferTokens generates a Cadence script to operate a token transfer transaction.
 func (g *Generator) TransferTokens(symbol string) ([]byte, error) {
 	return g.bytes(g.transferTokens, symbol)
 }
 
+// TransferFUSD generates a Cadence script to operate an FUSD transfer transaction.
+// FUSD is not part of the chain parameters, so it is only available on the chains
+// that it is deployed on.
+func (g *Generator) TransferFUSD() ([]byte, error) {
+	token, ok := fusd[g.params.ChainID]
+	if !ok {
+		return nil, fmt.Errorf("unknown FUSD address (chain: %s)", g.params.ChainID)
+	}
+	buf, err := g.render(g.transferFUSD, token)
+	if err != nil {
+		return nil, fmt.Errorf("could not compile template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 // TokensDeposited generates a Cadence script that matches the Flow event for tokens being deposited.
 func (g *Generator) TokensDeposited(symbol string) (string, error) {
 	return g.string(g.tokensDeposited, symbol)
@@ -63,6 +154,117 @@ func (g *Generator) TokensWithdrawn(symbol string) (string, error) {
 	return g.string(g.tokensWithdrawn, symbol)
 }
 
+// TokensMinted generates a Cadence script that matches the Flow event for tokens being minted.
+func (g *Generator) TokensMinted(symbol string) (string, error) {
+	return g.string(g.tokensMinted, symbol)
+}
+
+// TokensBurned generates a Cadence script that matches the Flow event for tokens being burned.
+func (g *Generator) TokensBurned(symbol string) (string, error) {
+	return g.string(g.tokensBurned, symbol)
+}
+
+// RewardsPaid generates a Cadence script that matches the Flow event for staking rewards being paid to a node operator.
+// Staking rewards are always paid in FLOW tokens.
+func (g *Generator) RewardsPaid() (string, error) {
+	return g.string(g.rewardsPaid, dps.FlowSymbol)
+}
+
+// DelegatorRewardsPaid generates a Cadence script that matches the Flow event for staking rewards being paid to a delegator.
+// Staking rewards are always paid in FLOW tokens.
+func (g *Generator) DelegatorRewardsPaid() (string, error) {
+	return g.string(g.delegatorRewardsPaid, dps.FlowSymbol)
+}
+
+// FeesDeducted generates a Cadence script that matches the Flow event for transaction fees being deducted.
+// Transaction fees are always paid in FLOW tokens.
+func (g *Generator) FeesDeducted() (string, error) {
+	return g.string(g.feesDeducted, dps.FlowSymbol)
+}
+
+// EpochSetup generates a Cadence script that matches the Flow service event for the setup of a new epoch.
+func (g *Generator) EpochSetup() (string, error) {
+	return g.string(g.epochSetup, dps.FlowSymbol)
+}
+
+// EpochCommit generates a Cadence script that matches the Flow service event for the commit of a new epoch.
+func (g *Generator) EpochCommit() (string, error) {
+	return g.string(g.epochCommit, dps.FlowSymbol)
+}
+
+// GetNodeInfo generates a Cadence script to retrieve the staking record of a node operator.
+func (g *Generator) GetNodeInfo() ([]byte, error) {
+	return g.bytes(g.getNodeInfo, dps.FlowSymbol)
+}
+
+// GetLockedAccount generates a Cadence script to retrieve the address, locked balance
+// and unlock limit of the locked account held by an account through the LockedTokens contract.
+// Locked tokens are always FLOW tokens.
+func (g *Generator) GetLockedAccount() ([]byte, error) {
+	return g.bytes(g.getLockedAccount, dps.FlowSymbol)
+}
+
+// GetMachineAccounts generates a Cadence script to retrieve the machine accounts and their
+// FLOW balances for all nodes that an account operates through its staking collection.
+func (g *Generator) GetMachineAccounts() ([]byte, error) {
+	return g.bytes(g.getMachineAccounts, dps.FlowSymbol)
+}
+
+// GetStorageInfo generates a Cadence script to retrieve the storage used by an account, its
+// storage capacity and the minimum amount of FLOW tokens reserved to pay for its storage.
+func (g *Generator) GetStorageInfo() ([]byte, error) {
+	_, ok := serviceAccount[g.params.ChainID]
+	if !ok {
+		return nil, fmt.Errorf("unknown service account address (chain: %s)", g.params.ChainID)
+	}
+	return g.bytes(g.getStorageInfo, dps.FlowSymbol)
+}
+
+// GetTransactionFee generates a Cadence script to retrieve the fee that is charged
+// for every transaction, which the FlowServiceAccount contract deducts from the
+// payer and deposits into the vault of the FlowFees contract.
+func (g *Generator) GetTransactionFee() ([]byte, error) {
+	_, ok := serviceAccount[g.params.ChainID]
+	if !ok {
+		return nil, fmt.Errorf("unknown service account address (chain: %s)", g.params.ChainID)
+	}
+	return g.bytes(g.getTransactionFee, dps.FlowSymbol)
+}
+
+// AddAccountKey generates a Cadence script to add a public key to the signer account.
+func (g *Generator) AddAccountKey() ([]byte, error) {
+	return g.bytes(g.addAccountKey, dps.FlowSymbol)
+}
+
+// RevokeAccountKey generates a Cadence script to revoke a public key of the signer account.
+func (g *Generator) RevokeAccountKey() ([]byte, error) {
+	return g.bytes(g.revokeAccountKey, dps.FlowSymbol)
+}
+
+// StakeNewTokens generates a Cadence script to stake new tokens for a node or delegator
+// of the signer's staking collection.
+func (g *Generator) StakeNewTokens() ([]byte, error) {
+	return g.bytes(g.stakeNewTokens, dps.FlowSymbol)
+}
+
+// RequestUnstaking generates a Cadence script to request the unstaking of tokens for a
+// node or delegator of the signer's staking collection.
+func (g *Generator) RequestUnstaking() ([]byte, error) {
+	return g.bytes(g.requestUnstaking, dps.FlowSymbol)
+}
+
+// WithdrawUnstakedTokens generates a Cadence script to withdraw the unstaked tokens of a
+// node or delegator of the signer's staking collection.
+func (g *Generator) WithdrawUnstakedTokens() ([]byte, error) {
+	return g.bytes(g.withdrawUnstakedTokens, dps.FlowSymbol)
+}
+
+// WithdrawRewardedTokens generates a Cadence script to withdraw the rewarded tokens of a
+// node or delegator of the signer's staking collection.
+func (g *Generator) WithdrawRewardedTokens() ([]byte, error) {
+	return g.bytes(g.withdrawRewardedTokens, dps.FlowSymbol)
+}
+
 func (g *Generator) string(template *template.Template, symbol string) (string, error) {
 	buf, err := g.compile(template, symbol)
 	if err != nil {
@@ -71,25 +273,33 @@ func (g *Generator) string(template *template.Template, symbol string) (string,
 	return buf.String(), nil
 }
 
-func (g *Generator) bytes(template *template.Template, symbol string) ([]byte, error) {
-	buf, err := g.compile(template, symbol)
+func (g *Generator) bytes(template *template.Template, symbol string, paths ...string) ([]byte, error) {
+	buf, err := g.compile(template, symbol, paths...)
 	if err != nil {
 		return nil, fmt.Errorf("could not compile template: %w", err)
 	}
 	return buf.Bytes(), nil
 }
 
-func (g *Generator) compile(template *template.Template, symbol string) (*bytes.Buffer, error) {
+func (g *Generator) compile(template *template.Template, symbol string, paths ...string) (*bytes.Buffer, error) {
 	token, ok := g.params.Tokens[symbol]
 	if !ok {
 		return nil, fmt.Errorf("invalid token symbol (%s)", symbol)
 	}
+	return g.render(template, token, paths...)
+}
+
+func (g *Generator) render(template *template.Template, token dps.Token, paths ...string) (*bytes.Buffer, error) {
 	data := struct {
-		Params dps.Params
-		Token  dps.Token
+		Params         dps.Params
+		Token          dps.Token
+		Paths          []string
+		ServiceAccount flow.Address
 	}{
-		Params: g.params,
-		Token:  token,
+		Params:         g.params,
+		Token:          token,
+		Paths:          paths,
+		ServiceAccount: serviceAccount[g.params.ChainID],
 	}
 	buf := &bytes.Buffer{}
 	err := template.Execute(buf, data)
@@ -0,0 +1,53 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package scripts
+
+// Adopted from:
+// https://github.com/onflow/flow-core-contracts/blob/master/transactions/lockedTokens/user/get_locked_account_balance.cdc
+// https://github.com/onflow/flow-core-contracts/blob/master/transactions/lockedTokens/user/get_unlock_limit.cdc
+
+const getLockedAccount = `// This script returns the address, locked balance and unlock limit of the
+// locked account that belongs to an account, or nil if it has none.
+
+import LockedTokens from 0x{{.Params.LockedTokens}}
+
+pub struct LockedAccount {
+    pub let address: Address
+    pub let balance: UFix64
+    pub let unlockLimit: UFix64
+
+    init(address: Address, balance: UFix64, unlockLimit: UFix64) {
+        self.address = address
+        self.balance = balance
+        self.unlockLimit = unlockLimit
+    }
+}
+
+pub fun main(account: Address): LockedAccount? {
+
+    let infoRef = getAccount(account)
+        .getCapability<&LockedTokens.TokenHolder{LockedTokens.LockedAccountInfo}>(LockedTokens.LockedAccountInfoPublicPath)
+        .borrow()
+    if infoRef == nil {
+        return nil
+    }
+
+    return LockedAccount(
+        address: infoRef!.getLockedAccountAddress(),
+        balance: infoRef!.getLockedAccountBalance(),
+        unlockLimit: infoRef!.getUnlockLimit()
+    )
+}
+`
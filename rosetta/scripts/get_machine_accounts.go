@@ -0,0 +1,65 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package scripts
+
+// Adopted from:
+// https://github.com/onflow/flow-core-contracts/blob/master/transactions/stakingCollection/scripts/get_machine_accounts.cdc
+
+// The staking collection contract is deployed to the same account as the locked
+// tokens contract on all networks, so we reuse its address.
+const getMachineAccounts = `// This script returns the machine account address and balance of every node
+// that an account operates through its staking collection.
+
+import FungibleToken from 0x{{.Params.FungibleToken}}
+import {{.Token.Type}} from 0x{{.Token.Address}}
+import FlowStakingCollection from 0x{{.Params.LockedTokens}}
+
+pub struct MachineAccount {
+    pub let nodeID: String
+    pub let role: UInt8
+    pub let address: Address
+    pub let balance: UFix64
+
+    init(nodeID: String, role: UInt8, address: Address, balance: UFix64) {
+        self.nodeID = nodeID
+        self.role = role
+        self.address = address
+        self.balance = balance
+    }
+}
+
+pub fun main(account: Address): [MachineAccount] {
+
+    let machines: [MachineAccount] = []
+    if !FlowStakingCollection.doesAccountHaveStakingCollection(address: account) {
+        return machines
+    }
+
+    let infos = FlowStakingCollection.getMachineAccounts(address: account)
+    for nodeID in infos.keys {
+        let info = infos[nodeID]!
+        let address = info.getAddress()
+        let vaultRef = getAccount(address).getCapability({{.Token.Balance}}).borrow<&{{.Token.Type}}.Vault{FungibleToken.Balance}>()
+        machines.append(MachineAccount(
+            nodeID: nodeID,
+            role: info.role,
+            address: address,
+            balance: vaultRef?.balance ?? 0.0
+        ))
+    }
+
+    return machines
+}
+`
@@ -0,0 +1,50 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package scripts
+
+const getStorageInfo = `// This script returns the storage used by an account, its storage capacity and
+// the amount of FLOW tokens it has to keep in its vault to pay for its storage.
+
+import FlowStorageFees from 0x{{.ServiceAccount}}
+
+pub struct StorageInfo {
+    pub let used: UInt64
+    pub let capacity: UInt64
+    pub let reserved: UFix64
+
+    init(used: UInt64, capacity: UInt64, reserved: UFix64) {
+        self.used = used
+        self.capacity = capacity
+        self.reserved = reserved
+    }
+}
+
+pub fun main(account: Address): StorageInfo {
+
+    let owner = getAccount(account)
+
+    let megabytes = FlowStorageFees.convertUInt64StorageBytesToUFix64Megabytes(owner.storageUsed)
+    var reserved = megabytes / FlowStorageFees.storageMegaBytesPerReservedFLOW
+    if reserved < FlowStorageFees.minimumStorageReservation {
+        reserved = FlowStorageFees.minimumStorageReservation
+    }
+
+    return StorageInfo(
+        used: owner.storageUsed,
+        capacity: owner.storageCapacity,
+        reserved: reserved
+    )
+}
+`
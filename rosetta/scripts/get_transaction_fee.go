@@ -0,0 +1,25 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package scripts
+
+const getTransactionFee = `// This script returns the fee charged for every transaction, regardless of its
+// gas limit, which is deducted from the payer of the transaction.
+
+import FlowServiceAccount from 0x{{.ServiceAccount}}
+
+pub fun main(): UFix64 {
+    return FlowServiceAccount.transactionFee
+}
+`
@@ -0,0 +1,45 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package scripts
+
+const getVaultBalances = `// This script reads the balance of every vault of an account exposed
+// through its default balance path or one of the given public paths, keyed by path.
+// Vaults exposed on more than one of these paths are only included once,
+// under the first path they were found on.
+
+import FungibleToken from 0x{{.Params.FungibleToken}}
+import {{.Token.Type}} from 0x{{.Token.Address}}
+
+pub fun main(account: Address): {String: UFix64} {
+
+    let owner = getAccount(account)
+    let balances: {String: UFix64} = {}
+    let seen: {UInt64: Bool} = {}
+
+    if let vaultRef = owner.getCapability({{.Token.Balance}}).borrow<&{{.Token.Type}}.Vault{FungibleToken.Balance}>() {
+        balances["{{.Token.Balance}}"] = vaultRef.balance
+        seen[vaultRef.uuid] = true
+    }
+{{range .Paths}}{{if ne . $.Token.Balance}}
+    if let vaultRef = owner.getCapability({{.}}).borrow<&{{$.Token.Type}}.Vault{FungibleToken.Balance}>() {
+        if seen[vaultRef.uuid] == nil {
+            balances["{{.}}"] = vaultRef.balance
+            seen[vaultRef.uuid] = true
+        }
+    }
+{{end}}{{end}}
+    return balances
+}
+`
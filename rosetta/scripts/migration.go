@@ -0,0 +1,116 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package scripts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// Migration describes the core contract addresses and token types that were in
+// effect for all heights below its height, before the contracts were moved to
+// their current addresses or renamed. Empty fields keep the current value.
+type Migration struct {
+	Height        uint64                    `json:"height"`
+	FungibleToken flow.Address              `json:"fungible_token"`
+	FlowFees      flow.Address              `json:"flow_fees"`
+	StakingTable  flow.Address              `json:"staking_table"`
+	LockedTokens  flow.Address              `json:"locked_tokens"`
+	StakingProxy  flow.Address              `json:"staking_proxy"`
+	Tokens        map[string]TokenMigration `json:"tokens"`
+}
+
+// TokenMigration describes the address and contract name that a token had
+// before a migration. Empty fields keep the current value.
+type TokenMigration struct {
+	Address flow.Address `json:"address"`
+	Type    string       `json:"type"`
+}
+
+// LoadMigrations reads the list of contract migrations from the JSON
+// configuration at the given path, sorted by height.
+func LoadMigrations(path string) ([]Migration, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read migration configuration: %w", err)
+	}
+
+	var migrations []Migration
+	err = json.Unmarshal(data, &migrations)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode migration configuration: %w", err)
+	}
+
+	sort.Slice(migrations, func(i int, j int) bool {
+		return migrations[i].Height < migrations[j].Height
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i-1].Height == migrations[i].Height {
+			return nil, fmt.Errorf("duplicate migration height (height: %d)", migrations[i].Height)
+		}
+	}
+
+	return migrations, nil
+}
+
+// Params returns the given chain parameters with the contract addresses and
+// token types of the migration applied to them.
+func (m Migration) Params(params dps.Params) (dps.Params, error) {
+
+	if m.FungibleToken != flow.EmptyAddress {
+		params.FungibleToken = m.FungibleToken
+	}
+	if m.FlowFees != flow.EmptyAddress {
+		params.FlowFees = m.FlowFees
+	}
+	if m.StakingTable != flow.EmptyAddress {
+		params.StakingTable = m.StakingTable
+	}
+	if m.LockedTokens != flow.EmptyAddress {
+		params.LockedTokens = m.LockedTokens
+	}
+	if m.StakingProxy != flow.EmptyAddress {
+		params.StakingProxy = m.StakingProxy
+	}
+
+	// The tokens are copied so that the current parameters are left untouched.
+	tokens := make(map[string]dps.Token, len(params.Tokens))
+	for symbol, token := range params.Tokens {
+		tokens[symbol] = token
+	}
+	for symbol, migration := range m.Tokens {
+		token, ok := tokens[symbol]
+		if !ok {
+			return dps.Params{}, fmt.Errorf("unknown migrated token (symbol: %s, height: %d)", symbol, m.Height)
+		}
+		if migration.Address != flow.EmptyAddress {
+			token.Address = migration.Address
+		}
+		if migration.Type != "" {
+			token.Type = migration.Type
+		}
+		tokens[symbol] = token
+	}
+	params.Tokens = tokens
+
+	return params, nil
+}
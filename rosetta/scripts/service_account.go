@@ -0,0 +1,31 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package scripts
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// serviceAccount contains the address of the service account for each known
+// Flow chain, which is not part of the DPS chain parameters. The service account
+// holds the FlowServiceAccount and FlowStorageFees contracts, see:
+// https://docs.onflow.org/core-contracts
+var serviceAccount = map[flow.ChainID]flow.Address{
+	dps.FlowMainnet:  flow.HexToAddress("e467b9dd11fa00df"),
+	dps.FlowTestnet:  flow.HexToAddress("8c5303eaa26202d6"),
+	dps.FlowLocalnet: flow.HexToAddress("f8d6e0586b0a20c7"),
+}
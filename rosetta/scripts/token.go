@@ -0,0 +1,59 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package scripts
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+var (
+	// storagePath matches the literal of a Cadence storage path.
+	storagePath = regexp.MustCompile(`^/storage/[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// identifier matches a Cadence identifier, such as a contract name.
+	identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// ValidateToken checks that the given token definition can be used to generate
+// scripts, which means that its contract address, contract name and paths are
+// all valid. Whether the contract is actually deployed is not checked.
+func ValidateToken(token dps.Token) error {
+
+	if token.Symbol == "" {
+		return fmt.Errorf("token symbol is empty")
+	}
+	if token.Address == flow.EmptyAddress {
+		return fmt.Errorf("token contract address is empty (symbol: %s)", token.Symbol)
+	}
+	if !identifier.MatchString(token.Type) {
+		return fmt.Errorf("invalid token contract name (symbol: %s, type: %s)", token.Symbol, token.Type)
+	}
+	if !storagePath.MatchString(token.Vault) {
+		return fmt.Errorf("invalid token vault path (symbol: %s, path: %s)", token.Symbol, token.Vault)
+	}
+	if !publicPath.MatchString(token.Receiver) {
+		return fmt.Errorf("invalid token receiver path (symbol: %s, path: %s)", token.Symbol, token.Receiver)
+	}
+	if !publicPath.MatchString(token.Balance) {
+		return fmt.Errorf("invalid token balance path (symbol: %s, path: %s)", token.Symbol, token.Balance)
+	}
+
+	return nil
+}
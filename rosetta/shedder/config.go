@@ -0,0 +1,66 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package shedder
+
+import (
+	"time"
+)
+
+// Config contains the configuration options for the load shedder.
+type Config struct {
+	MaxInflight uint
+	MaxLatency  time.Duration
+	Window      time.Duration
+	Queues      []Threshold
+}
+
+// Threshold is the number of waiting items above which a queue is considered
+// overloaded.
+type Threshold struct {
+	Queue   Queue
+	Waiting uint
+}
+
+// WithMaxInflight sets the maximum number of requests that are processed at the
+// same time, after which new requests are shed. Zero means no limit.
+func WithMaxInflight(max uint) func(*Config) {
+	return func(c *Config) {
+		c.MaxInflight = max
+	}
+}
+
+// WithMaxLatency sets the 99th percentile latency above which new requests are
+// shed until latency recovers. Zero disables latency-based shedding.
+func WithMaxLatency(max time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.MaxLatency = max
+	}
+}
+
+// WithWindow sets the duration over which request latencies are observed to
+// compute the 99th percentile.
+func WithWindow(window time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.Window = window
+	}
+}
+
+// WithQueue adds an internal queue to watch, so that new requests are shed as
+// long as more than the given number of items are waiting in it.
+func WithQueue(queue Queue, waiting uint) func(*Config) {
+	return func(c *Config) {
+		c.Queues = append(c.Queues, Threshold{Queue: queue, Waiting: waiting})
+	}
+}
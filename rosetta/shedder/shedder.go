@@ -0,0 +1,177 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package shedder
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+const (
+	inflightExceeded = "too many requests in flight, retry later"
+	queueExceeded    = "internal queue is full, retry later"
+	latencyDegraded  = "request latency is degraded, retry later"
+)
+
+// minSamples is the number of latency samples needed within the window before
+// the 99th percentile is considered meaningful, while maxSamples bounds the
+// memory used to keep them under high throughput.
+const (
+	minSamples = 100
+	maxSamples = 10_000
+)
+
+// Shedder detects overload of the service and rejects new requests early, with
+// a retriable error, so that the requests that are already in flight can finish
+// instead of every request timing out. The service is considered overloaded
+// when too many requests are in flight, when one of its internal queues is
+// above its threshold, or when the 99th percentile latency is degraded.
+type Shedder struct {
+	cfg Config
+	now func() time.Time
+
+	mu       *sync.Mutex
+	inflight uint
+	samples  []sample
+	checked  time.Time
+	p99      time.Duration
+}
+
+// sample is the latency of a single request, along with when it finished.
+type sample struct {
+	finished time.Time
+	latency  time.Duration
+}
+
+// New creates a new load shedder with the given options.
+func New(options ...func(*Config)) *Shedder {
+
+	cfg := Config{
+		MaxInflight: 0,
+		MaxLatency:  0,
+		Window:      10 * time.Second,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	s := Shedder{
+		cfg: cfg,
+		now: time.Now,
+		mu:  &sync.Mutex{},
+	}
+
+	return &s
+}
+
+// Admit checks whether a new request can be processed. If so, it returns the
+// function to call once the request is done; otherwise, it returns an error.
+func (s *Shedder) Admit() (func(), error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.MaxInflight > 0 && s.inflight >= s.cfg.MaxInflight {
+		return nil, failure.Overloaded{
+			Capacity:    s.cfg.MaxInflight,
+			Description: failure.NewDescription(inflightExceeded),
+		}
+	}
+
+	for _, threshold := range s.cfg.Queues {
+		waiting := threshold.Queue.Waiting()
+		if waiting > threshold.Waiting {
+			return nil, failure.Overloaded{
+				Capacity: threshold.Waiting,
+				Description: failure.NewDescription(queueExceeded,
+					failure.WithInt("waiting", int(waiting)),
+				),
+			}
+		}
+	}
+
+	// While latency is degraded, we still let a request through when nothing
+	// else is in flight, so that fresh samples show when latency recovers.
+	if s.degraded() && s.inflight > 0 {
+		return nil, failure.Overloaded{
+			Capacity: s.inflight,
+			Description: failure.NewDescription(latencyDegraded,
+				failure.WithString("p99_latency", s.p99.String()),
+				failure.WithString("max_latency", s.cfg.MaxLatency.String()),
+			),
+		}
+	}
+
+	s.inflight++
+	start := s.now()
+	done := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.inflight--
+		if s.cfg.MaxLatency > 0 {
+			finished := s.now()
+			s.samples = append(s.samples, sample{finished: finished, latency: finished.Sub(start)})
+			if len(s.samples) > maxSamples {
+				s.samples = s.samples[1:]
+			}
+		}
+	}
+
+	return done, nil
+}
+
+// degraded returns whether the 99th percentile latency over the window is above
+// the maximum. Computing the percentile requires sorting the samples, so it is
+// only recomputed once per second. It must be called with the mutex held.
+func (s *Shedder) degraded() bool {
+
+	if s.cfg.MaxLatency == 0 {
+		return false
+	}
+
+	now := s.now()
+	if now.Sub(s.checked) < time.Second {
+		return s.p99 > s.cfg.MaxLatency
+	}
+	s.checked = now
+
+	// Drop the samples that are out of the window; they are kept in order of
+	// completion, so the oldest ones are at the start.
+	cutoff := now.Add(-s.cfg.Window)
+	expired := sort.Search(len(s.samples), func(i int) bool {
+		return s.samples[i].finished.After(cutoff)
+	})
+	s.samples = append(s.samples[:0], s.samples[expired:]...)
+
+	if len(s.samples) < minSamples {
+		s.p99 = 0
+		return false
+	}
+
+	latencies := make([]time.Duration, 0, len(s.samples))
+	for _, sample := range s.samples {
+		latencies = append(latencies, sample.latency)
+	}
+	sort.Slice(latencies, func(i int, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	s.p99 = latencies[len(latencies)*99/100]
+
+	return s.p99 > s.cfg.MaxLatency
+}
@@ -0,0 +1,220 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package shedder
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+func TestNew(t *testing.T) {
+	queue := &queue{}
+
+	s := New(
+		WithMaxInflight(10),
+		WithMaxLatency(time.Second),
+		WithWindow(time.Minute),
+		WithQueue(queue, 5),
+	)
+
+	assert.Equal(t, uint(10), s.cfg.MaxInflight)
+	assert.Equal(t, time.Second, s.cfg.MaxLatency)
+	assert.Equal(t, time.Minute, s.cfg.Window)
+	assert.Equal(t, []Threshold{{Queue: queue, Waiting: 5}}, s.cfg.Queues)
+}
+
+func TestShedder_Admit(t *testing.T) {
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		s := BaselineShedder(t)
+
+		done, err := s.Admit()
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), s.inflight)
+
+		done()
+		assert.Equal(t, uint(0), s.inflight)
+	})
+
+	t.Run("sheds requests above maximum in flight", func(t *testing.T) {
+		t.Parallel()
+
+		s := BaselineShedder(t, WithInflight(2))
+
+		_, err := s.Admit()
+		require.NoError(t, err)
+		done, err := s.Admit()
+		require.NoError(t, err)
+
+		_, err = s.Admit()
+		assert.ErrorAs(t, err, &failure.Overloaded{})
+
+		done()
+		_, err = s.Admit()
+		assert.NoError(t, err)
+	})
+
+	t.Run("sheds requests while queue is above threshold", func(t *testing.T) {
+		t.Parallel()
+
+		queue := &queue{waiting: 6}
+		s := BaselineShedder(t, WithThreshold(queue, 5))
+
+		_, err := s.Admit()
+		assert.ErrorAs(t, err, &failure.Overloaded{})
+
+		queue.set(5)
+		_, err = s.Admit()
+		assert.NoError(t, err)
+	})
+
+	t.Run("sheds requests while latency is degraded", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &clock{now: time.Unix(1_000_000, 0)}
+		s := BaselineShedder(t, WithLatency(time.Second), WithPeriod(time.Hour), WithClock(clock))
+
+		// Fill the window with slow requests.
+		for i := 0; i < minSamples; i++ {
+			done, err := s.Admit()
+			require.NoError(t, err)
+			clock.advance(2 * time.Second)
+			done()
+		}
+		clock.advance(time.Second)
+
+		// The first request goes through, as nothing else is in flight, but
+		// the next one is shed while it is running.
+		done, err := s.Admit()
+		require.NoError(t, err)
+		_, err = s.Admit()
+		assert.ErrorAs(t, err, &failure.Overloaded{})
+		done()
+
+		// Once the slow samples are out of the window, requests go through
+		// again.
+		clock.advance(s.cfg.Window)
+		_, err = s.Admit()
+		require.NoError(t, err)
+		_, err = s.Admit()
+		assert.NoError(t, err)
+	})
+
+	t.Run("ignores latency with too few samples", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &clock{now: time.Unix(1_000_000, 0)}
+		s := BaselineShedder(t, WithLatency(time.Second), WithClock(clock))
+
+		done, err := s.Admit()
+		require.NoError(t, err)
+		clock.advance(time.Minute)
+		done()
+		clock.advance(time.Second)
+
+		_, err = s.Admit()
+		require.NoError(t, err)
+		_, err = s.Admit()
+		assert.NoError(t, err)
+	})
+}
+
+func BaselineShedder(t *testing.T, opts ...func(*Shedder)) *Shedder {
+	t.Helper()
+
+	s := Shedder{
+		cfg: Config{Window: 10 * time.Second},
+		now: time.Now,
+		mu:  &sync.Mutex{},
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return &s
+}
+
+func WithInflight(max uint) func(*Shedder) {
+	return func(s *Shedder) {
+		s.cfg.MaxInflight = max
+	}
+}
+
+func WithLatency(max time.Duration) func(*Shedder) {
+	return func(s *Shedder) {
+		s.cfg.MaxLatency = max
+	}
+}
+
+func WithThreshold(queue Queue, waiting uint) func(*Shedder) {
+	return func(s *Shedder) {
+		s.cfg.Queues = append(s.cfg.Queues, Threshold{Queue: queue, Waiting: waiting})
+	}
+}
+
+func WithPeriod(window time.Duration) func(*Shedder) {
+	return func(s *Shedder) {
+		s.cfg.Window = window
+	}
+}
+
+func WithClock(clock *clock) func(*Shedder) {
+	return func(s *Shedder) {
+		s.now = clock.time
+	}
+}
+
+type queue struct {
+	mu      sync.Mutex
+	waiting uint
+}
+
+func (q *queue) Waiting() uint {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.waiting
+}
+
+func (q *queue) set(waiting uint) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.waiting = waiting
+}
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) time() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Export writes a full backup of the given database to the writer, compressed
+// with zstd. It only reads from the database, which can therefore be opened
+// read-only, and returns the version of the latest entry that was written.
+func Export(db *badger.DB, w io.Writer) (uint64, error) {
+
+	compress, err := zstd.NewWriter(w)
+	if err != nil {
+		return 0, fmt.Errorf("could not initialize compressor: %w", err)
+	}
+
+	version, err := db.Backup(compress, 0)
+	if err != nil {
+		_ = compress.Close()
+		return 0, fmt.Errorf("could not back up database: %w", err)
+	}
+
+	// Closing the compressor flushes the last frame to the writer, so the
+	// snapshot is incomplete unless it succeeds.
+	err = compress.Close()
+	if err != nil {
+		return 0, fmt.Errorf("could not flush compressor: %w", err)
+	}
+
+	return version, nil
+}
+
+// Import loads a snapshot that was written by Export into the given database.
+// The database should be empty, as entries already in it are overwritten by
+// the ones in the snapshot, but are otherwise left in place.
+func Import(db *badger.DB, r io.Reader) error {
+
+	decompress, err := zstd.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("could not initialize decompressor: %w", err)
+	}
+	defer decompress.Close()
+
+	err = db.Load(decompress, runtime.GOMAXPROCS(0))
+	if err != nil {
+		return fmt.Errorf("could not load database: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package snapshot_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/snapshot"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestSnapshot(t *testing.T) {
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		source := inMemoryDB(t)
+		err := source.Update(func(tx *badger.Txn) error {
+			err := tx.Set([]byte("first"), mocks.GenericBytes)
+			if err != nil {
+				return err
+			}
+			return tx.Set([]byte("second"), []byte("value"))
+		})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		_, err = snapshot.Export(source, &buf)
+		require.NoError(t, err)
+
+		target := inMemoryDB(t)
+		err = snapshot.Import(target, &buf)
+		require.NoError(t, err)
+
+		want := map[string][]byte{
+			"first":  mocks.GenericBytes,
+			"second": []byte("value"),
+		}
+		got := make(map[string][]byte)
+		err = target.View(func(tx *badger.Txn) error {
+			it := tx.NewIterator(badger.DefaultIteratorOptions)
+			defer it.Close()
+			for it.Rewind(); it.Valid(); it.Next() {
+				value, err := it.Item().ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				got[string(it.Item().Key())] = value
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("handles invalid snapshot", func(t *testing.T) {
+		t.Parallel()
+
+		target := inMemoryDB(t)
+		err := snapshot.Import(target, bytes.NewReader(mocks.GenericBytes))
+
+		assert.Error(t, err)
+	})
+}
+
+func inMemoryDB(t *testing.T) *badger.DB {
+	t.Helper()
+
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := badger.Open(opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
@@ -0,0 +1,417 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package sporks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+const (
+	heightUncovered = "block height is not covered by any spork"
+)
+
+// Registry is a DPS index reader that routes each read to the index of the
+// spork that it belongs to. Reads by height go to the spork with the highest
+// root height at or below the given height, while reads by identifier go to
+// each spork in turn, from the most recent to the oldest, until one succeeds.
+// Heights that fall before the first spork or in a gap between two sporks are
+// rejected with an error that lists the covered height ranges.
+type Registry struct {
+	sporks []Spork
+
+	// The first indexed height of each spork, and the last indexed height of
+	// each spork but the latest, never change once known, so we cache them.
+	mutex  sync.Mutex
+	firsts map[int]uint64
+	lasts  map[int]uint64
+}
+
+// New creates a new registry with the given sporks. It fails if there are no
+// sporks, if a spork has no name or index, or if two sporks share the same name
+// or root height. Sporks without a root height use the first height of their
+// index as root height.
+func New(sporks ...Spork) (*Registry, error) {
+
+	if len(sporks) == 0 {
+		return nil, fmt.Errorf("spork list is empty")
+	}
+
+	sorted := make([]Spork, len(sporks))
+	copy(sorted, sporks)
+	for i, spork := range sorted {
+		if spork.RootHeight != 0 || spork.Index == nil {
+			continue
+		}
+		root, err := spork.Index.First()
+		if err != nil {
+			return nil, fmt.Errorf("could not detect spork root height (name: %s): %w", spork.Name, err)
+		}
+		sorted[i].RootHeight = root
+	}
+	sort.Slice(sorted, func(i int, j int) bool {
+		return sorted[i].RootHeight < sorted[j].RootHeight
+	})
+
+	names := make(map[string]struct{}, len(sorted))
+	for i, spork := range sorted {
+		if spork.Name == "" {
+			return nil, fmt.Errorf("spork name is empty (root height: %d)", spork.RootHeight)
+		}
+		if spork.Index == nil {
+			return nil, fmt.Errorf("spork index is missing (name: %s)", spork.Name)
+		}
+		_, ok := names[spork.Name]
+		if ok {
+			return nil, fmt.Errorf("duplicate spork name (name: %s)", spork.Name)
+		}
+		if i > 0 && sorted[i-1].RootHeight == spork.RootHeight {
+			return nil, fmt.Errorf("duplicate spork root height (name: %s, root height: %d)", spork.Name, spork.RootHeight)
+		}
+		names[spork.Name] = struct{}{}
+	}
+
+	r := Registry{
+		sporks: sorted,
+		firsts: make(map[int]uint64, len(sorted)),
+		lasts:  make(map[int]uint64, len(sorted)),
+	}
+
+	return &r, nil
+}
+
+// Load reads the list of sporks from the JSON configuration at the given path.
+// The configuration is a list of sporks, each with a name, an optional root
+// height, the address of its Access API and either the address of its DPS API or the
+// directory of its index database. The indexes of the sporks still have to be set
+// before creating a registry with them.
+func Load(path string) ([]Spork, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read spork configuration: %w", err)
+	}
+
+	var sporks []Spork
+	err = json.Unmarshal(data, &sporks)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode spork configuration: %w", err)
+	}
+
+	return sporks, nil
+}
+
+// Sporks returns all sporks of the registry, in order of root height.
+func (r *Registry) Sporks() []Spork {
+	return r.sporks
+}
+
+// Latest returns the most recent spork of the registry.
+func (r *Registry) Latest() Spork {
+	return r.sporks[len(r.sporks)-1]
+}
+
+// Spork returns the spork that the given height belongs to. If the height is
+// before the first spork, or between the end of a spork's index and the root
+// of the next spork, it returns an uncovered block failure.
+func (r *Registry) Spork(height uint64) (Spork, error) {
+
+	// Find the first spork that starts above the height; the one before it is
+	// the spork that contains the height.
+	index := sort.Search(len(r.sporks), func(i int) bool {
+		return r.sporks[i].RootHeight > height
+	})
+	if index == 0 {
+		return Spork{}, r.uncovered(height)
+	}
+	index--
+
+	// The index of a spork might start after its root height, and the index of
+	// a past spork might end before the root height of the next spork.
+	first, err := r.first(index)
+	if err != nil {
+		return Spork{}, fmt.Errorf("could not get first height of spork (name: %s): %w", r.sporks[index].Name, err)
+	}
+	if height < first {
+		return Spork{}, r.uncovered(height)
+	}
+	if index == len(r.sporks)-1 {
+		return r.sporks[index], nil
+	}
+	last, err := r.last(index)
+	if err != nil {
+		return Spork{}, fmt.Errorf("could not get last height of spork (name: %s): %w", r.sporks[index].Name, err)
+	}
+	if height > last {
+		return Spork{}, r.uncovered(height)
+	}
+
+	return r.sporks[index], nil
+}
+
+// First returns the first indexed height of the oldest spork.
+func (r *Registry) First() (uint64, error) {
+	return r.sporks[0].Index.First()
+}
+
+// Last returns the last indexed height of the most recent spork.
+func (r *Registry) Last() (uint64, error) {
+	return r.Latest().Index.Last()
+}
+
+// HeightForBlock returns the height of the block with the given ID.
+func (r *Registry) HeightForBlock(blockID flow.Identifier) (uint64, error) {
+	var height uint64
+	err := r.each(func(index dps.Reader) error {
+		var err error
+		height, err = index.HeightForBlock(blockID)
+		return err
+	})
+	return height, err
+}
+
+// HeightForTransaction returns the height of the block that contains the transaction with the given ID.
+func (r *Registry) HeightForTransaction(txID flow.Identifier) (uint64, error) {
+	var height uint64
+	err := r.each(func(index dps.Reader) error {
+		var err error
+		height, err = index.HeightForTransaction(txID)
+		return err
+	})
+	return height, err
+}
+
+// Commit returns the state commitment at the given height.
+func (r *Registry) Commit(height uint64) (flow.StateCommitment, error) {
+	index, err := r.index(height)
+	if err != nil {
+		return flow.DummyStateCommitment, err
+	}
+	return index.Commit(height)
+}
+
+// Header returns the header at the given height.
+func (r *Registry) Header(height uint64) (*flow.Header, error) {
+	index, err := r.index(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.Header(height)
+}
+
+// Events returns the events of the given types at the given height.
+func (r *Registry) Events(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+	index, err := r.index(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.Events(height, types...)
+}
+
+// Values returns the register values for the given paths at the given height.
+func (r *Registry) Values(height uint64, paths []ledger.Path) ([]ledger.Value, error) {
+	index, err := r.index(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.Values(height, paths)
+}
+
+// Collection returns the collection with the given ID.
+func (r *Registry) Collection(collID flow.Identifier) (*flow.LightCollection, error) {
+	var collection *flow.LightCollection
+	err := r.each(func(index dps.Reader) error {
+		var err error
+		collection, err = index.Collection(collID)
+		return err
+	})
+	return collection, err
+}
+
+// Guarantee returns the guarantee for the collection with the given ID.
+func (r *Registry) Guarantee(collID flow.Identifier) (*flow.CollectionGuarantee, error) {
+	var guarantee *flow.CollectionGuarantee
+	err := r.each(func(index dps.Reader) error {
+		var err error
+		guarantee, err = index.Guarantee(collID)
+		return err
+	})
+	return guarantee, err
+}
+
+// Transaction returns the transaction with the given ID.
+func (r *Registry) Transaction(txID flow.Identifier) (*flow.TransactionBody, error) {
+	var transaction *flow.TransactionBody
+	err := r.each(func(index dps.Reader) error {
+		var err error
+		transaction, err = index.Transaction(txID)
+		return err
+	})
+	return transaction, err
+}
+
+// Seal returns the seal with the given ID.
+func (r *Registry) Seal(sealID flow.Identifier) (*flow.Seal, error) {
+	var seal *flow.Seal
+	err := r.each(func(index dps.Reader) error {
+		var err error
+		seal, err = index.Seal(sealID)
+		return err
+	})
+	return seal, err
+}
+
+// Result returns the result of the transaction with the given ID.
+func (r *Registry) Result(txID flow.Identifier) (*flow.TransactionResult, error) {
+	var result *flow.TransactionResult
+	err := r.each(func(index dps.Reader) error {
+		var err error
+		result, err = index.Result(txID)
+		return err
+	})
+	return result, err
+}
+
+// CollectionsByHeight returns the IDs of the collections at the given height.
+func (r *Registry) CollectionsByHeight(height uint64) ([]flow.Identifier, error) {
+	index, err := r.index(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.CollectionsByHeight(height)
+}
+
+// TransactionsByHeight returns the IDs of the transactions at the given height.
+func (r *Registry) TransactionsByHeight(height uint64) ([]flow.Identifier, error) {
+	index, err := r.index(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.TransactionsByHeight(height)
+}
+
+// SealsByHeight returns the IDs of the seals at the given height.
+func (r *Registry) SealsByHeight(height uint64) ([]flow.Identifier, error) {
+	index, err := r.index(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.SealsByHeight(height)
+}
+
+// first returns the first indexed height of the spork at the given position.
+func (r *Registry) first(index int) (uint64, error) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	first, ok := r.firsts[index]
+	if ok {
+		return first, nil
+	}
+	first, err := r.sporks[index].Index.First()
+	if err != nil {
+		return 0, err
+	}
+	r.firsts[index] = first
+
+	return first, nil
+}
+
+// last returns the last indexed height of the spork at the given position. It
+// is only cached for past sporks, as the index of the latest spork keeps growing.
+func (r *Registry) last(index int) (uint64, error) {
+
+	if index == len(r.sporks)-1 {
+		return r.sporks[index].Index.Last()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	last, ok := r.lasts[index]
+	if ok {
+		return last, nil
+	}
+	last, err := r.sporks[index].Index.Last()
+	if err != nil {
+		return 0, err
+	}
+	r.lasts[index] = last
+
+	return last, nil
+}
+
+// uncovered returns the failure for a height that is not covered by any spork,
+// with the height ranges that are covered as details.
+func (r *Registry) uncovered(height uint64) error {
+
+	ranges := make([]string, 0, len(r.sporks))
+	for i, spork := range r.sporks {
+		first, err := r.first(i)
+		if err != nil {
+			return fmt.Errorf("could not get first height of spork (name: %s): %w", spork.Name, err)
+		}
+		last, err := r.last(i)
+		if err != nil {
+			return fmt.Errorf("could not get last height of spork (name: %s): %w", spork.Name, err)
+		}
+		ranges = append(ranges, fmt.Sprintf("%s: %d-%d", spork.Name, first, last))
+	}
+
+	return failure.UncoveredBlock{
+		Index: height,
+		Description: failure.NewDescription(heightUncovered,
+			failure.WithUint64("block_index", height),
+			failure.WithStrings("available_ranges", ranges...),
+		),
+	}
+}
+
+// index returns the index of the spork that the given height belongs to.
+func (r *Registry) index(height uint64) (dps.Reader, error) {
+	spork, err := r.Spork(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not find spork: %w", err)
+	}
+	return spork.Index, nil
+}
+
+// each calls the given read function on the index of each spork, from the most
+// recent to the oldest, until it succeeds. If it fails for all sporks, the
+// error of the most recent spork is returned.
+func (r *Registry) each(read func(index dps.Reader) error) error {
+	var first error
+	for i := len(r.sporks) - 1; i >= 0; i-- {
+		err := read(r.sporks[i].Index)
+		if err == nil {
+			return nil
+		}
+		if first == nil {
+			first = fmt.Errorf("could not read from any spork (latest: %s): %w", r.sporks[i].Name, err)
+		}
+	}
+	return first
+}
@@ -0,0 +1,323 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package sporks_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/sporks"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		later := sporks.Spork{Name: "mainnet-2", RootHeight: 200, Index: mocks.BaselineReader(t)}
+		earlier := sporks.Spork{Name: "mainnet-1", RootHeight: 100, Index: mocks.BaselineReader(t)}
+
+		registry, err := sporks.New(later, earlier)
+
+		require.NoError(t, err)
+		assert.Equal(t, []sporks.Spork{earlier, later}, registry.Sporks())
+		assert.Equal(t, later, registry.Latest())
+	})
+
+	t.Run("detects missing root height", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.FirstFunc = func() (uint64, error) {
+			return 300, nil
+		}
+
+		earlier := sporks.Spork{Name: "mainnet-1", RootHeight: 100, Index: mocks.BaselineReader(t)}
+		later := sporks.Spork{Name: "mainnet-2", Index: index}
+
+		registry, err := sporks.New(later, earlier)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint64(300), registry.Latest().RootHeight)
+		assert.Equal(t, "mainnet-2", registry.Latest().Name)
+	})
+
+	t.Run("handles root height detection failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.FirstFunc = func() (uint64, error) {
+			return 0, mocks.GenericError
+		}
+
+		_, err := sporks.New(sporks.Spork{Name: "mainnet-1", Index: index})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles empty list", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := sporks.New()
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles missing name", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := sporks.New(sporks.Spork{RootHeight: 100, Index: mocks.BaselineReader(t)})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles missing index", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := sporks.New(sporks.Spork{Name: "mainnet-1", RootHeight: 100})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles duplicate name", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := sporks.New(
+			sporks.Spork{Name: "mainnet-1", RootHeight: 100, Index: mocks.BaselineReader(t)},
+			sporks.Spork{Name: "mainnet-1", RootHeight: 200, Index: mocks.BaselineReader(t)},
+		)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles duplicate root height", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := sporks.New(
+			sporks.Spork{Name: "mainnet-1", RootHeight: 100, Index: mocks.BaselineReader(t)},
+			sporks.Spork{Name: "mainnet-2", RootHeight: 100, Index: mocks.BaselineReader(t)},
+		)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "sporks.json")
+		data := []byte(`[{"name":"mainnet-1","root_height":100,"access_api":"access:9000","dps_api":"dps:5005"}]`)
+		require.NoError(t, os.WriteFile(path, data, 0600))
+
+		list, err := sporks.Load(path)
+
+		require.NoError(t, err)
+		want := []sporks.Spork{{Name: "mainnet-1", RootHeight: 100, AccessAPI: "access:9000", DPSAPI: "dps:5005"}}
+		assert.Equal(t, want, list)
+	})
+
+	t.Run("loads local index directory", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "sporks.json")
+		data := []byte(`[{"name":"mainnet-1","root_height":100,"index_dir":"/cold/mainnet-1"},{"name":"mainnet-2","root_height":200,"access_api":"access:9000","dps_api":"dps:5005"}]`)
+		require.NoError(t, os.WriteFile(path, data, 0600))
+
+		list, err := sporks.Load(path)
+
+		require.NoError(t, err)
+		want := []sporks.Spork{
+			{Name: "mainnet-1", RootHeight: 100, IndexDir: "/cold/mainnet-1"},
+			{Name: "mainnet-2", RootHeight: 200, AccessAPI: "access:9000", DPSAPI: "dps:5005"},
+		}
+		assert.Equal(t, want, list)
+	})
+
+	t.Run("handles missing file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := sporks.Load(filepath.Join(t.TempDir(), "missing.json"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid JSON", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "sporks.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{`), 0600))
+
+		_, err := sporks.Load(path)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRegistry_Routing(t *testing.T) {
+	earlierHeader := &flow.Header{Height: 150}
+	laterHeader := &flow.Header{Height: 250}
+
+	earlier := mocks.BaselineReader(t)
+	earlier.FirstFunc = func() (uint64, error) {
+		return 100, nil
+	}
+	earlier.LastFunc = func() (uint64, error) {
+		return 199, nil
+	}
+	earlier.HeaderFunc = func(uint64) (*flow.Header, error) {
+		return earlierHeader, nil
+	}
+	earlier.HeightForBlockFunc = func(flow.Identifier) (uint64, error) {
+		return 150, nil
+	}
+
+	later := mocks.BaselineReader(t)
+	later.FirstFunc = func() (uint64, error) {
+		return 200, nil
+	}
+	later.LastFunc = func() (uint64, error) {
+		return 300, nil
+	}
+	later.HeaderFunc = func(uint64) (*flow.Header, error) {
+		return laterHeader, nil
+	}
+	later.HeightForBlockFunc = func(flow.Identifier) (uint64, error) {
+		return 0, mocks.GenericError
+	}
+
+	registry, err := sporks.New(
+		sporks.Spork{Name: "mainnet-1", RootHeight: 100, Index: earlier},
+		sporks.Spork{Name: "mainnet-2", RootHeight: 200, Index: later},
+	)
+	require.NoError(t, err)
+
+	t.Run("routes first and last", func(t *testing.T) {
+		t.Parallel()
+
+		first, err := registry.First()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(100), first)
+
+		last, err := registry.Last()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(300), last)
+	})
+
+	t.Run("routes reads by height", func(t *testing.T) {
+		t.Parallel()
+
+		header, err := registry.Header(150)
+		require.NoError(t, err)
+		assert.Equal(t, earlierHeader, header)
+
+		header, err = registry.Header(200)
+		require.NoError(t, err)
+		assert.Equal(t, laterHeader, header)
+
+		spork, err := registry.Spork(199)
+		require.NoError(t, err)
+		assert.Equal(t, "mainnet-1", spork.Name)
+	})
+
+	t.Run("handles height below first spork", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := registry.Header(99)
+
+		var ucErr failure.UncoveredBlock
+		require.True(t, errors.As(err, &ucErr))
+		assert.Equal(t, uint64(99), ucErr.Index)
+	})
+
+	t.Run("handles height in gap between sporks", func(t *testing.T) {
+		t.Parallel()
+
+		gapped := mocks.BaselineReader(t)
+		gapped.FirstFunc = func() (uint64, error) {
+			return 100, nil
+		}
+		gapped.LastFunc = func() (uint64, error) {
+			return 150, nil
+		}
+
+		registry, err := sporks.New(
+			sporks.Spork{Name: "mainnet-1", RootHeight: 100, Index: gapped},
+			sporks.Spork{Name: "mainnet-2", RootHeight: 200, Index: later},
+		)
+		require.NoError(t, err)
+
+		_, err = registry.Header(175)
+
+		var ucErr failure.UncoveredBlock
+		require.True(t, errors.As(err, &ucErr))
+		assert.Equal(t, uint64(175), ucErr.Index)
+		assert.Contains(t, ucErr.Description.String(), "mainnet-1: 100-150")
+		assert.Contains(t, ucErr.Description.String(), "mainnet-2: 200-300")
+	})
+
+	t.Run("handles failure to get spork range", func(t *testing.T) {
+		t.Parallel()
+
+		broken := mocks.BaselineReader(t)
+		broken.LastFunc = func() (uint64, error) {
+			return 0, mocks.GenericError
+		}
+
+		registry, err := sporks.New(
+			sporks.Spork{Name: "mainnet-1", RootHeight: 1, Index: broken},
+			sporks.Spork{Name: "mainnet-2", RootHeight: 200, Index: later},
+		)
+		require.NoError(t, err)
+
+		_, err = registry.Header(100)
+
+		assert.Error(t, err)
+		var ucErr failure.UncoveredBlock
+		assert.False(t, errors.As(err, &ucErr))
+	})
+
+	t.Run("falls back to older sporks for reads by identifier", func(t *testing.T) {
+		t.Parallel()
+
+		height, err := registry.HeightForBlock(mocks.GenericHeader.ID())
+
+		require.NoError(t, err)
+		assert.Equal(t, uint64(150), height)
+	})
+
+	t.Run("handles identifier missing from all sporks", func(t *testing.T) {
+		t.Parallel()
+
+		missing := mocks.BaselineReader(t)
+		missing.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			return nil, mocks.GenericError
+		}
+		registry, err := sporks.New(sporks.Spork{Name: "mainnet-1", RootHeight: 100, Index: missing})
+		require.NoError(t, err)
+
+		_, err = registry.Transaction(mocks.GenericTransaction(0).ID())
+
+		assert.Error(t, err)
+	})
+}
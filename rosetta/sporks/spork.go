@@ -0,0 +1,37 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package sporks
+
+import (
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// Spork is a single spork of the Flow network. Each spork has its own history,
+// starting at its root height, and is served by its own DPS index and Access API.
+// The index is read either through a DPS API or from a local index database. A
+// spork can also be split into several entries, each with the root height of the
+// range of heights that its index covers. Sporks with a network name are not
+// part of the history of the other sporks, but are served as their own network,
+// identified by that sub-network, such as the next spork during a blue/green
+// cutover.
+type Spork struct {
+	Name       string     `json:"name"`
+	Network    string     `json:"network,omitempty"`
+	RootHeight uint64     `json:"root_height"`
+	AccessAPI  string     `json:"access_api"`
+	DPSAPI     string     `json:"dps_api"`
+	IndexDir   string     `json:"index_dir"`
+	Index      dps.Reader `json:"-"`
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package storage
+
+// Config contains the configuration options for the block store.
+type Config struct {
+	Capacity    uint64
+	Fingerprint []byte
+}
+
+// WithCapacity sets the maximum amount of bytes of compressed blocks that are
+// kept in the store, after which the blocks with the lowest heights are evicted.
+func WithCapacity(capacity uint64) func(*Config) {
+	return func(c *Config) {
+		c.Capacity = capacity
+	}
+}
+
+// WithFingerprint sets the fingerprint of the configuration that the stored
+// blocks were converted with. When the store is opened with a fingerprint that
+// differs from the one of its stored blocks, they are all dropped, so that
+// blocks converted under another configuration are not served.
+func WithFingerprint(fingerprint []byte) func(*Config) {
+	return func(c *Config) {
+		c.Fingerprint = fingerprint
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+const (
+	// prefixBlock is the key prefix of the column holding converted blocks.
+	prefixBlock = byte(1)
+
+	// prefixSchema is the key of the schema version and configuration
+	// fingerprint of the stored blocks.
+	prefixSchema = byte(2)
+)
+
+// Version is the version of the conversion of stored blocks. It has to be
+// increased whenever a change to the conversion would change the blocks, so
+// that blocks stored by a previous version are dropped on upgrade.
+const Version = uint32(2)
+
+// Store persists converted Rosetta blocks in a Badger database, so that the
+// work of converting a block is not lost when the server restarts. Blocks are
+// stored as JSON compressed with zstd. When the total size of the stored
+// blocks exceeds the capacity, the blocks with the lowest heights are evicted.
+type Store struct {
+	log        zerolog.Logger
+	cfg        Config
+	db         *badger.DB
+	compress   *zstd.Encoder
+	decompress *zstd.Decoder
+
+	mutex *sync.Mutex
+	size  uint64
+}
+
+// entry is the stored representation of a converted block, along with the
+// identifiers of the transactions that did not fit into it.
+type entry struct {
+	Block *object.Block            `json:"block"`
+	Other []identifier.Transaction `json:"other_transactions,omitempty"`
+}
+
+// New returns a new block store on top of the given database. The total size
+// of the blocks that are already stored is computed on startup.
+func New(log zerolog.Logger, db *badger.DB, options ...func(*Config)) (*Store, error) {
+
+	cfg := Config{
+		Capacity: 1 << 30, // 1 GB default size
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	compress, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize compressor: %w", err)
+	}
+	decompress, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize decompressor: %w", err)
+	}
+
+	s := Store{
+		log:        log.With().Str("component", "block_store").Logger(),
+		cfg:        cfg,
+		db:         db,
+		compress:   compress,
+		decompress: decompress,
+		mutex:      &sync.Mutex{},
+	}
+
+	err = s.migrate()
+	if err != nil {
+		return nil, fmt.Errorf("could not check store schema: %w", err)
+	}
+
+	err = db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = []byte{prefixBlock}
+		it := tx.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			s.size += uint64(it.Item().ValueSize())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not compute store size: %w", err)
+	}
+
+	return &s, nil
+}
+
+// migrate drops all stored blocks if they were stored by another version of
+// the conversion or under another configuration, and records the current ones.
+func (s *Store) migrate() error {
+
+	schema := make([]byte, 4, 4+len(s.cfg.Fingerprint))
+	binary.BigEndian.PutUint32(schema, Version)
+	schema = append(schema, s.cfg.Fingerprint...)
+
+	var stored []byte
+	err := s.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get([]byte{prefixSchema})
+		if err != nil {
+			return err
+		}
+		stored, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+		return fmt.Errorf("could not read store schema: %w", err)
+	}
+	if bytes.Equal(stored, schema) {
+		return nil
+	}
+
+	s.log.Info().Uint32("version", Version).Msg("dropping blocks stored by another version or configuration")
+
+	err = s.db.DropPrefix([]byte{prefixBlock})
+	if err != nil {
+		return fmt.Errorf("could not drop stored blocks: %w", err)
+	}
+	err = s.db.Update(func(tx *badger.Txn) error {
+		return tx.Set([]byte{prefixSchema}, schema)
+	})
+	if err != nil {
+		return fmt.Errorf("could not write store schema: %w", err)
+	}
+
+	return nil
+}
+
+// Block returns the stored block at the given height, along with the
+// identifiers of the transactions that did not fit into it. The boolean is
+// false if no block is stored at the given height.
+func (s *Store) Block(height uint64) (*object.Block, []identifier.Transaction, bool) {
+
+	var compressed []byte
+	err := s.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get(key(height))
+		if err != nil {
+			return err
+		}
+		compressed, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, nil, false
+	}
+	if err != nil {
+		s.log.Warn().Uint64("height", height).Err(err).Msg("could not read stored block")
+		return nil, nil, false
+	}
+
+	data, err := s.decompress.DecodeAll(compressed, nil)
+	if err != nil {
+		s.log.Warn().Uint64("height", height).Err(err).Msg("could not decompress stored block")
+		return nil, nil, false
+	}
+
+	var e entry
+	err = json.Unmarshal(data, &e)
+	if err != nil {
+		s.log.Warn().Uint64("height", height).Err(err).Msg("could not decode stored block")
+		return nil, nil, false
+	}
+
+	return e.Block, e.Other, true
+}
+
+// Save stores the given block at the given height and evicts the blocks with
+// the lowest heights if the store is over capacity. Failures are logged
+// instead of returned, as the block can always be converted again.
+func (s *Store) Save(height uint64, block *object.Block, other []identifier.Transaction) {
+
+	err := s.save(height, block, other)
+	if err != nil {
+		s.log.Warn().Uint64("height", height).Err(err).Msg("could not store block")
+	}
+}
+
+func (s *Store) save(height uint64, block *object.Block, other []identifier.Transaction) error {
+
+	data, err := json.Marshal(entry{Block: block, Other: other})
+	if err != nil {
+		return fmt.Errorf("could not encode block: %w", err)
+	}
+	compressed := s.compress.EncodeAll(data, nil)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Blocks at an indexed height never change, so if the block is already
+	// stored, there is nothing to do.
+	err = s.db.Update(func(tx *badger.Txn) error {
+		_, err := tx.Get(key(height))
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+		err = tx.Set(key(height), compressed)
+		if err != nil {
+			return err
+		}
+		s.size += uint64(len(compressed))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not write block: %w", err)
+	}
+
+	if s.size <= s.cfg.Capacity {
+		return nil
+	}
+
+	err = s.evict()
+	if err != nil {
+		return fmt.Errorf("could not evict blocks: %w", err)
+	}
+
+	return nil
+}
+
+// evict deletes the blocks with the lowest heights until the store is back
+// within its capacity. It must be called with the mutex held.
+func (s *Store) evict() error {
+
+	var keys [][]byte
+	var freed uint64
+	err := s.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = []byte{prefixBlock}
+		it := tx.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid() && s.size-freed > s.cfg.Capacity; it.Next() {
+			keys = append(keys, it.Item().KeyCopy(nil))
+			freed += uint64(it.Item().ValueSize())
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not find blocks to evict: %w", err)
+	}
+
+	batch := s.db.NewWriteBatch()
+	defer batch.Cancel()
+	for _, key := range keys {
+		err = batch.Delete(key)
+		if err != nil {
+			return fmt.Errorf("could not delete block: %w", err)
+		}
+	}
+	err = batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not flush deletions: %w", err)
+	}
+
+	s.size -= freed
+
+	return nil
+}
+
+// key returns the database key for the block at the given height. Heights
+// are encoded in big endian, so that keys are sorted by height.
+func key(height uint64) []byte {
+	k := make([]byte, 9)
+	k[0] = prefixBlock
+	binary.BigEndian.PutUint64(k[1:], height)
+	return k
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/storage"
+)
+
+func TestStore(t *testing.T) {
+
+	block := func(height uint64) *object.Block {
+		return &object.Block{
+			ID:        identifier.Block{Index: &height, Hash: "block"},
+			ParentID:  identifier.Block{Index: &height, Hash: "parent"},
+			Timestamp: 1_600_000_000_000,
+			Transactions: []*object.Transaction{
+				{ID: identifier.Transaction{Hash: "tx"}},
+			},
+		}
+	}
+	other := []identifier.Transaction{{Hash: "other"}}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		db := inMemoryDB(t)
+		store, err := storage.New(zerolog.Nop(), db)
+		require.NoError(t, err)
+
+		store.Save(42, block(42), other)
+
+		got, extra, ok := store.Block(42)
+		require.True(t, ok)
+		assert.Equal(t, block(42), got)
+		assert.Equal(t, other, extra)
+	})
+
+	t.Run("handles missing block", func(t *testing.T) {
+		t.Parallel()
+
+		db := inMemoryDB(t)
+		store, err := storage.New(zerolog.Nop(), db)
+		require.NoError(t, err)
+
+		_, _, ok := store.Block(42)
+		assert.False(t, ok)
+	})
+
+	t.Run("keeps blocks across restarts", func(t *testing.T) {
+		t.Parallel()
+
+		db := inMemoryDB(t)
+		store, err := storage.New(zerolog.Nop(), db)
+		require.NoError(t, err)
+		store.Save(42, block(42), nil)
+
+		store, err = storage.New(zerolog.Nop(), db)
+		require.NoError(t, err)
+
+		got, extra, ok := store.Block(42)
+		require.True(t, ok)
+		assert.Equal(t, block(42), got)
+		assert.Empty(t, extra)
+	})
+
+	t.Run("drops blocks of another configuration", func(t *testing.T) {
+		t.Parallel()
+
+		db := inMemoryDB(t)
+		store, err := storage.New(zerolog.Nop(), db, storage.WithFingerprint([]byte("before")))
+		require.NoError(t, err)
+		store.Save(42, block(42), nil)
+
+		store, err = storage.New(zerolog.Nop(), db, storage.WithFingerprint([]byte("before")))
+		require.NoError(t, err)
+		_, _, ok := store.Block(42)
+		require.True(t, ok)
+
+		store, err = storage.New(zerolog.Nop(), db, storage.WithFingerprint([]byte("after")))
+		require.NoError(t, err)
+		_, _, ok = store.Block(42)
+		assert.False(t, ok)
+	})
+
+	t.Run("evicts lowest heights when over capacity", func(t *testing.T) {
+		t.Parallel()
+
+		db := inMemoryDB(t)
+		store, err := storage.New(zerolog.Nop(), db)
+		require.NoError(t, err)
+
+		// Measure the size of a single stored block, so that the capacity
+		// can be set to hold two blocks, with some slack for small differences
+		// in compressed size.
+		store.Save(1, block(1), nil)
+		var size uint64
+		err = db.View(func(tx *badger.Txn) error {
+			opts := badger.DefaultIteratorOptions
+			opts.Prefix = []byte{1}
+			it := tx.NewIterator(opts)
+			defer it.Close()
+			for it.Rewind(); it.Valid(); it.Next() {
+				size += uint64(it.Item().ValueSize())
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		store, err = storage.New(zerolog.Nop(), db, storage.WithCapacity(2*size+size/2))
+		require.NoError(t, err)
+
+		store.Save(3, block(3), nil)
+		store.Save(2, block(2), nil)
+
+		_, _, ok := store.Block(1)
+		assert.False(t, ok)
+		_, _, ok = store.Block(2)
+		assert.True(t, ok)
+		_, _, ok = store.Block(3)
+		assert.True(t, ok)
+	})
+}
+
+func inMemoryDB(t *testing.T) *badger.DB {
+	t.Helper()
+
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := badger.Open(opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
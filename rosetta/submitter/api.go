@@ -25,4 +25,5 @@ import (
 // API represents something that can be used to submit transactions.
 type API interface {
 	SendTransaction(ctx context.Context, tx sdk.Transaction, opts ...grpc.CallOption) error
+	GetAccountAtLatestBlock(ctx context.Context, address sdk.Address, opts ...grpc.CallOption) (*sdk.Account, error)
 }
@@ -0,0 +1,34 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package submitter
+
+import (
+	"time"
+)
+
+// Config contains the configuration options for the submitter.
+type Config struct {
+	DeduplicationWindow time.Duration
+}
+
+// WithDeduplicationWindow sets how long a successfully submitted transaction is
+// remembered, so that resubmissions of the same transaction within that window
+// are not sent to the Flow network again. A window of zero disables the
+// deduplication.
+func WithDeduplicationWindow(window time.Duration) func(*Config) {
+	return func(c *Config) {
+		c.DeduplicationWindow = window
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package submitter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	sdk "github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+const (
+	// Name of the upstream service for typed failures.
+	accessAPI = "access"
+
+	// Error descriptions for failures of the Access API.
+	submissionFailed = "access API could not submit transaction"
+	sequenceRejected = "access API rejected the sequence number of the proposal key"
+)
+
+// grpcCode returns the gRPC status code of the given error, or of the first
+// error in its chain that carries a gRPC status.
+func grpcCode(err error) codes.Code {
+	return grpcStatus(err).Code()
+}
+
+func grpcStatus(err error) *status.Status {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		return grpcErr.GRPCStatus()
+	}
+	return status.Convert(err)
+}
+
+// upstreamError translates an error returned by the Access API for the given
+// transaction into a typed failure for the gRPC codes that clients can act
+// upon, while wrapping all other errors as they are. A rejection of the
+// sequence number of the proposal key becomes a sequence conflict, without
+// the current sequence number, which the Access API does not give.
+func upstreamError(tx *sdk.Transaction, err error) error {
+	rejection := grpcStatus(err)
+	code := rejection.Code()
+	if code == codes.InvalidArgument && strings.Contains(strings.ToLower(rejection.Message()), "sequence number") {
+		return failure.SequenceConflict{
+			Description: failure.NewDescription(sequenceRejected, failure.WithErr(err)),
+			Proposer:    flow.Address(tx.ProposalKey.Address),
+			KeyIndex:    tx.ProposalKey.KeyIndex,
+			Sequence:    tx.ProposalKey.SequenceNumber,
+		}
+	}
+	switch code {
+	case codes.NotFound, codes.OutOfRange, codes.Unavailable, codes.DeadlineExceeded:
+		return failure.Upstream{
+			Service:     accessAPI,
+			Code:        code,
+			Description: failure.NewDescription(submissionFailed, failure.WithErr(err)),
+		}
+	default:
+		return fmt.Errorf("could not submit transaction: %w", err)
+	}
+}
@@ -16,31 +16,125 @@ package submitter
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
 
 	sdk "github.com/onflow/flow-go-sdk"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
 )
 
 // Submitter submits transactions for execution.
 type Submitter struct {
+	cfg Config
+
 	// api is typically a Flow SDK client.
 	api API
+
+	// records keeps track of when each transaction was successfully submitted,
+	// so that client retries of the same transaction are idempotent.
+	mu      *sync.Mutex
+	records map[sdk.Identifier]time.Time
 }
 
 // New creates a new Submitter that uses the given API.
-func New(api API) *Submitter {
+func New(api API, options ...func(*Config)) *Submitter {
+
+	cfg := Config{
+		DeduplicationWindow: 10 * time.Minute,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
 	s := Submitter{
-		api: api,
+		cfg:     cfg,
+		api:     api,
+		mu:      &sync.Mutex{},
+		records: make(map[sdk.Identifier]time.Time),
 	}
+
 	return &s
 }
 
-// Transaction submits the given transaction for execution.
+// Transaction submits the given transaction for execution. If the same
+// transaction was already submitted successfully within the deduplication
+// window, it is not sent again and the original successful result is returned.
 func (s *Submitter) Transaction(tx *sdk.Transaction) error {
+
+	txID := tx.ID()
+	if s.Submitted(txID) {
+		return nil
+	}
+
+	// If the access node already knows about the transaction, it was submitted
+	// before, for example by a previous instance of this service. We treat this
+	// the same way as a resubmission that we have a record for.
 	err := s.api.SendTransaction(context.Background(), *tx)
-	if err != nil {
-		return fmt.Errorf("could not submit transaction: %w", err)
+	if err != nil && grpcCode(err) != codes.AlreadyExists {
+		return s.current(upstreamError(tx, err))
 	}
 
+	s.record(txID)
+
 	return nil
 }
+
+// Submitted checks whether the transaction with the given ID was successfully
+// submitted within the deduplication window. It also removes all records that
+// have expired.
+func (s *Submitter) Submitted(txID sdk.Identifier) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-s.cfg.DeduplicationWindow)
+	for id, submitted := range s.records {
+		if submitted.Before(cutoff) {
+			delete(s.records, id)
+		}
+	}
+
+	_, ok := s.records[txID]
+	return ok
+}
+
+// current adds the sequence number of the proposal key at the latest sealed
+// block to the given error, if it is a sequence conflict, so that clients can
+// rebuild the transaction right away. If the account can not be retrieved, the
+// conflict is returned without it.
+func (s *Submitter) current(err error) error {
+
+	var conflict failure.SequenceConflict
+	if !errors.As(err, &conflict) {
+		return err
+	}
+
+	account, accErr := s.api.GetAccountAtLatestBlock(context.Background(), sdk.Address(conflict.Proposer))
+	if accErr != nil {
+		return conflict
+	}
+	for _, key := range account.Keys {
+		if key.Index == conflict.KeyIndex {
+			conflict.Current = key.SequenceNumber
+		}
+	}
+
+	return conflict
+}
+
+// record remembers that the transaction with the given ID was successfully
+// submitted.
+func (s *Submitter) record(txID sdk.Identifier) {
+	if s.cfg.DeduplicationWindow == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[txID] = time.Now()
+}
@@ -0,0 +1,250 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package submitter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	sdk "github.com/onflow/flow-go-sdk"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestNew(t *testing.T) {
+	api := mocks.BaselineAccessAPI(t)
+
+	s := New(api, WithDeduplicationWindow(time.Minute))
+
+	require.NotNil(t, s)
+	assert.Equal(t, api, s.api)
+	assert.Equal(t, time.Minute, s.cfg.DeduplicationWindow)
+	assert.NotNil(t, s.records)
+}
+
+func TestSubmitter_Transaction(t *testing.T) {
+	tx := sdk.NewTransaction().SetGasLimit(42)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		api := mocks.BaselineAccessAPI(t)
+		api.SendTransactionFunc = func(_ context.Context, gotTx sdk.Transaction, _ ...grpc.CallOption) error {
+			calls++
+			assert.Equal(t, tx.ID(), gotTx.ID())
+			return nil
+		}
+
+		s := BaselineSubmitter(t, WithAPI(api))
+
+		err := s.Transaction(tx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Contains(t, s.records, tx.ID())
+	})
+
+	t.Run("deduplicates resubmission within window", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		api := mocks.BaselineAccessAPI(t)
+		api.SendTransactionFunc = func(context.Context, sdk.Transaction, ...grpc.CallOption) error {
+			calls++
+			return nil
+		}
+
+		s := BaselineSubmitter(t, WithAPI(api))
+
+		err := s.Transaction(tx)
+		require.NoError(t, err)
+		err = s.Transaction(tx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("resubmits after window expired", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		api := mocks.BaselineAccessAPI(t)
+		api.SendTransactionFunc = func(context.Context, sdk.Transaction, ...grpc.CallOption) error {
+			calls++
+			return nil
+		}
+
+		s := BaselineSubmitter(t, WithAPI(api))
+		s.records[tx.ID()] = time.Now().Add(-2 * s.cfg.DeduplicationWindow)
+
+		err := s.Transaction(tx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("does not record when deduplication is disabled", func(t *testing.T) {
+		t.Parallel()
+
+		s := BaselineSubmitter(t)
+		s.cfg.DeduplicationWindow = 0
+
+		err := s.Transaction(tx)
+
+		require.NoError(t, err)
+		assert.Empty(t, s.records)
+	})
+
+	t.Run("handles already known transaction as success", func(t *testing.T) {
+		t.Parallel()
+
+		api := mocks.BaselineAccessAPI(t)
+		api.SendTransactionFunc = func(context.Context, sdk.Transaction, ...grpc.CallOption) error {
+			return status.Error(codes.AlreadyExists, "transaction already exists")
+		}
+
+		s := BaselineSubmitter(t, WithAPI(api))
+
+		err := s.Transaction(tx)
+
+		require.NoError(t, err)
+		assert.Contains(t, s.records, tx.ID())
+	})
+
+	t.Run("translates actionable gRPC codes into upstream failures", func(t *testing.T) {
+		t.Parallel()
+
+		api := mocks.BaselineAccessAPI(t)
+		api.SendTransactionFunc = func(context.Context, sdk.Transaction, ...grpc.CallOption) error {
+			return status.Error(codes.Unavailable, "connection refused")
+		}
+
+		s := BaselineSubmitter(t, WithAPI(api))
+
+		err := s.Transaction(tx)
+
+		var upErr failure.Upstream
+		require.ErrorAs(t, err, &upErr)
+		assert.Equal(t, codes.Unavailable, upErr.Code)
+		assert.Empty(t, s.records)
+	})
+
+	t.Run("translates sequence number rejection into sequence conflict", func(t *testing.T) {
+		t.Parallel()
+
+		tx := sdk.NewTransaction().SetProposalKey(sdk.HexToAddress("01"), 1, 5)
+
+		api := mocks.BaselineAccessAPI(t)
+		api.SendTransactionFunc = func(context.Context, sdk.Transaction, ...grpc.CallOption) error {
+			return status.Error(codes.InvalidArgument, "invalid proposal key: public key 1 on account 0000000000000001 has sequence number 7, but given 5")
+		}
+		api.GetAccountAtLatestBlockFunc = func(_ context.Context, address sdk.Address, _ ...grpc.CallOption) (*sdk.Account, error) {
+			assert.Equal(t, sdk.HexToAddress("01"), address)
+			account := sdk.Account{
+				Address: address,
+				Keys: []*sdk.AccountKey{
+					{Index: 0, SequenceNumber: 3},
+					{Index: 1, SequenceNumber: 7},
+				},
+			}
+			return &account, nil
+		}
+
+		s := BaselineSubmitter(t, WithAPI(api))
+
+		err := s.Transaction(tx)
+
+		var conflict failure.SequenceConflict
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, 1, conflict.KeyIndex)
+		assert.Equal(t, uint64(5), conflict.Sequence)
+		assert.Equal(t, uint64(7), conflict.Current)
+		assert.Empty(t, s.records)
+	})
+
+	t.Run("handles account retrieval failure after sequence number rejection", func(t *testing.T) {
+		t.Parallel()
+
+		tx := sdk.NewTransaction().SetProposalKey(sdk.HexToAddress("01"), 0, 5)
+
+		api := mocks.BaselineAccessAPI(t)
+		api.SendTransactionFunc = func(context.Context, sdk.Transaction, ...grpc.CallOption) error {
+			return status.Error(codes.InvalidArgument, "invalid sequence number")
+		}
+		api.GetAccountAtLatestBlockFunc = func(context.Context, sdk.Address, ...grpc.CallOption) (*sdk.Account, error) {
+			return nil, mocks.GenericError
+		}
+
+		s := BaselineSubmitter(t, WithAPI(api))
+
+		err := s.Transaction(tx)
+
+		var conflict failure.SequenceConflict
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, uint64(5), conflict.Sequence)
+		assert.Zero(t, conflict.Current)
+	})
+
+	t.Run("handles API failure", func(t *testing.T) {
+		t.Parallel()
+
+		api := mocks.BaselineAccessAPI(t)
+		api.SendTransactionFunc = func(context.Context, sdk.Transaction, ...grpc.CallOption) error {
+			return mocks.GenericError
+		}
+
+		s := BaselineSubmitter(t, WithAPI(api))
+
+		err := s.Transaction(tx)
+
+		assert.Error(t, err)
+		assert.False(t, errors.As(err, &failure.Upstream{}))
+		assert.Empty(t, s.records)
+	})
+}
+
+func BaselineSubmitter(t *testing.T, opts ...func(*Submitter)) *Submitter {
+	t.Helper()
+
+	s := Submitter{
+		cfg:     Config{DeduplicationWindow: time.Minute},
+		api:     mocks.BaselineAccessAPI(t),
+		mu:      &sync.Mutex{},
+		records: make(map[sdk.Identifier]time.Time),
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return &s
+}
+
+func WithAPI(api API) func(*Submitter) {
+	return func(submitter *Submitter) {
+		submitter.api = api
+	}
+}
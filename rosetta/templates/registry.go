@@ -0,0 +1,151 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package templates
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onflow/flow-go/crypto/hash"
+)
+
+// Registry is an allowlist of transaction templates, which can be looked up by
+// name when constructing transactions and by script when parsing them. The
+// zero value is an empty registry.
+type Registry struct {
+	names  map[string]Template
+	hashes map[string]Template
+}
+
+// New creates a new registry with the given templates. It fails if a template
+// has no name, uses an unsupported argument type, does not match its pinned
+// hash or duplicates the name or script of another template.
+func New(templates ...Template) (*Registry, error) {
+
+	r := Registry{
+		names:  make(map[string]Template, len(templates)),
+		hashes: make(map[string]Template, len(templates)),
+	}
+
+	for _, template := range templates {
+		if template.Name == "" {
+			return nil, fmt.Errorf("template name is empty")
+		}
+		_, ok := r.names[template.Name]
+		if ok {
+			return nil, fmt.Errorf("duplicate template name (name: %s)", template.Name)
+		}
+		for _, argument := range template.Arguments {
+			switch argument.Type {
+			case TypeAddress, TypeUFix64, TypeUInt64, TypeString:
+			default:
+				return nil, fmt.Errorf("unsupported template argument type (name: %s, argument: %s, type: %s)", template.Name, argument.Name, argument.Type)
+			}
+		}
+		digest := Hash(template.Script)
+		if digest != template.Hash {
+			return nil, fmt.Errorf("template hash mismatch (name: %s, have: %s, want: %s)", template.Name, digest, template.Hash)
+		}
+		_, ok = r.hashes[template.Hash]
+		if ok {
+			return nil, fmt.Errorf("duplicate template script (name: %s)", template.Name)
+		}
+		r.names[template.Name] = template
+		r.hashes[template.Hash] = template
+	}
+
+	return &r, nil
+}
+
+// FromFile creates a new registry from the JSON manifest at the given path.
+// The manifest is a list of templates, each with a name, the path of its
+// Cadence script relative to the manifest, the hex-encoded SHA3-256 hash of
+// the script, and its list of named and typed arguments.
+func FromFile(path string) (*Registry, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest: %w", err)
+	}
+
+	var entries []struct {
+		Name      string     `json:"name"`
+		Script    string     `json:"script"`
+		Hash      string     `json:"hash"`
+		Arguments []Argument `json:"arguments"`
+	}
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode manifest: %w", err)
+	}
+
+	templates := make([]Template, 0, len(entries))
+	for _, entry := range entries {
+		script, err := os.ReadFile(filepath.Join(filepath.Dir(path), entry.Script))
+		if err != nil {
+			return nil, fmt.Errorf("could not read template script (name: %s): %w", entry.Name, err)
+		}
+		template := Template{
+			Name:      entry.Name,
+			Script:    script,
+			Hash:      entry.Hash,
+			Arguments: entry.Arguments,
+		}
+		templates = append(templates, template)
+	}
+
+	return New(templates...)
+}
+
+// Verify checks that the scripts of all templates in the registry have one of the given
+// trusted hashes, which are configured separately from the manifest. This guards against
+// tampering with both a script and its pinned hash in the manifest.
+func (r *Registry) Verify(trusted ...string) error {
+
+	hashes := make(map[string]struct{}, len(trusted))
+	for _, hash := range trusted {
+		hashes[strings.ToLower(hash)] = struct{}{}
+	}
+
+	for _, template := range r.names {
+		_, ok := hashes[template.Hash]
+		if !ok {
+			return fmt.Errorf("untrusted template script (name: %s, hash: %s)", template.Name, template.Hash)
+		}
+	}
+
+	return nil
+}
+
+// Template returns the template with the given name, if it is allowlisted.
+func (r *Registry) Template(name string) (Template, bool) {
+	template, ok := r.names[name]
+	return template, ok
+}
+
+// Match returns the template with the given script, if it is allowlisted.
+func (r *Registry) Match(script []byte) (Template, bool) {
+	template, ok := r.hashes[Hash(script)]
+	return template, ok
+}
+
+// Hash returns the hex-encoded SHA3-256 hash of the given script.
+func Hash(script []byte) string {
+	return hex.EncodeToString(hash.NewSHA3_256().ComputeHash(script))
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package templates_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-rosetta/rosetta/templates"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		registry, err := templates.New(mocks.GenericTemplate)
+
+		require.NoError(t, err)
+
+		got, ok := registry.Template(mocks.GenericTemplate.Name)
+		assert.True(t, ok)
+		assert.Equal(t, mocks.GenericTemplate, got)
+
+		got, ok = registry.Match(mocks.GenericTemplate.Script)
+		assert.True(t, ok)
+		assert.Equal(t, mocks.GenericTemplate, got)
+	})
+
+	t.Run("handles hash mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		template := mocks.GenericTemplate
+		template.Script = mocks.GenericBytes
+
+		_, err := templates.New(template)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles missing name", func(t *testing.T) {
+		t.Parallel()
+
+		template := mocks.GenericTemplate
+		template.Name = ""
+
+		_, err := templates.New(template)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles duplicate name", func(t *testing.T) {
+		t.Parallel()
+
+		template := mocks.GenericTemplate
+		template.Script = mocks.GenericBytes
+		template.Hash = templates.Hash(mocks.GenericBytes)
+
+		_, err := templates.New(mocks.GenericTemplate, template)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles duplicate script", func(t *testing.T) {
+		t.Parallel()
+
+		template := mocks.GenericTemplate
+		template.Name = "other"
+
+		_, err := templates.New(mocks.GenericTemplate, template)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles unsupported argument type", func(t *testing.T) {
+		t.Parallel()
+
+		template := mocks.GenericTemplate
+		template.Arguments = []templates.Argument{{Name: "resource", Type: "Resource"}}
+
+		_, err := templates.New(template)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestFromFile(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "template.cdc")
+	require.NoError(t, os.WriteFile(script, mocks.GenericTemplate.Script, 0644))
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		manifest := filepath.Join(dir, "nominal.json")
+		data := `[{"name":"template","script":"template.cdc","hash":"` + mocks.GenericTemplate.Hash + `","arguments":[{"name":"recipient","type":"Address"},{"name":"id","type":"UInt64"}]}]`
+		require.NoError(t, os.WriteFile(manifest, []byte(data), 0644))
+
+		registry, err := templates.FromFile(manifest)
+
+		require.NoError(t, err)
+		got, ok := registry.Template(mocks.GenericTemplate.Name)
+		assert.True(t, ok)
+		assert.Equal(t, mocks.GenericTemplate, got)
+	})
+
+	t.Run("handles missing manifest", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := templates.FromFile(filepath.Join(dir, "missing.json"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid manifest", func(t *testing.T) {
+		t.Parallel()
+
+		manifest := filepath.Join(dir, "invalid.json")
+		require.NoError(t, os.WriteFile(manifest, mocks.GenericBytes, 0644))
+
+		_, err := templates.FromFile(manifest)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles missing script", func(t *testing.T) {
+		t.Parallel()
+
+		manifest := filepath.Join(dir, "script.json")
+		data := `[{"name":"template","script":"missing.cdc","hash":"` + mocks.GenericTemplate.Hash + `"}]`
+		require.NoError(t, os.WriteFile(manifest, []byte(data), 0644))
+
+		_, err := templates.FromFile(manifest)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRegistry_Verify(t *testing.T) {
+	registry, err := templates.New(mocks.GenericTemplate)
+	require.NoError(t, err)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		err := registry.Verify(templates.Hash(mocks.GenericBytes), mocks.GenericTemplate.Hash)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("handles untrusted script", func(t *testing.T) {
+		t.Parallel()
+
+		err := registry.Verify(templates.Hash(mocks.GenericBytes))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles empty registry", func(t *testing.T) {
+		t.Parallel()
+
+		var empty templates.Registry
+
+		err := empty.Verify()
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestRegistry_Match(t *testing.T) {
+	registry, err := templates.New(mocks.GenericTemplate)
+	require.NoError(t, err)
+
+	t.Run("handles unknown script", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := registry.Match(mocks.GenericBytes)
+
+		assert.False(t, ok)
+	})
+
+	t.Run("handles unknown name", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := registry.Template("unknown")
+
+		assert.False(t, ok)
+	})
+
+	t.Run("handles empty registry", func(t *testing.T) {
+		t.Parallel()
+
+		var empty templates.Registry
+
+		_, ok := empty.Match(mocks.GenericTemplate.Script)
+
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package templates
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/onflow/cadence"
+	cjson "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+)
+
+// Supported template argument types.
+const (
+	TypeAddress = "Address"
+	TypeUFix64  = "UFix64"
+	TypeUInt64  = "UInt64"
+	TypeString  = "String"
+)
+
+// Template is a named, parameterized Cadence transaction that operators can
+// allowlist for the Construction API. Its script is pinned by its SHA3-256
+// hash, so that the transaction text can not change without the operator
+// updating the configuration.
+type Template struct {
+	Name      string
+	Script    []byte
+	Hash      string
+	Arguments []Argument
+}
+
+// Argument is a named and typed argument of a transaction template. Arguments
+// are passed to the script in the order in which they are declared.
+type Argument struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Encode converts the given named argument values into the Cadence values
+// expected by the template script, in the order of the template arguments.
+// Amounts of type UFix64 are given in their smallest unit, like the amounts
+// of Rosetta operations.
+func (t Template) Encode(values map[string]string) ([]cadence.Value, error) {
+
+	if len(values) != len(t.Arguments) {
+		return nil, fmt.Errorf("invalid number of arguments (have: %d, want: %d)", len(values), len(t.Arguments))
+	}
+
+	args := make([]cadence.Value, 0, len(t.Arguments))
+	for _, argument := range t.Arguments {
+		value, ok := values[argument.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing argument (name: %s)", argument.Name)
+		}
+		arg, err := encode(argument.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode argument (name: %s): %w", argument.Name, err)
+		}
+		args = append(args, arg)
+	}
+
+	return args, nil
+}
+
+// Decode converts the given JSON-encoded Cadence arguments of a transaction
+// back into the named argument values of the template.
+func (t Template) Decode(args [][]byte) (map[string]string, error) {
+
+	if len(args) != len(t.Arguments) {
+		return nil, fmt.Errorf("invalid number of arguments (have: %d, want: %d)", len(args), len(t.Arguments))
+	}
+
+	values := make(map[string]string, len(t.Arguments))
+	for i, argument := range t.Arguments {
+		arg, err := cjson.Decode(args[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not decode argument (name: %s): %w", argument.Name, err)
+		}
+		value, err := decode(argument.Type, arg)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode argument (name: %s): %w", argument.Name, err)
+		}
+		values[argument.Name] = value
+	}
+
+	return values, nil
+}
+
+func encode(typ string, value string) (cadence.Value, error) {
+	switch typ {
+	case TypeAddress:
+		address := flow.HexToAddress(value)
+		if address.Hex() != value && address.HexWithPrefix() != value {
+			return nil, fmt.Errorf("invalid address (value: %s)", value)
+		}
+		return cadence.NewAddress(address), nil
+	case TypeUFix64:
+		units, err := amount.ToUFix64(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount (value: %s): %w", value, err)
+		}
+		return units, nil
+	case TypeUInt64:
+		number, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number (value: %s): %w", value, err)
+		}
+		return cadence.NewUInt64(number), nil
+	case TypeString:
+		return cadence.NewString(value)
+	default:
+		return nil, fmt.Errorf("unsupported argument type (type: %s)", typ)
+	}
+}
+
+func decode(typ string, arg cadence.Value) (string, error) {
+	switch typ {
+	case TypeAddress:
+		address, ok := arg.(cadence.Address)
+		if ok {
+			return flow.BytesToAddress(address.Bytes()).Hex(), nil
+		}
+	case TypeUFix64:
+		units, ok := arg.(cadence.UFix64)
+		if ok {
+			return amount.FromUFix64(units), nil
+		}
+	case TypeUInt64:
+		number, ok := arg.(cadence.UInt64)
+		if ok {
+			return strconv.FormatUint(uint64(number), 10), nil
+		}
+	case TypeString:
+		text, ok := arg.(cadence.String)
+		if ok {
+			return string(text), nil
+		}
+	default:
+		return "", fmt.Errorf("unsupported argument type (type: %s)", typ)
+	}
+	return "", fmt.Errorf("invalid argument value (have: %s, want: %s)", arg.Type().ID(), typ)
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package templates_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	cjson "github.com/onflow/cadence/encoding/json"
+
+	"github.com/optakt/flow-rosetta/rosetta/templates"
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestTemplate_Encode(t *testing.T) {
+	template := templates.Template{
+		Arguments: []templates.Argument{
+			{Name: "recipient", Type: templates.TypeAddress},
+			{Name: "amount", Type: templates.TypeUFix64},
+			{Name: "id", Type: templates.TypeUInt64},
+			{Name: "memo", Type: templates.TypeString},
+		},
+	}
+
+	values := map[string]string{
+		"recipient": mocks.GenericAddress(0).Hex(),
+		"amount":    "100000000",
+		"id":        "42",
+		"memo":      "hello",
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := template.Encode(values)
+
+		require.NoError(t, err)
+		require.Len(t, got, 4)
+		assert.Equal(t, cadence.NewAddress(mocks.GenericAddress(0)), got[0])
+		assert.Equal(t, cadence.UFix64(100000000), got[1])
+		assert.Equal(t, cadence.NewUInt64(42), got[2])
+		assert.Equal(t, cadence.String("hello"), got[3])
+	})
+
+	t.Run("handles missing argument", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := template.Encode(map[string]string{
+			"recipient": mocks.GenericAddress(0).Hex(),
+			"amount":    "100000000",
+			"id":        "42",
+			"other":     "hello",
+		})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid number of arguments", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := template.Encode(map[string]string{
+			"recipient": mocks.GenericAddress(0).Hex(),
+		})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid address", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := template.Encode(map[string]string{
+			"recipient": "not an address",
+			"amount":    "100000000",
+			"id":        "42",
+			"memo":      "hello",
+		})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid amount", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := template.Encode(map[string]string{
+			"recipient": mocks.GenericAddress(0).Hex(),
+			"amount":    "1.0",
+			"id":        "42",
+			"memo":      "hello",
+		})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTemplate_Decode(t *testing.T) {
+	template := templates.Template{
+		Arguments: []templates.Argument{
+			{Name: "recipient", Type: templates.TypeAddress},
+			{Name: "amount", Type: templates.TypeUFix64},
+			{Name: "id", Type: templates.TypeUInt64},
+			{Name: "memo", Type: templates.TypeString},
+		},
+	}
+
+	values := map[string]string{
+		"recipient": mocks.GenericAddress(0).Hex(),
+		"amount":    "100000000",
+		"id":        "42",
+		"memo":      "hello",
+	}
+
+	encoded, err := template.Encode(values)
+	require.NoError(t, err)
+
+	var args [][]byte
+	for _, value := range encoded {
+		arg, err := cjson.Encode(value)
+		require.NoError(t, err)
+		args = append(args, arg)
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := template.Decode(args)
+
+		require.NoError(t, err)
+		assert.Equal(t, values, got)
+	})
+
+	t.Run("handles invalid number of arguments", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := template.Decode(args[:2])
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles arguments which are not json-encoded", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := template.Decode([][]byte{mocks.GenericBytes, args[1], args[2], args[3]})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles arguments of the wrong type", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := template.Decode([][]byte{args[1], args[0], args[2], args[3]})
+
+		assert.Error(t, err)
+	})
+}
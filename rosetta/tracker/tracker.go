@@ -0,0 +1,78 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Tracker keeps track of the latest sealed height of the Flow network, as
+// reported by the Flow Access API. It allows us to distinguish blocks that are
+// not yet indexed from blocks that do not exist yet.
+type Tracker struct {
+	cfg Config
+
+	// api is typically a Flow SDK client.
+	api API
+
+	mu      *sync.Mutex
+	sealed  uint64
+	updated time.Time
+}
+
+// New creates a new Tracker that uses the given API.
+func New(api API, options ...func(*Config)) *Tracker {
+
+	cfg := Config{
+		CacheDuration: 5 * time.Second,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	t := Tracker{
+		cfg: cfg,
+		api: api,
+		mu:  &sync.Mutex{},
+	}
+
+	return &t
+}
+
+// Sealed returns the latest sealed height of the Flow network. The height is
+// cached for the configured duration, so that frequent calls do not result in
+// a request to the Access API each time.
+func (t *Tracker) Sealed() (uint64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.updated.IsZero() && time.Since(t.updated) < t.cfg.CacheDuration {
+		return t.sealed, nil
+	}
+
+	header, err := t.api.GetLatestBlockHeader(context.Background(), true)
+	if err != nil {
+		return 0, fmt.Errorf("could not get latest sealed block header: %w", err)
+	}
+
+	t.sealed = header.Height
+	t.updated = time.Now()
+
+	return t.sealed, nil
+}
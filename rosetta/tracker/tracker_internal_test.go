@@ -0,0 +1,101 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package tracker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	sdk "github.com/onflow/flow-go-sdk"
+
+	"github.com/optakt/flow-rosetta/testing/mocks"
+)
+
+func TestNew(t *testing.T) {
+	api := mocks.BaselineAccessAPI(t)
+
+	tr := New(api, WithCacheDuration(time.Minute))
+
+	require.NotNil(t, tr)
+	assert.Equal(t, api, tr.api)
+	assert.Equal(t, time.Minute, tr.cfg.CacheDuration)
+	assert.NotNil(t, tr.mu)
+}
+
+func TestTracker_Sealed(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		api := mocks.BaselineAccessAPI(t)
+		api.GetLatestBlockHeaderFunc = func(_ context.Context, isSealed bool, _ ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			calls++
+			assert.True(t, isSealed)
+			return &sdk.BlockHeader{Height: mocks.GenericHeight}, nil
+		}
+
+		tr := New(api, WithCacheDuration(time.Minute))
+
+		sealed, err := tr.Sealed()
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight, sealed)
+
+		sealed, err = tr.Sealed()
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight, sealed)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("refreshes expired cache", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		api := mocks.BaselineAccessAPI(t)
+		api.GetLatestBlockHeaderFunc = func(context.Context, bool, ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			calls++
+			return &sdk.BlockHeader{Height: mocks.GenericHeight + uint64(calls)}, nil
+		}
+
+		tr := New(api, WithCacheDuration(0))
+
+		_, err := tr.Sealed()
+		require.NoError(t, err)
+		sealed, err := tr.Sealed()
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight+2, sealed)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("handles access API failure", func(t *testing.T) {
+		t.Parallel()
+
+		api := mocks.BaselineAccessAPI(t)
+		api.GetLatestBlockHeaderFunc = func(context.Context, bool, ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			return nil, mocks.GenericError
+		}
+
+		tr := New(api)
+
+		_, err := tr.Sealed()
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, mocks.GenericError))
+	})
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package transactor
+
+// Config is the configuration for the Rosetta transactor component.
+// The gas limit is the one given to transactions unless a fee multiplier scales it.
+type Config struct {
+	Templates Templates
+	Intents   Intents
+	GasLimit  uint64
+}
+
+// WithTemplates sets the registry of allowlisted transaction templates in a Config.
+func WithTemplates(templates Templates) func(*Config) {
+	return func(c *Config) {
+		c.Templates = templates
+	}
+}
+
+// WithIntents sets the registry of known transaction scripts in a Config, whose
+// transactions are parsed into the operations they intend to execute.
+func WithIntents(intents Intents) func(*Config) {
+	return func(c *Config) {
+		c.Intents = intents
+	}
+}
+
+// WithGasLimit sets the default gas limit of transactions in a Config.
+func WithGasLimit(limit uint64) func(*Config) {
+	return func(c *Config) {
+		c.GasLimit = limit
+	}
+}
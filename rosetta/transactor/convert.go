@@ -27,6 +27,22 @@ func rosettaTxID(txID sdk.Identifier) identifier.Transaction {
 	}
 }
 
+// flowTransaction converts a transaction of the Flow Go SDK into the transaction
+// body that the Flow virtual machine executes, without its signatures.
+func flowTransaction(tx *sdk.Transaction) *flow.TransactionBody {
+	body := flow.NewTransactionBody().
+		SetScript(tx.Script).
+		SetArguments(tx.Arguments).
+		SetReferenceBlockID(flow.Identifier(tx.ReferenceBlockID)).
+		SetGasLimit(tx.GasLimit).
+		SetProposalKey(flow.Address(tx.ProposalKey.Address), uint64(tx.ProposalKey.KeyIndex), tx.ProposalKey.SequenceNumber).
+		SetPayer(flow.Address(tx.Payer))
+	for _, authorizer := range tx.Authorizers {
+		body.AddAuthorizer(flow.Address(authorizer))
+	}
+	return body
+}
+
 func rosettaBlockID(height uint64, blockID flow.Identifier) identifier.Block {
 	return identifier.Block{
 		Index: &height,
@@ -0,0 +1,196 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package transactor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	sdk "github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+// Payload formats in which signing payloads hold the transaction message rather than
+// its hash, so that signers can review the transaction before they sign it.
+const (
+	// FormatDetached payloads hold the RLP-encoded message prefixed with the transaction
+	// domain tag, which are the exact bytes that the signer hashes and signs.
+	FormatDetached = "detached"
+	// FormatLedger payloads hold the RLP-encoded message without the domain tag, which
+	// is what the Flow Ledger app expects, since it prepends the tag itself.
+	FormatLedger = "ledger"
+)
+
+// hashLength is the length of the hashes produced by the hashing algorithms that
+// transaction signing supports.
+const hashLength = 32
+
+// Format returns the format of the given hex-encoded signing payload, or an empty
+// string if it holds a hash. Hashes are never prefixed with the transaction domain
+// tag, and messages are RLP-encoded lists longer than any supported hash, so the
+// formats can be told apart from the payload bytes alone.
+func Format(payload string) string {
+
+	if strings.HasPrefix(payload, hex.EncodeToString(flow.TransactionDomainTag[:])) {
+		return FormatDetached
+	}
+
+	data, err := hex.DecodeString(payload)
+	if err != nil || len(data) <= hashLength {
+		return ""
+	}
+	if isList(data) {
+		return FormatLedger
+	}
+
+	return ""
+}
+
+// These are the smallest prefix bytes of an RLP-encoded list, and of one whose
+// payload is too long for its length to fit in the prefix byte itself.
+const (
+	rlpList     = 0xc0
+	rlpLongList = 0xf8
+)
+
+// isList returns whether the given data is exactly one RLP-encoded list, which
+// is the case when the length in its prefix matches the length of the data.
+func isList(data []byte) bool {
+
+	prefix := data[0]
+	if prefix < rlpList {
+		return false
+	}
+	if prefix < rlpLongList {
+		return int(prefix-rlpList) == len(data)-1
+	}
+
+	size := int(prefix-rlpLongList) + 1
+	if len(data) <= size {
+		return false
+	}
+	length := 0
+	for _, b := range data[1 : size+1] {
+		length = length<<8 | int(b)
+	}
+
+	return length == len(data)-1-size
+}
+
+// encodeMessage returns the hex-encoded signing payload for the given transaction
+// message in the given format.
+func encodeMessage(message []byte, format string) (string, error) {
+	switch format {
+	case FormatDetached:
+		return hex.EncodeToString(append(flow.TransactionDomainTag[:], message...)), nil
+	case FormatLedger:
+		return hex.EncodeToString(message), nil
+	default:
+		return "", fmt.Errorf("unsupported payload format (format: %s)", format)
+	}
+}
+
+// verifyDetached verifies that the given signing payload holds the given message in
+// the given format, and that the given signature was produced from it with the given key.
+func verifyDetached(key *flow.AccountPublicKey, payload string, format string, message []byte, signature []byte) error {
+
+	want, err := encodeMessage(message, format)
+	if err != nil {
+		return err
+	}
+	if payload != want {
+		return failure.InvalidSignature{
+			Description: failure.NewDescription(sigPayloadMismatch,
+				failure.WithString("signing_payload", payload),
+				failure.WithString("payload_format", format)),
+		}
+	}
+
+	hasher, err := crypto.NewHasher(key.HashAlgo)
+	if err != nil {
+		return fmt.Errorf("could not create hasher: %w", err)
+	}
+	message = append(flow.TransactionDomainTag[:], message...)
+	valid, err := key.PublicKey.Verify(signature, message, hasher)
+	if err != nil {
+		return fmt.Errorf("could not verify signature: %w", err)
+	}
+	if !valid {
+		return failure.InvalidSignature{
+			Description: failure.NewDescription(sigInvalid,
+				failure.WithString("signature", hex.EncodeToString(signature))),
+		}
+	}
+
+	return nil
+}
+
+// summarize returns a human-readable summary of the given transaction, which the
+// operator of an offline signer can compare against the decoded signing payload.
+func (t *Transactor) summarize(tx *sdk.Transaction) (*object.Summary, error) {
+
+	p := TransactionParser{
+		tx:        tx,
+		validate:  t.validate,
+		generate:  t.generate,
+		invoke:    t.invoke,
+		templates: t.cfg.Templates,
+		intents:   t.cfg.Intents,
+	}
+	operations, err := p.Operations()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse operations: %w", err)
+	}
+
+	lines := make([]string, 0, len(operations))
+	for _, op := range operations {
+		line := fmt.Sprintf("%s %s", op.Type, op.AccountID.Address)
+		units, negative, err := amount.ParseSigned(op.Amount.Value)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse operation amount: %w", err)
+		}
+		if units != 0 {
+			value := amount.ToDecimal(units, op.Amount.Currency.Decimals)
+			if negative {
+				value = "-" + value
+			}
+			line = fmt.Sprintf("%s %s %s", line, value, op.Amount.Currency.Symbol)
+		}
+		lines = append(lines, line)
+	}
+
+	authorizers := make([]string, 0, len(tx.Authorizers))
+	for _, authorizer := range tx.Authorizers {
+		authorizers = append(authorizers, authorizer.Hex())
+	}
+
+	summary := object.Summary{
+		ReferenceBlock: tx.ReferenceBlockID.Hex(),
+		SequenceNumber: tx.ProposalKey.SequenceNumber,
+		GasLimit:       tx.GasLimit,
+		Proposer:       tx.ProposalKey.Address.Hex(),
+		Payer:          tx.Payer.Hex(),
+		Authorizers:    authorizers,
+		Operations:     lines,
+	}
+
+	return &summary, nil
+}
@@ -18,8 +18,6 @@ package transactor
 const (
 	// Transaction actor errors.
 	authorizersInvalid = "invalid number of authorizers"
-	payerInvalid       = "invalid transaction payer"
-	proposerInvalid    = "invalid transaction proposer"
 
 	// Transaction signature errors.
 	signerInvalid           = "invalid signer account"
@@ -30,6 +28,13 @@ const (
 	envelopeSigCountInvalid = "unexpected number of envelope signatures"
 	sigCountInvalid         = "invalid number of signatures"
 	sigAlgoInvalid          = "invalid signature algorithm"
+	sigCurveInvalid         = "invalid signature curve"
+	sigLengthInvalid        = "invalid signature length"
+	sigPayloadMismatch      = "detached signing payload does not match the transaction"
+	payloadSigsMissing      = "payer can only sign once all payload signatures are attached"
+	sigWeightInsufficient   = "signer keys do not reach the required weight"
+	proposalSigMissing      = "proposer did not sign with the proposal key"
+	sigDuplicate            = "account key signed more than once"
 
 	// Transaction script errors.
 	scriptInvalid       = "transaction text is not valid token transfer script"
@@ -37,12 +42,38 @@ const (
 	amountUnparseable   = "could not parse transaction amount"
 	amountInvalid       = "invalid amount"
 	receiverUnparseable = "could not parse transaction receiver address"
+	templateArgsInvalid = "invalid transaction template arguments"
+	intentArgsInvalid   = "invalid arguments for known transaction script"
+
+	// Transaction simulation errors.
+	balanceInsufficient  = "sender balance does not cover transferred amount"
+	receiverVaultMissing = "receiver account does not have a FLOW vault"
+
+	// Fee suggestion errors.
+	maxFeeInsufficient = "maximum fee does not cover the transaction fee"
+
+	// Transaction submission errors.
+	txExpired          = "transaction expired, rebuild payloads with a new reference block"
+	txSequenceConflict = "proposal key sequence number already used, rebuild payloads with the current sequence number"
 
 	// Operations/intent errors.
 	opsInvalid          = "invalid number of operations"
 	opsAmountsMismatch  = "transfer amounts do not match"
 	currenciesInvalid   = "invalid currencies found"
 	opAmountUnparseable = "could not parse amount"
+	opAmountPrecision   = "amount is more precise than the currency decimals allow, it must be given in the smallest unit of the currency"
 	opTypeInvalid       = "only transfer operations are supported"
 	keyInvalid          = "invalid account key"
+	proposerKeyInvalid  = "proposer key index must not be negative"
+
+	// Account key errors.
+	keySigAlgoUnsupported  = "unsupported account key signature algorithm"
+	keyHashAlgoUnsupported = "unsupported account key hashing algorithm"
+	keyMetadataMissing     = "key operation does not include key metadata"
+	keyIndexInvalid        = "key revocation requires a valid key index"
+	keyWeightInvalid       = "key weight must be between 0 and 1000"
+	keyArgsInvalid         = "invalid key operation arguments"
+	publicKeyInvalid       = "invalid public key"
+	templateMissing        = "template operation does not reference a transaction template"
+	templateUnknown        = "transaction template is not allowlisted"
 )
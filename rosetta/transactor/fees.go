@@ -0,0 +1,99 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package transactor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+)
+
+// DefaultGasLimit is the gas limit given to transactions by default, which leaves
+// room for fee multipliers to scale it up to the maximum gas limit of Flow.
+const DefaultGasLimit = 1000
+
+// SuggestFee returns the gas limit and the suggested fee of a transaction with the
+// given reference block. The default gas limit is scaled by the given multiplier,
+// when it is non-zero, to give complex transactions more headroom, up to the
+// maximum gas limit of Flow.
+// Flow charges the same fee for every transaction, regardless of its gas limit,
+// so the suggested fee is the transaction fee set in the FlowServiceAccount
+// contract at the reference block, which it deposits into the FlowFees vault.
+// When a non-zero maximum fee is given, it has to cover that fee.
+func (t *Transactor) SuggestFee(rosBlockID identifier.Block, multiplier float64, maxFee uint64) (uint64, uint64, error) {
+
+	limit := t.gasLimit(0)
+	if multiplier != 0 {
+		limit = t.gasLimit(uint64(math.Ceil(float64(limit) * multiplier)))
+	}
+
+	height, _, err := t.validate.Block(rosBlockID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not validate block: %w", err)
+	}
+
+	fee, err := t.transactionFee(height)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if maxFee != 0 && fee > maxFee {
+		return 0, 0, failure.InsufficientFee{
+			Description: failure.NewDescription(maxFeeInsufficient),
+			MaxFee:      amount.Format(maxFee),
+			Fee:         amount.Format(fee),
+		}
+	}
+
+	return limit, fee, nil
+}
+
+// transactionFee returns the transaction fee set in the FlowServiceAccount
+// contract at the given height.
+func (t *Transactor) transactionFee(height uint64) (uint64, error) {
+
+	script, err := t.generate.GetTransactionFee()
+	if err != nil {
+		return 0, fmt.Errorf("could not generate transaction fee script: %w", err)
+	}
+
+	value, err := t.invoke.Script(height, script, nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not get transaction fee: %w", err)
+	}
+	fee, ok := value.ToGoValue().(uint64)
+	if !ok {
+		return 0, fmt.Errorf("could not convert transaction fee (type: %T)", value.ToGoValue())
+	}
+
+	return fee, nil
+}
+
+// gasLimit returns the given gas limit, or the default one if it is zero, capped at
+// the maximum gas limit of Flow.
+func (t *Transactor) gasLimit(limit uint64) uint64 {
+	if limit == 0 {
+		limit = t.cfg.GasLimit
+	}
+	if limit > flow.DefaultMaxTransactionGasLimit {
+		limit = flow.DefaultMaxTransactionGasLimit
+	}
+	return limit
+}
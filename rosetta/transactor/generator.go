@@ -15,7 +15,11 @@
 package transactor
 
 // Generator represents something that can generate Cadence scripts for transferring tokens
-// between two accounts.
+// between two accounts, for reading account balances and for managing account keys.
 type Generator interface {
 	TransferTokens(symbol string) ([]byte, error)
+	GetBalance(symbol string) ([]byte, error)
+	AddAccountKey() ([]byte, error)
+	RevokeAccountKey() ([]byte, error)
+	GetTransactionFee() ([]byte, error)
 }
@@ -19,11 +19,20 @@ import (
 	"github.com/onflow/flow-go/model/flow"
 )
 
-// Intent describes the intent of a set of two Rosetta operations.
+// Intent describes the intent of a set of two Rosetta operations, or of a
+// single Rosetta operation referencing a transaction template or managing an
+// account key. For the latter, the recipient and amount are left empty and the
+// script arguments are set instead. The gas limit is not part of the operations,
+// and is set from the construction metadata when it was given there.
 type Intent struct {
-	From     flow.Address
-	To       flow.Address
-	Amount   cadence.UFix64
-	Payer    flow.Address
-	Proposer flow.Address
+	From         flow.Address
+	To           flow.Address
+	Amount       cadence.UFix64
+	Payer        flow.Address
+	Proposer     flow.Address
+	Template     string
+	KeyOperation string
+	Arguments    []cadence.Value
+	GasLimit     uint64
+	KeyIndex     int
 }
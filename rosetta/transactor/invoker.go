@@ -15,10 +15,14 @@
 package transactor
 
 import (
+	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go/model/flow"
 )
 
-// Invoker represents something that can retrieve account public keys at any given height.
+// Invoker represents something that can retrieve account public keys and execute Cadence
+// scripts and transactions at any given height.
 type Invoker interface {
 	Key(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error)
+	Script(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error)
+	Transaction(height uint64, body *flow.TransactionBody) error
 }
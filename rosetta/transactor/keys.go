@@ -0,0 +1,81 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package transactor
+
+import (
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+const (
+	// maxKeyWeight is the weight at which a single account key is enough to
+	// sign for its account.
+	maxKeyWeight = 1000
+
+	// cadenceUnits is the number of units in one UFix64.
+	cadenceUnits = 100_000_000
+)
+
+// Raw values of the signature and hashing algorithms in Cadence, which differ
+// from their values in Go.
+const (
+	cadenceECDSAP256      = 1
+	cadenceECDSASecp256k1 = 2
+	cadenceSHA2256        = 1
+	cadenceSHA3256        = 3
+)
+
+func encodeSigAlgo(algo crypto.SignatureAlgorithm) (uint8, bool) {
+	switch algo {
+	case crypto.ECDSA_P256:
+		return cadenceECDSAP256, true
+	case crypto.ECDSA_secp256k1:
+		return cadenceECDSASecp256k1, true
+	default:
+		return 0, false
+	}
+}
+
+func decodeSigAlgo(raw uint8) (crypto.SignatureAlgorithm, bool) {
+	switch raw {
+	case cadenceECDSAP256:
+		return crypto.ECDSA_P256, true
+	case cadenceECDSASecp256k1:
+		return crypto.ECDSA_secp256k1, true
+	default:
+		return crypto.UnknownSignatureAlgorithm, false
+	}
+}
+
+func encodeHashAlgo(algo crypto.HashAlgorithm) (uint8, bool) {
+	switch algo {
+	case crypto.SHA2_256:
+		return cadenceSHA2256, true
+	case crypto.SHA3_256:
+		return cadenceSHA3256, true
+	default:
+		return 0, false
+	}
+}
+
+func decodeHashAlgo(raw uint8) (crypto.HashAlgorithm, bool) {
+	switch raw {
+	case cadenceSHA2256:
+		return crypto.SHA2_256, true
+	case cadenceSHA3256:
+		return crypto.SHA3_256, true
+	default:
+		return crypto.UnknownHashAlgorithm, false
+	}
+}
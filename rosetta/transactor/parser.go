@@ -18,26 +18,32 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
-	"strconv"
 
+	"github.com/onflow/cadence"
 	cjson "github.com/onflow/cadence/encoding/json"
 	sdk "github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
 	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
 	"github.com/optakt/flow-rosetta/rosetta/failure"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/intents"
 	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/templates"
 )
 
 // TransactionParser is a wrapper around a pointer to a sdk.Transaction which exposes methods to
 // individually parse different elements of the transaction.
 type TransactionParser struct {
-	tx       *sdk.Transaction
-	validate Validator
-	generate Generator
-	invoke   Invoker
+	tx        *sdk.Transaction
+	validate  Validator
+	generate  Generator
+	invoke    Invoker
+	templates Templates
+	intents   Intents
 }
 
 // BlockID parses the transaction's BlockID.
@@ -60,22 +66,24 @@ func (p *TransactionParser) Sequence() uint64 {
 	return p.tx.ProposalKey.SequenceNumber
 }
 
-// Signers parses the transaction's signer accounts.
+// GasLimit parses the transaction's gas limit.
+func (p *TransactionParser) GasLimit() uint64 {
+	return p.tx.GasLimit
+}
+
+// Signers parses the transaction's signer accounts. Payload signatures must belong to
+// the authorizer or proposer of the transaction, and the envelope signature to its payer.
+// Each signature is verified against the account key it declares, and the keys of each
+// signer account need to reach the weight required to sign for it. If the proposer
+// signed, one of its signatures has to be made with the proposal key.
 func (p *TransactionParser) Signers() ([]identifier.Account, error) {
-	// Since we only support sender as the payer/proposer, we never expect any payload signatures.
-	if len(p.tx.PayloadSignatures) > 0 {
-		return nil, failure.InvalidSignature{
-			Description: failure.NewDescription(payloadSigFound,
-				failure.WithInt("signatures", len(p.tx.PayloadSignatures))),
-		}
-	}
 
 	// We may be parsing an unsigned transaction - if that's the case, we're done.
-	if len(p.tx.EnvelopeSignatures) == 0 {
+	if len(p.tx.PayloadSignatures) == 0 && len(p.tx.EnvelopeSignatures) == 0 {
 		return nil, nil
 	}
 
-	// We don't support multiple signatures.
+	// We don't support multiple envelope signatures.
 	if len(p.tx.EnvelopeSignatures) > 1 {
 		return nil, failure.InvalidSignature{
 			Description: failure.NewDescription(envelopeSigCountInvalid,
@@ -83,20 +91,33 @@ func (p *TransactionParser) Signers() ([]identifier.Account, error) {
 		}
 	}
 
-	// Validate that it is the sender who signed the transaction.
-	signer := p.tx.EnvelopeSignatures[0].Address
-	authorizer := p.tx.Authorizers[0]
-	if signer != authorizer {
-		return nil, failure.InvalidSignature{
-			Description: failure.NewDescription(signerInvalid,
-				failure.WithString("have_signer", signer.String()),
-				failure.WithString("want_signer", authorizer.String()),
-				failure.WithString("signature", hex.EncodeToString(p.tx.EnvelopeSignatures[0].Signature))),
+	// Validate that the payload signatures are from the payload signers.
+	expected := make(map[sdk.Address]struct{})
+	for _, signer := range payloadSigners(p.tx) {
+		expected[signer] = struct{}{}
+	}
+	for _, sig := range p.tx.PayloadSignatures {
+		_, ok := expected[sig.Address]
+		if !ok {
+			return nil, failure.InvalidSignature{
+				Description: failure.NewDescription(payloadSigFound,
+					failure.WithString("signer", sig.Address.String()),
+					failure.WithString("signature", hex.EncodeToString(sig.Signature))),
+			}
 		}
 	}
 
-	// Check that the signature is valid.
-	address := flow.BytesToAddress(signer[:])
+	// Validate that it is the payer who signed the transaction envelope.
+	for _, sig := range p.tx.EnvelopeSignatures {
+		if sig.Address != p.tx.Payer {
+			return nil, failure.InvalidSignature{
+				Description: failure.NewDescription(signerInvalid,
+					failure.WithString("have_signer", sig.Address.String()),
+					failure.WithString("want_signer", p.tx.Payer.String()),
+					failure.WithString("signature", hex.EncodeToString(sig.Signature))),
+			}
+		}
+	}
 
 	rosBlockID := identifier.Block{Hash: p.tx.ReferenceBlockID.Hex()}
 	height, _, err := p.validate.Block(rosBlockID)
@@ -104,56 +125,121 @@ func (p *TransactionParser) Signers() ([]identifier.Account, error) {
 		return nil, fmt.Errorf("could not validate block: %w", err)
 	}
 
-	key, err := p.invoke.Key(height, address, 0)
-	if err != nil {
-		return nil, fmt.Errorf("could not retrieve key: %w", err)
+	// Check that the signatures are valid, and sum up the weights of the keys
+	// used by each signer. Each key can only sign once, across the payload and
+	// the envelope, as the network rejects duplicate signatures, and so that
+	// the weight of a key is only counted once.
+	var addresses []sdk.Address
+	weights := make(map[sdk.Address]int)
+	keys := make(map[sdk.Address]map[int]struct{})
+	proposed := false
+	record := func(sig sdk.TransactionSignature, weight int) error {
+		_, ok := weights[sig.Address]
+		if !ok {
+			addresses = append(addresses, sig.Address)
+			keys[sig.Address] = make(map[int]struct{})
+		}
+		_, ok = keys[sig.Address][sig.KeyIndex]
+		if ok {
+			return failure.InvalidSignature{
+				Description: failure.NewDescription(sigDuplicate,
+					failure.WithString("signer", sig.Address.String()),
+					failure.WithInt("key_index", sig.KeyIndex)),
+			}
+		}
+		keys[sig.Address][sig.KeyIndex] = struct{}{}
+		weights[sig.Address] += weight
+		if sig.Address == p.tx.ProposalKey.Address && sig.KeyIndex == p.tx.ProposalKey.KeyIndex {
+			proposed = true
+		}
+		return nil
 	}
-
-	// NOTE: signature verification is ported from the DefaultSignatureVerifier
-	// => https://github.com/onflow/flow-go/blob/master/fvm/crypto/crypto.go
-	hasher, err := crypto.NewHasher(key.HashAlgo)
-	if err != nil {
-		return nil, fmt.Errorf("could not get new hasher: %w", err)
+	for _, sig := range p.tx.PayloadSignatures {
+		weight, err := p.verify(height, sig, p.tx.PayloadMessage())
+		if err != nil {
+			return nil, fmt.Errorf("could not verify payload signature: %w", err)
+		}
+		err = record(sig, weight)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, sig := range p.tx.EnvelopeSignatures {
+		weight, err := p.verify(height, sig, p.tx.EnvelopeMessage())
+		if err != nil {
+			return nil, fmt.Errorf("could not verify envelope signature: %w", err)
+		}
+		err = record(sig, weight)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	message := p.tx.EnvelopeMessage()
-	message = append(sdk.TransactionDomainTag[:], message...)
+	// Create the signers list, in the order in which they signed.
+	signers := make([]identifier.Account, 0, len(addresses))
+	for _, address := range addresses {
+		if weights[address] < requiredWeight {
+			return nil, failure.InvalidSignature{
+				Description: failure.NewDescription(sigWeightInsufficient,
+					failure.WithString("signer", address.String()),
+					failure.WithInt("have_weight", weights[address]),
+					failure.WithInt("want_weight", requiredWeight)),
+			}
+		}
 
-	signature := p.tx.EnvelopeSignatures[0].Signature
+		signer := identifier.Account{
+			Address: address.String(),
+		}
 
-	valid, err := key.PublicKey.Verify(signature, message, hasher)
-	if err != nil {
-		return nil, fmt.Errorf("could not verify transaction signature: %w", err)
+		// Validate the signer address.
+		_, err = p.validate.Account(signer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signer account: %w", err)
+		}
+
+		signers = append(signers, signer)
 	}
-	if !valid {
+
+	_, signed := weights[p.tx.ProposalKey.Address]
+	if signed && !proposed {
 		return nil, failure.InvalidSignature{
-			Description: failure.NewDescription(sigInvalid,
-				failure.WithString("signature", hex.EncodeToString(signature))),
+			Description: failure.NewDescription(proposalSigMissing,
+				failure.WithString("proposer", p.tx.ProposalKey.Address.String()),
+				failure.WithInt("key_index", p.tx.ProposalKey.KeyIndex)),
 		}
 	}
 
-	sender := identifier.Account{
-		Address: authorizer.String(),
-	}
+	return signers, nil
+}
 
-	// Validate the sender address.
-	_, err = p.validate.Account(sender)
-	if err != nil {
-		return nil, fmt.Errorf("invalid sender account: %w", err)
+// Roles parses the transaction's payer and proposer, when they are distinct from
+// its authorizer, as well as its proposal key, when it is not the first key of
+// the proposer.
+func (p *TransactionParser) Roles() *object.Roles {
+
+	if len(p.tx.Authorizers) != requiredAuthorizers {
+		return nil
 	}
 
-	// Create the signers list.
-	signers := []identifier.Account{
-		sender,
+	var roles object.Roles
+	authorizer := p.tx.Authorizers[0]
+	if p.tx.Payer != authorizer {
+		roles.PayerID = &identifier.Account{Address: p.tx.Payer.String()}
+	}
+	if p.tx.ProposalKey.Address != authorizer {
+		roles.ProposerID = &identifier.Account{Address: p.tx.ProposalKey.Address.String()}
+	}
+	roles.ProposerKeyIndex = p.tx.ProposalKey.KeyIndex
+	if roles.PayerID == nil && roles.ProposerID == nil && roles.ProposerKeyIndex == 0 {
+		return nil
 	}
 
-	return signers, nil
+	return &roles
 }
 
 // Operations parses the transaction's operations.
 func (p *TransactionParser) Operations() ([]object.Operation, error) {
 	// Validate the transaction actors. We expect a single authorizer - the sender account.
-	// For now, the sender must also be the proposer and the payer for the transaction.
 	if len(p.tx.Authorizers) != requiredAuthorizers {
 		return nil, failure.InvalidAuthorizers{
 			Have:        uint(len(p.tx.Authorizers)),
@@ -173,28 +259,50 @@ func (p *TransactionParser) Operations() ([]object.Operation, error) {
 		return nil, fmt.Errorf("invalid sender account: %w", err)
 	}
 
-	// Verify that the sender is the payer and the proposer.
+	// The payer and the proposer can be distinct from the sender, but they
+	// need to be valid accounts as well.
 	if p.tx.Payer != authorizer {
-		return nil, failure.InvalidPayer{
-			Have:        flow.BytesToAddress(p.tx.Payer[:]),
-			Want:        flow.BytesToAddress(authorizer[:]),
-			Description: failure.NewDescription(payerInvalid),
+		_, err = p.validate.Account(identifier.Account{Address: p.tx.Payer.String()})
+		if err != nil {
+			return nil, fmt.Errorf("invalid payer account: %w", err)
 		}
 	}
 	if p.tx.ProposalKey.Address != authorizer {
-		return nil, failure.InvalidProposer{
-			Have:        flow.BytesToAddress(p.tx.ProposalKey.Address[:]),
-			Want:        flow.BytesToAddress(authorizer[:]),
-			Description: failure.NewDescription(proposerInvalid),
+		_, err = p.validate.Account(identifier.Account{Address: p.tx.ProposalKey.Address.String()})
+		if err != nil {
+			return nil, fmt.Errorf("invalid proposer account: %w", err)
 		}
 	}
 
-	// Verify the transaction script is the token transfer script.
+	// Verify the transaction script is the token transfer script, the script
+	// of one of the allowlisted transaction templates, or a key management script.
 	script, err := p.generate.TransferTokens(dps.FlowSymbol)
 	if err != nil {
 		return nil, fmt.Errorf("could not generate transfer script: %w", err)
 	}
+	template, ok := p.templates.Match(p.tx.Script)
+	if ok {
+		return p.templateOperations(sender, template)
+	}
+	addScript, err := p.generate.AddAccountKey()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate key addition script: %w", err)
+	}
+	if bytes.Equal(addScript, p.tx.Script) {
+		return p.keyAddOperations(sender)
+	}
+	revokeScript, err := p.generate.RevokeAccountKey()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate key revocation script: %w", err)
+	}
+	if bytes.Equal(revokeScript, p.tx.Script) {
+		return p.keyRevokeOperations(sender)
+	}
 	if !bytes.Equal(script, p.tx.Script) {
+		intent, ok := p.intents.Match(p.tx.Script)
+		if ok {
+			return p.intentOperations(intent)
+		}
 		return nil, failure.InvalidScript{
 			Script:      string(p.tx.Script),
 			Description: failure.NewDescription(scriptInvalid),
@@ -220,14 +328,13 @@ func (p *TransactionParser) Operations() ([]object.Operation, error) {
 				failure.WithErr(err)),
 		}
 	}
-	amountArg, ok := val.ToGoValue().(uint64)
+	units, ok := val.ToGoValue().(uint64)
 	if !ok {
 		return nil, failure.InvalidAmount{
 			Amount:      string(args[0]),
 			Description: failure.NewDescription(amountInvalid),
 		}
 	}
-	amount := strconv.FormatUint(amountArg, 10)
 
 	// Parse and validate receiver script argument.
 	val, err = cjson.Decode(args[1])
@@ -256,7 +363,7 @@ func (p *TransactionParser) Operations() ([]object.Operation, error) {
 		AccountID: sender,
 		Type:      dps.OperationTransfer,
 		Amount: object.Amount{
-			Value: "-" + amount,
+			Value: amount.FormatSigned(units, true),
 			Currency: identifier.Currency{
 				Symbol:   dps.FlowSymbol,
 				Decimals: dps.FlowDecimals,
@@ -265,16 +372,17 @@ func (p *TransactionParser) Operations() ([]object.Operation, error) {
 		Status: "", // must NOT be set for non-submitted transactions
 	}
 
-	// Create the receive operation.
+	// Create the receive operation, which is related to the send operation.
 	receiveOp := object.Operation{
 		ID: identifier.Operation{
 			Index:        1,
 			NetworkIndex: nil, // optional, omitted for now
 		},
-		AccountID: receiver,
-		Type:      dps.OperationTransfer,
+		RelatedIDs: []identifier.Operation{sendOp.ID},
+		AccountID:  receiver,
+		Type:       dps.OperationTransfer,
 		Amount: object.Amount{
-			Value: amount,
+			Value: amount.Format(units),
 			Currency: identifier.Currency{
 				Symbol:   dps.FlowSymbol,
 				Decimals: dps.FlowDecimals,
@@ -290,3 +398,234 @@ func (p *TransactionParser) Operations() ([]object.Operation, error) {
 
 	return ops, nil
 }
+
+func (p *TransactionParser) templateOperations(sender identifier.Account, template templates.Template) ([]object.Operation, error) {
+
+	// Parse and validate the template arguments.
+	arguments, err := template.Decode(p.tx.Arguments)
+	if err != nil {
+		return nil, failure.InvalidScript{
+			Script: string(p.tx.Script),
+			Description: failure.NewDescription(templateArgsInvalid,
+				failure.WithString("template", template.Name),
+				failure.WithErr(err),
+			),
+		}
+	}
+
+	// Create the template operation. It does not move any tokens by itself,
+	// so its amount is zero.
+	templateOp := object.Operation{
+		ID: identifier.Operation{
+			Index:        0,
+			NetworkIndex: nil, // optional, omitted for now
+		},
+		AccountID: sender,
+		Type:      configuration.OperationTemplate,
+		Amount: object.Amount{
+			Value: "0",
+			Currency: identifier.Currency{
+				Symbol:   dps.FlowSymbol,
+				Decimals: dps.FlowDecimals,
+			},
+		},
+		Status: "", // must NOT be set for non-submitted transactions
+		Metadata: &object.OperationMetadata{
+			Template:  template.Name,
+			Arguments: arguments,
+		},
+	}
+
+	ops := []object.Operation{
+		templateOp,
+	}
+
+	return ops, nil
+}
+
+// intentOperations parses the operations of a transaction with a known script
+// other than the token transfer script, such as FUSD transfers or staking
+// collection actions, which can be parsed but not constructed.
+func (p *TransactionParser) intentOperations(intent intents.Intent) ([]object.Operation, error) {
+
+	ops, err := intent.Operations(flow.Address(p.tx.Authorizers[0]), p.tx.Arguments)
+	if err != nil {
+		return nil, failure.InvalidScript{
+			Script: string(p.tx.Script),
+			Description: failure.NewDescription(intentArgsInvalid,
+				failure.WithString("intent", intent.Name),
+				failure.WithErr(err),
+			),
+		}
+	}
+
+	// The receiver of a transfer needs to be a valid account, like the one of
+	// a token transfer.
+	if intent.Kind == intents.KindTransfer {
+		_, err = p.validate.Account(ops[1].AccountID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid receiver account: %w", err)
+		}
+	}
+
+	return ops, nil
+}
+
+func (p *TransactionParser) keyAddOperations(sender identifier.Account) ([]object.Operation, error) {
+
+	values, err := p.keyArguments(4)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, okKey := values[0].(cadence.String)
+	rawSigAlgo, okSig := values[1].(cadence.UInt8)
+	rawHashAlgo, okHash := values[2].(cadence.UInt8)
+	weight, okWeight := values[3].(cadence.UFix64)
+	if !okKey || !okSig || !okHash || !okWeight {
+		return nil, p.keyArgsFailure(fmt.Errorf("unexpected argument types"))
+	}
+
+	sigAlgo, ok := decodeSigAlgo(uint8(rawSigAlgo))
+	if !ok {
+		return nil, p.keyArgsFailure(fmt.Errorf("unsupported signature algorithm (%d)", rawSigAlgo))
+	}
+	hashAlgo, ok := decodeHashAlgo(uint8(rawHashAlgo))
+	if !ok {
+		return nil, p.keyArgsFailure(fmt.Errorf("unsupported hashing algorithm (%d)", rawHashAlgo))
+	}
+
+	// Key weights are reported as integers, like the weights of existing keys.
+	units := uint64(weight) / cadenceUnits
+	if uint64(weight)%cadenceUnits != 0 || units > maxKeyWeight {
+		return nil, p.keyArgsFailure(fmt.Errorf("invalid key weight (%s)", weight))
+	}
+
+	metadata := object.OperationMetadata{
+		PublicKey:          string(publicKey),
+		SignatureAlgorithm: sigAlgo.String(),
+		HashAlgorithm:      hashAlgo.String(),
+		Weight:             int(units),
+	}
+
+	return p.keyOperations(sender, configuration.OperationKeyAdd, metadata), nil
+}
+
+func (p *TransactionParser) keyRevokeOperations(sender identifier.Account) ([]object.Operation, error) {
+
+	values, err := p.keyArguments(1)
+	if err != nil {
+		return nil, err
+	}
+
+	index, ok := values[0].(cadence.Int)
+	if !ok || !index.Big().IsInt64() || index.Int() < 0 {
+		return nil, p.keyArgsFailure(fmt.Errorf("invalid key index (%s)", values[0]))
+	}
+
+	keyIndex := index.Int()
+	metadata := object.OperationMetadata{
+		KeyIndex: &keyIndex,
+	}
+
+	return p.keyOperations(sender, configuration.OperationKeyRevoke, metadata), nil
+}
+
+func (p *TransactionParser) keyArguments(want int) ([]cadence.Value, error) {
+
+	args := p.tx.Arguments
+	if len(args) != want {
+		return nil, failure.InvalidArguments{
+			Have:        uint(len(args)),
+			Want:        uint(want),
+			Description: failure.NewDescription(scriptArgsInvalid),
+		}
+	}
+
+	values := make([]cadence.Value, 0, len(args))
+	for _, arg := range args {
+		value, err := cjson.Decode(arg)
+		if err != nil {
+			return nil, p.keyArgsFailure(err)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func (p *TransactionParser) keyArgsFailure(err error) error {
+	return failure.InvalidScript{
+		Script: string(p.tx.Script),
+		Description: failure.NewDescription(keyArgsInvalid,
+			failure.WithErr(err),
+		),
+	}
+}
+
+func (p *TransactionParser) keyOperations(sender identifier.Account, typ string, metadata object.OperationMetadata) []object.Operation {
+
+	// Create the key operation. Like template operations, it does not move
+	// any tokens, so its amount is zero.
+	keyOp := object.Operation{
+		ID: identifier.Operation{
+			Index:        0,
+			NetworkIndex: nil, // optional, omitted for now
+		},
+		AccountID: sender,
+		Type:      typ,
+		Amount: object.Amount{
+			Value: "0",
+			Currency: identifier.Currency{
+				Symbol:   dps.FlowSymbol,
+				Decimals: dps.FlowDecimals,
+			},
+		},
+		Status:   "", // must NOT be set for non-submitted transactions
+		Metadata: &metadata,
+	}
+
+	ops := []object.Operation{
+		keyOp,
+	}
+
+	return ops
+}
+
+// verify verifies the given signature against the account key it declares, and
+// returns the weight of that key.
+func (p *TransactionParser) verify(height uint64, sig sdk.TransactionSignature, message []byte) (int, error) {
+
+	address := flow.BytesToAddress(sig.Address[:])
+	key, err := p.invoke.Key(height, address, sig.KeyIndex)
+	if err != nil {
+		return 0, failure.InvalidKey{
+			Description: failure.NewDescription(keyInvalid, failure.WithErr(err)),
+			Height:      height,
+			Address:     address,
+			Index:       sig.KeyIndex,
+		}
+	}
+
+	// NOTE: signature verification is ported from the DefaultSignatureVerifier
+	// => https://github.com/onflow/flow-go/blob/master/fvm/crypto/crypto.go
+	hasher, err := crypto.NewHasher(key.HashAlgo)
+	if err != nil {
+		return 0, fmt.Errorf("could not get new hasher: %w", err)
+	}
+
+	message = append(sdk.TransactionDomainTag[:], message...)
+
+	valid, err := key.PublicKey.Verify(sig.Signature, message, hasher)
+	if err != nil {
+		return 0, fmt.Errorf("could not verify transaction signature: %w", err)
+	}
+	if !valid {
+		return 0, failure.InvalidSignature{
+			Description: failure.NewDescription(sigInvalid,
+				failure.WithString("signature", hex.EncodeToString(sig.Signature))),
+		}
+	}
+
+	return key.Weight, nil
+}
@@ -24,10 +24,12 @@ import (
 
 func BaselineTransactionParser(t *testing.T, opts ...func(parser *TransactionParser)) *TransactionParser {
 	p := TransactionParser{
-		tx:       sdk.NewTransaction(),
-		validate: mocks.BaselineValidator(t),
-		generate: mocks.BaselineGenerator(t),
-		invoke:   mocks.BaselineInvoker(t),
+		tx:        sdk.NewTransaction(),
+		validate:  mocks.BaselineValidator(t),
+		generate:  mocks.BaselineGenerator(t),
+		invoke:    mocks.BaselineInvoker(t),
+		templates: mocks.BaselineTemplates(t),
+		intents:   mocks.BaselineIntents(t),
 	}
 
 	for _, opt := range opts {
@@ -60,3 +62,15 @@ func InjectInvoker(invoke Invoker) func(*TransactionParser) {
 		parser.invoke = invoke
 	}
 }
+
+func InjectTemplates(templates Templates) func(*TransactionParser) {
+	return func(parser *TransactionParser) {
+		parser.templates = templates
+	}
+}
+
+func InjectIntents(intents Intents) func(*TransactionParser) {
+	return func(parser *TransactionParser) {
+		parser.intents = intents
+	}
+}
@@ -15,7 +15,9 @@
 package transactor_test
 
 import (
+	"bytes"
 	"crypto/rand"
+	"encoding/hex"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,8 +31,13 @@ import (
 	chash "github.com/onflow/flow-go/crypto/hash"
 	"github.com/onflow/flow-go/model/flow"
 
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
 	"github.com/optakt/flow-rosetta/rosetta/failure"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/intents"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/templates"
 	"github.com/optakt/flow-rosetta/rosetta/transactor"
 	"github.com/optakt/flow-rosetta/testing/mocks"
 )
@@ -118,6 +125,7 @@ func TestTransactionParser_Signers(t *testing.T) {
 	senderID := mocks.GenericAccountID(0)
 	senderAddr := mocks.GenericAddress(0)
 	sender := sdk.HexToAddress(senderAddr.Hex())
+	receiverID := mocks.GenericAccountID(1)
 	receiver := sdk.HexToAddress(mocks.GenericAddress(1).Hex())
 	signature := sdk.TransactionSignature{
 		Address:     sender,
@@ -132,6 +140,8 @@ func TestTransactionParser_Signers(t *testing.T) {
 
 	tx := &sdk.Transaction{
 		ReferenceBlockID:   sdk.HashToID(blockID[:]),
+		Payer:              sender,
+		ProposalKey:        sdk.ProposalKey{Address: sender, KeyIndex: signature.KeyIndex},
 		Authorizers:        []sdk.Address{sender},
 		EnvelopeSignatures: []sdk.TransactionSignature{signature},
 	}
@@ -145,6 +155,25 @@ func TestTransactionParser_Signers(t *testing.T) {
 
 	tx.EnvelopeSignatures[0].Signature = sig
 
+	// The proposer of this transaction is distinct from the sender, so it signs the
+	// payload before the sender signs the envelope.
+	proposedTx := &sdk.Transaction{
+		ReferenceBlockID: sdk.HashToID(blockID[:]),
+		Payer:            sender,
+		ProposalKey:      sdk.ProposalKey{Address: receiver},
+		Authorizers:      []sdk.Address{sender},
+	}
+
+	message = append(sdk.TransactionDomainTag[:], proposedTx.PayloadMessage()...)
+	sig, err = signer.Sign(message)
+	require.NoError(t, err)
+	proposedTx.AddPayloadSignature(receiver, 0, sig)
+
+	message = append(sdk.TransactionDomainTag[:], proposedTx.EnvelopeMessage()...)
+	sig, err = signer.Sign(message)
+	require.NoError(t, err)
+	proposedTx.AddEnvelopeSignature(sender, 0, sig)
+
 	t.Run("nominal case with unsigned transaction", func(t *testing.T) {
 		t.Parallel()
 
@@ -166,7 +195,7 @@ func TestTransactionParser_Signers(t *testing.T) {
 		invoker.KeyFunc = func(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
 			assert.Equal(t, header.Height, height)
 			assert.Equal(t, senderAddr, address)
-			assert.Zero(t, index)
+			assert.Equal(t, signature.KeyIndex, index)
 
 			return &pubKey, nil
 		}
@@ -180,6 +209,151 @@ func TestTransactionParser_Signers(t *testing.T) {
 		assert.Equal(t, senderID, got[0])
 	})
 
+	t.Run("nominal case with distinct proposer", func(t *testing.T) {
+		t.Parallel()
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(proposedTx), transactor.InjectInvoker(invoker))
+
+		got, err := p.Signers()
+
+		require.NoError(t, err)
+		assert.Equal(t, []identifier.Account{receiverID, senderID}, got)
+	})
+
+	t.Run("nominal case with multiple proposer keys", func(t *testing.T) {
+		t.Parallel()
+
+		// The proposer signs with two keys, which together reach the required weight.
+		halfKey := key.PublicKey(500)
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(_ uint64, address flow.Address, _ int) (*flow.AccountPublicKey, error) {
+			if address == flow.BytesToAddress(receiver[:]) {
+				return &halfKey, nil
+			}
+			return &pubKey, nil
+		}
+
+		tx := &sdk.Transaction{
+			ReferenceBlockID: sdk.HashToID(blockID[:]),
+			Payer:            sender,
+			ProposalKey:      sdk.ProposalKey{Address: receiver, KeyIndex: 1},
+			Authorizers:      []sdk.Address{sender},
+		}
+
+		message := append(sdk.TransactionDomainTag[:], tx.PayloadMessage()...)
+		sig, err := signer.Sign(message)
+		require.NoError(t, err)
+		tx.AddPayloadSignature(receiver, 0, sig)
+		tx.AddPayloadSignature(receiver, 1, sig)
+
+		message = append(sdk.TransactionDomainTag[:], tx.EnvelopeMessage()...)
+		sig, err = signer.Sign(message)
+		require.NoError(t, err)
+		tx.AddEnvelopeSignature(sender, 0, sig)
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx), transactor.InjectInvoker(invoker))
+
+		got, err := p.Signers()
+
+		require.NoError(t, err)
+		assert.Equal(t, []identifier.Account{receiverID, senderID}, got)
+	})
+
+	t.Run("handles insufficient signer key weight", func(t *testing.T) {
+		t.Parallel()
+
+		halfKey := key.PublicKey(500)
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return &halfKey, nil
+		}
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx), transactor.InjectInvoker(invoker))
+
+		_, err := p.Signers()
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+	})
+
+	t.Run("handles duplicate signatures of the same key", func(t *testing.T) {
+		t.Parallel()
+
+		// Two signatures with the same key would reach the required weight if
+		// the weight of the key was counted for each of them.
+		halfKey := key.PublicKey(500)
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(_ uint64, address flow.Address, _ int) (*flow.AccountPublicKey, error) {
+			if address == flow.BytesToAddress(receiver[:]) {
+				return &halfKey, nil
+			}
+			return &pubKey, nil
+		}
+
+		tx := &sdk.Transaction{
+			ReferenceBlockID: sdk.HashToID(blockID[:]),
+			Payer:            sender,
+			ProposalKey:      sdk.ProposalKey{Address: receiver},
+			Authorizers:      []sdk.Address{sender},
+		}
+
+		message := append(sdk.TransactionDomainTag[:], tx.PayloadMessage()...)
+		sig, err := signer.Sign(message)
+		require.NoError(t, err)
+		tx.AddPayloadSignature(receiver, 0, sig)
+		tx.AddPayloadSignature(receiver, 0, sig)
+
+		message = append(sdk.TransactionDomainTag[:], tx.EnvelopeMessage()...)
+		sig, err = signer.Sign(message)
+		require.NoError(t, err)
+		tx.AddEnvelopeSignature(sender, 0, sig)
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx), transactor.InjectInvoker(invoker))
+
+		_, err = p.Signers()
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+		assert.Contains(t, err.Error(), "signed more than once")
+	})
+
+	t.Run("handles proposer signature without proposal key", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			ReferenceBlockID: sdk.HashToID(blockID[:]),
+			Payer:            sender,
+			ProposalKey:      sdk.ProposalKey{Address: sender, KeyIndex: 1},
+			Authorizers:      []sdk.Address{sender},
+		}
+
+		message := append(sdk.TransactionDomainTag[:], tx.EnvelopeMessage()...)
+		sig, err := signer.Sign(message)
+		require.NoError(t, err)
+		tx.AddEnvelopeSignature(sender, 0, sig)
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx), transactor.InjectInvoker(invoker))
+
+		_, err = p.Signers()
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+	})
+
+	t.Run("handles invalid payload signature", func(t *testing.T) {
+		t.Parallel()
+
+		tx := *proposedTx
+		tx.PayloadSignatures = []sdk.TransactionSignature{{Address: receiver, Signature: mocks.GenericBytes}}
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(&tx), transactor.InjectInvoker(invoker))
+
+		_, err := p.Signers()
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+	})
+
 	t.Run("handles case where transaction contains a payload signature (which it should not)", func(t *testing.T) {
 		t.Parallel()
 
@@ -187,6 +361,8 @@ func TestTransactionParser_Signers(t *testing.T) {
 
 		tx := &sdk.Transaction{
 			ReferenceBlockID:   sdk.HashToID(blockID[:]),
+			Payer:              sender,
+			ProposalKey:        sdk.ProposalKey{Address: sender},
 			Authorizers:        []sdk.Address{sender},
 			EnvelopeSignatures: []sdk.TransactionSignature{signature},
 			PayloadSignatures:  []sdk.TransactionSignature{signature},
@@ -205,6 +381,8 @@ func TestTransactionParser_Signers(t *testing.T) {
 
 		tx := &sdk.Transaction{
 			ReferenceBlockID:   sdk.HashToID(blockID[:]),
+			Payer:              sender,
+			ProposalKey:        sdk.ProposalKey{Address: sender},
 			Authorizers:        []sdk.Address{sender},
 			EnvelopeSignatures: []sdk.TransactionSignature{signature, signature, signature},
 		}
@@ -217,11 +395,13 @@ func TestTransactionParser_Signers(t *testing.T) {
 		assert.ErrorAs(t, err, &failure.InvalidSignature{})
 	})
 
-	t.Run("handles case where transaction signer is not the sender", func(t *testing.T) {
+	t.Run("handles case where transaction signer is not the payer", func(t *testing.T) {
 		t.Parallel()
 
 		tx := &sdk.Transaction{
 			ReferenceBlockID:   sdk.HashToID(blockID[:]),
+			Payer:              receiver,
+			ProposalKey:        sdk.ProposalKey{Address: receiver},
 			Authorizers:        []sdk.Address{receiver},
 			EnvelopeSignatures: []sdk.TransactionSignature{signature},
 		}
@@ -270,7 +450,8 @@ func TestTransactionParser_Signers(t *testing.T) {
 
 		_, err := p.Signers()
 
-		assert.Error(t, err)
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidKey{})
 	})
 
 	t.Run("handles signature and key mismatch", func(t *testing.T) {
@@ -314,6 +495,83 @@ func TestTransactionParser_Signers(t *testing.T) {
 	})
 }
 
+func TestTransactionParser_Roles(t *testing.T) {
+	sender := sdk.HexToAddress(mocks.GenericAddress(0).Hex())
+	receiverID := mocks.GenericAccountID(1)
+	receiver := sdk.HexToAddress(mocks.GenericAddress(1).Hex())
+
+	t.Run("nominal case with sender as payer and proposer", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+		}
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx))
+
+		got := p.Roles()
+
+		assert.Nil(t, got)
+	})
+
+	t.Run("nominal case with distinct payer", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       receiver,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+		}
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx))
+
+		got := p.Roles()
+
+		require.NotNil(t, got)
+		assert.Equal(t, &receiverID, got.PayerID)
+		assert.Nil(t, got.ProposerID)
+	})
+
+	t.Run("nominal case with distinct proposer", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: receiver},
+			Authorizers: []sdk.Address{sender},
+		}
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx))
+
+		got := p.Roles()
+
+		require.NotNil(t, got)
+		assert.Nil(t, got.PayerID)
+		assert.Equal(t, &receiverID, got.ProposerID)
+	})
+
+	t.Run("nominal case with proposal key other than first key", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender, KeyIndex: 2},
+			Authorizers: []sdk.Address{sender},
+		}
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx))
+
+		got := p.Roles()
+
+		require.NotNil(t, got)
+		assert.Nil(t, got.PayerID)
+		assert.Nil(t, got.ProposerID)
+		assert.Equal(t, 2, got.ProposerKeyIndex)
+	})
+}
+
 func TestTransactionParser_Operations(t *testing.T) {
 	sender := sdk.HexToAddress(mocks.GenericAddress(0).Hex())
 	receiverAddr := mocks.GenericAddress(1)
@@ -343,7 +601,9 @@ func TestTransactionParser_Operations(t *testing.T) {
 		got, err := p.Operations()
 
 		require.NoError(t, err)
-		assert.NotEmpty(t, got)
+		require.Len(t, got, 2)
+		assert.Empty(t, got[0].RelatedIDs)
+		assert.Equal(t, []identifier.Operation{got[0].ID}, got[1].RelatedIDs)
 	})
 
 	t.Run("handles invalid number of authorizers", func(t *testing.T) {
@@ -384,37 +644,69 @@ func TestTransactionParser_Operations(t *testing.T) {
 		assert.Error(t, err)
 	})
 
-	t.Run("handles mismatch between authorizer and payer", func(t *testing.T) {
+	t.Run("nominal case with distinct payer and proposer", func(t *testing.T) {
 		t.Parallel()
 
-		tx := &sdk.Transaction{
-			Payer:       receiver,
-			Authorizers: []sdk.Address{sender},
+		tx := *tx
+		tx.Payer = receiver
+		tx.ProposalKey = sdk.ProposalKey{Address: receiver}
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(&tx))
+
+		got, err := p.Operations()
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, got)
+	})
+
+	t.Run("handles invalid payer account", func(t *testing.T) {
+		t.Parallel()
+
+		tx := *tx
+		tx.Payer = receiver
+
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+			if rosAccountID.Address == receiver.String() {
+				return flow.EmptyAddress, mocks.GenericError
+			}
+			return flow.HexToAddress(rosAccountID.Address), nil
 		}
 
-		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx))
+		p := transactor.BaselineTransactionParser(
+			t,
+			transactor.InjectTransaction(&tx),
+			transactor.InjectValidator(validator),
+		)
 
 		_, err := p.Operations()
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidPayer{})
+		assert.Error(t, err)
 	})
 
-	t.Run("handles mismatch between proposal key address and payer", func(t *testing.T) {
+	t.Run("handles invalid proposer account", func(t *testing.T) {
 		t.Parallel()
 
-		tx := &sdk.Transaction{
-			Payer:       sender,
-			ProposalKey: sdk.ProposalKey{Address: receiver},
-			Authorizers: []sdk.Address{sender},
+		tx := *tx
+		tx.ProposalKey = sdk.ProposalKey{Address: receiver}
+
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+			if rosAccountID.Address == receiver.String() {
+				return flow.EmptyAddress, mocks.GenericError
+			}
+			return flow.HexToAddress(rosAccountID.Address), nil
 		}
 
-		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx))
+		p := transactor.BaselineTransactionParser(
+			t,
+			transactor.InjectTransaction(&tx),
+			transactor.InjectValidator(validator),
+		)
 
 		_, err := p.Operations()
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidProposer{})
+		assert.Error(t, err)
 	})
 
 	t.Run("handles transfer token generation failure", func(t *testing.T) {
@@ -587,6 +879,345 @@ func TestTransactionParser_Operations(t *testing.T) {
 		require.Error(t, err)
 		assert.ErrorAs(t, err, &failure.InvalidReceiver{})
 	})
+
+	idData, err := cjson.Encode(cadence.NewUInt64(mocks.GenericHeight))
+	require.NoError(t, err)
+
+	t.Run("nominal case with template script", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericTemplateScript,
+			Arguments:   [][]byte{addressData, idData},
+		}
+
+		p := transactor.BaselineTransactionParser(
+			t,
+			transactor.InjectTransaction(tx),
+		)
+
+		got, err := p.Operations()
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, mocks.GenericTemplateOperation(), got[0])
+	})
+
+	t.Run("handles invalid template arguments", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericTemplateScript,
+			Arguments:   [][]byte{idData, addressData}, // Arguments are in the wrong order.
+		}
+
+		p := transactor.BaselineTransactionParser(
+			t,
+			transactor.InjectTransaction(tx),
+		)
+
+		_, err := p.Operations()
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidScript{})
+	})
+
+	t.Run("handles script which is not allowlisted", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericTemplateScript,
+			Arguments:   [][]byte{addressData, idData},
+		}
+
+		registry := mocks.BaselineTemplates(t)
+		registry.MatchFunc = func([]byte) (templates.Template, bool) {
+			return templates.Template{}, false
+		}
+
+		p := transactor.BaselineTransactionParser(
+			t,
+			transactor.InjectTransaction(tx),
+			transactor.InjectTemplates(registry),
+		)
+
+		_, err := p.Operations()
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidScript{})
+	})
+
+	encode := func(values ...cadence.Value) [][]byte {
+		var args [][]byte
+		for _, value := range values {
+			arg, err := cjson.Encode(value)
+			require.NoError(t, err)
+			args = append(args, arg)
+		}
+		return args
+	}
+	publicKey := cadence.String(hex.EncodeToString(mocks.GenericBytes))
+	keyAddArgs := encode(publicKey, cadence.NewUInt8(2), cadence.NewUInt8(1), cadence.UFix64(500_00000000))
+
+	ufixData, err := cjson.Encode(cadence.UFix64(100_000_000))
+	require.NoError(t, err)
+
+	fusdIntents := mocks.BaselineIntents(t)
+	fusdIntents.MatchFunc = func(script []byte) (intents.Intent, bool) {
+		intent := intents.Intent{
+			Name:   intents.NameTransferFUSD,
+			Kind:   intents.KindTransfer,
+			Symbol: "FUSD",
+		}
+		return intent, bytes.Equal(script, mocks.GenericFUSDScript)
+	}
+
+	t.Run("nominal case with known transfer script", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericFUSDScript,
+			Arguments:   [][]byte{ufixData, addressData},
+		}
+
+		p := transactor.BaselineTransactionParser(
+			t,
+			transactor.InjectTransaction(tx),
+			transactor.InjectIntents(fusdIntents),
+		)
+
+		got, err := p.Operations()
+
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, sender.Hex(), got[0].AccountID.Address)
+		assert.Equal(t, "-100000000", got[0].Amount.Value)
+		assert.Equal(t, "FUSD", got[0].Amount.Currency.Symbol)
+		assert.Equal(t, receiverAddr.Hex(), got[1].AccountID.Address)
+		assert.Equal(t, "100000000", got[1].Amount.Value)
+		assert.Equal(t, []identifier.Operation{got[0].ID}, got[1].RelatedIDs)
+		assert.Empty(t, got[0].Status)
+		assert.Empty(t, got[1].Status)
+	})
+
+	t.Run("nominal case with known staking script", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericStakeScript,
+			Arguments:   encode(cadence.String("node"), cadence.NewOptional(cadence.NewUInt32(7)), cadence.UFix64(100_000_000)),
+		}
+
+		registry := mocks.BaselineIntents(t)
+		registry.MatchFunc = func([]byte) (intents.Intent, bool) {
+			intent := intents.Intent{
+				Name:   intents.NameStakeNewTokens,
+				Kind:   intents.KindStaking,
+				Symbol: dps.FlowSymbol,
+			}
+			return intent, true
+		}
+
+		p := transactor.BaselineTransactionParser(
+			t,
+			transactor.InjectTransaction(tx),
+			transactor.InjectIntents(registry),
+		)
+
+		got, err := p.Operations()
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, configuration.OperationTemplate, got[0].Type)
+		assert.Equal(t, sender.Hex(), got[0].AccountID.Address)
+		assert.Equal(t, "0", got[0].Amount.Value)
+		require.NotNil(t, got[0].Metadata)
+		assert.Equal(t, intents.NameStakeNewTokens, got[0].Metadata.Template)
+		want := map[string]string{
+			"node_id":      "node",
+			"delegator_id": "7",
+			"amount":       "100000000",
+		}
+		assert.Equal(t, want, got[0].Metadata.Arguments)
+	})
+
+	t.Run("handles invalid arguments for known script", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericFUSDScript,
+			Arguments:   [][]byte{addressData, ufixData}, // Arguments are in the wrong order.
+		}
+
+		p := transactor.BaselineTransactionParser(
+			t,
+			transactor.InjectTransaction(tx),
+			transactor.InjectIntents(fusdIntents),
+		)
+
+		_, err := p.Operations()
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidScript{})
+	})
+
+	t.Run("handles invalid receiver for known transfer script", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericFUSDScript,
+			Arguments:   [][]byte{ufixData, addressData},
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+			if rosAccountID.Address == receiverAddr.Hex() {
+				return flow.EmptyAddress, mocks.GenericError
+			}
+			return flow.HexToAddress(rosAccountID.Address), nil
+		}
+
+		p := transactor.BaselineTransactionParser(
+			t,
+			transactor.InjectTransaction(tx),
+			transactor.InjectValidator(validator),
+			transactor.InjectIntents(fusdIntents),
+		)
+
+		_, err := p.Operations()
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+
+	t.Run("nominal case with key addition script", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericAddKeyScript,
+			Arguments:   keyAddArgs,
+		}
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx))
+
+		got, err := p.Operations()
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, configuration.OperationKeyAdd, got[0].Type)
+		assert.Equal(t, "0", got[0].Amount.Value)
+		want := &object.OperationMetadata{
+			PublicKey:          string(publicKey),
+			SignatureAlgorithm: "ECDSA_secp256k1",
+			HashAlgorithm:      "SHA2_256",
+			Weight:             500,
+		}
+		assert.Equal(t, want, got[0].Metadata)
+	})
+
+	t.Run("nominal case with key revocation script", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericRevokeKeyScript,
+			Arguments:   encode(cadence.NewInt(3)),
+		}
+
+		p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx))
+
+		got, err := p.Operations()
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, configuration.OperationKeyRevoke, got[0].Type)
+		require.NotNil(t, got[0].Metadata.KeyIndex)
+		assert.Equal(t, 3, *got[0].Metadata.KeyIndex)
+	})
+
+	t.Run("handles invalid key operation arguments", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name   string
+			script []byte
+			args   [][]byte
+		}{
+			{name: "wrong number", script: mocks.GenericRevokeKeyScript, args: keyAddArgs},
+			{name: "wrong type", script: mocks.GenericRevokeKeyScript, args: encode(cadence.NewUInt64(3))},
+			{name: "negative index", script: mocks.GenericRevokeKeyScript, args: encode(cadence.NewInt(-1))},
+			{name: "unknown algorithm", script: mocks.GenericAddKeyScript, args: encode(publicKey, cadence.NewUInt8(3), cadence.NewUInt8(1), cadence.UFix64(0))},
+			{name: "fractional weight", script: mocks.GenericAddKeyScript, args: encode(publicKey, cadence.NewUInt8(1), cadence.NewUInt8(1), cadence.UFix64(1))},
+		}
+
+		for _, test := range tests {
+			tx := &sdk.Transaction{
+				Payer:       sender,
+				ProposalKey: sdk.ProposalKey{Address: sender},
+				Authorizers: []sdk.Address{sender},
+				Script:      test.script,
+				Arguments:   test.args,
+			}
+
+			p := transactor.BaselineTransactionParser(t, transactor.InjectTransaction(tx))
+
+			_, err := p.Operations()
+
+			assert.Error(t, err, test.name)
+		}
+	})
+
+	t.Run("handles key script generation failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.AddAccountKeyFunc = func() ([]byte, error) {
+			return nil, mocks.GenericError
+		}
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericAddKeyScript,
+			Arguments:   keyAddArgs,
+		}
+
+		p := transactor.BaselineTransactionParser(
+			t,
+			transactor.InjectTransaction(tx),
+			transactor.InjectGenerator(generator),
+		)
+
+		_, err := p.Operations()
+
+		assert.Error(t, err)
+	})
 }
 
 func generateKey() (*flow.AccountPrivateKey, error) {
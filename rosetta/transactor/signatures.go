@@ -0,0 +1,119 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package transactor
+
+import (
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/failure"
+)
+
+// Rosetta signature and curve types for the signature algorithms that Flow
+// accounts can use.
+const (
+	signatureECDSA = "ecdsa"
+	curveSecp256r1 = "secp256r1"
+	curveSecp256k1 = "secp256k1"
+)
+
+// scheme describes how signatures with a given account key are represented in
+// the Rosetta API. Rosetta signature types do not depend on the hashing algorithm,
+// because signing payloads are already hashed with the hasher of the key.
+type scheme struct {
+	SignatureType string
+	CurveType     string
+	Length        int
+}
+
+// schemes maps the signature algorithms supported for Flow account keys to their
+// Rosetta representation. Both curves produce signatures made of the concatenated
+// 32-byte `r` and `s` values.
+var schemes = map[crypto.SignatureAlgorithm]scheme{
+	crypto.ECDSA_P256: {
+		SignatureType: signatureECDSA,
+		CurveType:     curveSecp256r1,
+		Length:        64,
+	},
+	crypto.ECDSA_secp256k1: {
+		SignatureType: signatureECDSA,
+		CurveType:     curveSecp256k1,
+		Length:        64,
+	},
+}
+
+// signingScheme returns the signing scheme of the given account key, and makes
+// sure that its hashing algorithm can be used to sign transactions.
+func signingScheme(height uint64, address flow.Address, key *flow.AccountPublicKey) (scheme, error) {
+
+	sch, ok := schemes[key.SignAlgo]
+	if !ok {
+		return scheme{}, failure.InvalidKey{
+			Description: failure.NewDescription(keySigAlgoUnsupported,
+				failure.WithString("signature_algorithm", key.SignAlgo.String())),
+			Height:  height,
+			Address: address,
+			Index:   key.Index,
+		}
+	}
+
+	if key.HashAlgo != crypto.SHA2_256 && key.HashAlgo != crypto.SHA3_256 {
+		return scheme{}, failure.InvalidKey{
+			Description: failure.NewDescription(keyHashAlgoUnsupported,
+				failure.WithString("hash_algorithm", key.HashAlgo.String())),
+			Height:  height,
+			Address: address,
+			Index:   key.Index,
+		}
+	}
+
+	return sch, nil
+}
+
+// rawSignature converts signatures returned by hardware wallets, such as the Flow
+// Ledger app, to the concatenated `r` and `s` values that Flow expects. These can
+// have a trailing recovery ID, or be DER-encoded. Signatures in any other encoding
+// are returned unchanged.
+func rawSignature(signature []byte, length int) []byte {
+
+	if len(signature) == length {
+		return signature
+	}
+	if len(signature) == length+1 {
+		return signature[:length]
+	}
+
+	var der struct {
+		R *big.Int
+		S *big.Int
+	}
+	rest, err := asn1.Unmarshal(signature, &der)
+	if err != nil || len(rest) != 0 || der.R.Sign() <= 0 || der.S.Sign() <= 0 {
+		return signature
+	}
+	size := length / 2
+	if len(der.R.Bytes()) > size || len(der.S.Bytes()) > size {
+		return signature
+	}
+
+	raw := make([]byte, length)
+	der.R.FillBytes(raw[:size])
+	der.S.FillBytes(raw[size:])
+
+	return raw
+}
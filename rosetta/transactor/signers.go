@@ -0,0 +1,75 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package transactor
+
+import (
+	sdk "github.com/onflow/flow-go-sdk"
+)
+
+// payloadSigners returns the accounts that need to sign the payload of the given
+// transaction, which are its authorizers and its proposer. The payer only signs
+// the envelope, even when it has other roles, so it is never part of them.
+func payloadSigners(tx *sdk.Transaction) []sdk.Address {
+
+	candidates := make([]sdk.Address, 0, len(tx.Authorizers)+1)
+	candidates = append(candidates, tx.Authorizers...)
+	candidates = append(candidates, tx.ProposalKey.Address)
+
+	seen := make(map[sdk.Address]struct{})
+	var signers []sdk.Address
+	for _, address := range candidates {
+		if address == tx.Payer {
+			continue
+		}
+		_, ok := seen[address]
+		if ok {
+			continue
+		}
+		seen[address] = struct{}{}
+		signers = append(signers, address)
+	}
+
+	return signers
+}
+
+// pendingSigners returns the payload signers of the given transaction which have
+// not signed its payload yet.
+func pendingSigners(tx *sdk.Transaction) []sdk.Address {
+
+	signed := make(map[sdk.Address]struct{})
+	for _, sig := range tx.PayloadSignatures {
+		signed[sig.Address] = struct{}{}
+	}
+
+	var pending []sdk.Address
+	for _, address := range payloadSigners(tx) {
+		_, ok := signed[address]
+		if !ok {
+			pending = append(pending, address)
+		}
+	}
+
+	return pending
+}
+
+// signerKey returns the index of the key with which the given account signs the
+// given transaction. The proposer signs with its proposal key, while the other
+// signers sign with their first key.
+func signerKey(tx *sdk.Transaction, signer sdk.Address) int {
+	if signer == tx.ProposalKey.Address {
+		return tx.ProposalKey.KeyIndex
+	}
+	return 0
+}
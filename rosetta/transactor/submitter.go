@@ -21,4 +21,5 @@ import (
 // Submitter represents something that can submit transactions.
 type Submitter interface {
 	Transaction(tx *sdk.Transaction) error
+	Submitted(txID sdk.Identifier) bool
 }
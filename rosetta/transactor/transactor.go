@@ -18,9 +18,8 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"sort"
-	"strconv"
 
 	"github.com/onflow/cadence"
 	sdk "github.com/onflow/flow-go-sdk"
@@ -28,22 +27,28 @@ import (
 	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
 	"github.com/optakt/flow-rosetta/rosetta/failure"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/intents"
 	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/templates"
 )
 
 const (
-	requiredAuthorizers = 1       // we only support one authorizer per transaction
-	requiredArguments   = 2       // transactions need to arguments (amount & receiver)
-	requiredOperations  = 2       // transactions are made of two operations (deposit & withdrawal)
-	requiredAlgorithm   = "ecdsa" // transactions are signed with ECSDA
+	requiredAuthorizers = 1    // we only support one authorizer per transaction
+	requiredArguments   = 2    // transactions need to arguments (amount & receiver)
+	requiredOperations  = 2    // transactions are made of two operations (deposit & withdrawal)
+	requiredWeight      = 1000 // signer keys need a total weight of 1000 to sign for an account
 )
 
 // Transactor can determine the transaction intent from an array of Rosetta
 // operations, create a Flow transaction from a transaction intent and
 // translate a Flow transaction back to an array of Rosetta operations.
 type Transactor struct {
+	cfg Config
+
 	validate Validator
 	generate Generator
 	invoke   Invoker
@@ -56,12 +61,27 @@ type Parser interface {
 	Sequence() uint64
 	Signers() ([]identifier.Account, error)
 	Operations() ([]object.Operation, error)
+	Roles() *object.Roles
+	GasLimit() uint64
 }
 
 // New creates a new transactor to handle interactions with Flow transactions.
-func New(validate Validator, generate Generator, invoke Invoker, submit Submitter) *Transactor {
+// By default, no transaction templates are allowlisted, no other scripts are known,
+// and transactions get the default gas limit.
+func New(validate Validator, generate Generator, invoke Invoker, submit Submitter, options ...func(*Config)) *Transactor {
+
+	cfg := Config{
+		Templates: &templates.Registry{},
+		Intents:   &intents.Registry{},
+		GasLimit:  DefaultGasLimit,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
 
 	p := Transactor{
+		cfg:      cfg,
 		validate: validate,
 		generate: generate,
 		invoke:   invoke,
@@ -74,8 +94,31 @@ func New(validate Validator, generate Generator, invoke Invoker, submit Submitte
 // DeriveIntent derives a transaction Intent from two operations given as input.
 // Specified operations should be symmetrical, a deposit and a withdrawal from two
 // different accounts. At the moment, the only fields taken into account are the
-// account IDs, amounts and type of operation.
-func (t *Transactor) DeriveIntent(operations []object.Operation) (*Intent, error) {
+// account IDs, amounts and type of operation. Alternatively, a single template
+// operation can reference one of the allowlisted transaction templates in its
+// metadata, in which case the intent is derived from the template instead, or
+// add or revoke a key of its account.
+// The sender authorizes the transaction, and also pays for and proposes it,
+// unless the given roles designate other accounts to do so.
+func (t *Transactor) DeriveIntent(operations []object.Operation, roles *object.Roles) (*Intent, error) {
+
+	// Template operations stand on their own, so they are handled separately.
+	if len(operations) == 1 && operations[0].Type == configuration.OperationTemplate {
+		intent, err := t.deriveTemplateIntent(operations[0])
+		if err != nil {
+			return nil, err
+		}
+		return t.assignRoles(intent, roles)
+	}
+
+	// Key operations also stand on their own.
+	if len(operations) == 1 && (operations[0].Type == configuration.OperationKeyAdd || operations[0].Type == configuration.OperationKeyRevoke) {
+		intent, err := t.deriveKeyIntent(operations[0])
+		if err != nil {
+			return nil, err
+		}
+		return t.assignRoles(intent, roles)
+	}
 
 	// Verify that we have exactly two operations.
 	if len(operations) != requiredOperations {
@@ -86,10 +129,20 @@ func (t *Transactor) DeriveIntent(operations []object.Operation) (*Intent, error
 		}
 	}
 
-	// Parse amounts.
-	amounts := make([]int64, requiredOperations)
+	// Parse amounts, which are signed to distinguish the withdrawal from the deposit.
+	units := make([]uint64, requiredOperations)
+	negative := make([]bool, requiredOperations)
 	for i, op := range operations {
-		amount, err := strconv.ParseInt(op.Amount.Value, 10, 64)
+		value, sign, err := amount.ParseSigned(op.Amount.Value)
+		if errors.Is(err, amount.ErrPrecision) {
+			return nil, failure.InvalidIntent{
+				Description: failure.NewDescription(opAmountPrecision,
+					failure.WithString("amount", op.Amount.Value),
+					failure.WithString("symbol", op.Amount.Currency.Symbol),
+					failure.WithInt("decimals", dps.FlowDecimals),
+				),
+			}
+		}
 		if err != nil {
 			return nil, failure.InvalidIntent{
 				Description: failure.NewDescription(opAmountUnparseable,
@@ -98,11 +151,12 @@ func (t *Transactor) DeriveIntent(operations []object.Operation) (*Intent, error
 				),
 			}
 		}
-		amounts[i] = amount
+		units[i] = value
+		negative[i] = sign
 	}
 
 	// Verify that the amounts match.
-	if amounts[0] != -amounts[1] {
+	if units[0] != units[1] || (units[0] != 0 && negative[0] == negative[1]) {
 		return nil, failure.InvalidIntent{
 			Description: failure.NewDescription(opsAmountsMismatch,
 				failure.WithString("first_amount", operations[0].Amount.Value),
@@ -111,13 +165,10 @@ func (t *Transactor) DeriveIntent(operations []object.Operation) (*Intent, error
 		}
 	}
 
-	// Sort the operations so that the send operation (negative amount) comes first.
-	sort.Slice(operations, func(i int, j int) bool {
-		return amounts[i] < amounts[j]
-	})
-	sort.Slice(amounts, func(i int, j int) bool {
-		return amounts[i] < amounts[j]
-	})
+	// Make sure that the send operation (negative amount) comes first.
+	if negative[1] {
+		operations[0], operations[1] = operations[1], operations[0]
+	}
 
 	// Validate the currencies specified for deposit and withdrawal.
 	send := operations[0]
@@ -162,27 +213,60 @@ func (t *Transactor) DeriveIntent(operations []object.Operation) (*Intent, error
 		}
 	}
 
-	// The smaller amount is first, so the second one should always have the
-	// positive number.
-	amount := amounts[1]
 	intent := Intent{
 		From:     flow.HexToAddress(send.AccountID.Address),
 		To:       flow.HexToAddress(receive.AccountID.Address),
-		Amount:   cadence.UFix64(amount),
+		Amount:   cadence.UFix64(units[0]),
 		Payer:    flow.HexToAddress(send.AccountID.Address),
 		Proposer: flow.HexToAddress(send.AccountID.Address),
 	}
 
-	return &intent, nil
+	return t.assignRoles(&intent, roles)
 }
 
 // CompileTransaction creates a complete Flow transaction from the given intent and metadata.
 func (t *Transactor) CompileTransaction(rosBlockID identifier.Block, intent *Intent, sequence uint64) (string, error) {
 
-	// Generate script for the token transfer.
-	script, err := t.generate.TransferTokens(dps.FlowSymbol)
-	if err != nil {
-		return "", fmt.Errorf("could not generate transfer script: %w", err)
+	// Use the script of the transaction template if the intent references one,
+	// the script of the key operation if it manages a key, or generate the script
+	// for the token transfer otherwise. In the latter case, the arguments are the
+	// amount and the receiver.
+	var script []byte
+	var arguments []cadence.Value
+	var err error
+	switch {
+	case intent.Template != "":
+		template, ok := t.cfg.Templates.Template(intent.Template)
+		if !ok {
+			return "", failure.InvalidIntent{
+				Description: failure.NewDescription(templateUnknown,
+					failure.WithString("template", intent.Template)),
+			}
+		}
+		script = template.Script
+		arguments = intent.Arguments
+
+	case intent.KeyOperation == configuration.OperationKeyAdd:
+		script, err = t.generate.AddAccountKey()
+		if err != nil {
+			return "", fmt.Errorf("could not generate key addition script: %w", err)
+		}
+		arguments = intent.Arguments
+
+	case intent.KeyOperation == configuration.OperationKeyRevoke:
+		script, err = t.generate.RevokeAccountKey()
+		if err != nil {
+			return "", fmt.Errorf("could not generate key revocation script: %w", err)
+		}
+		arguments = intent.Arguments
+
+	default:
+		script, err = t.generate.TransferTokens(dps.FlowSymbol)
+		if err != nil {
+			return "", fmt.Errorf("could not generate transfer script: %w", err)
+		}
+		receiver := cadence.NewAddress(flow.BytesToAddress(intent.To.Bytes()))
+		arguments = []cadence.Value{intent.Amount, receiver}
 	}
 
 	// Create the transaction.
@@ -190,17 +274,16 @@ func (t *Transactor) CompileTransaction(rosBlockID identifier.Block, intent *Int
 		SetScript(script).
 		SetReferenceBlockID(sdk.HexToID(rosBlockID.Hash)).
 		SetPayer(sdk.Address(intent.Payer)).
-		SetProposalKey(sdk.Address(intent.Proposer), 0, sequence).
+		SetProposalKey(sdk.Address(intent.Proposer), intent.KeyIndex, sequence).
 		AddAuthorizer(sdk.Address(intent.From)).
-		SetGasLimit(flow.DefaultMaxTransactionGasLimit)
+		SetGasLimit(t.gasLimit(intent.GasLimit))
 
-	receiver := cadence.NewAddress(flow.BytesToAddress(intent.To.Bytes()))
-
-	// Add the script arguments - the amount and the receiver.
+	// Add the script arguments.
 	// NOTE: This can only fail if the argument can not be encoded using the
 	// Cadence JSON encoder, which will never happen here.
-	_ = unsignedTx.AddArgument(intent.Amount)
-	_ = unsignedTx.AddArgument(receiver)
+	for _, argument := range arguments {
+		_ = unsignedTx.AddArgument(argument)
+	}
 
 	payload, err := t.encodeTransaction(unsignedTx)
 	if err != nil {
@@ -210,8 +293,45 @@ func (t *Transactor) CompileTransaction(rosBlockID identifier.Block, intent *Int
 	return payload, nil
 }
 
+// SigningPayloads returns the signing payloads that are still required for the given
+// transaction, which can be unsigned or partially signed. The payload signers are
+// asked to sign first; the payer can only sign the envelope once their signatures
+// are attached, since the envelope includes them. Fully signed transactions need
+// no further signing payloads.
+func (t *Transactor) SigningPayloads(unsigned string) ([]object.SigningPayload, error) {
+
+	unsignedTx, err := t.decodeTransaction(unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode transaction: %w", err)
+	}
+
+	signers := pendingSigners(unsignedTx)
+	if len(signers) == 0 && len(unsignedTx.EnvelopeSignatures) == 0 {
+		signers = []sdk.Address{unsignedTx.Payer}
+	}
+
+	rosBlockID := identifier.Block{Hash: unsignedTx.ReferenceBlockID.Hex()}
+	payloads := make([]object.SigningPayload, 0, len(signers))
+	for _, signer := range signers {
+		rosAccountID := identifier.Account{Address: signer.Hex()}
+		algo, hash, err := t.HashPayload(rosBlockID, unsigned, rosAccountID)
+		if err != nil {
+			return nil, fmt.Errorf("could not hash signing payload (signer: %s): %w", signer.Hex(), err)
+		}
+		payload := object.SigningPayload{
+			AccountID:     rosAccountID,
+			HexBytes:      hash,
+			SignatureType: algo,
+		}
+		payloads = append(payloads, payload)
+	}
+
+	return payloads, nil
+}
+
 // HashPayload returns the algorithm and hash of a given unsigned transaction when signed by
-// a given account's public key.
+// a given account's public key. Payload signers that have not signed yet sign the
+// transaction payload, while any other account signs the transaction envelope.
 func (t *Transactor) HashPayload(rosBlockID identifier.Block, unsigned string, signer identifier.Account) (string, string, error) {
 
 	unsignedTx, err := t.decodeTransaction(unsigned)
@@ -219,42 +339,118 @@ func (t *Transactor) HashPayload(rosBlockID identifier.Block, unsigned string, s
 		return "", "", fmt.Errorf("could not decode transaction: %w", err)
 	}
 
+	algo, message, key, err := t.signingMessage(rosBlockID, unsignedTx, signer)
+	if err != nil {
+		return "", "", err
+	}
+	message = append(flow.TransactionDomainTag[:], message...)
+
+	hasher, err := crypto.NewHasher(key.HashAlgo)
+	if err != nil {
+		return "", "", fmt.Errorf("could not create hasher: %w", err)
+	}
+
+	hash := hex.EncodeToString(hasher.ComputeHash(message))
+
+	return algo, hash, nil
+}
+
+// DetachedPayloads returns the signing payloads of a given unsigned transaction in the
+// given format, along with a human-readable summary of the transaction. Instead of the
+// hash, each payload holds the canonical RLP-encoded message, so that an offline signer
+// or a Ledger device can decode and review exactly what it signs, and hash it with the
+// algorithm of its own key.
+func (t *Transactor) DetachedPayloads(unsigned string, format string) ([]object.SigningPayload, *object.Summary, error) {
+
+	unsignedTx, err := t.decodeTransaction(unsigned)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not decode transaction: %w", err)
+	}
+
+	signers := pendingSigners(unsignedTx)
+	if len(signers) == 0 && len(unsignedTx.EnvelopeSignatures) == 0 {
+		signers = []sdk.Address{unsignedTx.Payer}
+	}
+	if len(signers) == 0 {
+		return []object.SigningPayload{}, nil, nil
+	}
+
+	rosBlockID := identifier.Block{Hash: unsignedTx.ReferenceBlockID.Hex()}
+	payloads := make([]object.SigningPayload, 0, len(signers))
+	for _, signer := range signers {
+		rosAccountID := identifier.Account{Address: signer.Hex()}
+		algo, message, _, err := t.signingMessage(rosBlockID, unsignedTx, rosAccountID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not encode signing payload (signer: %s): %w", signer.Hex(), err)
+		}
+		data, err := encodeMessage(message, format)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not encode signing payload (signer: %s): %w", signer.Hex(), err)
+		}
+		payload := object.SigningPayload{
+			AccountID:     rosAccountID,
+			HexBytes:      data,
+			SignatureType: algo,
+		}
+		payloads = append(payloads, payload)
+	}
+
+	summary, err := t.summarize(unsignedTx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not summarize transaction: %w", err)
+	}
+
+	return payloads, summary, nil
+}
+
+// signingMessage returns the signature type, the message and the key with which the
+// given signer signs the given transaction. The message does not include the domain tag.
+func (t *Transactor) signingMessage(rosBlockID identifier.Block, tx *sdk.Transaction, signer identifier.Account) (string, []byte, *flow.AccountPublicKey, error) {
+
 	// Validate block.
 	height, _, err := t.validate.Block(rosBlockID)
 	if err != nil {
-		return "", "", fmt.Errorf("could not validate block: %w", err)
+		return "", nil, nil, fmt.Errorf("could not validate block: %w", err)
 	}
 
 	// Validate address.
 	address, err := t.validate.Account(signer)
 	if err != nil {
-		return "", "", fmt.Errorf("could not validate account: %w", err)
+		return "", nil, nil, fmt.Errorf("could not validate account: %w", err)
 	}
 
-	key, err := t.invoke.Key(height, address, 0)
+	index := signerKey(tx, sdk.Address(address))
+	key, err := t.invoke.Key(height, address, index)
 	if err != nil {
-		return "", "", failure.InvalidKey{
+		return "", nil, nil, failure.InvalidKey{
 			Description: failure.NewDescription(keyInvalid, failure.WithErr(err)),
 			Height:      height,
 			Address:     address,
-			Index:       0,
+			Index:       index,
 		}
 	}
 
-	message := unsignedTx.EnvelopeMessage()
-	message = append(flow.TransactionDomainTag[:], message...)
-
-	hasher, err := crypto.NewHasher(key.HashAlgo)
+	sch, err := signingScheme(height, address, key)
 	if err != nil {
-		return "", "", fmt.Errorf("could not create hasher: %w", err)
+		return "", nil, nil, fmt.Errorf("could not determine signing scheme: %w", err)
 	}
 
-	hash := hex.EncodeToString(hasher.ComputeHash(message))
+	message := tx.EnvelopeMessage()
+	for _, pending := range pendingSigners(tx) {
+		if pending == sdk.Address(address) {
+			message = tx.PayloadMessage()
+			break
+		}
+	}
 
-	return requiredAlgorithm, hash, nil
+	return sch.SignatureType, message, key, nil
 }
 
 // AttachSignatures returns the given transaction with the given signatures attached to it.
+// Signatures of payload signers are attached to the payload, while the signature of the
+// payer is attached to the envelope. The payer can only sign once all payload signatures
+// are attached, so when it is distinct from the other signers, signatures are attached
+// in two rounds.
 func (t *Transactor) AttachSignatures(unsigned string, signatures []object.Signature) (string, error) {
 
 	unsignedTx, err := t.decodeTransaction(unsigned)
@@ -271,64 +467,147 @@ func (t *Transactor) AttachSignatures(unsigned string, signatures []object.Signa
 		}
 	}
 
-	// We expect one signature for the one signer.
-	if len(unsignedTx.Authorizers) != len(signatures) {
+	// Verify that we do not already have an envelope signature.
+	if len(unsignedTx.EnvelopeSignatures) > 0 {
+		return "", failure.InvalidSignature{
+			Description: failure.NewDescription(envelopeSigFound,
+				failure.WithInt("signatures", len(unsignedTx.EnvelopeSignatures))),
+		}
+	}
+
+	// We expect at most one signature for each pending payload signer, and one
+	// for the payer.
+	pending := pendingSigners(unsignedTx)
+	want := uint(len(pending) + 1)
+	if len(signatures) == 0 || uint(len(signatures)) > want {
 		return "", failure.InvalidSignatures{
 			Have:        uint(len(signatures)),
-			Want:        uint(len(unsignedTx.Authorizers)),
+			Want:        want,
 			Description: failure.NewDescription(sigCountInvalid),
 		}
 	}
 
-	// Verify that the sender is the payer, since it is the payer that needs to sign the envelope.
-	sender := unsignedTx.Authorizers[0]
-	signature := signatures[0]
-	if unsignedTx.Payer != sender {
-		return "", failure.InvalidPayer{
-			Have:        flow.BytesToAddress(unsignedTx.Payer[:]),
-			Want:        flow.BytesToAddress(sender[:]),
-			Description: failure.NewDescription(payerInvalid),
-		}
+	signers := make(map[sdk.Address]struct{}, len(pending))
+	for _, signer := range pending {
+		signers[signer] = struct{}{}
 	}
 
-	// Verify that we do not already have signatures.
-	if len(unsignedTx.EnvelopeSignatures) > 0 {
-		return "", failure.InvalidSignature{
-			Description: failure.NewDescription(envelopeSigFound,
-				failure.WithInt("signatures", len(unsignedTx.EnvelopeSignatures))),
-		}
+	// Signer keys are looked up at the reference block of the transaction, which
+	// is the height at which their signing payloads were hashed.
+	rosBlockID := identifier.Block{Hash: unsignedTx.ReferenceBlockID.Hex()}
+	height, _, err := t.validate.Block(rosBlockID)
+	if err != nil {
+		return "", fmt.Errorf("could not validate block: %w", err)
 	}
 
-	// Verify that the signature belongs to the sender.
-	signer := sdk.HexToAddress(signature.SigningPayload.AccountID.Address)
-	if signer != sender {
-		return "", failure.InvalidSignature{
-			Description: failure.NewDescription(signerInvalid,
-				failure.WithString("have_signer", signer.Hex()),
-				failure.WithString("want_signer", sender.Hex()),
-			),
+	// Signatures produced from detached signing payloads are checked against the
+	// messages they should have been produced from.
+	payloadMessage := unsignedTx.PayloadMessage()
+	envelopeMessage := unsignedTx.EnvelopeMessage()
+
+	var envelope []byte
+	for _, signature := range signatures {
+
+		// Verify that the signature belongs to one of the expected signers,
+		// and that each of them only signs once.
+		signer := sdk.HexToAddress(signature.SigningPayload.AccountID.Address)
+		_, isPending := signers[signer]
+		isPayer := signer == unsignedTx.Payer && envelope == nil
+		if !isPending && !isPayer {
+			return "", failure.InvalidSignature{
+				Description: failure.NewDescription(signerInvalid,
+					failure.WithString("have_signer", signer.Hex()),
+					failure.WithString("want_payer", unsignedTx.Payer.Hex()),
+				),
+			}
 		}
-	}
 
-	if signature.SignatureType != requiredAlgorithm {
-		return "", failure.InvalidSignature{
-			Description: failure.NewDescription(sigAlgoInvalid,
-				failure.WithString("have_algo", signature.SignatureType),
-				failure.WithString("want_algo", requiredAlgorithm),
-			),
+		// Verify that the signature matches the signing scheme of the signer's key.
+		address := flow.BytesToAddress(signer[:])
+		index := signerKey(unsignedTx, signer)
+		key, err := t.invoke.Key(height, address, index)
+		if err != nil {
+			return "", failure.InvalidKey{
+				Description: failure.NewDescription(keyInvalid, failure.WithErr(err)),
+				Height:      height,
+				Address:     address,
+				Index:       index,
+			}
+		}
+		sch, err := signingScheme(height, address, key)
+		if err != nil {
+			return "", fmt.Errorf("could not determine signing scheme: %w", err)
+		}
+
+		if signature.SignatureType != sch.SignatureType {
+			return "", failure.InvalidSignature{
+				Description: failure.NewDescription(sigAlgoInvalid,
+					failure.WithString("have_algo", signature.SignatureType),
+					failure.WithString("want_algo", sch.SignatureType),
+				),
+			}
 		}
+		curve := signature.PublicKey.CurveType
+		if curve != "" && curve != sch.CurveType {
+			return "", failure.InvalidSignature{
+				Description: failure.NewDescription(sigCurveInvalid,
+					failure.WithString("have_curve", curve),
+					failure.WithString("want_curve", sch.CurveType),
+				),
+			}
+		}
+
+		bytes, err := hex.DecodeString(signature.HexBytes)
+		if err != nil {
+			return "", failure.InvalidSignature{
+				Description: failure.NewDescription(sigEncoding,
+					failure.WithErr(err)),
+			}
+		}
+		bytes = rawSignature(bytes, sch.Length)
+		if len(bytes) != sch.Length {
+			return "", failure.InvalidSignature{
+				Description: failure.NewDescription(sigLengthInvalid,
+					failure.WithInt("have_length", len(bytes)),
+					failure.WithInt("want_length", sch.Length),
+				),
+			}
+		}
+
+		format := Format(signature.SigningPayload.HexBytes)
+		if format != "" {
+			message := envelopeMessage
+			if isPending {
+				message = payloadMessage
+			}
+			err = verifyDetached(key, signature.SigningPayload.HexBytes, format, message, bytes)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if isPending {
+			unsignedTx.AddPayloadSignature(signer, index, bytes)
+			delete(signers, signer)
+			continue
+		}
+		envelope = bytes
 	}
 
-	bytes, err := hex.DecodeString(signature.HexBytes)
-	if err != nil {
-		return "", failure.InvalidSignature{
-			Description: failure.NewDescription(sigEncoding,
-				failure.WithErr(err)),
+	// The envelope includes the payload signatures, so the payer can only have
+	// signed it if they were all attached in a previous round.
+	if envelope != nil && len(pending) > 0 {
+		return "", failure.InvalidSignatures{
+			Have:        uint(len(signatures)),
+			Want:        uint(len(pending)),
+			Description: failure.NewDescription(payloadSigsMissing),
 		}
 	}
+	if envelope != nil {
+		unsignedTx.AddEnvelopeSignature(unsignedTx.Payer, signerKey(unsignedTx, unsignedTx.Payer), envelope)
+	}
 
-	signedTx := unsignedTx.AddEnvelopeSignature(signer, 0, bytes)
-	signed, err := t.encodeTransaction(signedTx)
+	signed, err := t.encodeTransaction(unsignedTx)
 	if err != nil {
 		return "", fmt.Errorf("could not encode transaction: %w", err)
 	}
@@ -351,14 +630,67 @@ func (t *Transactor) TransactionIdentifier(signed string) (identifier.Transactio
 	return rosTxID, nil
 }
 
-// SubmitTransaction submits the given signed transaction.
-func (t *Transactor) SubmitTransaction(signed string) (identifier.Transaction, error) {
+// SubmitTransaction submits the given signed transaction. It fails without submitting
+// the transaction if its reference block has expired at the given current block, or
+// if the sequence number of its proposal key was already used at that block.
+// Transactions that were already submitted are not checked again, so that retries
+// return the identifier of the original submission, even once it was indexed.
+func (t *Transactor) SubmitTransaction(current identifier.Block, signed string) (identifier.Transaction, error) {
 
 	signedTx, err := t.decodeTransaction(signed)
 	if err != nil {
 		return identifier.Transaction{}, fmt.Errorf("could not decode transaction: %w", err)
 	}
 
+	if t.submit.Submitted(signedTx.ID()) {
+		return rosettaTxID(signedTx.ID()), nil
+	}
+
+	// Check the expiry of the transaction against the current block, since the
+	// network would otherwise reject it with a less descriptive error.
+	refHeight, _, err := t.validate.Block(identifier.Block{Hash: signedTx.ReferenceBlockID.Hex()})
+	if err != nil {
+		return identifier.Transaction{}, fmt.Errorf("invalid reference block: %w", err)
+	}
+	height, _, err := t.validate.Block(current)
+	if err != nil {
+		return identifier.Transaction{}, fmt.Errorf("invalid current block: %w", err)
+	}
+	expiry := refHeight + flow.DefaultTransactionExpiry
+	if height > expiry {
+		return identifier.Transaction{}, failure.ExpiredTransaction{
+			Description:     failure.NewDescription(txExpired),
+			ReferenceHeight: refHeight,
+			ExpiryHeight:    expiry,
+			CurrentHeight:   height,
+			Proposer:        flow.Address(signedTx.ProposalKey.Address),
+			KeyIndex:        signedTx.ProposalKey.KeyIndex,
+			Payer:           flow.Address(signedTx.Payer),
+			GasLimit:        signedTx.GasLimit,
+		}
+	}
+
+	// A sequence number below the one of the proposal key at the current block
+	// was already used, and the network would reject the transaction only once
+	// it is executed. Higher sequence numbers can be valid, as transactions
+	// that are still pending are not indexed yet. If the key can not be found,
+	// for example because the account was created after the last indexed
+	// block, we leave the decision to the network.
+	proposal := signedTx.ProposalKey
+	key, err := t.invoke.Key(height, flow.Address(proposal.Address), proposal.KeyIndex)
+	if err == nil && proposal.SequenceNumber < key.SeqNumber {
+		return identifier.Transaction{}, failure.SequenceConflict{
+			Description: failure.NewDescription(txSequenceConflict),
+			Proposer:    flow.Address(proposal.Address),
+			KeyIndex:    proposal.KeyIndex,
+			Sequence:    proposal.SequenceNumber,
+			Current:     key.SeqNumber,
+		}
+	}
+
+	// When the index lags behind the network, the sequence number can pass the
+	// check above and still be rejected by the Access API, in which case the
+	// submitter returns the sequence conflict.
 	err = t.submit.Transaction(signedTx)
 	if err != nil {
 		return identifier.Transaction{}, fmt.Errorf("could not submit transaction: %w", err)
@@ -367,6 +699,194 @@ func (t *Transactor) SubmitTransaction(signed string) (identifier.Transaction, e
 	return rosettaTxID(signedTx.ID()), nil
 }
 
+// Simulate predicts the outcome of the given transaction by executing it
+// against the state at its reference block, so that failures can be caught
+// before it is broadcast. It returns the reference block, the operations that
+// the transaction is expected to produce and the fee charged to its payer.
+//
+// Transfers of FLOW tokens are checked before the execution, so that a sender
+// without enough tokens to cover the amount, along with the fee when it also
+// pays for the transaction, or a receiver without a FLOW vault are reported as
+// such. The transaction is then executed, with its fee deducted from the
+// payer, but without checking its signatures or its sequence number, so that
+// it can be simulated before it is signed.
+func (t *Transactor) Simulate(payload string) (identifier.Block, []object.Operation, uint64, error) {
+
+	tx, err := t.decodeTransaction(payload)
+	if err != nil {
+		return identifier.Block{}, nil, 0, fmt.Errorf("could not decode transaction: %w", err)
+	}
+	parse := t.parser(tx)
+
+	rosBlockID, err := parse.BlockID()
+	if err != nil {
+		return identifier.Block{}, nil, 0, fmt.Errorf("could not parse reference block: %w", err)
+	}
+	height := *rosBlockID.Index
+
+	operations, err := parse.Operations()
+	if err != nil {
+		return identifier.Block{}, nil, 0, fmt.Errorf("could not parse operations: %w", err)
+	}
+
+	fee, err := t.transactionFee(height)
+	if err != nil {
+		return identifier.Block{}, nil, 0, err
+	}
+
+	if len(operations) == requiredOperations && operations[1].Amount.Currency.Symbol == dps.FlowSymbol {
+		err = t.checkTransfer(height, operations, flow.Address(tx.Payer), fee)
+		if err != nil {
+			return identifier.Block{}, nil, 0, fmt.Errorf("could not check transfer: %w", err)
+		}
+	}
+
+	err = t.invoke.Transaction(height, flowTransaction(tx))
+	if err != nil {
+		return identifier.Block{}, nil, 0, fmt.Errorf("could not execute transaction: %w", err)
+	}
+
+	return rosBlockID, operations, fee, nil
+}
+
+// checkTransfer checks that the sender of the given transfer operations has
+// enough FLOW tokens to cover the transferred amount, along with the fee when it
+// is the payer of the transaction, and that the receiver has a FLOW vault.
+func (t *Transactor) checkTransfer(height uint64, operations []object.Operation, payer flow.Address, fee uint64) error {
+
+	script, err := t.generate.GetBalance(dps.FlowSymbol)
+	if err != nil {
+		return fmt.Errorf("could not generate balance script: %w", err)
+	}
+
+	// The parser always returns the send operation first, with the amount of
+	// the receive operation being the transferred amount.
+	sender := flow.HexToAddress(operations[0].AccountID.Address)
+	receiver := flow.HexToAddress(operations[1].AccountID.Address)
+	transfer := operations[1].Amount.Value
+
+	value, err := t.invoke.Script(height, script, []cadence.Value{cadence.NewAddress(sender)})
+	if err != nil {
+		return fmt.Errorf("could not get sender balance: %w", err)
+	}
+	balance, ok := value.ToGoValue().(uint64)
+	if !ok {
+		return fmt.Errorf("could not convert sender balance (type: %T)", value.ToGoValue())
+	}
+	want, err := amount.Parse(transfer)
+	if err != nil {
+		return failure.InvalidAmount{
+			Amount: transfer,
+			Description: failure.NewDescription(amountUnparseable,
+				failure.WithErr(err)),
+		}
+	}
+	if sender == payer {
+		want += fee
+	}
+	if balance < want {
+		return failure.InsufficientBalance{
+			Description: failure.NewDescription(balanceInsufficient,
+				failure.WithString("fee", amount.Format(fee))),
+			Address: sender,
+			Balance: amount.Format(balance),
+			Amount:  amount.Format(want),
+		}
+	}
+
+	// The balance script fails if the account does not have a FLOW vault, in
+	// which case the deposit would fail as well.
+	_, err = t.invoke.Script(height, script, []cadence.Value{cadence.NewAddress(receiver)})
+	if err != nil {
+		return failure.InvalidReceiver{
+			Receiver: receiver.Hex(),
+			Description: failure.NewDescription(receiverVaultMissing,
+				failure.WithErr(err)),
+		}
+	}
+
+	return nil
+}
+
+func (t *Transactor) assignRoles(intent *Intent, roles *object.Roles) (*Intent, error) {
+
+	if roles == nil {
+		return intent, nil
+	}
+
+	if roles.PayerID != nil {
+		payer, err := t.validate.Account(*roles.PayerID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payer account: %w", err)
+		}
+		intent.Payer = payer
+	}
+
+	if roles.ProposerID != nil {
+		proposer, err := t.validate.Account(*roles.ProposerID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proposer account: %w", err)
+		}
+		intent.Proposer = proposer
+	}
+
+	if roles.ProposerKeyIndex < 0 {
+		return nil, failure.InvalidProposer{
+			Description: failure.NewDescription(proposerKeyInvalid,
+				failure.WithInt("proposer_key_index", roles.ProposerKeyIndex)),
+		}
+	}
+	intent.KeyIndex = roles.ProposerKeyIndex
+
+	return intent, nil
+}
+
+func (t *Transactor) deriveTemplateIntent(operation object.Operation) (*Intent, error) {
+
+	// Make sure that the operation references a transaction template.
+	if operation.Metadata == nil || operation.Metadata.Template == "" {
+		return nil, failure.InvalidIntent{
+			Description: failure.NewDescription(templateMissing),
+		}
+	}
+
+	// Only allowlisted templates can be used.
+	name := operation.Metadata.Template
+	template, ok := t.cfg.Templates.Template(name)
+	if !ok {
+		return nil, failure.InvalidIntent{
+			Description: failure.NewDescription(templateUnknown,
+				failure.WithString("template", name)),
+		}
+	}
+
+	// Validate the account which authorizes and pays for the transaction.
+	address, err := t.validate.Account(operation.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender account: %w", err)
+	}
+
+	arguments, err := template.Encode(operation.Metadata.Arguments)
+	if err != nil {
+		return nil, failure.InvalidIntent{
+			Description: failure.NewDescription(templateArgsInvalid,
+				failure.WithString("template", name),
+				failure.WithErr(err),
+			),
+		}
+	}
+
+	intent := Intent{
+		From:      address,
+		Payer:     address,
+		Proposer:  address,
+		Template:  template.Name,
+		Arguments: arguments,
+	}
+
+	return &intent, nil
+}
+
 func (t *Transactor) encodeTransaction(tx *sdk.Transaction) (string, error) {
 
 	data, err := json.Marshal(tx)
@@ -408,12 +928,106 @@ func (t *Transactor) Parse(payload string) (Parser, error) {
 		return nil, err
 	}
 
+	return t.parser(tx), nil
+}
+
+// parser returns the parser for the given transaction.
+func (t *Transactor) parser(tx *sdk.Transaction) *TransactionParser {
 	p := TransactionParser{
-		tx:       tx,
-		validate: t.validate,
-		generate: t.generate,
-		invoke:   t.invoke,
+		tx:        tx,
+		validate:  t.validate,
+		generate:  t.generate,
+		invoke:    t.invoke,
+		templates: t.cfg.Templates,
+		intents:   t.cfg.Intents,
+	}
+	return &p
+}
+
+func (t *Transactor) deriveKeyIntent(operation object.Operation) (*Intent, error) {
+
+	// Make sure that the operation describes the key.
+	if operation.Metadata == nil {
+		return nil, failure.InvalidIntent{
+			Description: failure.NewDescription(keyMetadataMissing,
+				failure.WithString("type", operation.Type)),
+		}
 	}
 
-	return &p, nil
+	// Validate the account whose keys are managed, which authorizes the transaction.
+	address, err := t.validate.Account(operation.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender account: %w", err)
+	}
+
+	intent := Intent{
+		From:         address,
+		Payer:        address,
+		Proposer:     address,
+		KeyOperation: operation.Type,
+	}
+
+	meta := operation.Metadata
+	if operation.Type == configuration.OperationKeyRevoke {
+		if meta.KeyIndex == nil || *meta.KeyIndex < 0 {
+			return nil, failure.InvalidIntent{
+				Description: failure.NewDescription(keyIndexInvalid),
+			}
+		}
+		intent.Arguments = []cadence.Value{
+			cadence.NewInt(*meta.KeyIndex),
+		}
+		return &intent, nil
+	}
+
+	// Validate the algorithms, the public key and the weight of the key to add.
+	sigAlgo := crypto.StringToSignatureAlgorithm(meta.SignatureAlgorithm)
+	rawSigAlgo, ok := encodeSigAlgo(sigAlgo)
+	if !ok {
+		return nil, failure.InvalidIntent{
+			Description: failure.NewDescription(keySigAlgoUnsupported,
+				failure.WithString("signature_algorithm", meta.SignatureAlgorithm)),
+		}
+	}
+	hashAlgo := crypto.StringToHashAlgorithm(meta.HashAlgorithm)
+	rawHashAlgo, ok := encodeHashAlgo(hashAlgo)
+	if !ok {
+		return nil, failure.InvalidIntent{
+			Description: failure.NewDescription(keyHashAlgoUnsupported,
+				failure.WithString("hash_algorithm", meta.HashAlgorithm)),
+		}
+	}
+	key, err := crypto.DecodePublicKeyHex(sigAlgo, meta.PublicKey)
+	if err != nil {
+		return nil, failure.InvalidIntent{
+			Description: failure.NewDescription(publicKeyInvalid,
+				failure.WithString("public_key", meta.PublicKey),
+				failure.WithErr(err)),
+		}
+	}
+	if meta.Weight < 0 || meta.Weight > maxKeyWeight {
+		return nil, failure.InvalidIntent{
+			Description: failure.NewDescription(keyWeightInvalid,
+				failure.WithInt("weight", meta.Weight)),
+		}
+	}
+
+	// The public key is re-encoded, so that it always matches the format
+	// expected by the script.
+	weight, err := cadence.NewUFix64FromParts(meta.Weight, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode key weight: %w", err)
+	}
+	publicKey, err := cadence.NewString(hex.EncodeToString(key.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("could not encode public key: %w", err)
+	}
+	intent.Arguments = []cadence.Value{
+		publicKey,
+		cadence.NewUInt8(rawSigAlgo),
+		cadence.NewUInt8(rawHashAlgo),
+		weight,
+	}
+
+	return &intent, nil
 }
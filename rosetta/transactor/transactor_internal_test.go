@@ -13,19 +13,26 @@ func TestNew(t *testing.T) {
 	generate := mocks.BaselineGenerator(t)
 	invoke := mocks.BaselineInvoker(t)
 	submit := mocks.BaselineSubmitter(t)
+	templates := mocks.BaselineTemplates(t)
 
-	tr := New(validate, generate, invoke, submit)
+	tr := New(validate, generate, invoke, submit, WithTemplates(templates))
 
 	assert.Equal(t, validate, tr.validate)
 	assert.Equal(t, generate, tr.generate)
 	assert.Equal(t, invoke, tr.invoke)
 	assert.Equal(t, submit, tr.submit)
+	assert.Equal(t, templates, tr.cfg.Templates)
 }
 
 func BaselineTransactor(t *testing.T, opts ...func(*Transactor)) *Transactor {
 	t.Helper()
 
 	tr := Transactor{
+		cfg: Config{
+			Templates: mocks.BaselineTemplates(t),
+			Intents:   mocks.BaselineIntents(t),
+			GasLimit:  DefaultGasLimit,
+		},
 		validate: mocks.BaselineValidator(t),
 		generate: mocks.BaselineGenerator(t),
 		invoke:   mocks.BaselineInvoker(t),
@@ -62,3 +69,15 @@ func WithSubmitter(submitter Submitter) func(*Transactor) {
 		transactor.submit = submitter
 	}
 }
+
+func WithTemplateRegistry(templates Templates) func(*Transactor) {
+	return func(transactor *Transactor) {
+		transactor.cfg.Templates = templates
+	}
+}
+
+func WithIntentRegistry(intents Intents) func(*Transactor) {
+	return func(transactor *Transactor) {
+		transactor.cfg.Intents = intents
+	}
+}
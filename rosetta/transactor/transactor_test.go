@@ -15,9 +15,13 @@
 package transactor_test
 
 import (
+	"bytes"
+	"encoding/asn1"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 
@@ -25,13 +29,20 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/cadence"
+	cjson "github.com/onflow/cadence/encoding/json"
 	sdk "github.com/onflow/flow-go-sdk"
+	sdkcrypto "github.com/onflow/flow-go-sdk/crypto"
+	chash "github.com/onflow/flow-go/crypto/hash"
 	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
 	"github.com/optakt/flow-rosetta/rosetta/failure"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/intents"
 	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/templates"
 	"github.com/optakt/flow-rosetta/rosetta/transactor"
 	"github.com/optakt/flow-rosetta/testing/mocks"
 )
@@ -43,7 +54,7 @@ func TestTransactor_DeriveIntent(t *testing.T) {
 		tr := transactor.BaselineTransactor(t)
 
 		want := mocks.GenericOperations(2)
-		got, err := tr.DeriveIntent(want)
+		got, err := tr.DeriveIntent(want, nil)
 
 		require.NoError(t, err)
 		assert.Equal(t, want[1].Amount.Value, fmt.Sprint(uint64(got.Amount)))
@@ -53,6 +64,110 @@ func TestTransactor_DeriveIntent(t *testing.T) {
 		assert.Equal(t, want[0].AccountID.Address, got.Proposer.String())
 	})
 
+	t.Run("nominal case with distinct payer and proposer", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+			return flow.HexToAddress(rosAccountID.Address), nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		payer := mocks.GenericAccountID(2)
+		proposer := mocks.GenericAccountID(3)
+		roles := object.Roles{
+			PayerID:    &payer,
+			ProposerID: &proposer,
+		}
+
+		ops := mocks.GenericOperations(2)
+		got, err := tr.DeriveIntent(ops, &roles)
+
+		require.NoError(t, err)
+		assert.Equal(t, ops[0].AccountID.Address, got.From.String())
+		assert.Equal(t, payer.Address, got.Payer.String())
+		assert.Equal(t, proposer.Address, got.Proposer.String())
+		assert.Equal(t, 0, got.KeyIndex)
+	})
+
+	t.Run("nominal case with proposer key index", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		roles := object.Roles{
+			ProposerKeyIndex: 2,
+		}
+
+		got, err := tr.DeriveIntent(mocks.GenericOperations(2), &roles)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, got.KeyIndex)
+	})
+
+	t.Run("handles negative proposer key index", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		roles := object.Roles{
+			ProposerKeyIndex: -1,
+		}
+
+		_, err := tr.DeriveIntent(mocks.GenericOperations(2), &roles)
+
+		assert.ErrorAs(t, err, &failure.InvalidProposer{})
+	})
+
+	t.Run("handles invalid payer account", func(t *testing.T) {
+		t.Parallel()
+
+		payer := mocks.GenericAccountID(2)
+
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+			if rosAccountID == payer {
+				return flow.EmptyAddress, mocks.GenericError
+			}
+			return flow.HexToAddress(rosAccountID.Address), nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		roles := object.Roles{
+			PayerID: &payer,
+		}
+
+		_, err := tr.DeriveIntent(mocks.GenericOperations(2), &roles)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid proposer account", func(t *testing.T) {
+		t.Parallel()
+
+		proposer := mocks.GenericAccountID(3)
+
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+			if rosAccountID == proposer {
+				return flow.EmptyAddress, mocks.GenericError
+			}
+			return flow.HexToAddress(rosAccountID.Address), nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		roles := object.Roles{
+			ProposerID: &proposer,
+		}
+
+		_, err := tr.DeriveIntent(mocks.GenericOperations(2), &roles)
+
+		assert.Error(t, err)
+	})
+
 	t.Run("handles invalid currency", func(t *testing.T) {
 		t.Parallel()
 
@@ -63,7 +178,7 @@ func TestTransactor_DeriveIntent(t *testing.T) {
 
 		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
 
-		_, err := tr.DeriveIntent(mocks.GenericOperations(2))
+		_, err := tr.DeriveIntent(mocks.GenericOperations(2), nil)
 
 		assert.Error(t, err)
 	})
@@ -78,7 +193,7 @@ func TestTransactor_DeriveIntent(t *testing.T) {
 
 		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
 
-		_, err := tr.DeriveIntent(mocks.GenericOperations(2))
+		_, err := tr.DeriveIntent(mocks.GenericOperations(2), nil)
 
 		assert.Error(t, err)
 	})
@@ -90,7 +205,7 @@ func TestTransactor_DeriveIntent(t *testing.T) {
 
 		op := mocks.GenericOperations(3)
 
-		_, err := tr.DeriveIntent(op)
+		_, err := tr.DeriveIntent(op, nil)
 
 		assert.Error(t, err)
 		assert.ErrorAs(t, err, &failure.InvalidOperations{})
@@ -105,12 +220,29 @@ func TestTransactor_DeriveIntent(t *testing.T) {
 		op[0].Amount.Value = "42"
 		op[1].Amount.Value = "84"
 
-		_, err := tr.DeriveIntent(op)
+		_, err := tr.DeriveIntent(op, nil)
 
 		assert.Error(t, err)
 		assert.ErrorAs(t, err, &failure.InvalidIntent{})
 	})
 
+	t.Run("handles operations with amounts more precise than the currency", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		op := mocks.GenericOperations(2)
+		op[0].Amount.Value = "-4.2"
+		op[1].Amount.Value = "4.2"
+
+		_, err := tr.DeriveIntent(op, nil)
+
+		require.Error(t, err)
+		var intentErr failure.InvalidIntent
+		require.ErrorAs(t, err, &intentErr)
+		assert.Contains(t, intentErr.Description.Fields.String(), "decimals: 8")
+	})
+
 	t.Run("handles operations with non-matching amounts", func(t *testing.T) {
 		t.Parallel()
 
@@ -120,7 +252,7 @@ func TestTransactor_DeriveIntent(t *testing.T) {
 		op[0].Amount.Value = "42"
 		op[1].Amount.Value = "84"
 
-		_, err := tr.DeriveIntent(op)
+		_, err := tr.DeriveIntent(op, nil)
 
 		assert.Error(t, err)
 		assert.ErrorAs(t, err, &failure.InvalidIntent{})
@@ -136,7 +268,7 @@ func TestTransactor_DeriveIntent(t *testing.T) {
 
 		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
 
-		_, err := tr.DeriveIntent(mocks.GenericOperations(2))
+		_, err := tr.DeriveIntent(mocks.GenericOperations(2), nil)
 
 		assert.Error(t, err)
 		assert.ErrorAs(t, err, &failure.InvalidIntent{})
@@ -150,315 +282,1951 @@ func TestTransactor_DeriveIntent(t *testing.T) {
 		op := mocks.GenericOperations(2)
 		op[0].Type = "irrelevant_type"
 
-		_, err := tr.DeriveIntent(op)
+		_, err := tr.DeriveIntent(op, nil)
 
 		assert.Error(t, err)
 		assert.ErrorAs(t, err, &failure.InvalidIntent{})
 	})
-}
-
-func TestTransactor_CompileTransaction(t *testing.T) {
-	rosBlockID := mocks.GenericRosBlockID
-	sequence := uint64(42)
 
-	sender := mocks.GenericAddress(0)
-	receiver := mocks.GenericAddress(1)
-	amount, err := cadence.NewUFix64("100.00000000")
-	require.NoError(t, err)
+	t.Run("nominal case with template operation", func(t *testing.T) {
+		t.Parallel()
 
-	intent := &transactor.Intent{
-		From:     sender,
-		To:       receiver,
-		Amount:   amount,
-		Payer:    sender,
-		Proposer: sender,
-	}
+		tr := transactor.BaselineTransactor(t)
 
-	t.Run("nominal case", func(t *testing.T) {
-		t.Parallel()
+		op := mocks.GenericTemplateOperation()
+		got, err := tr.DeriveIntent([]object.Operation{op}, nil)
 
-		wantCompiled := `eyJTY3JpcHQiOiJkR1Z6ZEE9PSIsIkFyZ3VtZW50cyI6WyJleUowZVhCbElqb2lWVVpwZURZMElpd2lkbUZzZFdVaU9pSXhNREF1TURBd01EQXdNREFpZlFvPSIsImV5SjBlWEJsSWpvaVFXUmtjbVZ6Y3lJc0luWmhiSFZsSWpvaU1IaGpNamd5WlRJeVl6bGlNbVExTTJObUluMEsiXSwiUmVmZXJlbmNlQmxvY2tJRCI6WzcsNDAsMjgsMTUyLDIyOSwxNCwxMzMsNzgsOCwxOCwyNTIsMTI1LDExNywyMjAsMjIwLDY2LDE3NCwxNjIsMTUxLDcxLDEyNSw4OSw5MCw0MSwyMDIsMTE1LDY5LDIxOCwyMDIsMzYsMTQzLDU0XSwiR2FzTGltaXQiOjk5OTksIlByb3Bvc2FsS2V5Ijp7IkFkZHJlc3MiOiJlNmU0NjMyYWUwMTMwOWMwIiwiS2V5SW5kZXgiOjAsIlNlcXVlbmNlTnVtYmVyIjo0Mn0sIlBheWVyIjoiZTZlNDYzMmFlMDEzMDljMCIsIkF1dGhvcml6ZXJzIjpbImU2ZTQ2MzJhZTAxMzA5YzAiXSwiUGF5bG9hZFNpZ25hdHVyZXMiOm51bGwsIkVudmVsb3BlU2lnbmF0dXJlcyI6bnVsbH0=`
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericTemplate.Name, got.Template)
+		assert.Equal(t, op.AccountID.Address, got.From.String())
+		assert.Equal(t, op.AccountID.Address, got.Payer.String())
+		assert.Equal(t, op.AccountID.Address, got.Proposer.String())
+		require.Len(t, got.Arguments, 2)
+		assert.Equal(t, cadence.NewAddress(mocks.GenericAddress(1)), got.Arguments[0])
+		assert.Equal(t, cadence.NewUInt64(mocks.GenericHeight), got.Arguments[1])
+	})
 
-		generator := mocks.BaselineGenerator(t)
-		generator.TransferTokensFunc = func(symbol string) ([]byte, error) {
-			assert.Equal(t, dps.FlowSymbol, symbol)
+	t.Run("handles template operation without template", func(t *testing.T) {
+		t.Parallel()
 
-			return mocks.GenericBytes, nil
-		}
+		tr := transactor.BaselineTransactor(t)
 
-		tr := transactor.BaselineTransactor(t, transactor.WithGenerator(generator))
+		op := mocks.GenericTemplateOperation()
+		op.Metadata = nil
 
-		got, err := tr.CompileTransaction(rosBlockID, intent, sequence)
+		_, err := tr.DeriveIntent([]object.Operation{op}, nil)
 
-		require.NoError(t, err)
-		assert.Equal(t, wantCompiled, got)
+		assert.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidIntent{})
 	})
 
-	t.Run("handles generator failure on TransferTokens", func(t *testing.T) {
+	t.Run("handles template which is not allowlisted", func(t *testing.T) {
 		t.Parallel()
 
-		generator := mocks.BaselineGenerator(t)
-		generator.TransferTokensFunc = func(string) ([]byte, error) {
-			return nil, mocks.GenericError
+		registry := mocks.BaselineTemplates(t)
+		registry.TemplateFunc = func(string) (templates.Template, bool) {
+			return templates.Template{}, false
 		}
 
-		tr := transactor.BaselineTransactor(t, transactor.WithGenerator(generator))
+		tr := transactor.BaselineTransactor(t, transactor.WithTemplateRegistry(registry))
 
-		_, err := tr.CompileTransaction(rosBlockID, intent, sequence)
+		_, err := tr.DeriveIntent([]object.Operation{mocks.GenericTemplateOperation()}, nil)
 
 		assert.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidIntent{})
 	})
-}
 
-func TestTransactor_HashPayload(t *testing.T) {
-	header := mocks.GenericHeader
-	rosBlockID := mocks.GenericRosBlockID
-	signer := mocks.GenericAccountID(0)
-	signerAddr := mocks.GenericAddress(0)
-	tx := &sdk.Transaction{
-		ProposalKey: sdk.ProposalKey{SequenceNumber: 42},
-	}
+	t.Run("handles invalid template arguments", func(t *testing.T) {
+		t.Parallel()
 
-	key, err := generateKey()
-	require.NoError(t, err)
+		tr := transactor.BaselineTransactor(t)
 
-	// We need to specify a weight of 1000 because otherwise multiple public keys would be required, as
-	// the total required weight for an account's signature to be considered valid has to be equal to 1000.
-	// For some reason this 1000 magic number is not exposed anywhere that I could find in Flow.
-	pubKey := key.PublicKey(1000)
+		op := mocks.GenericTemplateOperation()
+		op.Metadata.Arguments["id"] = "not a number"
 
-	data, err := json.Marshal(tx)
-	require.NoError(t, err)
+		_, err := tr.DeriveIntent([]object.Operation{op}, nil)
 
-	payload := base64.StdEncoding.EncodeToString(data)
+		assert.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidIntent{})
+	})
 
-	t.Run("nominal case", func(t *testing.T) {
+	t.Run("handles invalid template account", func(t *testing.T) {
 		t.Parallel()
 
 		validator := mocks.BaselineValidator(t)
-		validator.BlockFunc = func(gotBlockID identifier.Block) (uint64, flow.Identifier, error) {
-			assert.Equal(t, rosBlockID, gotBlockID)
-
-			return header.Height, header.ID(), nil
+		validator.AccountFunc = func(identifier.Account) (flow.Address, error) {
+			return flow.EmptyAddress, mocks.GenericError
 		}
-		validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
-			assert.Equal(t, signer, rosAccountID)
 
-			return signerAddr, nil
-		}
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
 
-		invoker := mocks.BaselineInvoker(t)
-		invoker.KeyFunc = func(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
-			assert.Equal(t, mocks.GenericHeight, height)
-			assert.Equal(t, signerAddr, address)
-			assert.Zero(t, index)
+		_, err := tr.DeriveIntent([]object.Operation{mocks.GenericTemplateOperation()}, nil)
 
-			return &pubKey, nil
+		assert.Error(t, err)
+	})
+
+	key, err := generateKey()
+	require.NoError(t, err)
+	publicKey := strings.TrimPrefix(key.PublicKey(1000).PublicKey.String(), "0x")
+	keyIndex := 1
+	negativeIndex := -1
+
+	keyAdd := func() object.Operation {
+		return object.Operation{
+			ID:        identifier.Operation{Index: 0},
+			AccountID: mocks.GenericAccountID(0),
+			Type:      configuration.OperationKeyAdd,
+			Metadata: &object.OperationMetadata{
+				PublicKey:          publicKey,
+				SignatureAlgorithm: "ECDSA_P256",
+				HashAlgorithm:      "SHA3_256",
+				Weight:             1000,
+			},
 		}
+	}
 
-		tr := transactor.BaselineTransactor(
-			t,
-			transactor.WithValidator(validator),
-			transactor.WithInvoker(invoker),
-		)
+	t.Run("nominal case with key addition", func(t *testing.T) {
+		t.Parallel()
 
-		algorithm, hash, err := tr.HashPayload(rosBlockID, payload, signer)
+		tr := transactor.BaselineTransactor(t)
+
+		got, err := tr.DeriveIntent([]object.Operation{keyAdd()}, nil)
 
 		require.NoError(t, err)
-		assert.Equal(t, "ecdsa", algorithm)
-		assert.Equal(t, "3395952d355d9a5e9b5ba6f46f155cca9ec7615deef5ce1146926cb4abbf5cbb", hash)
+		assert.Equal(t, configuration.OperationKeyAdd, got.KeyOperation)
+		assert.Equal(t, mocks.GenericAddress(0), got.From)
+		require.Len(t, got.Arguments, 4)
+		assert.Equal(t, publicKey, got.Arguments[0].ToGoValue())
+		assert.Equal(t, cadence.NewUInt8(1), got.Arguments[1])
+		assert.Equal(t, cadence.NewUInt8(3), got.Arguments[2])
+		assert.Equal(t, cadence.UFix64(1000_00000000), got.Arguments[3])
 	})
 
-	t.Run("handles non-base64-encoded transaction payload", func(t *testing.T) {
+	t.Run("nominal case with key revocation", func(t *testing.T) {
 		t.Parallel()
 
 		tr := transactor.BaselineTransactor(t)
 
-		data, err := json.Marshal(tx)
-		require.NoError(t, err)
+		op := object.Operation{
+			AccountID: mocks.GenericAccountID(0),
+			Type:      configuration.OperationKeyRevoke,
+			Metadata:  &object.OperationMetadata{KeyIndex: &keyIndex},
+		}
 
-		_, _, err = tr.HashPayload(rosBlockID, string(data), signer)
+		got, err := tr.DeriveIntent([]object.Operation{op}, nil)
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+		require.NoError(t, err)
+		assert.Equal(t, configuration.OperationKeyRevoke, got.KeyOperation)
+		assert.Equal(t, []cadence.Value{cadence.NewInt(keyIndex)}, got.Arguments)
 	})
 
-	t.Run("handles non-json-encoded transaction payload", func(t *testing.T) {
+	t.Run("handles key operation without metadata", func(t *testing.T) {
 		t.Parallel()
 
 		tr := transactor.BaselineTransactor(t)
 
-		invalidPayload := base64.StdEncoding.EncodeToString(mocks.GenericBytes)
+		op := keyAdd()
+		op.Metadata = nil
 
-		_, _, err = tr.HashPayload(rosBlockID, invalidPayload, signer)
+		_, err := tr.DeriveIntent([]object.Operation{op}, nil)
 
 		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+		assert.ErrorAs(t, err, &failure.InvalidIntent{})
 	})
 
-	t.Run("handles invalid block", func(t *testing.T) {
+	t.Run("handles key revocation with invalid key index", func(t *testing.T) {
 		t.Parallel()
 
-		validator := mocks.BaselineValidator(t)
-		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
-			return 0, flow.ZeroID, mocks.GenericError
-		}
+		tr := transactor.BaselineTransactor(t)
 
-		tr := transactor.BaselineTransactor(
-			t,
-			transactor.WithValidator(validator),
-		)
+		for _, index := range []*int{nil, &negativeIndex} {
+			op := object.Operation{
+				AccountID: mocks.GenericAccountID(0),
+				Type:      configuration.OperationKeyRevoke,
+				Metadata:  &object.OperationMetadata{KeyIndex: index},
+			}
 
-		_, _, err := tr.HashPayload(rosBlockID, payload, signer)
+			_, err := tr.DeriveIntent([]object.Operation{op}, nil)
 
-		assert.Error(t, err)
+			require.Error(t, err)
+			assert.ErrorAs(t, err, &failure.InvalidIntent{})
+		}
 	})
 
-	t.Run("handles invalid account", func(t *testing.T) {
+	t.Run("handles unsupported key signature algorithm", func(t *testing.T) {
 		t.Parallel()
 
-		validator := mocks.BaselineValidator(t)
-		validator.AccountFunc = func(identifier.Account) (flow.Address, error) {
-			return flow.EmptyAddress, mocks.GenericError
-		}
+		tr := transactor.BaselineTransactor(t)
 
-		tr := transactor.BaselineTransactor(
-			t,
-			transactor.WithValidator(validator),
-		)
+		op := keyAdd()
+		op.Metadata.SignatureAlgorithm = "BLS_BLS12_381"
 
-		_, _, err := tr.HashPayload(rosBlockID, payload, signer)
+		_, err := tr.DeriveIntent([]object.Operation{op}, nil)
 
-		assert.Error(t, err)
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidIntent{})
 	})
 
-	t.Run("handles invoker failure on Key", func(t *testing.T) {
+	t.Run("handles unsupported key hashing algorithm", func(t *testing.T) {
 		t.Parallel()
 
-		invoker := mocks.BaselineInvoker(t)
-		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
-			return nil, mocks.GenericError
-		}
+		tr := transactor.BaselineTransactor(t)
 
-		tr := transactor.BaselineTransactor(
-			t,
-			transactor.WithInvoker(invoker),
-		)
+		op := keyAdd()
+		op.Metadata.HashAlgorithm = "SHA3_384"
 
-		_, _, err := tr.HashPayload(rosBlockID, payload, signer)
+		_, err := tr.DeriveIntent([]object.Operation{op}, nil)
 
 		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidKey{})
+		assert.ErrorAs(t, err, &failure.InvalidIntent{})
 	})
-}
-
-func TestTransactor_Parse(t *testing.T) {
-	tx := &sdk.Transaction{
-		ProposalKey: sdk.ProposalKey{SequenceNumber: 42},
-	}
 
-	t.Run("nominal case", func(t *testing.T) {
+	t.Run("handles invalid public key", func(t *testing.T) {
 		t.Parallel()
 
-		data, err := json.Marshal(tx)
-		require.NoError(t, err)
-
-		encodedData := base64.StdEncoding.EncodeToString(data)
-
 		tr := transactor.BaselineTransactor(t)
 
-		got, err := tr.Parse(encodedData)
+		op := keyAdd()
+		op.Metadata.PublicKey = hex.EncodeToString(mocks.GenericBytes)
 
-		require.NoError(t, err)
-		assert.Equal(t, tx.ProposalKey.SequenceNumber, got.Sequence())
+		_, err := tr.DeriveIntent([]object.Operation{op}, nil)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidIntent{})
 	})
 
-	t.Run("handles missing base64 encoding", func(t *testing.T) {
+	t.Run("handles invalid key weight", func(t *testing.T) {
 		t.Parallel()
 
-		data, err := json.Marshal(tx)
-		require.NoError(t, err)
-
 		tr := transactor.BaselineTransactor(t)
 
-		_, err = tr.Parse(string(data))
+		op := keyAdd()
+		op.Metadata.Weight = 1001
 
-		assert.Error(t, err)
+		_, err := tr.DeriveIntent([]object.Operation{op}, nil)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidIntent{})
 	})
 
-	t.Run("handles non-JSON-encoded payloads", func(t *testing.T) {
+	t.Run("handles invalid key account", func(t *testing.T) {
 		t.Parallel()
 
-		payload := mocks.GenericBytes
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(identifier.Account) (flow.Address, error) {
+			return flow.EmptyAddress, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		_, err := tr.DeriveIntent([]object.Operation{keyAdd()}, nil)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTransactor_CompileTransaction(t *testing.T) {
+	rosBlockID := mocks.GenericRosBlockID
+	sequence := uint64(42)
+
+	sender := mocks.GenericAddress(0)
+	receiver := mocks.GenericAddress(1)
+	amount, err := cadence.NewUFix64("100.00000000")
+	require.NoError(t, err)
+
+	intent := &transactor.Intent{
+		From:     sender,
+		To:       receiver,
+		Amount:   amount,
+		Payer:    sender,
+		Proposer: sender,
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		wantCompiled := `eyJTY3JpcHQiOiJkR1Z6ZEE9PSIsIkFyZ3VtZW50cyI6WyJleUowZVhCbElqb2lWVVpwZURZMElpd2lkbUZzZFdVaU9pSXhNREF1TURBd01EQXdNREFpZlFvPSIsImV5SjBlWEJsSWpvaVFXUmtjbVZ6Y3lJc0luWmhiSFZsSWpvaU1IaGpNamd5WlRJeVl6bGlNbVExTTJObUluMEsiXSwiUmVmZXJlbmNlQmxvY2tJRCI6WzcsNDAsMjgsMTUyLDIyOSwxNCwxMzMsNzgsOCwxOCwyNTIsMTI1LDExNywyMjAsMjIwLDY2LDE3NCwxNjIsMTUxLDcxLDEyNSw4OSw5MCw0MSwyMDIsMTE1LDY5LDIxOCwyMDIsMzYsMTQzLDU0XSwiR2FzTGltaXQiOjEwMDAsIlByb3Bvc2FsS2V5Ijp7IkFkZHJlc3MiOiJlNmU0NjMyYWUwMTMwOWMwIiwiS2V5SW5kZXgiOjAsIlNlcXVlbmNlTnVtYmVyIjo0Mn0sIlBheWVyIjoiZTZlNDYzMmFlMDEzMDljMCIsIkF1dGhvcml6ZXJzIjpbImU2ZTQ2MzJhZTAxMzA5YzAiXSwiUGF5bG9hZFNpZ25hdHVyZXMiOm51bGwsIkVudmVsb3BlU2lnbmF0dXJlcyI6bnVsbH0=`
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TransferTokensFunc = func(symbol string) ([]byte, error) {
+			assert.Equal(t, dps.FlowSymbol, symbol)
+
+			return mocks.GenericBytes, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithGenerator(generator))
+
+		got, err := tr.CompileTransaction(rosBlockID, intent, sequence)
+
+		require.NoError(t, err)
+		assert.Equal(t, wantCompiled, got)
+	})
+
+	t.Run("nominal case with gas limit", func(t *testing.T) {
+		t.Parallel()
+
+		intent := *intent
+		intent.GasLimit = 1000
+
+		tr := transactor.BaselineTransactor(t)
+
+		got, err := tr.CompileTransaction(rosBlockID, &intent, sequence)
+		require.NoError(t, err)
+
+		data, err := base64.StdEncoding.DecodeString(got)
+		require.NoError(t, err)
+		var tx sdk.Transaction
+		err = json.Unmarshal(data, &tx)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(1000), tx.GasLimit)
+	})
+
+	t.Run("nominal case with proposer key index", func(t *testing.T) {
+		t.Parallel()
+
+		intent := *intent
+		intent.KeyIndex = 2
+
+		tr := transactor.BaselineTransactor(t)
+
+		got, err := tr.CompileTransaction(rosBlockID, &intent, sequence)
+		require.NoError(t, err)
+
+		data, err := base64.StdEncoding.DecodeString(got)
+		require.NoError(t, err)
+		var tx sdk.Transaction
+		err = json.Unmarshal(data, &tx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, tx.ProposalKey.KeyIndex)
+		assert.Equal(t, sequence, tx.ProposalKey.SequenceNumber)
+	})
+
+	t.Run("handles gas limit above the maximum", func(t *testing.T) {
+		t.Parallel()
+
+		intent := *intent
+		intent.GasLimit = flow.DefaultMaxTransactionGasLimit + 1
+
+		tr := transactor.BaselineTransactor(t)
+
+		got, err := tr.CompileTransaction(rosBlockID, &intent, sequence)
+		require.NoError(t, err)
+
+		data, err := base64.StdEncoding.DecodeString(got)
+		require.NoError(t, err)
+		var tx sdk.Transaction
+		err = json.Unmarshal(data, &tx)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(flow.DefaultMaxTransactionGasLimit), tx.GasLimit)
+	})
+
+	t.Run("handles generator failure on TransferTokens", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.TransferTokensFunc = func(string) ([]byte, error) {
+			return nil, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithGenerator(generator))
+
+		_, err := tr.CompileTransaction(rosBlockID, intent, sequence)
+
+		assert.Error(t, err)
+	})
+
+	templateIntent := &transactor.Intent{
+		From:      sender,
+		Payer:     sender,
+		Proposer:  sender,
+		Template:  mocks.GenericTemplate.Name,
+		Arguments: []cadence.Value{cadence.NewAddress(receiver), cadence.NewUInt64(mocks.GenericHeight)},
+	}
+
+	t.Run("nominal case with template intent", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		got, err := tr.CompileTransaction(rosBlockID, templateIntent, sequence)
+		require.NoError(t, err)
+
+		data, err := base64.StdEncoding.DecodeString(got)
+		require.NoError(t, err)
+		var tx sdk.Transaction
+		require.NoError(t, json.Unmarshal(data, &tx))
+
+		assert.Equal(t, mocks.GenericTemplate.Script, tx.Script)
+		assert.Len(t, tx.Arguments, 2)
+		assert.Equal(t, sdk.Address(sender), tx.Authorizers[0])
+	})
+
+	t.Run("handles template which is not allowlisted", func(t *testing.T) {
+		t.Parallel()
+
+		registry := mocks.BaselineTemplates(t)
+		registry.TemplateFunc = func(string) (templates.Template, bool) {
+			return templates.Template{}, false
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithTemplateRegistry(registry))
+
+		_, err := tr.CompileTransaction(rosBlockID, templateIntent, sequence)
+
+		assert.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidIntent{})
+	})
+
+	keyIntent := &transactor.Intent{
+		From:         sender,
+		Payer:        sender,
+		Proposer:     sender,
+		KeyOperation: configuration.OperationKeyRevoke,
+		Arguments:    []cadence.Value{cadence.NewInt(1)},
+	}
+
+	t.Run("nominal case with key intent", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		got, err := tr.CompileTransaction(rosBlockID, keyIntent, sequence)
+		require.NoError(t, err)
+
+		data, err := base64.StdEncoding.DecodeString(got)
+		require.NoError(t, err)
+		var tx sdk.Transaction
+		require.NoError(t, json.Unmarshal(data, &tx))
+
+		assert.Equal(t, mocks.GenericRevokeKeyScript, tx.Script)
+		assert.Len(t, tx.Arguments, 1)
+		assert.Equal(t, sdk.Address(sender), tx.Authorizers[0])
+	})
+
+	t.Run("handles key script generation failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.RevokeAccountKeyFunc = func() ([]byte, error) {
+			return nil, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithGenerator(generator))
+
+		_, err := tr.CompileTransaction(rosBlockID, keyIntent, sequence)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTransactor_SuggestFee(t *testing.T) {
+	rosBlockID := mocks.GenericRosBlockID
+	maxLimit := uint64(flow.DefaultMaxTransactionGasLimit)
+	transactionFee := uint64(1000)
+
+	validator := mocks.BaselineValidator(t)
+	validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+		return mocks.GenericHeight, mocks.GenericHeader.ID(), nil
+	}
+
+	invoker := mocks.BaselineInvoker(t)
+	invoker.ScriptFunc = func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+		return cadence.UFix64(transactionFee), nil
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.GetTransactionFeeFunc = func() ([]byte, error) {
+			return mocks.GenericBytes, nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+			assert.Equal(t, mocks.GenericHeight, height)
+			assert.Equal(t, mocks.GenericBytes, script)
+			assert.Empty(t, parameters)
+
+			return cadence.UFix64(transactionFee), nil
+		}
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithValidator(validator),
+			transactor.WithGenerator(generator),
+			transactor.WithInvoker(invoker),
+		)
+
+		limit, fee, err := tr.SuggestFee(rosBlockID, 0, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint64(transactor.DefaultGasLimit), limit)
+		assert.Equal(t, transactionFee, fee)
+	})
+
+	t.Run("nominal case with multiplier", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithValidator(validator),
+			transactor.WithInvoker(invoker),
+		)
+
+		limit, fee, err := tr.SuggestFee(rosBlockID, 2.5, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2500), limit)
+		assert.Equal(t, transactionFee, fee)
+	})
+
+	t.Run("nominal case with multiplier above the maximum gas limit", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithValidator(validator),
+			transactor.WithInvoker(invoker),
+		)
+
+		limit, fee, err := tr.SuggestFee(rosBlockID, 20, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, maxLimit, limit)
+		assert.Equal(t, transactionFee, fee)
+	})
+
+	t.Run("nominal case with max fee covering the fee", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithValidator(validator),
+			transactor.WithInvoker(invoker),
+		)
+
+		limit, fee, err := tr.SuggestFee(rosBlockID, 0, transactionFee)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint64(transactor.DefaultGasLimit), limit)
+		assert.Equal(t, transactionFee, fee)
+	})
+
+	t.Run("handles max fee below the fee", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithValidator(validator),
+			transactor.WithInvoker(invoker),
+		)
+
+		_, _, err := tr.SuggestFee(rosBlockID, 0, transactionFee-1)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InsufficientFee{})
+	})
+
+	t.Run("handles invalid block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		_, _, err := tr.SuggestFee(rosBlockID, 0, 0)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+
+	t.Run("handles generator failure", func(t *testing.T) {
+		t.Parallel()
+
+		generator := mocks.BaselineGenerator(t)
+		generator.GetTransactionFeeFunc = func() ([]byte, error) {
+			return nil, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithValidator(validator),
+			transactor.WithGenerator(generator),
+		)
+
+		_, _, err := tr.SuggestFee(rosBlockID, 0, 0)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+
+	t.Run("handles invoker failure", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			return nil, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithValidator(validator),
+			transactor.WithInvoker(invoker),
+		)
+
+		_, _, err := tr.SuggestFee(rosBlockID, 0, 0)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+
+	t.Run("handles invalid script result", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			return cadence.NewBool(true), nil
+		}
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithValidator(validator),
+			transactor.WithInvoker(invoker),
+		)
+
+		_, _, err := tr.SuggestFee(rosBlockID, 0, 0)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTransactor_HashPayload(t *testing.T) {
+	header := mocks.GenericHeader
+	rosBlockID := mocks.GenericRosBlockID
+	signer := mocks.GenericAccountID(0)
+	signerAddr := mocks.GenericAddress(0)
+	tx := &sdk.Transaction{
+		ProposalKey: sdk.ProposalKey{SequenceNumber: 42},
+	}
+
+	key, err := generateKey()
+	require.NoError(t, err)
+
+	// We need to specify a weight of 1000 because otherwise multiple public keys would be required, as
+	// the total required weight for an account's signature to be considered valid has to be equal to 1000.
+	// For some reason this 1000 magic number is not exposed anywhere that I could find in Flow.
+	pubKey := key.PublicKey(1000)
+
+	data, err := json.Marshal(tx)
+	require.NoError(t, err)
+
+	payload := base64.StdEncoding.EncodeToString(data)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(gotBlockID identifier.Block) (uint64, flow.Identifier, error) {
+			assert.Equal(t, rosBlockID, gotBlockID)
+
+			return header.Height, header.ID(), nil
+		}
+		validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+			assert.Equal(t, signer, rosAccountID)
+
+			return signerAddr, nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
+			assert.Equal(t, mocks.GenericHeight, height)
+			assert.Equal(t, signerAddr, address)
+			assert.Zero(t, index)
+
+			return &pubKey, nil
+		}
+
+		tr := transactor.BaselineTransactor(
+			t,
+			transactor.WithValidator(validator),
+			transactor.WithInvoker(invoker),
+		)
+
+		algorithm, hash, err := tr.HashPayload(rosBlockID, payload, signer)
+
+		require.NoError(t, err)
+		assert.Equal(t, "ecdsa", algorithm)
+		assert.Equal(t, "3395952d355d9a5e9b5ba6f46f155cca9ec7615deef5ce1146926cb4abbf5cbb", hash)
+	})
+
+	t.Run("nominal case with pending payload signer", func(t *testing.T) {
+		t.Parallel()
+
+		// The signer authorizes the transaction, but does not pay for it, so it
+		// needs to sign the payload instead of the envelope.
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sdk.Address(signerAddr)},
+			Payer:       sdk.HexToAddress(mocks.GenericAddress(1).Hex()),
+			ProposalKey: sdk.ProposalKey{Address: sdk.Address(signerAddr), SequenceNumber: 42},
+		}
+
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		payload := base64.StdEncoding.EncodeToString(data)
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return &pubKey, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		_, hash, err := tr.HashPayload(rosBlockID, payload, signer)
+
+		message := append(flow.TransactionDomainTag[:], tx.PayloadMessage()...)
+		want := hex.EncodeToString(chash.NewSHA3_256().ComputeHash(message))
+
+		require.NoError(t, err)
+		assert.Equal(t, want, hash)
+	})
+
+	t.Run("handles non-base64-encoded transaction payload", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		_, _, err = tr.HashPayload(rosBlockID, string(data), signer)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+	})
+
+	t.Run("handles non-json-encoded transaction payload", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		invalidPayload := base64.StdEncoding.EncodeToString(mocks.GenericBytes)
+
+		_, _, err = tr.HashPayload(rosBlockID, invalidPayload, signer)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+	})
+
+	t.Run("handles invalid block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(
+			t,
+			transactor.WithValidator(validator),
+		)
+
+		_, _, err := tr.HashPayload(rosBlockID, payload, signer)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid account", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.AccountFunc = func(identifier.Account) (flow.Address, error) {
+			return flow.EmptyAddress, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(
+			t,
+			transactor.WithValidator(validator),
+		)
+
+		_, _, err := tr.HashPayload(rosBlockID, payload, signer)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invoker failure on Key", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return nil, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(
+			t,
+			transactor.WithInvoker(invoker),
+		)
+
+		_, _, err := tr.HashPayload(rosBlockID, payload, signer)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidKey{})
+	})
+
+	t.Run("nominal case with secp256k1 and SHA2 key", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			key := pubKey
+			key.SignAlgo = sdkcrypto.ECDSA_secp256k1
+			key.HashAlgo = sdkcrypto.SHA2_256
+			return &key, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		algorithm, hash, err := tr.HashPayload(rosBlockID, payload, signer)
+
+		message := append(flow.TransactionDomainTag[:], tx.EnvelopeMessage()...)
+		want := hex.EncodeToString(chash.NewSHA2_256().ComputeHash(message))
+
+		require.NoError(t, err)
+		assert.Equal(t, "ecdsa", algorithm)
+		assert.Equal(t, want, hash)
+	})
+
+	t.Run("handles unsupported key signature algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			key := pubKey
+			key.SignAlgo = sdkcrypto.UnknownSignatureAlgorithm
+			return &key, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		_, _, err := tr.HashPayload(rosBlockID, payload, signer)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidKey{})
+	})
+
+	t.Run("handles unsupported key hashing algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			key := pubKey
+			key.HashAlgo = sdkcrypto.SHA3_384
+			return &key, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		_, _, err := tr.HashPayload(rosBlockID, payload, signer)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidKey{})
+	})
+}
+
+func TestTransactor_SigningPayloads(t *testing.T) {
+	sender := sdk.HexToAddress(mocks.GenericAddress(0).Hex())
+	payer := sdk.HexToAddress(mocks.GenericAddress(1).Hex())
+
+	validator := mocks.BaselineValidator(t)
+	validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+		return flow.HexToAddress(rosAccountID.Address), nil
+	}
+
+	encode := func(t *testing.T, tx *sdk.Transaction) string {
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
+	t.Run("nominal case with sender as payer", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sender},
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		got, err := tr.SigningPayloads(encode(t, tx))
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, sender.Hex(), got[0].AccountID.Address)
+		assert.Equal(t, "ecdsa", got[0].SignatureType)
+		assert.NotEmpty(t, got[0].HexBytes)
+	})
+
+	t.Run("nominal case with distinct payer", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sender},
+			Payer:       payer,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		got, err := tr.SigningPayloads(encode(t, tx))
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, sender.Hex(), got[0].AccountID.Address)
+	})
+
+	t.Run("nominal case with proposal key of proposer", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sender},
+			Payer:       payer,
+			ProposalKey: sdk.ProposalKey{Address: sender, KeyIndex: 2},
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(_ uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
+			assert.Equal(t, flow.Address(sender), address)
+			assert.Equal(t, 2, index)
+			return &mocks.GenericAccount.Keys[0], nil
+		}
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithValidator(validator),
+			transactor.WithInvoker(invoker),
+		)
+
+		got, err := tr.SigningPayloads(encode(t, tx))
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, sender.Hex(), got[0].AccountID.Address)
+	})
+
+	t.Run("nominal case with attached payload signatures", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sender},
+			Payer:       payer,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+		tx.AddPayloadSignature(sender, 0, mocks.GenericBytes)
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		got, err := tr.SigningPayloads(encode(t, tx))
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, payer.Hex(), got[0].AccountID.Address)
+	})
+
+	t.Run("nominal case with signed transaction", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sender},
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+		tx.AddEnvelopeSignature(sender, 0, mocks.GenericBytes)
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		got, err := tr.SigningPayloads(encode(t, tx))
+
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("handles non-json-encoded transaction payload", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		invalidPayload := base64.StdEncoding.EncodeToString(mocks.GenericBytes)
+
+		_, err := tr.SigningPayloads(invalidPayload)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+	})
+
+	t.Run("handles invoker failure on Key", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sender},
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return nil, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		_, err := tr.SigningPayloads(encode(t, tx))
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidKey{})
+	})
+}
+
+func TestTransactor_DetachedPayloads(t *testing.T) {
+	sender := sdk.HexToAddress(mocks.GenericAddress(0).Hex())
+	payer := sdk.HexToAddress(mocks.GenericAddress(1).Hex())
+
+	validator := mocks.BaselineValidator(t)
+	validator.AccountFunc = func(rosAccountID identifier.Account) (flow.Address, error) {
+		return flow.HexToAddress(rosAccountID.Address), nil
+	}
+
+	amountData, err := cjson.Encode(mocks.GenericAmount(0))
+	require.NoError(t, err)
+	addressData, err := cjson.Encode(cadence.BytesToAddress(payer.Bytes()))
+	require.NoError(t, err)
+
+	encode := func(t *testing.T, tx *sdk.Transaction) string {
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
+	t.Run("nominal case with sender as payer", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Script:      mocks.GenericBytes,
+			Arguments:   [][]byte{amountData, addressData},
+			GasLimit:    9999,
+			Authorizers: []sdk.Address{sender},
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender, SequenceNumber: 42},
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		payloads, summary, err := tr.DetachedPayloads(encode(t, tx), transactor.FormatDetached)
+
+		require.NoError(t, err)
+		require.Len(t, payloads, 1)
+		assert.Equal(t, sender.Hex(), payloads[0].AccountID.Address)
+		assert.Equal(t, "ecdsa", payloads[0].SignatureType)
+		message := append(sdk.TransactionDomainTag[:], tx.EnvelopeMessage()...)
+		assert.Equal(t, hex.EncodeToString(message), payloads[0].HexBytes)
+		assert.Equal(t, transactor.FormatDetached, transactor.Format(payloads[0].HexBytes))
+
+		require.NotNil(t, summary)
+		assert.Equal(t, uint64(42), summary.SequenceNumber)
+		assert.Equal(t, uint64(9999), summary.GasLimit)
+		assert.Equal(t, sender.Hex(), summary.Payer)
+		assert.Equal(t, []string{sender.Hex()}, summary.Authorizers)
+		require.Len(t, summary.Operations, 2)
+		assert.Contains(t, summary.Operations[0], sender.Hex())
+		assert.Contains(t, summary.Operations[1], payer.Hex())
+	})
+
+	t.Run("nominal case with distinct payer", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Script:      mocks.GenericBytes,
+			Arguments:   [][]byte{amountData, addressData},
+			Authorizers: []sdk.Address{sender},
+			Payer:       payer,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		payloads, _, err := tr.DetachedPayloads(encode(t, tx), transactor.FormatDetached)
+
+		require.NoError(t, err)
+		require.Len(t, payloads, 1)
+		assert.Equal(t, sender.Hex(), payloads[0].AccountID.Address)
+		message := append(sdk.TransactionDomainTag[:], tx.PayloadMessage()...)
+		assert.Equal(t, hex.EncodeToString(message), payloads[0].HexBytes)
+	})
+
+	t.Run("nominal case with signed transaction", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sender},
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+		tx.AddEnvelopeSignature(sender, 0, mocks.GenericBytes)
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		payloads, summary, err := tr.DetachedPayloads(encode(t, tx), transactor.FormatDetached)
+
+		require.NoError(t, err)
+		assert.Empty(t, payloads)
+		assert.Nil(t, summary)
+	})
+
+	t.Run("handles non-json-encoded transaction payload", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		invalidPayload := base64.StdEncoding.EncodeToString(mocks.GenericBytes)
+
+		_, _, err := tr.DetachedPayloads(invalidPayload, transactor.FormatDetached)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+	})
+
+	t.Run("nominal case with ledger format", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Script:      mocks.GenericBytes,
+			Arguments:   [][]byte{amountData, addressData},
+			Authorizers: []sdk.Address{sender},
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		payloads, summary, err := tr.DetachedPayloads(encode(t, tx), transactor.FormatLedger)
+
+		require.NoError(t, err)
+		require.Len(t, payloads, 1)
+		assert.Equal(t, hex.EncodeToString(tx.EnvelopeMessage()), payloads[0].HexBytes)
+		assert.Equal(t, transactor.FormatLedger, transactor.Format(payloads[0].HexBytes))
+		assert.NotNil(t, summary)
+	})
+
+	t.Run("handles unsupported payload format", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Script:      mocks.GenericBytes,
+			Arguments:   [][]byte{amountData, addressData},
+			Authorizers: []sdk.Address{sender},
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		_, _, err := tr.DetachedPayloads(encode(t, tx), "invalid")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles unsupported transaction script", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Script:      []byte("invalid script"),
+			Authorizers: []sdk.Address{sender},
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		_, _, err := tr.DetachedPayloads(encode(t, tx), transactor.FormatDetached)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidScript{})
+	})
+}
+
+func TestFormat(t *testing.T) {
+	tx := &sdk.Transaction{
+		Script:   []byte("transaction {}"),
+		GasLimit: 9999,
+	}
+
+	t.Run("hash", func(t *testing.T) {
+		t.Parallel()
+
+		hash := bytes.Repeat([]byte{0xff}, 32)
+
+		assert.Equal(t, "", transactor.Format(hex.EncodeToString(hash)))
+	})
+
+	t.Run("domain-tagged message", func(t *testing.T) {
+		t.Parallel()
+
+		message := append(flow.TransactionDomainTag[:], tx.PayloadMessage()...)
+
+		assert.Equal(t, transactor.FormatDetached, transactor.Format(hex.EncodeToString(message)))
+	})
+
+	t.Run("message", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, transactor.FormatLedger, transactor.Format(hex.EncodeToString(tx.EnvelopeMessage())))
+	})
+
+	t.Run("long message", func(t *testing.T) {
+		t.Parallel()
+
+		long := &sdk.Transaction{
+			Script:   bytes.Repeat([]byte{'a'}, 300),
+			GasLimit: 9999,
+		}
+
+		assert.Equal(t, transactor.FormatLedger, transactor.Format(hex.EncodeToString(long.PayloadMessage())))
+	})
+
+	t.Run("bytes that are not a list", func(t *testing.T) {
+		t.Parallel()
+
+		data := append([]byte{0xd8}, bytes.Repeat([]byte{0x01}, 63)...)
+
+		assert.Equal(t, "", transactor.Format(hex.EncodeToString(data)))
+	})
+
+	t.Run("invalid hex", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "", transactor.Format("not hex"))
+	})
+}
+
+func TestTransactor_Parse(t *testing.T) {
+	tx := &sdk.Transaction{
+		ProposalKey: sdk.ProposalKey{SequenceNumber: 42},
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		encodedData := base64.StdEncoding.EncodeToString(data)
+
+		tr := transactor.BaselineTransactor(t)
+
+		got, err := tr.Parse(encodedData)
+
+		require.NoError(t, err)
+		assert.Equal(t, tx.ProposalKey.SequenceNumber, got.Sequence())
+	})
+
+	t.Run("handles missing base64 encoding", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		tr := transactor.BaselineTransactor(t)
+
+		_, err = tr.Parse(string(data))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles non-JSON-encoded payloads", func(t *testing.T) {
+		t.Parallel()
+
+		payload := mocks.GenericBytes
+
+		tr := transactor.BaselineTransactor(t)
+
+		_, err := tr.Parse(string(payload))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestTransactor_AttachSignatures(t *testing.T) {
+	senderID := mocks.GenericAccountID(0)
+	sender := sdk.HexToAddress(mocks.GenericAddress(0).Hex())
+	receiverID := mocks.GenericAccountID(1)
+	receiver := sdk.HexToAddress(mocks.GenericAddress(1).Hex())
+	tx := &sdk.Transaction{
+		Authorizers: []sdk.Address{
+			sender,
+		},
+		Payer:       sender,
+		ProposalKey: sdk.ProposalKey{Address: sender},
+	}
+
+	data, err := json.Marshal(tx)
+	require.NoError(t, err)
+
+	payload := base64.StdEncoding.EncodeToString(data)
+
+	key, err := generateKey()
+	require.NoError(t, err)
+
+	// We need to specify a weight of 1000 because otherwise multiple public keys would be required, as
+	// the total required weight for an account's signature to be considered valid has to be equal to 1000.
+	// For some reason this 1000 magic number is not exposed anywhere that I could find in Flow.
+	pubKey := key.PublicKey(1000)
+	hexBytes := strings.TrimPrefix(pubKey.PublicKey.String(), "0x")
+	senderSignature := object.Signature{
+		SigningPayload: object.SigningPayload{
+			AccountID:     senderID,
+			HexBytes:      hexBytes,
+			SignatureType: "ecdsa",
+		},
+		SignatureType: "ecdsa",
+		HexBytes:      hexBytes,
+		PublicKey: object.PublicKey{
+			HexBytes: hexBytes,
+		},
+	}
+	receiverSignature := object.Signature{
+		SigningPayload: object.SigningPayload{
+			AccountID:     receiverID,
+			HexBytes:      hexBytes,
+			SignatureType: "ecdsa",
+		},
+		SignatureType: "ecdsa",
+		HexBytes:      hexBytes,
+		PublicKey: object.PublicKey{
+			HexBytes: hexBytes,
+		},
+	}
+	signatures := []object.Signature{senderSignature}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		got, err := tr.AttachSignatures(payload, signatures)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, got)
+	})
+
+	t.Run("nominal case with proposal key of proposer", func(t *testing.T) {
+		t.Parallel()
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sender},
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender, KeyIndex: 2},
+		}
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(_ uint64, _ flow.Address, index int) (*flow.AccountPublicKey, error) {
+			assert.Equal(t, 2, index)
+			return &mocks.GenericAccount.Keys[0], nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		got, err := tr.AttachSignatures(base64.StdEncoding.EncodeToString(data), signatures)
+		require.NoError(t, err)
+
+		data, err = base64.StdEncoding.DecodeString(got)
+		require.NoError(t, err)
+		var signed sdk.Transaction
+		err = json.Unmarshal(data, &signed)
+		require.NoError(t, err)
+
+		require.Len(t, signed.EnvelopeSignatures, 1)
+		assert.Equal(t, 2, signed.EnvelopeSignatures[0].KeyIndex)
+	})
+
+	t.Run("handles non-base64-encoded transaction payload", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		invalidPayload, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		_, err = tr.AttachSignatures(string(invalidPayload), signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+	})
+
+	t.Run("handles non-json-encoded transaction payload", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		invalidPayload := base64.StdEncoding.EncodeToString(mocks.GenericBytes)
+
+		_, err = tr.AttachSignatures(invalidPayload, signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+	})
+
+	t.Run("handles invalid number of authorizers (>)", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{
+				sender,
+				sender,
+			},
+			Payer: sender,
+		}
+
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		payload := base64.StdEncoding.EncodeToString(data)
+
+		_, err = tr.AttachSignatures(payload, signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidAuthorizers{})
+	})
+
+	t.Run("handles invalid number of authorizers (0)", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{},
+			Payer:       sender,
+		}
+
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		payload := base64.StdEncoding.EncodeToString(data)
+
+		_, err = tr.AttachSignatures(payload, signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidAuthorizers{})
+	})
+
+	t.Run("handles mismatch between length of signatures and authorizers", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		signatures := []object.Signature{senderSignature, senderSignature, senderSignature} // 3 signatures but only 1 authorizer.
+
+		_, err = tr.AttachSignatures(payload, signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignatures{})
+	})
+
+	t.Run("nominal case with distinct payer", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sender},
+			Payer:       receiver,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		payload := base64.StdEncoding.EncodeToString(data)
+
+		// The sender first signs the payload.
+		partial, err := tr.AttachSignatures(payload, signatures)
+		require.NoError(t, err)
+
+		data, err = base64.StdEncoding.DecodeString(partial)
+		require.NoError(t, err)
+		var partialTx sdk.Transaction
+		err = json.Unmarshal(data, &partialTx)
+		require.NoError(t, err)
+
+		require.Len(t, partialTx.PayloadSignatures, 1)
+		assert.Equal(t, sender, partialTx.PayloadSignatures[0].Address)
+		assert.Empty(t, partialTx.EnvelopeSignatures)
+
+		// The payer then signs the envelope, in a second round.
+		signed, err := tr.AttachSignatures(partial, []object.Signature{receiverSignature})
+		require.NoError(t, err)
+
+		data, err = base64.StdEncoding.DecodeString(signed)
+		require.NoError(t, err)
+		var signedTx sdk.Transaction
+		err = json.Unmarshal(data, &signedTx)
+		require.NoError(t, err)
+
+		assert.Len(t, signedTx.PayloadSignatures, 1)
+		require.Len(t, signedTx.EnvelopeSignatures, 1)
+		assert.Equal(t, receiver, signedTx.EnvelopeSignatures[0].Address)
+	})
+
+	t.Run("handles payer signature with pending payload signatures", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		tx := &sdk.Transaction{
+			Authorizers: []sdk.Address{sender},
+			Payer:       receiver,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+		}
+
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		payload := base64.StdEncoding.EncodeToString(data)
+
+		_, err = tr.AttachSignatures(payload, []object.Signature{senderSignature, receiverSignature})
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignatures{})
+	})
+
+	t.Run("handles unexpected envelope signatures", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		tx := &sdk.Transaction{
+			Authorizers:        []sdk.Address{sender},
+			Payer:              sender,
+			EnvelopeSignatures: []sdk.TransactionSignature{{}}, // 1 empty senderSignature just to trigger the failure.
+		}
+
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		payload := base64.StdEncoding.EncodeToString(data)
+
+		_, err = tr.AttachSignatures(payload, signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+	})
+
+	t.Run("handles mismatch between signer and sender", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		signatures := []object.Signature{receiverSignature} // Signed by receiver instead of sender.
+
+		_, err = tr.AttachSignatures(payload, signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+	})
+
+	t.Run("handles invalid signature algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		signatures := []object.Signature{
+			{
+				SigningPayload: object.SigningPayload{
+					AccountID:     senderID,
+					HexBytes:      hexBytes,
+					SignatureType: "invalid_type",
+				},
+				SignatureType: "invalid_type",
+				HexBytes:      hexBytes,
+				PublicKey: object.PublicKey{
+					HexBytes: hexBytes,
+				},
+			},
+		}
+
+		_, err = tr.AttachSignatures(payload, signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+	})
+
+	t.Run("handles invalid signature bytes", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		signatures := []object.Signature{
+			{
+				SigningPayload: object.SigningPayload{
+					AccountID:     senderID,
+					HexBytes:      string(mocks.GenericBytes),
+					SignatureType: "ecdsa",
+				},
+				SignatureType: "ecdsa",
+				HexBytes:      string(mocks.GenericBytes),
+				PublicKey: object.PublicKey{
+					HexBytes: string(mocks.GenericBytes),
+				},
+			},
+		}
+
+		_, err = tr.AttachSignatures(payload, signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+	})
+
+	t.Run("nominal case with secp256k1 key", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			key := mocks.GenericAccount.Keys[0]
+			key.SignAlgo = sdkcrypto.ECDSA_secp256k1
+			key.HashAlgo = sdkcrypto.SHA3_256
+			return &key, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		signature := senderSignature
+		signature.PublicKey.CurveType = "secp256k1"
+
+		got, err := tr.AttachSignatures(payload, []object.Signature{signature})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, got)
+	})
+
+	t.Run("handles invalid signature curve", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		signature := senderSignature
+		signature.PublicKey.CurveType = "secp256k1" // baseline key uses the P-256 curve
+
+		_, err = tr.AttachSignatures(payload, []object.Signature{signature})
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+	})
+
+	t.Run("handles invalid signature length", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		signature := senderSignature
+		signature.HexBytes = hex.EncodeToString(mocks.GenericBytes)
+
+		_, err = tr.AttachSignatures(payload, []object.Signature{signature})
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+	})
+
+	t.Run("handles invalid block", func(t *testing.T) {
+		t.Parallel()
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
+
+		_, err = tr.AttachSignatures(payload, signatures)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invoker failure on Key", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return nil, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		_, err = tr.AttachSignatures(payload, signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidKey{})
+	})
+
+	t.Run("handles unsupported key signature algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			key := mocks.GenericAccount.Keys[0]
+			key.SignAlgo = sdkcrypto.UnknownSignatureAlgorithm
+			return &key, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		_, err = tr.AttachSignatures(payload, signatures)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidKey{})
+	})
+
+	t.Run("nominal case with detached signing payload", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return &pubKey, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		message := append(sdk.TransactionDomainTag[:], tx.EnvelopeMessage()...)
+		signer := sdkcrypto.NewInMemorySigner(key.PrivateKey, key.HashAlgo)
+		sig, err := signer.Sign(message)
+		require.NoError(t, err)
+
+		signature := object.Signature{
+			SigningPayload: object.SigningPayload{
+				AccountID:     senderID,
+				HexBytes:      hex.EncodeToString(message),
+				SignatureType: "ecdsa",
+			},
+			SignatureType: "ecdsa",
+			HexBytes:      hex.EncodeToString(sig),
+		}
+
+		got, err := tr.AttachSignatures(payload, []object.Signature{signature})
+
+		require.NoError(t, err)
+
+		data, err := base64.StdEncoding.DecodeString(got)
+		require.NoError(t, err)
+		var signedTx sdk.Transaction
+		err = json.Unmarshal(data, &signedTx)
+		require.NoError(t, err)
+
+		require.Len(t, signedTx.EnvelopeSignatures, 1)
+		assert.Equal(t, sig, signedTx.EnvelopeSignatures[0].Signature)
+	})
+
+	t.Run("handles detached signing payload of another message", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return &pubKey, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		// The payload message is not what the sender signs when it is also the payer.
+		message := append(sdk.TransactionDomainTag[:], tx.PayloadMessage()...)
+		signer := sdkcrypto.NewInMemorySigner(key.PrivateKey, key.HashAlgo)
+		sig, err := signer.Sign(message)
+		require.NoError(t, err)
+
+		signature := object.Signature{
+			SigningPayload: object.SigningPayload{
+				AccountID:     senderID,
+				HexBytes:      hex.EncodeToString(message),
+				SignatureType: "ecdsa",
+			},
+			SignatureType: "ecdsa",
+			HexBytes:      hex.EncodeToString(sig),
+		}
+
+		_, err = tr.AttachSignatures(payload, []object.Signature{signature})
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+	})
+
+	t.Run("nominal case with ledger signing payload and DER signature", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return &pubKey, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		message := tx.EnvelopeMessage()
+		signer := sdkcrypto.NewInMemorySigner(key.PrivateKey, key.HashAlgo)
+		sig, err := signer.Sign(append(sdk.TransactionDomainTag[:], message...))
+		require.NoError(t, err)
+
+		der, err := asn1.Marshal(struct {
+			R *big.Int
+			S *big.Int
+		}{
+			R: new(big.Int).SetBytes(sig[:32]),
+			S: new(big.Int).SetBytes(sig[32:]),
+		})
+		require.NoError(t, err)
+
+		signature := object.Signature{
+			SigningPayload: object.SigningPayload{
+				AccountID:     senderID,
+				HexBytes:      hex.EncodeToString(message),
+				SignatureType: "ecdsa",
+			},
+			SignatureType: "ecdsa",
+			HexBytes:      hex.EncodeToString(der),
+		}
+
+		got, err := tr.AttachSignatures(payload, []object.Signature{signature})
+
+		require.NoError(t, err)
+
+		data, err := base64.StdEncoding.DecodeString(got)
+		require.NoError(t, err)
+		var signedTx sdk.Transaction
+		err = json.Unmarshal(data, &signedTx)
+		require.NoError(t, err)
+
+		require.Len(t, signedTx.EnvelopeSignatures, 1)
+		assert.Equal(t, sig, signedTx.EnvelopeSignatures[0].Signature)
+	})
+
+	t.Run("nominal case with signature with recovery ID", func(t *testing.T) {
+		t.Parallel()
+
+		signature := senderSignature
+		signature.HexBytes = hexBytes + "01"
 
 		tr := transactor.BaselineTransactor(t)
 
-		_, err := tr.Parse(string(payload))
+		got, err := tr.AttachSignatures(payload, []object.Signature{signature})
 
-		assert.Error(t, err)
+		require.NoError(t, err)
+
+		data, err := base64.StdEncoding.DecodeString(got)
+		require.NoError(t, err)
+		var signedTx sdk.Transaction
+		err = json.Unmarshal(data, &signedTx)
+		require.NoError(t, err)
+
+		require.Len(t, signedTx.EnvelopeSignatures, 1)
+		assert.Equal(t, hexBytes, hex.EncodeToString(signedTx.EnvelopeSignatures[0].Signature))
+	})
+
+	t.Run("handles invalid signature of detached signing payload", func(t *testing.T) {
+		t.Parallel()
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return &pubKey, nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
+
+		message := append(sdk.TransactionDomainTag[:], tx.EnvelopeMessage()...)
+		signature := object.Signature{
+			SigningPayload: object.SigningPayload{
+				AccountID:     senderID,
+				HexBytes:      hex.EncodeToString(message),
+				SignatureType: "ecdsa",
+			},
+			SignatureType: "ecdsa",
+			HexBytes:      hexBytes,
+		}
+
+		_, err = tr.AttachSignatures(payload, []object.Signature{signature})
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidSignature{})
 	})
 }
 
-func TestTransactor_AttachSignatures(t *testing.T) {
-	senderID := mocks.GenericAccountID(0)
-	sender := sdk.HexToAddress(mocks.GenericAddress(0).Hex())
-	receiverID := mocks.GenericAccountID(1)
-	receiver := sdk.HexToAddress(mocks.GenericAddress(1).Hex())
-	tx := &sdk.Transaction{
-		Authorizers: []sdk.Address{
-			sender,
-		},
-		Payer: sender,
-	}
+func TestTransactor_TransactionIdentifier(t *testing.T) {
+	tx := &sdk.Transaction{}
 
 	data, err := json.Marshal(tx)
 	require.NoError(t, err)
 
 	payload := base64.StdEncoding.EncodeToString(data)
 
-	key, err := generateKey()
-	require.NoError(t, err)
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
 
-	// We need to specify a weight of 1000 because otherwise multiple public keys would be required, as
-	// the total required weight for an account's signature to be considered valid has to be equal to 1000.
-	// For some reason this 1000 magic number is not exposed anywhere that I could find in Flow.
-	pubKey := key.PublicKey(1000)
-	hexBytes := strings.TrimPrefix(pubKey.PublicKey.String(), "0x")
-	senderSignature := object.Signature{
-		SigningPayload: object.SigningPayload{
-			AccountID:     senderID,
-			HexBytes:      hexBytes,
-			SignatureType: "ecdsa",
-		},
-		SignatureType: "ecdsa",
-		HexBytes:      hexBytes,
-		PublicKey: object.PublicKey{
-			HexBytes: hexBytes,
-		},
-	}
-	receiverSignature := object.Signature{
-		SigningPayload: object.SigningPayload{
-			AccountID:     receiverID,
-			HexBytes:      hexBytes,
-			SignatureType: "ecdsa",
-		},
-		SignatureType: "ecdsa",
-		HexBytes:      hexBytes,
-		PublicKey: object.PublicKey{
-			HexBytes: hexBytes,
-		},
+		tr := transactor.BaselineTransactor(t)
+
+		got, err := tr.TransactionIdentifier(payload)
+
+		require.NoError(t, err)
+		assert.Equal(t, tx.ID().Hex(), got.Hash)
+	})
+
+	t.Run("handles non-base64-encoded transaction payload", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		invalidPayload, err := json.Marshal(tx)
+		require.NoError(t, err)
+
+		_, err = tr.TransactionIdentifier(string(invalidPayload))
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+	})
+
+	t.Run("handles non-json-encoded transaction payload", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		invalidPayload := base64.StdEncoding.EncodeToString(mocks.GenericBytes)
+
+		_, err = tr.TransactionIdentifier(invalidPayload)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+	})
+}
+
+func TestTransactor_SubmitTransaction(t *testing.T) {
+	current := mocks.GenericRosBlockID
+	tx := &sdk.Transaction{
+		ProposalKey: sdk.ProposalKey{SequenceNumber: mocks.GenericAccount.Keys[0].SeqNumber},
 	}
-	signatures := []object.Signature{senderSignature}
+
+	data, err := json.Marshal(tx)
+	require.NoError(t, err)
+
+	payload := base64.StdEncoding.EncodeToString(data)
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
+		submitter := mocks.BaselineSubmitter(t)
+		submitter.TransactionFunc = func(gotTx *sdk.Transaction) error {
+			assert.Equal(t, tx, gotTx)
 
-		got, err := tr.AttachSignatures(payload, signatures)
+			return nil
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithSubmitter(submitter))
+
+		got, err := tr.SubmitTransaction(current, payload)
 
 		require.NoError(t, err)
-		assert.NotEmpty(t, got)
+		assert.Equal(t, tx.ID().Hex(), got.Hash)
 	})
 
 	t.Run("handles non-base64-encoded transaction payload", func(t *testing.T) {
@@ -469,304 +2237,538 @@ func TestTransactor_AttachSignatures(t *testing.T) {
 		invalidPayload, err := json.Marshal(tx)
 		require.NoError(t, err)
 
-		_, err = tr.AttachSignatures(string(invalidPayload), signatures)
+		_, err = tr.SubmitTransaction(current, string(invalidPayload))
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+	})
+
+	t.Run("handles non-json-encoded transaction payload", func(t *testing.T) {
+		t.Parallel()
+
+		tr := transactor.BaselineTransactor(t)
+
+		invalidPayload := base64.StdEncoding.EncodeToString(mocks.GenericBytes)
+
+		_, err = tr.SubmitTransaction(current, invalidPayload)
+
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+	})
+
+	t.Run("handles submitter failure", func(t *testing.T) {
+		t.Parallel()
+
+		submitter := mocks.BaselineSubmitter(t)
+		submitter.TransactionFunc = func(*sdk.Transaction) error {
+			return mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithSubmitter(submitter))
+
+		_, err := tr.SubmitTransaction(current, payload)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles expired transaction", func(t *testing.T) {
+		t.Parallel()
+
+		submitter := mocks.BaselineSubmitter(t)
+		submitter.TransactionFunc = func(*sdk.Transaction) error {
+			t.Fail()
+			return nil
+		}
+
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(rosBlockID identifier.Block) (uint64, flow.Identifier, error) {
+			if rosBlockID.Index == nil {
+				return mocks.GenericHeight, flow.ZeroID, nil
+			}
+			return mocks.GenericHeight + flow.DefaultTransactionExpiry + 1, flow.ZeroID, nil
+		}
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithSubmitter(submitter),
+			transactor.WithValidator(validator),
+		)
+
+		_, err := tr.SubmitTransaction(current, payload)
+
+		var expired failure.ExpiredTransaction
+		require.ErrorAs(t, err, &expired)
+		assert.Equal(t, flow.Address(tx.ProposalKey.Address), expired.Proposer)
+		assert.Equal(t, tx.ProposalKey.KeyIndex, expired.KeyIndex)
+		assert.Equal(t, flow.Address(tx.Payer), expired.Payer)
+		assert.Equal(t, tx.GasLimit, expired.GasLimit)
+	})
+
+	t.Run("handles sequence conflict", func(t *testing.T) {
+		t.Parallel()
+
+		submitter := mocks.BaselineSubmitter(t)
+		submitter.TransactionFunc = func(*sdk.Transaction) error {
+			t.Fail()
+			return nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			key := mocks.GenericAccount.Keys[0]
+			key.SeqNumber++
+			return &key, nil
+		}
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithSubmitter(submitter),
+			transactor.WithInvoker(invoker),
+		)
+
+		_, err := tr.SubmitTransaction(current, payload)
+
+		var conflict failure.SequenceConflict
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, tx.ProposalKey.SequenceNumber, conflict.Sequence)
+		assert.Equal(t, tx.ProposalKey.SequenceNumber+1, conflict.Current)
+	})
+
+	t.Run("returns original transaction on resubmission after inclusion", func(t *testing.T) {
+		t.Parallel()
+
+		submitter := mocks.BaselineSubmitter(t)
+		submitter.SubmittedFunc = func(txID sdk.Identifier) bool {
+			assert.Equal(t, tx.ID(), txID)
+			return true
+		}
+		submitter.TransactionFunc = func(*sdk.Transaction) error {
+			t.Fail()
+			return nil
+		}
+
+		// Once the transaction is indexed, the sequence number of the key has
+		// moved past the one of the transaction.
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			key := mocks.GenericAccount.Keys[0]
+			key.SeqNumber++
+			return &key, nil
+		}
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithSubmitter(submitter),
+			transactor.WithInvoker(invoker),
+		)
+
+		got, err := tr.SubmitTransaction(current, payload)
+
+		require.NoError(t, err)
+		assert.Equal(t, tx.ID().Hex(), got.Hash)
+	})
+
+	t.Run("handles upstream sequence rejection", func(t *testing.T) {
+		t.Parallel()
+
+		submitter := mocks.BaselineSubmitter(t)
+		submitter.TransactionFunc = func(*sdk.Transaction) error {
+			return failure.SequenceConflict{
+				Sequence: tx.ProposalKey.SequenceNumber,
+				Current:  tx.ProposalKey.SequenceNumber + 2,
+			}
+		}
+
+		tr := transactor.BaselineTransactor(t, transactor.WithSubmitter(submitter))
+
+		_, err := tr.SubmitTransaction(current, payload)
+
+		var conflict failure.SequenceConflict
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, tx.ProposalKey.SequenceNumber+2, conflict.Current)
+	})
+
+	t.Run("submits ahead of indexed sequence number", func(t *testing.T) {
+		t.Parallel()
+
+		submitted := false
+		submitter := mocks.BaselineSubmitter(t)
+		submitter.TransactionFunc = func(*sdk.Transaction) error {
+			submitted = true
+			return nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			key := mocks.GenericAccount.Keys[0]
+			key.SeqNumber--
+			return &key, nil
+		}
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithSubmitter(submitter),
+			transactor.WithInvoker(invoker),
+		)
+
+		_, err := tr.SubmitTransaction(current, payload)
+
+		require.NoError(t, err)
+		assert.True(t, submitted)
+	})
+
+	t.Run("submits when proposal key is not indexed", func(t *testing.T) {
+		t.Parallel()
+
+		submitted := false
+		submitter := mocks.BaselineSubmitter(t)
+		submitter.TransactionFunc = func(*sdk.Transaction) error {
+			submitted = true
+			return nil
+		}
+
+		invoker := mocks.BaselineInvoker(t)
+		invoker.KeyFunc = func(uint64, flow.Address, int) (*flow.AccountPublicKey, error) {
+			return nil, mocks.GenericError
+		}
+
+		tr := transactor.BaselineTransactor(t,
+			transactor.WithSubmitter(submitter),
+			transactor.WithInvoker(invoker),
+		)
+
+		_, err := tr.SubmitTransaction(current, payload)
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+		require.NoError(t, err)
+		assert.True(t, submitted)
 	})
 
-	t.Run("handles non-json-encoded transaction payload", func(t *testing.T) {
+	t.Run("handles invalid reference block", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
 
-		invalidPayload := base64.StdEncoding.EncodeToString(mocks.GenericBytes)
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
 
-		_, err = tr.AttachSignatures(invalidPayload, signatures)
+		_, err := tr.SubmitTransaction(current, payload)
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+		assert.Error(t, err)
 	})
+}
 
-	t.Run("handles invalid number of authorizers (>)", func(t *testing.T) {
-		t.Parallel()
-
-		tr := transactor.BaselineTransactor(t)
+func TestTransactor_Simulate(t *testing.T) {
+	rosBlockID := mocks.GenericRosBlockID
+	sender := sdk.HexToAddress(mocks.GenericAddress(0).Hex())
+	receiver := mocks.GenericAddress(1)
+	transfer := mocks.GenericAmount(0).ToGoValue().(uint64)
+	fee := uint64(100_000)
 
-		tx := &sdk.Transaction{
-			Authorizers: []sdk.Address{
-				sender,
-				sender,
-			},
-			Payer: sender,
-		}
+	amountData, err := cjson.Encode(mocks.GenericAmount(0))
+	require.NoError(t, err)
+	addressData, err := cjson.Encode(cadence.NewAddress(receiver))
+	require.NoError(t, err)
 
-		data, err := json.Marshal(tx)
-		require.NoError(t, err)
+	tx := &sdk.Transaction{
+		Payer:       sender,
+		ProposalKey: sdk.ProposalKey{Address: sender},
+		Authorizers: []sdk.Address{sender},
+		Script:      mocks.GenericBytes,
+		Arguments:   [][]byte{amountData, addressData},
+	}
 
-		payload := base64.StdEncoding.EncodeToString(data)
+	data, err := json.Marshal(tx)
+	require.NoError(t, err)
 
-		_, err = tr.AttachSignatures(payload, signatures)
+	payload := base64.StdEncoding.EncodeToString(data)
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidAuthorizers{})
-	})
+	// script returns the transaction fee for the fee script, and the given
+	// balance for the balance script.
+	script := func(balance uint64) func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+		return func(_ uint64, script []byte, _ []cadence.Value) (cadence.Value, error) {
+			if bytes.Equal(script, mocks.GenericBytes) {
+				return cadence.NewUInt64(fee), nil
+			}
+			return cadence.NewUInt64(balance), nil
+		}
+	}
 
-	t.Run("handles invalid number of authorizers (0)", func(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
-
-		tx := &sdk.Transaction{
-			Authorizers: []sdk.Address{},
-			Payer:       sender,
+		var addresses []cadence.Value
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+			assert.Equal(t, mocks.GenericHeight, height)
+			if bytes.Equal(script, mocks.GenericBytes) {
+				return cadence.NewUInt64(fee), nil
+			}
+			assert.Equal(t, []byte(mocks.GenericAmount(0).String()), script)
+			require.Len(t, parameters, 1)
+			addresses = append(addresses, parameters[0])
+
+			return cadence.NewUInt64(transfer + fee), nil
 		}
+		var executed *flow.TransactionBody
+		invoker.TransactionFunc = func(height uint64, body *flow.TransactionBody) error {
+			assert.Equal(t, mocks.GenericHeight, height)
+			executed = body
 
-		data, err := json.Marshal(tx)
-		require.NoError(t, err)
+			return nil
+		}
 
-		payload := base64.StdEncoding.EncodeToString(data)
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
 
-		_, err = tr.AttachSignatures(payload, signatures)
+		gotBlockID, got, gotFee, err := tr.Simulate(payload)
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidAuthorizers{})
+		require.NoError(t, err)
+		assert.Equal(t, rosBlockID, gotBlockID)
+		assert.Len(t, got, 2)
+		assert.Equal(t, fee, gotFee)
+		assert.Equal(t, []cadence.Value{cadence.NewAddress(mocks.GenericAddress(0)), cadence.NewAddress(receiver)}, addresses)
+
+		require.NotNil(t, executed)
+		assert.Equal(t, mocks.GenericBytes, executed.Script)
+		assert.Equal(t, [][]byte{amountData, addressData}, executed.Arguments)
+		assert.Equal(t, flow.Address(sender), executed.Payer)
+		assert.Equal(t, []flow.Address{flow.Address(sender)}, executed.Authorizers)
 	})
 
-	t.Run("handles mismatch between length of signatures and authorizers", func(t *testing.T) {
+	t.Run("handles insufficient balance", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = script(0)
 
-		signatures := []object.Signature{senderSignature, senderSignature, senderSignature} // 3 signatures but only 1 authorizer.
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
 
-		_, err = tr.AttachSignatures(payload, signatures)
+		_, _, _, err := tr.Simulate(payload)
 
 		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidSignatures{})
+		assert.ErrorAs(t, err, &failure.InsufficientBalance{})
 	})
 
-	t.Run("handles mismatch between payer and sender", func(t *testing.T) {
+	t.Run("handles balance that does not cover the fee", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
-
-		tx := &sdk.Transaction{
-			Authorizers: []sdk.Address{sender},
-			Payer:       receiver,
-		}
-
-		data, err := json.Marshal(tx)
-		require.NoError(t, err)
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = script(transfer)
 
-		payload := base64.StdEncoding.EncodeToString(data)
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
 
-		_, err = tr.AttachSignatures(payload, signatures)
+		_, _, _, err := tr.Simulate(payload)
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidPayer{})
+		var ibErr failure.InsufficientBalance
+		require.ErrorAs(t, err, &ibErr)
+		assert.Equal(t, amount.Format(transfer+fee), ibErr.Amount)
 	})
 
-	t.Run("handles unexpected envelope signatures", func(t *testing.T) {
+	t.Run("does not charge the fee to a sender that is not the payer", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
-
 		tx := &sdk.Transaction{
-			Authorizers:        []sdk.Address{sender},
-			Payer:              sender,
-			EnvelopeSignatures: []sdk.TransactionSignature{{}}, // 1 empty senderSignature just to trigger the failure.
+			Payer:       sdk.HexToAddress(mocks.GenericAddress(2).Hex()),
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericBytes,
+			Arguments:   [][]byte{amountData, addressData},
 		}
-
 		data, err := json.Marshal(tx)
 		require.NoError(t, err)
 
-		payload := base64.StdEncoding.EncodeToString(data)
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = script(transfer)
 
-		_, err = tr.AttachSignatures(payload, signatures)
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+		_, _, _, err = tr.Simulate(base64.StdEncoding.EncodeToString(data))
+
+		assert.NoError(t, err)
 	})
 
-	t.Run("handles mismatch between signer and sender", func(t *testing.T) {
+	t.Run("handles receiver without vault", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(_ uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+			if bytes.Equal(script, mocks.GenericBytes) {
+				return cadence.NewUInt64(fee), nil
+			}
+			if parameters[0] == cadence.NewAddress(receiver) {
+				return nil, mocks.GenericError
+			}
+			return cadence.NewUInt64(transfer + fee), nil
+		}
 
-		signatures := []object.Signature{receiverSignature} // Signed by receiver instead of sender.
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
 
-		_, err = tr.AttachSignatures(payload, signatures)
+		_, _, _, err := tr.Simulate(payload)
 
 		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+		assert.ErrorAs(t, err, &failure.InvalidReceiver{})
 	})
 
-	t.Run("handles invalid signature algorithm", func(t *testing.T) {
+	t.Run("handles failed transaction", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
-
-		signatures := []object.Signature{
-			{
-				SigningPayload: object.SigningPayload{
-					AccountID:     senderID,
-					HexBytes:      hexBytes,
-					SignatureType: "invalid_type",
-				},
-				SignatureType: "invalid_type",
-				HexBytes:      hexBytes,
-				PublicKey: object.PublicKey{
-					HexBytes: hexBytes,
-				},
-			},
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = script(transfer + fee)
+		invoker.TransactionFunc = func(uint64, *flow.TransactionBody) error {
+			return failure.FailedTransaction{Code: 1101}
 		}
 
-		_, err = tr.AttachSignatures(payload, signatures)
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidSignature{})
+		_, _, _, err := tr.Simulate(payload)
+
+		var ftErr failure.FailedTransaction
+		require.ErrorAs(t, err, &ftErr)
+		assert.Equal(t, uint(1101), ftErr.Code)
 	})
 
-	t.Run("handles invalid signature bytes", func(t *testing.T) {
+	t.Run("handles sender balance retrieval failure", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
-
-		signatures := []object.Signature{
-			{
-				SigningPayload: object.SigningPayload{
-					AccountID:     senderID,
-					HexBytes:      string(mocks.GenericBytes),
-					SignatureType: "ecdsa",
-				},
-				SignatureType: "ecdsa",
-				HexBytes:      string(mocks.GenericBytes),
-				PublicKey: object.PublicKey{
-					HexBytes: string(mocks.GenericBytes),
-				},
-			},
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(_ uint64, script []byte, _ []cadence.Value) (cadence.Value, error) {
+			if bytes.Equal(script, mocks.GenericBytes) {
+				return cadence.NewUInt64(fee), nil
+			}
+			return nil, mocks.GenericError
 		}
 
-		_, err = tr.AttachSignatures(payload, signatures)
-
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidSignature{})
-	})
-}
-
-func TestTransactor_TransactionIdentifier(t *testing.T) {
-	tx := &sdk.Transaction{}
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
 
-	data, err := json.Marshal(tx)
-	require.NoError(t, err)
+		_, _, _, err := tr.Simulate(payload)
 
-	payload := base64.StdEncoding.EncodeToString(data)
+		assert.Error(t, err)
+	})
 
-	t.Run("nominal case", func(t *testing.T) {
+	t.Run("handles transaction fee retrieval failure", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(uint64, []byte, []cadence.Value) (cadence.Value, error) {
+			return nil, mocks.GenericError
+		}
 
-		got, err := tr.TransactionIdentifier(payload)
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
 
-		require.NoError(t, err)
-		assert.Equal(t, tx.ID().Hex(), got.Hash)
+		_, _, _, err := tr.Simulate(payload)
+
+		assert.Error(t, err)
 	})
 
-	t.Run("handles non-base64-encoded transaction payload", func(t *testing.T) {
+	t.Run("handles invalid block", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
+		validator := mocks.BaselineValidator(t)
+		validator.BlockFunc = func(identifier.Block) (uint64, flow.Identifier, error) {
+			return 0, flow.ZeroID, mocks.GenericError
+		}
 
-		invalidPayload, err := json.Marshal(tx)
-		require.NoError(t, err)
+		tr := transactor.BaselineTransactor(t, transactor.WithValidator(validator))
 
-		_, err = tr.TransactionIdentifier(string(invalidPayload))
+		_, _, _, err := tr.Simulate(payload)
 
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidPayload{})
+		assert.Error(t, err)
 	})
 
-	t.Run("handles non-json-encoded transaction payload", func(t *testing.T) {
+	t.Run("handles invalid transaction payload", func(t *testing.T) {
 		t.Parallel()
 
 		tr := transactor.BaselineTransactor(t)
 
-		invalidPayload := base64.StdEncoding.EncodeToString(mocks.GenericBytes)
-
-		_, err = tr.TransactionIdentifier(invalidPayload)
+		_, _, _, err := tr.Simulate(string(mocks.GenericBytes))
 
 		require.Error(t, err)
 		assert.ErrorAs(t, err, &failure.InvalidPayload{})
 	})
-}
-
-func TestTransactor_SubmitTransaction(t *testing.T) {
-	tx := &sdk.Transaction{}
-
-	data, err := json.Marshal(tx)
-	require.NoError(t, err)
 
-	payload := base64.StdEncoding.EncodeToString(data)
-
-	t.Run("nominal case", func(t *testing.T) {
+	t.Run("executes template transactions without balance checks", func(t *testing.T) {
 		t.Parallel()
 
-		submitter := mocks.BaselineSubmitter(t)
-		submitter.TransactionFunc = func(gotTx *sdk.Transaction) error {
-			assert.Equal(t, tx, gotTx)
+		idData, err := cjson.Encode(cadence.NewUInt64(mocks.GenericHeight))
+		require.NoError(t, err)
+
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericTemplateScript,
+			Arguments:   [][]byte{addressData, idData},
+		}
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
 
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(_ uint64, script []byte, _ []cadence.Value) (cadence.Value, error) {
+			assert.Equal(t, mocks.GenericBytes, script)
+			return cadence.NewUInt64(fee), nil
+		}
+		executed := false
+		invoker.TransactionFunc = func(_ uint64, body *flow.TransactionBody) error {
+			assert.Equal(t, mocks.GenericTemplateScript, body.Script)
+			executed = true
 			return nil
 		}
 
-		tr := transactor.BaselineTransactor(t, transactor.WithSubmitter(submitter))
+		tr := transactor.BaselineTransactor(t, transactor.WithInvoker(invoker))
 
-		got, err := tr.SubmitTransaction(payload)
+		_, got, gotFee, err := tr.Simulate(base64.StdEncoding.EncodeToString(data))
 
 		require.NoError(t, err)
-		assert.Equal(t, tx.ID().Hex(), got.Hash)
+		assert.Equal(t, []object.Operation{mocks.GenericTemplateOperation()}, got)
+		assert.Equal(t, fee, gotFee)
+		assert.True(t, executed)
 	})
 
-	t.Run("handles non-base64-encoded transaction payload", func(t *testing.T) {
+	t.Run("executes transfers of other tokens without balance checks", func(t *testing.T) {
 		t.Parallel()
 
-		tr := transactor.BaselineTransactor(t)
-
-		invalidPayload, err := json.Marshal(tx)
+		ufixData, err := cjson.Encode(cadence.UFix64(100_000_000))
 		require.NoError(t, err)
 
-		_, err = tr.SubmitTransaction(string(invalidPayload))
-
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidPayload{})
-	})
-
-	t.Run("handles non-json-encoded transaction payload", func(t *testing.T) {
-		t.Parallel()
-
-		tr := transactor.BaselineTransactor(t)
-
-		invalidPayload := base64.StdEncoding.EncodeToString(mocks.GenericBytes)
-
-		_, err = tr.SubmitTransaction(invalidPayload)
-
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &failure.InvalidPayload{})
-	})
+		tx := &sdk.Transaction{
+			Payer:       sender,
+			ProposalKey: sdk.ProposalKey{Address: sender},
+			Authorizers: []sdk.Address{sender},
+			Script:      mocks.GenericFUSDScript,
+			Arguments:   [][]byte{ufixData, addressData},
+		}
+		data, err := json.Marshal(tx)
+		require.NoError(t, err)
 
-	t.Run("handles submitter failure", func(t *testing.T) {
-		t.Parallel()
+		registry := mocks.BaselineIntents(t)
+		registry.MatchFunc = func([]byte) (intents.Intent, bool) {
+			intent := intents.Intent{
+				Name:   intents.NameTransferFUSD,
+				Kind:   intents.KindTransfer,
+				Symbol: "FUSD",
+			}
+			return intent, true
+		}
 
-		submitter := mocks.BaselineSubmitter(t)
-		submitter.TransactionFunc = func(*sdk.Transaction) error {
-			return mocks.GenericError
+		invoker := mocks.BaselineInvoker(t)
+		invoker.ScriptFunc = func(_ uint64, script []byte, _ []cadence.Value) (cadence.Value, error) {
+			assert.Equal(t, mocks.GenericBytes, script)
+			return cadence.NewUInt64(fee), nil
+		}
+		executed := false
+		invoker.TransactionFunc = func(uint64, *flow.TransactionBody) error {
+			executed = true
+			return nil
 		}
 
-		tr := transactor.BaselineTransactor(t, transactor.WithSubmitter(submitter))
+		tr := transactor.BaselineTransactor(
+			t,
+			transactor.WithInvoker(invoker),
+			transactor.WithIntentRegistry(registry),
+		)
 
-		_, err := tr.SubmitTransaction(payload)
+		_, got, _, err := tr.Simulate(base64.StdEncoding.EncodeToString(data))
 
-		assert.Error(t, err)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, "FUSD", got[1].Amount.Currency.Symbol)
+		assert.True(t, executed)
 	})
 }
@@ -26,13 +26,22 @@ import (
 // Account validates the given account identifier, and if successful, returns a matching Flow Address.
 func (v *Validator) Account(account identifier.Account) (flow.Address, error) {
 
-	// Parse the address; the length was already validated, but it's still
-	// possible that the characters are not valid hex encoding.
-	bytes, err := hex.DecodeString(account.Address)
+	// Check that the address is a hex-encoded Flow address, so that it can be
+	// decoded without error.
+	err := account.Validate()
 	if err != nil {
+		return flow.EmptyAddress, err
+	}
+	bytes, _ := hex.DecodeString(account.Address)
+
+	// Sub-accounts are not used, so none of them has a balance that could be
+	// looked up.
+	if account.SubAccount != nil {
 		return flow.EmptyAddress, failure.InvalidAccount{
-			Address:     account.Address,
-			Description: failure.NewDescription(addressInvalid),
+			Address: account.Address,
+			Description: failure.NewDescription(subAccountUnsupported,
+				failure.WithString("sub_account", account.SubAccount.Address),
+			),
 		}
 	}
 
@@ -35,6 +35,18 @@ func (v *Validator) Block(rosBlockID identifier.Block) (uint64, flow.Identifier,
 		if err != nil {
 			return 0, flow.ZeroID, fmt.Errorf("could not retrieve last: %w", err)
 		}
+
+		// When only sealed blocks are served, the latest block is the latest
+		// one that is both indexed and sealed.
+		if v.cfg.SealedOnly {
+			sealed, err := v.track.Sealed()
+			if err != nil {
+				return 0, flow.ZeroID, fmt.Errorf("could not retrieve sealed: %w", err)
+			}
+			if sealed < last {
+				last = sealed
+			}
+		}
 		header, err := v.index.Header(last)
 		if err != nil {
 			return 0, flow.ZeroID, fmt.Errorf("could not retrieve header: %w", err)
@@ -43,15 +55,9 @@ func (v *Validator) Block(rosBlockID identifier.Block) (uint64, flow.Identifier,
 	}
 
 	// If a block hash is present, it should be a valid block ID for Flow.
-	if rosBlockID.Hash != "" {
-		_, err := flow.HexStringToIdentifier(rosBlockID.Hash)
-		if err != nil {
-			return 0, flow.ZeroID, failure.InvalidBlock{
-				Description: failure.NewDescription(blockInvalid,
-					failure.WithString("block_hash", rosBlockID.Hash),
-				),
-			}
-		}
+	err := rosBlockID.Validate()
+	if err != nil {
+		return 0, flow.ZeroID, err
 	}
 
 	// If a block index is present, it should be a valid height for the DPS.
@@ -72,6 +78,15 @@ func (v *Validator) Block(rosBlockID identifier.Block) (uint64, flow.Identifier,
 		if err != nil {
 			return 0, flow.ZeroID, fmt.Errorf("could not get last: %w", err)
 		}
+		if *rosBlockID.Index > last && v.sealed(*rosBlockID.Index) {
+			return 0, flow.ZeroID, failure.UnavailableBlock{
+				Index: *rosBlockID.Index,
+				Hash:  rosBlockID.Hash,
+				Description: failure.NewDescription(blockNotIndexed,
+					failure.WithUint64("last_index", last),
+				),
+			}
+		}
 		if *rosBlockID.Index > last {
 			return 0, flow.ZeroID, failure.UnknownBlock{
 				Index: *rosBlockID.Index,
@@ -108,9 +123,39 @@ func (v *Validator) Block(rosBlockID identifier.Block) (uint64, flow.Identifier,
 		}
 	}
 
+	// When only sealed blocks are served, blocks that are finalized and indexed
+	// but not sealed yet are rejected, so that clients never see a block that
+	// could still be affected by an execution fork.
+	if v.cfg.SealedOnly {
+		sealed, err := v.track.Sealed()
+		if err != nil {
+			return 0, flow.ZeroID, fmt.Errorf("could not get sealed: %w", err)
+		}
+		if header.Height > sealed {
+			return 0, flow.ZeroID, failure.UnsealedBlock{
+				Index: header.Height,
+				Hash:  header.ID().String(),
+				Description: failure.NewDescription(blockNotSealed,
+					failure.WithUint64("sealed_index", sealed),
+				),
+			}
+		}
+	}
+
 	return header.Height, header.ID(), nil
 }
 
+// sealed checks whether the given height is already sealed on the Flow network.
+// If the latest sealed height can not be determined, the height is considered
+// as not sealed, so that we fall back to treating the block as unknown.
+func (v *Validator) sealed(height uint64) bool {
+	sealed, err := v.track.Sealed()
+	if err != nil {
+		return false
+	}
+	return height <= sealed
+}
+
 // CompleteBlockID verifies that both index and hash are populated in the block ID.
 func (v *Validator) CompleteBlockID(rosBlockID identifier.Block) error {
 	if rosBlockID.Index == nil || rosBlockID.Hash == "" {
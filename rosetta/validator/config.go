@@ -0,0 +1,29 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package validator
+
+// Config contains the configuration options for the validator.
+type Config struct {
+	SealedOnly bool
+}
+
+// WithSealedOnly sets whether blocks that are finalized and indexed, but not
+// yet sealed on the Flow network, are rejected. When they are, references to
+// the latest block resolve to the latest sealed block instead.
+func WithSealedOnly(sealed bool) func(*Config) {
+	return func(c *Config) {
+		c.SealedOnly = sealed
+	}
+}
@@ -38,14 +38,9 @@ func (v *Validator) Currency(currency identifier.Currency) (string, uint, error)
 
 	// If the token is known, there should always be 8 decimals, as we always use
 	// `UFix64` for tokens on Flow.
-	if currency.Decimals != 0 && currency.Decimals != dps.FlowDecimals {
-		return "", 0, failure.InvalidCurrency{
-			Symbol:   currency.Symbol,
-			Decimals: currency.Decimals,
-			Description: failure.NewDescription(decimalsMismatch,
-				failure.WithInt("want_decimals", dps.FlowDecimals),
-			),
-		}
+	err := currency.Validate()
+	if err != nil {
+		return "", 0, err
 	}
 
 	return currency.Symbol, dps.FlowDecimals, nil
@@ -23,29 +23,41 @@ const (
 	networkEmpty      = "blockchain identifier has empty network field"
 
 	// Block identifier errors.
-	blockInvalid  = "block hash is not a valid hex-encoded string"
-	blockNotFull  = "block identifier needs both fields filled for this request"
-	blockLength   = "block identifier has invalid hash field length"
-	blockTooLow   = "block index is below first indexed height"
-	blockTooHigh  = "block index is above last indexed height"
-	blockMismatch = "block hash mismatches with authoritative hash for index"
+	blockNotFull    = "block identifier needs both fields filled for this request"
+	blockLength     = "block identifier has invalid hash field length"
+	blockTooLow     = "block index is below first indexed height"
+	blockTooHigh    = "block index is above last indexed height"
+	blockNotIndexed = "block index is sealed but not indexed yet"
+	blockNotSealed  = "block is indexed but not sealed yet"
+	blockMismatch   = "block hash mismatches with authoritative hash for index"
 
 	// Account identifier errors.
-	addressEmpty         = "account identifier has empty address field"
-	addressInvalid       = "account address is not a valid hex-encoded string"
-	addressMisconfigured = "account address is not valid for configured chain"
-	addressLength        = "account identifier has invalid address field length"
+	addressEmpty          = "account identifier has empty address field"
+	addressMisconfigured  = "account address is not valid for configured chain"
+	addressLength         = "account identifier has invalid address field length"
+	subAccountUnsupported = "account identifier has unsupported sub-account"
 
 	// Currency identifier errors.
-	currenciesEmpty  = "currency identifier list is empty"
-	symbolEmpty      = "currency identifier has empty symbol field"
-	symbolUnknown    = "currency symbol is unknown"
-	decimalsMismatch = "currency decimals mismatch with authoritative decimals for symbol"
+	currenciesEmpty = "currency identifier list is empty"
+	symbolEmpty     = "currency identifier has empty symbol field"
+	symbolUnknown   = "currency symbol is unknown"
+
+	// Node identifier errors.
+	nodeEmpty  = "node identifier is empty"
+	nodeLength = "node identifier has invalid length"
+	nodeHex    = "node identifier is not hexadecimal"
 
 	// Transaction and transaction identifier errors.
 	txHashEmpty     = "transaction identifier has empty hash field"
-	txHashInvalid   = "transaction hash is not a valid hex-encoded string"
 	txLength        = "transaction identifier has invalid hash field length"
 	txBodyEmpty     = "transaction text is empty"
 	signaturesEmpty = "signature list is empty"
+
+	// Fee errors.
+	maxFeeMultiple    = "maximum fee list has more than one amount"
+	maxFeeInvalid     = "maximum fee has invalid amount"
+	multiplierInvalid = "fee multiplier is negative"
+
+	// Search errors.
+	operatorUnknown = "search operator is unknown"
 )
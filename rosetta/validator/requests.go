@@ -15,13 +15,17 @@
 package validator
 
 import (
+	"encoding/hex"
 	"errors"
 
 	"github.com/go-playground/validator/v10"
 
+	"github.com/optakt/flow-dps/models/dps"
 	"github.com/optakt/flow-rosetta/api/rosetta"
+	"github.com/optakt/flow-rosetta/rosetta/amount"
 	"github.com/optakt/flow-rosetta/rosetta/failure"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
 	"github.com/optakt/flow-rosetta/rosetta/request"
 )
 
@@ -42,6 +46,10 @@ const (
 	symbolField      = "symbol"
 	transactionField = "transaction"
 	signaturesField  = "signatures"
+	nodeField        = "node_id"
+	operatorField    = "operator"
+	maxFeeField      = "max_fee"
+	multiplierField  = "suggested_fee_multiplier"
 
 	blockchainFailTag = "blockchain"
 	networkFailTag    = "network"
@@ -60,16 +68,23 @@ func newRequestValidator(config Configuration) *validator.Validate {
 	validate.RegisterStructValidation(accountValidator, identifier.Account{})
 	validate.RegisterStructValidation(transactionValidator, identifier.Transaction{})
 	validate.RegisterStructValidation(networkValidator(config), identifier.Network{})
+	validate.RegisterStructValidation(filterValidator, object.OperationFilter{})
 
 	// Register custom top-level validators. These validate the entire request
 	// object, compared to the ones above which validate a specific type
 	// within the request. This way we can validate some standard types (strings)
 	// or complex ones (array of currencies) in a structured way.
 	validate.RegisterStructValidation(balanceValidator, request.Balance{})
+	validate.RegisterStructValidation(preprocessValidator, request.Preprocess{})
+	validate.RegisterStructValidation(metadataValidator, request.Metadata{})
 	validate.RegisterStructValidation(parseValidator, request.Parse{})
 	validate.RegisterStructValidation(combineValidator, request.Combine{})
 	validate.RegisterStructValidation(submitValidator, request.Submit{})
 	validate.RegisterStructValidation(hashValidator, request.Hash{})
+	validate.RegisterStructValidation(rewardsValidator, request.Rewards{})
+	validate.RegisterStructValidation(nodeValidator, request.Node{})
+	validate.RegisterStructValidation(previewValidator, request.Preview{})
+	validate.RegisterStructValidation(searchValidator, request.SearchTransactions{})
 
 	return validate
 }
@@ -193,11 +208,14 @@ func blockValidator(sl validator.StructLevel) {
 // accountValidator ensures that the account address field is populated and has correct length.
 func accountValidator(sl validator.StructLevel) {
 	rosAccountID := sl.Current().Interface().(identifier.Account)
-	if rosAccountID.Address == "" {
-		sl.ReportError(rosAccountID.Address, addressField, addressField, addressEmpty, "")
-	}
-	if len(rosAccountID.Address) != rosetta.HexAddressSize {
-		sl.ReportError(rosAccountID.Address, addressField, addressField, addressLength, "")
+	validateAddress(sl, rosAccountID.Address)
+}
+
+// filterValidator ensures that all accounts of the provided operation filter have a populated address with correct length.
+func filterValidator(sl validator.StructLevel) {
+	filter := sl.Current().Interface().(object.OperationFilter)
+	for _, rosAccountID := range filter.Accounts {
+		validateAddress(sl, rosAccountID.Address)
 	}
 }
 
@@ -226,6 +244,44 @@ func balanceValidator(sl validator.StructLevel) {
 	}
 }
 
+// preprocessValidator ensures that the provided Preprocess request has at most one maximum
+// fee, given as a valid amount of FLOW, and that its fee multiplier is not negative.
+func preprocessValidator(sl validator.StructLevel) {
+	req := sl.Current().Interface().(request.Preprocess)
+	if len(req.MaxFee) > 1 {
+		sl.ReportError(req.MaxFee, maxFeeField, maxFeeField, maxFeeMultiple, "")
+	}
+	for _, fee := range req.MaxFee {
+		if fee.Currency.Symbol != dps.FlowSymbol {
+			sl.ReportError(fee.Currency.Symbol, symbolField, symbolField, symbolUnknown, "")
+		}
+		_, err := amount.Parse(fee.Value)
+		if err != nil {
+			sl.ReportError(fee.Value, maxFeeField, maxFeeField, maxFeeInvalid, "")
+		}
+	}
+	if req.Multiplier < 0 {
+		sl.ReportError(req.Multiplier, multiplierField, multiplierField, multiplierInvalid, "")
+	}
+}
+
+// metadataValidator ensures that the options of the provided Metadata request have a
+// valid maximum fee, if any, and that their fee multiplier is not negative. The options
+// are usually those returned by the preprocess endpoint, but nothing keeps clients from
+// changing them.
+func metadataValidator(sl validator.StructLevel) {
+	req := sl.Current().Interface().(request.Metadata)
+	if req.Options.MaxFee != "" {
+		_, err := amount.Parse(req.Options.MaxFee)
+		if err != nil {
+			sl.ReportError(req.Options.MaxFee, maxFeeField, maxFeeField, maxFeeInvalid, "")
+		}
+	}
+	if req.Options.Multiplier < 0 {
+		sl.ReportError(req.Options.Multiplier, multiplierField, multiplierField, multiplierInvalid, "")
+	}
+}
+
 // parseValidator ensures that the provided Parse request has a non-empty transaction field.
 func parseValidator(sl validator.StructLevel) {
 	req := sl.Current().Interface().(request.Parse)
@@ -234,6 +290,14 @@ func parseValidator(sl validator.StructLevel) {
 	}
 }
 
+// previewValidator ensures that the provided Preview request has a non-empty transaction field.
+func previewValidator(sl validator.StructLevel) {
+	req := sl.Current().Interface().(request.Preview)
+	if req.Transaction == "" {
+		sl.ReportError(req.Transaction, transactionField, transactionField, txBodyEmpty, "")
+	}
+}
+
 // combineValidator ensures that the provided Combine request has a non-empty transaction field, and
 // that the signature list is not empty.
 func combineValidator(sl validator.StructLevel) {
@@ -262,3 +326,45 @@ func hashValidator(sl validator.StructLevel) {
 		sl.ReportError(req.SignedTransaction, transactionField, transactionField, txBodyEmpty, "")
 	}
 }
+
+// rewardsValidator ensures that the provided Rewards request has a node identifier with the correct length.
+func rewardsValidator(sl validator.StructLevel) {
+	req := sl.Current().Interface().(request.Rewards)
+	validateNodeID(sl, req.NodeID)
+}
+
+// nodeValidator ensures that the provided Node request has a node identifier with the correct length.
+func nodeValidator(sl validator.StructLevel) {
+	req := sl.Current().Interface().(request.Node)
+	validateNodeID(sl, req.NodeID)
+}
+
+// searchValidator ensures that the provided SearchTransactions request has a known operator, if any.
+func searchValidator(sl validator.StructLevel) {
+	req := sl.Current().Interface().(request.SearchTransactions)
+	if req.Operator != "" && req.Operator != "and" && req.Operator != "or" {
+		sl.ReportError(req.Operator, operatorField, operatorField, operatorUnknown, "")
+	}
+}
+
+func validateAddress(sl validator.StructLevel, address string) {
+	if address == "" {
+		sl.ReportError(address, addressField, addressField, addressEmpty, "")
+	}
+	if len(address) != rosetta.HexAddressSize {
+		sl.ReportError(address, addressField, addressField, addressLength, "")
+	}
+}
+
+func validateNodeID(sl validator.StructLevel, nodeID string) {
+	if nodeID == "" {
+		sl.ReportError(nodeID, nodeField, nodeField, nodeEmpty, "")
+	}
+	if len(nodeID) != rosetta.HexIDSize {
+		sl.ReportError(nodeID, nodeField, nodeField, nodeLength, "")
+	}
+	_, err := hex.DecodeString(nodeID)
+	if err != nil {
+		sl.ReportError(nodeID, nodeField, nodeField, nodeHex, "")
+	}
+}
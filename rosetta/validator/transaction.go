@@ -17,20 +17,18 @@ package validator
 import (
 	"github.com/onflow/flow-go/model/flow"
 
-	"github.com/optakt/flow-rosetta/rosetta/failure"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
 )
 
 // Transaction validates a transaction identifier, and if its valid, returns a matching Flow Identifier.
 func (v *Validator) Transaction(transaction identifier.Transaction) (flow.Identifier, error) {
 
-	txID, err := flow.HexStringToIdentifier(transaction.Hash)
+	err := transaction.Validate()
 	if err != nil {
-		return flow.ZeroID, failure.InvalidTransaction{
-			Hash:        transaction.Hash,
-			Description: failure.NewDescription(txHashInvalid),
-		}
+		return flow.ZeroID, err
 	}
 
+	txID, _ := flow.HexStringToIdentifier(transaction.Hash)
+
 	return txID, nil
 }
@@ -22,17 +22,29 @@ import (
 
 // Validator validates Rosetta object identifiers.
 type Validator struct {
+	cfg      Config
 	params   dps.Params
 	index    dps.Reader
+	track    Tracker
 	validate *validator.Validate
 }
 
 // New returns a new Validator.
-func New(params dps.Params, index dps.Reader, config Configuration) *Validator {
+func New(params dps.Params, index dps.Reader, track Tracker, config Configuration, options ...func(*Config)) *Validator {
+
+	cfg := Config{
+		SealedOnly: false,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
 
 	v := Validator{
+		cfg:      cfg,
 		params:   params,
 		index:    index,
+		track:    track,
 		validate: newRequestValidator(config),
 	}
 
@@ -0,0 +1,82 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package mocks
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	sdk "github.com/onflow/flow-go-sdk"
+)
+
+type AccessAPI struct {
+	SendTransactionFunc         func(ctx context.Context, tx sdk.Transaction, opts ...grpc.CallOption) error
+	GetLatestBlockHeaderFunc    func(ctx context.Context, isSealed bool, opts ...grpc.CallOption) (*sdk.BlockHeader, error)
+	GetBlockHeaderByHeightFunc  func(ctx context.Context, height uint64, opts ...grpc.CallOption) (*sdk.BlockHeader, error)
+	GetAccountAtLatestBlockFunc func(ctx context.Context, address sdk.Address, opts ...grpc.CallOption) (*sdk.Account, error)
+}
+
+func BaselineAccessAPI(t testing.TB) *AccessAPI {
+	t.Helper()
+
+	a := AccessAPI{
+		SendTransactionFunc: func(ctx context.Context, tx sdk.Transaction, opts ...grpc.CallOption) error {
+			return nil
+		},
+		GetLatestBlockHeaderFunc: func(ctx context.Context, isSealed bool, opts ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			header := sdk.BlockHeader{
+				ID:       sdk.Identifier(GenericHeader.ID()),
+				ParentID: sdk.Identifier(GenericHeader.ParentID),
+				Height:   GenericHeader.Height,
+			}
+			return &header, nil
+		},
+		GetBlockHeaderByHeightFunc: func(ctx context.Context, height uint64, opts ...grpc.CallOption) (*sdk.BlockHeader, error) {
+			header := sdk.BlockHeader{
+				ID:       sdk.Identifier(GenericHeader.ID()),
+				ParentID: sdk.Identifier(GenericHeader.ParentID),
+				Height:   GenericHeader.Height,
+			}
+			return &header, nil
+		},
+		GetAccountAtLatestBlockFunc: func(ctx context.Context, address sdk.Address, opts ...grpc.CallOption) (*sdk.Account, error) {
+			account := sdk.Account{
+				Address: address,
+				Keys:    []*sdk.AccountKey{{Index: 0, SequenceNumber: GenericAccount.Keys[0].SeqNumber}},
+			}
+			return &account, nil
+		},
+	}
+
+	return &a
+}
+
+func (a *AccessAPI) SendTransaction(ctx context.Context, tx sdk.Transaction, opts ...grpc.CallOption) error {
+	return a.SendTransactionFunc(ctx, tx, opts...)
+}
+
+func (a *AccessAPI) GetLatestBlockHeader(ctx context.Context, isSealed bool, opts ...grpc.CallOption) (*sdk.BlockHeader, error) {
+	return a.GetLatestBlockHeaderFunc(ctx, isSealed, opts...)
+}
+
+func (a *AccessAPI) GetBlockHeaderByHeight(ctx context.Context, height uint64, opts ...grpc.CallOption) (*sdk.BlockHeader, error) {
+	return a.GetBlockHeaderByHeightFunc(ctx, height, opts...)
+}
+
+func (a *AccessAPI) GetAccountAtLatestBlock(ctx context.Context, address sdk.Address, opts ...grpc.CallOption) (*sdk.Account, error) {
+	return a.GetAccountAtLatestBlockFunc(ctx, address, opts...)
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package mocks
+
+import (
+	"testing"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+type BlockStore struct {
+	BlockFunc func(height uint64) (*object.Block, []identifier.Transaction, bool)
+	SaveFunc  func(height uint64, block *object.Block, other []identifier.Transaction)
+}
+
+func BaselineBlockStore(t testing.TB) *BlockStore {
+	t.Helper()
+
+	b := BlockStore{
+		BlockFunc: func(uint64) (*object.Block, []identifier.Transaction, bool) {
+			return nil, nil, false
+		},
+		SaveFunc: func(uint64, *object.Block, []identifier.Transaction) {},
+	}
+
+	return &b
+}
+
+func (b *BlockStore) Block(height uint64) (*object.Block, []identifier.Transaction, bool) {
+	return b.BlockFunc(height)
+}
+
+func (b *BlockStore) Save(height uint64, block *object.Block, other []identifier.Transaction) {
+	b.SaveFunc(height, block, other)
+}
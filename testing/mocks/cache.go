@@ -23,7 +23,7 @@ type Cache struct {
 	SetFunc func(key, value interface{}, cost int64) bool
 }
 
-func BaselineCache(t *testing.T) *Cache {
+func BaselineCache(t testing.TB) *Cache {
 	t.Helper()
 
 	c := Cache{
@@ -32,7 +32,7 @@ type Chain struct {
 	SealsFunc        func(height uint64) ([]*flow.Seal, error)
 }
 
-func BaselineChain(t *testing.T) *Chain {
+func BaselineChain(t testing.TB) *Chain {
 	t.Helper()
 
 	c := Chain{
@@ -25,7 +25,7 @@ type Codec struct {
 	UnmarshalFunc  func(compressed []byte, value interface{}) error
 }
 
-func BaselineCodec(t *testing.T) *Codec {
+func BaselineCodec(t testing.TB) *Codec {
 	t.Helper()
 
 	c := Codec{
@@ -24,9 +24,15 @@ import (
 
 type Converter struct {
 	EventToOperationFunc func(event flow.Event) (*object.Operation, error)
+	EventToRewardFunc    func(event flow.Event) (*object.Reward, error)
+	EventToFeesFunc      func(event flow.Event) (*object.TransactionFees, error)
+
+	EventToServiceEventFunc func(event flow.Event) (*object.ServiceEvent, error)
+	EventToAddressFunc      func(event flow.Event) (flow.Address, error)
+	EventToContractFunc     func(event flow.Event) (*object.ContractChange, error)
 }
 
-func BaselineConverter(t *testing.T) *Converter {
+func BaselineConverter(t testing.TB) *Converter {
 	t.Helper()
 
 	c := Converter{
@@ -34,6 +40,25 @@ func BaselineConverter(t *testing.T) *Converter {
 			op := GenericOperation(0)
 			return &op, nil
 		},
+		EventToRewardFunc: func(event flow.Event) (*object.Reward, error) {
+			reward := GenericReward(0)
+			return &reward, nil
+		},
+		EventToFeesFunc: func(event flow.Event) (*object.TransactionFees, error) {
+			fees := GenericFees()
+			return &fees, nil
+		},
+		EventToServiceEventFunc: func(event flow.Event) (*object.ServiceEvent, error) {
+			service := GenericServiceEvent()
+			return &service, nil
+		},
+		EventToAddressFunc: func(event flow.Event) (flow.Address, error) {
+			return GenericAddress(0), nil
+		},
+		EventToContractFunc: func(event flow.Event) (*object.ContractChange, error) {
+			contract := GenericContractChange()
+			return &contract, nil
+		},
 	}
 
 	return &c
@@ -42,3 +67,23 @@ func BaselineConverter(t *testing.T) *Converter {
 func (c *Converter) EventToOperation(event flow.Event) (transaction *object.Operation, err error) {
 	return c.EventToOperationFunc(event)
 }
+
+func (c *Converter) EventToReward(event flow.Event) (*object.Reward, error) {
+	return c.EventToRewardFunc(event)
+}
+
+func (c *Converter) EventToFees(event flow.Event) (*object.TransactionFees, error) {
+	return c.EventToFeesFunc(event)
+}
+
+func (c *Converter) EventToServiceEvent(event flow.Event) (*object.ServiceEvent, error) {
+	return c.EventToServiceEventFunc(event)
+}
+
+func (c *Converter) EventToAddress(event flow.Event) (flow.Address, error) {
+	return c.EventToAddressFunc(event)
+}
+
+func (c *Converter) EventToContract(event flow.Event) (*object.ContractChange, error) {
+	return c.EventToContractFunc(event)
+}
@@ -24,7 +24,7 @@ type Feeder struct {
 	UpdateFunc func() (*ledger.TrieUpdate, error)
 }
 
-func BaselineFeeder(t *testing.T) *Feeder {
+func BaselineFeeder(t testing.TB) *Feeder {
 	t.Helper()
 
 	f := Feeder{
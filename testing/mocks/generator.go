@@ -17,28 +17,111 @@ package mocks
 import "testing"
 
 type Generator struct {
-	GetBalanceFunc      func(symbol string) ([]byte, error)
-	TokensDepositedFunc func(symbol string) (string, error)
-	TokensWithdrawnFunc func(symbol string) (string, error)
-	TransferTokensFunc  func(symbol string) ([]byte, error)
+	GetBalanceFunc       func(symbol string) ([]byte, error)
+	GetVaultBalancesFunc func(symbol string, paths []string) ([]byte, error)
+	TokensDepositedFunc  func(symbol string) (string, error)
+	TokensWithdrawnFunc  func(symbol string) (string, error)
+	TokensMintedFunc     func(symbol string) (string, error)
+	TokensBurnedFunc     func(symbol string) (string, error)
+	TransferTokensFunc   func(symbol string) ([]byte, error)
+
+	RewardsPaidFunc          func() (string, error)
+	DelegatorRewardsPaidFunc func() (string, error)
+	FeesDeductedFunc         func() (string, error)
+	EpochSetupFunc           func() (string, error)
+	EpochCommitFunc          func() (string, error)
+	GetNodeInfoFunc          func() ([]byte, error)
+	GetLockedAccountFunc     func() ([]byte, error)
+	GetMachineAccountsFunc   func() ([]byte, error)
+	GetStorageInfoFunc       func() ([]byte, error)
+	GetTransactionFeeFunc    func() ([]byte, error)
+
+	AddAccountKeyFunc    func() ([]byte, error)
+	RevokeAccountKeyFunc func() ([]byte, error)
+
+	TransferFUSDFunc           func() ([]byte, error)
+	StakeNewTokensFunc         func() ([]byte, error)
+	RequestUnstakingFunc       func() ([]byte, error)
+	WithdrawUnstakedTokensFunc func() ([]byte, error)
+	WithdrawRewardedTokensFunc func() ([]byte, error)
 }
 
-func BaselineGenerator(t *testing.T) *Generator {
+func BaselineGenerator(t testing.TB) *Generator {
 	t.Helper()
 
 	g := Generator{
 		GetBalanceFunc: func(string) ([]byte, error) {
 			return []byte(GenericAmount(0).String()), nil
 		},
+		GetVaultBalancesFunc: func(string, []string) ([]byte, error) {
+			return GenericBytes, nil
+		},
 		TokensDepositedFunc: func(string) (string, error) {
 			return string(GenericEventType(0)), nil
 		},
 		TokensWithdrawnFunc: func(string) (string, error) {
 			return string(GenericEventType(1)), nil
 		},
+		TokensMintedFunc: func(string) (string, error) {
+			return string(GenericEventType(12)), nil
+		},
+		TokensBurnedFunc: func(string) (string, error) {
+			return string(GenericEventType(13)), nil
+		},
 		TransferTokensFunc: func(string) ([]byte, error) {
 			return GenericBytes, nil
 		},
+		RewardsPaidFunc: func() (string, error) {
+			return string(GenericEventType(2)), nil
+		},
+		DelegatorRewardsPaidFunc: func() (string, error) {
+			return string(GenericEventType(3)), nil
+		},
+		FeesDeductedFunc: func() (string, error) {
+			return string(GenericEventType(8)), nil
+		},
+		EpochSetupFunc: func() (string, error) {
+			return string(GenericEventType(10)), nil
+		},
+		EpochCommitFunc: func() (string, error) {
+			return string(GenericEventType(11)), nil
+		},
+		GetNodeInfoFunc: func() ([]byte, error) {
+			return GenericBytes, nil
+		},
+		GetLockedAccountFunc: func() ([]byte, error) {
+			return GenericBytes, nil
+		},
+		GetMachineAccountsFunc: func() ([]byte, error) {
+			return GenericBytes, nil
+		},
+		GetStorageInfoFunc: func() ([]byte, error) {
+			return GenericBytes, nil
+		},
+		GetTransactionFeeFunc: func() ([]byte, error) {
+			return GenericBytes, nil
+		},
+		AddAccountKeyFunc: func() ([]byte, error) {
+			return GenericAddKeyScript, nil
+		},
+		RevokeAccountKeyFunc: func() ([]byte, error) {
+			return GenericRevokeKeyScript, nil
+		},
+		TransferFUSDFunc: func() ([]byte, error) {
+			return GenericFUSDScript, nil
+		},
+		StakeNewTokensFunc: func() ([]byte, error) {
+			return GenericStakeScript, nil
+		},
+		RequestUnstakingFunc: func() ([]byte, error) {
+			return GenericUnstakeScript, nil
+		},
+		WithdrawUnstakedTokensFunc: func() ([]byte, error) {
+			return GenericWithdrawUnstakedScript, nil
+		},
+		WithdrawRewardedTokensFunc: func() ([]byte, error) {
+			return GenericWithdrawRewardedScript, nil
+		},
 	}
 
 	return &g
@@ -48,6 +131,10 @@ func (g *Generator) GetBalance(symbol string) ([]byte, error) {
 	return g.GetBalanceFunc(symbol)
 }
 
+func (g *Generator) GetVaultBalances(symbol string, paths []string) ([]byte, error) {
+	return g.GetVaultBalancesFunc(symbol, paths)
+}
+
 func (g *Generator) TokensDeposited(symbol string) (string, error) {
 	return g.TokensDepositedFunc(symbol)
 }
@@ -56,6 +143,82 @@ func (g *Generator) TokensWithdrawn(symbol string) (string, error) {
 	return g.TokensWithdrawnFunc(symbol)
 }
 
+func (g *Generator) TokensMinted(symbol string) (string, error) {
+	return g.TokensMintedFunc(symbol)
+}
+
+func (g *Generator) TokensBurned(symbol string) (string, error) {
+	return g.TokensBurnedFunc(symbol)
+}
+
 func (g *Generator) TransferTokens(symbol string) ([]byte, error) {
 	return g.TransferTokensFunc(symbol)
 }
+
+func (g *Generator) RewardsPaid() (string, error) {
+	return g.RewardsPaidFunc()
+}
+
+func (g *Generator) DelegatorRewardsPaid() (string, error) {
+	return g.DelegatorRewardsPaidFunc()
+}
+
+func (g *Generator) FeesDeducted() (string, error) {
+	return g.FeesDeductedFunc()
+}
+
+func (g *Generator) EpochSetup() (string, error) {
+	return g.EpochSetupFunc()
+}
+
+func (g *Generator) EpochCommit() (string, error) {
+	return g.EpochCommitFunc()
+}
+
+func (g *Generator) GetNodeInfo() ([]byte, error) {
+	return g.GetNodeInfoFunc()
+}
+
+func (g *Generator) GetLockedAccount() ([]byte, error) {
+	return g.GetLockedAccountFunc()
+}
+
+func (g *Generator) GetMachineAccounts() ([]byte, error) {
+	return g.GetMachineAccountsFunc()
+}
+
+func (g *Generator) GetStorageInfo() ([]byte, error) {
+	return g.GetStorageInfoFunc()
+}
+
+func (g *Generator) GetTransactionFee() ([]byte, error) {
+	return g.GetTransactionFeeFunc()
+}
+
+func (g *Generator) AddAccountKey() ([]byte, error) {
+	return g.AddAccountKeyFunc()
+}
+
+func (g *Generator) RevokeAccountKey() ([]byte, error) {
+	return g.RevokeAccountKeyFunc()
+}
+
+func (g *Generator) TransferFUSD() ([]byte, error) {
+	return g.TransferFUSDFunc()
+}
+
+func (g *Generator) StakeNewTokens() ([]byte, error) {
+	return g.StakeNewTokensFunc()
+}
+
+func (g *Generator) RequestUnstaking() ([]byte, error) {
+	return g.RequestUnstakingFunc()
+}
+
+func (g *Generator) WithdrawUnstakedTokens() ([]byte, error) {
+	return g.WithdrawUnstakedTokensFunc()
+}
+
+func (g *Generator) WithdrawRewardedTokens() ([]byte, error) {
+	return g.WithdrawRewardedTokensFunc()
+}
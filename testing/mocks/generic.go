@@ -38,8 +38,11 @@ import (
 	"github.com/onflow/flow-go/module/mempool/entity"
 
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-rosetta/rosetta/configuration"
 	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/intents"
 	"github.com/optakt/flow-rosetta/rosetta/object"
+	"github.com/optakt/flow-rosetta/rosetta/templates"
 )
 
 // Offsets used to ensure different flow identifiers that do not overlap.
@@ -49,6 +52,7 @@ const (
 	offsetBlock      = 0
 	offsetCollection = 1 * 16
 	offsetResult     = 2 * 16
+	offsetNode       = 3 * 16
 )
 
 // Global variables that can be used for testing. They are non-nil valid values for the types commonly needed
@@ -123,6 +127,7 @@ var (
 			{
 				Index:     0,
 				SeqNumber: 42,
+				SignAlgo:  crypto.ECDSAP256,
 				HashAlgo:  chash.SHA2_256,
 				PublicKey: crypto.NeutralBLSPublicKey(),
 			},
@@ -135,6 +140,33 @@ var (
 	}
 
 	GenericParams = dps.Params{ChainID: dps.FlowTestnet}
+
+	GenericTemplateScript = []byte(`template`)
+
+	GenericAddKeyScript    = []byte(`add_account_key`)
+	GenericRevokeKeyScript = []byte(`revoke_account_key`)
+
+	GenericFUSDScript             = []byte(`transfer_fusd`)
+	GenericStakeScript            = []byte(`stake_new_tokens`)
+	GenericUnstakeScript          = []byte(`request_unstaking`)
+	GenericWithdrawUnstakedScript = []byte(`withdraw_unstaked_tokens`)
+	GenericWithdrawRewardedScript = []byte(`withdraw_rewarded_tokens`)
+
+	GenericIntent = intents.Intent{
+		Name:   intents.NameTransferTokens,
+		Kind:   intents.KindTransfer,
+		Symbol: dps.FlowSymbol,
+	}
+
+	GenericTemplate = templates.Template{
+		Name:   "template",
+		Script: GenericTemplateScript,
+		Hash:   templates.Hash(GenericTemplateScript),
+		Arguments: []templates.Argument{
+			{Name: "recipient", Type: templates.TypeAddress},
+			{Name: "id", Type: templates.TypeUInt64},
+		},
+	}
 )
 
 func GenericBlockIDs(number int) []flow.Identifier {
@@ -437,6 +469,110 @@ func GenericOperation(index int) object.Operation {
 	return GenericOperations(index + 1)[index]
 }
 
+func GenericRosTransaction(index int) *object.Transaction {
+	op0 := GenericOperation(2 * index)
+	op1 := GenericOperation(2*index + 1)
+	transaction := object.Transaction{
+		ID:         GenericTransactionQualifier(index),
+		Operations: []*object.Operation{&op0, &op1},
+	}
+	return &transaction
+}
+
+func GenericTemplateArguments() map[string]string {
+	return map[string]string{
+		"recipient": GenericAddress(1).Hex(),
+		"id":        fmt.Sprint(GenericHeight),
+	}
+}
+
+func GenericTemplateOperation() object.Operation {
+	return object.Operation{
+		ID:        identifier.Operation{Index: 0},
+		Type:      configuration.OperationTemplate,
+		AccountID: GenericAccountID(0),
+		Amount: object.Amount{
+			Value:    "0",
+			Currency: GenericCurrency,
+		},
+		Metadata: &object.OperationMetadata{
+			Template:  GenericTemplate.Name,
+			Arguments: GenericTemplateArguments(),
+		},
+	}
+}
+
+func GenericNodeIDs(number int) []flow.Identifier {
+	return genericIdentifiers(number, offsetNode)
+}
+
+func GenericNodeID(index int) flow.Identifier {
+	return GenericNodeIDs(index + 1)[index]
+}
+
+func GenericRewards(number int) []object.Reward {
+	var rewards []object.Reward
+	for i := 0; i < number; i++ {
+		height := GenericHeight + uint64(i)
+		reward := object.Reward{
+			BlockID: identifier.Block{
+				Index: &height,
+				Hash:  genericIdentifier(i, offsetBlock).String(),
+			},
+			NodeID: GenericNodeID(0).String(),
+			Amount: object.Amount{
+				Value:    GenericAmount(i).String(),
+				Currency: GenericCurrency,
+			},
+		}
+
+		rewards = append(rewards, reward)
+	}
+
+	return rewards
+}
+
+func GenericReward(index int) object.Reward {
+	return GenericRewards(index + 1)[index]
+}
+
+func GenericFees() object.TransactionFees {
+	return object.TransactionFees{
+		Amount: object.Amount{
+			Value: "1000",
+			Currency: identifier.Currency{
+				Symbol:   dps.FlowSymbol,
+				Decimals: dps.FlowDecimals,
+			},
+		},
+		InclusionEffort: "1.00000000",
+		ExecutionEffort: "0.00000005",
+	}
+}
+
+func GenericServiceEvent() object.ServiceEvent {
+	return object.ServiceEvent{
+		Type:    flow.ServiceEventSetup,
+		Counter: 42,
+		Setup: &object.EpochSetup{
+			FirstView:          1000,
+			FinalView:          1999,
+			DKGPhaseFinalViews: []uint64{1100, 1200, 1300},
+			RandomSource:       "01020304",
+			Participants:       4,
+			Clusters:           1,
+		},
+	}
+}
+
+func GenericContractChange() object.ContractChange {
+	return object.ContractChange{
+		Address: GenericAddress(0).String(),
+		Name:    "FungibleToken",
+		Action:  "updated",
+	}
+}
+
 func GenericCollections(number int) []*flow.LightCollection {
 	txIDs := GenericTransactionIDs(number * 2)
 
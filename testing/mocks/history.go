@@ -0,0 +1,65 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package mocks
+
+import (
+	"testing"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-rosetta/rosetta/history"
+)
+
+type History struct {
+	RangeFunc        func() (uint64, uint64, bool)
+	TransactionsFunc func(address flow.Address, start uint64, index uint32, end uint64, limit uint) ([]history.Reference, error)
+	LatestFunc       func(address flow.Address, height uint64) (uint64, bool, error)
+}
+
+func BaselineHistory(t testing.TB) *History {
+	t.Helper()
+
+	h := History{
+		RangeFunc: func() (uint64, uint64, bool) {
+			return GenericHeight, GenericHeight, true
+		},
+		TransactionsFunc: func(flow.Address, uint64, uint32, uint64, uint) ([]history.Reference, error) {
+			ref := history.Reference{
+				Height:  GenericHeight,
+				Index:   0,
+				BlockID: GenericHeader.ID(),
+				TxID:    GenericTransaction(0).ID(),
+			}
+			return []history.Reference{ref}, nil
+		},
+		LatestFunc: func(flow.Address, uint64) (uint64, bool, error) {
+			return GenericHeight, true, nil
+		},
+	}
+
+	return &h
+}
+
+func (h *History) Range() (uint64, uint64, bool) {
+	return h.RangeFunc()
+}
+
+func (h *History) Transactions(address flow.Address, start uint64, index uint32, end uint64, limit uint) ([]history.Reference, error) {
+	return h.TransactionsFunc(address, start, index, end, limit)
+}
+
+func (h *History) Latest(address flow.Address, height uint64) (uint64, bool, error) {
+	return h.LatestFunc(address, height)
+}
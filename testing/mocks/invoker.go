@@ -22,12 +22,13 @@ import (
 )
 
 type Invoker struct {
-	KeyFunc     func(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error)
-	AccountFunc func(height uint64, address flow.Address) (*flow.Account, error)
-	ScriptFunc  func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error)
+	KeyFunc         func(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error)
+	AccountFunc     func(height uint64, address flow.Address) (*flow.Account, error)
+	ScriptFunc      func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error)
+	TransactionFunc func(height uint64, body *flow.TransactionBody) error
 }
 
-func BaselineInvoker(t *testing.T) *Invoker {
+func BaselineInvoker(t testing.TB) *Invoker {
 	t.Helper()
 
 	i := Invoker{
@@ -40,6 +41,9 @@ func BaselineInvoker(t *testing.T) *Invoker {
 		ScriptFunc: func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
 			return GenericAmount(0), nil
 		},
+		TransactionFunc: func(height uint64, body *flow.TransactionBody) error {
+			return nil
+		},
 	}
 
 	return &i
@@ -56,3 +60,7 @@ func (i *Invoker) Account(height uint64, address flow.Address) (*flow.Account, e
 func (i *Invoker) Script(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
 	return i.ScriptFunc(height, script, parameters)
 }
+
+func (i *Invoker) Transaction(height uint64, body *flow.TransactionBody) error {
+	return i.TransactionFunc(height, body)
+}
@@ -24,7 +24,7 @@ type Loader struct {
 	CheckpointFunc func() (*trie.MTrie, error)
 }
 
-func BaselineLoader(t *testing.T) *Loader {
+func BaselineLoader(t testing.TB) *Loader {
 	t.Helper()
 
 	l := Loader{
@@ -40,7 +40,7 @@ type Reader struct {
 	SealsByHeightFunc        func(height uint64) ([]flow.Identifier, error)
 }
 
-func BaselineReader(t *testing.T) *Reader {
+func BaselineReader(t testing.TB) *Reader {
 	t.Helper()
 
 	r := Reader{
@@ -26,7 +26,7 @@ type RecordHolder struct {
 	RecordFunc func(blockID flow.Identifier) (*uploader.BlockData, error)
 }
 
-func BaselineRecordHolder(t *testing.T) *RecordHolder {
+func BaselineRecordHolder(t testing.TB) *RecordHolder {
 	t.Helper()
 
 	r := RecordHolder{
@@ -24,7 +24,7 @@ type RecordStreamer struct {
 	NextFunc func() (*uploader.BlockData, error)
 }
 
-func BaselineRecordStreamer(t *testing.T) *RecordStreamer {
+func BaselineRecordStreamer(t testing.TB) *RecordStreamer {
 	t.Helper()
 
 	r := RecordStreamer{
@@ -0,0 +1,203 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package mocks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/optakt/flow-rosetta/rosetta/identifier"
+	"github.com/optakt/flow-rosetta/rosetta/object"
+)
+
+type Retriever struct {
+	OldestFunc              func() (identifier.Block, time.Time, error)
+	CurrentFunc             func() (identifier.Block, time.Time, error)
+	BlockIDFunc             func(rosBlockID identifier.Block) (identifier.Block, error)
+	BlockFunc               func(rosBlockID identifier.Block) (*object.Block, []identifier.Transaction, error)
+	ChildFunc               func(rosBlockID identifier.Block) (identifier.Block, identifier.Block, error)
+	BlocksFunc              func(rosStart identifier.Block, rosEnd identifier.Block) ([]*object.Block, [][]identifier.Transaction, error)
+	TransactionFunc         func(rosBlockID identifier.Block, rosTxID identifier.Transaction) (*object.Transaction, error)
+	LookupFunc              func(rosTxID identifier.Transaction) (identifier.Block, *object.Transaction, error)
+	BalancesFunc            func(rosBlockID identifier.Block, rosAccountID identifier.Account, rosCurrencies []identifier.Currency) (identifier.Block, []object.Amount, error)
+	KeysFunc                func(rosBlockID identifier.Block, rosAccountID identifier.Account) ([]object.AccountKey, error)
+	SequenceFunc            func(rosBlockID identifier.Block, rosAccountID identifier.Account, index int) (uint64, error)
+	NodeFunc                func(rosBlockID identifier.Block, nodeID string) (identifier.Block, *object.Node, error)
+	RewardsFunc             func(nodeID string, delegatorID *uint32, rosStart identifier.Block, rosEnd identifier.Block) ([]object.Reward, *identifier.Block, error)
+	AccountTransactionsFunc func(rosAccountID identifier.Account, rosStart identifier.Block, rosEnd identifier.Block, index uint, limit uint) ([]object.BlockTransaction, *identifier.Block, uint, error)
+	SearchFunc              func(rosBlockID identifier.Block, index uint, limit uint, rosAccountID *identifier.Account, match func(*object.Transaction) bool) ([]object.BlockTransaction, *identifier.Block, uint, error)
+	StatementFunc           func(rosAccountID identifier.Account, rosStart identifier.Block, rosEnd identifier.Block) ([]object.StatementEntry, error)
+}
+
+func BaselineRetriever(t testing.TB) *Retriever {
+	t.Helper()
+
+	r := Retriever{
+		OldestFunc: func() (identifier.Block, time.Time, error) {
+			return GenericRosBlockID, GenericHeader.Timestamp, nil
+		},
+		CurrentFunc: func() (identifier.Block, time.Time, error) {
+			return GenericRosBlockID, GenericHeader.Timestamp, nil
+		},
+		BlockIDFunc: func(identifier.Block) (identifier.Block, error) {
+			return GenericRosBlockID, nil
+		},
+		BlockFunc: func(identifier.Block) (*object.Block, []identifier.Transaction, error) {
+			block := object.Block{
+				ID:        GenericRosBlockID,
+				Timestamp: GenericHeader.Timestamp.UnixNano() / 1_000_000,
+				Transactions: []*object.Transaction{
+					GenericRosTransaction(0),
+				},
+			}
+			return &block, nil, nil
+		},
+		ChildFunc: func(identifier.Block) (identifier.Block, identifier.Block, error) {
+			return GenericRosBlockID, GenericRosBlockID, nil
+		},
+		BlocksFunc: func(identifier.Block, identifier.Block) ([]*object.Block, [][]identifier.Transaction, error) {
+			block := object.Block{
+				ID:        GenericRosBlockID,
+				Timestamp: GenericHeader.Timestamp.UnixNano() / 1_000_000,
+				Transactions: []*object.Transaction{
+					GenericRosTransaction(0),
+				},
+			}
+			return []*object.Block{&block}, [][]identifier.Transaction{nil}, nil
+		},
+		TransactionFunc: func(identifier.Block, identifier.Transaction) (*object.Transaction, error) {
+			return GenericRosTransaction(0), nil
+		},
+		LookupFunc: func(identifier.Transaction) (identifier.Block, *object.Transaction, error) {
+			return GenericRosBlockID, GenericRosTransaction(0), nil
+		},
+		BalancesFunc: func(identifier.Block, identifier.Account, []identifier.Currency) (identifier.Block, []object.Amount, error) {
+			return GenericRosBlockID, []object.Amount{GenericOperation(0).Amount}, nil
+		},
+		KeysFunc: func(identifier.Block, identifier.Account) ([]object.AccountKey, error) {
+			key := GenericAccount.Keys[0]
+			keys := []object.AccountKey{
+				{
+					Index:              key.Index,
+					PublicKey:          key.PublicKey.String(),
+					SignatureAlgorithm: key.SignAlgo.String(),
+					HashAlgorithm:      key.HashAlgo.String(),
+					Weight:             key.Weight,
+					SequenceNumber:     key.SeqNumber,
+				},
+			}
+			return keys, nil
+		},
+		SequenceFunc: func(identifier.Block, identifier.Account, int) (uint64, error) {
+			return GenericAccount.Keys[0].SeqNumber, nil
+		},
+		NodeFunc: func(identifier.Block, string) (identifier.Block, *object.Node, error) {
+			return GenericRosBlockID, &object.Node{}, nil
+		},
+		RewardsFunc: func(string, *uint32, identifier.Block, identifier.Block) ([]object.Reward, *identifier.Block, error) {
+			return GenericRewards(2), nil, nil
+		},
+		AccountTransactionsFunc: func(identifier.Account, identifier.Block, identifier.Block, uint, uint) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+			match := object.BlockTransaction{
+				BlockID:     GenericRosBlockID,
+				Transaction: GenericRosTransaction(0),
+			}
+			return []object.BlockTransaction{match}, nil, 0, nil
+		},
+		SearchFunc: func(identifier.Block, uint, uint, *identifier.Account, func(*object.Transaction) bool) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+			match := object.BlockTransaction{
+				BlockID:     GenericRosBlockID,
+				Transaction: GenericRosTransaction(0),
+			}
+			return []object.BlockTransaction{match}, nil, 0, nil
+		},
+		StatementFunc: func(identifier.Account, identifier.Block, identifier.Block) ([]object.StatementEntry, error) {
+			op := GenericOperation(0)
+			entry := object.StatementEntry{
+				BlockID:       GenericRosBlockID,
+				Timestamp:     GenericHeader.Timestamp.UnixNano() / 1_000_000,
+				TransactionID: GenericTransactionQualifier(0),
+				Operation:     &op,
+			}
+			return []object.StatementEntry{entry}, nil
+		},
+	}
+
+	return &r
+}
+
+func (r *Retriever) Oldest() (identifier.Block, time.Time, error) {
+	return r.OldestFunc()
+}
+
+func (r *Retriever) Current() (identifier.Block, time.Time, error) {
+	return r.CurrentFunc()
+}
+
+func (r *Retriever) BlockID(rosBlockID identifier.Block) (identifier.Block, error) {
+	return r.BlockIDFunc(rosBlockID)
+}
+
+func (r *Retriever) Block(rosBlockID identifier.Block) (*object.Block, []identifier.Transaction, error) {
+	return r.BlockFunc(rosBlockID)
+}
+
+func (r *Retriever) Child(rosBlockID identifier.Block) (identifier.Block, identifier.Block, error) {
+	return r.ChildFunc(rosBlockID)
+}
+
+func (r *Retriever) Blocks(rosStart identifier.Block, rosEnd identifier.Block) ([]*object.Block, [][]identifier.Transaction, error) {
+	return r.BlocksFunc(rosStart, rosEnd)
+}
+
+func (r *Retriever) Transaction(rosBlockID identifier.Block, rosTxID identifier.Transaction) (*object.Transaction, error) {
+	return r.TransactionFunc(rosBlockID, rosTxID)
+}
+
+func (r *Retriever) Lookup(rosTxID identifier.Transaction) (identifier.Block, *object.Transaction, error) {
+	return r.LookupFunc(rosTxID)
+}
+
+func (r *Retriever) Balances(rosBlockID identifier.Block, rosAccountID identifier.Account, rosCurrencies []identifier.Currency) (identifier.Block, []object.Amount, error) {
+	return r.BalancesFunc(rosBlockID, rosAccountID, rosCurrencies)
+}
+
+func (r *Retriever) Keys(rosBlockID identifier.Block, rosAccountID identifier.Account) ([]object.AccountKey, error) {
+	return r.KeysFunc(rosBlockID, rosAccountID)
+}
+
+func (r *Retriever) Sequence(rosBlockID identifier.Block, rosAccountID identifier.Account, index int) (uint64, error) {
+	return r.SequenceFunc(rosBlockID, rosAccountID, index)
+}
+
+func (r *Retriever) Node(rosBlockID identifier.Block, nodeID string) (identifier.Block, *object.Node, error) {
+	return r.NodeFunc(rosBlockID, nodeID)
+}
+
+func (r *Retriever) Rewards(nodeID string, delegatorID *uint32, rosStart identifier.Block, rosEnd identifier.Block) ([]object.Reward, *identifier.Block, error) {
+	return r.RewardsFunc(nodeID, delegatorID, rosStart, rosEnd)
+}
+
+func (r *Retriever) AccountTransactions(rosAccountID identifier.Account, rosStart identifier.Block, rosEnd identifier.Block, index uint, limit uint) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+	return r.AccountTransactionsFunc(rosAccountID, rosStart, rosEnd, index, limit)
+}
+
+func (r *Retriever) Search(rosBlockID identifier.Block, index uint, limit uint, rosAccountID *identifier.Account, match func(*object.Transaction) bool) ([]object.BlockTransaction, *identifier.Block, uint, error) {
+	return r.SearchFunc(rosBlockID, index, limit, rosAccountID, match)
+}
+
+func (r *Retriever) Statement(rosAccountID identifier.Account, rosStart identifier.Block, rosEnd identifier.Block) ([]object.StatementEntry, error) {
+	return r.StatementFunc(rosAccountID, rosStart, rosEnd)
+}
@@ -22,19 +22,27 @@ import (
 
 type Submitter struct {
 	TransactionFunc func(tx *sdk.Transaction) error
+	SubmittedFunc   func(txID sdk.Identifier) bool
 }
 
 func (s *Submitter) Transaction(tx *sdk.Transaction) error {
 	return s.TransactionFunc(tx)
 }
 
-func BaselineSubmitter(t *testing.T) *Submitter {
+func (s *Submitter) Submitted(txID sdk.Identifier) bool {
+	return s.SubmittedFunc(txID)
+}
+
+func BaselineSubmitter(t testing.TB) *Submitter {
 	t.Helper()
 
 	s := Submitter{
 		TransactionFunc: func(tx *sdk.Transaction) error {
 			return nil
 		},
+		SubmittedFunc: func(txID sdk.Identifier) bool {
+			return false
+		},
 	}
 
 	return &s
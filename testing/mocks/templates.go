@@ -0,0 +1,50 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package mocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/optakt/flow-rosetta/rosetta/templates"
+)
+
+type Templates struct {
+	TemplateFunc func(name string) (templates.Template, bool)
+	MatchFunc    func(script []byte) (templates.Template, bool)
+}
+
+func BaselineTemplates(t testing.TB) *Templates {
+	t.Helper()
+
+	r := Templates{
+		TemplateFunc: func(string) (templates.Template, bool) {
+			return GenericTemplate, true
+		},
+		MatchFunc: func(script []byte) (templates.Template, bool) {
+			return GenericTemplate, bytes.Equal(script, GenericTemplate.Script)
+		},
+	}
+
+	return &r
+}
+
+func (r *Templates) Template(name string) (templates.Template, bool) {
+	return r.TemplateFunc(name)
+}
+
+func (r *Templates) Match(script []byte) (templates.Template, bool) {
+	return r.MatchFunc(script)
+}
@@ -27,9 +27,12 @@ type Validator struct {
 	BlockFunc       func(rosBlockID identifier.Block) (uint64, flow.Identifier, error)
 	TransactionFunc func(rosTxID identifier.Transaction) (flow.Identifier, error)
 	CurrencyFunc    func(rosCurrencies identifier.Currency) (string, uint, error)
+
+	RequestFunc         func(request interface{}) error
+	CompleteBlockIDFunc func(rosBlockID identifier.Block) error
 }
 
-func BaselineValidator(t *testing.T) *Validator {
+func BaselineValidator(t testing.TB) *Validator {
 	t.Helper()
 
 	v := Validator{
@@ -45,6 +48,12 @@ func BaselineValidator(t *testing.T) *Validator {
 		CurrencyFunc: func(rosCurrency identifier.Currency) (string, uint, error) {
 			return GenericCurrency.Symbol, GenericCurrency.Decimals, nil
 		},
+		RequestFunc: func(interface{}) error {
+			return nil
+		},
+		CompleteBlockIDFunc: func(identifier.Block) error {
+			return nil
+		},
 	}
 
 	return &v
@@ -65,3 +74,11 @@ func (v *Validator) Transaction(rosTxID identifier.Transaction) (flow.Identifier
 func (v *Validator) Currency(rosCurrency identifier.Currency) (string, uint, error) {
 	return v.CurrencyFunc(rosCurrency)
 }
+
+func (v *Validator) Request(request interface{}) error {
+	return v.RequestFunc(request)
+}
+
+func (v *Validator) CompleteBlockID(rosBlockID identifier.Block) error {
+	return v.CompleteBlockIDFunc(rosBlockID)
+}
@@ -28,7 +28,7 @@ type VirtualMachine struct {
 	RunFunc        func(ctx fvm.Context, proc fvm.Procedure, v state.View, programs *programs.Programs) error
 }
 
-func BaselineVirtualMachine(t *testing.T) *VirtualMachine {
+func BaselineVirtualMachine(t testing.TB) *VirtualMachine {
 	t.Helper()
 
 	vm := VirtualMachine{
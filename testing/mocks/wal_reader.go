@@ -24,7 +24,7 @@ type WALReader struct {
 	RecordFunc func() []byte
 }
 
-func BaselineWALReader(t *testing.T) *WALReader {
+func BaselineWALReader(t testing.TB) *WALReader {
 	t.Helper()
 
 	return &WALReader{
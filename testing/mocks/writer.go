@@ -36,7 +36,7 @@ type Writer struct {
 	SealsFunc        func(height uint64, seals []*flow.Seal) error
 }
 
-func BaselineWriter(t *testing.T) *Writer {
+func BaselineWriter(t testing.TB) *Writer {
 	t.Helper()
 
 	w := Writer{